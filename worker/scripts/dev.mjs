@@ -85,8 +85,34 @@ function loadEnvVars() {
   }
 }
 
+/**
+ * SALEOR_MODE=mock: serve the fixture directory instead of Saleor
+ * (SALEOR_FIXTURES_DIR, default fixtures/) via the SALEOR_FIXTURES var
+ */
+function loadFixtures() {
+  if (String(globalThis.SALEOR_MODE).toLowerCase() !== "mock" || globalThis.SALEOR_FIXTURES) {
+    return;
+  }
+  const dir = resolve(__dirname, "..", globalThis.SALEOR_FIXTURES_DIR || "fixtures");
+  const fixtures = {};
+  for (const kind of ["restaurants", "categories", "dishes"]) {
+    const path = resolve(dir, `${kind}.json`);
+    if (!existsSync(path)) {
+      continue;
+    }
+    try {
+      fixtures[kind] = JSON.parse(readFileSync(path, "utf-8"));
+    } catch (error) {
+      console.error(`❌ Failed to load ${path}:`, error.message);
+    }
+  }
+  globalThis.SALEOR_FIXTURES = JSON.stringify(fixtures);
+  console.log(`🧪 SALEOR_MODE=mock: serving fixtures from ${dir}`);
+}
+
 // Load environment variables BEFORE importing the worker
 loadEnvVars();
+loadFixtures();
 
 // Dynamically import the bundled worker
 const bundledWorker = await import("../dist/bundled.js");
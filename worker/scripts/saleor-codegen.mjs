@@ -0,0 +1,582 @@
+/**
+ * Generate typed Saleor operations from saleor/operations/*.graphql
+ *
+ * Every operation is checked against saleor/schema.graphql (a subset of
+ * Saleor's schema): unknown fields, arguments and types, missing required
+ * arguments and undeclared variables fail the script, and with it the
+ * build. src/saleorOperations.ts then exports each operation's document
+ * (ORDER_FULFILL_MUTATION) with the types of its result
+ * (OrderFulfillMutation) and variables (OrderFulfillMutationVariables).
+ * Runs before every build; commit the generated file.
+ *
+ * Usage:
+ *   npm run saleor:codegen
+ *   npm run saleor:codegen -- --check   (fail if the file is out of date)
+ */
+
+import { readFileSync, readdirSync, writeFileSync } from "fs";
+import { resolve, dirname } from "path";
+import { fileURLToPath } from "url";
+
+const __dirname = dirname(fileURLToPath(import.meta.url));
+const SALEOR_DIR = resolve(__dirname, "../saleor");
+const OUTPUT = resolve(__dirname, "../src/saleorOperations.ts");
+
+const SCALARS = {
+  ID: "string",
+  String: "string",
+  Int: "number",
+  Float: "number",
+  Boolean: "boolean",
+};
+
+// ============================================================
+// Lexer and parser (the subset of GraphQL used in these files)
+// ============================================================
+
+function tokenize(source, file) {
+  const tokens = [];
+  let i = 0;
+  while (i < source.length) {
+    const ch = source[i];
+    if (/[\s,﻿]/.test(ch)) {
+      i++;
+    } else if (ch === "#") {
+      while (i < source.length && source[i] !== "\n") i++;
+    } else if (source.startsWith('"""', i)) {
+      const end = source.indexOf('"""', i + 3);
+      if (end < 0) throw new Error(`${file}: unterminated block string`);
+      tokens.push({ kind: "string", value: source.slice(i + 3, end) });
+      i = end + 3;
+    } else if (ch === '"') {
+      let j = i + 1;
+      while (j < source.length && source[j] !== '"') j += source[j] === "\\" ? 2 : 1;
+      tokens.push({ kind: "string", value: source.slice(i + 1, j) });
+      i = j + 1;
+    } else if (source.startsWith("...", i)) {
+      tokens.push({ kind: "punct", value: "..." });
+      i += 3;
+    } else if ("!$():=@[]{}|&".includes(ch)) {
+      tokens.push({ kind: "punct", value: ch });
+      i++;
+    } else if (/[-0-9]/.test(ch)) {
+      let j = i + 1;
+      while (j < source.length && /[0-9.eE+-]/.test(source[j])) j++;
+      tokens.push({ kind: "number", value: source.slice(i, j) });
+      i = j;
+    } else if (/[_A-Za-z]/.test(ch)) {
+      let j = i + 1;
+      while (j < source.length && /[_0-9A-Za-z]/.test(source[j])) j++;
+      tokens.push({ kind: "name", value: source.slice(i, j) });
+      i = j;
+    } else {
+      throw new Error(`${file}: unexpected character "${ch}"`);
+    }
+  }
+  return tokens;
+}
+
+class Parser {
+  constructor(source, file) {
+    this.file = file;
+    this.tokens = tokenize(source, file);
+    this.pos = 0;
+  }
+
+  peek(value) {
+    const token = this.tokens[this.pos];
+    return token && (value === undefined || token.value === value) ? token : null;
+  }
+
+  next() {
+    const token = this.tokens[this.pos++];
+    if (!token) throw new Error(`${this.file}: unexpected end of file`);
+    return token;
+  }
+
+  expect(value) {
+    const token = this.next();
+    if (token.value !== value) {
+      throw new Error(`${this.file}: expected "${value}", got "${token.value}"`);
+    }
+    return token;
+  }
+
+  name() {
+    const token = this.next();
+    if (token.kind !== "name") throw new Error(`${this.file}: expected a name, got "${token.value}"`);
+    return token.value;
+  }
+
+  skipDescription() {
+    while (this.peek()?.kind === "string") this.pos++;
+  }
+
+  // Type reference: Name, [Type], Type!
+  typeRef() {
+    let type;
+    if (this.peek("[")) {
+      this.next();
+      type = { list: this.typeRef() };
+      this.expect("]");
+    } else {
+      type = { name: this.name() };
+    }
+    if (this.peek("!")) {
+      this.next();
+      type = { nonNull: type };
+    }
+    return type;
+  }
+
+  // Any value literal; variables used in it are collected
+  value(variables = []) {
+    const token = this.next();
+    if (token.value === "$") {
+      variables.push(this.name());
+    } else if (token.value === "[") {
+      while (!this.peek("]")) this.value(variables);
+      this.next();
+    } else if (token.value === "{") {
+      while (!this.peek("}")) {
+        this.name();
+        this.expect(":");
+        this.value(variables);
+      }
+      this.next();
+    }
+    return variables;
+  }
+
+  // Directives; returns their names, variables used in them are collected
+  directives(variables = []) {
+    const names = [];
+    while (this.peek("@")) {
+      this.next();
+      names.push(this.name());
+      if (this.peek("(")) {
+        this.next();
+        while (!this.peek(")")) {
+          this.name();
+          this.expect(":");
+          this.value(variables);
+        }
+        this.next();
+      }
+    }
+    return names;
+  }
+}
+
+function parseSchema(source, file) {
+  const p = new Parser(source, file);
+  const types = new Map();
+  while (p.peek()) {
+    p.skipDescription();
+    const keyword = p.name();
+    if (keyword === "schema") {
+      p.directives();
+      p.expect("{");
+      while (!p.peek("}")) p.next();
+      p.next();
+      continue;
+    }
+    const name = p.name();
+    if (keyword === "scalar") {
+      p.directives();
+      types.set(name, { kind: "scalar", name });
+    } else if (keyword === "enum") {
+      p.directives();
+      const values = [];
+      p.expect("{");
+      while (!p.peek("}")) {
+        p.skipDescription();
+        values.push(p.name());
+        p.directives();
+      }
+      p.next();
+      types.set(name, { kind: "enum", name, values });
+    } else if (keyword === "union") {
+      p.directives();
+      p.expect("=");
+      const members = [];
+      do {
+        if (p.peek("|")) p.next();
+        members.push(p.name());
+      } while (p.peek("|"));
+      types.set(name, { kind: "union", name, members });
+    } else if (keyword === "type" || keyword === "interface" || keyword === "input") {
+      if (p.peek("implements")) {
+        p.next();
+        do {
+          if (p.peek("&")) p.next();
+          p.name();
+        } while (p.peek("&"));
+      }
+      p.directives();
+      const fields = new Map();
+      p.expect("{");
+      while (!p.peek("}")) {
+        p.skipDescription();
+        const fieldName = p.name();
+        const args = new Map();
+        if (p.peek("(")) {
+          p.next();
+          while (!p.peek(")")) {
+            p.skipDescription();
+            const argName = p.name();
+            p.expect(":");
+            const type = p.typeRef();
+            let hasDefault = false;
+            if (p.peek("=")) {
+              p.next();
+              p.value();
+              hasDefault = true;
+            }
+            p.directives();
+            args.set(argName, { type, hasDefault });
+          }
+          p.next();
+        }
+        p.expect(":");
+        const type = p.typeRef();
+        let hasDefault = false;
+        if (p.peek("=")) {
+          p.next();
+          p.value();
+          hasDefault = true;
+        }
+        p.directives();
+        fields.set(fieldName, { type, args, hasDefault });
+      }
+      p.next();
+      types.set(name, { kind: keyword === "input" ? "input" : "object", name, fields });
+    } else {
+      throw new Error(`${file}: unsupported definition "${keyword}"`);
+    }
+  }
+  return types;
+}
+
+function parseSelections(p) {
+  const selections = [];
+  p.expect("{");
+  while (!p.peek("}")) {
+    if (p.peek("...")) {
+      throw new Error(`${p.file}: fragments are not supported, select the fields inline`);
+    }
+    let alias = p.name();
+    let name = alias;
+    if (p.peek(":")) {
+      p.next();
+      name = p.name();
+    }
+    const args = new Map();
+    if (p.peek("(")) {
+      p.next();
+      while (!p.peek(")")) {
+        const argName = p.name();
+        p.expect(":");
+        args.set(argName, p.value());
+      }
+      p.next();
+    }
+    const directiveVariables = [];
+    const directives = p.directives(directiveVariables);
+    // @include and @skip leave the field out of the result
+    const optional = directives.includes("include") || directives.includes("skip");
+    const children = p.peek("{") ? parseSelections(p) : null;
+    selections.push({ alias, name, args, directiveVariables, optional, children });
+  }
+  p.next();
+  return selections;
+}
+
+function parseOperation(source, file) {
+  const p = new Parser(source, file);
+  const type = p.name();
+  if (type !== "query" && type !== "mutation") {
+    throw new Error(`${file}: expected a query or mutation`);
+  }
+  const name = p.name();
+  const variables = new Map();
+  if (p.peek("(")) {
+    p.next();
+    while (!p.peek(")")) {
+      p.expect("$");
+      const variable = p.name();
+      p.expect(":");
+      const varType = p.typeRef();
+      let hasDefault = false;
+      if (p.peek("=")) {
+        p.next();
+        p.value();
+        hasDefault = true;
+      }
+      variables.set(variable, { type: varType, hasDefault });
+    }
+    p.next();
+  }
+  p.directives();
+  const selections = parseSelections(p);
+  if (p.peek()) {
+    throw new Error(`${file}: one operation per file`);
+  }
+  return { type, name, variables, selections };
+}
+
+// ============================================================
+// Validation
+// ============================================================
+
+function namedType(type) {
+  return type.name ?? namedType(type.list ?? type.nonNull);
+}
+
+function validate(operation, schema, file) {
+  const errors = [];
+  const root = schema.get(operation.type === "query" ? "Query" : "Mutation");
+  const used = new Set();
+
+  for (const [variable, { type }] of operation.variables) {
+    const name = namedType(type);
+    const definition = schema.get(name);
+    if (!SCALARS[name] && (!definition || definition.kind === "object")) {
+      errors.push(`$${variable}: unknown input type ${name}`);
+    }
+  }
+
+  const check = (selections, parent, path) => {
+    for (const selection of selections) {
+      const fieldPath = `${path}.${selection.name}`;
+      if (selection.name === "__typename") continue;
+      for (const variable of selection.directiveVariables) {
+        used.add(variable);
+        if (!operation.variables.has(variable)) {
+          errors.push(`${fieldPath}: $${variable} is not declared`);
+        }
+      }
+      const field = parent.fields?.get(selection.name);
+      if (!field) {
+        errors.push(`${fieldPath}: ${parent.name} has no field ${selection.name}`);
+        continue;
+      }
+      for (const [argName, variables] of selection.args) {
+        if (!field.args.has(argName)) {
+          errors.push(`${fieldPath}: unknown argument ${argName}`);
+        }
+        for (const variable of variables) {
+          used.add(variable);
+          if (!operation.variables.has(variable)) {
+            errors.push(`${fieldPath}: $${variable} is not declared`);
+          }
+        }
+      }
+      for (const [argName, arg] of field.args) {
+        if (arg.type.nonNull && !arg.hasDefault && !selection.args.has(argName)) {
+          errors.push(`${fieldPath}: missing required argument ${argName}`);
+        }
+      }
+      const typeName = namedType(field.type);
+      const type = schema.get(typeName);
+      if (!SCALARS[typeName] && !type) {
+        errors.push(`${fieldPath}: unknown type ${typeName}`);
+      } else if (type?.kind === "object" || type?.kind === "union") {
+        if (!selection.children) {
+          errors.push(`${fieldPath}: ${typeName} needs a selection`);
+        } else if (type.kind === "union") {
+          errors.push(`${fieldPath}: unions are not supported`);
+        } else {
+          check(selection.children, type, fieldPath);
+        }
+      } else if (selection.children) {
+        errors.push(`${fieldPath}: ${typeName} has no fields to select`);
+      }
+    }
+  };
+  if (!root) {
+    errors.push(`schema has no ${operation.type === "query" ? "Query" : "Mutation"} type`);
+  } else {
+    check(operation.selections, root, operation.name);
+  }
+  for (const variable of operation.variables.keys()) {
+    if (!used.has(variable)) errors.push(`$${variable} is never used`);
+  }
+  return errors.map((error) => `${file}: ${error}`);
+}
+
+// ============================================================
+// TypeScript output
+// ============================================================
+
+// Enums and input types get a Saleor prefix so they don't clash with ours
+function tsName(name) {
+  return `Saleor${name}`;
+}
+
+function tsType(type, schema, referenced, selections, indent) {
+  if (type.nonNull) {
+    return tsType(type.nonNull, schema, referenced, selections, indent, true).replace(/ \| null$/, "");
+  }
+  if (type.list) {
+    const inner = tsType(type.list, schema, referenced, selections, indent);
+    return `Array<${inner}> | null`;
+  }
+  if (SCALARS[type.name]) {
+    return `${SCALARS[type.name]} | null`;
+  }
+  const definition = schema.get(type.name);
+  if (definition.kind === "enum" || definition.kind === "input") {
+    referenced.add(type.name);
+    return `${tsName(type.name)} | null`;
+  }
+  if (definition.kind === "scalar") {
+    // Saleor's custom scalars (DateTime, JSONString, Decimal, ...) arrive as strings or numbers
+    return "any";
+  }
+  return `${objectType(selections, definition, schema, referenced, indent)} | null`;
+}
+
+function objectType(selections, parent, schema, referenced, indent) {
+  const pad = "  ".repeat(indent + 1);
+  const lines = selections.map((selection) => {
+    if (selection.name === "__typename") {
+      return `${pad}__typename: "${parent.name}";`;
+    }
+    const field = parent.fields.get(selection.name);
+    const type = tsType(field.type, schema, referenced, selection.children, indent + 1);
+    return `${pad}${selection.alias}${selection.optional ? "?" : ""}: ${type};`;
+  });
+  return `{\n${lines.join("\n")}\n${"  ".repeat(indent)}}`;
+}
+
+function variablesType(operation, schema, referenced) {
+  if (operation.variables.size === 0) {
+    return "Record<string, never>";
+  }
+  const lines = [...operation.variables].map(([name, { type, hasDefault }]) => {
+    const ts = tsType(type, schema, referenced, null, 1);
+    const optional = !type.nonNull || hasDefault ? "?" : "";
+    return `  ${name}${optional}: ${ts};`;
+  });
+  return `{\n${lines.join("\n")}\n}`;
+}
+
+function inputDefinitions(referenced, schema) {
+  const output = [];
+  const done = new Set();
+  const queue = [...referenced].sort();
+  while (queue.length > 0) {
+    const name = queue.shift();
+    if (done.has(name)) continue;
+    done.add(name);
+    const definition = schema.get(name);
+    if (definition.kind === "enum") {
+      const values = definition.values.map((value) => `  | "${value}"`).join("\n");
+      output.push(`export type ${tsName(name)} =\n${values};`);
+      continue;
+    }
+    const nested = new Set();
+    const lines = [...definition.fields].map(([fieldName, field]) => {
+      const ts = tsType(field.type, schema, nested, null, 1);
+      const optional = !field.type.nonNull || field.hasDefault ? "?" : "";
+      return `  ${fieldName}${optional}: ${ts};`;
+    });
+    output.push(`export interface ${tsName(name)} {\n${lines.join("\n")}\n}`);
+    queue.push(...[...nested].sort());
+  }
+  return output.sort((a, b) => a.localeCompare(b));
+}
+
+function constantName(operation) {
+  const words = operation.name.replace(/([a-z0-9])([A-Z])/g, "$1_$2").toUpperCase();
+  return `${words}_${operation.type.toUpperCase()}`;
+}
+
+function typeName(operation) {
+  return `${operation.name}${operation.type === "query" ? "Query" : "Mutation"}`;
+}
+
+function escapeTemplate(text) {
+  return text.replace(/\\/g, "\\\\").replace(/`/g, "\\`").replace(/\$\{/g, "\\${");
+}
+
+// ============================================================
+// Main
+// ============================================================
+
+const schema = parseSchema(
+  readFileSync(resolve(SALEOR_DIR, "schema.graphql"), "utf-8"),
+  "saleor/schema.graphql",
+);
+const files = readdirSync(resolve(SALEOR_DIR, "operations"))
+  .filter((file) => file.endsWith(".graphql"))
+  .sort();
+
+const errors = [];
+const blocks = [];
+const referenced = new Set();
+const names = new Set();
+
+for (const file of files) {
+  const path = `saleor/operations/${file}`;
+  const source = readFileSync(resolve(SALEOR_DIR, "operations", file), "utf-8");
+  const operation = parseOperation(source, path);
+  if (names.has(operation.name)) {
+    errors.push(`${path}: operation ${operation.name} is defined twice`);
+  }
+  names.add(operation.name);
+  const problems = validate(operation, schema, path);
+  if (problems.length > 0) {
+    errors.push(...problems);
+    continue;
+  }
+
+  const document = source
+    .split("\n")
+    .filter((line) => !line.trim().startsWith("#"))
+    .join("\n")
+    .trim();
+  const root = schema.get(operation.type === "query" ? "Query" : "Mutation");
+  blocks.push(
+    [
+      `// ${path}`,
+      `export const ${constantName(operation)} = \`\n${escapeTemplate(document)}\n\`;`,
+      "",
+      `export type ${typeName(operation)} = ${objectType(operation.selections, root, schema, referenced, 0)};`,
+      "",
+      `export type ${typeName(operation)}Variables = ${variablesType(operation, schema, referenced)};`,
+    ].join("\n"),
+  );
+}
+
+if (errors.length > 0) {
+  console.error(errors.join("\n"));
+  process.exit(1);
+}
+
+const output = [
+  "// Generated from worker/saleor by scripts/saleor-codegen.mjs - do not edit",
+  "",
+  ...inputDefinitions(referenced, schema).flatMap((definition) => [definition, ""]),
+  ...blocks.flatMap((block) => [block, ""]),
+]
+  .join("\n")
+  .replace(/\n+$/, "\n");
+
+if (process.argv.includes("--check")) {
+  let current = "";
+  try {
+    current = readFileSync(OUTPUT, "utf-8");
+  } catch {
+    // missing counts as out of date
+  }
+  if (current !== output) {
+    console.error("src/saleorOperations.ts is out of date, run npm run saleor:codegen");
+    process.exit(1);
+  }
+  console.log("src/saleorOperations.ts is up to date");
+} else {
+  writeFileSync(OUTPUT, output);
+  console.log(`Wrote src/saleorOperations.ts (${blocks.length} operations)`);
+}
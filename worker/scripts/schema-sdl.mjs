@@ -0,0 +1,28 @@
+/**
+ * Embed schema.graphql in the worker bundle for GraphQL introspection
+ *
+ * The worker has no filesystem, so the SDL is compiled into
+ * src/schemaSdl.ts. Runs before every build; commit the generated file.
+ *
+ * Usage:
+ *   npm run schema:sdl
+ */
+
+import { readFileSync, writeFileSync } from "fs";
+import { resolve, dirname } from "path";
+import { fileURLToPath } from "url";
+
+const __dirname = dirname(fileURLToPath(import.meta.url));
+
+const sdl = readFileSync(resolve(__dirname, "../schema.graphql"), "utf-8");
+const escaped = sdl
+  .replace(/\\/g, "\\\\")
+  .replace(/`/g, "\\`")
+  .replace(/\$\{/g, "\\${");
+
+writeFileSync(
+  resolve(__dirname, "../src/schemaSdl.ts"),
+  "// Generated from worker/schema.graphql by scripts/schema-sdl.mjs - do not edit\n" +
+    `export const SCHEMA_SDL = \`${escaped}\`;\n`,
+);
+console.log("Wrote src/schemaSdl.ts");
@@ -0,0 +1,146 @@
+// Abuse Detection Tests
+// Tests for abuseDetection.ts - ordering heuristics and the review queue
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { SaleorOrder, clearOrders, createSaleorOrder } from "./saleorOrder";
+import {
+  checkCoordinates,
+  checkOrderHistory,
+  flagOrderForReview,
+  listAbuseFlags,
+  reviewAbuseFlag,
+} from "./abuseDetection";
+import { distanceKm } from "./geo";
+import { markOrderDelivered } from "./orderFulfillment";
+import { verifyHandoffCode } from "./orderHandoff";
+import { createInvoice } from "./telegramPayments";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const now = new Date("2026-10-18T12:00:00Z");
+
+function order(id: string, minutesAgo: number, status: SaleorOrder["status"] = "UNFULFILLED"): SaleorOrder {
+  return {
+    id,
+    status,
+    channelId: "ch-1",
+    total: { gross: { amount: 10, currency: "USD" } },
+    deliveryAddress: { address: "1 Main St" },
+    lines: [],
+    createdAt: new Date(now.getTime() - minutesAgo * 60 * 1000).toISOString(),
+  };
+}
+
+describe("checkCoordinates", () => {
+  const metadata = { tma_location: "52.52, 13.405" };
+
+  it("should accept a nearby location or none at all", () => {
+    expect(checkCoordinates({ address: "x", latitude: 52.5, longitude: 13.4 }, metadata)).toBeNull();
+    expect(checkCoordinates({ address: "x" }, metadata)).toBeNull();
+  });
+
+  it("should flag null island and half a pair", () => {
+    expect(checkCoordinates({ address: "x", latitude: 0, longitude: 0 }, undefined)?.type).toBe(
+      "UNREALISTIC_COORDINATES",
+    );
+    expect(checkCoordinates({ address: "x", latitude: 52.5 }, undefined)?.type).toBe(
+      "UNREALISTIC_COORDINATES",
+    );
+  });
+
+  it("should flag locations far from the restaurant", () => {
+    const signal = checkCoordinates({ address: "x", latitude: 48.85, longitude: 2.35 }, metadata);
+    expect(signal?.detail).toBe("878 km from the restaurant");
+  });
+
+  it("should measure great-circle distance", () => {
+    expect(
+      Math.round(distanceKm({ latitude: 0, longitude: 0 }, { latitude: 0, longitude: 1 })),
+    ).toBe(111);
+  });
+});
+
+describe("checkOrderHistory", () => {
+  afterEach(() => {
+    delete (globalThis as any).ABUSE_MAX_CANCELLED_ORDERS;
+  });
+
+  it("should not flag a normal history", () => {
+    expect(checkOrderHistory([order("o1", 60 * 24), order("o2", 30)], now)).toEqual([]);
+  });
+
+  it("should flag repeated cancellations within 30 days", () => {
+    const orders = [
+      order("o1", 60, "CANCELLED"),
+      order("o2", 60 * 24, "CANCELLED"),
+      order("o3", 60 * 24 * 40, "CANCELLED"),
+    ];
+    expect(checkOrderHistory(orders, now)).toEqual([]);
+
+    (globalThis as any).ABUSE_MAX_CANCELLED_ORDERS = "2";
+    expect(checkOrderHistory(orders, now)).toEqual([
+      { type: "CANCELLED_ORDERS", detail: "2 cancelled orders in 30 days" },
+    ]);
+  });
+
+  it("should flag rapid repeat orders", () => {
+    expect(checkOrderHistory([order("o1", 2), order("o2", 5)], now)).toEqual([
+      { type: "RAPID_REPEAT_ORDERS", detail: "3 orders in 10 minutes" },
+    ]);
+  });
+});
+
+describe("review queue", () => {
+  it("should queue flags and record the review", async () => {
+    const signals = [{ type: "RAPID_REPEAT_ORDERS" as const, detail: "3 orders in 10 minutes" }];
+    const flag = await flagOrderForReview(order("queued-1", 0), "user-9", "ch-queue", signals);
+
+    expect(flag).toMatchObject({ status: "PENDING", requiresApproval: false, signals });
+    expect((await listAbuseFlags("ch-queue", "PENDING")).map((f) => f.id)).toEqual([flag.id]);
+
+    const reviewed = await reviewAbuseFlag({ flagId: flag.id, decision: "APPROVE" }, "admin-1");
+    expect(reviewed).toMatchObject({ status: "APPROVED", reviewedBy: "admin-1" });
+    expect(await listAbuseFlags("ch-queue", "PENDING")).toEqual([]);
+
+    await expect(
+      reviewAbuseFlag({ flagId: flag.id, decision: "REJECT" }, "admin-1"),
+    ).rejects.toThrow("Flag was already reviewed");
+  });
+});
+
+describe("orders held for review", () => {
+  afterEach(() => {
+    clearOrders();
+    delete (globalThis as any).ABUSE_REQUIRE_APPROVAL;
+    delete (globalThis as any).TELEGRAM_STARS_RATE;
+  });
+
+  it("should not be paid, delivered or handed over until approved", async () => {
+    (globalThis as any).ABUSE_REQUIRE_APPROVAL = "true";
+    (globalThis as any).TELEGRAM_STARS_RATE = "50";
+    const placed = await createSaleorOrder(
+      {
+        restaurantId: "ch-held",
+        deliveryLocation: { address: "1 Main St" },
+        items: [{ dishId: "dish-1", quantity: 1 }],
+      },
+      "user-held",
+    );
+    const held = placed.order!;
+    const signals = [{ type: "RAPID_REPEAT_ORDERS" as const, detail: "3 orders in 10 minutes" }];
+    const flag = await flagOrderForReview(held, "user-held", "ch-held", signals);
+
+    const awaitingReview = { code: "BAD_USER_INPUT", message: "Order is awaiting review" };
+    await expect(createInvoice(held.id, "user-held")).rejects.toMatchObject(awaitingReview);
+    await expect(markOrderDelivered(held.id, "courier")).rejects.toMatchObject(awaitingReview);
+    await expect(verifyHandoffCode(held.id, "0000", "staff")).rejects.toMatchObject(
+      awaitingReview,
+    );
+
+    await reviewAbuseFlag({ flagId: flag.id, decision: "APPROVE" }, "admin-1");
+    expect(await verifyHandoffCode(held.id, "0000", "staff")).toMatchObject({ valid: false });
+  });
+});
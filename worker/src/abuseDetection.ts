@@ -0,0 +1,247 @@
+// Abuse Detection
+// Heuristics run when an order is placed: a history of cancelled orders,
+// delivery coordinates that can't be real (null island, half a pair, or
+// further than ABUSE_MAX_DELIVERY_KM from the restaurant's tma_location)
+// and bursts of repeat orders. Orders that trip any signal land in an admin
+// review queue (abuse-flag:<id>). With ABUSE_REQUIRE_APPROVAL the order is
+// also held until the flag is approved: it can't be paid (invoice, payment
+// gateways), accepted, marked delivered or handed over, and rejecting the
+// flag rejects the order.
+
+import {
+  AbuseFlag,
+  AbuseFlagStatus,
+  AbuseSignal,
+  DeliveryLocation,
+  ReviewAbuseFlagInput,
+} from "./contracts";
+import { getBooleanVar, getNumberVar } from "./config";
+import { badUserInputError, notFoundError } from "./errors";
+import { logger } from "./logger";
+import { LOCATION_METADATA_KEY, distanceKm, parseCoordinates } from "./geo";
+import { rejectOrder } from "./paymentHolds";
+import {
+  ORDER_METADATA_KEYS,
+  SaleorOrder,
+  fetchUserOrders,
+  getNormalizedStatus,
+  updateOrderMetadata,
+} from "./saleorOrder";
+import { readAllJSON, readJSON, writeJSON } from "./storage";
+
+const FLAG_PREFIX = "abuse-flag:";
+const CANCELLATION_WINDOW_DAYS = 30;
+
+const REVIEW_PENDING = "PENDING";
+
+function getKey(flagId: string): string {
+  return `${FLAG_PREFIX}${flagId}`;
+}
+
+/**
+ * Whether flagged orders wait for an admin before they can be accepted
+ * (ABUSE_REQUIRE_APPROVAL)
+ */
+export function isApprovalRequired(): boolean {
+  return getBooleanVar("ABUSE_REQUIRE_APPROVAL");
+}
+
+/**
+ * Signal for delivery coordinates that can't be a real drop-off point
+ */
+export function checkCoordinates(
+  location: DeliveryLocation | undefined,
+  restaurantMetadata: Record<string, string> | undefined,
+): AbuseSignal | null {
+  const lat = location?.latitude;
+  const lng = location?.longitude;
+  const hasLat = lat !== undefined && lat !== null;
+  const hasLng = lng !== undefined && lng !== null;
+  if (!hasLat && !hasLng) {
+    return null;
+  }
+  if (hasLat !== hasLng) {
+    return { type: "UNREALISTIC_COORDINATES", detail: "Only one coordinate given" };
+  }
+  if (lat === 0 && lng === 0) {
+    return { type: "UNREALISTIC_COORDINATES", detail: "Coordinates are 0,0" };
+  }
+
+  const restaurant = parseCoordinates(restaurantMetadata?.[LOCATION_METADATA_KEY]);
+  if (restaurant) {
+    const maxKm = getNumberVar("ABUSE_MAX_DELIVERY_KM", 50);
+    const km = distanceKm(restaurant, { latitude: lat as number, longitude: lng as number });
+    if (km > maxKm) {
+      return {
+        type: "UNREALISTIC_COORDINATES",
+        detail: `${Math.round(km)} km from the restaurant`,
+      };
+    }
+  }
+  return null;
+}
+
+/**
+ * Signals from a user's order history (newest first)
+ */
+export function checkOrderHistory(orders: SaleorOrder[], now: Date = new Date()): AbuseSignal[] {
+  const signals: AbuseSignal[] = [];
+
+  const maxCancelled = getNumberVar("ABUSE_MAX_CANCELLED_ORDERS", 3);
+  const cancelledSince = now.getTime() - CANCELLATION_WINDOW_DAYS * 24 * 60 * 60 * 1000;
+  const cancelled = orders.filter((order) => {
+    const status = getNormalizedStatus(order);
+    return (
+      (status === "CANCELLED" || status === "EXPIRED") &&
+      new Date(order.createdAt).getTime() >= cancelledSince
+    );
+  }).length;
+  if (cancelled >= maxCancelled) {
+    signals.push({
+      type: "CANCELLED_ORDERS",
+      detail: `${cancelled} cancelled orders in ${CANCELLATION_WINDOW_DAYS} days`,
+    });
+  }
+
+  // Counting this order too, so 3 means "the third within the window"
+  const maxRepeat = getNumberVar("ABUSE_MAX_RAPID_ORDERS", 3);
+  const windowMinutes = getNumberVar("ABUSE_RAPID_ORDER_MINUTES", 10);
+  const recentSince = now.getTime() - windowMinutes * 60 * 1000;
+  const recent = orders.filter(
+    (order) => new Date(order.createdAt).getTime() >= recentSince,
+  ).length;
+  if (recent + 1 >= maxRepeat) {
+    signals.push({
+      type: "RAPID_REPEAT_ORDERS",
+      detail: `${recent + 1} orders in ${windowMinutes} minutes`,
+    });
+  }
+
+  return signals;
+}
+
+/**
+ * Run every heuristic for an order about to be placed
+ * Pickup orders pass no delivery location
+ */
+export async function detectAbuseSignals(
+  userId: string,
+  deliveryLocation: DeliveryLocation | undefined,
+  restaurantMetadata: Record<string, string> | undefined,
+  now: Date = new Date(),
+): Promise<AbuseSignal[]> {
+  const signals = checkOrderHistory(await fetchUserOrders(userId), now);
+  const coordinates = checkCoordinates(deliveryLocation, restaurantMetadata);
+  return coordinates ? [coordinates, ...signals] : signals;
+}
+
+/**
+ * Put a placed order in the review queue, holding it when approval is required
+ */
+export async function flagOrderForReview(
+  order: SaleorOrder,
+  userId: string,
+  restaurantId: string,
+  signals: AbuseSignal[],
+): Promise<AbuseFlag> {
+  const flag: AbuseFlag = {
+    id: crypto.randomUUID(),
+    orderId: order.id,
+    orderNumber: order.number,
+    restaurantId,
+    userId,
+    signals,
+    requiresApproval: isApprovalRequired(),
+    status: "PENDING",
+    createdAt: new Date().toISOString(),
+    reviewedAt: null,
+    reviewedBy: null,
+    note: null,
+  };
+  await writeJSON(getKey(flag.id), flag);
+  if (flag.requiresApproval) {
+    await updateOrderMetadata(order.id, {
+      [ORDER_METADATA_KEYS.abuseReview]: REVIEW_PENDING,
+    });
+  }
+  logger.warn("order_flagged_for_review", {
+    flagId: flag.id,
+    orderId: order.id,
+    userId,
+    signals: signals.map((signal) => signal.type).join(","),
+    requiresApproval: flag.requiresApproval,
+  });
+  return flag;
+}
+
+export async function getAbuseFlag(flagId: string): Promise<AbuseFlag | null> {
+  return readJSON<AbuseFlag>(getKey(flagId));
+}
+
+/**
+ * Review queue, oldest first
+ * Omitting restaurantId lists every restaurant (callers enforce superadmin)
+ */
+export async function listAbuseFlags(
+  restaurantId?: string,
+  status?: AbuseFlagStatus,
+  userId?: string,
+): Promise<AbuseFlag[]> {
+  const flags = await readAllJSON<AbuseFlag>(FLAG_PREFIX);
+  return flags
+    .filter((f) => !restaurantId || f.restaurantId === restaurantId)
+    .filter((f) => !status || f.status === status)
+    .filter((f) => !userId || f.userId === userId)
+    .sort((a, b) => a.createdAt.localeCompare(b.createdAt));
+}
+
+/**
+ * Look up the restaurant a flag belongs to (for permission checks)
+ */
+export async function getAbuseFlagRestaurantId(flagId: string): Promise<string> {
+  const flag = flagId ? await getAbuseFlag(flagId) : null;
+  if (!flag) {
+    throw notFoundError("Flag not found");
+  }
+  return flag.restaurantId;
+}
+
+/**
+ * Approve or reject a flagged order
+ * Rejecting a held order rejects it (voiding any payment hold)
+ */
+export async function reviewAbuseFlag(
+  input: ReviewAbuseFlagInput,
+  reviewerId: string,
+): Promise<AbuseFlag> {
+  const flag = await getAbuseFlag(input.flagId);
+  if (!flag) {
+    throw notFoundError("Flag not found");
+  }
+  if (flag.status !== "PENDING") {
+    throw badUserInputError("Flag was already reviewed", "flagId");
+  }
+
+  if (flag.requiresApproval) {
+    if (input.decision === "REJECT") {
+      await rejectOrder(flag.orderId, null, reviewerId, "FRAUD_CHECK");
+    }
+    await updateOrderMetadata(flag.orderId, {
+      [ORDER_METADATA_KEYS.abuseReview]: input.decision === "APPROVE" ? "APPROVED" : "REJECTED",
+    });
+  }
+
+  flag.status = input.decision === "APPROVE" ? "APPROVED" : "REJECTED";
+  flag.reviewedAt = new Date().toISOString();
+  flag.reviewedBy = reviewerId;
+  flag.note = input.note?.trim() || null;
+  await writeJSON(getKey(flag.id), flag);
+
+  logger.info("abuse_flag_reviewed", {
+    flagId: flag.id,
+    orderId: flag.orderId,
+    decision: input.decision,
+    reviewerId,
+  });
+  return flag;
+}
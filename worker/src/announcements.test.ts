@@ -0,0 +1,27 @@
+// Restaurant Announcement Tests
+// Tests for announcements.ts - active announcement and expiry
+
+import { describe, it, expect } from "vitest";
+import { getActiveAnnouncement } from "./announcements";
+
+describe("getActiveAnnouncement", () => {
+  const now = new Date("2026-05-01T12:00:00Z");
+
+  it("should return the announcement until it expires", () => {
+    const channel = {
+      metadata: {
+        tma_announcement: "No deliveries today after 20:00",
+        tma_announcement_expires_at: "2026-05-01T20:00:00Z",
+      },
+    };
+    expect(getActiveAnnouncement(channel, now)).toBe(
+      "No deliveries today after 20:00",
+    );
+    expect(getActiveAnnouncement(channel, new Date("2026-05-01T21:00:00Z"))).toBeNull();
+  });
+
+  it("should treat a cleared announcement as none", () => {
+    expect(getActiveAnnouncement({ metadata: { tma_announcement: "" } }, now)).toBeNull();
+    expect(getActiveAnnouncement({}, now)).toBeNull();
+  });
+});
@@ -0,0 +1,77 @@
+// Restaurant Announcements
+// A short owner-set notice ("No deliveries today after 20:00") stored in
+// channel metadata (tma_announcement, optional tma_announcement_expires_at),
+// shown as Restaurant.announcement and appended to order confirmations.
+
+import { Channel, RestaurantAnnouncement } from "./contracts";
+import { badUserInputError, notFoundError } from "./errors";
+import { logger } from "./logger";
+import { fetchChannelById, updateChannelMetadata } from "./saleorService";
+
+export const ANNOUNCEMENT_METADATA_KEY = "tma_announcement";
+export const ANNOUNCEMENT_EXPIRES_METADATA_KEY = "tma_announcement_expires_at";
+
+export const MAX_ANNOUNCEMENT_LENGTH = 200;
+
+/**
+ * Announcement currently shown for a channel, if any
+ */
+export function getActiveAnnouncement(
+  channel: Pick<Channel, "metadata">,
+  now: Date = new Date(),
+): string | null {
+  const message = channel.metadata?.[ANNOUNCEMENT_METADATA_KEY]?.trim();
+  if (!message) {
+    return null;
+  }
+  const expiresAt = channel.metadata?.[ANNOUNCEMENT_EXPIRES_METADATA_KEY];
+  if (expiresAt && new Date(expiresAt) <= now) {
+    return null;
+  }
+  return message;
+}
+
+/**
+ * Set or clear (empty message) a restaurant's announcement
+ */
+export async function setRestaurantAnnouncement(
+  restaurantId: string,
+  message: string,
+  expiresAt: string | null | undefined,
+  updatedBy: string,
+): Promise<RestaurantAnnouncement> {
+  const channel = await fetchChannelById(restaurantId);
+  if (!channel) {
+    throw notFoundError("Restaurant not found");
+  }
+
+  const text = message.trim().replace(/\s+/g, " ");
+  if (text.length > MAX_ANNOUNCEMENT_LENGTH) {
+    throw badUserInputError(
+      `Announcement must be at most ${MAX_ANNOUNCEMENT_LENGTH} characters`,
+      "message",
+    );
+  }
+  if (expiresAt && new Date(expiresAt) <= new Date()) {
+    throw badUserInputError("Expiry must be in the future", "expiresAt");
+  }
+
+  const saved = await updateChannelMetadata(restaurantId, {
+    [ANNOUNCEMENT_METADATA_KEY]: text,
+    [ANNOUNCEMENT_EXPIRES_METADATA_KEY]: text && expiresAt ? expiresAt : "",
+  });
+  if (!saved) {
+    throw badUserInputError("Could not save the announcement, please try again");
+  }
+
+  logger.info("restaurant_announcement_updated", {
+    restaurantId,
+    updatedBy,
+    cleared: !text,
+  });
+  return {
+    restaurantId,
+    message: text || null,
+    expiresAt: text && expiresAt ? expiresAt : null,
+  };
+}
@@ -0,0 +1,103 @@
+// Dish Availability Tests
+// Tests for availability.ts - channel listing rules for menus and purchases
+
+import { describe, it, expect } from "vitest";
+import {
+  checkChannelListing,
+  findOtherRestaurant,
+  isSoldOut,
+  ProductChannelListing,
+} from "./availability";
+import { ErrorCode, itemsUnavailableError, mixedRestaurantsError } from "./errors";
+
+const listing: ProductChannelListing = {
+  channel: { id: "channel-1" },
+  isPublished: true,
+  visibleInListings: true,
+  isAvailableForPurchase: true,
+  availableForPurchaseAt: "2026-01-01T00:00:00Z",
+};
+
+describe("checkChannelListing", () => {
+  it("should allow published, visible, purchasable dishes", () => {
+    expect(checkChannelListing([listing], "channel-1", "LISTING")).toBeNull();
+    expect(checkChannelListing([listing], "channel-1", "PURCHASE")).toBeNull();
+  });
+
+  it("should reject dishes not listed or unpublished in the channel", () => {
+    expect(checkChannelListing([listing], "channel-2", "PURCHASE")).toBe("NOT_IN_CHANNEL");
+    expect(
+      checkChannelListing([{ ...listing, isPublished: false }], "channel-1", "LISTING"),
+    ).toBe("UNPUBLISHED");
+  });
+
+  it("should hide dishes from menus but still sell them", () => {
+    const hidden = { ...listing, visibleInListings: false };
+    expect(checkChannelListing([hidden], "channel-1", "LISTING")).toBe("HIDDEN");
+    expect(checkChannelListing([hidden], "channel-1", "PURCHASE")).toBeNull();
+  });
+
+  it("should use availableForPurchaseAt when the flag is missing", () => {
+    const scheduled = {
+      ...listing,
+      isAvailableForPurchase: null,
+      availableForPurchaseAt: "2026-06-01T00:00:00Z",
+    };
+    const before = new Date("2026-05-01T00:00:00Z");
+    const after = new Date("2026-07-01T00:00:00Z");
+    expect(checkChannelListing([scheduled], "channel-1", "PURCHASE", before)).toBe(
+      "NOT_AVAILABLE_FOR_PURCHASE",
+    );
+    expect(checkChannelListing([scheduled], "channel-1", "PURCHASE", after)).toBeNull();
+  });
+});
+
+describe("stock", () => {
+  it("should only treat tracked stock as sold out", () => {
+    expect(isSoldOut(null)).toBe(false);
+    expect(isSoldOut(undefined)).toBe(false);
+    expect(isSoldOut(0)).toBe(true);
+    expect(isSoldOut(2, 2)).toBe(false);
+    expect(isSoldOut(2, 3)).toBe(true);
+  });
+
+  it("should list unavailable dishes in the error", () => {
+    const error = itemsUnavailableError("2 dishes are not available: a, b", [
+      { dishId: "a", reason: "SOLD_OUT" },
+      { dishId: "b", reason: "NOT_IN_CHANNEL" },
+    ]);
+    expect(error.statusCode).toBe(409);
+    expect(error.toGraphQL()).toMatchObject({
+      code: ErrorCode.ITEMS_UNAVAILABLE,
+      field: "items",
+      unavailableItems: [
+        { dishId: "a", reason: "SOLD_OUT" },
+        { dishId: "b", reason: "NOT_IN_CHANNEL" },
+      ],
+    });
+  });
+});
+
+describe("restaurant ownership", () => {
+  it("should find the restaurant a dish is listed in instead", () => {
+    const listings = [{ channel: { id: "channel-2" } }, { channel: { id: "channel-3" } }];
+    expect(findOtherRestaurant(listings, "channel-1")).toBe("channel-2");
+    expect(findOtherRestaurant([{ channel: { id: "channel-1" } }], "channel-1")).toBeNull();
+    expect(findOtherRestaurant(null, "channel-1")).toBeNull();
+  });
+
+  it("should list dishes from other restaurants with their owner", () => {
+    const error = mixedRestaurantsError([
+      { dishId: "a", reason: "OTHER_RESTAURANT", restaurantId: "channel-2" },
+    ]);
+    expect(error.statusCode).toBe(409);
+    expect(error.message).toBe(
+      "Dish a is from another restaurant. An order can only have dishes from one restaurant.",
+    );
+    expect(error.toGraphQL()).toMatchObject({
+      code: ErrorCode.MIXED_RESTAURANTS,
+      field: "items",
+      unavailableItems: [{ dishId: "a", reason: "OTHER_RESTAURANT", restaurantId: "channel-2" }],
+    });
+  });
+});
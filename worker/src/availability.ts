@@ -0,0 +1,209 @@
+// Channel-Aware Dish Availability
+// The single place that decides whether a dish can be shown or bought in a
+// restaurant's channel, from Saleor product channel listings (published,
+// visible in listings, available for purchase) and variant channel
+// listings. Menu queries check LISTING; cart changes and placeOrder check
+// PURCHASE, plus stock (variant quantityAvailable) when it is tracked.
+// A restaurant owns the dishes listed in its channel (categories are shared
+// product types, not per restaurant), so a dish missing from the channel but
+// listed in another one is OTHER_RESTAURANT; placeOrder rejects those with
+// MIXED_RESTAURANTS. Without Saleor (mock data) every dish is available.
+
+import { UnavailableItem, itemsUnavailableError, mixedRestaurantsError } from "./errors";
+import { logger } from "./logger";
+import {
+  getSaleorClient,
+  isSaleorConfigured,
+  DISH_AVAILABILITY_QUERY,
+} from "./saleorClient";
+import { DishAvailabilityQuery, DishAvailabilityQueryVariables } from "./saleorOperations";
+
+export type AvailabilityPurpose = "LISTING" | "PURCHASE";
+
+export type UnavailableReason =
+  | "NOT_FOUND"
+  | "NOT_IN_CHANNEL"
+  | "OTHER_RESTAURANT"
+  | "UNPUBLISHED"
+  | "HIDDEN"
+  | "NOT_AVAILABLE_FOR_PURCHASE"
+  | "SOLD_OUT";
+
+/**
+ * Saleor ProductChannelListing fields used for availability
+ */
+export interface ProductChannelListing {
+  channel: { id: string };
+  isPublished: boolean;
+  visibleInListings?: boolean;
+  isAvailableForPurchase?: boolean | null;
+  availableForPurchaseAt?: string | null;
+}
+
+const REASON_MESSAGES: Record<UnavailableReason, string> = {
+  NOT_FOUND: "does not exist",
+  NOT_IN_CHANNEL: "is not sold by this restaurant",
+  OTHER_RESTAURANT: "is from another restaurant",
+  UNPUBLISHED: "is not published",
+  HIDDEN: "is hidden from the menu",
+  NOT_AVAILABLE_FOR_PURCHASE: "is not available for purchase right now",
+  SOLD_OUT: "is sold out",
+};
+
+/**
+ * Why a product cannot be listed/bought in a channel, or null when it can
+ */
+export function checkChannelListing(
+  listings: ProductChannelListing[] | null | undefined,
+  channelId: string,
+  purpose: AvailabilityPurpose,
+  now: Date = new Date(),
+): UnavailableReason | null {
+  const listing = (listings || []).find((l) => l.channel?.id === channelId);
+  if (!listing) {
+    return "NOT_IN_CHANNEL";
+  }
+  if (!listing.isPublished) {
+    return "UNPUBLISHED";
+  }
+  if (purpose === "LISTING") {
+    return listing.visibleInListings === false ? "HIDDEN" : null;
+  }
+  const purchasable =
+    listing.isAvailableForPurchase ??
+    (listing.availableForPurchaseAt
+      ? new Date(listing.availableForPurchaseAt) <= now
+      : false);
+  return purchasable ? null : "NOT_AVAILABLE_FOR_PURCHASE";
+}
+
+/**
+ * Whether tracked stock can't cover the quantity (null means not tracked)
+ */
+export function isSoldOut(
+  quantityAvailable: number | null | undefined,
+  quantity: number = 1,
+): boolean {
+  return (
+    quantityAvailable !== null &&
+    quantityAvailable !== undefined &&
+    quantityAvailable < Math.max(1, quantity)
+  );
+}
+
+/**
+ * Another channel a dish is listed in, i.e. the restaurant it belongs to
+ * when it isn't listed in channelId (null when listed nowhere else)
+ */
+export function findOtherRestaurant(
+  listings: Array<{ channel: { id: string } }> | null | undefined,
+  channelId: string,
+): string | null {
+  const other = (listings || []).find((l) => l.channel?.id && l.channel.id !== channelId);
+  return other ? other.channel.id : null;
+}
+
+/**
+ * Dishes (variant or product IDs) that cannot be bought in a channel
+ * quantities (dish ID -> count) makes PURCHASE checks require enough stock;
+ * product IDs are checked against their first variant, as menus price them
+ */
+export async function findUnavailableDishes(
+  dishIds: string[],
+  channelId: string,
+  purpose: AvailabilityPurpose = "PURCHASE",
+  quantities: Record<string, number> = {},
+): Promise<UnavailableItem[]> {
+  const ids = Array.from(new Set(dishIds.filter(Boolean)));
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client || ids.length === 0) {
+    return [];
+  }
+
+  const variables: DishAvailabilityQueryVariables = { ids };
+  const response = await client.execute<DishAvailabilityQuery>(DISH_AVAILABILITY_QUERY, variables);
+  if (response.errors && response.errors.length > 0) {
+    // Saleor rejects unavailable lines at order creation anyway
+    logger.warn("dish_availability_check_failed", {
+      error: response.errors.map((e) => e.message).join(", "),
+      channelId,
+    });
+    return [];
+  }
+
+  const variants = new Map(
+    (response.data?.productVariants?.edges || []).map((e) => [e.node.id, e.node]),
+  );
+  const products = new Map(
+    (response.data?.products?.edges || []).map((e) => [e.node.id, e.node]),
+  );
+
+  const unavailable: UnavailableItem[] = [];
+  for (const dishId of ids) {
+    const variant = variants.get(dishId);
+    let reason: UnavailableReason | null;
+    let quantityAvailable: number | null | undefined;
+    let listings: Array<{ channel: { id: string } }> | null = null;
+    if (variant) {
+      const variantListed = (variant.channelListings || []).some(
+        (l) => l.channel?.id === channelId,
+      );
+      reason = variantListed
+        ? checkChannelListing(variant.product.channelListings, channelId, purpose)
+        : "NOT_IN_CHANNEL";
+      quantityAvailable = variant.quantityAvailable;
+      listings = variant.channelListings;
+    } else if (products.has(dishId)) {
+      const product = products.get(dishId)!;
+      reason = checkChannelListing(product.channelListings, channelId, purpose);
+      quantityAvailable = product.variants?.[0]?.quantityAvailable;
+      listings = product.channelListings;
+    } else {
+      reason = "NOT_FOUND";
+    }
+    if (!reason && purpose === "PURCHASE" && isSoldOut(quantityAvailable, quantities[dishId])) {
+      reason = "SOLD_OUT";
+    }
+    const owner = reason === "NOT_IN_CHANNEL" ? findOtherRestaurant(listings, channelId) : null;
+    if (owner) {
+      unavailable.push({ dishId, reason: "OTHER_RESTAURANT", restaurantId: owner });
+    } else if (reason) {
+      unavailable.push({ dishId, reason });
+    }
+  }
+  return unavailable;
+}
+
+/**
+ * Throw MIXED_RESTAURANTS when a dish belongs to another restaurant, or
+ * ITEMS_UNAVAILABLE when a dish cannot be bought in the channel, listing the
+ * offending dishes
+ */
+export async function assertDishesAvailable(
+  dishIds: string[],
+  channelId: string,
+  field: string = "items",
+  quantities: Record<string, number> = {},
+): Promise<void> {
+  const unavailable = await findUnavailableDishes(
+    dishIds,
+    channelId,
+    "PURCHASE",
+    quantities,
+  );
+  if (unavailable.length === 0) {
+    return;
+  }
+  const foreign = unavailable.filter((u) => u.reason === "OTHER_RESTAURANT");
+  if (foreign.length > 0) {
+    throw mixedRestaurantsError(foreign, field);
+  }
+  const [first] = unavailable;
+  const message =
+    unavailable.length === 1
+      ? `Dish ${first.dishId} ${REASON_MESSAGES[first.reason as UnavailableReason]}`
+      : `${unavailable.length} dishes are not available: ${unavailable
+          .map((u) => u.dishId)
+          .join(", ")}`;
+  throw itemsUnavailableError(message, unavailable, field);
+}
@@ -0,0 +1,31 @@
+// Background Tasks
+// Work that must not hold up the response (pipeline shadow runs, audit
+// writes) is handed to the fetch event's waitUntil. The hook is
+// request-scoped (AsyncLocalStorage, like the Saleor target); outside a
+// request the task still runs, it just doesn't extend the event's lifetime.
+
+import { AsyncLocalStorage } from "node:async_hooks";
+import { logger } from "./logger";
+
+export type WaitUntil = (promise: Promise<unknown>) => void;
+
+const waitUntilStorage = new AsyncLocalStorage<WaitUntil>();
+
+/**
+ * Run a function with background tasks attached to the given waitUntil
+ */
+export function runWithWaitUntil<T>(waitUntil: WaitUntil, fn: () => T): T {
+  return waitUntilStorage.run(waitUntil, fn);
+}
+
+/**
+ * Start a task without awaiting it; failures are logged as errorEvent
+ */
+export function runInBackground(task: () => Promise<unknown>, errorEvent: string): void {
+  const promise = task().catch((error) => {
+    logger.error(errorEvent, {
+      error: error instanceof Error ? error.message : "Unknown error",
+    });
+  });
+  waitUntilStorage.getStore()?.(promise);
+}
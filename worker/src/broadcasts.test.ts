@@ -0,0 +1,128 @@
+// Broadcast Tests
+// Tests for broadcasts.ts - recipient queues, batching, 429 requeue and aborts
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { clearOrders, createSaleorOrder } from "./saleorOrder";
+import { deliverTelegramMessage } from "./notifications";
+import { listKeys } from "./storage";
+import {
+  abortBroadcast,
+  getBroadcast,
+  runPendingBroadcasts,
+  startBroadcast,
+} from "./broadcasts";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+vi.mock("./notifications", async (importOriginal) => ({
+  ...(await importOriginal<typeof import("./notifications")>()),
+  deliverTelegramMessage: vi.fn(async () => ({ ok: true, status: 200 })),
+}));
+
+async function createOrders(userIds: string[]): Promise<void> {
+  for (const userId of userIds) {
+    await createSaleorOrder(
+      {
+        restaurantId: "restA",
+        deliveryLocation: { address: "1 Test Street" },
+        items: [{ dishId: "dish1", quantity: 1 }],
+      },
+      userId,
+    );
+  }
+}
+
+// Batches sleep between each other; let the fake clock run them through
+async function runBroadcasts(): Promise<number> {
+  vi.useFakeTimers({ toFake: ["setTimeout"] });
+  try {
+    const run = runPendingBroadcasts();
+    await vi.runAllTimersAsync();
+    return await run;
+  } finally {
+    vi.useRealTimers();
+  }
+}
+
+function deliveredTo(): string[] {
+  return vi.mocked(deliverTelegramMessage).mock.calls.map(([userId]) => userId);
+}
+
+beforeEach(() => {
+  clearOrders();
+  vi.mocked(deliverTelegramMessage)
+    .mockClear()
+    .mockImplementation(async () => ({ ok: true, status: 200 }));
+  (globalThis as any).BROADCAST_BATCH_SIZE = "2";
+});
+
+afterEach(() => {
+  delete (globalThis as any).BROADCAST_BATCH_SIZE;
+  delete (globalThis as any).BROADCAST_MAX_PER_RUN;
+});
+
+describe("startBroadcast", () => {
+  it("should count recipients once per user without queueing a dry run", async () => {
+    await createOrders(["1", "2", "2", "3"]);
+
+    const broadcast = await startBroadcast({ message: "Hi", dryRun: true }, "admin");
+    expect(broadcast).toMatchObject({ status: "DRY_RUN", recipientCount: 3 });
+    expect(await getBroadcast(broadcast.id)).toBeNull();
+    expect(await listKeys("broadcast-recipients:")).toHaveLength(0);
+  });
+});
+
+describe("runPendingBroadcasts", () => {
+  it("should deliver in batches within the per-run budget", async () => {
+    await createOrders(["1", "2", "3", "4", "5"]);
+    (globalThis as any).BROADCAST_MAX_PER_RUN = "3";
+    const broadcast = await startBroadcast({ message: "Hi" }, "admin");
+    expect(await listKeys("broadcast-recipients:")).toHaveLength(1);
+
+    expect(await runBroadcasts()).toBe(3);
+    expect(await getBroadcast(broadcast.id)).toMatchObject({ status: "RUNNING", sent: 3 });
+
+    expect(await runBroadcasts()).toBe(2);
+    expect(await getBroadcast(broadcast.id)).toMatchObject({ status: "COMPLETED", sent: 5 });
+    expect(new Set(deliveredTo())).toEqual(new Set(["1", "2", "3", "4", "5"]));
+    expect(await listKeys("broadcast-recipients:")).toHaveLength(0);
+  });
+
+  it("should requeue rate-limited recipients and resume on the next run", async () => {
+    await createOrders(["1", "2", "3"]);
+    vi.mocked(deliverTelegramMessage).mockImplementation(async (userId) =>
+      userId === "2" && deliveredTo().filter((id) => id === "2").length === 1
+        ? { ok: false, status: 429, retryAfter: 30 }
+        : { ok: true, status: 200 },
+    );
+    const broadcast = await startBroadcast({ message: "Hi" }, "admin");
+
+    // A long retry_after ends the run after the batch
+    expect(await runBroadcasts()).toBe(2);
+    expect(await getBroadcast(broadcast.id)).toMatchObject({ status: "RUNNING", sent: 1 });
+
+    expect(await runBroadcasts()).toBe(2);
+    expect(await getBroadcast(broadcast.id)).toMatchObject({
+      status: "COMPLETED",
+      sent: 3,
+      failed: 0,
+    });
+    expect(deliveredTo().filter((id) => id === "2")).toHaveLength(2);
+  });
+
+  it("should stop aborted broadcasts and drop their recipients", async () => {
+    await createOrders(["1", "2", "3"]);
+    const broadcast = await startBroadcast({ message: "Hi" }, "admin");
+
+    const aborted = await abortBroadcast(broadcast.id, "admin");
+    expect(aborted).toMatchObject({ status: "ABORTED" });
+    expect(aborted.completedAt).toBeTruthy();
+    expect(await listKeys("broadcast-recipients:")).toHaveLength(0);
+
+    expect(await runBroadcasts()).toBe(0);
+    expect(deliverTelegramMessage).not.toHaveBeenCalled();
+  });
+});
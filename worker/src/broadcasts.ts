@@ -0,0 +1,489 @@
+// Admin Broadcast Messaging
+// Superadmins queue a templated Bot API message to past customers,
+// optionally filtered by last-order recency, city or language. The cron
+// job scheduler delivers queued broadcasts in rate-limit-aware batches,
+// tracks delivery stats and stops as soon as a broadcast is aborted.
+// Recipients are collected by paging through every order and stored in
+// chunks of RECIPIENT_CHUNK_SIZE next to the broadcast record, so neither
+// the order list nor a single KV value caps the audience.
+
+import {
+  Broadcast,
+  BroadcastFilterInput,
+  StartBroadcastInput,
+} from "./contracts";
+import { getNumberVar } from "./config";
+import { badUserInputError, notFoundError } from "./errors";
+import { logger } from "./logger";
+import { deliverTelegramMessage } from "./notifications";
+import {
+  ORDER_METADATA_KEYS,
+  OrderPage,
+  OrderQueryFilter,
+  SaleorOrder,
+  fetchOrdersPage,
+} from "./saleorOrder";
+import { readJSON, writeJSON, readAllJSON, deleteKey } from "./storage";
+
+/**
+ * Customer targeted by a broadcast (derived from their most recent order)
+ */
+export interface BroadcastRecipient {
+  userId: string;
+  language?: string;
+  city?: string;
+  lastOrderAt: string;
+}
+
+interface BroadcastRecord extends Broadcast {
+  queued: number; // recipient entries across chunks, requeued ones included
+  cursor: number; // index of the next entry to message
+}
+
+const BROADCAST_PREFIX = "broadcast:";
+const RECIPIENTS_PREFIX = "broadcast-recipients:";
+// Recipients per KV value
+const RECIPIENT_CHUNK_SIZE = 500;
+// Finished broadcasts are kept for stats, then dropped by KV TTL
+const BROADCAST_TTL_SECONDS = 30 * 24 * 60 * 60;
+// Telegram allows roughly 30 messages per second across chats
+const BATCH_INTERVAL_MS = 1000;
+// Longest 429 retry_after we wait out inside a run
+const MAX_INLINE_RETRY_SECONDS = 5;
+
+function getKey(broadcastId: string): string {
+  return `${BROADCAST_PREFIX}${broadcastId}`;
+}
+
+function getChunkKey(broadcastId: string, chunk: number): string {
+  return `${RECIPIENTS_PREFIX}${broadcastId}:${chunk}`;
+}
+
+/**
+ * Messages sent concurrently per batch (BROADCAST_BATCH_SIZE, default 25)
+ */
+export function getBatchSize(): number {
+  const size = getNumberVar("BROADCAST_BATCH_SIZE", 25);
+  return size > 0 ? Math.min(Math.floor(size), 30) : 25;
+}
+
+/**
+ * Messages sent per cron run (BROADCAST_MAX_PER_RUN, default 500)
+ */
+export function getMaxPerRun(): number {
+  const max = getNumberVar("BROADCAST_MAX_PER_RUN", 500);
+  return max > 0 ? Math.floor(max) : 500;
+}
+
+/**
+ * Fill {{placeholders}} with recipient values (unknown keys become empty)
+ */
+export function renderBroadcastTemplate(
+  template: string,
+  recipient: BroadcastRecipient,
+): string {
+  const values: Record<string, string> = {
+    city: recipient.city || "",
+    language: recipient.language || "",
+  };
+  return template.replace(/\{\{\s*(\w+)\s*\}\}/g, (_, key: string) =>
+    values[key] ?? "",
+  );
+}
+
+function pickTemplate(
+  input: Pick<Broadcast, "message" | "translations">,
+  language?: string,
+): string {
+  const lang = (language || "").toLowerCase();
+  const translation = input.translations.find(
+    (t) =>
+      t.language.toLowerCase() === lang ||
+      t.language.toLowerCase() === lang.split("-")[0],
+  );
+  return translation?.message || input.message;
+}
+
+/**
+ * Keep the latest order per Telegram user as broadcast recipients
+ */
+function collectRecipients(
+  orders: SaleorOrder[],
+  latest: Map<string, BroadcastRecipient>,
+): void {
+  for (const order of orders) {
+    const userId = order.metadata?.[ORDER_METADATA_KEYS.telegramUserId];
+    if (!userId) {
+      continue;
+    }
+    const current = latest.get(userId);
+    if (current && current.lastOrderAt >= order.createdAt) {
+      continue;
+    }
+    latest.set(userId, {
+      userId,
+      language: order.metadata?.[ORDER_METADATA_KEYS.language],
+      city: order.deliveryAddress.city,
+      lastOrderAt: order.createdAt,
+    });
+  }
+}
+
+/**
+ * Recipients from every order (all pages), narrowed to the filter's
+ * recency window in Saleor
+ */
+async function fetchRecipients(
+  filter: BroadcastFilterInput | undefined,
+  now: Date,
+): Promise<BroadcastRecipient[]> {
+  const orderFilter: OrderQueryFilter = {};
+  if (filter?.lastOrderWithinDays) {
+    orderFilter.createdFrom = new Date(
+      now.getTime() - filter.lastOrderWithinDays * 24 * 60 * 60 * 1000,
+    )
+      .toISOString()
+      .substring(0, 10);
+  }
+
+  const latest = new Map<string, BroadcastRecipient>();
+  let after: string | null = null;
+  do {
+    const page: OrderPage = await fetchOrdersPage(orderFilter, after);
+    collectRecipients(page.orders, latest);
+    after = page.endCursor;
+  } while (after);
+  return Array.from(latest.values());
+}
+
+/**
+ * Apply recency/city/language filters to recipients
+ */
+export function filterRecipients(
+  recipients: BroadcastRecipient[],
+  filter: BroadcastFilterInput | undefined,
+  now: Date = new Date(),
+): BroadcastRecipient[] {
+  const city = filter?.city?.trim().toLowerCase();
+  const language = filter?.language?.trim().toLowerCase();
+  const since = filter?.lastOrderWithinDays
+    ? new Date(
+        now.getTime() - filter.lastOrderWithinDays * 24 * 60 * 60 * 1000,
+      ).toISOString()
+    : null;
+
+  return recipients.filter((recipient) => {
+    if (since && recipient.lastOrderAt < since) {
+      return false;
+    }
+    if (city && (recipient.city || "").trim().toLowerCase() !== city) {
+      return false;
+    }
+    if (
+      language &&
+      !(recipient.language || "").toLowerCase().startsWith(language)
+    ) {
+      return false;
+    }
+    return true;
+  });
+}
+
+function toBroadcast(record: BroadcastRecord): Broadcast {
+  const { queued: _queued, cursor: _cursor, ...broadcast } = record;
+  return broadcast;
+}
+
+async function readChunk(broadcastId: string, chunk: number): Promise<BroadcastRecipient[]> {
+  return (await readJSON<BroadcastRecipient[]>(getChunkKey(broadcastId, chunk))) || [];
+}
+
+/**
+ * Append recipients to the broadcast's queue, filling the last chunk first
+ */
+async function enqueueRecipients(
+  record: BroadcastRecord,
+  recipients: BroadcastRecipient[],
+): Promise<void> {
+  let pending = recipients;
+  while (pending.length > 0) {
+    const chunk = Math.floor(record.queued / RECIPIENT_CHUNK_SIZE);
+    const offset = record.queued % RECIPIENT_CHUNK_SIZE;
+    const existing = offset > 0 ? (await readChunk(record.id, chunk)).slice(0, offset) : [];
+    const room = RECIPIENT_CHUNK_SIZE - offset;
+    await writeJSON(getChunkKey(record.id, chunk), [...existing, ...pending.slice(0, room)], {
+      expirationTtl: BROADCAST_TTL_SECONDS,
+    });
+    record.queued += Math.min(room, pending.length);
+    pending = pending.slice(room);
+  }
+}
+
+async function deleteRecipients(record: BroadcastRecord): Promise<void> {
+  const chunks = Math.ceil(record.queued / RECIPIENT_CHUNK_SIZE);
+  for (let chunk = 0; chunk < chunks; chunk++) {
+    await deleteKey(getChunkKey(record.id, chunk));
+  }
+}
+
+function validateInput(input: StartBroadcastInput): void {
+  if (!input?.message || input.message.trim().length === 0) {
+    throw badUserInputError("Message is required", "message");
+  }
+  if (input.message.length > 4096) {
+    throw badUserInputError("Message exceeds 4096 characters", "message");
+  }
+  for (const translation of input.translations || []) {
+    if (!translation.language || !translation.message?.trim()) {
+      throw badUserInputError(
+        "Translations need a language and message",
+        "translations",
+      );
+    }
+  }
+  const days = input.filter?.lastOrderWithinDays;
+  if (days !== undefined && days !== null && !(days > 0)) {
+    throw badUserInputError(
+      "lastOrderWithinDays must be positive",
+      "filter.lastOrderWithinDays",
+    );
+  }
+}
+
+/**
+ * Queue a broadcast (or, with dryRun, only count its recipients)
+ */
+export async function startBroadcast(
+  input: StartBroadcastInput,
+  createdBy: string,
+): Promise<Broadcast> {
+  validateInput(input);
+
+  const started = new Date();
+  const recipients = filterRecipients(
+    await fetchRecipients(input.filter, started),
+    input.filter,
+    started,
+  );
+  const now = started.toISOString();
+
+  const record: BroadcastRecord = {
+    id: crypto.randomUUID(),
+    status: input.dryRun ? "DRY_RUN" : "PENDING",
+    message: input.message,
+    translations: input.translations || [],
+    filter: input.filter || {},
+    createdBy,
+    createdAt: now,
+    updatedAt: now,
+    completedAt: null,
+    recipientCount: recipients.length,
+    sent: 0,
+    failed: 0,
+    blocked: 0,
+    queued: 0,
+    cursor: 0,
+  };
+
+  if (input.dryRun) {
+    return toBroadcast(record);
+  }
+
+  await enqueueRecipients(record, recipients);
+  await writeJSON(getKey(record.id), record, {
+    expirationTtl: BROADCAST_TTL_SECONDS,
+  });
+  logger.info("broadcast_queued", {
+    broadcastId: record.id,
+    recipients: recipients.length,
+    createdBy,
+  });
+  return toBroadcast(record);
+}
+
+/**
+ * Stop a broadcast; in-flight batches finish, no new ones start
+ */
+export async function abortBroadcast(
+  broadcastId: string,
+  abortedBy: string,
+): Promise<Broadcast> {
+  const record = await readJSON<BroadcastRecord>(getKey(broadcastId));
+  if (!record) {
+    throw notFoundError("Broadcast not found");
+  }
+  if (record.status === "PENDING" || record.status === "RUNNING") {
+    record.status = "ABORTED";
+    record.updatedAt = new Date().toISOString();
+    record.completedAt = record.updatedAt;
+    await writeJSON(getKey(broadcastId), record, {
+      expirationTtl: BROADCAST_TTL_SECONDS,
+    });
+    await deleteRecipients(record);
+    logger.info("broadcast_aborted", { broadcastId, abortedBy });
+  }
+  return toBroadcast(record);
+}
+
+export async function getBroadcast(
+  broadcastId: string,
+): Promise<Broadcast | null> {
+  const record = await readJSON<BroadcastRecord>(getKey(broadcastId));
+  return record ? toBroadcast(record) : null;
+}
+
+/**
+ * All stored broadcasts, newest first
+ */
+export async function listBroadcasts(): Promise<Broadcast[]> {
+  const records = await readAllJSON<BroadcastRecord>(BROADCAST_PREFIX);
+  return records
+    .map(toBroadcast)
+    .sort((a, b) => b.createdAt.localeCompare(a.createdAt));
+}
+
+function sleep(ms: number): Promise<void> {
+  return new Promise((resolve) => setTimeout(resolve, ms));
+}
+
+async function isAborted(broadcastId: string): Promise<boolean> {
+  const latest = await readJSON<BroadcastRecord>(getKey(broadcastId));
+  return !latest || latest.status === "ABORTED";
+}
+
+/**
+ * Deliver the next chunk of one broadcast
+ * Returns the number of messages attempted
+ */
+async function processBroadcast(
+  record: BroadcastRecord,
+  budget: number,
+): Promise<number> {
+  const batchSize = getBatchSize();
+  let attempted = 0;
+  let rateLimited = false;
+  let chunkIndex = -1;
+  let chunk: BroadcastRecipient[] = [];
+
+  record.status = "RUNNING";
+
+  while (record.cursor < record.queued && attempted < budget && !rateLimited) {
+    // Honour aborts issued by other requests between batches
+    if (await isAborted(record.id)) {
+      record.status = "ABORTED";
+      break;
+    }
+
+    // Batches stay within one chunk
+    const index = Math.floor(record.cursor / RECIPIENT_CHUNK_SIZE);
+    if (index !== chunkIndex) {
+      chunk = await readChunk(record.id, index);
+      chunkIndex = index;
+    }
+    const offset = record.cursor % RECIPIENT_CHUNK_SIZE;
+    const batch = chunk.slice(offset, offset + Math.min(batchSize, budget - attempted));
+    if (batch.length === 0) {
+      // Chunk expired or deleted: nothing left to send from it
+      logger.warn("broadcast_chunk_missing", { broadcastId: record.id, chunk: index });
+      record.cursor = Math.min(record.queued, (index + 1) * RECIPIENT_CHUNK_SIZE);
+      continue;
+    }
+
+    const results = await Promise.all(
+      batch.map((recipient) =>
+        deliverTelegramMessage(
+          recipient.userId,
+          renderBroadcastTemplate(
+            pickTemplate(record, recipient.language),
+            recipient,
+          ),
+          { recordFailure: false },
+        ),
+      ),
+    );
+
+    let retryAfter = 0;
+    const requeued: BroadcastRecipient[] = [];
+    results.forEach((result, index) => {
+      if (result.status === 429) {
+        // Rate-limited recipients go to the back of the queue
+        requeued.push(batch[index]);
+        retryAfter = Math.max(retryAfter, result.retryAfter || 1);
+      } else if (result.ok) {
+        record.sent++;
+      } else if (result.blocked) {
+        record.blocked++;
+      } else {
+        record.failed++;
+      }
+      record.cursor++;
+      attempted++;
+    });
+
+    // Don't overwrite an abort issued while the batch was in flight
+    if (await isAborted(record.id)) {
+      record.status = "ABORTED";
+      break;
+    }
+    if (requeued.length > 0) {
+      await enqueueRecipients(record, requeued);
+      chunkIndex = -1; // the current chunk may have grown
+    }
+    record.updatedAt = new Date().toISOString();
+    await writeJSON(getKey(record.id), record, {
+      expirationTtl: BROADCAST_TTL_SECONDS,
+    });
+
+    if (retryAfter > MAX_INLINE_RETRY_SECONDS) {
+      // Resume on the next cron run instead of holding the worker
+      rateLimited = true;
+    } else if (record.cursor < record.queued) {
+      await sleep(Math.max(BATCH_INTERVAL_MS, retryAfter * 1000));
+    }
+  }
+
+  if (record.status === "RUNNING" && record.cursor >= record.queued) {
+    record.status = "COMPLETED";
+    record.completedAt = new Date().toISOString();
+    logger.info("broadcast_completed", {
+      broadcastId: record.id,
+      sent: record.sent,
+      failed: record.failed,
+      blocked: record.blocked,
+    });
+  }
+  if (record.status === "ABORTED" && !record.completedAt) {
+    record.completedAt = new Date().toISOString();
+  }
+  if (record.status !== "RUNNING") {
+    await deleteRecipients(record);
+  }
+
+  record.updatedAt = new Date().toISOString();
+  await writeJSON(getKey(record.id), record, {
+    expirationTtl: BROADCAST_TTL_SECONDS,
+  });
+  return attempted;
+}
+
+/**
+ * Scheduled job: continue pending/running broadcasts, oldest first
+ */
+export async function runPendingBroadcasts(): Promise<number> {
+  const records = await readAllJSON<BroadcastRecord>(BROADCAST_PREFIX);
+  const active = records
+    .filter((r) => r.status === "PENDING" || r.status === "RUNNING")
+    .sort((a, b) => a.createdAt.localeCompare(b.createdAt));
+
+  let budget = getMaxPerRun();
+  let attempted = 0;
+  for (const record of active) {
+    if (budget <= 0) {
+      break;
+    }
+    const count = await processBroadcast(record, budget);
+    budget -= count;
+    attempted += count;
+  }
+  return attempted;
+}
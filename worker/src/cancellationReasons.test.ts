@@ -0,0 +1,105 @@
+// Cancellation Reason Tests
+// Tests for cancellationReasons.ts - recording and reading why orders were cancelled
+
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import { buildCancellationMetadata, getOrderCancellation } from "./cancellationReasons";
+import { PlaceOrderInput } from "./contracts";
+import { buildOrderCancelledMessage } from "./notifications";
+import { cancelUserOrder, rejectOrder } from "./paymentHolds";
+import { SaleorOrder, clearOrders, createSaleorOrder, getOrder } from "./saleorOrder";
+import { buildSaleorOrder } from "./testHelpers";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+const orderInput: PlaceOrderInput = {
+  restaurantId: "restA",
+  deliveryLocation: { address: "1 Test Street" },
+  items: [{ dishId: "dish1", quantity: 1 }],
+};
+
+function order(status: SaleorOrder["status"], metadata: Record<string, string>): SaleorOrder {
+  return buildSaleorOrder({ status, metadata });
+}
+
+describe("getOrderCancellation", () => {
+  it("should read the recorded reason, with who cancelled from the code", () => {
+    const metadata = buildCancellationMetadata(
+      "KITCHEN_BUSY",
+      "  Oven is down  ",
+      new Date("2026-04-01T12:00:00.000Z"),
+    );
+    expect(getOrderCancellation(order("CANCELED", metadata))).toEqual({
+      reason: "KITCHEN_BUSY",
+      cancelledBy: "RESTAURANT",
+      note: "Oven is down",
+      cancelledAt: "2026-04-01T12:00:00.000Z",
+    });
+  });
+
+  it("should be null for orders that aren't cancelled", () => {
+    const metadata = buildCancellationMetadata("CUSTOMER_REQUEST");
+    expect(getOrderCancellation(order("UNFULFILLED", metadata))).toBeNull();
+  });
+
+  it("should fall back for orders cancelled before reason codes", () => {
+    expect(
+      getOrderCancellation(order("CANCELED", { "tma.rejectionReason": "Closed early" })),
+    ).toMatchObject({ reason: "RESTAURANT_REJECTED", note: "Closed early", cancelledAt: null });
+    expect(getOrderCancellation(order("CANCELED", { "tma.state": "EXPIRED" }))).toMatchObject({
+      reason: "PAYMENT_TIMEOUT",
+      cancelledBy: "SYSTEM",
+    });
+    expect(getOrderCancellation(order("CANCELED", {}))).toBeNull();
+  });
+});
+
+describe("buildOrderCancelledMessage", () => {
+  it("should give the reason, the note and a released hold", () => {
+    const text = buildOrderCancelledMessage(
+      "#42",
+      { reason: "OUT_OF_STOCK", cancelledBy: "RESTAURANT", note: "No basil", cancelledAt: null },
+      { paymentReleased: true },
+    );
+    expect(text).toBe(
+      "Your order #42 was cancelled: some items are out of stock.\n" +
+        "Note: No basil\n" +
+        "Your payment hold has been released.",
+    );
+  });
+});
+
+describe("cancelling orders", () => {
+  beforeEach(() => {
+    clearOrders();
+  });
+
+  it("should record the restaurant's reason code on rejection", async () => {
+    const placed = await createSaleorOrder(orderInput, "user-1");
+    await rejectOrder(placed.order!.id, "Back tomorrow", "admin", "RESTAURANT_CLOSED");
+    expect(getOrderCancellation(getOrder(placed.order!.id)!)).toMatchObject({
+      reason: "RESTAURANT_CLOSED",
+      cancelledBy: "RESTAURANT",
+      note: "Back tomorrow",
+    });
+  });
+
+  it("should let customers cancel only their own unaccepted orders", async () => {
+    const placed = await createSaleorOrder(orderInput, "user-1");
+    await expect(cancelUserOrder(placed.order!.id, "user-2", null)).rejects.toThrow(
+      "Order not found",
+    );
+
+    const result = await cancelUserOrder(placed.order!.id, "user-1", "Ordered twice");
+    expect(result.accepted).toBe(false);
+    expect(getOrderCancellation(getOrder(placed.order!.id)!)).toMatchObject({
+      reason: "CUSTOMER_REQUEST",
+      cancelledBy: "CUSTOMER",
+      note: "Ordered twice",
+    });
+    await expect(cancelUserOrder(placed.order!.id, "user-1", null)).rejects.toThrow(
+      "Order can no longer be cancelled",
+    );
+  });
+});
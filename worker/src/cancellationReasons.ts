@@ -0,0 +1,107 @@
+// Order Cancellation Reasons
+// Every cancellation, whether the customer's, the restaurant's rejection or
+// the system's (unpaid past the deadline, failed fraud review), records a
+// reason code and an optional note in the order's metadata
+// (tma.cancellationReason, tma.cancellationNote, tma.cancelledBy,
+// tma.cancelledAt). Who cancelled follows from the code. Orders cancelled
+// before codes were recorded fall back to their rejection reason or
+// expired state.
+
+import { CancellationReasonCode, CancelledBy, OrderCancellation } from "./contracts";
+import { ORDER_METADATA_KEYS, SaleorOrder, getNormalizedStatus } from "./saleorOrder";
+
+export const CANCELLATION_REASON_CODES: CancellationReasonCode[] = [
+  "CUSTOMER_REQUEST",
+  "RESTAURANT_REJECTED",
+  "OUT_OF_STOCK",
+  "RESTAURANT_CLOSED",
+  "KITCHEN_BUSY",
+  "OUT_OF_DELIVERY_AREA",
+  "PAYMENT_TIMEOUT",
+  "FRAUD_CHECK",
+];
+
+// Codes a restaurant may give when rejecting an order
+export const RESTAURANT_CANCELLATION_REASONS: CancellationReasonCode[] = [
+  "RESTAURANT_REJECTED",
+  "OUT_OF_STOCK",
+  "RESTAURANT_CLOSED",
+  "KITCHEN_BUSY",
+  "OUT_OF_DELIVERY_AREA",
+];
+
+export const MAX_CANCELLATION_NOTE_LENGTH = 500;
+
+export function isCancellationReasonCode(value: unknown): value is CancellationReasonCode {
+  return CANCELLATION_REASON_CODES.includes(value as CancellationReasonCode);
+}
+
+/**
+ * Who cancels an order for a reason
+ */
+export function getCancelledBy(reason: CancellationReasonCode): CancelledBy {
+  switch (reason) {
+    case "CUSTOMER_REQUEST":
+      return "CUSTOMER";
+    case "PAYMENT_TIMEOUT":
+    case "FRAUD_CHECK":
+      return "SYSTEM";
+    default:
+      return "RESTAURANT";
+  }
+}
+
+/**
+ * Order metadata recording a cancellation
+ */
+export function buildCancellationMetadata(
+  reason: CancellationReasonCode,
+  note?: string | null,
+  now: Date = new Date(),
+): Record<string, string> {
+  const metadata: Record<string, string> = {
+    [ORDER_METADATA_KEYS.cancellationReason]: reason,
+    [ORDER_METADATA_KEYS.cancelledBy]: getCancelledBy(reason),
+    [ORDER_METADATA_KEYS.cancelledAt]: now.toISOString(),
+  };
+  const trimmed = note?.trim().slice(0, MAX_CANCELLATION_NOTE_LENGTH);
+  if (trimmed) {
+    metadata[ORDER_METADATA_KEYS.cancellationNote] = trimmed;
+  }
+  return metadata;
+}
+
+/**
+ * Why an order was cancelled, or null while it isn't
+ */
+export function getOrderCancellation(order: SaleorOrder): OrderCancellation | null {
+  const status = getNormalizedStatus(order);
+  if (status !== "CANCELLED" && status !== "EXPIRED") {
+    return null;
+  }
+  const metadata = order.metadata || {};
+  const recorded = metadata[ORDER_METADATA_KEYS.cancellationReason];
+  if (isCancellationReasonCode(recorded)) {
+    return {
+      reason: recorded,
+      cancelledBy: getCancelledBy(recorded),
+      note: metadata[ORDER_METADATA_KEYS.cancellationNote] || null,
+      cancelledAt: metadata[ORDER_METADATA_KEYS.cancelledAt] || null,
+    };
+  }
+
+  // Cancelled before reason codes were recorded
+  const rejection = metadata[ORDER_METADATA_KEYS.rejectionReason];
+  if (rejection) {
+    return {
+      reason: "RESTAURANT_REJECTED",
+      cancelledBy: "RESTAURANT",
+      note: rejection,
+      cancelledAt: null,
+    };
+  }
+  if (status === "EXPIRED") {
+    return { reason: "PAYMENT_TIMEOUT", cancelledBy: "SYSTEM", note: null, cancelledAt: null };
+  }
+  return null;
+}
@@ -1,4 +1,4 @@
-// Phase 3: Cart Management with Cloudflare KV Persistence
+// Phase 3: Cart Management persisted through the store (store.ts)
 // Implements cart state with per-session channel context
 // See: task/phase-3-in-memory-cart-and-state.md
 // Phase 10: Channel entity support - internal channelId, GraphQL backward-compatible restaurantId
@@ -9,93 +9,35 @@ import {
   AddToCartInput,
   UpdateCartItemInput,
 } from "./contracts";
+import { sumMoney, multiplyMoney } from "./money";
+import { getStore } from "./store";
 
-// KV namespace binding type (will be injected by Cloudflare Workers)
-export interface CartKV {
-  get(key: string, type?: "text" | "json"): Promise<string | any | null>;
-  put(
-    key: string,
-    value: string | ReadableStream | ArrayBuffer,
-    options?: { expirationTtl?: number },
-  ): Promise<void>;
-  delete(key: string): Promise<void>;
-}
-
-// Environment interface for Cloudflare Workers
-export interface Env {
-  CARTS?: CartKV;
-}
-
-// In-memory cart store (fallback when KV not available in tests)
+// In-memory carts behind the synchronous API
 const memoryCarts: Map<string, CartState> = new Map();
 
-// Determine if we're in a Cloudflare Workers environment
-function isWorkersEnvironment(): boolean {
-  return (
-    typeof globalThis !== "undefined" &&
-    typeof (globalThis as any).__env__ !== "undefined"
-  );
-}
-
-// Get KV instance from environment
-function getKV(): CartKV | null {
-  if (typeof globalThis !== "undefined") {
-    const env = (globalThis as any).__env__ as Env | undefined;
-    return env?.CARTS ?? null;
-  }
-  return null;
+function emptyCart(): CartState {
+  return { channelId: null, restaurantId: null, items: [] };
 }
 
 /**
- * Get cart for a user from KV, creating empty cart if not exists
+ * Get cart for a user from the store, or an empty cart if none is saved
  */
 export async function getCart(userId: string): Promise<CartState> {
-  const kv = getKV();
-
-  if (kv) {
-    try {
-      const data = await kv.get(`cart:${userId}`, "json");
-      if (data) {
-        return data as CartState;
-      }
-    } catch (error) {
-      console.error(`[Cart] KV get error for user ${userId}:`, error);
-    }
-  }
-
-  if (!memoryCarts.has(userId)) {
-    memoryCarts.set(userId, { channelId: null, restaurantId: null, items: [] });
-  }
-  return memoryCarts.get(userId)!;
+  return (await getStore().carts.get(userId)) ?? emptyCart();
 }
 
 /**
- * Set entire cart for a user (persists to KV)
+ * Set entire cart for a user (the store applies the cart TTL)
  */
 export async function setCart(userId: string, cart: CartState): Promise<void> {
-  const kv = getKV();
-
-  if (kv) {
-    try {
-      // Cart expires after 24 hours (86400 seconds)
-      await kv.put(`cart:${userId}`, JSON.stringify(cart), {
-        expirationTtl: 86400,
-      });
-    } catch (error) {
-      console.error(`[Cart] KV put error for user ${userId}:`, error);
-    }
-  }
-
-  // Also update memory for test compatibility
-  memoryCarts.set(userId, cart);
+  await getStore().carts.put(userId, cart);
 }
 
 /**
- * Clear cart for a user (reset to empty, persists to KV)
+ * Clear cart for a user
  */
 export async function clearCart(userId: string): Promise<void> {
-  const cart: CartState = { channelId: null, restaurantId: null, items: [] };
-  await setCart(userId, cart);
+  await getStore().carts.delete(userId);
 }
 
 /**
@@ -116,22 +58,20 @@ export async function addToCart(
     console.log(
       `[Cart] User ${userId} switching channel ${cart.channelId} to ${targetChannelId}, clearing cart`,
     );
-    await clearCart(userId);
-    const clearedCart = await getCart(userId);
-    return addToCartToCart(clearedCart, userId, input);
+    const clearedCart = addToCartToCart(emptyCart(), input);
+    await setCart(userId, clearedCart);
+    return clearedCart;
   }
 
-  return addToCartToCart(cart, userId, input);
+  addToCartToCart(cart, input);
+  await setCart(userId, cart);
+  return cart;
 }
 
 /**
- * Internal function to add item to cart object
+ * Internal function to add item to cart object (callers persist it)
  */
-function addToCartToCart(
-  cart: CartState,
-  userId: string,
-  input: AddToCartInput,
-): CartState {
+function addToCartToCart(cart: CartState, input: AddToCartInput): CartState {
   const existingItemIndex = cart.items.findIndex(
     (item) => item.dishId === input.dishId,
   );
@@ -156,7 +96,6 @@ function addToCartToCart(
     cart.channelId = input.channelId || input.restaurantId;
   }
 
-  setCart(userId, cart);
   return cart;
 }
 
@@ -200,14 +139,25 @@ export async function removeFromCart(
   return cart;
 }
 
+/**
+ * Sum line totals in the cart currency's minor units
+ */
+function calculateCartTotal(cart: CartState): number {
+  const currency = cart.items[0]?.currency || "USD";
+  return sumMoney(
+    cart.items.map((item) =>
+      multiplyMoney(item.price || 0, item.quantity, currency),
+    ),
+    currency,
+  );
+}
+
 /**
  * Calculate total price for cart
  */
 export async function getCartTotal(userId: string): Promise<number> {
   const cart = await getCart(userId);
-  return cart.items.reduce((total, item) => {
-    return total + (item.price || 0) * item.quantity;
-  }, 0);
+  return calculateCartTotal(cart);
 }
 
 /**
@@ -227,7 +177,7 @@ export async function getCartItemCount(userId: string): Promise<number> {
  */
 export function getCartSync(userId: string): CartState {
   if (!memoryCarts.has(userId)) {
-    memoryCarts.set(userId, { channelId: null, restaurantId: null, items: [] });
+    memoryCarts.set(userId, emptyCart());
   }
   return memoryCarts.get(userId)!;
 }
@@ -237,7 +187,7 @@ export function setCartSync(userId: string, cart: CartState): void {
 }
 
 export function clearCartSync(userId: string): void {
-  memoryCarts.set(userId, { channelId: null, restaurantId: null, items: [] });
+  memoryCarts.set(userId, emptyCart());
 }
 
 export function addToCartSync(
@@ -250,11 +200,14 @@ export function addToCartSync(
 
   if (cart.channelId && targetChannelId && cart.channelId !== targetChannelId) {
     clearCartSync(userId);
-    const clearedCart = getCartSync(userId);
-    return addToCartToCart(clearedCart, userId, input);
+    const clearedCart = addToCartToCart(getCartSync(userId), input);
+    setCartSync(userId, clearedCart);
+    return clearedCart;
   }
 
-  return addToCartToCart(cart, userId, input);
+  addToCartToCart(cart, input);
+  setCartSync(userId, cart);
+  return cart;
 }
 
 /**
@@ -298,9 +251,7 @@ export function removeFromCartSync(userId: string, dishId: string): CartState {
  */
 export function getCartTotalSync(userId: string): number {
   const cart = getCartSync(userId);
-  return cart.items.reduce((total, item) => {
-    return total + (item.price || 0) * item.quantity;
-  }, 0);
+  return calculateCartTotal(cart);
 }
 
 /**
@@ -315,13 +266,13 @@ export function getCartItemCountSync(userId: string): number {
 // Phase 9 Notes: KV Cart Implementation
 // ============================================================
 //
-// Current implementation uses Cloudflare KV for production with
-// in-memory fallback for tests.
+// The async API reads and writes carts through getStore().carts, so they
+// live under cart:<userId> in KV in production and in the memory store in
+// tests (setStore).
 //
 // Key features:
-// - Cart data persisted to KV with 24-hour TTL
-// - Automatic migration path between test (memory) and prod (KV)
-// - Sync versions maintained for backward compatibility with tests
+// - Cart data persisted with the store's 24-hour TTL
+// - Sync versions kept in memory for backward compatibility with tests
 // - Same public API regardless of storage backend
 //
 // Environment requirements:
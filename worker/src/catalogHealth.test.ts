@@ -0,0 +1,122 @@
+// Catalog Health Tests
+// Tests for catalogHealth.ts - duplicate, orphan and cross-listing checks
+
+import { describe, it, expect, vi } from "vitest";
+import {
+  buildCatalogHealthReport,
+  CatalogSnapshot,
+  duplicateKey,
+  findCatalogIssues,
+} from "./catalogHealth";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+const healthy: CatalogSnapshot = {
+  channels: [
+    { id: "restA", slug: "pizza-place", name: "Pizza Place" },
+    { id: "restB", slug: "sushi-bar", name: "Sushi Bar" },
+  ],
+  categories: [
+    { id: "cat1", name: "Pizza", parentId: null },
+    { id: "cat2", name: "Vegetarian", parentId: "cat1" },
+  ],
+  products: [
+    { id: "p1", name: "Margherita", categoryId: "cat2", channelIds: ["restA"] },
+    { id: "p2", name: "Maki", categoryId: "cat1", channelIds: ["restB"] },
+  ],
+};
+
+describe("duplicateKey", () => {
+  it("should ignore case, spacing and punctuation", () => {
+    expect(duplicateKey("Pizza  Place!")).toBe(duplicateKey("pizza-place"));
+    expect(duplicateKey("Café")).not.toBe(duplicateKey("Cafe"));
+  });
+});
+
+describe("findCatalogIssues", () => {
+  it("should find nothing in a healthy catalog", () => {
+    expect(findCatalogIssues(healthy)).toEqual([]);
+  });
+
+  it("should report duplicate restaurants once per group", () => {
+    const issues = findCatalogIssues({
+      ...healthy,
+      channels: [...healthy.channels, { id: "restC", slug: "pizzaplace", name: "Pizza place" }],
+    });
+    expect(issues).toHaveLength(1);
+    expect(issues[0].kind).toBe("DUPLICATE_RESTAURANT");
+    expect(issues[0].objectIds).toEqual(["restA", "restC"]);
+  });
+
+  it("should report duplicate categories only under the same parent", () => {
+    const issues = findCatalogIssues({
+      ...healthy,
+      categories: [
+        ...healthy.categories,
+        { id: "cat3", name: "pizza", parentId: null },
+        { id: "cat4", name: "Pizza", parentId: "cat2" },
+      ],
+      products: [
+        ...healthy.products,
+        { id: "p3", name: "Calzone", categoryId: "cat3", channelIds: ["restA"] },
+        { id: "p4", name: "Pinsa", categoryId: "cat4", channelIds: ["restA"] },
+      ],
+    });
+    expect(issues.map((i) => [i.kind, i.objectIds])).toEqual([
+      ["DUPLICATE_CATEGORY", ["cat1", "cat3"]],
+    ]);
+  });
+
+  it("should report orphan, looping and empty categories", () => {
+    const issues = findCatalogIssues({
+      ...healthy,
+      categories: [
+        ...healthy.categories,
+        { id: "cat5", name: "Lost", parentId: "gone" },
+        { id: "cat6", name: "Loop A", parentId: "cat7" },
+        { id: "cat7", name: "Loop B", parentId: "cat6" },
+      ],
+      products: [
+        ...healthy.products,
+        { id: "p5", name: "Soup", categoryId: "cat5", channelIds: ["restA"] },
+        { id: "p6", name: "Salad", categoryId: "cat6", channelIds: ["restA"] },
+      ],
+    });
+    expect(issues.filter((i) => i.kind === "ORPHAN_CATEGORY").map((i) => i.objectIds[0])).toEqual(
+      ["cat5", "cat6", "cat7"],
+    );
+    expect(issues.filter((i) => i.kind === "EMPTY_CATEGORY")).toEqual([]);
+
+    const empty = findCatalogIssues({
+      ...healthy,
+      categories: [...healthy.categories, { id: "cat8", name: "Desserts", parentId: null }],
+    });
+    expect(empty.map((i) => [i.kind, i.objectIds])).toEqual([["EMPTY_CATEGORY", ["cat8"]]]);
+  });
+
+  it("should report products listed in several restaurants", () => {
+    const issues = findCatalogIssues({
+      ...healthy,
+      products: [
+        ...healthy.products,
+        { id: "p7", name: "Cola", categoryId: "cat1", channelIds: ["restA", "restB"] },
+      ],
+    });
+    expect(issues).toHaveLength(1);
+    expect(issues[0]).toMatchObject({
+      kind: "MULTI_RESTAURANT_PRODUCT",
+      objectIds: ["p7"],
+      message: '"Cola" is listed in Pizza Place, Sushi Bar',
+    });
+  });
+});
+
+describe("buildCatalogHealthReport", () => {
+  it("should report an empty scan without Saleor", async () => {
+    const report = await buildCatalogHealthReport();
+    expect(report.complete).toBe(true);
+    expect(report.issues).toEqual([]);
+  });
+});
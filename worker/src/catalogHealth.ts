@@ -0,0 +1,287 @@
+// Catalog Health
+// Scans Saleor for catalog problems that creep in as more restaurants are
+// onboarded: restaurants (channels) sharing a name or slug, categories
+// (product types) duplicated under the same parent, categories whose
+// tma_parent_category is missing or loops, categories with no dishes, and
+// products listed in more than one restaurant's channel. Each issue comes
+// with a suggested fix; nothing is changed automatically.
+
+import {
+  CatalogHealthReport,
+  CatalogIssue,
+  CatalogIssueKind,
+  CatalogObjectType,
+} from "./contracts";
+import { getCategoryAncestors, getParentCategoryId } from "./categoryTree";
+import { internalError } from "./errors";
+import { logger } from "./logger";
+import { metadataToRecord } from "./metadata";
+import { MAX_CATALOG_PAGES, MAX_PAGE_SIZE } from "./pagination";
+import { CATALOG_HEALTH_QUERY, getSaleorClient, isSaleorConfigured } from "./saleorClient";
+import {
+  PRODUCT_TYPES_QUERY,
+  CatalogHealthQuery,
+  ProductTypesQuery,
+  ProductTypesQueryVariables,
+} from "./saleorOperations";
+import { QUERY_NODE_COSTS, createCostBudget, executePageWithinCost } from "./queryCost";
+
+// Products are scanned in pages of up to 100; the report is marked incomplete
+// after this many pages
+const MAX_PRODUCT_PAGES = 50;
+
+export interface CatalogSnapshot {
+  channels: Array<{ id: string; slug: string; name: string }>;
+  categories: Array<{ id: string; name: string; parentId: string | null }>;
+  products: Array<{ id: string; name: string; categoryId: string | null; channelIds: string[] }>;
+}
+
+/**
+ * Key that duplicate names share: case, spacing and punctuation ignored
+ */
+export function duplicateKey(value: string): string {
+  return value
+    .normalize("NFKC")
+    .toLowerCase()
+    .replace(/[^\p{L}\p{N}]+/gu, "");
+}
+
+function groupBy<T>(items: T[], key: (item: T) => string): T[][] {
+  const groups = new Map<string, T[]>();
+  for (const item of items) {
+    const k = key(item);
+    if (k) {
+      groups.set(k, [...(groups.get(k) || []), item]);
+    }
+  }
+  return Array.from(groups.values()).filter((group) => group.length > 1);
+}
+
+function issue(
+  kind: CatalogIssueKind,
+  objectType: CatalogObjectType,
+  objects: Array<{ id: string; name: string }>,
+  message: string,
+  suggestedFix: string,
+): CatalogIssue {
+  return {
+    kind,
+    objectType,
+    objectIds: objects.map((o) => o.id),
+    objectNames: objects.map((o) => o.name),
+    message,
+    suggestedFix,
+  };
+}
+
+/**
+ * Issues in a catalog snapshot, in kind order
+ */
+export function findCatalogIssues(catalog: CatalogSnapshot): CatalogIssue[] {
+  const issues: CatalogIssue[] = [];
+
+  // Restaurants sharing a name or slug; a pair matching on both is reported once
+  const reported = new Set<string>();
+  const channelGroups = [
+    ...groupBy(catalog.channels, (ch) => duplicateKey(ch.name)),
+    ...groupBy(catalog.channels, (ch) => duplicateKey(ch.slug)),
+  ];
+  for (const group of channelGroups) {
+    const ids = group.map((ch) => ch.id).sort().join(",");
+    if (reported.has(ids)) {
+      continue;
+    }
+    reported.add(ids);
+    issues.push(
+      issue(
+        "DUPLICATE_RESTAURANT",
+        "CHANNEL",
+        group,
+        `${group.length} restaurants share the name or slug of "${group[0].name}"`,
+        "Merge the menus into one channel and deactivate the others, or rename them",
+      ),
+    );
+  }
+
+  const categories = catalog.categories.map((c) => ({
+    id: c.id,
+    name: c.name,
+    imageUrl: "",
+    parentId: c.parentId ?? undefined,
+  }));
+  const categoryIds = new Set(categories.map((c) => c.id));
+
+  // Same name under the same parent (or both top level)
+  for (const group of groupBy(categories, (c) => {
+    const parentId = getCategoryAncestors(categories, c.id)[0] || "";
+    return `${parentId}/${duplicateKey(c.name)}`;
+  })) {
+    issues.push(
+      issue(
+        "DUPLICATE_CATEGORY",
+        "CATEGORY",
+        group,
+        `${group.length} categories are named "${group[0].name}" in the same section`,
+        "Move the dishes into one product type and delete the others",
+      ),
+    );
+  }
+
+  for (const category of categories) {
+    if (!category.parentId) {
+      continue;
+    }
+    if (!categoryIds.has(category.parentId)) {
+      issues.push(
+        issue(
+          "ORPHAN_CATEGORY",
+          "CATEGORY",
+          [category],
+          `"${category.name}" has a parent category that doesn't exist`,
+          "Set tma_parent_category to an existing product type or remove it",
+        ),
+      );
+    } else if (getCategoryAncestors(categories, category.id).length === 0) {
+      issues.push(
+        issue(
+          "ORPHAN_CATEGORY",
+          "CATEGORY",
+          [category],
+          `"${category.name}" is nested inside itself`,
+          "Fix tma_parent_category so the chain ends at a top level category",
+        ),
+      );
+    }
+  }
+
+  const usedCategories = new Set(catalog.products.map((p) => p.categoryId));
+  const parents = new Set(categories.map((c) => c.parentId));
+  for (const category of categories) {
+    if (!usedCategories.has(category.id) && !parents.has(category.id)) {
+      issues.push(
+        issue(
+          "EMPTY_CATEGORY",
+          "CATEGORY",
+          [category],
+          `"${category.name}" has no dishes`,
+          "Add dishes to it or delete the product type",
+        ),
+      );
+    }
+  }
+
+  const channelNames = new Map(catalog.channels.map((ch) => [ch.id, ch.name]));
+  for (const product of catalog.products) {
+    const channelIds = Array.from(new Set(product.channelIds));
+    if (channelIds.length > 1) {
+      const names = channelIds.map((id) => channelNames.get(id) || id);
+      issues.push(
+        issue(
+          "MULTI_RESTAURANT_PRODUCT",
+          "PRODUCT",
+          [product],
+          `"${product.name}" is listed in ${names.join(", ")}`,
+          "Keep one channel listing and create a separate product per restaurant",
+        ),
+      );
+    }
+  }
+
+  return issues;
+}
+
+function scanFailed(error: string, dataType: string): never {
+  logger.error("catalog_health_scan_failed", { error, dataType });
+  throw internalError("catalog_health_scan_failed", "Catalog scan failed, please try again");
+}
+
+/**
+ * Scan Saleor and report catalog issues (superadmin diagnostics)
+ */
+export async function buildCatalogHealthReport(): Promise<CatalogHealthReport> {
+  const report: CatalogHealthReport = {
+    scannedChannels: 0,
+    scannedCategories: 0,
+    scannedProducts: 0,
+    complete: true,
+    issues: [],
+  };
+
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    // Nothing to diagnose in mock data
+    return report;
+  }
+
+  const catalog: CatalogSnapshot = { channels: [], categories: [], products: [] };
+
+  let after: string | null = null;
+  for (let page = 0; page < MAX_CATALOG_PAGES; page++) {
+    const variables: ProductTypesQueryVariables = { first: MAX_PAGE_SIZE, after };
+    const response = await client.execute<ProductTypesQuery>(PRODUCT_TYPES_QUERY, variables);
+    const productTypes = response.data?.productTypes;
+    if ((response.errors && response.errors.length > 0) || !productTypes) {
+      scanFailed((response.errors || []).map((e) => e.message).join(", "), "categories");
+    }
+    for (const edge of productTypes.edges) {
+      catalog.categories.push({
+        id: edge.node.id,
+        name: edge.node.name,
+        parentId: getParentCategoryId(metadataToRecord(edge.node.metadata)),
+      });
+    }
+    if (!productTypes.pageInfo.hasNextPage || !productTypes.pageInfo.endCursor) {
+      break;
+    }
+    after = productTypes.pageInfo.endCursor;
+  }
+
+  after = null;
+  // Pages shrink to stay within Saleor's query cost limit
+  const budget = createCostBudget(QUERY_NODE_COSTS.catalogProduct);
+  for (let page = 0; ; page++) {
+    if (page >= MAX_PRODUCT_PAGES) {
+      report.complete = false;
+      break;
+    }
+    const response = await executePageWithinCost<CatalogHealthQuery>(
+      client,
+      CATALOG_HEALTH_QUERY,
+      { after, includeChannels: page === 0 },
+      budget,
+    );
+    const products = response.data?.products;
+    if ((response.errors && response.errors.length > 0) || !products) {
+      scanFailed((response.errors || []).map((e) => e.message).join(", "), "products");
+    }
+    catalog.channels.push(...(response.data?.channels || []));
+    for (const edge of products.edges) {
+      catalog.products.push({
+        id: edge.node.id,
+        name: edge.node.name,
+        categoryId: edge.node.productType?.id ?? null,
+        channelIds: (edge.node.channelListings || []).map((listing) => listing.channel.id),
+      });
+    }
+    if (!products.pageInfo.hasNextPage || !products.pageInfo.endCursor) {
+      break;
+    }
+    after = products.pageInfo.endCursor;
+  }
+
+  report.scannedChannels = catalog.channels.length;
+  report.scannedCategories = catalog.categories.length;
+  report.scannedProducts = catalog.products.length;
+  report.issues = findCatalogIssues(catalog);
+  // An incomplete scan can't tell which categories are really empty
+  if (!report.complete) {
+    report.issues = report.issues.filter((i) => i.kind !== "EMPTY_CATEGORY");
+  }
+  logger.info("catalog_health_scanned", {
+    channels: report.scannedChannels,
+    categories: report.scannedCategories,
+    products: report.scannedProducts,
+    issues: report.issues.length,
+  });
+  return report;
+}
@@ -0,0 +1,84 @@
+// Category Tree Tests
+// Tests for categoryTree.ts - nested categories from tma_parent_category
+
+import { describe, it, expect } from "vitest";
+import {
+  buildCategoryTree,
+  getCategoryAncestors,
+  getCategoryScope,
+  getChildCategories,
+  getParentCategoryId,
+  isCategoryWithin,
+} from "./categoryTree";
+import { Category } from "./contracts";
+import { buildCategoryDishCountsQuery, fetchCategoryDishCounts } from "./saleorService";
+
+const categories: Category[] = [
+  { id: "drinks", name: "Drinks" },
+  { id: "hot", name: "Hot drinks", parentId: "drinks" },
+  { id: "tea", name: "Tea", parentId: "hot" },
+  { id: "pizza", name: "Pizza" },
+  { id: "orphan", name: "Orphan", parentId: "deleted" },
+  { id: "loopA", name: "Loop A", parentId: "loopB" },
+  { id: "loopB", name: "Loop B", parentId: "loopA" },
+];
+
+describe("category nesting", () => {
+  it("should read the parent from metadata", () => {
+    expect(getParentCategoryId({ tma_parent_category: " drinks " })).toBe("drinks");
+    expect(getParentCategoryId({})).toBeNull();
+  });
+
+  it("should walk ancestors at any depth", () => {
+    expect(getCategoryAncestors(categories, "tea")).toEqual(["hot", "drinks"]);
+    expect(isCategoryWithin(categories, "tea", "drinks")).toBe(true);
+    expect(isCategoryWithin(categories, "drinks", "tea")).toBe(false);
+    expect(isCategoryWithin(categories, "pizza", "pizza")).toBe(true);
+  });
+
+  it("should treat missing parents and cycles as top level", () => {
+    expect(getCategoryAncestors(categories, "orphan")).toEqual([]);
+    expect(getCategoryAncestors(categories, "loopA")).toEqual([]);
+    expect(getChildCategories(categories, null).map((c) => c.id)).toEqual([
+      "drinks",
+      "pizza",
+      "orphan",
+      "loopA",
+      "loopB",
+    ]);
+  });
+
+  it("should scope a section to its subcategories", () => {
+    expect(getCategoryScope(categories, "drinks")).toEqual(["drinks", "hot", "tea"]);
+    expect(getCategoryScope(categories, "unknown")).toEqual(["unknown"]);
+  });
+
+  it("should nest children in the tree", () => {
+    const [drinks] = buildCategoryTree(categories);
+    expect(drinks.children?.map((c) => c.id)).toEqual(["hot"]);
+    expect(drinks.children?.[0].children?.map((c) => c.id)).toEqual(["tea"]);
+    expect(drinks.children?.[0].children?.[0].children).toEqual([]);
+  });
+});
+
+describe("category dish counts", () => {
+  it("should alias one products count per category", () => {
+    const query = buildCategoryDishCountsQuery(2);
+    expect(query).toContain("$types0: [ID!], $types1: [ID!]");
+    expect(query).toContain("c1: products(");
+    expect(query).toContain("totalCount");
+  });
+
+  it("should count mock dishes including subcategories", async () => {
+    const counts = await fetchCategoryDishCounts(
+      [
+        { id: "menu", name: "Menu" },
+        { id: "catA", name: "Pizzas", parentId: "menu" },
+        { id: "catB", name: "Nigiri" },
+        { id: "empty", name: "Empty" },
+      ],
+      "restA",
+    );
+    expect(Object.fromEntries(counts)).toEqual({ menu: 2, catA: 2, catB: 1, empty: 0 });
+  });
+});
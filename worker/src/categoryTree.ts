@@ -0,0 +1,87 @@
+// Category Trees
+// Categories are Saleor product types, which are flat, so nesting is kept
+// in product type metadata: tma_parent_category holds the parent product
+// type's ID. A section's dishes include those of every category nested
+// under it, at any depth. Parents that don't exist and cycles are treated
+// as top level, so a bad edit in the dashboard can't hide a category.
+
+import { Category } from "./contracts";
+
+export const PARENT_CATEGORY_METADATA_KEY = "tma_parent_category";
+
+/**
+ * Parent category ID from product type metadata, null for top level
+ */
+export function getParentCategoryId(metadata: Record<string, string> | undefined): string | null {
+  return metadata?.[PARENT_CATEGORY_METADATA_KEY]?.trim() || null;
+}
+
+/**
+ * A category's ancestors, nearest first; the chain stops at a missing
+ * parent, and a category whose chain loops has none
+ */
+export function getCategoryAncestors(categories: Category[], categoryId: string): string[] {
+  const parents = new Map(categories.map((category) => [category.id, category.parentId ?? null]));
+  const ancestors: string[] = [];
+  let parentId = parents.get(categoryId) ?? null;
+  while (parentId && parents.has(parentId)) {
+    if (parentId === categoryId || ancestors.includes(parentId)) {
+      return [];
+    }
+    ancestors.push(parentId);
+    parentId = parents.get(parentId) ?? null;
+  }
+  return ancestors;
+}
+
+/**
+ * Whether a category is the given one or nested under it at any depth
+ */
+export function isCategoryWithin(
+  categories: Category[],
+  categoryId: string,
+  ancestorId: string,
+): boolean {
+  return (
+    categoryId === ancestorId || getCategoryAncestors(categories, categoryId).includes(ancestorId)
+  );
+}
+
+/**
+ * A category's ID followed by those of every category nested under it
+ */
+export function getCategoryScope(categories: Category[], categoryId: string): string[] {
+  return [
+    categoryId,
+    ...categories
+      .filter((category) => category.id !== categoryId)
+      .filter((category) => isCategoryWithin(categories, category.id, categoryId))
+      .map((category) => category.id),
+  ];
+}
+
+/**
+ * Categories directly under a parent (top level for null)
+ */
+export function getChildCategories(
+  categories: Category[],
+  parentId: string | null,
+): Category[] {
+  return categories.filter((category) => {
+    const ancestors = getCategoryAncestors(categories, category.id);
+    return parentId ? ancestors[0] === parentId : ancestors.length === 0;
+  });
+}
+
+/**
+ * Top level categories with their subcategories nested in children
+ */
+export function buildCategoryTree(
+  categories: Category[],
+  parentId: string | null = null,
+): Category[] {
+  return getChildCategories(categories, parentId).map((category) => ({
+    ...category,
+    children: buildCategoryTree(categories, category.id),
+  }));
+}
@@ -0,0 +1,92 @@
+// Client Config Tests
+// Tests for clientConfig.ts and dailyBanner.ts - the Mini App startup config
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { buildClientConfig, getMapConfig, getSupportContact } from "./clientConfig";
+import { getDailyBanner, setDailyBanner } from "./dailyBanner";
+
+// Mock the logger to avoid console output during tests
+vi.mock("./logger", () => ({
+  logger: {
+    info: vi.fn(),
+    error: vi.fn(),
+    warn: vi.fn(),
+    debug: vi.fn(),
+  },
+}));
+
+const VARS = [
+  "CLIENT_FEATURE_FLAGS",
+  "SUPPORT_TELEGRAM",
+  "SUPPORT_EMAIL",
+  "MAP_PROVIDER",
+  "MAP_API_KEY",
+  "LOYALTY_ENABLED",
+  "MIN_CLIENT_VERSION",
+];
+
+afterEach(() => {
+  for (const name of VARS) {
+    delete (globalThis as any)[name];
+  }
+});
+
+describe("buildClientConfig", () => {
+  it("should bootstrap from env vars and restaurants", async () => {
+    (globalThis as any).CLIENT_FEATURE_FLAGS = "newCheckout, darkMode";
+    (globalThis as any).LOYALTY_ENABLED = "true";
+    (globalThis as any).MIN_CLIENT_VERSION = "1.2.0";
+
+    const config = await buildClientConfig("1.3.0");
+
+    expect(config.updateRequired).toBe(false);
+    expect(config.features).toMatchObject({
+      loyalty: true,
+      flags: ["newCheckout", "darkMode"],
+    });
+    expect(config.currencies).toEqual(["USD"]);
+    expect(config.fulfillmentTypes).toEqual(["DELIVERY"]);
+    expect(config.support).toBeNull();
+    expect(config.map).toBeNull();
+  });
+
+  it("should report support contact and map provider when configured", () => {
+    (globalThis as any).SUPPORT_TELEGRAM = "@food_help";
+    (globalThis as any).MAP_PROVIDER = "Mapbox";
+    (globalThis as any).MAP_API_KEY = "pk.public";
+
+    expect(getSupportContact()).toEqual({ telegram: "@food_help", email: null });
+    expect(getMapConfig()).toEqual({ provider: "mapbox", apiKey: "pk.public" });
+  });
+});
+
+describe("daily banner", () => {
+  const now = new Date("2026-05-10T08:00:00Z");
+
+  it("should show the banner on its day only", async () => {
+    await setDailyBanner(
+      { date: "2026-05-11", text: "  Free   delivery  today ", linkUrl: "https://example.com" },
+      "admin",
+      now,
+    );
+
+    expect(await getDailyBanner(now)).toBeNull();
+    expect(await getDailyBanner(new Date("2026-05-11T12:00:00Z"))).toMatchObject({
+      text: "Free delivery today",
+      linkUrl: "https://example.com",
+      imageUrl: null,
+    });
+  });
+
+  it("should clear the banner with empty text", async () => {
+    await setDailyBanner({ date: "2026-05-12", text: "Hello" }, "admin", now);
+    expect(await setDailyBanner({ date: "2026-05-12", text: "" }, "admin", now)).toBeNull();
+    expect(await getDailyBanner(new Date("2026-05-12T00:00:00Z"))).toBeNull();
+  });
+
+  it("should reject past dates", async () => {
+    await expect(
+      setDailyBanner({ date: "2026-05-09", text: "Late" }, "admin", now),
+    ).rejects.toMatchObject({ code: "BAD_USER_INPUT" });
+  });
+});
@@ -0,0 +1,73 @@
+// Client Bootstrap Config
+// clientConfig gives the Mini App everything it needs before its first
+// screen in one request: version gating, feature switches, the currencies
+// and fulfillment types restaurants offer, the support contact, the map
+// provider key and today's banner. Switches the backend derives (loyalty,
+// payments, tips) sit next to CLIENT_FEATURE_FLAGS, which passes
+// frontend-only flags through untouched.
+
+import {
+  ClientConfig,
+  ClientFeatures,
+  FulfillmentType,
+  MapConfig,
+  SupportContact,
+} from "./contracts";
+import { getListVar, getVar } from "./config";
+import { getClientVersionStatus } from "./clientVersion";
+import { getDailyBanner } from "./dailyBanner";
+import { isDemoMode } from "./demoMode";
+import { isLoyaltyEnabled } from "./loyalty";
+import { getPickupLocation } from "./pickup";
+import { fetchChannels } from "./saleorService";
+import { isTelegramPaymentsEnabled } from "./telegramPayments";
+import { getMaxTipAmount } from "./tips";
+
+export function getClientFeatures(): ClientFeatures {
+  return {
+    loyalty: isLoyaltyEnabled(),
+    onlinePayments: isTelegramPaymentsEnabled() && !isDemoMode(),
+    tips: getMaxTipAmount() > 0,
+    demo: isDemoMode(),
+    flags: getListVar("CLIENT_FEATURE_FLAGS"),
+  };
+}
+
+/**
+ * Where users get help (SUPPORT_TELEGRAM, SUPPORT_EMAIL), null when neither is set
+ */
+export function getSupportContact(): SupportContact | null {
+  const telegram = getVar("SUPPORT_TELEGRAM")?.trim() || null;
+  const email = getVar("SUPPORT_EMAIL")?.trim() || null;
+  return telegram || email ? { telegram, email } : null;
+}
+
+/**
+ * Map provider for address picking (MAP_PROVIDER, MAP_API_KEY), null when unset
+ * The key ships to browsers, so it must be a referrer-restricted public key
+ */
+export function getMapConfig(): MapConfig | null {
+  const provider = getVar("MAP_PROVIDER")?.trim().toLowerCase();
+  return provider ? { provider, apiKey: getVar("MAP_API_KEY")?.trim() || null } : null;
+}
+
+/**
+ * Everything the Mini App loads at startup
+ */
+export async function buildClientConfig(clientVersion?: string): Promise<ClientConfig> {
+  const [channels, banner] = await Promise.all([fetchChannels(), getDailyBanner()]);
+  const active = channels.filter((ch) => ch.isActive);
+  const fulfillmentTypes: FulfillmentType[] = active.some((ch) => getPickupLocation(ch))
+    ? ["DELIVERY", "PICKUP"]
+    : ["DELIVERY"];
+
+  return {
+    ...getClientVersionStatus(clientVersion),
+    features: getClientFeatures(),
+    currencies: Array.from(new Set(active.map((ch) => ch.currencyCode).filter(Boolean))).sort(),
+    fulfillmentTypes,
+    support: getSupportContact(),
+    map: getMapConfig(),
+    banner,
+  };
+}
@@ -0,0 +1,80 @@
+// Client Version Tests
+// Tests for clientVersion.ts - X-TMA-Client-Version parsing and gating
+
+import { describe, it, expect, afterEach } from "vitest";
+import {
+  assertSupportedClientVersion,
+  compareVersions,
+  getClientVersionStatus,
+  getRequestClientVersion,
+  parseVersion,
+} from "./clientVersion";
+
+describe("parseVersion", () => {
+  it("should parse full, short and prefixed versions", () => {
+    expect(parseVersion("1.4.2")).toEqual([1, 4, 2]);
+    expect(parseVersion("v2.0")).toEqual([2, 0, 0]);
+    expect(parseVersion("1.5.0-beta.1")).toEqual([1, 5, 0]);
+  });
+
+  it("should reject anything else", () => {
+    expect(parseVersion("latest")).toBeNull();
+    expect(parseVersion("")).toBeNull();
+    expect(parseVersion(undefined)).toBeNull();
+  });
+
+  it("should compare numerically", () => {
+    expect(compareVersions([1, 10, 0], [1, 9, 0])).toBeGreaterThan(0);
+    expect(compareVersions([1, 2, 3], [1, 2, 3])).toBe(0);
+    expect(compareVersions([0, 9, 9], [1, 0, 0])).toBeLessThan(0);
+  });
+});
+
+describe("client version gating", () => {
+  afterEach(() => {
+    delete (globalThis as any).MIN_CLIENT_VERSION;
+    delete (globalThis as any).LATEST_CLIENT_VERSION;
+  });
+
+  it("should read the version header", () => {
+    const request = new Request("https://example.com/graphql", {
+      headers: { "X-TMA-Client-Version": " 1.4.0 " },
+    });
+    expect(getRequestClientVersion(request)).toBe("1.4.0");
+    expect(getRequestClientVersion(new Request("https://example.com/graphql"))).toBeUndefined();
+  });
+
+  it("should require an update below the minimum", () => {
+    (globalThis as any).MIN_CLIENT_VERSION = "1.4.0";
+
+    expect(() => assertSupportedClientVersion("1.3.9")).toThrow(
+      expect.objectContaining({ code: "UPDATE_REQUIRED", statusCode: 426 }),
+    );
+    expect(() => assertSupportedClientVersion("1.4.0")).not.toThrow();
+    // Clients that predate the header are not gated
+    expect(() => assertSupportedClientVersion(undefined)).not.toThrow();
+  });
+
+  it("should report the supported range", () => {
+    (globalThis as any).MIN_CLIENT_VERSION = "1.4.0";
+    (globalThis as any).LATEST_CLIENT_VERSION = "1.6.0";
+
+    expect(getClientVersionStatus("1.5.0")).toEqual({
+      minVersion: "1.4.0",
+      latestVersion: "1.6.0",
+      clientVersion: "1.5.0",
+      updateRequired: false,
+      updateAvailable: true,
+    });
+    expect(getClientVersionStatus("1.2.0").updateRequired).toBe(true);
+  });
+
+  it("should not gate without configured versions", () => {
+    expect(getClientVersionStatus("0.1.0")).toMatchObject({
+      minVersion: null,
+      latestVersion: null,
+      updateRequired: false,
+      updateAvailable: false,
+    });
+  });
+});
@@ -0,0 +1,91 @@
+// Client Version Gating
+// The Mini App sends its version in X-TMA-Client-Version ("1.4.0").
+// Clients older than MIN_CLIENT_VERSION get UPDATE_REQUIRED on every
+// operation except clientConfig, which reports the supported range
+// (MIN_CLIENT_VERSION, LATEST_CLIENT_VERSION) so the app can prompt for a
+// reload before a breaking contract change. Requests without the header
+// (tools, older builds that predate it) are not gated.
+
+import { ClientVersionStatus } from "./contracts";
+import { getVar } from "./config";
+import { updateRequiredError } from "./errors";
+
+export const CLIENT_VERSION_HEADER = "X-TMA-Client-Version";
+
+const MAX_VERSION_LENGTH = 32;
+const VERSION_PATTERN = /^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:[-+].*)?$/;
+
+/**
+ * Client version from the request header, if sent
+ */
+export function getRequestClientVersion(request: Request): string | undefined {
+  const value = request.headers.get(CLIENT_VERSION_HEADER)?.trim();
+  return value ? value.slice(0, MAX_VERSION_LENGTH) : undefined;
+}
+
+/**
+ * [major, minor, patch] of a version like "1.4", "v2.0.1" or "1.4.0-beta";
+ * null when it isn't one
+ */
+export function parseVersion(version: string | undefined): number[] | null {
+  const match = version?.trim().match(VERSION_PATTERN);
+  if (!match) {
+    return null;
+  }
+  return [match[1], match[2], match[3]].map((part) => parseInt(part || "0", 10));
+}
+
+/**
+ * Negative, zero or positive as a is older than, equal to or newer than b
+ */
+export function compareVersions(a: number[], b: number[]): number {
+  for (let i = 0; i < 3; i++) {
+    if (a[i] !== b[i]) {
+      return a[i] - b[i];
+    }
+  }
+  return 0;
+}
+
+function getConfiguredVersion(name: string): string | null {
+  const value = getVar(name);
+  return value && parseVersion(value) ? value : null;
+}
+
+/**
+ * Supported version range and where a client stands in it
+ */
+export function getClientVersionStatus(clientVersion?: string): ClientVersionStatus {
+  const minVersion = getConfiguredVersion("MIN_CLIENT_VERSION");
+  const latestVersion = getConfiguredVersion("LATEST_CLIENT_VERSION");
+  const current = parseVersion(clientVersion);
+  return {
+    minVersion,
+    latestVersion,
+    clientVersion: clientVersion ?? null,
+    updateRequired: isUpdateRequired(clientVersion),
+    updateAvailable:
+      !!current &&
+      !!latestVersion &&
+      compareVersions(current, parseVersion(latestVersion)!) < 0,
+  };
+}
+
+/**
+ * Whether a client is older than MIN_CLIENT_VERSION
+ * Missing or unparseable versions are not gated
+ */
+export function isUpdateRequired(clientVersion: string | undefined): boolean {
+  const minVersion = getConfiguredVersion("MIN_CLIENT_VERSION");
+  const current = parseVersion(clientVersion);
+  return !!current && !!minVersion && compareVersions(current, parseVersion(minVersion)!) < 0;
+}
+
+/**
+ * Refuse operations from clients below MIN_CLIENT_VERSION
+ */
+export function assertSupportedClientVersion(clientVersion: string | undefined): void {
+  if (isUpdateRequired(clientVersion)) {
+    throw updateRequiredError(getConfiguredVersion("MIN_CLIENT_VERSION")!);
+  }
+}
@@ -0,0 +1,63 @@
+// Runtime configuration helpers
+// Cloudflare Workers (service-worker format) expose vars and secrets on
+// globalThis; scripts/dev.mjs loads .dev.vars the same way.
+// See: worker/ENVIRONMENT.md for the full variable reference
+
+/**
+ * Read a raw configuration value, treating empty strings as unset
+ */
+export function getVar(name: string): string | undefined {
+  if (typeof globalThis === "undefined") {
+    return undefined;
+  }
+  const value = (globalThis as any)[name];
+  if (value === undefined || value === null) {
+    return undefined;
+  }
+  const str = String(value).trim();
+  return str.length > 0 ? str : undefined;
+}
+
+/**
+ * Read a string value with a default
+ */
+export function getStringVar(name: string, fallback: string): string {
+  return getVar(name) ?? fallback;
+}
+
+/**
+ * Read a boolean flag ("true"/"1"/"yes" are truthy)
+ */
+export function getBooleanVar(name: string, fallback = false): boolean {
+  const value = getVar(name);
+  if (value === undefined) {
+    return fallback;
+  }
+  return ["true", "1", "yes", "on"].includes(value.toLowerCase());
+}
+
+/**
+ * Read a numeric value, falling back when unset or not a finite number
+ */
+export function getNumberVar(name: string, fallback: number): number {
+  const value = getVar(name);
+  if (value === undefined) {
+    return fallback;
+  }
+  const parsed = Number(value);
+  return Number.isFinite(parsed) ? parsed : fallback;
+}
+
+/**
+ * Read a comma-separated list, dropping empty entries
+ */
+export function getListVar(name: string): string[] {
+  const value = getVar(name);
+  if (!value) {
+    return [];
+  }
+  return value
+    .split(",")
+    .map((entry) => entry.trim())
+    .filter((entry) => entry.length > 0);
+}
@@ -165,10 +165,21 @@ export interface CartState {
    items: CartItem[];
 }
 
+/**
+ * Amount with a display string formatted for the Telegram user's
+ * language_code, e.g. { amount: 12.5, currency: "EUR", displayAmount: "€12.50" }
+ */
+export interface Money {
+  amount: number;
+  currency: string;
+  displayAmount: string;
+}
+
 export interface Cart {
   restaurantId: string | null;
   items: CartItem[];
   total: number;
+  totalMoney?: Money | null; // null for an empty cart
   itemCount: number;
 }
 
@@ -213,6 +224,7 @@ export interface UpdateCartItemInput {
      slug: string;
      name?: string;
    }>;
+   metadata?: Record<string, string>; // Saleor channel metadata (tma_* keys)
    // Legacy fields for backward compatibility with Restaurant interface
    description?: string;
    imageUrl?: string;
@@ -230,29 +242,204 @@ export interface UpdateCartItemInput {
    name: string;
    description?: string;
    imageUrl?: string;
-   tags?: string[];
+   tags?: string[]; // cuisine tags from tma_tags channel metadata (tagLabels.ts)
+   tagLabels?: TagLabel[]; // tags in the user's language
    categories: Category[];
    deliveryLocations?: DeliveryLocation[];
+   announcement?: string | null; // owner notice from tma_announcement
+   isOpenNow?: boolean; // from tma_hours; omitted when no hours are set
+   opensAt?: string | null; // next opening while closed (openingHours.ts)
+   closesAt?: string | null; // next closing while open
+   distanceKm?: number | null; // from the user's location (geo.ts)
+   busy?: boolean; // at tma_max_active_orders (kitchenCapacity.ts)
+   prepMinutes?: number | null; // tma_prep_minutes or DEFAULT_PREP_MINUTES (eta.ts)
+   acceptingOrders?: boolean; // false while tma_paused (restaurantPause.ts)
+   isPromoted?: boolean; // tma_promoted_until in the future (promotedRestaurants.ts)
+   // Detail fields, filled by the restaurant query only (restaurantDetails.ts)
+   address?: string | null;
+   phone?: string | null;
+   timezone?: string | null;
+   openingHours?: OpeningHoursDay[] | null;
+   minOrder?: Money | null;
+   deliveryFee?: Money | null;
+   freeDeliveryThreshold?: Money | null;
+   rating?: number | null; // average of approved reviews
+   reviewCount?: number;
+   ratingSummary?: RatingSummary;
  }
 
+/**
+ * User location passed to the restaurant list
+ */
+export interface GeoPoint {
+  lat: number;
+  lng: number;
+}
+
+/**
+ * Weekly opening hours for one weekday ("HH:MM-HH:MM" ranges, empty when closed)
+ */
+export interface OpeningHoursDay {
+  weekday: string; // "mon" ... "sun"
+  hours: string[];
+}
+
+/**
+ * Stable tag key (filters match on it) with its display label
+ */
+export interface TagLabel {
+  key: string;
+  label: string;
+}
+
+/**
+ * Restaurant's concurrent active order cap (null when uncapped)
+ */
+export interface KitchenCapacity {
+  restaurantId: string;
+  maxActiveOrders: number | null;
+}
+
+/**
+ * Opening hours for one local date (holiday or special hours)
+ */
+export interface OpeningHoursOverride {
+  restaurantId: string;
+  date: string; // YYYY-MM-DD in the restaurant's timezone
+  hours: string[] | null; // "HH:MM-HH:MM" ranges; [] closed, null cleared
+}
+
+export interface SetOpeningHoursOverrideInput {
+  restaurantId: string;
+  date: string;
+  hours?: string[] | null;
+  clear?: boolean | null;
+}
+
+/**
+ * Window a scheduled order can be delivered in
+ */
+export interface DeliverySlot {
+  start: string; // ISO timestamp
+  end: string;
+  remaining: number | null; // orders the slot still takes, null when uncapped
+}
+
+/**
+ * Restaurant announcement as saved by an owner
+ */
+export interface RestaurantAnnouncement {
+  restaurantId: string;
+  message: string | null; // null when cleared
+  expiresAt: string | null; // ISO timestamp
+}
+
+export interface RestaurantPause {
+  restaurantId: string;
+  paused: boolean;
+  pausedUntil: string | null; // ISO timestamp; null pauses until resumed
+}
+
+export interface SetRestaurantAnnouncementInput {
+  restaurantId: string;
+  message: string; // empty clears the announcement
+  expiresAt?: string;
+}
+
+/**
+ * Relay-style page of a list (pagination.ts)
+ */
+export interface PageInfo {
+  hasNextPage: boolean;
+  endCursor: string | null; // pass as after to get the next page
+}
+
+export interface Edge<T> {
+  cursor: string;
+  node: T;
+}
+
+export interface Connection<T> {
+  edges: Edge<T>[];
+  pageInfo: PageInfo;
+}
+
 export interface Category {
    id: string;
    channelId?: string;
    restaurantId?: string;
    name: string;
    imageUrl?: string;
+   description?: string; // product type tma_description, when set
+   descriptionHtml?: string;
+   parentId?: string | null; // tma_parent_category (categoryTree.ts)
+   dishCount?: number | null; // listed dishes, subcategories included
+   children?: Category[]; // subcategories (categoryTree query)
 }
 
 export interface Dish {
    id: string;
    name: string;
-   description: string;
+   description: string; // plain text (richText.ts)
+   descriptionHtml: string; // description as Telegram HTML
    price: number;
    currency: string;
    categoryId: string;
    channelId?: string;
    imageUrl: string;
    restaurantId?: string;
+   taxIncluded?: boolean; // true when price is shown gross (tax inclusive)
+   prepMinutes?: number | null; // from product tma_prep_minutes metadata
+   calories?: number | null; // kcal per serving, product tma_calories metadata
+   priceMoney?: Money; // price formatted for the user's language
+   available: boolean; // purchasable in the channel and in stock
+   soldOut: boolean; // stock is tracked and none is left
+   dietaryTags: string[]; // from product tma_dietary metadata (dietaryTags.ts)
+   allergens: string[]; // from product tma_allergens metadata
+   dietaryTagLabels?: TagLabel[]; // dietaryTags in the user's language
+   allergenLabels?: TagLabel[]; // allergens in the user's language
+   images: Image[]; // Saleor media gallery (productMedia.ts)
+   variantIds?: string[]; // sellable variants (cart dishIds); not in the schema
+}
+
+/**
+ * Gallery image in three sizes
+ */
+export interface Image {
+   id: string;
+   alt: string;
+   url: string; // GALLERY_IMAGE_SIZE
+   thumbnailUrl: string; // THUMBNAIL_SIZE
+   originalUrl: string;
+}
+
+/**
+ * Selection attribute of a variant, e.g. { name: "Size", value: "35cm" }
+ */
+export interface DishVariantOption {
+  name: string;
+  value: string;
+}
+
+/**
+ * Sellable variant of a dish; its id is the dishId for cart and placeOrder
+ */
+export interface DishVariant {
+  id: string;
+  name: string;
+  price: number;
+  currency: string;
+  priceMoney?: Money;
+  available: boolean;
+  quantityAvailable: number | null; // null when stock is not tracked
+  options: DishVariantOption[];
+}
+
+/**
+ * Dish with every variant (dish query); price is the lowest variant price
+ */
+export interface DishDetail extends Dish {
+  variants: DishVariant[];
 }
 
 export interface DeliveryLocation {
@@ -265,6 +452,54 @@ export interface DeliveryLocation {
   mapsUrl?: string;
 }
 
+/**
+ * Delivery address a user saved for reuse
+ */
+export interface SavedAddress {
+  id: string;
+  label: string; // "Home", "Work"
+  location: DeliveryLocation;
+  isDefault?: boolean; // used when no location is given (deliveryZones.ts)
+  createdAt: string; // ISO timestamp
+}
+
+export type FavoriteKind = "RESTAURANT" | "DISH";
+
+/**
+ * Restaurant or dish a user saved
+ */
+export interface Favorite {
+  kind: FavoriteKind;
+  targetId: string; // restaurant (channel) or dish ID
+  restaurantId?: string; // dish favorites: the restaurant it was saved from
+  createdAt: string; // ISO timestamp
+}
+
+/**
+ * A dish the user orders often, for one-tap reordering
+ */
+export interface RecentDish {
+  dish: Dish;
+  restaurantId: string;
+  orderCount: number; // orders it was in
+  quantity: number; // units ordered in total
+  lastOrderedAt: string; // ISO timestamp
+}
+
+export interface FavoriteInput {
+  kind: FavoriteKind;
+  targetId: string;
+  restaurantId?: string | null;
+}
+
+/**
+ * Favorite with its current restaurant or dish
+ */
+export interface FavoriteEntry extends Favorite {
+  restaurant: Restaurant | null;
+  dish: Dish | null;
+}
+
 export interface OrderItemInput {
    dishId: string;
    quantity: number;
@@ -272,24 +507,936 @@ export interface OrderItemInput {
    channelId?: string;
 }
 
+/**
+ * How an order reaches the customer
+ */
+export type FulfillmentType = "DELIVERY" | "PICKUP";
+
+/**
+ * How the customer pays; CASH and CARD_ON_DELIVERY are settled at handover
+ */
+export type OrderPaymentMethod = "CASH" | "CARD_ON_DELIVERY" | "ONLINE";
+
 export interface PlaceOrderInput {
    restaurantId: string;
    channelId?: string;
-   deliveryLocation: DeliveryLocation;
+   fulfillmentType?: FulfillmentType; // defaults to DELIVERY
+   paymentMethod?: OrderPaymentMethod; // defaults to ONLINE
+   deliveryLocation: DeliveryLocation; // for PICKUP, the restaurant's pickup address
    items: OrderItemInput[];
    customerNote?: string;
+   scheduledFor?: string; // ISO timestamp for "order for later"
+   estimatedDeliveryAt?: string; // computed by the server at placement
+   tipAmount?: number; // in the order currency, capped by MAX_TIP_AMOUNT
+   voucherCode?: string; // Saleor voucher (promo) code
+   giftCardCode?: string; // Saleor gift card; forces the checkout pipeline
+   redeemPoints?: boolean; // spend loyalty points; forces the draft pipeline
+   loyaltyPoints?: number; // points available to redeem, set by the server
+   serviceFee?: number; // restaurant's tma_service_fee, set by the server
 }
 
 export interface PlaceOrderPayload {
   orderId: string;
   status: string;
   estimatedDelivery?: string;
+  estimatedDeliveryAt?: string;
+  scheduledFor?: string;
+  state?: string; // PENDING_PAYMENT when payment is required before completion
+  paymentDeadline?: string;
+  fulfillmentType?: FulfillmentType;
+  pickupAddress?: string;
+  paymentMethod?: OrderPaymentMethod;
+  tipAmount?: number;
+  voucherCode?: string;
+  giftCardLast4?: string;
+  giftCardBalance?: number; // remaining after this order
+  loyaltyPointsRedeemed?: number;
+  totals?: OrderTotals;
+  handoffCode?: string; // shown to staff at handoff (handoffCodes.ts)
+  handoffQrPayload?: string;
+}
+
+/**
+ * Gross price breakdown of an order (tax included in the other amounts);
+ * subtotal + deliveryFee + serviceFee + tip - discount = grandTotal
+ */
+export interface OrderTotals {
+  currency: string;
+  subtotal: number; // dishes before discounts
+  deliveryFee: number;
+  serviceFee: number;
+  tip: number;
+  tax: number;
+  discount: number; // promotions, vouchers and loyalty points
+  grandTotal: number;
+}
+
+// ============================================================
+// Order Quote Types
+// ============================================================
+
+export interface OrderQuoteLine {
+  dishId: string;
+  name: string;
+  quantity: number;
+  unitPrice: number; // after promotions
+  undiscountedUnitPrice: number;
+  discount: number; // line total saved by promotions
+  lineTotal: number;
+}
+
+/**
+ * Price preview for a PlaceOrderInput; nothing is created in Saleor
+ */
+export interface OrderQuote {
+  restaurantId: string;
+  currency: string;
+  lines: OrderQuoteLine[];
+  subtotal: number; // sum of line totals, after discounts
+  discount: number;
+  deliveryFee: number;
+  serviceFee: number;
+  tipAmount: number;
+  tax: number; // included in the line prices and total
+  total: number;
+  totalMoney?: Money;
+}
+
+// ============================================================
+// Promo Code Types
+// ============================================================
+
+/**
+ * Saleor voucher value type
+ */
+export type PromoDiscountType = "PERCENTAGE" | "FIXED";
+
+/**
+ * What a Saleor voucher discounts
+ */
+export type PromoDiscountScope = "ENTIRE_ORDER" | "SHIPPING" | "SPECIFIC_PRODUCT";
+
+/**
+ * Promo code check for a restaurant, shown before ordering
+ */
+export interface PromoCodeValidation {
+  code: string;
+  valid: boolean;
+  reason: string | null; // why an invalid code was rejected
+  discountType: PromoDiscountType | null;
+  appliesTo: PromoDiscountScope | null;
+  discountValue: number | null; // percent or amount, per discountType
+  currency: string | null;
+  minSpent: number | null;
+}
+
+// ============================================================
+// Order Detail & History Types
+// ============================================================
+
+export interface OrderLine {
+  dishId: string;
+  name: string;
+  quantity: number;
+  unitPrice?: number;
+}
+
+/**
+ * Order as exposed by the order detail/history queries
+ */
+export interface OrderDetails {
+  orderId: string;
+  number?: number;
+  status: string; // raw Saleor status
+  // PENDING_PAYMENT | PLACED | PREPARING | OUT_FOR_DELIVERY | COMPLETED | CANCELLED | EXPIRED
+  normalizedStatus: string;
+  restaurantId?: string;
+  createdAt: string;
+  total: number;
+  currency: string;
+  totalMoney: Money;
+  lines: OrderLine[];
+  deliveryLocation: DeliveryLocation;
+  customerNote?: string;
+  scheduledFor?: string;
+  estimatedDeliveryAt?: string;
+  state?: string; // worker app state: PENDING_PAYMENT | PAID | EXPIRED
+  paymentDeadline?: string;
+  fulfillmentType: FulfillmentType;
+  pickupAddress?: string;
+  paymentMethod: OrderPaymentMethod;
+  tipAmount?: number;
+  voucherCode?: string;
+  totals: OrderTotals;
+  handoffCode?: string; // customer's own views only, never staff ones
+  handoffQrPayload?: string;
+  handedOffAt?: string;
+  deliveredAt?: string;
+  cancellation?: OrderCancellation | null;
+}
+
+export type CancellationReasonCode =
+  | "CUSTOMER_REQUEST"
+  | "RESTAURANT_REJECTED"
+  | "OUT_OF_STOCK"
+  | "RESTAURANT_CLOSED"
+  | "KITCHEN_BUSY"
+  | "OUT_OF_DELIVERY_AREA"
+  | "PAYMENT_TIMEOUT"
+  | "FRAUD_CHECK";
+
+export type CancelledBy = "CUSTOMER" | "RESTAURANT" | "SYSTEM";
+
+/**
+ * Why and by whom an order was cancelled
+ */
+export interface OrderCancellation {
+  reason: CancellationReasonCode;
+  cancelledBy: CancelledBy;
+  note: string | null; // free text shown to the customer
+  cancelledAt: string | null; // unknown for orders cancelled before reasons were kept
+}
+
+/**
+ * verifyHandoffCode input: a scanned QR payload, or the order and the
+ * code the customer reads out
+ */
+export interface VerifyHandoffCodeInput {
+  orderId?: string | null;
+  code?: string | null;
+  qrPayload?: string | null;
+}
+
+export interface HandoffVerification {
+  orderId: string;
+  number: number | null;
+  valid: boolean; // the code belongs to this order
+  handedOffAt: string | null; // when the handoff was first recorded
+}
+
+/**
+ * Result of a courier marking an order delivered
+ */
+export interface OrderDelivery {
+  orderId: string;
+  number: number | null;
+  deliveredAt: string;
+  fulfilled: boolean; // Saleor fulfillment created (or already there)
+}
+
+// ============================================================
+// Price History Types
+// ============================================================
+
+export interface PriceSnapshot {
+  dishId: string;
+  restaurantId: string;
+  price: number; // menu price as shown (gross or net, see taxIncluded)
+  currency: string;
+  taxIncluded: boolean;
+  recordedAt: string; // when the price was first seen
+}
+
+// ============================================================
+// Operation Audit Types
+// ============================================================
+
+/**
+ * Distinct GraphQL document + variables shape seen by the worker
+ */
+export interface OperationAuditRecord {
+  documentHash: string;
+  variablesShapeHash: string;
+  operationName: string | null;
+  document: string;
+  variablesShape: string; // JSON-encoded shape, values replaced by type names
+  count: number;
+  firstSeenAt: string; // ISO timestamp
+  lastSeenAt: string; // ISO timestamp
+  allowlisted: boolean;
+}
+
+// ============================================================
+// Order Pipeline Shadow Types
+// ============================================================
+
+/**
+ * One order placed through the draft pipeline and replayed through the
+ * checkout pipeline in shadow mode
+ */
+export interface ShadowOrderComparison {
+  id: string;
+  createdAt: string; // ISO timestamp
+  primaryOrderId: string | null;
+  shadowOrderId: string | null; // cancelled after comparison
+  primarySuccess: boolean;
+  shadowSuccess: boolean;
+  primaryMs: number;
+  shadowMs: number;
+  primaryTotal: number | null;
+  shadowTotal: number | null;
+  divergences: string[]; // empty when the results match
+}
+
+export interface OrderPipelineShadowReport {
+  enabled: boolean;
+  total: number;
+  divergent: number;
+  primaryP50Ms: number;
+  primaryP95Ms: number;
+  shadowP50Ms: number;
+  shadowP95Ms: number;
+  recentDivergences: ShadowOrderComparison[];
+}
+
+// ============================================================
+// Gift Card Types
+// ============================================================
+
+/**
+ * Gift card balance; the full code is never returned
+ */
+export interface GiftCardBalance {
+  last4: string;
+  balance: number;
+  currency: string;
+  expiresAt: string | null; // YYYY-MM-DD
+  usable: boolean; // active, not expired and with balance left
+}
+
+// ============================================================
+// Loyalty Types
+// ============================================================
+
+export interface LoyaltyBalance {
+  enabled: boolean;
+  points: number;
+  pointValue: number; // currency value of one point
+  value: number; // points * pointValue
+}
+
+// ============================================================
+// Metadata Migration Types
+// ============================================================
+
+export type MetadataObjectType = "CHANNEL" | "PRODUCT";
+
+export type MetadataChangeStatus = "PLANNED" | "APPLIED" | "CONFLICT" | "FAILED";
+
+/**
+ * One legacy metadata key moved (or reformatted) to the tma_* contract
+ */
+export interface MetadataMigrationChange {
+  objectType: MetadataObjectType;
+  objectId: string;
+  objectName: string;
+  fromKey: string;
+  toKey: string; // same as fromKey when only the value format changes
+  oldValue: string;
+  newValue: string;
+  status: MetadataChangeStatus;
+}
+
+export interface MetadataMigrationReport {
+  dryRun: boolean;
+  scannedChannels: number;
+  scannedProducts: number;
+  changedObjects: number;
+  conflicts: number;
+  failures: number;
+  nextCursor: string | null; // set when the run stopped before the catalog end
+  changes: MetadataMigrationChange[];
+}
+
+export type CatalogObjectType = "CHANNEL" | "CATEGORY" | "PRODUCT";
+
+export type CatalogIssueKind =
+  | "DUPLICATE_RESTAURANT"
+  | "DUPLICATE_CATEGORY"
+  | "ORPHAN_CATEGORY"
+  | "EMPTY_CATEGORY"
+  | "MULTI_RESTAURANT_PRODUCT";
+
+/**
+ * A catalog problem found by the health scan, with a suggested fix
+ */
+export interface CatalogIssue {
+  kind: CatalogIssueKind;
+  objectType: CatalogObjectType;
+  objectIds: string[]; // every object involved, e.g. all duplicates
+  objectNames: string[];
+  message: string;
+  suggestedFix: string;
+}
+
+export interface CatalogHealthReport {
+  scannedChannels: number;
+  scannedCategories: number;
+  scannedProducts: number;
+  complete: boolean; // false when the product scan stopped at its page limit
+  issues: CatalogIssue[];
+}
+
+// ============================================================
+// Commission & Payout Types
+// ============================================================
+
+/**
+ * Commission rate charged to a restaurant (fraction, e.g. 0.15 = 15%)
+ */
+export interface CommissionRate {
+  restaurantId: string;
+  rate: number;
+  updatedAt: string | null;
+  updatedBy: string | null;
+}
+
+export interface SetCommissionRateInput {
+  restaurantId: string;
+  rate: number;
+}
+
+/**
+ * Payout report for one restaurant, period and currency
+ */
+export interface PayoutReport {
+  restaurantId: string;
+  periodStart: string;
+  periodEnd: string;
+  currency: string;
+  orderCount: number;
+  gross: number;
+  commissionRate: number;
+  commission: number;
+  net: number;
+}
+
+// ============================================================
+// Tax Configuration Types
+// ============================================================
+
+/**
+ * Whether menu prices are displayed including (GROSS) or excluding (NET) tax
+ */
+export type PriceDisplay = "GROSS" | "NET";
+
+/**
+ * Saleor tax configuration for a restaurant channel
+ */
+export interface TaxConfiguration {
+  restaurantId: string;
+  chargeTaxes: boolean;
+  pricesEnteredWithTax: boolean;
+  displayGrossPrices: boolean;
+  countryCode: string | null;
+  taxRate: number; // percent, e.g. 20 = 20%
+  priceDisplay: PriceDisplay;
+}
+
+// ============================================================
+// Image Format Types
+// ============================================================
+
+/**
+ * Saleor thumbnail format (ThumbnailFormatEnum); ORIGINAL is the uploaded
+ * file's format, usually JPEG
+ */
+export type ImageFormat = "AVIF" | "WEBP" | "ORIGINAL";
+
+/**
+ * restaurants ordering (restaurantSort.ts)
+ */
+export type RestaurantSortBy = "RATING" | "DISTANCE" | "PREP_TIME" | "ALPHABETICAL";
+
+/**
+ * categoryDishes ordering (dishPopularity.ts)
+ */
+export type DishSortBy = "PRICE_ASC" | "PRICE_DESC" | "NAME" | "POPULARITY";
+
+// ============================================================
+// Broadcast Types
+// ============================================================
+
+export type BroadcastStatus =
+  | "DRY_RUN"
+  | "PENDING"
+  | "RUNNING"
+  | "COMPLETED"
+  | "ABORTED";
+
+/**
+ * Audience filter for a broadcast (all criteria must match)
+ */
+export interface BroadcastFilterInput {
+  lastOrderWithinDays?: number;
+  city?: string;
+  language?: string; // Telegram language code prefix, e.g. "en"
+}
+
+export interface BroadcastTranslation {
+  language: string;
+  message: string;
+}
+
+export interface StartBroadcastInput {
+  message: string; // supports {{city}} and {{language}} placeholders
+  translations?: BroadcastTranslation[];
+  filter?: BroadcastFilterInput;
+  dryRun?: boolean;
+}
+
+/**
+ * Broadcast with delivery stats
+ */
+export interface Broadcast {
+  id: string;
+  status: BroadcastStatus;
+  message: string;
+  translations: BroadcastTranslation[];
+  filter: BroadcastFilterInput;
+  createdBy: string;
+  createdAt: string;
+  updatedAt: string;
+  completedAt: string | null;
+  recipientCount: number;
+  sent: number;
+  failed: number;
+  blocked: number; // users who blocked the bot
+}
+
+// ============================================================
+// Notification Failure Types
+// ============================================================
+
+export type NotificationFailureReason = "BLOCKED" | "RATE_LIMITED" | "ERROR";
+
+export type NotificationFailureStatus = "FAILED" | "DELIVERED" | "PERMANENTLY_FAILED";
+
+/**
+ * A bot message Telegram refused
+ */
+export interface NotificationFailure {
+  id: string;
+  userId: string;
+  text: string;
+  reason: NotificationFailureReason;
+  httpStatus: number; // 0 when the request didn't complete
+  status: NotificationFailureStatus;
+  attempts: number;
+  firstFailedAt: string;
+  lastFailedAt: string;
+  resolvedAt: string | null; // delivered or given up
+  resolvedBy: string | null;
+  userSuppressed: boolean; // user blocked the bot too often to be messaged
+}
+
+export interface NotificationFailureFilter {
+  status?: NotificationFailureStatus | null; // FAILED when omitted
+  reason?: NotificationFailureReason | null;
+  userId?: string | null;
+}
+
+// ============================================================
+// Review Moderation Types
+// ============================================================
+
+export type ReviewStatus = "PENDING" | "APPROVED" | "HIDDEN";
+
+/**
+ * Customer review of a completed order
+ */
+export interface Review {
+  id: string;
+  orderId: string;
+  restaurantId: string;
+  authorId: string; // Telegram user ID
+  authorName?: string;
+  rating: number; // 1-5
+  comment: string;
+  status: ReviewStatus;
+  createdAt: string;
+  moderatedAt: string | null;
+  moderatedBy: string | null;
+  reply: string | null;
+  repliedAt: string | null;
+  repliedBy: string | null;
+}
+
+/**
+ * Approved review ratings of a restaurant
+ */
+export interface RatingSummary {
+  average: number | null; // one decimal, null without reviews
+  count: number;
+}
+
+/**
+ * Where a search matched: the restaurant name, a cuisine tag label or its
+ * description
+ */
+export type SearchField = "NAME" | "TAG" | "DESCRIPTION";
+
+/**
+ * Matched text with the character ranges to highlight; descriptions are
+ * cut to a snippet around the first match
+ */
+export interface SearchHighlight {
+  field: SearchField;
+  text: string;
+  ranges: Array<{ start: number; length: number }>;
+}
+
+/**
+ * A restaurant search match, best first by score
+ */
+export interface RestaurantSearchResult {
+  restaurant: Restaurant;
+  score: number;
+  highlights: SearchHighlight[];
+}
+
+/**
+ * A review as customers see it, without author or moderation details
+ */
+export interface RestaurantReview {
+  id: string;
+  authorName?: string;
+  rating: number;
+  comment: string;
+  createdAt: string;
+  reply: string | null;
+  repliedAt: string | null;
+}
+
+export interface SubmitReviewInput {
+  orderId: string;
+  rating: number;
+  comment?: string;
+}
+
+export interface ModerateReviewInput {
+  reviewId: string;
+  action: "APPROVE" | "HIDE";
+}
+
+export interface ReplyToReviewInput {
+  reviewId: string;
+  reply: string;
+}
+
+// ============================================================
+// Order Issue Types
+// ============================================================
+
+export type OrderIssueCategory =
+  | "MISSING_ITEM"
+  | "WRONG_ITEM"
+  | "COLD_FOOD"
+  | "LATE"
+  | "QUALITY"
+  | "OTHER";
+
+export type OrderIssueStatus = "OPEN" | "RESOLVED";
+
+export type OrderIssueResolution = "REFUND" | "VOUCHER" | "NO_ACTION";
+
+/**
+ * Structured customer complaint about an order
+ */
+export interface OrderIssue {
+  id: string;
+  orderId: string;
+  orderNumber?: number;
+  restaurantId: string;
+  reporterId: string; // Telegram user ID
+  category: OrderIssueCategory;
+  details: string;
+  photos: string[]; // https URLs
+  status: OrderIssueStatus;
+  createdAt: string;
+  slaDueAt: string;
+  slaBreached: boolean; // computed on read
+  resolution: OrderIssueResolution | null;
+  resolutionNote: string | null;
+  refundAmount: number | null;
+  resolvedAt: string | null;
+  resolvedBy: string | null;
+  voucherCode: string | null; // set for VOUCHER resolutions
+}
+
+export interface ReportOrderIssueInput {
+  orderId: string;
+  category: OrderIssueCategory;
+  details?: string;
+  photos?: string[];
+}
+
+export interface ResolveOrderIssueInput {
+  issueId: string;
+  resolution: OrderIssueResolution;
+  note?: string;
+  refundAmount?: number;
+  voucherValue?: number; // VOUCHER only, defaults to COMPENSATION_VOUCHER_VALUE
+}
+
+// ============================================================
+// Abuse Review Types
+// ============================================================
+
+export type AbuseSignalType =
+  | "CANCELLED_ORDERS"
+  | "UNREALISTIC_COORDINATES"
+  | "RAPID_REPEAT_ORDERS";
+
+export type AbuseFlagStatus = "PENDING" | "APPROVED" | "REJECTED";
+
+export interface AbuseSignal {
+  type: AbuseSignalType;
+  detail: string;
+}
+
+/**
+ * Order flagged by abuse heuristics, awaiting or past admin review
+ */
+export interface AbuseFlag {
+  id: string;
+  orderId: string;
+  orderNumber?: number;
+  restaurantId: string;
+  userId: string; // Telegram user ID
+  signals: AbuseSignal[];
+  requiresApproval: boolean; // order can't be accepted until approved
+  status: AbuseFlagStatus;
+  createdAt: string;
+  reviewedAt: string | null;
+  reviewedBy: string | null;
+  note: string | null;
+}
+
+export interface ReviewAbuseFlagInput {
+  flagId: string;
+  decision: "APPROVE" | "REJECT";
+  note?: string;
+}
+
+// ============================================================
+// Telegram Payments Types
+// ============================================================
+
+/**
+ * Invoice link for paying an order inside Telegram
+ */
+export interface CreateInvoicePayload {
+  orderId: string;
+  invoiceUrl: string; // open with Telegram.WebApp.openInvoice
+  currency: string; // order currency, or XTR for Telegram Stars
+  amount: number; // smallest currency units
+}
+
+// ============================================================
+// Compensation Voucher Types
+// ============================================================
+
+/**
+ * Single-use Saleor voucher issued to compensate a customer
+ */
+export interface CompensationVoucher {
+  orderId: string;
+  code: string;
+  voucherId: string | null; // null for mock vouchers (Saleor not configured)
+  value: number;
+  currency: string;
+  reason: string;
+  issuedAt: string;
+  issuedBy: string; // Telegram user ID, or "system" for automation
+}
+
+export interface IssueCompensationVoucherInput {
+  orderId: string;
+  value?: number;
+  reason: string;
+}
+
+// ============================================================
+// Payment Status Types
+// ============================================================
+
+export type PaymentStatus = "PENDING" | "AUTHORIZED" | "PAID" | "FAILED" | "REFUNDED";
+
+/**
+ * Payment progress of an order, as shown by the Mini App
+ */
+export interface OrderPaymentStatus {
+  orderId: string;
+  status: PaymentStatus;
+  chargeStatus: string | null; // raw Saleor OrderChargeStatusEnum
+  amountCharged: number | null;
+  total: number;
+  currency: string;
+  lastEvent: string | null; // latest Saleor payment webhook event type
+  updatedAt: string | null; // when that webhook was received
+}
+
+// ============================================================
+// Payment Gateway Types
+// ============================================================
+
+export type PaymentMethodKind = "GATEWAY" | "TELEGRAM";
+
+/**
+ * Way to pay accepted by a restaurant
+ */
+export interface PaymentMethod {
+  id: string; // Saleor payment app ID, or "telegram"
+  name: string;
+  kind: PaymentMethodKind;
+  currencies: string[];
+}
+
+/**
+ * Result of starting a payment; the Mini App opens redirectUrl
+ */
+export interface InitPaymentPayload {
+  orderId: string;
+  method: string;
+  redirectUrl: string | null; // null when the gateway needs no redirect
+  transactionId: string | null; // Saleor transaction (gateway payments)
+}
+
+// ============================================================
+// Payment Hold Types
+// ============================================================
+
+export type PaymentHoldStatus = "NONE" | "PENDING" | "HELD" | "CAPTURED" | "VOIDED";
+
+/**
+ * Result of a restaurant accepting or rejecting an order
+ */
+export interface OrderDecisionPayload {
+  orderId: string;
+  accepted: boolean;
+  paymentHold: PaymentHoldStatus; // NONE when no payment was held
+}
+
+// ============================================================
+// Staff Invitation Types
+// ============================================================
+
+export type StaffRole = "COURIER" | "STAFF";
+
+/**
+ * Courier or staff member of a restaurant
+ */
+export interface StaffMember {
+  restaurantId: string;
+  telegramUserId: string;
+  role: StaffRole;
+  grantedAt: string; // ISO timestamp
+  grantedBy: string; // admin who created the invite
+}
+
+/**
+ * One-time invite; the Mini App opens link and calls acceptStaffInvite
+ */
+export interface StaffInvite {
+  token: string;
+  restaurantId: string;
+  role: StaffRole;
+  link: string | null; // null when TELEGRAM_MINI_APP_URL is not set
+  expiresAt: string; // ISO timestamp
+}
+
+export interface CreateStaffInviteInput {
+  restaurantId: string;
+  role: StaffRole;
+}
+
+export interface RevokeStaffRoleInput {
+  restaurantId: string;
+  telegramUserId: string;
+}
+
+// ============================================================
+// Health Hint Types
+// ============================================================
+
+/**
+ * Degraded-mode hints returned in the response `extensions.health` block
+ */
+export interface HealthHints {
+  saleorDegraded: boolean; // recent Saleor API failures (or SALEOR_DEGRADED)
+  servingStaleData: boolean; // fallback data served instead of live Saleor data
+  staleDataTypes: string[]; // e.g. channels, categories, dishes
+  paymentsDisabled: boolean; // no payment method, or PAYMENTS_DISABLED
 }
 
 // Phase 2: GraphQL Context Types
 // Context passed to all resolvers with authenticated user info
+/**
+ * Supported Mini App versions (clientVersion.ts)
+ */
+export interface ClientVersionStatus {
+  minVersion: string | null;
+  latestVersion: string | null;
+  clientVersion: string | null;
+  updateRequired: boolean;
+  updateAvailable: boolean;
+}
+
+export interface ClientFeatures {
+  loyalty: boolean;
+  onlinePayments: boolean;
+  tips: boolean;
+  demo: boolean; // SALEOR_MODE=demo: orders are simulated (demoMode.ts)
+  flags: string[]; // CLIENT_FEATURE_FLAGS, passed through for the frontend
+}
+
+export interface SupportContact {
+  telegram: string | null;
+  email: string | null;
+}
+
+export interface MapConfig {
+  provider: string;
+  apiKey: string | null;
+}
+
+/**
+ * App-wide banner for one (UTC) day
+ */
+export interface DailyBanner {
+  date: string; // YYYY-MM-DD
+  text: string;
+  imageUrl: string | null;
+  linkUrl: string | null;
+  updatedBy: string;
+  updatedAt: string;
+}
+
+export interface SetDailyBannerInput {
+  date: string;
+  text?: string | null; // empty clears the day's banner
+  imageUrl?: string | null;
+  linkUrl?: string | null;
+}
+
+/**
+ * Startup configuration for the Mini App (clientConfig query)
+ */
+export interface ClientConfig extends ClientVersionStatus {
+  features: ClientFeatures;
+  currencies: string[];
+  fulfillmentTypes: FulfillmentType[];
+  support: SupportContact | null;
+  map: MapConfig | null;
+  banner: DailyBanner | null;
+}
+
 export interface GraphQLContext {
   auth: AuthContext;
+  imageFormats?: ImageFormat[]; // thumbnail formats the client can display
+  clientVersion?: string; // X-TMA-Client-Version
+  deadline?: number; // epoch ms the current operation must finish by
 }
 
 // ============================================================
@@ -0,0 +1,40 @@
+// Currency Formatting Tests
+// Tests for currencyFormat.ts - locales from Telegram language codes
+
+import { describe, it, expect } from "vitest";
+import { formatMoney, localeFromLanguageCode, toMoney } from "./currencyFormat";
+
+describe("localeFromLanguageCode", () => {
+  it("should normalize Telegram language codes", () => {
+    expect(localeFromLanguageCode("de")).toBe("de");
+    expect(localeFromLanguageCode("pt-br")).toBe("pt-BR");
+    expect(localeFromLanguageCode("EN_us")).toBe("en-US");
+  });
+
+  it("should fall back to English", () => {
+    expect(localeFromLanguageCode(undefined)).toBe("en");
+    expect(localeFromLanguageCode("")).toBe("en");
+    expect(localeFromLanguageCode("not a locale")).toBe("en");
+  });
+});
+
+describe("formatMoney", () => {
+  it("should use the currency's symbol and minor units", () => {
+    expect(formatMoney(12.5, "EUR", "en")).toBe("€12.50");
+    expect(formatMoney(1200.4, "JPY", "en")).toBe("¥1,200");
+  });
+
+  it("should fall back for unknown currencies", () => {
+    expect(formatMoney(3, "XX", "en")).toBe("3.00 XX");
+  });
+});
+
+describe("toMoney", () => {
+  it("should format for the user's language", () => {
+    const money = toMoney(12.5, "EUR", "de");
+    expect(money.amount).toBe(12.5);
+    expect(money.currency).toBe("EUR");
+    // German puts the symbol after the amount (with a no-break space)
+    expect(money.displayAmount.replace(/\s/g, " ")).toBe("12,50 €");
+  });
+});
@@ -0,0 +1,69 @@
+// Currency Formatting
+// Locale-aware display strings for money amounts, so clients get
+// "€12.50" / "12,50 €" from the API instead of formatting prices
+// themselves. The locale comes from the Telegram user's language_code.
+
+import { Money } from "./contracts";
+import { getMinorUnits, normalizeCurrency, roundMoney } from "./money";
+
+const DEFAULT_LOCALE = "en";
+
+/**
+ * BCP 47 locale for a Telegram language_code ("pt-br" -> "pt-BR")
+ * Unknown or missing codes fall back to "en"
+ */
+export function localeFromLanguageCode(languageCode: string | undefined): string {
+  const [language, region] = (languageCode || "").trim().split(/[-_]/);
+  if (!language || !/^[a-zA-Z]{2,3}$/.test(language)) {
+    return DEFAULT_LOCALE;
+  }
+  const locale =
+    region && /^[a-zA-Z]{2}$/.test(region)
+      ? `${language.toLowerCase()}-${region.toUpperCase()}`
+      : language.toLowerCase();
+  try {
+    return Intl.NumberFormat.supportedLocalesOf(locale).length > 0
+      ? locale
+      : DEFAULT_LOCALE;
+  } catch {
+    return DEFAULT_LOCALE;
+  }
+}
+
+/**
+ * Format an amount for display, e.g. "€12.50" for EUR in "en"
+ * Falls back to "12.50 EUR" when Intl does not know the currency or locale
+ */
+export function formatMoney(
+  amount: number,
+  currency: string,
+  locale: string = DEFAULT_LOCALE,
+): string {
+  const units = getMinorUnits(currency);
+  const rounded = roundMoney(amount, currency);
+  try {
+    return new Intl.NumberFormat(locale, {
+      style: "currency",
+      currency: normalizeCurrency(currency),
+      minimumFractionDigits: units,
+      maximumFractionDigits: units,
+    }).format(rounded);
+  } catch {
+    return `${rounded.toFixed(units)} ${normalizeCurrency(currency)}`;
+  }
+}
+
+/**
+ * Money value with its display string for a Telegram language_code
+ */
+export function toMoney(
+  amount: number,
+  currency: string,
+  languageCode?: string,
+): Money {
+  return {
+    amount,
+    currency,
+    displayAmount: formatMoney(amount, currency, localeFromLanguageCode(languageCode)),
+  };
+}
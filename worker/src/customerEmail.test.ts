@@ -0,0 +1,72 @@
+// Customer Email Tests
+// Tests for customerEmail.ts - placeholder emails and linked Saleor customers
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { SaleorFetch, initializeSaleorClient } from "./saleorClient";
+import { buildPlaceholderEmail, resolveCustomerEmail } from "./customerEmail";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+// Only user 42 is linked to a Saleor customer
+const send = vi.fn<SaleorFetch>(async (_, init) => {
+  const { variables } = JSON.parse(String(init?.body));
+  const linked = variables.filter.metadata[0].value === "42";
+  return Response.json({
+    data: {
+      customers: { edges: linked ? [{ node: { email: "ada@example.com" } }] : [] },
+    },
+  });
+});
+
+afterEach(() => {
+  delete (globalThis as any).CUSTOMER_EMAIL_DOMAIN;
+  delete (globalThis as any).CUSTOMER_EMAIL_PATTERN;
+  delete (globalThis as any).USE_LINKED_CUSTOMER_EMAIL;
+});
+
+describe("buildPlaceholderEmail", () => {
+  it("should default to tg-<id>@tma.local", () => {
+    expect(buildPlaceholderEmail("42")).toBe("tg-42@tma.local");
+  });
+
+  it("should fill in CUSTOMER_EMAIL_PATTERN and CUSTOMER_EMAIL_DOMAIN", () => {
+    (globalThis as any).CUSTOMER_EMAIL_DOMAIN = "shop.test";
+    (globalThis as any).CUSTOMER_EMAIL_PATTERN = "telegram+{id}@{domain}";
+    expect(buildPlaceholderEmail("42")).toBe("telegram+42@shop.test");
+  });
+});
+
+describe("resolveCustomerEmail", () => {
+  beforeEach(() => {
+    send.mockClear();
+    (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+    initializeSaleorClient({ SALEOR_API_URL: "https://saleor.test/graphql/", SALEOR_TOKEN: "t" });
+  });
+
+  afterEach(() => {
+    initializeSaleorClient({});
+    delete (globalThis as any).SALEOR_TRANSPORT;
+  });
+
+  it("should use the placeholder without looking up customers by default", async () => {
+    expect(await resolveCustomerEmail("42")).toBe("tg-42@tma.local");
+    expect(send).not.toHaveBeenCalled();
+  });
+
+  it("should use the linked customer's email and cache it", async () => {
+    (globalThis as any).USE_LINKED_CUSTOMER_EMAIL = "true";
+    expect(await resolveCustomerEmail("42")).toBe("ada@example.com");
+    expect(await resolveCustomerEmail("42")).toBe("ada@example.com");
+    expect(send).toHaveBeenCalledTimes(1);
+  });
+
+  it("should cache users without a linked customer too", async () => {
+    (globalThis as any).USE_LINKED_CUSTOMER_EMAIL = "true";
+    expect(await resolveCustomerEmail("7")).toBe("tg-7@tma.local");
+    expect(await resolveCustomerEmail("7")).toBe("tg-7@tma.local");
+    expect(send).toHaveBeenCalledTimes(1);
+  });
+});
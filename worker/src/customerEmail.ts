@@ -0,0 +1,74 @@
+// Customer Emails for Saleor Orders
+// Telegram users have no email, but Saleor orders and checkouts need one.
+// A placeholder is built from CUSTOMER_EMAIL_PATTERN ({id}, {domain}) and
+// CUSTOMER_EMAIL_DOMAIN; with USE_LINKED_CUSTOMER_EMAIL, the real email of
+// a Saleor customer whose metadata links the Telegram user is used instead.
+
+import { getBooleanVar, getStringVar } from "./config";
+import { logger } from "./logger";
+import {
+  getSaleorClient,
+  isSaleorConfigured,
+  LINKED_CUSTOMER_QUERY,
+} from "./saleorClient";
+import { LinkedCustomerQuery, LinkedCustomerQueryVariables } from "./saleorOperations";
+import { readJSON, writeJSON } from "./storage";
+
+// Saleor customer metadata key holding the Telegram user ID
+export const CUSTOMER_TELEGRAM_METADATA_KEY = "tma.telegramUserId";
+
+const CACHE_PREFIX = "customer-email:";
+const CACHE_TTL_SECONDS = 60 * 60;
+
+/**
+ * Placeholder email for a Telegram user (default tg-<id>@tma.local)
+ */
+export function buildPlaceholderEmail(userId: string): string {
+  const domain = getStringVar("CUSTOMER_EMAIL_DOMAIN", "tma.local");
+  const pattern = getStringVar("CUSTOMER_EMAIL_PATTERN", "tg-{id}@{domain}");
+  return pattern.replace(/\{id\}/g, userId).replace(/\{domain\}/g, domain);
+}
+
+async function fetchLinkedCustomerEmail(userId: string): Promise<string | null> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    return null;
+  }
+  const variables: LinkedCustomerQueryVariables = {
+    filter: {
+      metadata: [{ key: CUSTOMER_TELEGRAM_METADATA_KEY, value: userId }],
+    },
+  };
+  const response = await client.execute<LinkedCustomerQuery>(LINKED_CUSTOMER_QUERY, variables);
+  if (response.errors && response.errors.length > 0) {
+    logger.warn("linked_customer_lookup_failed", {
+      userId,
+      error: response.errors.map((e) => e.message).join(", "),
+    });
+    return null;
+  }
+  return response.data?.customers?.edges?.[0]?.node?.email || null;
+}
+
+/**
+ * Email to put on a user's Saleor order or checkout
+ */
+export async function resolveCustomerEmail(userId: string): Promise<string> {
+  if (!getBooleanVar("USE_LINKED_CUSTOMER_EMAIL")) {
+    return buildPlaceholderEmail(userId);
+  }
+
+  // Cached as "" when the user has no linked customer
+  const cached = await readJSON<{ email: string }>(`${CACHE_PREFIX}${userId}`);
+  if (cached) {
+    return cached.email || buildPlaceholderEmail(userId);
+  }
+
+  const email = await fetchLinkedCustomerEmail(userId);
+  await writeJSON(
+    `${CACHE_PREFIX}${userId}`,
+    { email: email || "" },
+    { expirationTtl: CACHE_TTL_SECONDS },
+  );
+  return email || buildPlaceholderEmail(userId);
+}
@@ -0,0 +1,71 @@
+// Daily Banner
+// Superadmins schedule one app-wide banner per day (banner:<YYYY-MM-DD>,
+// UTC dates) that the Mini App shows above the restaurant list. It is part
+// of clientConfig, so the app gets it with its startup request. Banners
+// drop out of KV the day after they run.
+
+import { DailyBanner, SetDailyBannerInput } from "./contracts";
+import { badUserInputError } from "./errors";
+import { logger } from "./logger";
+import { isValidDateKey } from "./openingHours";
+import { deleteKey, readJSON, writeJSON } from "./storage";
+
+export const MAX_BANNER_LENGTH = 200;
+
+const BANNER_PREFIX = "banner:";
+const DAY_MS = 24 * 60 * 60 * 1000;
+const MIN_TTL_SECONDS = 60; // KV's minimum expiration
+
+function getKey(date: string): string {
+  return `${BANNER_PREFIX}${date}`;
+}
+
+/**
+ * Banner scheduled for the current UTC day, if any
+ */
+export async function getDailyBanner(now: Date = new Date()): Promise<DailyBanner | null> {
+  return readJSON<DailyBanner>(getKey(now.toISOString().slice(0, 10)));
+}
+
+/**
+ * Schedule, replace or clear (empty text) the banner for a day
+ */
+export async function setDailyBanner(
+  input: SetDailyBannerInput,
+  updatedBy: string,
+  now: Date = new Date(),
+): Promise<DailyBanner | null> {
+  if (!isValidDateKey(input.date)) {
+    throw badUserInputError("Date must be YYYY-MM-DD", "date");
+  }
+  if (input.date < now.toISOString().slice(0, 10)) {
+    throw badUserInputError("Date must be today or later", "date");
+  }
+
+  const text = (input.text || "").trim().replace(/\s+/g, " ");
+  if (!text) {
+    await deleteKey(getKey(input.date));
+    logger.info("daily_banner_cleared", { date: input.date, updatedBy });
+    return null;
+  }
+  if (text.length > MAX_BANNER_LENGTH) {
+    throw badUserInputError(`Banner must be at most ${MAX_BANNER_LENGTH} characters`, "text");
+  }
+
+  const banner: DailyBanner = {
+    date: input.date,
+    text,
+    imageUrl: input.imageUrl || null,
+    linkUrl: input.linkUrl || null,
+    updatedBy,
+    updatedAt: now.toISOString(),
+  };
+  // Kept until the end of the day after it runs
+  const expiresAt = Date.parse(`${input.date}T00:00:00Z`) + 2 * DAY_MS;
+  await writeJSON(getKey(input.date), banner, {
+    expirationTtl: Math.max(MIN_TTL_SECONDS, Math.ceil((expiresAt - now.getTime()) / 1000)),
+  });
+
+  logger.info("daily_banner_set", { date: input.date, updatedBy });
+  return banner;
+}
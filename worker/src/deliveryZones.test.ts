@@ -0,0 +1,123 @@
+// Delivery Zone Tests
+// Tests for deliveryZones.ts - placing users in zones and hiding other zones' restaurants
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { Channel } from "./contracts";
+import {
+  filterRestaurantsByZones,
+  findUserZones,
+  getDeliveryZones,
+  resolveUserPlace,
+} from "./deliveryZones";
+import { createMemoryStore, getStore, setStore } from "./store";
+import { buildChannel, buildRestaurant, buildSavedAddress } from "./testHelpers";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+const ZONES = [
+  {
+    id: "berlin",
+    name: "Berlin",
+    cities: ["Berlin"],
+    polygon: [
+      [52.68, 13.08],
+      [52.68, 13.76],
+      [52.34, 13.76],
+      [52.34, 13.08],
+    ],
+  },
+  {
+    id: "hamburg",
+    name: "Hamburg",
+    cities: ["Hamburg"],
+    polygon: [
+      [53.7, 9.7],
+      [53.7, 10.3],
+      [53.4, 10.3],
+      [53.4, 9.7],
+    ],
+  },
+];
+
+const alexanderplatz = { latitude: 52.5219, longitude: 13.4132 };
+
+function channel(id: string, zones?: string): Channel {
+  return buildChannel({ id, slug: id, name: id, metadata: zones ? { tma_zones: zones } : {} });
+}
+
+const channels = [
+  channel("berlin-only", "berlin"),
+  channel("hamburg-only", "Hamburg"),
+  channel("anywhere"),
+];
+const restaurants = channels.map((c) => buildRestaurant({ id: c.id, name: c.name }));
+
+beforeEach(() => {
+  (globalThis as any).DELIVERY_ZONES = JSON.stringify(ZONES);
+  setStore(createMemoryStore());
+});
+
+afterEach(() => {
+  delete (globalThis as any).DELIVERY_ZONES;
+  setStore(null);
+});
+
+describe("findUserZones", () => {
+  it("should place a point or a city in its zones", () => {
+    expect(getDeliveryZones().map((zone) => zone.id)).toEqual(["berlin", "hamburg"]);
+    expect(findUserZones({ point: alexanderplatz, city: null })).toEqual(["berlin"]);
+    expect(findUserZones({ point: null, city: " hamburg " })).toEqual(["hamburg"]);
+    // In no zone
+    const munich = { latitude: 48.14, longitude: 11.58 };
+    expect(findUserZones({ point: munich, city: null })).toEqual([]);
+  });
+
+  it("should not tell without zones or a place", () => {
+    expect(findUserZones(null)).toBeNull();
+    delete (globalThis as any).DELIVERY_ZONES;
+    expect(findUserZones({ point: alexanderplatz, city: null })).toBeNull();
+  });
+});
+
+describe("filterRestaurantsByZones", () => {
+  it("should hide restaurants that only serve other zones", () => {
+    const ids = (zones: string[] | null) =>
+      filterRestaurantsByZones(restaurants, channels, zones).map((r) => r.id);
+    expect(ids(["berlin"])).toEqual(["berlin-only", "anywhere"]);
+    expect(ids([])).toEqual(["anywhere"]);
+    expect(ids(null)).toEqual(["berlin-only", "hamburg-only", "anywhere"]);
+  });
+});
+
+describe("resolveUserPlace", () => {
+  it("should prefer the location, then the default saved address", async () => {
+    await getStore().addresses.put(
+      "user-1",
+      buildSavedAddress({
+        id: "work",
+        label: "Work",
+        location: { address: "1 Harbour Street", city: "Hamburg" },
+      }),
+    );
+    await getStore().addresses.put(
+      "user-1",
+      buildSavedAddress({
+        location: { address: "1 Market Square", latitude: 52.52, longitude: 13.41 },
+        isDefault: true,
+        createdAt: "2026-02-01T00:00:00.000Z",
+      }),
+    );
+
+    expect(await resolveUserPlace("user-1", { lat: 53.55, lng: 10 })).toEqual({
+      point: { latitude: 53.55, longitude: 10 },
+      city: null,
+    });
+    expect(await resolveUserPlace("user-1")).toEqual({
+      point: { latitude: 52.52, longitude: 13.41 },
+      city: null,
+    });
+    expect(await resolveUserPlace("user-2")).toBeNull();
+  });
+});
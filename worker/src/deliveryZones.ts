@@ -0,0 +1,202 @@
+// Delivery Zones
+// Cities or zones are drawn as polygons in DELIVERY_ZONES (JSON), e.g.
+// [{"id": "berlin", "name": "Berlin", "cities": ["Berlin"],
+//   "polygon": [[52.68, 13.08], [52.68, 13.76], [52.34, 13.76], [52.34, 13.08]]}]
+// with [lat, lng] corners. A restaurant that only serves some zones lists
+// them in its channel metadata as tma_zones ("berlin,potsdam"). The
+// restaurant list then only shows it to users inside one of them, placed
+// by the location argument or, without one, their default saved address
+// (its coordinates, else its city). Restaurants without tma_zones, and
+// everyone while no zones are configured or the user can't be placed,
+// are unaffected.
+
+import { Channel, DeliveryLocation, GeoPoint, Restaurant } from "./contracts";
+import { getVar } from "./config";
+import { logger } from "./logger";
+import { fetchChannels } from "./saleorService";
+import { getStore } from "./store";
+
+export const ZONES_METADATA_KEY = "tma_zones";
+
+/**
+ * A city or zone boundary
+ */
+export interface DeliveryZone {
+  id: string;
+  name: string;
+  polygon: Array<[number, number]>; // [lat, lng] corners
+  cities: string[]; // lowercase city names placing addresses without coordinates
+}
+
+/**
+ * Where a user is, as far as it is known
+ */
+export interface UserPlace {
+  point: { latitude: number; longitude: number } | null;
+  city: string | null;
+}
+
+let cachedSource: string | undefined;
+let cachedZones: DeliveryZone[] = [];
+
+function isCorner(value: unknown): value is [number, number] {
+  return (
+    Array.isArray(value) &&
+    value.length === 2 &&
+    Math.abs(value[0]) <= 90 &&
+    Math.abs(value[1]) <= 180
+  );
+}
+
+/**
+ * Zones from DELIVERY_ZONES; invalid entries are skipped
+ */
+export function getDeliveryZones(): DeliveryZone[] {
+  const source = getVar("DELIVERY_ZONES");
+  if (source === cachedSource) {
+    return cachedZones;
+  }
+  cachedSource = source;
+  cachedZones = [];
+  if (!source) {
+    return cachedZones;
+  }
+  try {
+    const parsed = JSON.parse(source);
+    for (const entry of Array.isArray(parsed) ? parsed : []) {
+      const id = typeof entry?.id === "string" ? entry.id.trim().toLowerCase() : "";
+      const polygon = Array.isArray(entry?.polygon) ? entry.polygon : [];
+      if (!id || polygon.length < 3 || !polygon.every(isCorner)) {
+        logger.warn("delivery_zone_invalid", { zone: id || null });
+        continue;
+      }
+      cachedZones.push({
+        id,
+        name: typeof entry.name === "string" ? entry.name : id,
+        polygon,
+        cities: (Array.isArray(entry.cities) ? entry.cities : [])
+          .filter((city: unknown): city is string => typeof city === "string")
+          .map((city: string) => city.trim().toLowerCase()),
+      });
+    }
+  } catch {
+    logger.warn("delivery_zone_invalid", { reason: "DELIVERY_ZONES is not valid JSON" });
+  }
+  return cachedZones;
+}
+
+/**
+ * Zones a restaurant serves (tma_zones), empty when it isn't restricted
+ */
+export function getRestaurantZones(metadata: Record<string, string> | undefined): string[] {
+  return (metadata?.[ZONES_METADATA_KEY] || "")
+    .split(",")
+    .map((zone) => zone.trim().toLowerCase())
+    .filter(Boolean);
+}
+
+/**
+ * Ray casting; points on an edge may fall either way
+ */
+export function isInPolygon(
+  point: { latitude: number; longitude: number },
+  polygon: Array<[number, number]>,
+): boolean {
+  let inside = false;
+  for (let i = 0, j = polygon.length - 1; i < polygon.length; j = i++) {
+    const [latI, lngI] = polygon[i];
+    const [latJ, lngJ] = polygon[j];
+    if (
+      lngI > point.longitude !== lngJ > point.longitude &&
+      point.latitude < ((latJ - latI) * (point.longitude - lngI)) / (lngJ - lngI) + latI
+    ) {
+      inside = !inside;
+    }
+  }
+  return inside;
+}
+
+/**
+ * IDs of the zones a user is in, or null when that can't be told (no
+ * zones configured, or nothing known about where the user is)
+ */
+export function findUserZones(
+  place: UserPlace | null,
+  zones: DeliveryZone[] = getDeliveryZones(),
+): string[] | null {
+  if (zones.length === 0 || !place || (!place.point && !place.city)) {
+    return null;
+  }
+  if (place.point) {
+    const point = place.point;
+    return zones.filter((zone) => isInPolygon(point, zone.polygon)).map((zone) => zone.id);
+  }
+  const city = place.city!.trim().toLowerCase();
+  return zones.filter((zone) => zone.cities.includes(city)).map((zone) => zone.id);
+}
+
+/**
+ * Restaurants visible to a user in the given zones
+ */
+export function filterRestaurantsByZones(
+  restaurants: Restaurant[],
+  channels: Channel[],
+  userZones: string[] | null,
+): Restaurant[] {
+  if (userZones === null) {
+    return restaurants;
+  }
+  const served = new Map(
+    channels.map((channel) => [channel.id, getRestaurantZones(channel.metadata)]),
+  );
+  return restaurants.filter((restaurant) => {
+    const zones = served.get(restaurant.id) || [];
+    return zones.length === 0 || zones.some((zone) => userZones.includes(zone));
+  });
+}
+
+function toPlace(location: DeliveryLocation): UserPlace {
+  const { latitude, longitude } = location;
+  return {
+    point:
+      typeof latitude === "number" && typeof longitude === "number"
+        ? { latitude, longitude }
+        : null,
+    city: location.city?.trim() || null,
+  };
+}
+
+/**
+ * The user's place: the location argument, else their default saved
+ * address (marked default, or the first saved)
+ */
+export async function resolveUserPlace(
+  userId: string | undefined,
+  location?: GeoPoint | null,
+): Promise<UserPlace | null> {
+  if (location) {
+    return { point: { latitude: location.lat, longitude: location.lng }, city: null };
+  }
+  if (!userId || getDeliveryZones().length === 0) {
+    return null;
+  }
+  const addresses = await getStore().addresses.list(userId);
+  const saved = addresses.find((address) => address.isDefault) ?? addresses[0];
+  return saved ? toPlace(saved.location) : null;
+}
+
+/**
+ * Leave out restaurants that don't serve the user's zone; channels are
+ * only fetched when the user can be placed in the configured zones
+ */
+export async function applyZoneVisibility(
+  restaurants: Restaurant[],
+  userId: string | undefined,
+  location?: GeoPoint | null,
+): Promise<Restaurant[]> {
+  const userZones = findUserZones(await resolveUserPlace(userId, location));
+  if (userZones === null) {
+    return restaurants;
+  }
+  return filterRestaurantsByZones(restaurants, await fetchChannels(), userZones);
+}
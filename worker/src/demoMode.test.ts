@@ -0,0 +1,44 @@
+// Demo Mode Tests
+// Tests for demoMode.ts - simulated orders without Saleor
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { PlaceOrderInput } from "./contracts";
+import { placeDemoOrder } from "./demoMode";
+import { getSaleorClient, isSaleorConfigured } from "./saleorClient";
+import { clearOrders, getAllOrders } from "./saleorOrder";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+afterEach(() => {
+  delete (globalThis as any).SALEOR_MODE;
+  delete (globalThis as any).SALEOR_API_URL;
+  delete (globalThis as any).SALEOR_TOKEN;
+});
+
+describe("demo mode", () => {
+  it("should keep Saleor switched off even when configured", () => {
+    (globalThis as any).SALEOR_MODE = "demo";
+    (globalThis as any).SALEOR_API_URL = "https://saleor.example.com/graphql/";
+    (globalThis as any).SALEOR_TOKEN = "token";
+    expect(isSaleorConfigured()).toBe(false);
+    expect(getSaleorClient()).toBeNull();
+  });
+
+  it("should answer with a simulated order that is never stored", () => {
+    clearOrders();
+    const input: PlaceOrderInput = {
+      restaurantId: "channelA",
+      deliveryLocation: { address: "1 Demo Street" },
+      items: [{ dishId: "dishA1", quantity: 2 }],
+      paymentMethod: "CASH",
+    };
+    const payload = placeDemoOrder(input, "demo-user");
+    expect(payload.orderId).toMatch(/^demo:/);
+    expect(payload.status).toBe("DEMO");
+    expect(payload.paymentMethod).toBe("CASH");
+    expect(payload.totals?.grandTotal).toBe(20);
+    expect(getAllOrders()).toEqual([]);
+  });
+});
@@ -0,0 +1,36 @@
+// Demo Mode
+// SALEOR_MODE=demo runs a deployment for sales demos and app store review:
+// like SALEOR_MODE=mock the Saleor client is switched off, so browsing uses
+// the fixture (or seeded mock) catalog even when SALEOR_API_URL is set, but
+// placeOrder runs its usual checks and answers with a simulated order
+// (status DEMO) instead of creating one. Nothing is stored, charged or sent
+// to the restaurant; the cart is cleared as after a real order. clientConfig
+// reports it so the Mini App can show a demo banner.
+
+import { PlaceOrderInput, PlaceOrderPayload } from "./contracts";
+import { logger } from "./logger";
+import { getSaleorMode } from "./saleorFixtures";
+import { buildMockOrder, toPlaceOrderPayload } from "./saleorOrder";
+
+export function isDemoMode(): boolean {
+  return getSaleorMode() === "demo";
+}
+
+/**
+ * Payload for a simulated order; the order itself is never kept
+ */
+export function placeDemoOrder(
+  input: PlaceOrderInput,
+  userId: string,
+  userLanguage?: string,
+): PlaceOrderPayload {
+  const orderId = `demo:${crypto.randomUUID()}`;
+  const order = buildMockOrder(input, userId, orderId, 0, "DEMO", userLanguage);
+  logger.info("demo_order_simulated", {
+    orderId,
+    userId,
+    restaurantId: input.restaurantId,
+    itemCount: input.items.length,
+  });
+  return toPlaceOrderPayload(order);
+}
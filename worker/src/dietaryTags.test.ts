@@ -0,0 +1,66 @@
+// Dietary Tag Tests
+// Tests for dietaryTags.ts - tma_dietary / tma_allergens parsing and filtering,
+// tma_calories
+
+import { describe, it, expect } from "vitest";
+import {
+  getAllergens,
+  getDietaryTags,
+  getDishCalories,
+  matchesDietaryFilter,
+  normalizeTag,
+} from "./dietaryTags";
+
+describe("normalizeTag", () => {
+  it("should lowercase and kebab-case tags", () => {
+    expect(normalizeTag(" Gluten Free ")).toBe("gluten-free");
+    expect(normalizeTag("gluten_free")).toBe("gluten-free");
+    expect(normalizeTag("Vegan")).toBe("vegan");
+  });
+});
+
+describe("getDietaryTags / getAllergens", () => {
+  it("should parse comma separated and JSON lists", () => {
+    expect(getDietaryTags({ tma_dietary: "Vegan, gluten free" })).toEqual([
+      "vegan",
+      "gluten-free",
+    ]);
+    expect(getAllergens({ tma_allergens: '["Nuts", "milk", "nuts"]' })).toEqual([
+      "nuts",
+      "milk",
+    ]);
+  });
+
+  it("should return no tags without metadata", () => {
+    expect(getDietaryTags(undefined)).toEqual([]);
+    expect(getAllergens({ tma_allergens: "" })).toEqual([]);
+  });
+});
+
+describe("matchesDietaryFilter", () => {
+  const dish = { dietaryTags: ["vegan", "gluten-free"] };
+
+  it("should require every filter tag", () => {
+    expect(matchesDietaryFilter(dish, ["Vegan"])).toBe(true);
+    expect(matchesDietaryFilter(dish, ["vegan", "Gluten Free"])).toBe(true);
+    expect(matchesDietaryFilter(dish, ["vegan", "halal"])).toBe(false);
+  });
+
+  it("should match everything without a filter", () => {
+    expect(matchesDietaryFilter(dish, undefined)).toBe(true);
+    expect(matchesDietaryFilter({ dietaryTags: [] }, [])).toBe(true);
+  });
+});
+
+describe("getDishCalories", () => {
+  it("should read kcal per serving from product metadata", () => {
+    expect(getDishCalories({ tma_calories: "640" })).toBe(640);
+    expect(getDishCalories({ tma_calories: "512.6" })).toBe(513);
+  });
+
+  it("should be null when unset or invalid", () => {
+    expect(getDishCalories(undefined)).toBeNull();
+    expect(getDishCalories({ tma_calories: "lots" })).toBeNull();
+    expect(getDishCalories({ tma_calories: "-10" })).toBeNull();
+  });
+});
@@ -0,0 +1,52 @@
+// Dietary and Allergen Tags
+// Dishes carry tma_dietary (vegan, halal, gluten-free, ...) and
+// tma_allergens (nuts, milk, ...) product metadata, as a JSON array or a
+// comma separated string. Tags are normalised to lowercase kebab-case so
+// "Gluten Free", "gluten_free" and "gluten-free" match the same filter.
+// tma_calories holds the energy per serving in kcal.
+
+import { parseListValue, parseNumberValue } from "./metadata";
+
+export const DIETARY_METADATA_KEY = "tma_dietary";
+export const ALLERGENS_METADATA_KEY = "tma_allergens";
+export const CALORIES_METADATA_KEY = "tma_calories";
+
+/**
+ * Lowercase kebab-case form of a tag
+ */
+export function normalizeTag(tag: string): string {
+  return tag
+    .trim()
+    .toLowerCase()
+    .replace(/[\s_]+/g, "-");
+}
+
+function parseTags(value: string | undefined): string[] {
+  return Array.from(new Set(parseListValue(value).map(normalizeTag).filter(Boolean)));
+}
+
+export function getDietaryTags(metadata: Record<string, string> | undefined): string[] {
+  return parseTags(metadata?.[DIETARY_METADATA_KEY]);
+}
+
+export function getAllergens(metadata: Record<string, string> | undefined): string[] {
+  return parseTags(metadata?.[ALLERGENS_METADATA_KEY]);
+}
+
+/**
+ * Calories per serving (kcal, rounded) from product metadata, null when not set
+ */
+export function getDishCalories(metadata: Record<string, string> | undefined): number | null {
+  const calories = parseNumberValue(metadata?.[CALORIES_METADATA_KEY]);
+  return calories !== null && calories >= 0 ? Math.round(calories) : null;
+}
+
+/**
+ * Whether a dish has every requested dietary tag (an empty filter matches all)
+ */
+export function matchesDietaryFilter(
+  dish: { dietaryTags: string[] },
+  filter: string[] | null | undefined,
+): boolean {
+  return (filter || []).every((tag) => dish.dietaryTags.includes(normalizeTag(tag)));
+}
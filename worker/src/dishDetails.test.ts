@@ -0,0 +1,83 @@
+// Dish Details Tests
+// Tests for dishDetails.ts - variant mapping, availability and "from" price
+
+import { describe, it, expect } from "vitest";
+import { SaleorDishProduct, fetchDishDetail, toDishDetail } from "./dishDetails";
+
+const listing = {
+  channel: { id: "ch1" },
+  isPublished: true,
+  visibleInListings: true,
+  isAvailableForPurchase: true,
+};
+
+function variant(id: string, amount: number, extra: Record<string, unknown> = {}) {
+  return {
+    id,
+    name: id,
+    quantityAvailable: null,
+    channelListings: [{ channel: { id: "ch1" } }],
+    attributes: [{ attribute: { name: "Size" }, values: [{ name: id }] }],
+    pricing: {
+      price: {
+        gross: { amount: String(amount), currency: "EUR" },
+        net: { amount: String(amount - 1), currency: "EUR" },
+      },
+    },
+    ...extra,
+  };
+}
+
+const pizza: SaleorDishProduct = {
+  id: "prod1",
+  name: "Pizza",
+  description: null,
+  thumbnail: null,
+  productType: { id: "cat1" },
+  channelListings: [listing],
+  variants: [
+    variant("35cm", 14),
+    variant("25cm", 10, { quantityAvailable: 0 }),
+    variant("30cm", 12),
+  ],
+};
+
+describe("toDishDetail", () => {
+  it("should return every variant with its options", () => {
+    const dish = toDishDetail(pizza, "ch1", "GROSS")!;
+    expect(dish.variants.map((v) => v.id)).toEqual(["35cm", "25cm", "30cm"]);
+    expect(dish.variants[0].options).toEqual([{ name: "Size", value: "35cm" }]);
+    expect(dish.variants[0].price).toBe(14);
+  });
+
+  it("should price the dish from the cheapest available variant", () => {
+    const dish = toDishDetail(pizza, "ch1", "GROSS")!;
+    expect(dish.variants[1].available).toBe(false);
+    expect(dish.price).toBe(12);
+    expect(dish.available).toBe(true);
+    expect(toDishDetail(pizza, "ch1", "NET")!.price).toBe(11);
+  });
+
+  it("should mark variants unavailable when the product can't be bought", () => {
+    const dish = toDishDetail(
+      { ...pizza, channelListings: [{ ...listing, isAvailableForPurchase: false }] },
+      "ch1",
+      "GROSS",
+    )!;
+    expect(dish.available).toBe(false);
+    expect(dish.variants.every((v) => !v.available)).toBe(true);
+  });
+
+  it("should hide dishes not listed in the channel", () => {
+    expect(toDishDetail(pizza, "ch2", "GROSS")).toBeNull();
+  });
+});
+
+describe("fetchDishDetail", () => {
+  it("should return mock dishes as a single variant without Saleor", async () => {
+    const dish = await fetchDishDetail("dishA1", "restA");
+    expect(dish?.variants).toHaveLength(1);
+    expect(dish?.variants[0].id).toBe("dishA1");
+    expect(await fetchDishDetail("missing", "restA")).toBeNull();
+  });
+});
@@ -0,0 +1,213 @@
+// Dish Details
+// The dish(productId) query: one product with every variant (sizes and
+// options such as "Pizza 25cm / 35cm"), priced for the restaurant's channel.
+// Menu queries collapse a product to its first variant; here each variant is
+// returned with its selection attributes and availability, and its id is
+// what cart items and placeOrder take as dishId.
+
+import { DishDetail, DishVariant, ImageFormat, PriceDisplay } from "./contracts";
+import { checkChannelListing, isSoldOut, ProductChannelListing } from "./availability";
+import { internalError } from "./errors";
+import { getDishPrepMinutes } from "./eta";
+import { getThumbnailSize } from "./imageFormat";
+import { logger } from "./logger";
+import { metadataToRecord, MetadataItem } from "./metadata";
+import { getAllergens, getDietaryTags, getDishCalories } from "./dietaryTags";
+import { renderDescription } from "./richText";
+import { SaleorProductMedia, getGallerySize, toImages } from "./productMedia";
+import {
+  getSaleorClient,
+  isSaleorConfigured,
+  DISH_DETAIL_QUERY,
+} from "./saleorClient";
+import { DishDetailQuery, DishDetailQueryVariables } from "./saleorOperations";
+import { fetchChannelById } from "./saleorService";
+import { TEST_DISHES } from "./testHelpers";
+
+interface SaleorMoney {
+  amount: string | number;
+  currency: string;
+}
+
+export interface SaleorDishVariant {
+  id: string;
+  name: string;
+  quantityAvailable?: number | null;
+  channelListings?: Array<{ channel: { id: string } }> | null;
+  attributes?: Array<{
+    attribute: { name: string | null };
+    values: Array<{ name: string | null }>;
+  }>;
+  pricing?: { price?: { gross: SaleorMoney; net?: SaleorMoney | null } | null } | null;
+}
+
+export interface SaleorDishProduct {
+  id: string;
+  name: string;
+  description: string | null;
+  thumbnail: { url: string } | null;
+  media?: SaleorProductMedia[] | null;
+  metadata?: MetadataItem[];
+  productType: { id: string };
+  channelListings?: ProductChannelListing[] | null;
+  variants: SaleorDishVariant[] | null;
+}
+
+/**
+ * Variant as sold in a channel
+ * Available when the product can be bought there, the variant is listed in
+ * the channel and, when stock is tracked, some is left
+ */
+export function toDishVariant(
+  variant: SaleorDishVariant,
+  channelId: string,
+  priceDisplay: PriceDisplay,
+  productPurchasable: boolean,
+): DishVariant {
+  const price =
+    priceDisplay === "NET" && variant.pricing?.price?.net
+      ? variant.pricing.price.net
+      : variant.pricing?.price?.gross;
+  const listed = (variant.channelListings || []).some(
+    (listing) => listing.channel?.id === channelId,
+  );
+  const quantityAvailable = variant.quantityAvailable ?? null;
+
+  return {
+    id: variant.id,
+    name: variant.name,
+    price: Number(price?.amount) || 0,
+    currency: price?.currency || "USD",
+    available: productPurchasable && listed && !!price && !isSoldOut(quantityAvailable),
+    quantityAvailable,
+    options: (variant.attributes || [])
+      .filter((attribute) => attribute.values.length > 0)
+      .map((attribute) => ({
+        name: attribute.attribute.name || "",
+        value: attribute.values.map((value) => value.name || "").join(", "),
+      })),
+  };
+}
+
+/**
+ * Dish detail for a product in a restaurant's channel
+ * The dish price is the lowest available variant price ("from" price)
+ */
+export function toDishDetail(
+  product: SaleorDishProduct,
+  restaurantId: string,
+  priceDisplay: PriceDisplay,
+  now: Date = new Date(),
+): DishDetail | null {
+  if (checkChannelListing(product.channelListings, restaurantId, "LISTING", now)) {
+    return null;
+  }
+  const purchasable =
+    checkChannelListing(product.channelListings, restaurantId, "PURCHASE", now) === null;
+  const variants = (product.variants || []).map((variant) =>
+    toDishVariant(variant, restaurantId, priceDisplay, purchasable),
+  );
+  const available = variants.filter((variant) => variant.available);
+  const from = (available.length > 0 ? available : variants).reduce<DishVariant | null>(
+    (cheapest, variant) =>
+      !cheapest || variant.price < cheapest.price ? variant : cheapest,
+    null,
+  );
+
+  const metadata = metadataToRecord(product.metadata);
+
+  return {
+    id: product.id,
+    name: product.name,
+    ...renderDescription(product.description),
+    price: from?.price ?? 0,
+    currency: from?.currency ?? "USD",
+    categoryId: product.productType.id,
+    imageUrl: product.thumbnail?.url || "",
+    restaurantId,
+    taxIncluded: priceDisplay === "GROSS",
+    prepMinutes: getDishPrepMinutes(metadata),
+    calories: getDishCalories(metadata),
+    available: available.length > 0,
+    soldOut:
+      variants.length > 0 &&
+      variants.every((variant) => isSoldOut(variant.quantityAvailable)),
+    dietaryTags: getDietaryTags(metadata),
+    allergens: getAllergens(metadata),
+    images: toImages(product.media),
+    variants,
+  };
+}
+
+function getMockDishDetail(productId: string, restaurantId: string): DishDetail | null {
+  const dish = Object.values(TEST_DISHES).find((d) => d.id === productId);
+  if (!dish) {
+    return null;
+  }
+  return {
+    id: dish.id,
+    name: dish.name,
+    description: "Test description",
+    descriptionHtml: "Test description",
+    price: dish.price,
+    currency: "USD",
+    categoryId: dish.categoryId,
+    imageUrl: "https://example.com/image.jpg",
+    restaurantId,
+    available: true,
+    soldOut: false,
+    dietaryTags: [],
+    allergens: [],
+    images: [],
+    variants: [
+      {
+        id: dish.id,
+        name: dish.name,
+        price: dish.price,
+        currency: "USD",
+        available: true,
+        quantityAvailable: null,
+        options: [],
+      },
+    ],
+  };
+}
+
+/**
+ * Fetch a dish with all its variants, or null when the restaurant doesn't list it
+ */
+export async function fetchDishDetail(
+  productId: string,
+  restaurantId: string,
+  priceDisplay: PriceDisplay = "GROSS",
+  imageFormat: ImageFormat = "ORIGINAL",
+): Promise<DishDetail | null> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    return getMockDishDetail(productId, restaurantId);
+  }
+
+  const channel = await fetchChannelById(restaurantId);
+  if (!channel) {
+    return null;
+  }
+  const variables: DishDetailQueryVariables = {
+    id: productId,
+    channel: channel.slug,
+    thumbnailSize: getThumbnailSize(),
+    mediaSize: getGallerySize(),
+    thumbnailFormat: imageFormat,
+  };
+  const response = await client.execute<DishDetailQuery>(DISH_DETAIL_QUERY, variables);
+  if (response.errors && response.errors.length > 0) {
+    logger.error("saleor_service_error", {
+      error: response.errors.map((e) => e.message).join(", "),
+      dataType: "dish",
+      productId,
+    });
+    throw internalError("dish_detail_failed", "Could not load the dish, please try again");
+  }
+
+  const product = response.data?.product;
+  return product ? toDishDetail(product, restaurantId, priceDisplay) : null;
+}
@@ -0,0 +1,71 @@
+// Dish Sorting Tests
+// Tests for dishPopularity.ts - sort options, Saleor ProductOrder mapping
+// and the tma_popularity counter
+
+import { describe, it, expect, vi } from "vitest";
+import { getDishPopularity, sortDishes, toProductOrder } from "./dishPopularity";
+import { fetchDishes } from "./saleorService";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+const dishes = [
+  { id: "d1", name: "Pepperoni", price: 11 },
+  { id: "d2", name: "Calzone", price: 12.5 },
+  { id: "d3", name: "Margherita", price: 9.5 },
+];
+
+describe("toProductOrder", () => {
+  it("should map price sorts with the channel slug", () => {
+    expect(toProductOrder("PRICE_ASC", "rest-a")).toEqual({
+      field: "PRICE",
+      direction: "ASC",
+      channel: "rest-a",
+    });
+    expect(toProductOrder("PRICE_DESC", "rest-a")?.direction).toBe("DESC");
+    expect(toProductOrder("NAME")).toEqual({ field: "NAME", direction: "ASC" });
+  });
+
+  it("should leave sorts Saleor can't apply to the caller", () => {
+    expect(toProductOrder("POPULARITY", "rest-a")).toBeNull();
+    expect(toProductOrder("PRICE_ASC")).toBeNull();
+    expect(toProductOrder(undefined)).toBeNull();
+  });
+});
+
+describe("sortDishes", () => {
+  it("should sort by price and name", () => {
+    expect(sortDishes(dishes, "PRICE_ASC").map((d) => d.id)).toEqual(["d3", "d1", "d2"]);
+    expect(sortDishes(dishes, "PRICE_DESC").map((d) => d.id)).toEqual(["d2", "d1", "d3"]);
+    expect(sortDishes(dishes, "NAME").map((d) => d.id)).toEqual(["d2", "d3", "d1"]);
+  });
+
+  it("should sort by popularity, keeping ties in order", () => {
+    const popularity = new Map([["d3", 40], ["d1", 7]]);
+    expect(sortDishes(dishes, "POPULARITY", popularity).map((d) => d.id)).toEqual([
+      "d3",
+      "d1",
+      "d2",
+    ]);
+  });
+
+  it("should keep the fetched order without a sort", () => {
+    expect(sortDishes(dishes, undefined)).toBe(dishes);
+  });
+});
+
+describe("getDishPopularity", () => {
+  it("should read the counter and ignore bad values", () => {
+    expect(getDishPopularity({ tma_popularity: "12" })).toBe(12);
+    expect(getDishPopularity({ tma_popularity: "lots" })).toBe(0);
+    expect(getDishPopularity(undefined)).toBe(0);
+  });
+});
+
+describe("fetchDishes sortBy", () => {
+  it("should sort mock menus", async () => {
+    const result = await fetchDishes("catA", "restA", undefined, "GROSS", "ORIGINAL", "PRICE_DESC");
+    expect(result.map((d) => d.name)).toEqual(["Pepperoni Pizza", "Margherita Pizza"]);
+  });
+});
@@ -0,0 +1,157 @@
+// Dish Sorting and Popularity
+// categoryDishes(sortBy) maps PRICE_ASC / PRICE_DESC / NAME onto Saleor's
+// ProductOrder. Saleor can't sort by metadata, so POPULARITY reads each
+// product's tma_popularity counter and sorts the fetched menu here; the
+// counter is raised by the quantity ordered whenever Saleor reports an
+// order_fulfilled webhook (once per order, tma.popularityCounted).
+
+import { DishSortBy } from "./contracts";
+import { logger } from "./logger";
+import { metadataToRecord, parseNumberValue } from "./metadata";
+import {
+  getSaleorClient,
+  isSaleorConfigured,
+  PRODUCTS_METADATA_QUERY,
+  UPDATE_METADATA_MUTATION,
+} from "./saleorClient";
+import {
+  ProductsMetadataQuery,
+  ProductsMetadataQueryVariables,
+  UpdateMetadataMutation,
+  UpdateMetadataMutationVariables,
+} from "./saleorOperations";
+import { ORDER_METADATA_KEYS, fetchOrderById, updateOrderMetadata } from "./saleorOrder";
+
+export const POPULARITY_METADATA_KEY = "tma_popularity";
+
+export const DISH_SORT_OPTIONS: DishSortBy[] = ["PRICE_ASC", "PRICE_DESC", "NAME", "POPULARITY"];
+
+/**
+ * Saleor ProductOrder input
+ */
+export interface SaleorProductOrder {
+  field: "PRICE" | "NAME";
+  direction: "ASC" | "DESC";
+  channel?: string; // PRICE needs the channel slug
+}
+
+/**
+ * Saleor sort for a dish sort option; null when Saleor can't sort it
+ * (POPULARITY, or PRICE without a channel) and it's sorted after fetching
+ */
+export function toProductOrder(
+  sortBy: DishSortBy | undefined,
+  channelSlug?: string,
+): SaleorProductOrder | null {
+  switch (sortBy) {
+    case "PRICE_ASC":
+      return channelSlug ? { field: "PRICE", direction: "ASC", channel: channelSlug } : null;
+    case "PRICE_DESC":
+      return channelSlug ? { field: "PRICE", direction: "DESC", channel: channelSlug } : null;
+    case "NAME":
+      return { field: "NAME", direction: "ASC" };
+    default:
+      return null;
+  }
+}
+
+/**
+ * Popularity counter from product metadata (0 when unset)
+ */
+export function getDishPopularity(metadata: Record<string, string> | undefined): number {
+  const value = parseNumberValue(metadata?.[POPULARITY_METADATA_KEY]);
+  return value !== null && value > 0 ? value : 0;
+}
+
+/**
+ * Sort dishes in memory (mock menus, and sorts Saleor couldn't apply)
+ * Ties keep their fetched order
+ */
+export function sortDishes<T extends { id: string; name: string; price: number }>(
+  dishes: T[],
+  sortBy: DishSortBy | undefined,
+  popularity: Map<string, number> = new Map(),
+): T[] {
+  const sorted = [...dishes];
+  switch (sortBy) {
+    case "PRICE_ASC":
+      return sorted.sort((a, b) => a.price - b.price);
+    case "PRICE_DESC":
+      return sorted.sort((a, b) => b.price - a.price);
+    case "NAME":
+      return sorted.sort((a, b) => a.name.localeCompare(b.name));
+    case "POPULARITY":
+      return sorted.sort(
+        (a, b) => (popularity.get(b.id) || 0) - (popularity.get(a.id) || 0),
+      );
+    default:
+      return dishes;
+  }
+}
+
+/**
+ * Add a fulfilled order's quantities to its products' tma_popularity
+ * Returns the number of products updated; redelivered webhooks are no-ops
+ */
+export async function recordOrderPopularity(orderId: string): Promise<number> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  const order = client ? await fetchOrderById(orderId) : null;
+  if (!client || !order || order.metadata?.[ORDER_METADATA_KEYS.popularityCounted]) {
+    return 0;
+  }
+
+  const quantities = new Map<string, number>();
+  for (const line of order.lines) {
+    if (line.productId) {
+      quantities.set(line.productId, (quantities.get(line.productId) || 0) + line.quantity);
+    }
+  }
+  if (quantities.size === 0) {
+    return 0;
+  }
+
+  const variables: ProductsMetadataQueryVariables = {
+    ids: Array.from(quantities.keys()),
+    first: quantities.size,
+  };
+  const response = await client.execute<ProductsMetadataQuery>(PRODUCTS_METADATA_QUERY, variables);
+  if (response.errors && response.errors.length > 0) {
+    throw new Error(response.errors.map((e) => e.message).join(", "));
+  }
+
+  // Read-modify-write: Saleor has no metadata increment, and webhooks for
+  // one product rarely overlap, so an occasional lost count is accepted
+  let updated = 0;
+  for (const edge of response.data?.products?.edges || []) {
+    const current = getDishPopularity(metadataToRecord(edge.node.metadata));
+    const update: UpdateMetadataMutationVariables = {
+      id: edge.node.id,
+      input: [
+        {
+          key: POPULARITY_METADATA_KEY,
+          value: String(current + (quantities.get(edge.node.id) || 0)),
+        },
+      ],
+    };
+    const result = await client.execute<UpdateMetadataMutation>(UPDATE_METADATA_MUTATION, update);
+    const errors = [
+      ...(result.errors || []).map((e) => e.message),
+      ...(result.data?.updateMetadata?.errors || []).map((e) => e.message),
+    ];
+    if (errors.length > 0) {
+      logger.error("dish_popularity_update_failed", {
+        productId: edge.node.id,
+        orderId,
+        error: errors.join(", "),
+      });
+      continue;
+    }
+    updated++;
+  }
+
+  await updateOrderMetadata(orderId, {
+    [ORDER_METADATA_KEYS.popularityCounted]: new Date().toISOString(),
+  });
+  logger.info("dish_popularity_recorded", { orderId, products: updated });
+  return updated;
+}
@@ -7,13 +7,53 @@ export enum ErrorCode {
   BAD_USER_INPUT = "BAD_USER_INPUT",
   NOT_FOUND = "NOT_FOUND",
   RATE_LIMITED = "RATE_LIMITED",
+  TIMEOUT = "TIMEOUT",
+  ITEMS_UNAVAILABLE = "ITEMS_UNAVAILABLE",
+  MIXED_RESTAURANTS = "MIXED_RESTAURANTS",
+  AT_CAPACITY = "AT_CAPACITY",
+  RESTAURANT_PAUSED = "RESTAURANT_PAUSED",
+  TOO_MANY_ACTIVE_ORDERS = "TOO_MANY_ACTIVE_ORDERS",
+  UPDATE_REQUIRED = "UPDATE_REQUIRED",
+  INSUFFICIENT_STOCK = "INSUFFICIENT_STOCK",
   INTERNAL_ERROR = "INTERNAL_ERROR",
 }
 
+/**
+ * One invalid input field (path relative to the mutation input)
+ */
+export interface FieldError {
+  field: string;
+  message: string;
+}
+
+/**
+ * Dish that can't be ordered, with the reason (availability.ts) and, for
+ * OTHER_RESTAURANT, the restaurant it belongs to
+ */
+export interface UnavailableItem {
+  dishId: string;
+  reason: string;
+  restaurantId?: string;
+}
+
+/**
+ * Saleor error behind an AppError (saleorErrors.ts); Saleor's message is
+ * only logged
+ */
+export interface UpstreamError {
+  code: string | null;
+  field: string | null;
+  path: string[] | null;
+}
+
 export interface GraphQLErrorInput {
   message: string;
   code: ErrorCode;
   field?: string;
+  fieldErrors?: FieldError[];
+  unavailableItems?: UnavailableItem[];
+  retryAfterMinutes?: number;
+  saleorErrors?: UpstreamError[];
   internalId?: string;
 }
 
@@ -24,6 +64,10 @@ export class AppError extends Error {
     public readonly statusCode: number,
     public readonly field?: string,
     public readonly internalId?: string,
+    public readonly fieldErrors?: FieldError[],
+    public readonly unavailableItems?: UnavailableItem[],
+    public readonly retryAfterMinutes?: number,
+    public readonly saleorErrors?: UpstreamError[],
   ) {
     super(message);
     this.name = "AppError";
@@ -34,6 +78,10 @@ export class AppError extends Error {
       message: this.message,
       code: this.code,
       field: this.field,
+      ...(this.fieldErrors ? { fieldErrors: this.fieldErrors } : {}),
+      ...(this.unavailableItems ? { unavailableItems: this.unavailableItems } : {}),
+      ...(this.retryAfterMinutes ? { retryAfterMinutes: this.retryAfterMinutes } : {}),
+      ...(this.saleorErrors ? { saleorErrors: this.saleorErrors } : {}),
       internalId: this.internalId,
     };
   }
@@ -56,12 +104,143 @@ export function badUserInputError(message: string, field?: string): AppError {
   return new AppError(message, ErrorCode.BAD_USER_INPUT, 400, field);
 }
 
+/**
+ * BAD_USER_INPUT listing every invalid field; the first one is also
+ * reported as message/field for clients that only read those
+ */
+export function validationError(fieldErrors: FieldError[]): AppError {
+  const [first] = fieldErrors;
+  return new AppError(
+    first.message,
+    ErrorCode.BAD_USER_INPUT,
+    400,
+    first.field,
+    undefined,
+    fieldErrors,
+  );
+}
+
+/**
+ * ITEMS_UNAVAILABLE listing every dish that can't be ordered, so the Mini
+ * App can mark them in the cart
+ */
+export function itemsUnavailableError(
+  message: string,
+  items: UnavailableItem[],
+  field: string = "items",
+): AppError {
+  return new AppError(
+    message,
+    ErrorCode.ITEMS_UNAVAILABLE,
+    409,
+    field,
+    undefined,
+    undefined,
+    items,
+  );
+}
+
+/**
+ * MIXED_RESTAURANTS when an order has dishes from another restaurant;
+ * unavailableItems lists them with the restaurant each belongs to, so the
+ * Mini App can offer to remove them or start a new cart
+ */
+export function mixedRestaurantsError(items: UnavailableItem[], field: string = "items"): AppError {
+  const conflict =
+    items.length === 1
+      ? `Dish ${items[0].dishId} is from another restaurant`
+      : `${items.length} dishes are from another restaurant: ${items
+          .map((item) => item.dishId)
+          .join(", ")}`;
+  return new AppError(
+    `${conflict}. An order can only have dishes from one restaurant.`,
+    ErrorCode.MIXED_RESTAURANTS,
+    409,
+    field,
+    undefined,
+    undefined,
+    items,
+  );
+}
+
+/**
+ * AT_CAPACITY when a restaurant's kitchen has its maximum of active orders
+ * (kitchenCapacity.ts)
+ */
+export function atCapacityError(retryAfterMinutes: number): AppError {
+  return new AppError(
+    `The kitchen is at capacity, please try again in ${retryAfterMinutes} minutes.`,
+    ErrorCode.AT_CAPACITY,
+    503,
+    "restaurantId",
+    undefined,
+    undefined,
+    undefined,
+    retryAfterMinutes,
+  );
+}
+
+/**
+ * RESTAURANT_PAUSED when the owner has paused taking orders
+ * (restaurantPause.ts)
+ */
+export function restaurantPausedError(): AppError {
+  return new AppError(
+    "The restaurant is temporarily not accepting orders. Please try again later.",
+    ErrorCode.RESTAURANT_PAUSED,
+    409,
+    "restaurantId",
+  );
+}
+
+/**
+ * TOO_MANY_ACTIVE_ORDERS when a user or delivery address already has the
+ * maximum of orders in progress (orderLimits.ts)
+ */
+export function tooManyActiveOrdersError(message: string, field?: string): AppError {
+  return new AppError(message, ErrorCode.TOO_MANY_ACTIVE_ORDERS, 409, field);
+}
+
+/**
+ * RATE_LIMITED when a user has made too many failed attempts (giftCards.ts)
+ */
+export function rateLimitedError(message: string, retryAfterMinutes: number): AppError {
+  return new AppError(
+    message,
+    ErrorCode.RATE_LIMITED,
+    429,
+    undefined,
+    undefined,
+    undefined,
+    undefined,
+    retryAfterMinutes,
+  );
+}
+
+/**
+ * UPDATE_REQUIRED for Mini App builds older than MIN_CLIENT_VERSION
+ * (clientVersion.ts)
+ */
+export function updateRequiredError(minVersion: string): AppError {
+  return new AppError(
+    `This version of the app is no longer supported. Please update to ${minVersion} or later.`,
+    ErrorCode.UPDATE_REQUIRED,
+    426,
+  );
+}
+
 export function notFoundError(
   message: string = "The requested item was not found.",
 ): AppError {
   return new AppError(message, ErrorCode.NOT_FOUND, 404);
 }
 
+export function timeoutError(
+  message: string = "This is taking too long. Please try again.",
+): AppError {
+  return new AppError(message, ErrorCode.TIMEOUT, 504);
+}
+
 export function internalError(
   internalId: string,
   publicMessage: string = "Something went wrong. Please try again.",
@@ -0,0 +1,68 @@
+// Order ETA Tests
+// Tests for eta.ts - restaurant and dish prep times
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { SaleorFetch, initializeSaleorClient } from "./saleorClient";
+import { estimateDeliveryAt, getDishPrepMinutes, getPrepMinutes } from "./eta";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+afterEach(() => {
+  initializeSaleorClient({});
+  delete (globalThis as any).SALEOR_TRANSPORT;
+});
+
+describe("prep minutes", () => {
+  it("should read dish prep time from product metadata", () => {
+    expect(getDishPrepMinutes({ tma_prep_minutes: "40" })).toBe(40);
+    expect(getDishPrepMinutes({ tma_prep_minutes: "-5" })).toBeNull();
+    expect(getDishPrepMinutes(undefined)).toBeNull();
+  });
+
+  it("should fall back to the default restaurant prep time", () => {
+    expect(getPrepMinutes({ tma_prep_minutes: "25" })).toBe(25);
+    expect(getPrepMinutes({})).toBe(20);
+  });
+});
+
+describe("estimateDeliveryAt", () => {
+  it("should use scheduledFor for scheduled orders", async () => {
+    const scheduledFor = "2026-05-01T18:00:00.000Z";
+    expect(await estimateDeliveryAt("restA", "DELIVERY", scheduledFor)).toBe(scheduledFor);
+  });
+
+  it("should look up only the ordered dishes, by variant or product ID", async () => {
+    const metadata = (minutes: string) => [{ key: "tma_prep_minutes", value: minutes }];
+    const send = vi.fn<SaleorFetch>(async (_, init) => {
+      const { operationName } = JSON.parse(String(init?.body));
+      if (operationName !== "DishPrepMinutes") {
+        return Response.json({ errors: [{ message: "Unavailable" }] });
+      }
+      return Response.json({
+        data: {
+          productVariants: {
+            edges: [{ node: { id: "variant-1", product: { id: "p1", metadata: metadata("45") } } }],
+          },
+          products: { edges: [{ node: { id: "p2", metadata: metadata("30") } }] },
+        },
+      });
+    });
+    (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+    initializeSaleorClient({ SALEOR_API_URL: "https://saleor.test/graphql/", SALEOR_TOKEN: "t" });
+
+    const now = new Date("2026-05-01T12:00:00.000Z");
+    // 45 minutes for the slowest dish + 15 minutes delivery buffer
+    expect(
+      await estimateDeliveryAt("restA", "DELIVERY", undefined, now, ["variant-1", "p2"]),
+    ).toBe("2026-05-01T13:00:00.000Z");
+
+    const lookups = send.mock.calls
+      .map(([, init]) => JSON.parse(String(init?.body)))
+      .filter((body) => body.operationName === "DishPrepMinutes");
+    expect(lookups).toHaveLength(1);
+    expect(lookups[0].variables.ids).toEqual(["variant-1", "p2"]);
+  });
+});
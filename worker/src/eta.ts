@@ -0,0 +1,119 @@
+// Order ETA Estimation
+// estimatedDeliveryAt = order time + preparation time + delivery buffer.
+// Preparation time is the restaurant's tma_prep_minutes (channel metadata)
+// or the slowest ordered dish's tma_prep_minutes (product metadata),
+// whichever is longer; only the ordered dishes are looked up. Scheduled
+// orders are due at their scheduledFor time.
+
+import { FulfillmentType } from "./contracts";
+import { getNumberVar } from "./config";
+import { logger } from "./logger";
+import { metadataToRecord, parseNumberValue } from "./metadata";
+import {
+  DISH_PREP_MINUTES_QUERY,
+  getSaleorClient,
+  isSaleorConfigured,
+} from "./saleorClient";
+import { DishPrepMinutesQuery, DishPrepMinutesQueryVariables } from "./saleorOperations";
+import { fetchChannelById, fetchDishes } from "./saleorService";
+
+export const PREP_MINUTES_METADATA_KEY = "tma_prep_minutes";
+
+/**
+ * Prep time used when a restaurant has no tma_prep_minutes (DEFAULT_PREP_MINUTES)
+ */
+export function getDefaultPrepMinutes(): number {
+  return Math.max(0, getNumberVar("DEFAULT_PREP_MINUTES", 20));
+}
+
+/**
+ * Courier time added on top of prep time for deliveries (DELIVERY_BUFFER_MINUTES)
+ */
+export function getDeliveryBufferMinutes(): number {
+  return Math.max(0, getNumberVar("DELIVERY_BUFFER_MINUTES", 15));
+}
+
+/**
+ * Restaurant prep time in minutes from channel metadata
+ */
+export function getPrepMinutes(metadata: Record<string, string> | undefined): number {
+  const minutes = parseNumberValue(metadata?.[PREP_MINUTES_METADATA_KEY]);
+  return minutes !== null && minutes >= 0 ? minutes : getDefaultPrepMinutes();
+}
+
+/**
+ * Dish prep time in minutes from product metadata, null when not set
+ */
+export function getDishPrepMinutes(
+  metadata: Record<string, string> | undefined,
+): number | null {
+  const minutes = parseNumberValue(metadata?.[PREP_MINUTES_METADATA_KEY]);
+  return minutes !== null && minutes >= 0 ? minutes : null;
+}
+
+/**
+ * Longest prep time among the ordered dishes (0 when none is set)
+ * Order items name a variant or, for single-variant dishes, the product
+ */
+async function getSlowestDishPrepMinutes(
+  restaurantId: string,
+  dishIds: string[],
+): Promise<number> {
+  const ids = Array.from(new Set(dishIds.filter(Boolean)));
+  if (ids.length === 0) {
+    return 0;
+  }
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    const dishes = await fetchDishes(undefined, restaurantId);
+    return dishes
+      .filter(
+        (dish) =>
+          ids.includes(dish.id) ||
+          (dish.variantIds || []).some((variantId) => ids.includes(variantId)),
+      )
+      .reduce((max, dish) => Math.max(max, dish.prepMinutes ?? 0), 0);
+  }
+
+  const variables: DishPrepMinutesQueryVariables = { ids };
+  const response = await client.execute<DishPrepMinutesQuery>(DISH_PREP_MINUTES_QUERY, variables);
+  if (response.errors && response.errors.length > 0) {
+    // The estimate falls back to the restaurant's prep time
+    logger.warn("dish_prep_minutes_lookup_failed", {
+      error: response.errors.map((e) => e.message).join(", "),
+      restaurantId,
+    });
+    return 0;
+  }
+  const metadata = [
+    ...(response.data?.productVariants?.edges || []).map((e) => e.node.product?.metadata),
+    ...(response.data?.products?.edges || []).map((e) => e.node.metadata),
+  ];
+  return metadata.reduce(
+    (max, items) => Math.max(max, getDishPrepMinutes(metadataToRecord(items || [])) ?? 0),
+    0,
+  );
+}
+
+/**
+ * Estimate when an order will be delivered (or ready, for pickup)
+ */
+export async function estimateDeliveryAt(
+  restaurantId: string,
+  fulfillmentType: FulfillmentType = "DELIVERY",
+  scheduledFor?: string,
+  now: Date = new Date(),
+  dishIds: string[] = [],
+): Promise<string> {
+  if (scheduledFor) {
+    return new Date(scheduledFor).toISOString();
+  }
+  const channel = await fetchChannelById(restaurantId);
+  const prepMinutes = Math.max(
+    getPrepMinutes(channel?.metadata),
+    await getSlowestDishPrepMinutes(restaurantId, dishIds),
+  );
+  const buffer = fulfillmentType === "PICKUP" ? 0 : getDeliveryBufferMinutes();
+  const minutes = prepMinutes + buffer;
+  return new Date(now.getTime() + minutes * 60 * 1000).toISOString();
+}
@@ -0,0 +1,69 @@
+// Favorites Tests
+// Tests for favorites.ts - saved restaurants and dishes per user
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { addFavorite, listFavorites, removeFavorite, resolveFavorites } from "./favorites";
+import { createMemoryStore, setStore } from "./store";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+beforeEach(() => {
+  setStore(createMemoryStore());
+});
+
+afterEach(() => {
+  setStore(null);
+});
+
+describe("favorites", () => {
+  it("should save restaurants and dishes once, newest first", async () => {
+    const first = await addFavorite("user-1", { kind: "RESTAURANT", targetId: "channelA" });
+    await addFavorite("user-1", { kind: "DISH", targetId: "dishA1", restaurantId: "channelA" });
+    const again = await addFavorite("user-1", { kind: "RESTAURANT", targetId: "channelA" });
+    expect(again).toEqual(first);
+
+    const favorites = await listFavorites("user-1");
+    expect(favorites.map((f) => f.targetId)).toEqual(["dishA1", "channelA"]);
+    expect((await listFavorites("user-1", "DISH"))[0].restaurantId).toBe("channelA");
+    expect(await listFavorites("user-2")).toEqual([]);
+  });
+
+  it("should reject unknown targets and dishes without a restaurant", async () => {
+    await expect(
+      addFavorite("user-1", { kind: "RESTAURANT", targetId: "missing" }),
+    ).rejects.toThrow(/Restaurant not found/);
+    await expect(addFavorite("user-1", { kind: "DISH", targetId: "dishA1" })).rejects.toThrow(
+      /Restaurant is required/,
+    );
+    await expect(
+      addFavorite("user-1", { kind: "DISH", targetId: "missing", restaurantId: "channelA" }),
+    ).rejects.toThrow(/Dish not found/);
+  });
+
+  it("should remove favorites", async () => {
+    await addFavorite("user-1", { kind: "RESTAURANT", targetId: "channelA" });
+    expect(await removeFavorite("user-1", "RESTAURANT", "channelA")).toBe(true);
+    expect(await removeFavorite("user-1", "RESTAURANT", "channelA")).toBe(false);
+  });
+
+  it("should resolve favorites and leave out ones no longer listed", async () => {
+    const entries = await resolveFavorites([
+      { kind: "RESTAURANT", targetId: "channelA", createdAt: "2026-03-01T00:00:00.000Z" },
+      { kind: "RESTAURANT", targetId: "gone", createdAt: "2026-03-01T00:00:00.000Z" },
+      {
+        kind: "DISH",
+        targetId: "dishA1",
+        restaurantId: "channelA",
+        createdAt: "2026-03-01T00:00:00.000Z",
+      },
+    ]);
+    expect(entries.map((e) => [e.targetId, e.restaurant?.id ?? null, e.dish?.id ?? null])).toEqual(
+      [
+        ["channelA", "channelA", null],
+        ["dishA1", null, "dishA1"],
+      ],
+    );
+  });
+});
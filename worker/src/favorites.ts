@@ -0,0 +1,155 @@
+// Favorites
+// Users save restaurants and dishes for the home screen's Favorites shelf.
+// Favorites live in the favorite store (store.ts) keyed by Telegram user
+// ID. A dish favorite remembers its restaurant, since dish prices and
+// availability are per channel. Saved items that are no longer listed are
+// left off the shelf but kept, so they return if the restaurant relists
+// them.
+
+import {
+  Dish,
+  Favorite,
+  FavoriteEntry,
+  FavoriteInput,
+  FavoriteKind,
+  Restaurant,
+} from "./contracts";
+import { fetchDishDetail } from "./dishDetails";
+import { badUserInputError, notFoundError } from "./errors";
+import { logger } from "./logger";
+import {
+  fetchChannelById,
+  fetchDishes,
+  fetchRestaurants,
+  getSaleorFanoutLimit,
+} from "./saleorService";
+import { getStore } from "./store";
+import { resolveMenuPriceDisplay } from "./taxes";
+import { mapConcurrent } from "./utils";
+
+export const MAX_FAVORITES = 200;
+
+const FAVORITE_KINDS: FavoriteKind[] = ["RESTAURANT", "DISH"];
+
+function requireKind(kind: FavoriteKind): FavoriteKind {
+  if (!FAVORITE_KINDS.includes(kind)) {
+    throw badUserInputError("Kind must be RESTAURANT or DISH", "kind");
+  }
+  return kind;
+}
+
+/**
+ * Save a restaurant or dish; saving it again keeps the original
+ */
+export async function addFavorite(userId: string, input: FavoriteInput): Promise<Favorite> {
+  const kind = requireKind(input.kind);
+  const targetId = (input.targetId || "").trim();
+  if (!targetId) {
+    throw badUserInputError("Target is required", "targetId");
+  }
+  const existing = (await getStore().favorites.list(userId, kind)).find(
+    (favorite) => favorite.targetId === targetId,
+  );
+  if (existing) {
+    return existing;
+  }
+  if ((await getStore().favorites.list(userId)).length >= MAX_FAVORITES) {
+    throw badUserInputError(`At most ${MAX_FAVORITES} favorites can be saved`, "targetId");
+  }
+
+  let favorite: Favorite;
+  if (kind === "RESTAURANT") {
+    if (!(await fetchChannelById(targetId))) {
+      throw notFoundError("Restaurant not found");
+    }
+    favorite = { kind, targetId, createdAt: new Date().toISOString() };
+  } else {
+    const restaurantId = (input.restaurantId || "").trim();
+    if (!restaurantId) {
+      throw badUserInputError("Restaurant is required for a dish", "restaurantId");
+    }
+    if (!(await fetchDishDetail(targetId, restaurantId))) {
+      throw notFoundError("Dish not found");
+    }
+    favorite = { kind, targetId, restaurantId, createdAt: new Date().toISOString() };
+  }
+
+  await getStore().favorites.add(userId, favorite);
+  logger.info("favorite_added", { userId, kind, targetId });
+  return favorite;
+}
+
+/**
+ * Forget a saved restaurant or dish
+ * Returns false when it wasn't saved
+ */
+export async function removeFavorite(
+  userId: string,
+  kind: FavoriteKind,
+  targetId: string,
+): Promise<boolean> {
+  const removed = await getStore().favorites.remove(userId, requireKind(kind), targetId);
+  if (removed) {
+    logger.info("favorite_removed", { userId, kind, targetId });
+  }
+  return removed;
+}
+
+/**
+ * A user's favorites, most recently saved first
+ */
+export async function listFavorites(userId: string, kind?: FavoriteKind): Promise<Favorite[]> {
+  const favorites = await getStore().favorites.list(
+    userId,
+    kind ? requireKind(kind) : undefined,
+  );
+  return favorites.reverse();
+}
+
+/**
+ * Favorites with their current restaurant or dish; ones no longer listed
+ * are left out
+ */
+export async function resolveFavorites(favorites: Favorite[]): Promise<FavoriteEntry[]> {
+  const restaurants = new Map<string, Restaurant>();
+  if (favorites.some((favorite) => favorite.kind === "RESTAURANT")) {
+    for (const restaurant of await fetchRestaurants()) {
+      restaurants.set(restaurant.id, restaurant);
+    }
+  }
+
+  // One menu read per restaurant with favorite dishes, a few at a time
+  const dishes = new Map<string, Dish>();
+  const dishRestaurants = new Set(
+    favorites
+      .filter((favorite) => favorite.kind === "DISH" && favorite.restaurantId)
+      .map((favorite) => favorite.restaurantId!),
+  );
+  const menus = await mapConcurrent(
+    [...dishRestaurants],
+    getSaleorFanoutLimit(),
+    async (restaurantId) =>
+      fetchDishes(undefined, restaurantId, undefined, await resolveMenuPriceDisplay(restaurantId)),
+  );
+  [...dishRestaurants].forEach((restaurantId, index) => {
+    for (const dish of menus[index]) {
+      dishes.set(`${restaurantId}:${dish.id}`, dish);
+    }
+  });
+
+  const entries: FavoriteEntry[] = [];
+  for (const favorite of favorites) {
+    if (favorite.kind === "RESTAURANT") {
+      const restaurant = restaurants.get(favorite.targetId);
+      if (restaurant) {
+        entries.push({ ...favorite, restaurant, dish: null });
+      }
+    } else {
+      const dish = dishes.get(`${favorite.restaurantId}:${favorite.targetId}`);
+      if (dish) {
+        entries.push({ ...favorite, restaurant: null, dish });
+      }
+    }
+  }
+  return entries;
+}
@@ -0,0 +1,29 @@
+// Featured Dishes Tests
+// Tests for featuredDishes.ts - featured collection metadata
+
+import { describe, it, expect, vi } from "vitest";
+import { getFeaturedCollectionSlug } from "./featuredDishes";
+import { fetchFeaturedDishes } from "./saleorService";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+describe("getFeaturedCollectionSlug", () => {
+  it("should read the collection slug from channel metadata", () => {
+    expect(getFeaturedCollectionSlug({ tma_featured_collection: " Chef-Specials " })).toBe(
+      "chef-specials",
+    );
+  });
+
+  it("should be null when not set", () => {
+    expect(getFeaturedCollectionSlug(undefined)).toBeNull();
+    expect(getFeaturedCollectionSlug({ tma_featured_collection: "  " })).toBeNull();
+  });
+});
+
+describe("fetchFeaturedDishes", () => {
+  it("should feature nothing without Saleor", async () => {
+    expect(await fetchFeaturedDishes("restA")).toEqual([]);
+  });
+});
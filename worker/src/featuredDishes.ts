@@ -0,0 +1,20 @@
+// Featured Dishes
+// Restaurants pin specials to the top of the Mini App by putting them in a
+// Saleor collection and naming its slug in the tma_featured_collection
+// channel metadata. featuredDishes returns the collection's dishes in the
+// order arranged in the dashboard; restaurants without one feature nothing.
+
+export const FEATURED_COLLECTION_METADATA_KEY = "tma_featured_collection";
+
+// Featured is a short strip above the menu, not a second menu
+export const MAX_FEATURED_DISHES = 20;
+
+/**
+ * Featured collection slug from channel metadata, null when not set
+ */
+export function getFeaturedCollectionSlug(
+  metadata: Record<string, string> | undefined,
+): string | null {
+  const slug = metadata?.[FEATURED_COLLECTION_METADATA_KEY]?.trim().toLowerCase();
+  return slug || null;
+}
@@ -0,0 +1,78 @@
+// Geo Tests
+// Tests for geo.ts - restaurant distance and delivery radius
+
+import { describe, it, expect, vi } from "vitest";
+import { Channel } from "./contracts";
+import {
+  fetchRestaurantsNear,
+  getDeliveryRadiusKm,
+  locateRestaurants,
+  parseCoordinates,
+} from "./geo";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+function channel(id: string, metadata: Record<string, string>): Channel {
+  return {
+    id,
+    slug: id,
+    name: id,
+    isActive: true,
+    currencyCode: "EUR",
+    metadata,
+    categories: [],
+    deliveryLocations: [],
+  };
+}
+
+// Berlin Alexanderplatz
+const user = { lat: 52.5219, lng: 13.4132 };
+
+describe("parseCoordinates", () => {
+  it("should parse a lat,lng pair", () => {
+    expect(parseCoordinates("52.5, 13.4")).toEqual({ latitude: 52.5, longitude: 13.4 });
+    expect(parseCoordinates("91,0")).toBeNull();
+    expect(parseCoordinates("52.5")).toBeNull();
+  });
+});
+
+describe("getDeliveryRadiusKm", () => {
+  it("should read a positive radius", () => {
+    expect(getDeliveryRadiusKm({ tma_delivery_radius_km: "5" })).toBe(5);
+    expect(getDeliveryRadiusKm({ tma_delivery_radius_km: "0" })).toBeNull();
+    expect(getDeliveryRadiusKm(undefined)).toBeNull();
+  });
+});
+
+describe("locateRestaurants", () => {
+  it("should add distances and drop restaurants out of range", () => {
+    const restaurants = locateRestaurants(
+      [
+        // Brandenburg Gate, about 2.5 km away
+        channel("near", { tma_location: "52.5163,13.3777", tma_delivery_radius_km: "5" }),
+        channel("far", { tma_location: "52.5163,13.3777", tma_delivery_radius_km: "1" }),
+        channel("unlimited", { tma_location: "48.1351,11.582" }),
+        channel("unknown", {}),
+      ],
+      user,
+    );
+    expect(restaurants.map((r) => r.id)).toEqual(["near", "unlimited", "unknown"]);
+    expect(restaurants[0].distanceKm).toBeCloseTo(2.5, 0);
+    expect(restaurants[1].distanceKm).toBeGreaterThan(500);
+    expect(restaurants[2].distanceKm).toBeNull();
+  });
+
+  it("should reject an invalid location", () => {
+    expect(() => locateRestaurants([], { lat: 100, lng: 0 })).toThrow(/valid latitude/);
+  });
+});
+
+describe("fetchRestaurantsNear", () => {
+  it("should list mock restaurants without coordinates", async () => {
+    const restaurants = await fetchRestaurantsNear(user);
+    expect(restaurants.length).toBeGreaterThan(0);
+    expect(restaurants.every((r) => r.distanceKm === null)).toBe(true);
+  });
+});
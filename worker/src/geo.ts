@@ -0,0 +1,109 @@
+// Geo
+// Restaurant coordinates live in channel metadata as tma_location
+// ("lat,lng") and the area a restaurant delivers to as
+// tma_delivery_radius_km. Given the user's location, the restaurant list
+// gets a distanceKm per restaurant and drops those whose radius doesn't
+// reach the user. Restaurants without coordinates stay listed, without a
+// distance, since there is nothing to measure.
+
+import { Channel, GeoPoint, Restaurant } from "./contracts";
+import { badUserInputError } from "./errors";
+import { parseNumberValue } from "./metadata";
+import { fetchChannels, toRestaurant } from "./saleorService";
+
+export const LOCATION_METADATA_KEY = "tma_location"; // "lat,lng"
+export const DELIVERY_RADIUS_METADATA_KEY = "tma_delivery_radius_km";
+
+const EARTH_RADIUS_KM = 6371;
+
+/**
+ * Parse a "lat,lng" pair; null when missing or out of range
+ */
+export function parseCoordinates(
+  value: string | undefined,
+): { latitude: number; longitude: number } | null {
+  const parts = (value || "").split(",").map((part) => part.trim());
+  if (parts.length !== 2 || parts.some((part) => part === "")) {
+    return null;
+  }
+  const [latitude, longitude] = parts.map(Number);
+  if (!(Math.abs(latitude) <= 90) || !(Math.abs(longitude) <= 180)) {
+    return null;
+  }
+  return { latitude, longitude };
+}
+
+/**
+ * Great-circle distance in kilometres
+ */
+export function distanceKm(
+  a: { latitude: number; longitude: number },
+  b: { latitude: number; longitude: number },
+): number {
+  const toRad = (deg: number) => (deg * Math.PI) / 180;
+  const dLat = toRad(b.latitude - a.latitude);
+  const dLng = toRad(b.longitude - a.longitude);
+  const h =
+    Math.sin(dLat / 2) ** 2 +
+    Math.cos(toRad(a.latitude)) * Math.cos(toRad(b.latitude)) * Math.sin(dLng / 2) ** 2;
+  return 2 * EARTH_RADIUS_KM * Math.asin(Math.sqrt(h));
+}
+
+/**
+ * Delivery radius from channel metadata, null when unlimited
+ */
+export function getDeliveryRadiusKm(metadata: Record<string, string> | undefined): number | null {
+  const radius = parseNumberValue(metadata?.[DELIVERY_RADIUS_METADATA_KEY]);
+  return radius !== null && radius > 0 ? radius : null;
+}
+
+/**
+ * Validate a location argument
+ */
+export function toCoordinates(point: GeoPoint): { latitude: number; longitude: number } {
+  if (
+    typeof point?.lat !== "number" ||
+    typeof point?.lng !== "number" ||
+    !(Math.abs(point.lat) <= 90) ||
+    !(Math.abs(point.lng) <= 180)
+  ) {
+    throw badUserInputError("Location must be a valid latitude and longitude", "location");
+  }
+  return { latitude: point.lat, longitude: point.lng };
+}
+
+/**
+ * Restaurants that deliver to a point, with their distance from it
+ */
+export function locateRestaurants(
+  channels: Channel[],
+  point: GeoPoint,
+  now: Date = new Date(),
+): Restaurant[] {
+  const user = toCoordinates(point);
+  const restaurants: Restaurant[] = [];
+  for (const channel of channels) {
+    const restaurant = toRestaurant(channel, now);
+    const location = parseCoordinates(channel.metadata?.[LOCATION_METADATA_KEY]);
+    if (!location) {
+      restaurants.push({ ...restaurant, distanceKm: null });
+      continue;
+    }
+    const km = distanceKm(location, user);
+    const radius = getDeliveryRadiusKm(channel.metadata);
+    if (radius !== null && km > radius) {
+      continue;
+    }
+    restaurants.push({ ...restaurant, distanceKm: Math.round(km * 10) / 10 });
+  }
+  return restaurants;
+}
+
+/**
+ * Restaurant list for a user's location
+ */
+export async function fetchRestaurantsNear(point: GeoPoint): Promise<Restaurant[]> {
+  // Bad input fails before Saleor is queried
+  toCoordinates(point);
+  return locateRestaurants(await fetchChannels(), point);
+}
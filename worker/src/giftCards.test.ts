@@ -0,0 +1,91 @@
+// Gift Card Tests
+// Tests for giftCards.ts - code normalization and balance usability
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { SaleorFetch, initializeSaleorClient } from "./saleorClient";
+import { lookupGiftCard, normalizeGiftCardCode, toGiftCardBalance } from "./giftCards";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const card = {
+  id: "gc-1",
+  last4CodeChars: "AB12",
+  isActive: true,
+  expiryDate: "2026-12-31",
+  currentBalance: { amount: 25, currency: "USD" },
+};
+
+describe("normalizeGiftCardCode", () => {
+  it("should strip whitespace from entered codes", () => {
+    expect(normalizeGiftCardCode(" GIFT 1234 AB12 ")).toBe("GIFT1234AB12");
+  });
+});
+
+describe("toGiftCardBalance", () => {
+  const now = new Date("2026-06-01T12:00:00Z");
+
+  it("should expose the balance without the full code", () => {
+    expect(toGiftCardBalance(card, now)).toEqual({
+      last4: "AB12",
+      balance: 25,
+      currency: "USD",
+      expiresAt: "2026-12-31",
+      usable: true,
+    });
+  });
+
+  it("should stay usable through the expiry day", () => {
+    expect(toGiftCardBalance(card, new Date("2026-12-31T20:00:00Z")).usable).toBe(true);
+    expect(toGiftCardBalance(card, new Date("2027-01-01T01:00:00Z")).usable).toBe(false);
+  });
+
+  it("should not be usable when inactive or empty", () => {
+    expect(toGiftCardBalance({ ...card, isActive: false }, now).usable).toBe(false);
+    expect(
+      toGiftCardBalance({ ...card, currentBalance: { amount: 0, currency: "USD" } }, now)
+        .usable,
+    ).toBe(false);
+  });
+});
+
+describe("lookupGiftCard", () => {
+  afterEach(() => {
+    initializeSaleorClient({});
+    delete (globalThis as any).SALEOR_TRANSPORT;
+    delete (globalThis as any).GIFT_CARD_MAX_MISSES;
+  });
+
+  function useGiftCards(codes: Record<string, typeof card>) {
+    const send = vi.fn<SaleorFetch>(async (_, init) => {
+      const { variables } = JSON.parse(String(init?.body));
+      const node = codes[variables.code];
+      return Response.json({ data: { giftCards: { edges: node ? [{ node }] : [] } } });
+    });
+    (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+    initializeSaleorClient({ SALEOR_API_URL: "https://saleor.test/graphql/", SALEOR_TOKEN: "t" });
+    return send;
+  }
+
+  it("should lock a user out after too many codes that find no card", async () => {
+    (globalThis as any).GIFT_CARD_MAX_MISSES = "2";
+    const send = useGiftCards({ GOOD: card });
+    const now = new Date("2026-06-01T12:00:00Z");
+
+    expect(await lookupGiftCard("guesser", "GUESS1", now)).toBeNull();
+    expect(await lookupGiftCard("guesser", "GOOD", now)).toMatchObject({ last4: "AB12" });
+    expect(await lookupGiftCard("guesser", "GUESS2", now)).toBeNull();
+    await expect(lookupGiftCard("guesser", "GOOD", now)).rejects.toMatchObject({
+      code: "RATE_LIMITED",
+      retryAfterMinutes: 60,
+    });
+    expect(send).toHaveBeenCalledTimes(3);
+
+    // Other users and the end of the window are unaffected
+    expect(await lookupGiftCard("someone-else", "GOOD", now)).not.toBeNull();
+    const later = new Date("2026-06-01T13:00:01Z");
+    expect(await lookupGiftCard("guesser", "GOOD", later)).not.toBeNull();
+  });
+});
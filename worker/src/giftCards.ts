@@ -0,0 +1,168 @@
+// Gift Cards
+// Saleor gift cards redeemed at placeOrder. Saleor only applies gift cards
+// to checkouts (checkoutAddPromoCode), so orders paying with one always go
+// through the checkout pipeline. Codes are bearer credentials: only the
+// last four characters are logged or stored on the order, and a user whose
+// lookups miss GIFT_CARD_MAX_MISSES times within GIFT_CARD_LOCKOUT_MINUTES
+// is locked out of lookups until the window ends, so codes can't be guessed.
+
+import { GiftCardBalance } from "./contracts";
+import { getNumberVar } from "./config";
+import { badUserInputError, rateLimitedError } from "./errors";
+import { logger } from "./logger";
+import { readJSON, writeJSON } from "./storage";
+import {
+  getSaleorClient,
+  isSaleorConfigured,
+  GIFT_CARD_BY_CODE_QUERY,
+} from "./saleorClient";
+import { GiftCardByCodeQuery, GiftCardByCodeQueryVariables } from "./saleorOperations";
+
+interface SaleorGiftCardNode {
+  id: string;
+  last4CodeChars: string;
+  isActive: boolean;
+  expiryDate: string | null; // YYYY-MM-DD, valid through that day
+  currentBalance: { amount: number; currency: string } | null;
+}
+
+const MISSES_PREFIX = "gift-card-misses:";
+
+// Codes that found no card, counted from the window's first miss
+interface GiftCardMisses {
+  count: number;
+  firstAt: string;
+}
+
+function getMaxMisses(): number {
+  const max = getNumberVar("GIFT_CARD_MAX_MISSES", 5);
+  return max > 0 ? max : 5;
+}
+
+function getLockoutMs(): number {
+  const minutes = getNumberVar("GIFT_CARD_LOCKOUT_MINUTES", 60);
+  return (minutes > 0 ? minutes : 60) * 60 * 1000;
+}
+
+/**
+ * Gift card codes are entered as printed; whitespace is ignored
+ */
+export function normalizeGiftCardCode(code: string): string {
+  return code.replace(/\s+/g, "");
+}
+
+/**
+ * Balance view of a Saleor gift card at a point in time
+ */
+export function toGiftCardBalance(
+  card: SaleorGiftCardNode,
+  now: Date = new Date(),
+): GiftCardBalance {
+  const balance = Number(card.currentBalance?.amount ?? 0);
+  const expired =
+    !!card.expiryDate && new Date(`${card.expiryDate}T23:59:59Z`) < now;
+  return {
+    last4: card.last4CodeChars,
+    balance,
+    currency: card.currentBalance?.currency || "",
+    expiresAt: card.expiryDate,
+    usable: card.isActive && !expired && balance > 0,
+  };
+}
+
+/**
+ * Look up a gift card by code, or null when there is no such card
+ */
+export async function fetchGiftCardBalance(
+  rawCode: string,
+): Promise<GiftCardBalance | null> {
+  const code = normalizeGiftCardCode(rawCode);
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client || !code) {
+    return null;
+  }
+
+  const variables: GiftCardByCodeQueryVariables = { code };
+  const response = await client.execute<GiftCardByCodeQuery>(GIFT_CARD_BY_CODE_QUERY, variables);
+  if (response.errors && response.errors.length > 0) {
+    logger.error("saleor_gift_card_lookup_error", {
+      error: response.errors.map((e) => e.message).join(", "),
+      last4: code.slice(-4),
+    });
+    throw badUserInputError("Gift cards are unavailable, please try again");
+  }
+
+  const card = response.data?.giftCards?.edges?.[0]?.node;
+  return card ? toGiftCardBalance(card) : null;
+}
+
+/**
+ * Look up a gift card for a user, counting codes that find no card
+ * Throws RATE_LIMITED while the user is locked out
+ */
+export async function lookupGiftCard(
+  userId: string,
+  rawCode: string,
+  now: Date = new Date(),
+): Promise<GiftCardBalance | null> {
+  const key = `${MISSES_PREFIX}${userId}`;
+  const lockoutMs = getLockoutMs();
+  const stored = await readJSON<GiftCardMisses>(key);
+  const windowEnd = stored ? new Date(stored.firstAt).getTime() + lockoutMs : 0;
+  const misses = stored && windowEnd > now.getTime() ? stored : null;
+  if (misses && misses.count >= getMaxMisses()) {
+    throw rateLimitedError(
+      "Too many gift card codes tried, please try again later",
+      Math.ceil((windowEnd - now.getTime()) / 60000),
+    );
+  }
+
+  const card = await fetchGiftCardBalance(rawCode);
+  if (!card) {
+    const record: GiftCardMisses = {
+      count: (misses?.count ?? 0) + 1,
+      firstAt: misses?.firstAt ?? now.toISOString(),
+    };
+    const ttlMs = new Date(record.firstAt).getTime() + lockoutMs - now.getTime();
+    await writeJSON(key, record, {
+      expirationTtl: Math.max(60, Math.ceil(ttlMs / 1000)),
+    });
+    if (record.count >= getMaxMisses()) {
+      logger.warn("gift_card_lookups_locked", { userId, misses: record.count });
+    }
+  }
+  return card;
+}
+
+/**
+ * Check a gift card for placeOrder, throwing when it cannot pay
+ */
+export async function requireUsableGiftCard(
+  rawCode: string,
+  currency: string,
+  userId: string,
+): Promise<string> {
+  if (!isSaleorConfigured()) {
+    throw badUserInputError("Gift cards are unavailable", "giftCardCode");
+  }
+  const code = normalizeGiftCardCode(rawCode);
+  const card = await lookupGiftCard(userId, code);
+  if (!card) {
+    throw badUserInputError("Gift card not found", "giftCardCode");
+  }
+  if (!card.usable) {
+    throw badUserInputError(
+      card.balance > 0
+        ? "Gift card is expired or inactive"
+        : "Gift card has no balance left",
+      "giftCardCode",
+    );
+  }
+  if (card.currency !== currency) {
+    throw badUserInputError(
+      `Gift card is in ${card.currency}, this restaurant charges ${currency}`,
+      "giftCardCode",
+    );
+  }
+  return code;
+}
@@ -0,0 +1,84 @@
+// Handoff Code Tests
+// Tests for handoffCodes.ts and orderHandoff.ts - pickup codes and checks
+
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import {
+  buildHandoffQrPayload,
+  generateHandoffCode,
+  parseHandoffQrPayload,
+  resolveHandoffInput,
+} from "./handoffCodes";
+import { verifyHandoffCode, withHandoffCode } from "./orderHandoff";
+import { clearOrders, createSaleorOrder, getOrder, toOrderDetails } from "./saleorOrder";
+import { PlaceOrderInput } from "./contracts";
+
+// Mock the logger to avoid console output during tests
+vi.mock("./logger", () => ({
+  logger: {
+    info: vi.fn(),
+    error: vi.fn(),
+    warn: vi.fn(),
+    debug: vi.fn(),
+  },
+}));
+
+const orderInput: PlaceOrderInput = {
+  restaurantId: "restA",
+  deliveryLocation: { address: "1 Test Street" },
+  items: [{ dishId: "dish1", quantity: 1 }],
+  fulfillmentType: "PICKUP",
+};
+
+describe("handoff codes", () => {
+  it("should generate four digit codes", () => {
+    expect(generateHandoffCode()).toMatch(/^\d{4}$/);
+  });
+
+  it("should round-trip the QR payload", () => {
+    const payload = buildHandoffQrPayload("T3JkZXI6MQ==", "0427");
+    expect(payload).toBe("tma-handoff:T3JkZXI6MQ==:0427");
+    expect(parseHandoffQrPayload(payload)).toEqual({ orderId: "T3JkZXI6MQ==", code: "0427" });
+    expect(parseHandoffQrPayload("https://example.com")).toBeNull();
+    expect(parseHandoffQrPayload("tma-handoff::0427")).toBeNull();
+  });
+
+  it("should accept a QR payload or the order and code", () => {
+    expect(resolveHandoffInput({ orderId: "o1", code: " 04 27 " })).toEqual({
+      orderId: "o1",
+      code: "0427",
+    });
+    expect(() => resolveHandoffInput({ qrPayload: "nonsense" })).toThrow("not an order QR code");
+    expect(() => resolveHandoffInput({ orderId: "o1" })).toThrow("Code is required");
+  });
+});
+
+describe("verifyHandoffCode", () => {
+  beforeEach(() => {
+    clearOrders();
+  });
+
+  it("should record the first matching check", async () => {
+    const order = (await createSaleorOrder(orderInput, "user-1")).order!;
+    const code = order.metadata!["tma.handoffCode"];
+    const now = new Date("2026-05-01T12:00:00.000Z");
+
+    const wrong = code === "0000" ? "1111" : "0000";
+    expect((await verifyHandoffCode(order.id, wrong, "staff-1", now)).valid).toBe(false);
+    expect(getOrder(order.id)?.metadata?.["tma.handedOffAt"]).toBeUndefined();
+
+    const result = await verifyHandoffCode(order.id, code, "staff-1", now);
+    expect(result).toMatchObject({ valid: true, handedOffAt: now.toISOString() });
+    const again = await verifyHandoffCode(order.id, code, "staff-2", new Date());
+    expect(again.handedOffAt).toBe(now.toISOString());
+  });
+
+  it("should show the code only in the customer's view", async () => {
+    const order = (await createSaleorOrder(orderInput, "user-2")).order!;
+    const staffView = toOrderDetails(order);
+    expect(staffView.handoffCode).toBeUndefined();
+
+    const customerView = withHandoffCode(staffView, order);
+    expect(customerView.handoffCode).toBe(order.metadata!["tma.handoffCode"]);
+    expect(customerView.handoffQrPayload).toContain(order.id);
+  });
+});
@@ -0,0 +1,69 @@
+// Order Handoff Codes
+// Every order gets a short numeric code (tma.handoffCode) when it is
+// placed. The customer sees it on the order screen, also as a QR payload
+// encoding the order and code (tma-handoff:<orderId>:<code>); staff scan
+// the QR or type the code when handing the order over (orderHandoff.ts).
+
+import { VerifyHandoffCodeInput } from "./contracts";
+import { badUserInputError } from "./errors";
+
+export const HANDOFF_CODE_LENGTH = 4;
+
+const QR_PREFIX = "tma-handoff:";
+
+/**
+ * Random numeric handoff code, e.g. "0427"
+ */
+export function generateHandoffCode(): string {
+  const digits = new Uint32Array(HANDOFF_CODE_LENGTH);
+  crypto.getRandomValues(digits);
+  return Array.from(digits, (digit) => String(digit % 10)).join("");
+}
+
+/**
+ * QR payload for an order's handoff code
+ */
+export function buildHandoffQrPayload(orderId: string, code: string): string {
+  return `${QR_PREFIX}${orderId}:${code}`;
+}
+
+/**
+ * Order and code from a scanned QR payload, or null when it isn't one
+ */
+export function parseHandoffQrPayload(
+  payload: string,
+): { orderId: string; code: string } | null {
+  const value = payload.trim();
+  if (!value.startsWith(QR_PREFIX)) {
+    return null;
+  }
+  const separator = value.lastIndexOf(":");
+  const orderId = value.slice(QR_PREFIX.length, separator);
+  const code = value.slice(separator + 1);
+  return separator > QR_PREFIX.length && orderId && code ? { orderId, code } : null;
+}
+
+/**
+ * Order ID and code from verifyHandoffCode input (a QR payload or both fields)
+ */
+export function resolveHandoffInput(input: VerifyHandoffCodeInput): {
+  orderId: string;
+  code: string;
+} {
+  if (input.qrPayload) {
+    const parsed = parseHandoffQrPayload(input.qrPayload);
+    if (!parsed) {
+      throw badUserInputError("This is not an order QR code", "qrPayload");
+    }
+    return parsed;
+  }
+  const orderId = input.orderId?.trim() || "";
+  const code = input.code?.replace(/\s+/g, "") || "";
+  if (!orderId) {
+    throw badUserInputError("Order is required", "orderId");
+  }
+  if (!code) {
+    throw badUserInputError("Code is required", "code");
+  }
+  return { orderId, code };
+}
@@ -0,0 +1,45 @@
+// Health Hint Tests
+// Tests for health.ts - degraded-mode signals
+
+import { describe, it, expect, beforeEach } from "vitest";
+import {
+  getHealthHints,
+  recordFallbackServed,
+  recordSaleorFailure,
+  recordSaleorSuccess,
+  resetHealthSignals,
+} from "./health";
+
+describe("getHealthHints", () => {
+  beforeEach(() => {
+    resetHealthSignals();
+  });
+
+  it("should report Saleor as degraded after a recent failure", () => {
+    const now = Date.now();
+    recordSaleorFailure(now - 1000);
+    expect(getHealthHints(now).saleorDegraded).toBe(true);
+
+    recordSaleorSuccess(now - 500);
+    expect(getHealthHints(now).saleorDegraded).toBe(false);
+  });
+
+  it("should expire signals after the window", () => {
+    const now = Date.now();
+    recordSaleorFailure(now - 120_000);
+    recordFallbackServed("dishes", now - 120_000);
+    const hints = getHealthHints(now);
+    expect(hints.saleorDegraded).toBe(false);
+    expect(hints.servingStaleData).toBe(false);
+    expect(hints.staleDataTypes).toEqual([]);
+  });
+
+  it("should list the data types served from fallback", () => {
+    const now = Date.now();
+    recordFallbackServed("channels", now - 2000);
+    recordFallbackServed("dishes", now - 1000);
+    const hints = getHealthHints(now);
+    expect(hints.servingStaleData).toBe(true);
+    expect(hints.staleDataTypes).toEqual(["channels", "dishes"]);
+  });
+});
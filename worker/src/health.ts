@@ -0,0 +1,74 @@
+// Degraded-Mode Health Hints
+// Tracks recent Saleor failures and mock-data fallbacks in this isolate and
+// reports them in the GraphQL response `extensions.health` block, so the
+// Mini App can adapt (hide checkout, show a banner) before a user action
+// fails. Operators can force flags with SALEOR_DEGRADED / PAYMENTS_DISABLED.
+
+import { HealthHints } from "./contracts";
+import { getBooleanVar, getNumberVar } from "./config";
+import { isTelegramPaymentsEnabled } from "./telegramPayments";
+
+let lastSaleorFailureAt = 0;
+let lastSaleorSuccessAt = 0;
+let lastFallbackAt = 0;
+let fallbackDataTypes = new Set<string>();
+
+/**
+ * How long a failure or fallback keeps its flag set
+ * (HEALTH_SIGNAL_WINDOW_SECONDS, default 60)
+ */
+export function getSignalWindowMs(): number {
+  const seconds = getNumberVar("HEALTH_SIGNAL_WINDOW_SECONDS", 60);
+  return (seconds > 0 ? seconds : 60) * 1000;
+}
+
+export function recordSaleorFailure(now: number = Date.now()): void {
+  lastSaleorFailureAt = now;
+}
+
+export function recordSaleorSuccess(now: number = Date.now()): void {
+  lastSaleorSuccessAt = now;
+}
+
+/**
+ * Note that mock/fallback data was served instead of live Saleor data
+ */
+export function recordFallbackServed(
+  dataType: string,
+  now: number = Date.now(),
+): void {
+  if (now - lastFallbackAt > getSignalWindowMs()) {
+    fallbackDataTypes = new Set();
+  }
+  lastFallbackAt = now;
+  fallbackDataTypes.add(dataType);
+}
+
+/**
+ * Current health hints; Saleor counts as degraded while its latest call
+ * within the window failed
+ */
+export function getHealthHints(now: number = Date.now()): HealthHints {
+  const windowMs = getSignalWindowMs();
+  const recentFailure =
+    now - lastSaleorFailureAt <= windowMs &&
+    lastSaleorFailureAt >= lastSaleorSuccessAt;
+  const servingStaleData = now - lastFallbackAt <= windowMs;
+
+  return {
+    saleorDegraded: getBooleanVar("SALEOR_DEGRADED") || recentFailure,
+    servingStaleData,
+    staleDataTypes: servingStaleData ? Array.from(fallbackDataTypes) : [],
+    paymentsDisabled: !isTelegramPaymentsEnabled(),
+  };
+}
+
+/**
+ * Reset tracked signals (tests)
+ */
+export function resetHealthSignals(): void {
+  lastSaleorFailureAt = 0;
+  lastSaleorSuccessAt = 0;
+  lastFallbackAt = 0;
+  fallbackDataTypes = new Set();
+}
@@ -0,0 +1,50 @@
+// Thumbnail Format Negotiation Tests
+// Tests for imageFormat.ts - header parsing and format selection
+
+import { describe, it, expect, afterEach } from "vitest";
+import { getRequestImageFormats, negotiateImageFormat } from "./imageFormat";
+
+function requestWith(headers: Record<string, string>): Request {
+  return new Request("https://worker.example/graphql", { headers });
+}
+
+describe("getRequestImageFormats", () => {
+  it("should read the X-Image-Format header", () => {
+    expect(getRequestImageFormats(requestWith({ "X-Image-Format": "webp" }))).toEqual([
+      "WEBP",
+    ]);
+  });
+
+  it("should fall back to image types in Accept", () => {
+    const request = requestWith({
+      Accept: "image/avif,image/webp,image/apng,*/*;q=0.8",
+    });
+    expect(getRequestImageFormats(request)).toEqual(["AVIF", "WEBP"]);
+  });
+
+  it("should return nothing for JSON-only clients", () => {
+    expect(getRequestImageFormats(requestWith({ Accept: "application/json" }))).toEqual([]);
+  });
+});
+
+describe("negotiateImageFormat", () => {
+  afterEach(() => {
+    delete (globalThis as any).THUMBNAIL_FORMATS;
+  });
+
+  it("should prefer AVIF, then WEBP, then ORIGINAL", () => {
+    expect(negotiateImageFormat(undefined, ["WEBP", "AVIF"])).toBe("AVIF");
+    expect(negotiateImageFormat(undefined, ["WEBP"])).toBe("WEBP");
+    expect(negotiateImageFormat(undefined, [])).toBe("ORIGINAL");
+  });
+
+  it("should respect THUMBNAIL_FORMATS", () => {
+    (globalThis as any).THUMBNAIL_FORMATS = "WEBP";
+    expect(negotiateImageFormat(undefined, ["AVIF", "WEBP"])).toBe("WEBP");
+    expect(negotiateImageFormat("AVIF")).toBe("ORIGINAL");
+  });
+
+  it("should let an explicit argument win", () => {
+    expect(negotiateImageFormat("ORIGINAL", ["AVIF"])).toBe("ORIGINAL");
+  });
+});
@@ -0,0 +1,69 @@
+// Thumbnail Format Negotiation
+// Menu thumbnails are requested from Saleor as AVIF or WEBP when the client
+// supports them (imageFormat argument, X-Image-Format header, or image types
+// in Accept) and in the original upload format (usually JPEG) otherwise.
+// THUMBNAIL_FORMATS limits which modern formats may be served.
+
+import { ImageFormat } from "./contracts";
+import { getListVar, getNumberVar } from "./config";
+
+export const IMAGE_FORMAT_HEADER = "X-Image-Format";
+
+// Preferred first when the client accepts several
+const MODERN_FORMATS: ImageFormat[] = ["AVIF", "WEBP"];
+
+/**
+ * Modern formats the worker may request (THUMBNAIL_FORMATS, default AVIF,WEBP)
+ */
+export function getAllowedImageFormats(): ImageFormat[] {
+  const configured = getListVar("THUMBNAIL_FORMATS").map((f) => f.toUpperCase());
+  if (configured.length === 0) {
+    return MODERN_FORMATS;
+  }
+  return MODERN_FORMATS.filter((format) => configured.includes(format));
+}
+
+/**
+ * Thumbnail edge length in pixels (THUMBNAIL_SIZE); undefined keeps
+ * Saleor's default
+ */
+export function getThumbnailSize(): number | undefined {
+  const size = getNumberVar("THUMBNAIL_SIZE", 0);
+  return size > 0 ? Math.floor(size) : undefined;
+}
+
+function parseFormats(value: string | null | undefined): ImageFormat[] {
+  if (!value) {
+    return [];
+  }
+  const lower = value.toLowerCase();
+  return MODERN_FORMATS.filter((format) =>
+    new RegExp(`(^|[\\s,/])${format.toLowerCase()}([\\s,;]|$)`).test(lower),
+  );
+}
+
+/**
+ * Formats a request says the client can display, from the X-Image-Format
+ * header ("avif,webp") or Accept ("image/avif,image/webp,...")
+ */
+export function getRequestImageFormats(request: Request): ImageFormat[] {
+  const explicit = parseFormats(request.headers.get(IMAGE_FORMAT_HEADER));
+  return explicit.length > 0 ? explicit : parseFormats(request.headers.get("Accept"));
+}
+
+/**
+ * Pick the thumbnail format: an explicit argument wins, then the best
+ * allowed format the client supports, then ORIGINAL
+ */
+export function negotiateImageFormat(
+  requested: ImageFormat | undefined | null,
+  supported: ImageFormat[] = [],
+): ImageFormat {
+  const allowed = getAllowedImageFormats();
+  if (requested) {
+    return requested === "ORIGINAL" || allowed.includes(requested)
+      ? requested
+      : "ORIGINAL";
+  }
+  return allowed.find((format) => supported.includes(format)) || "ORIGINAL";
+}
@@ -2,7 +2,7 @@
 // Validates Telegram Init Data and propagates AuthContext to resolvers
 // Phase 7: Enhanced error handling with standardized codes
 
-import { AppError, unauthorizedError, internalError } from "./errors";
+import { AppError, unauthorizedError, forbiddenError, internalError } from "./errors";
 import { logger } from "./logger";
 
 import {
@@ -32,15 +32,32 @@ import {
   removeFromCartSync,
   clearCartSync,
 } from "./cart";
-import { initializeSaleorClient, isSaleorConfigured, getSaleorClient } from "./saleorClient";
-import { setDebugMode } from "./logger";
+import { isSaleorConfigured, getSaleorClient } from "./saleorClient";
+import { recordOperation } from "./operationAudit";
+import { runScheduledJobs } from "./jobs";
+import { matchReceiptPath, handleReceiptRequest } from "./receipts";
+import { TELEGRAM_WEBHOOK_PATH, handleTelegramWebhook } from "./telegramPayments";
+import { getHealthHints } from "./health";
+import { SALEOR_WEBHOOK_PATH, handleSaleorWebhook } from "./saleorWebhooks";
+import { METRICS_PATH, handleMetricsRequest } from "./resolverMetrics";
+import { READYZ_PATH, handleReadinessRequest, startLifecycle } from "./lifecycle";
+import { getRequestImageFormats } from "./imageFormat";
+import { assertSupportedClientVersion, getRequestClientVersion } from "./clientVersion";
+import { runWithSaleorTarget, selectSaleorTarget } from "./saleorTargets";
+import { runInBackground, runWithWaitUntil } from "./backgroundTasks";
+import {
+  INTROSPECTION_TOKEN_HEADER,
+  hasIntrospectionToken,
+  isIntrospectionQuery,
+  resolveIntrospection,
+} from "./introspection";
 
 // CORS headers for preflight and actual requests
 // Allow any localhost port during development (5173, 5174, etc.)
 const CORS_HEADERS = {
   "Access-Control-Allow-Origin": "*",
   "Access-Control-Allow-Headers":
-    "Content-Type, X-Telegram-Init-Data, Telegram-Init-Data",
+    "Content-Type, X-Telegram-Init-Data, Telegram-Init-Data, X-Image-Format, X-Introspection-Token, X-TMA-Client-Version",
   "Access-Control-Allow-Methods": "GET, POST, OPTIONS",
 };
 
@@ -288,21 +305,23 @@ interface Env {
 
 // Register the fetch event listener only in Cloudflare Workers environment
 if (typeof addEventListener === "function") {
+  // Components (config, Saleor client, ...) start on an isolate's first event
+  // Background work (startup ping, shadow runs, audit writes) outlives the response
   addEventListener("fetch", (event: FetchEvent) => {
-    // Cloudflare Workers injects environment variables into self/globalThis
-    // (not event.env in service-worker format)
-    const saleorApiUrl = (self as any).SALEOR_API_URL;
-    const saleorToken = (self as any).SALEOR_TOKEN;
-    const debugVal = (self as any).DEBUG;
-    
-    setDebugMode(debugVal === "true");
-    
-    initializeSaleorClient({
-      SALEOR_API_URL: saleorApiUrl,
-      SALEOR_TOKEN: saleorToken,
-    });
-    
-    event.respondWith(handleRequest(event.request));
+    event.respondWith(
+      runWithWaitUntil((promise) => event.waitUntil(promise), () =>
+        startLifecycle().then(() => handleRequest(event.request)),
+      ),
+    );
+  });
+
+  // Cron trigger: background jobs (payment deadlines, ...)
+  addEventListener("scheduled", (event: ScheduledEvent) => {
+    event.waitUntil(
+      runWithWaitUntil((promise) => event.waitUntil(promise), () =>
+        startLifecycle().then(() => runScheduledJobs(new Date(event.scheduledTime))),
+      ),
+    );
   });
 }
 
@@ -312,13 +331,21 @@ if (typeof addEventListener === "function") {
  */
 function createContext(request: Request): GraphQLContext {
   const auth = extractAuthContext(request);
-  return { auth };
+  return {
+    auth,
+    imageFormats: getRequestImageFormats(request),
+    clientVersion: getRequestClientVersion(request),
+  };
 }
 
 /**
  * Error response helpers
  */
-function errorResponse(error: AppError, requestId?: string): Response {
+function errorResponse(
+  error: AppError,
+  requestId?: string,
+  extensions?: Record<string, unknown>,
+): Response {
   // Log internal error ID for debugging
   if (error.internalId) {
     logger.error("request_error", {
@@ -328,7 +355,10 @@ function errorResponse(error: AppError, requestId?: string): Response {
     });
   }
 
-  return new Response(JSON.stringify({ errors: [error.toGraphQL()] }), {
+  const body = extensions
+    ? { errors: [error.toGraphQL()], extensions }
+    : { errors: [error.toGraphQL()] };
+  return new Response(JSON.stringify(body), {
     status: error.statusCode,
     headers: {
       "Content-Type": "application/json",
@@ -360,6 +390,43 @@ export async function handleRequest(request: Request): Promise<Response> {
     });
   }
 
+  // Bot webhook carries its own secret header instead of initData
+  if (
+    request.method === "POST" &&
+    new URL(request.url).pathname === TELEGRAM_WEBHOOK_PATH
+  ) {
+    return handleTelegramWebhook(request);
+  }
+
+  // Saleor webhooks are signed with the webhook secret key
+  if (
+    request.method === "POST" &&
+    new URL(request.url).pathname === SALEOR_WEBHOOK_PATH
+  ) {
+    return handleSaleorWebhook(request);
+  }
+
+  // Readiness probes need no auth; the body only carries component status
+  if (
+    request.method === "GET" &&
+    new URL(request.url).pathname === READYZ_PATH
+  ) {
+    return handleReadinessRequest();
+  }
+
+  // Prometheus scrapes authenticate with METRICS_TOKEN
+  if (
+    request.method === "GET" &&
+    new URL(request.url).pathname === METRICS_PATH
+  ) {
+    return handleMetricsRequest(request);
+  }
+
+  // Tooling and monitoring introspect with INTROSPECTION_TOKEN instead of initData
+  if (request.method === "POST" && request.headers.has(INTROSPECTION_TOKEN_HEADER)) {
+    return handleIntrospectionRequest(request);
+  }
+
   // Phase 2: Auth context extraction
   const context = createContext(request);
 
@@ -384,6 +451,42 @@ export async function handleRequest(request: Request): Promise<Response> {
   // Log authenticated user (avoid logging sensitive data in production)
   logger.authSuccess(context.auth.userId);
 
+  // Users on the sandbox/staging allowlist or rollout use the secondary Saleor
+  return runWithSaleorTarget(selectSaleorTarget(context.auth.userId), () =>
+    handleAuthenticatedRequest(request, context),
+  );
+}
+
+/**
+ * Receipt and GraphQL handling for an authenticated Telegram user
+ */
+async function handleAuthenticatedRequest(
+  request: Request,
+  context: GraphQLContext,
+): Promise<Response> {
+  // GET /orders/{id}/receipt - HTML receipt for the Telegram WebView
+  if (request.method === "GET") {
+    const receiptOrderId = matchReceiptPath(new URL(request.url).pathname);
+    if (receiptOrderId) {
+      try {
+        const response = await handleReceiptRequest(receiptOrderId, context);
+        Object.entries(CORS_HEADERS).forEach(([key, value]) =>
+          response.headers.set(key, value),
+        );
+        return response;
+      } catch (error) {
+        const requestId = crypto.randomUUID();
+        if (error instanceof AppError) {
+          return errorResponse(error, requestId);
+        }
+        logger.error("receipt_error", {
+          error: error instanceof Error ? error.message : "Unknown",
+        });
+        return errorResponse(internalError(requestId), requestId);
+      }
+    }
+  }
+
   // Parse GraphQL request body
   let body: any = {};
   if (request.method === "POST") {
@@ -397,25 +500,60 @@ export async function handleRequest(request: Request): Promise<Response> {
   const query: string = body?.query ?? "";
   const variables = body?.variables ?? {};
 
+  // Operation audit mode: record distinct documents/variable shapes
+  runInBackground(
+    () => recordOperation(query, variables, body?.operationName),
+    "operation_audit_failed",
+  );
+
   // GraphQL resolver routing with auth context
   try {
     const result = await resolveGraphQL(query, variables, context);
-    return jsonResponse({ data: result });
+    return jsonResponse({ data: result, extensions: { health: getHealthHints() } });
   } catch (error) {
     const requestId = crypto.randomUUID();
+    const extensions = { health: getHealthHints() };
 
     if (error != null && typeof error === 'object' && 'toGraphQL' in error && typeof error.toGraphQL === 'function') {
-      return errorResponse(error, requestId);
+      return errorResponse(error, requestId, extensions);
     }
 
     logger.error("unhandled_error", {
       error: error instanceof Error ? error.message : "Unknown",
     });
     const internalErr = internalError(requestId);
-    return errorResponse(internalErr, requestId);
+    return errorResponse(internalErr, requestId, extensions);
   }
 }
 
+/**
+ * Introspection-only request authenticated by X-Introspection-Token
+ */
+async function handleIntrospectionRequest(request: Request): Promise<Response> {
+  const requestId = crypto.randomUUID();
+  if (!hasIntrospectionToken(request)) {
+    logger.authFailure("invalid_introspection_token", requestId);
+    return errorResponse(forbiddenError(), requestId);
+  }
+
+  let body: any = {};
+  try {
+    body = await request.json();
+  } catch {
+    body = {};
+  }
+  const query: string = body?.query ?? "";
+  if (!isIntrospectionQuery(query)) {
+    return errorResponse(
+      forbiddenError("The introspection token only allows introspection queries"),
+      requestId,
+    );
+  }
+  return jsonResponse({
+    data: resolveIntrospection(query, body?.variables ?? {}, true),
+  });
+}
+
 /**
  * GraphQL resolver dispatcher - routes queries/mutations to handlers
  * Receives GraphQLContext with authenticated user info
@@ -425,9 +563,143 @@ async function resolveGraphQL(
   variables: any,
   context: GraphQLContext,
 ): Promise<any> {
+  // Introspection: debug mode only without the token (FORBIDDEN otherwise)
+  if (isIntrospectionQuery(query)) {
+    return resolveIntrospection(query, variables, false);
+  }
+
+  // clientConfig stays reachable so outdated clients learn what to update to
+  if (query.includes("clientConfig")) {
+    const result = await resolvers.Query.clientConfig(null, {}, context);
+    return { clientConfig: result };
+  }
+  assertSupportedClientVersion(context.clientVersion);
+
   // Query resolvers
+  if (query.includes("filterTagLabels")) {
+    const result = await resolvers.Query.filterTagLabels(null, {}, context);
+    return { filterTagLabels: result };
+  }
+
+  // Connections first: their names contain the list fields' names
+  if (query.includes("searchRestaurants")) {
+    const result = await resolvers.Query.searchRestaurants(
+      null,
+      {
+        query: variables?.query || "",
+        location: variables?.location ?? null,
+        first: variables?.first ?? null,
+      },
+      context,
+    );
+    return { searchRestaurants: result };
+  }
+
+  if (query.includes("restaurantsConnection")) {
+    const result = await resolvers.Query.restaurantsConnection(
+      null,
+      { first: variables?.first ?? null, after: variables?.after ?? null },
+      context,
+    );
+    return { restaurantsConnection: result };
+  }
+
+  if (query.includes("restaurantCategoriesConnection")) {
+    const result = await resolvers.Query.restaurantCategoriesConnection(
+      null,
+      {
+        restaurantId: variables?.restaurantId || "",
+        first: variables?.first ?? null,
+        after: variables?.after ?? null,
+      },
+      context,
+    );
+    return { restaurantCategoriesConnection: result };
+  }
+
+  if (query.includes("categoryDishesConnection")) {
+    const result = await resolvers.Query.categoryDishesConnection(
+      null,
+      {
+        categoryId: variables?.categoryId || "",
+        restaurantId: variables?.restaurantId || "",
+        first: variables?.first ?? null,
+        after: variables?.after ?? null,
+        imageFormat: variables?.imageFormat,
+        dietaryFilter: variables?.dietaryFilter ?? null,
+      },
+      context,
+    );
+    return { categoryDishesConnection: result };
+  }
+
+  if (query.includes("categoryTree")) {
+    const result = await resolvers.Query.categoryTree(
+      null,
+      { restaurantId: variables?.restaurantId || "" },
+      context,
+    );
+    return { categoryTree: result };
+  }
+
+  // Matched as a call; restaurants and restaurantId fields don't match
+  if (/\brestaurant\s*\(/.test(query)) {
+    const result = await resolvers.Query.restaurant(null, { id: variables?.id || "" }, context);
+    return { restaurant: result };
+  }
+
+  if (query.includes("addFavorite")) {
+    const input = variables?.input || { kind: "", targetId: "" };
+    const result = await resolvers.Mutation.addFavorite(null, { input }, context);
+    return { addFavorite: result };
+  }
+
+  if (query.includes("removeFavorite")) {
+    const result = await resolvers.Mutation.removeFavorite(
+      null,
+      { kind: variables?.kind || "", targetId: variables?.targetId || "" },
+      context,
+    );
+    return { removeFavorite: result };
+  }
+
+  if (query.includes("favorites")) {
+    const result = await resolvers.Query.favorites(
+      null,
+      { kind: variables?.kind ?? null },
+      context,
+    );
+    return { favorites: result };
+  }
+
+  if (query.includes("restaurantReviews")) {
+    const result = await resolvers.Query.restaurantReviews(
+      null,
+      {
+        restaurantId: variables?.restaurantId || "",
+        first: variables?.first ?? null,
+        after: variables?.after ?? null,
+      },
+      context,
+    );
+    return { restaurantReviews: result };
+  }
+
+  if (query.includes("restaurantTags")) {
+    const result = await resolvers.Query.restaurantTags(null, {}, context);
+    return { restaurantTags: result };
+  }
+
   if (query.includes("restaurants(") || query.includes("restaurants")) {
-    const result = await resolvers.Query.restaurants(null, {}, context);
+    const result = await resolvers.Query.restaurants(
+      null,
+      {
+        location: variables?.location ?? null,
+        tags: variables?.tags ?? null,
+        sortBy: variables?.sortBy ?? null,
+      },
+      context,
+    );
     return { restaurants: result };
   }
 
@@ -435,7 +707,7 @@ async function resolveGraphQL(
     const restaurantId = variables?.restaurantId || "restA"; // Default to test restaurant ID
     const result = await resolvers.Query.restaurantCategories(
       null,
-      { restaurantId },
+      { restaurantId, parentCategoryId: variables?.parentCategoryId ?? null },
       context,
     );
     return { restaurantCategories: result };
@@ -446,12 +718,50 @@ async function resolveGraphQL(
     const categoryId = variables?.categoryId || "catA"; // Default to test category ID
     const result = await resolvers.Query.categoryDishes(
       null,
-      { categoryId, restaurantId },
+      {
+        categoryId,
+        restaurantId,
+        imageFormat: variables?.imageFormat,
+        sortBy: variables?.sortBy,
+        dietaryFilter: variables?.dietaryFilter ?? null,
+      },
       context,
     );
     return { categoryDishes: result };
   }
 
+  if (query.includes("featuredDishes")) {
+    const result = await resolvers.Query.featuredDishes(
+      null,
+      { restaurantId: variables?.restaurantId || "", imageFormat: variables?.imageFormat },
+      context,
+    );
+    return { featuredDishes: result };
+  }
+
+  if (query.includes("dishesByIds")) {
+    const result = await resolvers.Query.dishesByIds(
+      null,
+      { restaurantId: variables?.restaurantId || "", ids: variables?.ids || [] },
+      context,
+    );
+    return { dishesByIds: result };
+  }
+
+  // Matched as a call; createDish/updateDish and dishId fields don't match
+  if (/\bdish\s*\(/.test(query)) {
+    const result = await resolvers.Query.dish(
+      null,
+      {
+        productId: variables?.productId || "",
+        restaurantId: variables?.restaurantId || "",
+        imageFormat: variables?.imageFormat,
+      },
+      context,
+    );
+    return { dish: result };
+  }
+
   // Mutation resolvers
   if (query.includes("placeOrder")) {
     const input =
@@ -545,6 +855,511 @@ async function resolveGraphQL(
     return { myChannels: result };
   }
 
+  if (query.includes("taxConfiguration")) {
+    const restaurantId = variables?.restaurantId || "";
+    const result = await resolvers.Query.taxConfiguration(
+      null,
+      { restaurantId },
+      context,
+    );
+    return { taxConfiguration: result };
+  }
+
+  if (query.includes("payoutReportCsv")) {
+    const result = await resolvers.Query.payoutReportCsv(
+      null,
+      {
+        restaurantId: variables?.restaurantId,
+        from: variables?.from || "",
+        to: variables?.to || "",
+      },
+      context,
+    );
+    return { payoutReportCsv: result };
+  }
+
+  if (query.includes("payoutReport")) {
+    const result = await resolvers.Query.payoutReport(
+      null,
+      {
+        restaurantId: variables?.restaurantId || "",
+        from: variables?.from || "",
+        to: variables?.to || "",
+      },
+      context,
+    );
+    return { payoutReport: result };
+  }
+
+  if (query.includes("setCommissionRate")) {
+    const input = variables?.input || { restaurantId: "", rate: 0 };
+    const result = await resolvers.Mutation.setCommissionRate(
+      null,
+      { input },
+      context,
+    );
+    return { setCommissionRate: result };
+  }
+
+  // Payout selections include commissionRate, so match it last
+  if (query.includes("commissionRate")) {
+    const restaurantId = variables?.restaurantId || "";
+    const result = await resolvers.Query.commissionRate(
+      null,
+      { restaurantId },
+      context,
+    );
+    return { commissionRate: result };
+  }
+
+  if (query.includes("paymentMethods")) {
+    const restaurantId = variables?.restaurantId || "";
+    const result = await resolvers.Query.paymentMethods(
+      null,
+      { restaurantId },
+      context,
+    );
+    return { paymentMethods: result };
+  }
+
+  if (query.includes("initPayment")) {
+    const result = await resolvers.Mutation.initPayment(
+      null,
+      { orderId: variables?.orderId || "", method: variables?.method || "" },
+      context,
+    );
+    return { initPayment: result };
+  }
+
+  if (query.includes("acceptOrder")) {
+    const result = await resolvers.Mutation.acceptOrder(
+      null,
+      { orderId: variables?.orderId || "" },
+      context,
+    );
+    return { acceptOrder: result };
+  }
+
+  if (query.includes("rejectOrder")) {
+    const result = await resolvers.Mutation.rejectOrder(
+      null,
+      {
+        orderId: variables?.orderId || "",
+        reason: variables?.reason || "",
+        reasonCode: variables?.reasonCode,
+      },
+      context,
+    );
+    return { rejectOrder: result };
+  }
+
+  if (query.includes("cancelOrder")) {
+    const result = await resolvers.Mutation.cancelOrder(
+      null,
+      { orderId: variables?.orderId || "", note: variables?.note },
+      context,
+    );
+    return { cancelOrder: result };
+  }
+
+  if (query.includes("verifyHandoffCode")) {
+    const result = await resolvers.Mutation.verifyHandoffCode(
+      null,
+      { input: variables?.input || {} },
+      context,
+    );
+    return { verifyHandoffCode: result };
+  }
+
+  if (query.includes("markOrderDelivered")) {
+    const result = await resolvers.Mutation.markOrderDelivered(
+      null,
+      { orderId: variables?.orderId || "" },
+      context,
+    );
+    return { markOrderDelivered: result };
+  }
+
+  if (query.includes("createStaffInvite")) {
+    const input = variables?.input || { restaurantId: "", role: "" };
+    const result = await resolvers.Mutation.createStaffInvite(null, { input }, context);
+    return { createStaffInvite: result };
+  }
+
+  if (query.includes("acceptStaffInvite")) {
+    const result = await resolvers.Mutation.acceptStaffInvite(
+      null,
+      { token: variables?.token || "" },
+      context,
+    );
+    return { acceptStaffInvite: result };
+  }
+
+  if (query.includes("revokeStaffRole")) {
+    const input = variables?.input || { restaurantId: "", telegramUserId: "" };
+    const result = await resolvers.Mutation.revokeStaffRole(null, { input }, context);
+    return { revokeStaffRole: result };
+  }
+
+  if (query.includes("setDailyDigestEnabled")) {
+    const result = await resolvers.Mutation.setDailyDigestEnabled(
+      null,
+      { enabled: variables?.enabled },
+      context,
+    );
+    return { setDailyDigestEnabled: result };
+  }
+
+  if (query.includes("restaurantStaff")) {
+    const result = await resolvers.Query.restaurantStaff(
+      null,
+      { restaurantId: variables?.restaurantId || "" },
+      context,
+    );
+    return { restaurantStaff: result };
+  }
+
+  if (query.includes("myStaffRoles")) {
+    const result = await resolvers.Query.myStaffRoles(null, {}, context);
+    return { myStaffRoles: result };
+  }
+
+  if (query.includes("quoteOrder")) {
+    const input = variables?.input || { restaurantId: "", items: [] };
+    const result = await resolvers.Query.quoteOrder(null, { input }, context);
+    return { quoteOrder: result };
+  }
+
+  if (query.includes("validatePromoCode")) {
+    const result = await resolvers.Query.validatePromoCode(
+      null,
+      {
+        code: variables?.code || "",
+        restaurantId: variables?.restaurantId || "",
+      },
+      context,
+    );
+    return { validatePromoCode: result };
+  }
+
+  if (query.includes("deliverySlots")) {
+    const result = await resolvers.Query.deliverySlots(
+      null,
+      {
+        restaurantId: variables?.restaurantId || "",
+        date: variables?.date || "",
+      },
+      context,
+    );
+    return { deliverySlots: result };
+  }
+
+  // Matched as a call; placeOrder payloads select a giftCardBalance field
+  if (/\bgiftCardBalance\s*\(/.test(query)) {
+    const result = await resolvers.Query.giftCardBalance(
+      null,
+      { code: variables?.code || "" },
+      context,
+    );
+    return { giftCardBalance: result };
+  }
+
+  if (query.includes("loyaltyBalance")) {
+    const result = await resolvers.Query.loyaltyBalance(null, {}, context);
+    return { loyaltyBalance: result };
+  }
+
+  if (query.includes("paymentStatus")) {
+    const orderId = variables?.orderId || "";
+    const result = await resolvers.Query.paymentStatus(
+      null,
+      { orderId },
+      context,
+    );
+    return { paymentStatus: result };
+  }
+
+  if (query.includes("restaurantOrdersConnection")) {
+    const result = await resolvers.Query.restaurantOrdersConnection(
+      null,
+      {
+        restaurantId: variables?.restaurantId || "",
+        activeOnly: variables?.activeOnly,
+        first: variables?.first ?? null,
+        after: variables?.after ?? null,
+      },
+      context,
+    );
+    return { restaurantOrdersConnection: result };
+  }
+
+  if (query.includes("restaurantOrders")) {
+    const result = await resolvers.Query.restaurantOrders(
+      null,
+      {
+        restaurantId: variables?.restaurantId || "",
+        activeOnly: variables?.activeOnly,
+      },
+      context,
+    );
+    return { restaurantOrders: result };
+  }
+
+  if (query.includes("recentDishes")) {
+    const result = await resolvers.Query.recentDishes(
+      null,
+      { limit: variables?.limit ?? null },
+      context,
+    );
+    return { recentDishes: result };
+  }
+
+  if (query.includes("activeOrder")) {
+    const result = await resolvers.Query.activeOrder(null, {}, context);
+    return { activeOrder: result };
+  }
+
+  if (query.includes("orderHistory")) {
+    const result = await resolvers.Query.orderHistory(null, {}, context);
+    return { orderHistory: result };
+  }
+
+  // "placeOrder(" is matched earlier; this only catches the order(...) field
+  if (/\border\s*\(/.test(query)) {
+    const orderId = variables?.orderId || "";
+    const result = await resolvers.Query.order(null, { orderId }, context);
+    return { order: result };
+  }
+
+  if (query.includes("createInvoice")) {
+    const orderId = variables?.orderId || "";
+    const result = await resolvers.Mutation.createInvoice(
+      null,
+      { orderId },
+      context,
+    );
+    return { createInvoice: result };
+  }
+
+  if (query.includes("reportOrderIssue")) {
+    const input = variables?.input || { orderId: "", category: "" };
+    const result = await resolvers.Mutation.reportOrderIssue(
+      null,
+      { input },
+      context,
+    );
+    return { reportOrderIssue: result };
+  }
+
+  if (query.includes("resolveOrderIssue")) {
+    const input = variables?.input || { issueId: "", resolution: "" };
+    const result = await resolvers.Mutation.resolveOrderIssue(
+      null,
+      { input },
+      context,
+    );
+    return { resolveOrderIssue: result };
+  }
+
+  if (query.includes("reviewAbuseFlag")) {
+    const input = variables?.input || { flagId: "", decision: "" };
+    const result = await resolvers.Mutation.reviewAbuseFlag(
+      null,
+      { input },
+      context,
+    );
+    return { reviewAbuseFlag: result };
+  }
+
+  if (query.includes("issueCompensationVoucher")) {
+    const input = variables?.input || { orderId: "", reason: "" };
+    const result = await resolvers.Mutation.issueCompensationVoucher(
+      null,
+      { input },
+      context,
+    );
+    return { issueCompensationVoucher: result };
+  }
+
+  if (query.includes("orderIssues")) {
+    const result = await resolvers.Query.orderIssues(
+      null,
+      { restaurantId: variables?.restaurantId, status: variables?.status },
+      context,
+    );
+    return { orderIssues: result };
+  }
+
+  if (query.includes("catalogHealthReport")) {
+    const result = await resolvers.Query.catalogHealthReport(null, {}, context);
+    return { catalogHealthReport: result };
+  }
+
+  if (query.includes("priceHistory")) {
+    const result = await resolvers.Query.priceHistory(
+      null,
+      { restaurantId: variables?.restaurantId || "", dishId: variables?.dishId || "" },
+      context,
+    );
+    return { priceHistory: result };
+  }
+
+  if (query.includes("abuseReviewQueue")) {
+    const result = await resolvers.Query.abuseReviewQueue(
+      null,
+      {
+        restaurantId: variables?.restaurantId,
+        status: variables?.status,
+        userId: variables?.userId,
+      },
+      context,
+    );
+    return { abuseReviewQueue: result };
+  }
+
+  if (query.includes("rateOrder")) {
+    const result = await resolvers.Mutation.rateOrder(
+      null,
+      {
+        orderId: variables?.orderId || "",
+        stars: variables?.stars ?? 0,
+        comment: variables?.comment ?? null,
+      },
+      context,
+    );
+    return { rateOrder: result };
+  }
+
+  if (query.includes("submitReview")) {
+    const input = variables?.input || { orderId: "", rating: 0 };
+    const result = await resolvers.Mutation.submitReview(
+      null,
+      { input },
+      context,
+    );
+    return { submitReview: result };
+  }
+
+  if (query.includes("moderateReview")) {
+    const input = variables?.input || { reviewId: "", action: "" };
+    const result = await resolvers.Mutation.moderateReview(
+      null,
+      { input },
+      context,
+    );
+    return { moderateReview: result };
+  }
+
+  if (query.includes("replyToReview")) {
+    const input = variables?.input || { reviewId: "", reply: "" };
+    const result = await resolvers.Mutation.replyToReview(
+      null,
+      { input },
+      context,
+    );
+    return { replyToReview: result };
+  }
+
+  if (query.includes("reviewQueue")) {
+    const result = await resolvers.Query.reviewQueue(
+      null,
+      { restaurantId: variables?.restaurantId, status: variables?.status },
+      context,
+    );
+    return { reviewQueue: result };
+  }
+
+  // Mutations first: their selections don't contain "broadcasts"
+  if (query.includes("startBroadcast")) {
+    const input = variables?.input || { message: "" };
+    const result = await resolvers.Mutation.startBroadcast(
+      null,
+      { input },
+      context,
+    );
+    return { startBroadcast: result };
+  }
+
+  if (query.includes("abortBroadcast")) {
+    const broadcastId = variables?.broadcastId || "";
+    const result = await resolvers.Mutation.abortBroadcast(
+      null,
+      { broadcastId },
+      context,
+    );
+    return { abortBroadcast: result };
+  }
+
+  if (query.includes("retryNotifications")) {
+    const result = await resolvers.Mutation.retryNotifications(
+      null,
+      { ids: variables?.ids || [] },
+      context,
+    );
+    return { retryNotifications: result };
+  }
+
+  if (query.includes("markNotificationsFailed")) {
+    const result = await resolvers.Mutation.markNotificationsFailed(
+      null,
+      { ids: variables?.ids || [] },
+      context,
+    );
+    return { markNotificationsFailed: result };
+  }
+
+  if (query.includes("notificationFailures")) {
+    const result = await resolvers.Query.notificationFailures(
+      null,
+      { filter: variables?.filter, first: variables?.first },
+      context,
+    );
+    return { notificationFailures: result };
+  }
+
+  if (query.includes("migrateCatalogMetadata")) {
+    const result = await resolvers.Mutation.migrateCatalogMetadata(
+      null,
+      { dryRun: variables?.dryRun, after: variables?.after },
+      context,
+    );
+    return { migrateCatalogMetadata: result };
+  }
+
+  if (query.includes("broadcasts")) {
+    const result = await resolvers.Query.broadcasts(null, {}, context);
+    return { broadcasts: result };
+  }
+
+  if (/\bbroadcast\s*\(/.test(query)) {
+    const broadcastId = variables?.broadcastId || "";
+    const result = await resolvers.Query.broadcast(
+      null,
+      { broadcastId },
+      context,
+    );
+    return { broadcast: result };
+  }
+
+  if (query.includes("operationAudit")) {
+    const onlyUnlisted = variables?.onlyUnlisted === true;
+    const result = await resolvers.Query.operationAudit(
+      null,
+      { onlyUnlisted },
+      context,
+    );
+    return { operationAudit: result };
+  }
+
+  if (query.includes("orderPipelineShadowReport")) {
+    const result = await resolvers.Query.orderPipelineShadowReport(
+      null,
+      {},
+      context,
+    );
+    return { orderPipelineShadowReport: result };
+  }
+
   // Phase 10: Superadmin & Channel Admin Mutation Resolvers
   if (query.includes("linkChannelToTelegram")) {
     const input = variables?.input || {
@@ -630,6 +1445,57 @@ async function resolveGraphQL(
     return { updateStoreDescription: result };
   }
 
+  if (query.includes("setRestaurantAnnouncement")) {
+    const input = variables?.input || { restaurantId: "", message: "" };
+    const result = await resolvers.Mutation.setRestaurantAnnouncement(
+      null,
+      { input },
+      context,
+    );
+    return { setRestaurantAnnouncement: result };
+  }
+
+  if (query.includes("setRestaurantPaused")) {
+    const result = await resolvers.Mutation.setRestaurantPaused(
+      null,
+      {
+        restaurantId: variables?.restaurantId || "",
+        paused: variables?.paused,
+        until: variables?.until ?? null,
+      },
+      context,
+    );
+    return { setRestaurantPaused: result };
+  }
+
+  if (query.includes("setKitchenCapacity")) {
+    const result = await resolvers.Mutation.setKitchenCapacity(
+      null,
+      {
+        restaurantId: variables?.restaurantId || "",
+        maxActiveOrders: variables?.maxActiveOrders ?? null,
+      },
+      context,
+    );
+    return { setKitchenCapacity: result };
+  }
+
+  if (query.includes("setDailyBanner")) {
+    const input = variables?.input || { date: "" };
+    const result = await resolvers.Mutation.setDailyBanner(null, { input }, context);
+    return { setDailyBanner: result };
+  }
+
+  if (query.includes("setOpeningHoursOverride")) {
+    const input = variables?.input || { restaurantId: "", date: "" };
+    const result = await resolvers.Mutation.setOpeningHoursOverride(
+      null,
+      { input },
+      context,
+    );
+    return { setOpeningHoursOverride: result };
+  }
+
   // Unknown operation
   return {};
 }
@@ -0,0 +1,150 @@
+// Introspection Tests
+// Tests for introspection.ts - SDL conversion and the token gate
+
+import { describe, it, expect, afterEach } from "vitest";
+import { readFileSync } from "fs";
+import { resolve } from "path";
+import {
+  buildIntrospectionSchema,
+  hasIntrospectionToken,
+  isIntrospectionQuery,
+  resolveIntrospection,
+} from "./introspection";
+import { setDebugMode } from "./logger";
+import { SCHEMA_SDL } from "./schemaSdl";
+
+afterEach(() => {
+  delete (globalThis as any).INTROSPECTION_TOKEN;
+  setDebugMode(false);
+});
+
+const SDL = `
+# A place to eat
+type Restaurant {
+  id: ID!
+  # Dishes on the menu
+  dishes(categoryId: ID, first: Int = 10): [Dish!]!
+  menu: [Dish!]! @deprecated(reason: "Use dishes")
+}
+
+type Dish {
+  name: String
+}
+
+enum FulfillmentType {
+  DELIVERY
+  PICKUP
+}
+
+input PlaceOrderInput {
+  fulfillmentType: FulfillmentType = DELIVERY
+}
+
+type Query {
+  restaurants: [Restaurant!]!
+}
+`;
+
+describe("buildIntrospectionSchema", () => {
+  const schema = buildIntrospectionSchema(SDL);
+  const type = (name: string) => schema.types.find((t) => t.name === name)!;
+
+  it("should convert fields, arguments and comments", () => {
+    const restaurant = type("Restaurant");
+    expect(restaurant.kind).toBe("OBJECT");
+    expect(restaurant.description).toBe("A place to eat");
+    const dishes = restaurant.fields![1];
+    expect(dishes.description).toBe("Dishes on the menu");
+    expect(dishes.args.map((arg) => [arg.name, arg.defaultValue])).toEqual([
+      ["categoryId", null],
+      ["first", "10"],
+    ]);
+    expect(dishes.type).toEqual({
+      kind: "NON_NULL",
+      name: null,
+      ofType: {
+        kind: "LIST",
+        name: null,
+        ofType: {
+          kind: "NON_NULL",
+          name: null,
+          ofType: { kind: "OBJECT", name: "Dish", ofType: null },
+        },
+      },
+    });
+  });
+
+  it("should mark deprecated fields", () => {
+    const [id, , menu] = type("Restaurant").fields!;
+    expect(id).toMatchObject({ isDeprecated: false, deprecationReason: null });
+    expect(menu).toMatchObject({ isDeprecated: true, deprecationReason: "Use dishes" });
+  });
+
+  it("should convert enums and input types", () => {
+    expect(type("FulfillmentType").enumValues!.map((v) => v.name)).toEqual([
+      "DELIVERY",
+      "PICKUP",
+    ]);
+    const input = type("PlaceOrderInput").inputFields![0];
+    expect(input.type).toEqual({ kind: "ENUM", name: "FulfillmentType", ofType: null });
+    expect(input.defaultValue).toBe("DELIVERY");
+    expect(schema.mutationType).toBeNull();
+  });
+
+  it("should only reference types that exist in schema.graphql", () => {
+    const full = buildIntrospectionSchema(SCHEMA_SDL);
+    const names = new Set(full.types.map((t) => t.name));
+    const named = (ref: any): string => (ref.ofType ? named(ref.ofType) : ref.name);
+    for (const t of full.types) {
+      for (const field of t.fields || []) {
+        expect(names).toContain(named(field.type));
+        field.args.forEach((arg) => expect(names).toContain(named(arg.type)));
+      }
+      (t.inputFields || []).forEach((f) => expect(names).toContain(named(f.type)));
+    }
+    expect(full.mutationType).toEqual({ name: "Mutation" });
+  });
+
+  it("should embed the current schema.graphql (run npm run schema:sdl)", () => {
+    const sdl = readFileSync(resolve(__dirname, "../schema.graphql"), "utf-8");
+    expect(SCHEMA_SDL).toBe(sdl);
+  });
+});
+
+describe("introspection gate", () => {
+  const request = (token?: string) =>
+    new Request("http://localhost/graphql", {
+      method: "POST",
+      headers: token ? { "X-Introspection-Token": token } : {},
+    });
+
+  it("should detect introspection queries but not __typename", () => {
+    expect(isIntrospectionQuery("query { __schema { types { name } } }")).toBe(true);
+    expect(isIntrospectionQuery('{ __type(name: "Dish") { name } }')).toBe(true);
+    expect(isIntrospectionQuery("{ restaurants { __typename id } }")).toBe(false);
+  });
+
+  it("should require the configured token", () => {
+    expect(hasIntrospectionToken(request("secret"))).toBe(false);
+    (globalThis as any).INTROSPECTION_TOKEN = "secret";
+    expect(hasIntrospectionToken(request("secret"))).toBe(true);
+    expect(hasIntrospectionToken(request("guess"))).toBe(false);
+    expect(hasIntrospectionToken(request())).toBe(false);
+  });
+
+  it("should be forbidden in production without the token", () => {
+    expect(() => resolveIntrospection("{ __schema { types { name } } }", {}, false))
+      .toThrow("introspection is disabled");
+    setDebugMode(true);
+    expect(resolveIntrospection("{ __schema { types { name } } }", {}, false))
+      .toHaveProperty("__schema");
+  });
+
+  it("should resolve __type by name or variable", () => {
+    const query = "query ($name: String!) { __type(name: $name) { name } }";
+    const data = resolveIntrospection(query, { name: "Dish" }, true);
+    expect((data.__type as any).kind).toBe("OBJECT");
+    expect(resolveIntrospection('{ __type(name: "Nope") { name } }', {}, true))
+      .toEqual({ __type: null });
+  });
+});
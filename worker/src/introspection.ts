@@ -0,0 +1,291 @@
+// GraphQL Introspection
+// Answers __schema / __type queries from schema.graphql (embedded as
+// SCHEMA_SDL by scripts/schema-sdl.mjs). Introspection is open in debug mode;
+// otherwise only requests with the X-Introspection-Token header matching
+// INTROSPECTION_TOKEN get it, so frontend tooling and monitoring can
+// introspect production while the public cannot. Token requests skip
+// Telegram auth and can only introspect.
+
+import { getVar } from "./config";
+import { forbiddenError } from "./errors";
+import { isDebugModeEnabled, logger } from "./logger";
+import { SCHEMA_SDL } from "./schemaSdl";
+
+export const INTROSPECTION_TOKEN_HEADER = "X-Introspection-Token";
+
+const BUILT_IN_SCALARS = ["String", "Int", "Float", "Boolean", "ID"];
+
+interface TypeRef {
+  kind: "NON_NULL" | "LIST" | "SCALAR" | "OBJECT" | "INPUT_OBJECT" | "ENUM";
+  name: string | null;
+  ofType: TypeRef | null;
+}
+
+interface InputValue {
+  name: string;
+  description: string | null;
+  type: TypeRef;
+  defaultValue: string | null;
+}
+
+interface IntrospectionType {
+  kind: "SCALAR" | "OBJECT" | "INPUT_OBJECT" | "ENUM";
+  name: string;
+  description: string | null;
+  specifiedByURL: null;
+  fields: Array<{
+    name: string;
+    description: string | null;
+    args: InputValue[];
+    type: TypeRef;
+    isDeprecated: boolean;
+    deprecationReason: string | null;
+  }> | null;
+  inputFields: InputValue[] | null;
+  interfaces: [] | null;
+  enumValues: Array<{
+    name: string;
+    description: string | null;
+    isDeprecated: false;
+    deprecationReason: null;
+  }> | null;
+  possibleTypes: null;
+}
+
+export interface IntrospectionSchema {
+  description: null;
+  queryType: { name: string };
+  mutationType: { name: string } | null;
+  subscriptionType: null;
+  types: IntrospectionType[];
+  directives: Array<{
+    name: string;
+    description: string;
+    isRepeatable: false;
+    locations: string[];
+    args: InputValue[];
+  }>;
+}
+
+/**
+ * Whether a query asks for __schema or __type (__typename is not introspection)
+ */
+export function isIntrospectionQuery(query: string): boolean {
+  return /\b__schema\b|\b__type\s*\(/.test(query);
+}
+
+/**
+ * Whether a request presents the configured introspection token
+ */
+export function hasIntrospectionToken(request: Request): boolean {
+  const token = getVar("INTROSPECTION_TOKEN");
+  return !!token && request.headers.get(INTROSPECTION_TOKEN_HEADER) === token;
+}
+
+function parseTypeRef(value: string, kinds: Map<string, TypeRef["kind"]>): TypeRef {
+  const type = value.trim();
+  if (type.endsWith("!")) {
+    return { kind: "NON_NULL", name: null, ofType: parseTypeRef(type.slice(0, -1), kinds) };
+  }
+  if (type.startsWith("[") && type.endsWith("]")) {
+    return { kind: "LIST", name: null, ofType: parseTypeRef(type.slice(1, -1), kinds) };
+  }
+  return { kind: kinds.get(type) || "SCALAR", name: type, ofType: null };
+}
+
+// "name: Type = DEFAULT" inside an input type or an argument list
+function parseInputValue(
+  value: string,
+  description: string | null,
+  kinds: Map<string, TypeRef["kind"]>,
+): InputValue {
+  const match = value.trim().match(/^(\w+)\s*:\s*([^=]+?)\s*(?:=\s*(.+))?$/);
+  if (!match) {
+    throw new Error(`Unsupported SDL input value "${value}"`);
+  }
+  return {
+    name: match[1],
+    description,
+    type: parseTypeRef(match[2], kinds),
+    defaultValue: match[3] ?? null,
+  };
+}
+
+/**
+ * Build the __schema introspection result from SDL
+ * Supports the subset schema.graphql uses: type/input/enum blocks with one
+ * member per line and # comments directly above as descriptions
+ */
+export function buildIntrospectionSchema(sdl: string): IntrospectionSchema {
+  const lines = sdl.split("\n");
+  const kinds = new Map<string, TypeRef["kind"]>();
+  for (const line of lines) {
+    const match = line.match(/^(type|input|enum)\s+(\w+)/);
+    if (match) {
+      kinds.set(
+        match[2],
+        match[1] === "type" ? "OBJECT" : match[1] === "input" ? "INPUT_OBJECT" : "ENUM",
+      );
+    }
+  }
+
+  const types: IntrospectionType[] = BUILT_IN_SCALARS.map((name) => ({
+    kind: "SCALAR",
+    name,
+    description: null,
+    specifiedByURL: null,
+    fields: null,
+    inputFields: null,
+    interfaces: null,
+    enumValues: null,
+    possibleTypes: null,
+  }));
+
+  let comments: string[] = [];
+  let current: IntrospectionType | null = null;
+  for (const raw of lines) {
+    const line = raw.trim();
+    if (!line) {
+      comments = [];
+      continue;
+    }
+    if (line.startsWith("#")) {
+      comments.push(line.replace(/^#\s?/, ""));
+      continue;
+    }
+    const description = comments.length > 0 ? comments.join("\n") : null;
+    comments = [];
+
+    if (!current) {
+      const match = line.match(/^(type|input|enum)\s+(\w+)\s*\{$/);
+      if (!match) {
+        continue;
+      }
+      const kind = kinds.get(match[2])! as IntrospectionType["kind"];
+      current = {
+        kind,
+        name: match[2],
+        description,
+        specifiedByURL: null,
+        fields: kind === "OBJECT" ? [] : null,
+        inputFields: kind === "INPUT_OBJECT" ? [] : null,
+        interfaces: kind === "OBJECT" ? [] : null,
+        enumValues: kind === "ENUM" ? [] : null,
+        possibleTypes: null,
+      };
+      continue;
+    }
+
+    if (line === "}") {
+      types.push(current);
+      current = null;
+    } else if (current.kind === "ENUM") {
+      current.enumValues!.push({
+        name: line,
+        description,
+        isDeprecated: false,
+        deprecationReason: null,
+      });
+    } else if (current.kind === "INPUT_OBJECT") {
+      current.inputFields!.push(parseInputValue(line, description, kinds));
+    } else {
+      // Fields may end with @deprecated(reason: "...")
+      const deprecated = line.match(/\s*@deprecated(?:\(reason:\s*"([^"]*)"\))?$/);
+      const field = deprecated ? line.slice(0, deprecated.index) : line;
+      const match = field.match(/^(\w+)\s*(?:\((.*)\))?\s*:\s*(.+)$/);
+      if (!match) {
+        throw new Error(`Unsupported SDL field "${line}"`);
+      }
+      current.fields!.push({
+        name: match[1],
+        description,
+        args: match[2]
+          ? match[2].split(",").map((arg) => parseInputValue(arg, null, kinds))
+          : [],
+        type: parseTypeRef(match[3], kinds),
+        isDeprecated: Boolean(deprecated),
+        deprecationReason: deprecated ? (deprecated[1] ?? "No longer supported") : null,
+      });
+    }
+  }
+
+  const ifArg: InputValue = {
+    name: "if",
+    description: null,
+    type: parseTypeRef("Boolean!", kinds),
+    defaultValue: null,
+  };
+  const locations = ["FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"];
+  return {
+    description: null,
+    queryType: { name: "Query" },
+    mutationType: kinds.has("Mutation") ? { name: "Mutation" } : null,
+    subscriptionType: null,
+    types,
+    directives: [
+      {
+        name: "include",
+        description: "Include this field only when the argument is true",
+        isRepeatable: false,
+        locations,
+        args: [ifArg],
+      },
+      {
+        name: "skip",
+        description: "Skip this field when the argument is true",
+        isRepeatable: false,
+        locations,
+        args: [ifArg],
+      },
+      {
+        name: "deprecated",
+        description: "Marks a field as no longer supported",
+        isRepeatable: false,
+        locations: ["FIELD_DEFINITION"],
+        args: [
+          {
+            name: "reason",
+            description: null,
+            type: parseTypeRef("String", kinds),
+            defaultValue: '"No longer supported"',
+          },
+        ],
+      },
+    ],
+  };
+}
+
+let cachedSchema: IntrospectionSchema | null = null;
+
+function getIntrospectionSchema(): IntrospectionSchema {
+  if (!cachedSchema) {
+    cachedSchema = buildIntrospectionSchema(SCHEMA_SDL);
+  }
+  return cachedSchema;
+}
+
+/**
+ * Resolve an introspection query, throwing FORBIDDEN unless it is allowed
+ */
+export function resolveIntrospection(
+  query: string,
+  variables: Record<string, unknown> | undefined,
+  hasToken: boolean,
+): Record<string, unknown> {
+  if (!hasToken && !isDebugModeEnabled()) {
+    logger.authFailure("introspection_disabled");
+    throw forbiddenError("GraphQL introspection is disabled");
+  }
+
+  const schema = getIntrospectionSchema();
+  const data: Record<string, unknown> = {};
+  if (/\b__schema\b/.test(query)) {
+    data.__schema = schema;
+  }
+  const typeArg = query.match(/\b__type\s*\(\s*name\s*:\s*(?:"(\w+)"|\$(\w+))/);
+  if (typeArg) {
+    const name = typeArg[1] ?? variables?.[typeArg[2]];
+    data.__type = schema.types.find((type) => type.name === name) ?? null;
+  }
+  return data;
+}
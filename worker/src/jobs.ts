@@ -0,0 +1,88 @@
+// Job Scheduler
+// Runs periodic background jobs from the Cloudflare cron trigger
+// (see [triggers] in wrangler.toml). Each job is isolated so one
+// failure does not stop the rest.
+
+import { logger } from "./logger";
+import { expireUnpaidOrders } from "./orderState";
+import { runPendingBroadcasts } from "./broadcasts";
+import { sendDailyDigests } from "./ownerDigest";
+import { snapshotDishPrices } from "./priceHistory";
+import { backfillRatingSummaries } from "./reviews";
+import { foldOperationHits } from "./operationAudit";
+
+export interface ScheduledJob {
+  name: string;
+  run: (now: Date) => Promise<unknown>;
+}
+
+export interface JobRunResult {
+  name: string;
+  success: boolean;
+  durationMs: number;
+  result?: unknown;
+  error?: string;
+}
+
+/**
+ * Jobs executed on every cron tick
+ */
+export const SCHEDULED_JOBS: ScheduledJob[] = [
+  { name: "expire_unpaid_orders", run: expireUnpaidOrders },
+  { name: "send_broadcasts", run: runPendingBroadcasts },
+  { name: "send_daily_digests", run: sendDailyDigests },
+  { name: "snapshot_dish_prices", run: snapshotDishPrices },
+  { name: "backfill_rating_summaries", run: backfillRatingSummaries },
+  { name: "fold_operation_audit", run: foldOperationHits },
+];
+
+let lastRun: { ranAt: string; results: JobRunResult[] } | null = null;
+
+/**
+ * Results of the latest cron tick in this isolate (lifecycle health)
+ */
+export function getLastJobRun(): { ranAt: string; results: JobRunResult[] } | null {
+  return lastRun;
+}
+
+/**
+ * Run all scheduled jobs sequentially
+ */
+export async function runScheduledJobs(
+  now: Date = new Date(),
+  jobs: ScheduledJob[] = SCHEDULED_JOBS,
+): Promise<JobRunResult[]> {
+  const results: JobRunResult[] = [];
+
+  for (const job of jobs) {
+    const startedAt = Date.now();
+    try {
+      const result = await job.run(now);
+      results.push({
+        name: job.name,
+        success: true,
+        durationMs: Date.now() - startedAt,
+        result,
+      });
+      logger.info("job_completed", {
+        job: job.name,
+        durationMs: Date.now() - startedAt,
+        result,
+      });
+    } catch (error) {
+      const message = error instanceof Error ? error.message : "Unknown error";
+      results.push({
+        name: job.name,
+        success: false,
+        durationMs: Date.now() - startedAt,
+        error: message,
+      });
+      logger.error("job_failed", { job: job.name, error: message });
+    }
+  }
+
+  if (jobs === SCHEDULED_JOBS) {
+    lastRun = { ranAt: now.toISOString(), results };
+  }
+  return results;
+}
@@ -0,0 +1,82 @@
+// Kitchen Capacity Tests
+// Tests for kitchenCapacity.ts - active order caps and AT_CAPACITY
+
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import { clearOrders, createSaleorOrder } from "./saleorOrder";
+import {
+  assertKitchenCapacity,
+  countActiveOrders,
+  getMaxActiveOrders,
+  isRestaurantBusy,
+} from "./kitchenCapacity";
+import { PlaceOrderInput } from "./contracts";
+
+vi.mock("./logger", () => ({
+  logger: {
+    info: vi.fn(),
+    error: vi.fn(),
+    warn: vi.fn(),
+    debug: vi.fn(),
+  },
+}));
+
+const orderInput: PlaceOrderInput = {
+  restaurantId: "restA",
+  deliveryLocation: { address: "1 Test Street" },
+  items: [{ dishId: "dish1", quantity: 1 }],
+};
+
+describe("getMaxActiveOrders", () => {
+  it("should read a positive whole cap", () => {
+    expect(getMaxActiveOrders({ tma_max_active_orders: "8" })).toBe(8);
+    expect(getMaxActiveOrders({ tma_max_active_orders: "0" })).toBeNull();
+    expect(getMaxActiveOrders({ tma_max_active_orders: "2.5" })).toBeNull();
+    expect(getMaxActiveOrders(undefined)).toBeNull();
+  });
+});
+
+describe("kitchen capacity", () => {
+  beforeEach(async () => {
+    clearOrders();
+    await createSaleorOrder(orderInput, "cap-user-1");
+    await createSaleorOrder(orderInput, "cap-user-2");
+    // Scheduled well past the prep time, so not in the kitchen yet
+    await createSaleorOrder(
+      { ...orderInput, scheduledFor: new Date(Date.now() + 3 * 60 * 60 * 1000).toISOString() },
+      "cap-user-3",
+    );
+  });
+
+  it("should count placed orders that load the kitchen", async () => {
+    expect(await countActiveOrders("restA", 20)).toBe(2);
+    expect(await countActiveOrders("restB", 20)).toBe(0);
+  });
+
+  it("should count orders scheduled days ahead once their prep starts", async () => {
+    const dueAt = new Date(Date.now() + 2 * 24 * 60 * 60 * 1000);
+    await createSaleorOrder({ ...orderInput, scheduledFor: dueAt.toISOString() }, "cap-user-4");
+    // Two days on, the ASAP orders are stale and only the due order cooks
+    const now = new Date(dueAt.getTime() - 10 * 60 * 1000);
+    expect(await countActiveOrders("restA", 20, now)).toBe(1);
+  });
+
+  it("should turn orders away at the cap with a retry estimate", async () => {
+    const channel = {
+      id: "restA",
+      metadata: { tma_max_active_orders: "2", tma_prep_minutes: "25" },
+    };
+    await expect(assertKitchenCapacity(channel)).rejects.toMatchObject({
+      code: "AT_CAPACITY",
+      retryAfterMinutes: 25,
+    });
+    expect(await isRestaurantBusy(channel)).toBe(true);
+  });
+
+  it("should accept orders below the cap or without one", async () => {
+    await expect(
+      assertKitchenCapacity({ id: "restA", metadata: { tma_max_active_orders: "3" } }),
+    ).resolves.toBeUndefined();
+    await expect(assertKitchenCapacity({ id: "restA", metadata: {} })).resolves.toBeUndefined();
+    expect(await isRestaurantBusy({ id: "restA", metadata: {} })).toBe(false);
+  });
+});
@@ -0,0 +1,146 @@
+// Kitchen Capacity
+// Restaurants can cap concurrent active orders (tma_max_active_orders
+// channel metadata; unset or 0 means no cap). Active means placed or being
+// prepared now: orders awaiting payment, out for delivery or scheduled
+// beyond the prep time don't load the kitchen. At the cap placeOrder fails
+// with AT_CAPACITY and a retry estimate of one prep cycle, and listings
+// show the restaurant as busy. Listing counts are cached for a minute
+// (kitchen-load:<restaurantId>); placeOrder always counts afresh.
+
+import { Channel, KitchenCapacity, Restaurant } from "./contracts";
+import { atCapacityError, badUserInputError, notFoundError } from "./errors";
+import { getPrepMinutes } from "./eta";
+import { logger } from "./logger";
+import { parseNumberValue } from "./metadata";
+import { ORDER_METADATA_KEYS, fetchOrders, getNormalizedStatus } from "./saleorOrder";
+import { fetchChannelById, fetchChannels, updateChannelMetadata } from "./saleorService";
+import { getScheduledOrderLimits } from "./scheduledOrders";
+import { readJSON, writeJSON } from "./storage";
+
+export const MAX_ACTIVE_ORDERS_METADATA_KEY = "tma_max_active_orders";
+
+const LOAD_PREFIX = "kitchen-load:";
+const LOAD_CACHE_TTL_SECONDS = 60;
+// Orders older than this are stale rather than in the kitchen
+const ACTIVE_WINDOW_HOURS = 24;
+
+/**
+ * Concurrent active order cap from channel metadata, null when uncapped
+ */
+export function getMaxActiveOrders(metadata: Record<string, string> | undefined): number | null {
+  const value = parseNumberValue(metadata?.[MAX_ACTIVE_ORDERS_METADATA_KEY]);
+  return value !== null && Number.isInteger(value) && value > 0 ? value : null;
+}
+
+/**
+ * Count a restaurant's orders currently loading the kitchen
+ */
+export async function countActiveOrders(
+  restaurantId: string,
+  prepMinutes: number,
+  now: Date = new Date(),
+): Promise<number> {
+  const activeFrom = now.getTime() - ACTIVE_WINDOW_HOURS * 60 * 60 * 1000;
+  // Scheduled orders can be placed up to the scheduling horizon before they cook
+  const { maxDaysAhead } = getScheduledOrderLimits();
+  const orders = await fetchOrders({
+    channelId: restaurantId,
+    createdFrom: new Date(activeFrom - maxDaysAhead * 24 * 60 * 60 * 1000)
+      .toISOString()
+      .slice(0, 10),
+  });
+  const startsBy = now.getTime() + prepMinutes * 60 * 1000;
+  const active = orders.filter((order) => {
+    const status = getNormalizedStatus(order);
+    if (status !== "PLACED" && status !== "PREPARING") {
+      return false;
+    }
+    // ASAP orders load the kitchen from when they're placed, scheduled ones
+    // from one prep time before their slot
+    const scheduledFor = order.metadata?.[ORDER_METADATA_KEYS.scheduledFor];
+    const startsAt = new Date(scheduledFor || order.createdAt).getTime();
+    return startsAt >= activeFrom && startsAt <= startsBy;
+  }).length;
+
+  await writeJSON(`${LOAD_PREFIX}${restaurantId}`, active, {
+    expirationTtl: LOAD_CACHE_TTL_SECONDS,
+  });
+  return active;
+}
+
+/**
+ * Whether a capped restaurant is at capacity (cached count, for listings)
+ */
+export async function isRestaurantBusy(
+  channel: Pick<Channel, "id" | "metadata">,
+  now: Date = new Date(),
+): Promise<boolean> {
+  const max = getMaxActiveOrders(channel.metadata);
+  if (max === null) {
+    return false;
+  }
+  const cached = await readJSON<number>(`${LOAD_PREFIX}${channel.id}`);
+  const active =
+    cached ?? (await countActiveOrders(channel.id, getPrepMinutes(channel.metadata), now));
+  return active >= max;
+}
+
+/**
+ * Set Restaurant.busy on listed restaurants
+ */
+export async function withBusyFlags(restaurants: Restaurant[]): Promise<Restaurant[]> {
+  const channels = new Map((await fetchChannels()).map((ch) => [ch.id, ch]));
+  return Promise.all(
+    restaurants.map(async (restaurant) => {
+      const channel = channels.get(restaurant.id);
+      return { ...restaurant, busy: channel ? await isRestaurantBusy(channel) : false };
+    }),
+  );
+}
+
+/**
+ * Refuse a new order while the kitchen is at capacity
+ */
+export async function assertKitchenCapacity(
+  channel: Pick<Channel, "id" | "metadata"> | null,
+  now: Date = new Date(),
+): Promise<void> {
+  const max = channel ? getMaxActiveOrders(channel.metadata) : null;
+  if (!channel || max === null) {
+    return;
+  }
+  const prepMinutes = getPrepMinutes(channel.metadata);
+  const active = await countActiveOrders(channel.id, prepMinutes, now);
+  if (active >= max) {
+    logger.info("kitchen_at_capacity", { restaurantId: channel.id, active, max });
+    throw atCapacityError(Math.max(1, prepMinutes));
+  }
+}
+
+/**
+ * Set or clear (null or 0) a restaurant's active order cap
+ */
+export async function setKitchenCapacity(
+  restaurantId: string,
+  maxActiveOrders: number | null | undefined,
+  updatedBy: string,
+): Promise<KitchenCapacity> {
+  const channel = await fetchChannelById(restaurantId);
+  if (!channel) {
+    throw notFoundError("Restaurant not found");
+  }
+  const max = maxActiveOrders || null;
+  if (max !== null && (!Number.isInteger(max) || max < 0)) {
+    throw badUserInputError("Capacity must be a positive whole number", "maxActiveOrders");
+  }
+
+  const saved = await updateChannelMetadata(restaurantId, {
+    [MAX_ACTIVE_ORDERS_METADATA_KEY]: max === null ? "" : String(max),
+  });
+  if (!saved) {
+    throw badUserInputError("Could not save the capacity, please try again");
+  }
+
+  logger.info("kitchen_capacity_updated", { restaurantId, maxActiveOrders: max, updatedBy });
+  return { restaurantId, maxActiveOrders: max };
+}
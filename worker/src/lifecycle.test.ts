@@ -0,0 +1,127 @@
+// Lifecycle Tests
+// Tests for lifecycle.ts - ordered start/stop and readiness
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { SaleorFetch, initializeSaleorClient } from "./saleorClient";
+import { resetSaleorPing } from "./saleorPing";
+import {
+  getComponentHealth,
+  handleReadinessRequest,
+  isReady,
+  LifecycleComponent,
+  startLifecycle,
+  stopLifecycle,
+} from "./lifecycle";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  setDebugMode: vi.fn(),
+  isDebugModeEnabled: () => false,
+}));
+
+function recording(calls: string[], name: string, extra: Partial<LifecycleComponent> = {}) {
+  return {
+    name,
+    required: true,
+    start: () => {
+      calls.push(`start:${name}`);
+    },
+    stop: () => {
+      calls.push(`stop:${name}`);
+    },
+    ...extra,
+  };
+}
+
+afterEach(async () => {
+  await stopLifecycle([]);
+});
+
+describe("lifecycle", () => {
+  it("should start once in order and stop in reverse", async () => {
+    const calls: string[] = [];
+    const components = [recording(calls, "config"), recording(calls, "saleor")];
+    await startLifecycle(components);
+    await startLifecycle(components);
+    await stopLifecycle(components);
+    expect(calls).toEqual(["start:config", "start:saleor", "stop:saleor", "stop:config"]);
+  });
+
+  it("should report a failed start, start the rest and retry it later", async () => {
+    const calls: string[] = [];
+    let configFails = true;
+    const components = [
+      recording(calls, "config", {
+        start: () => {
+          if (configFails) {
+            throw new Error("bad config");
+          }
+          calls.push("start:config");
+        },
+      }),
+      recording(calls, "saleor"),
+    ];
+    await startLifecycle(components);
+
+    expect(calls).toEqual(["start:saleor"]);
+    expect(getComponentHealth(components)).toEqual([
+      { name: "config", status: "DOWN", detail: "Start failed: bad config" },
+      { name: "saleor", status: "UP" },
+    ]);
+    expect(isReady(components)).toBe(false);
+
+    configFails = false;
+    await startLifecycle(components);
+    expect(calls).toEqual(["start:saleor", "start:config"]);
+    expect(isReady(components)).toBe(true);
+  });
+
+  it("should stay ready while an optional component is degraded", async () => {
+    const components: LifecycleComponent[] = [
+      { name: "config", required: true },
+      {
+        name: "storage",
+        health: () => ({ status: "DEGRADED", detail: "memory" }),
+      },
+    ];
+    await startLifecycle(components);
+    expect(getComponentHealth(components)[1].status).toBe("DEGRADED");
+    expect(isReady(components)).toBe(true);
+  });
+});
+
+describe("handleReadinessRequest", () => {
+  it("should report the default components", async () => {
+    const response = await handleReadinessRequest();
+    const body = (await response.json()) as { status: string; components: { name: string }[] };
+    expect(response.status).toBe(200);
+    expect(body.status).toBe("ready");
+    expect(body.components.map((c) => c.name)).toEqual([
+      "config",
+      "saleor",
+      "storage",
+      "scheduler",
+    ]);
+  });
+
+  it("should answer 503 while Saleor is unreachable with SALEOR_PING_FAIL_FAST", async () => {
+    const send = vi.fn<SaleorFetch>(async () => Response.json({ data: { shop: null } }));
+    (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+    (globalThis as any).SALEOR_API_URL = "https://saleor.test/graphql/";
+    (globalThis as any).SALEOR_TOKEN = "t";
+    (globalThis as any).SALEOR_PING_FAIL_FAST = "true";
+    try {
+      expect((await handleReadinessRequest()).status).toBe(503);
+
+      send.mockImplementation(async () => Response.json({ data: { shop: { name: "Eats" } } }));
+      expect((await handleReadinessRequest()).status).toBe(200);
+    } finally {
+      initializeSaleorClient({});
+      resetSaleorPing();
+      delete (globalThis as any).SALEOR_TRANSPORT;
+      delete (globalThis as any).SALEOR_API_URL;
+      delete (globalThis as any).SALEOR_TOKEN;
+      delete (globalThis as any).SALEOR_PING_FAIL_FAST;
+    }
+  });
+});
@@ -0,0 +1,232 @@
+// Lifecycle
+// A Worker has no long-running process: an isolate starts on its first
+// fetch or cron event and is dropped without notice. Subsystems register
+// here as components in start order; the first event of an isolate starts
+// them once, and a component that failed to start is retried on the next
+// event without holding back the others. Stopping runs in reverse order
+// (tests and local tooling), and each component reports its health for
+// GET /readyz, which answers 503 while a required component is down.
+// Components with a probe refresh their health before /readyz reports it.
+
+import { getHealthHints } from "./health";
+import { getLastJobRun, SCHEDULED_JOBS } from "./jobs";
+import { runInBackground } from "./backgroundTasks";
+import { logger, setDebugMode } from "./logger";
+import { initializeSaleorClient, isSaleorConfigured } from "./saleorClient";
+import {
+  getLastSaleorPing,
+  isSaleorPingFailFast,
+  pingSaleor,
+  refreshSaleorPing,
+} from "./saleorPing";
+import { isKVAvailable } from "./storage";
+
+export const READYZ_PATH = "/readyz";
+
+export type ComponentStatus = "UP" | "DEGRADED" | "DOWN";
+
+export interface ComponentHealth {
+  name: string;
+  status: ComponentStatus;
+  detail?: string;
+}
+
+export interface LifecycleComponent {
+  name: string;
+  // A required component that is down makes /readyz fail
+  required?: boolean;
+  start?: () => void | Promise<void>;
+  stop?: () => void | Promise<void>;
+  health?: () => Omit<ComponentHealth, "name">;
+  // Run by /readyz before health is read; must not throw
+  probe?: () => Promise<void>;
+}
+
+/**
+ * Components in start order
+ */
+export const COMPONENTS: LifecycleComponent[] = [
+  {
+    name: "config",
+    required: true,
+    // Cloudflare injects vars into globalThis (service-worker format)
+    start: () => setDebugMode((globalThis as any).DEBUG === "true"),
+  },
+  {
+    name: "saleor",
+    required: true,
+    start: () => {
+      initializeSaleorClient({
+        SALEOR_API_URL: (globalThis as any).SALEOR_API_URL,
+        SALEOR_TOKEN: (globalThis as any).SALEOR_TOKEN,
+      });
+      runInBackground(() => pingSaleor(), "saleor_ping_failed");
+    },
+    probe: () => refreshSaleorPing(),
+    health: () => {
+      if (!isSaleorConfigured()) {
+        return { status: "DEGRADED", detail: "Not configured, serving mock data" };
+      }
+      const ping = getLastSaleorPing();
+      if (ping && !ping.ok) {
+        return {
+          status: isSaleorPingFailFast() ? "DOWN" : "DEGRADED",
+          detail: `Ping failed: ${ping.error}`,
+        };
+      }
+      return getHealthHints().saleorDegraded
+        ? { status: "DEGRADED", detail: "Recent Saleor failures" }
+        : { status: "UP" };
+    },
+  },
+  {
+    name: "storage",
+    health: () =>
+      isKVAvailable()
+        ? { status: "UP" }
+        : { status: "DEGRADED", detail: "No KV binding, using isolate memory" },
+  },
+  {
+    name: "scheduler",
+    health: () => {
+      const lastRun = getLastJobRun();
+      const failed = lastRun?.results.filter((result) => !result.success) || [];
+      return failed.length > 0
+        ? { status: "DEGRADED", detail: `Failed: ${failed.map((r) => r.name).join(", ")}` }
+        : { status: "UP", detail: `${SCHEDULED_JOBS.length} jobs` };
+    },
+  },
+];
+
+interface ComponentState {
+  started: boolean;
+  error?: string;
+}
+
+let starting: Promise<void> | null = null;
+const states = new Map<string, ComponentState>();
+
+/**
+ * Start components in order, once per isolate
+ * A failed start shows in /readyz and is retried on the next call; the
+ * components after it still start
+ */
+export function startLifecycle(components: LifecycleComponent[] = COMPONENTS): Promise<void> {
+  if (!starting) {
+    starting = startComponents(components).then((failed) => {
+      if (failed) {
+        starting = null;
+      }
+    });
+  }
+  return starting;
+}
+
+// Starts the components not started yet; true when one of them failed
+async function startComponents(components: LifecycleComponent[]): Promise<boolean> {
+  let failed = false;
+  for (const component of components) {
+    if (states.get(component.name)?.started) {
+      continue;
+    }
+    try {
+      await component.start?.();
+      states.set(component.name, { started: true });
+    } catch (error) {
+      const message = error instanceof Error ? error.message : "Unknown error";
+      states.set(component.name, { started: false, error: message });
+      logger.error("component_start_failed", { component: component.name, error: message });
+      failed = true;
+    }
+  }
+  return failed;
+}
+
+/**
+ * Stop started components in reverse order
+ */
+export async function stopLifecycle(components: LifecycleComponent[] = COMPONENTS): Promise<void> {
+  await starting;
+  for (const component of [...components].reverse()) {
+    if (!states.get(component.name)?.started) {
+      continue;
+    }
+    try {
+      await component.stop?.();
+    } catch (error) {
+      logger.error("component_stop_failed", {
+        component: component.name,
+        error: error instanceof Error ? error.message : "Unknown error",
+      });
+    }
+  }
+  states.clear();
+  starting = null;
+}
+
+/**
+ * Health of every component; ones that didn't start are down
+ */
+export function getComponentHealth(
+  components: LifecycleComponent[] = COMPONENTS,
+): ComponentHealth[] {
+  return components.map((component) => {
+    const state = states.get(component.name);
+    if (!state?.started) {
+      return {
+        name: component.name,
+        status: "DOWN",
+        detail: state?.error ? `Start failed: ${state.error}` : "Not started",
+      };
+    }
+    return { name: component.name, ...(component.health?.() ?? { status: "UP" }) };
+  });
+}
+
+/**
+ * Run the probes of started components
+ */
+export async function probeComponents(
+  components: LifecycleComponent[] = COMPONENTS,
+): Promise<void> {
+  await Promise.all(
+    components
+      .filter((component) => states.get(component.name)?.started)
+      .map(async (component) => {
+        try {
+          await component.probe?.();
+        } catch (error) {
+          logger.error("component_probe_failed", {
+            component: component.name,
+            error: error instanceof Error ? error.message : "Unknown error",
+          });
+        }
+      }),
+  );
+}
+
+/**
+ * Whether every required component is up (degraded still serves traffic)
+ */
+export function isReady(components: LifecycleComponent[] = COMPONENTS): boolean {
+  const health = getComponentHealth(components);
+  return components.every(
+    (component, i) => !component.required || health[i].status !== "DOWN",
+  );
+}
+
+/**
+ * GET /readyz
+ */
+export async function handleReadinessRequest(): Promise<Response> {
+  await startLifecycle();
+  await probeComponents();
+  const ready = isReady();
+  return new Response(
+    JSON.stringify({ status: ready ? "ready" : "not_ready", components: getComponentHealth() }),
+    {
+      status: ready ? 200 : 503,
+      headers: { "Content-Type": "application/json", "Cache-Control": "no-store" },
+    },
+  );
+}
@@ -0,0 +1,22 @@
+// Log Context
+// Request-scoped fields (tenant, restaurantId) added to every log entry
+// written while handling them, so a restaurant's errors can be found in
+// the logs. AsyncLocalStorage, like saleorTargets.ts.
+
+import { AsyncLocalStorage } from "node:async_hooks";
+
+const contextStorage = new AsyncLocalStorage<Record<string, string>>();
+
+/**
+ * Run work with fields added to every log entry it writes
+ */
+export function runWithLogContext<T>(context: Record<string, string>, fn: () => T): T {
+  return contextStorage.run({ ...contextStorage.getStore(), ...context }, fn);
+}
+
+/**
+ * Fields of the current log context (empty outside one)
+ */
+export function getLogContext(): Record<string, string> {
+  return contextStorage.getStore() ?? {};
+}
@@ -1,5 +1,8 @@
 // Phase 7: Structured logging with minimal PII
 // Aligns with SEC_AGENT.md
+// Entries carry the request's log context (logContext.ts) when one is set.
+
+import { getLogContext } from "./logContext";
 
 export enum LogLevel {
   DEBUG = "debug",
@@ -37,6 +40,7 @@ function createLogEntry(
     level,
     event,
     timestamp: new Date().toISOString(),
+    ...getLogContext(),
     ...extra,
   };
 }
@@ -0,0 +1,113 @@
+// Loyalty Points Tests
+// Tests for loyalty.ts - accrual rate, redemption caps and mock balances
+
+import { describe, it, expect, vi, afterEach, beforeEach } from "vitest";
+import {
+  adjustLoyaltyPoints,
+  awardOrderPoints,
+  computeLoyaltyRedemption,
+  getLoyaltyBalance,
+  getLoyaltyPoints,
+  pointsForAmount,
+  releaseLoyaltyPoints,
+  reserveLoyaltyPoints,
+  restoreRedeemedPoints,
+} from "./loyalty";
+import { PlaceOrderInput } from "./contracts";
+import { cancelUserOrder } from "./paymentHolds";
+import { clearOrders, createSaleorOrder } from "./saleorOrder";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+const orderInput: PlaceOrderInput = {
+  restaurantId: "restA",
+  deliveryLocation: { address: "1 Test Street" },
+  items: [{ dishId: "dish1", quantity: 1 }],
+};
+
+afterEach(() => {
+  delete (globalThis as any).LOYALTY_ENABLED;
+  delete (globalThis as any).LOYALTY_POINTS_PER_UNIT;
+  delete (globalThis as any).LOYALTY_POINT_VALUE;
+});
+
+describe("pointsForAmount", () => {
+  it("should round earned points down", () => {
+    expect(pointsForAmount(19.99)).toBe(19);
+    (globalThis as any).LOYALTY_POINTS_PER_UNIT = "100";
+    expect(pointsForAmount(19.99)).toBe(1999);
+  });
+
+  it("should earn nothing for empty orders", () => {
+    expect(pointsForAmount(0)).toBe(0);
+  });
+});
+
+describe("computeLoyaltyRedemption", () => {
+  it("should spend the whole balance on larger orders", () => {
+    expect(computeLoyaltyRedemption(500, 20, "USD")).toEqual({
+      points: 500,
+      discount: 5,
+    });
+  });
+
+  it("should cap the discount at the order total", () => {
+    expect(computeLoyaltyRedemption(5000, 12.34, "USD")).toEqual({
+      points: 1234,
+      discount: 12.34,
+    });
+  });
+});
+
+describe("mock balances", () => {
+  it("should accrue and spend points", async () => {
+    (globalThis as any).LOYALTY_ENABLED = "true";
+    await adjustLoyaltyPoints("loyal-user", 300, "test");
+    await adjustLoyaltyPoints("loyal-user", -100, "test");
+    expect(await getLoyaltyBalance("loyal-user")).toEqual({
+      enabled: true,
+      points: 200,
+      pointValue: 0.01,
+      value: 2,
+    });
+  });
+
+  it("should refuse to overspend", async () => {
+    await expect(adjustLoyaltyPoints("broke-user", -1, "test")).rejects.toThrow(
+      "Not enough loyalty points",
+    );
+  });
+});
+
+describe("points on orders", () => {
+  beforeEach(() => {
+    clearOrders();
+    (globalThis as any).LOYALTY_ENABLED = "true";
+  });
+
+  it("should reserve the balance and take back what an order didn't use", async () => {
+    await adjustLoyaltyPoints("reserving-user", 300, "test");
+    expect(await reserveLoyaltyPoints("reserving-user")).toBe(300);
+    // A concurrent order finds nothing left to spend
+    await expect(reserveLoyaltyPoints("reserving-user")).rejects.toThrow("no loyalty points");
+    await releaseLoyaltyPoints("reserving-user", 100, "test");
+    expect(await getLoyaltyPoints("reserving-user")).toBe(100);
+  });
+
+  it("should restore redeemed points once when the order is cancelled", async () => {
+    const placed = await createSaleorOrder({ ...orderInput, loyaltyPoints: 200 }, "refund-user");
+    await cancelUserOrder(placed.order!.id, "refund-user", null);
+    expect(await getLoyaltyPoints("refund-user")).toBe(200);
+    expect(await restoreRedeemedPoints(placed.order!.id)).toBe(0);
+    expect(await getLoyaltyPoints("refund-user")).toBe(200);
+  });
+
+  it("should award a fulfilled order's points once", async () => {
+    const placed = await createSaleorOrder(orderInput, "award-user");
+    expect(await awardOrderPoints(placed.order!.id)).toBe(10);
+    expect(await awardOrderPoints(placed.order!.id)).toBe(0);
+    expect(await getLoyaltyPoints("award-user")).toBe(10);
+  });
+});
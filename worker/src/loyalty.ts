@@ -0,0 +1,407 @@
+// Loyalty Points
+// Customers earn LOYALTY_POINTS_PER_UNIT points per currency unit of a
+// fulfilled order (order_fulfilled webhook, tips excluded) and spend them
+// with placeOrder's redeemPoints, each point worth LOYALTY_POINT_VALUE.
+// Balances live in the private metadata (tma.loyaltyPoints) of the Saleor
+// customer linked by tma.telegramUserId, created on first accrual.
+// Redemptions are manual order discounts, which Saleor only allows on draft
+// orders, so those orders always use the draft pipeline. placeOrder debits
+// the balance before the order is created and credits back what the order
+// didn't use; cancelled and expired orders get their points back.
+
+import { LoyaltyBalance } from "./contracts";
+import { getBooleanVar, getNumberVar } from "./config";
+import { buildPlaceholderEmail, CUSTOMER_TELEGRAM_METADATA_KEY } from "./customerEmail";
+import { badUserInputError } from "./errors";
+import { logger } from "./logger";
+import { metadataToRecord, recordToMetadataInput } from "./metadata";
+import { roundMoney, subtractMoney } from "./money";
+import {
+  getSaleorClient,
+  isSaleorConfigured,
+  LOYALTY_CUSTOMER_QUERY,
+  CUSTOMER_CREATE_MUTATION,
+  UPDATE_PRIVATE_METADATA_MUTATION,
+  ORDER_DISCOUNT_ADD_MUTATION,
+} from "./saleorClient";
+import {
+  CustomerCreateMutation,
+  CustomerCreateMutationVariables,
+  LoyaltyCustomerQuery,
+  LoyaltyCustomerQueryVariables,
+  OrderDiscountAddMutation,
+  OrderDiscountAddMutationVariables,
+  UpdatePrivateMetadataMutation,
+  UpdatePrivateMetadataMutationVariables,
+} from "./saleorOperations";
+import {
+  ORDER_METADATA_KEYS,
+  SaleorOrder,
+  fetchOrderById,
+  getTipAmount,
+  updateOrderMetadata,
+  updateOrderMetadataWith,
+} from "./saleorOrder";
+
+// Saleor customer private metadata key holding the point balance
+export const LOYALTY_POINTS_METADATA_KEY = "tma.loyaltyPoints";
+
+// Balances for mock data, when Saleor is not configured
+const mockBalances = new Map<string, number>();
+
+/**
+ * Whether loyalty points are earned and redeemable (LOYALTY_ENABLED)
+ */
+export function isLoyaltyEnabled(): boolean {
+  return getBooleanVar("LOYALTY_ENABLED");
+}
+
+/**
+ * Currency value of one point (LOYALTY_POINT_VALUE, default 0.01)
+ */
+export function getPointValue(): number {
+  const value = getNumberVar("LOYALTY_POINT_VALUE", 0.01);
+  return value > 0 ? value : 0.01;
+}
+
+/**
+ * Points earned for an amount spent (LOYALTY_POINTS_PER_UNIT, default 1)
+ */
+export function pointsForAmount(amount: number): number {
+  const rate = getNumberVar("LOYALTY_POINTS_PER_UNIT", 1);
+  if (!(rate > 0) || !(amount > 0)) {
+    return 0;
+  }
+  // Tolerate float noise such as 19.99 * 100 = 1998.9999999999998
+  return Math.floor(amount * rate + 1e-9);
+}
+
+/**
+ * Points to spend and the discount they give on an order total
+ */
+export function computeLoyaltyRedemption(
+  availablePoints: number,
+  orderTotal: number,
+  currency: string,
+): { points: number; discount: number } {
+  const value = getPointValue();
+  if (availablePoints <= 0 || orderTotal <= 0) {
+    return { points: 0, discount: 0 };
+  }
+  const discount = roundMoney(
+    Math.min(availablePoints * value, orderTotal),
+    currency,
+  );
+  const points = Math.min(
+    availablePoints,
+    Math.ceil(discount / value - 1e-9),
+  );
+  return { points, discount };
+}
+
+interface LoyaltyCustomer {
+  id: string;
+  points: number;
+}
+
+async function fetchLoyaltyCustomer(userId: string): Promise<LoyaltyCustomer | null> {
+  const client = getSaleorClient();
+  if (!client) {
+    return null;
+  }
+  const variables: LoyaltyCustomerQueryVariables = {
+    filter: {
+      metadata: [{ key: CUSTOMER_TELEGRAM_METADATA_KEY, value: userId }],
+    },
+  };
+  const response = await client.execute<LoyaltyCustomerQuery>(LOYALTY_CUSTOMER_QUERY, variables);
+  if (response.errors && response.errors.length > 0) {
+    throw new Error(response.errors.map((e) => e.message).join(", "));
+  }
+  const node = response.data?.customers?.edges?.[0]?.node;
+  if (!node) {
+    return null;
+  }
+  const points = Number(
+    metadataToRecord(node.privateMetadata)[LOYALTY_POINTS_METADATA_KEY],
+  );
+  return { id: node.id, points: Number.isFinite(points) ? points : 0 };
+}
+
+async function createLoyaltyCustomer(userId: string): Promise<LoyaltyCustomer> {
+  const client = getSaleorClient();
+  if (!client) {
+    throw new Error("Saleor is not configured");
+  }
+  const variables: CustomerCreateMutationVariables = {
+    input: {
+      email: buildPlaceholderEmail(userId),
+      isActive: true,
+      metadata: [{ key: CUSTOMER_TELEGRAM_METADATA_KEY, value: userId }],
+    },
+  };
+  const response = await client.execute<CustomerCreateMutation>(
+    CUSTOMER_CREATE_MUTATION,
+    variables,
+  );
+  const errors = [
+    ...(response.errors || []).map((e) => e.message),
+    ...(response.data?.customerCreate?.errors || []).map((e) => e.message),
+  ];
+  const user = response.data?.customerCreate?.user;
+  if (errors.length > 0 || !user) {
+    throw new Error(errors.join(", ") || "customerCreate returned no user");
+  }
+  return { id: user.id, points: 0 };
+}
+
+/**
+ * Current point balance of a Telegram user
+ */
+export async function getLoyaltyPoints(userId: string): Promise<number> {
+  if (!isSaleorConfigured()) {
+    return mockBalances.get(userId) || 0;
+  }
+  const customer = await fetchLoyaltyCustomer(userId);
+  return customer?.points || 0;
+}
+
+/**
+ * Loyalty balance shown to the user
+ */
+export async function getLoyaltyBalance(userId: string): Promise<LoyaltyBalance> {
+  const enabled = isLoyaltyEnabled();
+  const points = enabled ? await getLoyaltyPoints(userId) : 0;
+  const pointValue = getPointValue();
+  return {
+    enabled,
+    points,
+    pointValue,
+    value: Math.round(points * pointValue * 100) / 100,
+  };
+}
+
+/**
+ * Add (positive) or spend (negative) points, returning the new balance
+ * Balances are read-modify-write; a spend larger than the balance fails
+ */
+export async function adjustLoyaltyPoints(
+  userId: string,
+  delta: number,
+  reason: string,
+): Promise<number> {
+  if (!isSaleorConfigured()) {
+    const balance = (mockBalances.get(userId) || 0) + delta;
+    if (balance < 0) {
+      throw badUserInputError("Not enough loyalty points", "redeemPoints");
+    }
+    mockBalances.set(userId, balance);
+    return balance;
+  }
+
+  let customer = await fetchLoyaltyCustomer(userId);
+  if (!customer) {
+    if (delta < 0) {
+      throw badUserInputError("Not enough loyalty points", "redeemPoints");
+    }
+    customer = await createLoyaltyCustomer(userId);
+  }
+  const balance = customer.points + delta;
+  if (balance < 0) {
+    throw badUserInputError("Not enough loyalty points", "redeemPoints");
+  }
+
+  const client = getSaleorClient()!;
+  const variables: UpdatePrivateMetadataMutationVariables = {
+    id: customer.id,
+    input: recordToMetadataInput({
+      [LOYALTY_POINTS_METADATA_KEY]: String(balance),
+    }),
+  };
+  const response = await client.execute<UpdatePrivateMetadataMutation>(
+    UPDATE_PRIVATE_METADATA_MUTATION,
+    variables,
+  );
+  const errors = [
+    ...(response.errors || []).map((e) => e.message),
+    ...(response.data?.updatePrivateMetadata?.errors || []).map((e) => e.message),
+  ];
+  if (errors.length > 0) {
+    throw new Error(errors.join(", "));
+  }
+
+  logger.info("loyalty_points_adjusted", { userId, delta, balance, reason });
+  return balance;
+}
+
+/**
+ * Points a user can redeem on placeOrder, throwing when there are none
+ */
+export async function requireRedeemablePoints(userId: string): Promise<number> {
+  if (!isLoyaltyEnabled()) {
+    throw badUserInputError("Loyalty points are not available", "redeemPoints");
+  }
+  const points = await getLoyaltyPoints(userId);
+  if (points <= 0) {
+    throw badUserInputError("You have no loyalty points to redeem", "redeemPoints");
+  }
+  return points;
+}
+
+/**
+ * Debit a user's redeemable points before their order is created, so two
+ * concurrent orders can't spend the same balance; returns the points
+ * reserved. What the order doesn't use goes back with releaseLoyaltyPoints.
+ */
+export async function reserveLoyaltyPoints(userId: string): Promise<number> {
+  const points = await requireRedeemablePoints(userId);
+  await adjustLoyaltyPoints(userId, -points, "reserved for an order");
+  return points;
+}
+
+/**
+ * Credit back reserved or redeemed points; a failure is logged for a
+ * manual fix rather than failing the caller
+ */
+export async function releaseLoyaltyPoints(
+  userId: string,
+  points: number,
+  reason: string,
+): Promise<void> {
+  if (points <= 0) {
+    return;
+  }
+  try {
+    await adjustLoyaltyPoints(userId, points, reason);
+  } catch (error) {
+    logger.error("loyalty_points_release_failed", {
+      userId,
+      points,
+      reason,
+      error: error instanceof Error ? error.message : "Unknown error",
+    });
+  }
+}
+
+/**
+ * Discount a draft order with points, returning the points used (null on failure)
+ */
+export async function applyLoyaltyDiscount(
+  order: SaleorOrder,
+  availablePoints: number,
+): Promise<number | null> {
+  const currency = order.total.gross.currency;
+  const { points, discount } = computeLoyaltyRedemption(
+    availablePoints,
+    order.total.gross.amount,
+    currency,
+  );
+  if (points === 0) {
+    return 0;
+  }
+
+  const client = getSaleorClient();
+  if (!client) {
+    return null;
+  }
+  const variables: OrderDiscountAddMutationVariables = {
+    orderId: order.id,
+    input: {
+      valueType: "FIXED",
+      value: discount,
+      reason: `Loyalty points (${points})`,
+    },
+  };
+  const response = await client.execute<OrderDiscountAddMutation>(
+    ORDER_DISCOUNT_ADD_MUTATION,
+    variables,
+  );
+  const errors = [
+    ...(response.errors || []).map((e) => e.message),
+    ...(response.data?.orderDiscountAdd?.errors || []).map((e) => e.message),
+  ];
+  const updated = response.data?.orderDiscountAdd?.order;
+  if (errors.length > 0 || !updated) {
+    logger.error("order_loyalty_discount_failed", {
+      orderId: order.id,
+      error: errors.join(", ") || "No order returned",
+    });
+    return null;
+  }
+  order.total = { gross: updated.total.gross };
+  order.totalTax = updated.total.tax.amount;
+  return points;
+}
+
+/**
+ * Points redeemed on an order, if any
+ */
+export function getLoyaltyPointsRedeemed(order: SaleorOrder): number | undefined {
+  const points = Number(order.metadata?.[ORDER_METADATA_KEYS.loyaltyRedeemed]);
+  return Number.isFinite(points) && points > 0 ? points : undefined;
+}
+
+/**
+ * Give back the points redeemed on a cancelled or expired order, once
+ * Returns the points restored
+ */
+export async function restoreRedeemedPoints(orderId: string): Promise<number> {
+  let userId: string | undefined;
+  let points = 0;
+  // Claimed before the credit, so repeated cancellations restore once
+  const claimed = await updateOrderMetadataWith(orderId, (metadata) => {
+    userId = metadata[ORDER_METADATA_KEYS.telegramUserId];
+    points = Number(metadata[ORDER_METADATA_KEYS.loyaltyRedeemed]) || 0;
+    if (!userId || points <= 0 || metadata[ORDER_METADATA_KEYS.loyaltyRestored]) {
+      return null;
+    }
+    return { [ORDER_METADATA_KEYS.loyaltyRestored]: String(points) };
+  });
+  if (!claimed || !userId) {
+    return 0;
+  }
+  await releaseLoyaltyPoints(userId, points, `order ${orderId} cancelled`);
+  return points;
+}
+
+/**
+ * Credit points for a fulfilled order once (order_fulfilled webhook)
+ * Returns the points awarded, 0 when nothing was due
+ */
+export async function awardOrderPoints(orderId: string): Promise<number> {
+  if (!isLoyaltyEnabled()) {
+    return 0;
+  }
+  const order = await fetchOrderById(orderId);
+  const userId = order?.metadata?.[ORDER_METADATA_KEYS.telegramUserId];
+  if (!order || !userId) {
+    return 0;
+  }
+
+  const currency = order.total.gross.currency;
+  const spent = subtractMoney(
+    order.total.gross.amount,
+    getTipAmount(order) || 0,
+    currency,
+  );
+  const points = pointsForAmount(spent);
+
+  // Saleor redelivers webhooks; the order is claimed before the credit so
+  // points are credited once per order
+  const claimed = await updateOrderMetadataWith(orderId, (metadata) =>
+    metadata[ORDER_METADATA_KEYS.loyaltyAwarded]
+      ? null
+      : { [ORDER_METADATA_KEYS.loyaltyAwarded]: String(points) },
+  );
+  if (!claimed || points === 0) {
+    return 0;
+  }
+  try {
+    await adjustLoyaltyPoints(userId, points, `order ${orderId}`);
+  } catch (error) {
+    // Give up the claim so a redelivered webhook credits them
+    await updateOrderMetadata(orderId, { [ORDER_METADATA_KEYS.loyaltyAwarded]: "" });
+    throw error;
+  }
+  return points;
+}
@@ -0,0 +1,110 @@
+// Message Templates
+// Bot messages are built from templates with {placeholders}. English
+// templates are built in; MESSAGE_TEMPLATES adds translations or overrides
+// per language as JSON, {"ru": {"order_total": "Итого: {amount}"}}.
+// Lookup follows the user's language_code like tag labels do: the full
+// code ("pt-br"), its language ("pt"), then English. Placeholders without
+// a value are left empty.
+
+import { getVar } from "./config";
+import { logger } from "./logger";
+import { getLanguageChain } from "./tagLabels";
+
+export const DEFAULT_MESSAGE_TEMPLATES = {
+  order_placed: "Your order {order} from {restaurant} has been placed.",
+  order_line: "{quantity} × {name}",
+  order_line_amount: "{quantity} × {name} — {amount}",
+  order_subtotal: "Subtotal: {amount}",
+  order_delivery_fee: "Delivery: {amount}",
+  order_service_fee: "Service fee: {amount}",
+  order_tip: "Tip: {amount}",
+  order_discount: "Discount: −{amount}",
+  order_total: "Total: {amount}",
+  order_delivery_to: "Delivery to: {address}",
+  order_pickup_at: "Pickup at: {address}",
+  order_scheduled_for: "Scheduled for {time}",
+  order_estimated_at: "Estimated at {time}",
+  order_payment: "Payment: {method}",
+  order_link: "Track your order: {link}",
+  payment_cash: "Cash",
+  payment_card_on_delivery: "Card on delivery",
+  payment_online: "Online",
+  order_cancelled: "Your order {order} was cancelled: {reason}.",
+  order_cancelled_note: "Note: {note}",
+  order_payment_released: "Your payment hold has been released.",
+  cancel_reason_customer_request: "at your request",
+  cancel_reason_restaurant_rejected: "the restaurant couldn't take it",
+  cancel_reason_out_of_stock: "some items are out of stock",
+  cancel_reason_restaurant_closed: "the restaurant is closed",
+  cancel_reason_kitchen_busy: "the kitchen is too busy right now",
+  cancel_reason_out_of_delivery_area: "the address is outside the delivery area",
+  cancel_reason_payment_timeout: "payment was not completed in time",
+  cancel_reason_fraud_check: "we couldn't verify this order",
+};
+
+export type MessageKey = keyof typeof DEFAULT_MESSAGE_TEMPLATES;
+
+type TemplateMap = Record<string, Record<string, string>>;
+
+let cachedSource: string | undefined;
+let cachedTemplates: TemplateMap = {};
+
+/**
+ * MESSAGE_TEMPLATES keyed by lowercase language
+ */
+function getTemplateMap(): TemplateMap {
+  const source = getVar("MESSAGE_TEMPLATES");
+  if (source === cachedSource) {
+    return cachedTemplates;
+  }
+  cachedSource = source;
+  cachedTemplates = {};
+  if (!source) {
+    return cachedTemplates;
+  }
+  try {
+    const parsed = JSON.parse(source);
+    for (const [language, templates] of Object.entries(parsed ?? {})) {
+      if (!templates || typeof templates !== "object") {
+        continue;
+      }
+      const byKey: Record<string, string> = {};
+      for (const [key, template] of Object.entries(templates as Record<string, unknown>)) {
+        if (typeof template === "string" && template.trim()) {
+          byKey[key] = template;
+        }
+      }
+      cachedTemplates[language.trim().toLowerCase().replace("_", "-")] = byKey;
+    }
+  } catch {
+    logger.warn("message_templates_invalid", { reason: "MESSAGE_TEMPLATES is not valid JSON" });
+  }
+  return cachedTemplates;
+}
+
+/**
+ * Template text for a key in the user's language
+ */
+export function getMessageTemplate(key: MessageKey, languageCode?: string): string {
+  const templates = getTemplateMap();
+  for (const language of getLanguageChain(languageCode)) {
+    const template = templates[language]?.[key];
+    if (template) {
+      return template;
+    }
+  }
+  return DEFAULT_MESSAGE_TEMPLATES[key];
+}
+
+/**
+ * Render a template with its placeholder values
+ */
+export function renderMessage(
+  key: MessageKey,
+  languageCode?: string,
+  values: Record<string, string | number> = {},
+): string {
+  return getMessageTemplate(key, languageCode).replace(/\{(\w+)\}/g, (_, name: string) =>
+    values[name] !== undefined ? String(values[name]) : "",
+  );
+}
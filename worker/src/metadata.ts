@@ -0,0 +1,91 @@
+// Saleor Metadata Helpers
+// Saleor exposes metadata as [{ key, value }] lists; restaurants (channels)
+// and dishes (products) carry tma_* keys, orders carry tma.* keys.
+
+/**
+ * Saleor metadata item as returned by the API
+ */
+export interface MetadataItem {
+  key: string;
+  value: string;
+}
+
+/**
+ * Convert a Saleor metadata list into a key/value record
+ */
+export function metadataToRecord(
+  items: MetadataItem[] | null | undefined,
+): Record<string, string> {
+  const record: Record<string, string> = {};
+  for (const item of items || []) {
+    if (item && typeof item.key === "string") {
+      record[item.key] = String(item.value ?? "");
+    }
+  }
+  return record;
+}
+
+/**
+ * Convert a key/value record into a Saleor MetadataInput list
+ */
+export function recordToMetadataInput(
+  record: Record<string, string | undefined>,
+): MetadataItem[] {
+  return Object.entries(record)
+    .filter((entry): entry is [string, string] => entry[1] !== undefined)
+    .map(([key, value]) => ({ key, value }));
+}
+
+/**
+ * Parse a JSON metadata value, returning null when absent or malformed
+ */
+export function parseJSONValue<T>(value: string | undefined): T | null {
+  if (!value) {
+    return null;
+  }
+  try {
+    return JSON.parse(value) as T;
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Parse a numeric metadata value
+ */
+export function parseNumberValue(value: string | undefined): number | null {
+  if (value === undefined || value.trim() === "") {
+    return null;
+  }
+  const parsed = Number(value);
+  return Number.isFinite(parsed) ? parsed : null;
+}
+
+/**
+ * Parse a boolean metadata value ("true"/"1"/"yes")
+ */
+export function parseBooleanValue(value: string | undefined): boolean {
+  return ["true", "1", "yes"].includes((value || "").trim().toLowerCase());
+}
+
+/**
+ * Parse a list stored either as a JSON array or comma separated string
+ */
+export function parseListValue(value: string | undefined): string[] {
+  if (!value || value.trim() === "") {
+    return [];
+  }
+  const trimmed = value.trim();
+  if (trimmed.startsWith("[")) {
+    const parsed = parseJSONValue<unknown[]>(trimmed);
+    if (Array.isArray(parsed)) {
+      return parsed
+        .map((entry) => String(entry).trim())
+        .filter((entry) => entry.length > 0);
+    }
+  }
+  return trimmed
+    .split(",")
+    .map((entry) => entry.trim())
+    .filter((entry) => entry.length > 0);
+}
@@ -0,0 +1,54 @@
+// Metadata Migration Tests
+// Tests for metadataMigration.ts - legacy key renames and list conversion
+
+import { describe, it, expect } from "vitest";
+import { migratedKey, planMetadataMigration } from "./metadataMigration";
+
+describe("migratedKey", () => {
+  it("should map known legacy and prefixed keys", () => {
+    expect(migratedKey("hours", "CHANNEL")).toBe("tma_hours");
+    expect(migratedKey("tma.pickup-address", "CHANNEL")).toBe("tma_pickup_address");
+    expect(migratedKey("prep_minutes", "PRODUCT")).toBe("tma_prep_minutes");
+  });
+
+  it("should leave current and unrelated keys alone", () => {
+    expect(migratedKey("tma_hours", "CHANNEL")).toBeNull();
+    expect(migratedKey("hours", "PRODUCT")).toBeNull();
+    expect(migratedKey("erp_id", "CHANNEL")).toBeNull();
+  });
+});
+
+describe("planMetadataMigration", () => {
+  it("should rename keys and convert comma lists to JSON arrays", () => {
+    const changes = planMetadataMigration("CHANNEL", "ch-1", "Pizza", {
+      timezone: "Europe/Berlin",
+      payment_gateways: "stripe, telegram",
+      tma_hours: "{}",
+    });
+    expect(changes.map((c) => [c.fromKey, c.toKey, c.newValue, c.status])).toEqual([
+      ["timezone", "tma_timezone", "Europe/Berlin", "PLANNED"],
+      ["payment_gateways", "tma_payment_gateways", '["stripe","telegram"]', "PLANNED"],
+    ]);
+  });
+
+  it("should reformat current list keys in place", () => {
+    const [change] = planMetadataMigration("CHANNEL", "ch-1", "Pizza", {
+      tma_payment_gateways: "stripe",
+    });
+    expect(change.toKey).toBe("tma_payment_gateways");
+    expect(change.newValue).toBe('["stripe"]');
+  });
+
+  it("should report conflicts with existing current values", () => {
+    const changes = planMetadataMigration("CHANNEL", "ch-1", "Pizza", {
+      delivery_fee: "3",
+      tma_delivery_fee: "5",
+      tma_timezone: "UTC",
+      timezone: "UTC",
+    });
+    expect(changes.map((c) => [c.fromKey, c.status])).toEqual([
+      ["delivery_fee", "CONFLICT"],
+      ["timezone", "PLANNED"],
+    ]);
+  });
+});
@@ -0,0 +1,299 @@
+// Catalog Metadata Migration
+// Scans Saleor channels (restaurants) and products (dishes) for legacy
+// metadata keys and moves them to the current tma_* contract: unprefixed
+// and "tma." / "tma-" keys are renamed, comma separated lists become JSON
+// arrays. Runs as a dry run by default; applying writes the new keys with
+// updateMetadata before deleting the legacy ones. Existing tma_* values
+// that disagree with a legacy value are reported as conflicts and left
+// untouched.
+
+import {
+  MetadataMigrationChange,
+  MetadataMigrationReport,
+  MetadataObjectType,
+} from "./contracts";
+import { internalError } from "./errors";
+import { logger } from "./logger";
+import {
+  MetadataItem,
+  metadataToRecord,
+  parseListValue,
+  recordToMetadataInput,
+} from "./metadata";
+import {
+  getSaleorClient,
+  isSaleorConfigured,
+  CATALOG_METADATA_QUERY,
+  DELETE_METADATA_MUTATION,
+  UPDATE_METADATA_MUTATION,
+} from "./saleorClient";
+import {
+  CatalogMetadataQuery,
+  DeleteMetadataMutation,
+  DeleteMetadataMutationVariables,
+  UpdateMetadataMutation,
+  UpdateMetadataMutationVariables,
+} from "./saleorOperations";
+import { QUERY_NODE_COSTS, createCostBudget, executePageWithinCost } from "./queryCost";
+
+// Products are scanned in pages of up to 100; a run stops after this many pages
+// and returns a cursor to continue from
+const MAX_PRODUCT_PAGES = 50;
+
+// Legacy key -> current key, per object type
+const RENAMED_KEYS: Record<MetadataObjectType, Record<string, string>> = {
+  CHANNEL: {
+    hours: "tma_hours",
+    opening_hours: "tma_hours",
+    timezone: "tma_timezone",
+    prep_minutes: "tma_prep_minutes",
+    pickup_address: "tma_pickup_address",
+    delivery_fee: "tma_delivery_fee",
+    free_delivery_threshold: "tma_free_delivery_threshold",
+    payment_gateways: "tma_payment_gateways",
+    shipping_method_id: "tma_shipping_method_id",
+  },
+  PRODUCT: {
+    prep_minutes: "tma_prep_minutes",
+  },
+};
+
+// Current keys whose value must be a JSON array
+const LIST_KEYS = new Set(["tma_payment_gateways"]);
+
+/**
+ * Current key for a metadata key, or null when it is not a legacy key
+ */
+export function migratedKey(
+  key: string,
+  objectType: MetadataObjectType,
+): string | null {
+  const renamed = RENAMED_KEYS[objectType][key];
+  if (renamed) {
+    return renamed;
+  }
+  const prefixed = /^tma[.-](.+)$/.exec(key);
+  if (prefixed) {
+    return `tma_${prefixed[1].replace(/[.-]/g, "_")}`;
+  }
+  return null;
+}
+
+/**
+ * Value in the current format for a key
+ */
+export function migratedValue(key: string, value: string): string {
+  if (LIST_KEYS.has(key)) {
+    return JSON.stringify(parseListValue(value));
+  }
+  return value;
+}
+
+/**
+ * Changes needed to bring one object's metadata to the current contract
+ */
+export function planMetadataMigration(
+  objectType: MetadataObjectType,
+  objectId: string,
+  objectName: string,
+  metadata: Record<string, string>,
+): MetadataMigrationChange[] {
+  const changes: MetadataMigrationChange[] = [];
+  const planned = new Map<string, string>();
+
+  // Current keys first so they are claimed before legacy keys map onto them
+  const entries = Object.entries(metadata).sort(
+    ([a], [b]) =>
+      Number(migratedKey(a, objectType) !== null) -
+      Number(migratedKey(b, objectType) !== null),
+  );
+  for (const [key, value] of entries) {
+    const toKey = migratedKey(key, objectType) || key;
+    const newValue = migratedValue(toKey, value);
+    if (toKey === key && newValue === value) {
+      continue;
+    }
+
+    // A current value (or an earlier legacy key) wins over a different legacy value
+    const existing =
+      toKey !== key ? planned.get(toKey) ?? metadata[toKey] : undefined;
+    const conflict =
+      existing !== undefined && migratedValue(toKey, existing) !== newValue;
+    if (!conflict) {
+      planned.set(toKey, newValue);
+    }
+
+    changes.push({
+      objectType,
+      objectId,
+      objectName,
+      fromKey: key,
+      toKey,
+      oldValue: value,
+      newValue,
+      status: conflict ? "CONFLICT" : "PLANNED",
+    });
+  }
+  return changes;
+}
+
+interface CatalogObject {
+  id: string;
+  name: string;
+  metadata: MetadataItem[] | null;
+}
+
+async function applyObjectChanges(
+  objectId: string,
+  changes: MetadataMigrationChange[],
+): Promise<string | null> {
+  const client = getSaleorClient();
+  if (!client) {
+    return "Saleor is not configured";
+  }
+
+  const entries: Record<string, string> = {};
+  const legacyKeys: string[] = [];
+  for (const change of changes) {
+    entries[change.toKey] = change.newValue;
+    if (change.fromKey !== change.toKey) {
+      legacyKeys.push(change.fromKey);
+    }
+  }
+
+  const update: UpdateMetadataMutationVariables = {
+    id: objectId,
+    input: recordToMetadataInput(entries),
+  };
+  const updated = await client.execute<UpdateMetadataMutation>(UPDATE_METADATA_MUTATION, update);
+  const updateErrors = [
+    ...(updated.errors || []).map((e) => e.message),
+    ...(updated.data?.updateMetadata?.errors || []).map((e) => e.message),
+  ];
+  if (updateErrors.length > 0) {
+    return updateErrors.join(", ");
+  }
+  if (legacyKeys.length === 0) {
+    return null;
+  }
+
+  // New keys are written, so a failed delete only leaves duplicates behind
+  const remove: DeleteMetadataMutationVariables = { id: objectId, keys: legacyKeys };
+  const deleted = await client.execute<DeleteMetadataMutation>(DELETE_METADATA_MUTATION, remove);
+  const deleteErrors = [
+    ...(deleted.errors || []).map((e) => e.message),
+    ...(deleted.data?.deleteMetadata?.errors || []).map((e) => e.message),
+  ];
+  return deleteErrors.length > 0 ? deleteErrors.join(", ") : null;
+}
+
+/**
+ * Scan the catalog and migrate legacy metadata keys
+ */
+export async function migrateCatalogMetadata(
+  dryRun: boolean,
+  requestedBy: string,
+  startAfter: string | null = null,
+): Promise<MetadataMigrationReport> {
+  const report: MetadataMigrationReport = {
+    dryRun,
+    scannedChannels: 0,
+    scannedProducts: 0,
+    changedObjects: 0,
+    conflicts: 0,
+    failures: 0,
+    nextCursor: null,
+    changes: [],
+  };
+
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    // Mock data already uses the current keys
+    return report;
+  }
+
+  const objects: Array<{ type: MetadataObjectType; node: CatalogObject }> = [];
+  let after = startAfter;
+  // Pages shrink to stay within Saleor's query cost limit
+  const budget = createCostBudget(QUERY_NODE_COSTS.productMetadata);
+  for (let page = 0; ; page++) {
+    if (page >= MAX_PRODUCT_PAGES) {
+      report.nextCursor = after;
+      break;
+    }
+    const response = await executePageWithinCost<CatalogMetadataQuery>(
+      client,
+      CATALOG_METADATA_QUERY,
+      { after, includeChannels: page === 0 && !startAfter },
+      budget,
+    );
+    if (response.errors && response.errors.length > 0) {
+      const error = response.errors.map((e) => e.message).join(", ");
+      logger.error("metadata_migration_scan_failed", { error, page });
+      throw internalError(
+        "metadata_migration_scan_failed",
+        "Catalog scan failed, please try again",
+      );
+    }
+    for (const channel of response.data?.channels || []) {
+      objects.push({ type: "CHANNEL", node: channel });
+      report.scannedChannels++;
+    }
+    const products = response.data?.products;
+    for (const edge of products?.edges || []) {
+      objects.push({ type: "PRODUCT", node: edge.node });
+      report.scannedProducts++;
+    }
+    if (!products?.pageInfo.hasNextPage || !products.pageInfo.endCursor) {
+      break;
+    }
+    after = products.pageInfo.endCursor;
+  }
+
+  for (const { type, node } of objects) {
+    const changes = planMetadataMigration(
+      type,
+      node.id,
+      node.name,
+      metadataToRecord(node.metadata),
+    );
+    if (changes.length === 0) {
+      continue;
+    }
+    report.changes.push(...changes);
+
+    const applicable = changes.filter((c) => c.status === "PLANNED");
+    report.conflicts += changes.length - applicable.length;
+    if (applicable.length === 0) {
+      continue;
+    }
+    report.changedObjects++;
+    if (dryRun) {
+      continue;
+    }
+
+    const error = await applyObjectChanges(node.id, applicable);
+    for (const change of applicable) {
+      change.status = error ? "FAILED" : "APPLIED";
+    }
+    if (error) {
+      report.failures++;
+      logger.error("metadata_migration_apply_failed", {
+        objectType: type,
+        objectId: node.id,
+        error,
+      });
+    }
+  }
+
+  logger.info("metadata_migration_completed", {
+    dryRun,
+    requestedBy,
+    scannedChannels: report.scannedChannels,
+    scannedProducts: report.scannedProducts,
+    changedObjects: report.changedObjects,
+    conflicts: report.conflicts,
+    failures: report.failures,
+  });
+  return report;
+}
@@ -0,0 +1,80 @@
+// Money Utilities Tests
+// Tests for money.ts - minor units, rounding and cash rounding rules
+
+import { describe, it, expect } from "vitest";
+import {
+  getMinorUnits,
+  formatAmount,
+  toMinorUnits,
+  fromMinorUnits,
+  roundMoney,
+  sumMoney,
+  subtractMoney,
+  multiplyMoney,
+  roundForCash,
+} from "./money";
+
+describe("getMinorUnits", () => {
+  it("should default to 2 decimals", () => {
+    expect(getMinorUnits("USD")).toBe(2);
+    expect(getMinorUnits("eur")).toBe(2);
+    expect(getMinorUnits("XYZ")).toBe(2);
+  });
+
+  it("should know zero- and three-decimal currencies", () => {
+    expect(getMinorUnits("JPY")).toBe(0);
+    expect(getMinorUnits("KWD")).toBe(3);
+  });
+});
+
+describe("toMinorUnits / fromMinorUnits", () => {
+  it("should round half away from zero without float drift", () => {
+    expect(toMinorUnits(1.005, "USD")).toBe(101);
+    expect(toMinorUnits(-1.005, "USD")).toBe(-101);
+    expect(toMinorUnits(1234.5, "JPY")).toBe(1235);
+  });
+
+  it("should convert back to major units", () => {
+    expect(fromMinorUnits(101, "USD")).toBe(1.01);
+    expect(fromMinorUnits(1235, "KWD")).toBe(1.235);
+  });
+
+  it("should treat non-finite amounts as zero", () => {
+    expect(toMinorUnits(NaN, "USD")).toBe(0);
+  });
+});
+
+describe("arithmetic", () => {
+  it("should round to the currency minor unit", () => {
+    expect(roundMoney(0.1 + 0.2, "USD")).toBe(0.3);
+    expect(roundMoney(9.999, "JPY")).toBe(10);
+  });
+
+  it("should sum and subtract in minor units", () => {
+    expect(sumMoney([0.1, 0.2], "USD")).toBe(0.3);
+    expect(subtractMoney(0.3, 0.1, "USD")).toBe(0.2);
+  });
+
+  it("should multiply and round rates", () => {
+    expect(multiplyMoney(19.99, 0.15, "USD")).toBe(3);
+    expect(multiplyMoney(9.5, 3, "USD")).toBe(28.5);
+  });
+
+  it("should format amounts with the currency's minor units", () => {
+    expect(formatAmount(12.5, "USD")).toBe("12.50");
+    expect(formatAmount(1200, "JPY")).toBe("1200");
+    expect(formatAmount(1.2345, "KWD")).toBe("1.235");
+  });
+});
+
+describe("roundForCash", () => {
+  it("should apply cash rounding increments", () => {
+    expect(roundForCash(1.02, "CHF")).toBe(1);
+    expect(roundForCash(1.03, "CHF")).toBe(1.05);
+    expect(roundForCash(12.49, "SEK")).toBe(12);
+  });
+
+  it("should fall back to normal rounding for other currencies", () => {
+    expect(roundForCash(1.03, "USD")).toBe(1.03);
+  });
+});
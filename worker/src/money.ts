@@ -0,0 +1,158 @@
+// Money Utilities
+// Per-currency minor-unit handling and rounding rules so quotes, fees,
+// tips, payouts and cart totals never rely on ad-hoc float math.
+// Amounts are kept as major-unit numbers at the API boundary and
+// converted to integer minor units for arithmetic.
+
+/**
+ * ISO 4217 currencies whose minor unit differs from 2 decimals
+ */
+const CURRENCY_MINOR_UNITS: Record<string, number> = {
+  BIF: 0,
+  CLP: 0,
+  DJF: 0,
+  GNF: 0,
+  ISK: 0,
+  JPY: 0,
+  KMF: 0,
+  KRW: 0,
+  PYG: 0,
+  RWF: 0,
+  UGX: 0,
+  UYI: 0,
+  VND: 0,
+  VUV: 0,
+  XAF: 0,
+  XOF: 0,
+  XPF: 0,
+  BHD: 3,
+  IQD: 3,
+  JOD: 3,
+  KWD: 3,
+  LYD: 3,
+  OMR: 3,
+  TND: 3,
+};
+
+/**
+ * Cash rounding increments in minor units (e.g. CHF cash rounds to 0.05)
+ */
+const CASH_ROUNDING_INCREMENTS: Record<string, number> = {
+  AUD: 5,
+  CAD: 5,
+  CHF: 5,
+  CZK: 100,
+  DKK: 50,
+  HUF: 500,
+  NOK: 100,
+  NZD: 10,
+  SEK: 100,
+};
+
+const DEFAULT_MINOR_UNITS = 2;
+
+export function normalizeCurrency(currency: string): string {
+  return (currency || "").trim().toUpperCase();
+}
+
+/**
+ * Number of decimal places for a currency
+ */
+export function getMinorUnits(currency: string): number {
+  return CURRENCY_MINOR_UNITS[normalizeCurrency(currency)] ?? DEFAULT_MINOR_UNITS;
+}
+
+/**
+ * Round half away from zero (Math.round rounds -2.5 to -2)
+ */
+function roundHalfAwayFromZero(value: number): number {
+  return Math.sign(value) * Math.round(Math.abs(value));
+}
+
+/**
+ * Convert a major-unit amount to integer minor units
+ * Uses exponent notation to avoid binary float drift (1.005 -> 101 cents)
+ */
+export function toMinorUnits(amount: number, currency: string): number {
+  if (!Number.isFinite(amount)) {
+    return 0;
+  }
+  const units = getMinorUnits(currency);
+  const str = String(Math.abs(amount));
+  const scaled = str.includes("e")
+    ? Math.abs(amount) * Math.pow(10, units)
+    : Number(`${str}e${units}`);
+  return Math.sign(amount) * roundHalfAwayFromZero(scaled) || 0;
+}
+
+/**
+ * Convert integer minor units back to a major-unit amount
+ */
+export function fromMinorUnits(minor: number, currency: string): number {
+  const units = getMinorUnits(currency);
+  return Number((minor / Math.pow(10, units)).toFixed(units));
+}
+
+/**
+ * Round an amount to the currency's minor unit
+ */
+export function roundMoney(amount: number, currency: string): number {
+  return fromMinorUnits(toMinorUnits(amount, currency), currency);
+}
+
+/**
+ * Plain decimal string with the currency's minor units, e.g. "1200" for JPY
+ */
+export function formatAmount(amount: number, currency: string): string {
+  return roundMoney(amount, currency).toFixed(getMinorUnits(currency));
+}
+
+/**
+ * Sum amounts of the same currency without float accumulation errors
+ */
+export function sumMoney(amounts: number[], currency: string): number {
+  const total = amounts.reduce(
+    (sum, amount) => sum + toMinorUnits(amount, currency),
+    0,
+  );
+  return fromMinorUnits(total, currency);
+}
+
+/**
+ * Subtract b from a in the given currency
+ */
+export function subtractMoney(a: number, b: number, currency: string): number {
+  return fromMinorUnits(
+    toMinorUnits(a, currency) - toMinorUnits(b, currency),
+    currency,
+  );
+}
+
+/**
+ * Multiply an amount by a factor (quantity, rate, percentage) and round
+ */
+export function multiplyMoney(
+  amount: number,
+  factor: number,
+  currency: string,
+): number {
+  // Trim float noise (1999 * 0.15 = 299.84999...) before rounding
+  const minor = Number((toMinorUnits(amount, currency) * factor).toFixed(6));
+  return fromMinorUnits(roundHalfAwayFromZero(minor), currency);
+}
+
+/**
+ * Round an amount for cash payment using the currency's cash increment
+ * Currencies without a cash rule fall back to normal rounding
+ */
+export function roundForCash(amount: number, currency: string): number {
+  const increment = CASH_ROUNDING_INCREMENTS[normalizeCurrency(currency)];
+  const minor = toMinorUnits(amount, currency);
+  if (!increment) {
+    return fromMinorUnits(minor, currency);
+  }
+  return fromMinorUnits(
+    roundHalfAwayFromZero(minor / increment) * increment,
+    currency,
+  );
+}
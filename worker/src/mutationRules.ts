@@ -0,0 +1,304 @@
+// Mutation Input Rules
+// Validation rules for every mutation, applied by withInputValidation at
+// the resolver boundary. New mutations register their rules here; domain
+// modules keep their own business checks (ownership, state, limits).
+
+import {
+  RuleSet,
+  required,
+  string,
+  number,
+  oneOf,
+  array,
+  url,
+  latitude,
+  longitude,
+  isoDateTime,
+} from "./validation";
+import {
+  MAX_CANCELLATION_NOTE_LENGTH,
+  RESTAURANT_CANCELLATION_REASONS,
+} from "./cancellationReasons";
+import { MAX_RETRY_BATCH } from "./notificationFailures";
+
+// Required ID with the "<Label> is required" message resolvers already use
+function id(label: string) {
+  return [required(`${label} is required`), string({ max: 200 })];
+}
+
+const OPTIONAL_ID = [string({ max: 200 })];
+const QUANTITY = [
+  required("Quantity is required"),
+  number({ integer: true, min: 1, max: 99 }),
+];
+const PRICE = [number({ min: 0, max: 1_000_000 })];
+const CURRENCY = [string({ pattern: /^[A-Z]{3}$/ })];
+const IMAGE_URL = [url(["https:", "http:"])];
+const TELEGRAM_USER_ID = [
+  required("Telegram user is required"),
+  string({ pattern: /^\d{1,20}$/ }),
+];
+
+const DELIVERY_LOCATION: RuleSet = {
+  "input.deliveryLocation.address": [string({ max: 300 })],
+  "input.deliveryLocation.city": [string({ max: 100 })],
+  "input.deliveryLocation.country": [string({ max: 100 })],
+  "input.deliveryLocation.latitude": [latitude()],
+  "input.deliveryLocation.longitude": [longitude()],
+  "input.deliveryLocation.mapsUrl": [url()],
+};
+
+export const MUTATION_INPUT_RULES: Record<string, RuleSet> = {
+  placeOrder: {
+    input: [required("Input is required")],
+    "input.restaurantId": id("Restaurant"),
+    "input.fulfillmentType": [oneOf(["DELIVERY", "PICKUP"])],
+    "input.paymentMethod": [oneOf(["CASH", "CARD_ON_DELIVERY", "ONLINE"])],
+    "input.items": [array({ max: 50 })],
+    "input.items[].dishId": id("Dish"),
+    "input.items[].quantity": QUANTITY,
+    "input.items[].notes": [string({ max: 500 })],
+    ...DELIVERY_LOCATION,
+    "input.customerNote": [string({ max: 1000 })],
+    "input.scheduledFor": [isoDateTime()],
+    "input.tipAmount": [number({ min: 0 })],
+    "input.voucherCode": [string({ max: 100 })],
+    "input.giftCardCode": [string({ max: 100 })],
+  },
+
+  addToCart: {
+    input: [required("Input is required")],
+    "input.dishId": id("Dish"),
+    "input.quantity": QUANTITY,
+    "input.name": [string({ max: 200 })],
+    "input.price": PRICE,
+    "input.currency": CURRENCY,
+    "input.description": [string({ max: 5000 })],
+    "input.imageUrl": IMAGE_URL,
+    "input.restaurantId": OPTIONAL_ID,
+  },
+  updateCartItem: {
+    input: [required("Input is required")],
+    "input.dishId": id("Dish"),
+    // 0 removes the item
+    "input.quantity": [required("Quantity is required"), number({ integer: true, min: 0, max: 99 })],
+  },
+  removeCartItem: {
+    dishId: id("Dish"),
+  },
+
+  linkChannelToTelegram: {
+    input: [required("Input is required")],
+    "input.restaurantId": id("Restaurant"),
+    "input.telegramUserId": TELEGRAM_USER_ID,
+  },
+  unlinkChannel: {
+    input: [required("Input is required")],
+    "input.restaurantId": id("Restaurant"),
+  },
+
+  createInvoice: {
+    orderId: id("Order"),
+  },
+  initPayment: {
+    orderId: id("Order"),
+    method: [required("Payment method is required"), string({ max: 200 })],
+  },
+  acceptOrder: {
+    orderId: id("Order"),
+  },
+  rejectOrder: {
+    orderId: id("Order"),
+    reason: [required("Reason is required"), string({ max: 500 })],
+    reasonCode: [oneOf(RESTAURANT_CANCELLATION_REASONS)],
+  },
+  cancelOrder: {
+    orderId: id("Order"),
+    note: [string({ max: MAX_CANCELLATION_NOTE_LENGTH })],
+  },
+  markOrderDelivered: {
+    orderId: id("Order"),
+  },
+  verifyHandoffCode: {
+    input: [required("Input is required")],
+    // Either the scanned QR payload or the order and code
+    "input.orderId": [string({ max: 200 })],
+    "input.code": [string({ max: 20 })],
+    "input.qrPayload": [string({ max: 300 })],
+  },
+
+  createStaffInvite: {
+    input: [required("Input is required")],
+    "input.restaurantId": id("Restaurant"),
+    "input.role": [required("Role is required"), oneOf(["COURIER", "STAFF"])],
+  },
+  acceptStaffInvite: {
+    token: [required("Invite token is required"), string({ max: 200 })],
+  },
+  revokeStaffRole: {
+    input: [required("Input is required")],
+    "input.restaurantId": id("Restaurant"),
+    "input.telegramUserId": id("User"),
+  },
+
+  addFavorite: {
+    input: [required("Input is required")],
+    "input.kind": [required("Kind is required"), oneOf(["RESTAURANT", "DISH"])],
+    "input.targetId": id("Target"),
+    "input.restaurantId": OPTIONAL_ID,
+  },
+  removeFavorite: {
+    kind: [required("Kind is required"), oneOf(["RESTAURANT", "DISH"])],
+    targetId: id("Target"),
+  },
+
+  setDailyDigestEnabled: {
+    enabled: [required("enabled is required")],
+  },
+
+  reportOrderIssue: {
+    input: [required("Input is required")],
+    "input.orderId": id("Order"),
+    "input.category": [
+      required("Category is required"),
+      oneOf(["MISSING_ITEM", "WRONG_ITEM", "COLD_FOOD", "LATE", "QUALITY", "OTHER"]),
+    ],
+    "input.details": [string({ max: 2000 })],
+    "input.photos": [array({ max: 5 })],
+    "input.photos[]": [url()],
+  },
+  resolveOrderIssue: {
+    input: [required("Input is required")],
+    "input.issueId": id("Issue"),
+    "input.resolution": [required("Resolution is required"), oneOf(["REFUND", "VOUCHER", "NO_ACTION"])],
+    "input.note": [string({ max: 1000 })],
+    "input.refundAmount": PRICE,
+    "input.voucherValue": PRICE,
+  },
+  reviewAbuseFlag: {
+    input: [required("Input is required")],
+    "input.flagId": id("Flag"),
+    "input.decision": [required("Decision is required"), oneOf(["APPROVE", "REJECT"])],
+    "input.note": [string({ max: 1000 })],
+  },
+  issueCompensationVoucher: {
+    input: [required("Input is required")],
+    "input.orderId": id("Order"),
+    "input.value": PRICE,
+    "input.reason": [required("Reason is required"), string({ max: 500 })],
+  },
+
+  submitReview: {
+    input: [required("Input is required")],
+    "input.orderId": id("Order"),
+    "input.rating": [required("Rating is required"), number({ integer: true, min: 1, max: 5 })],
+    "input.comment": [string({ max: 2000 })],
+  },
+  rateOrder: {
+    orderId: id("Order"),
+    stars: [required("Stars are required"), number({ integer: true, min: 1, max: 5 })],
+    comment: [string({ max: 2000 })],
+  },
+  moderateReview: {
+    input: [required("Input is required")],
+    "input.reviewId": id("Review"),
+    "input.action": [required("Action is required"), oneOf(["APPROVE", "HIDE"])],
+  },
+  replyToReview: {
+    input: [required("Input is required")],
+    "input.reviewId": id("Review"),
+    "input.reply": [required("Reply is required"), string({ max: 2000 })],
+  },
+
+  startBroadcast: {
+    input: [required("Input is required")],
+    "input.message": [required("Message is required"), string({ max: 4096 })],
+    "input.translations": [array({ max: 50 })],
+    "input.translations[].language": [required("Language is required"), string({ max: 10 })],
+    "input.translations[].message": [required("Message is required"), string({ max: 4096 })],
+    "input.filter.lastOrderWithinDays": [number({ integer: true, min: 1, max: 3650 })],
+    "input.filter.city": [string({ max: 100 })],
+    "input.filter.language": [string({ max: 10 })],
+  },
+  abortBroadcast: {
+    broadcastId: id("Broadcast"),
+  },
+  retryNotifications: {
+    ids: [required("IDs are required"), array({ min: 1, max: MAX_RETRY_BATCH })],
+  },
+  markNotificationsFailed: {
+    ids: [required("IDs are required"), array({ min: 1, max: MAX_RETRY_BATCH })],
+  },
+  migrateCatalogMetadata: {
+    after: [string({ max: 500 })],
+  },
+
+  setCommissionRate: {
+    input: [required("Input is required")],
+    "input.restaurantId": id("Restaurant"),
+    "input.rate": [required("Commission rate is required"), number({ min: 0, max: 1 })],
+  },
+
+  createDish: {
+    input: [required("Input is required")],
+    "input.restaurantId": id("Restaurant"),
+    "input.categoryId": id("Category"),
+    "input.name": [required("Name is required"), string({ max: 200 })],
+    "input.description": [string({ max: 5000 })],
+    "input.price": [required("Price is required"), ...PRICE],
+    "input.currency": CURRENCY,
+    "input.imageUrl": IMAGE_URL,
+  },
+  updateDish: {
+    input: [required("Input is required")],
+    "input.dishId": id("Dish"),
+    "input.restaurantId": id("Restaurant"),
+    "input.name": [string({ min: 1, max: 200 })],
+    "input.description": [string({ max: 5000 })],
+    "input.price": PRICE,
+    "input.currency": CURRENCY,
+    "input.imageUrl": IMAGE_URL,
+  },
+  updateStock: {
+    input: [required("Input is required")],
+    "input.dishId": id("Dish"),
+    "input.restaurantId": id("Restaurant"),
+    "input.quantity": [required("Quantity is required"), number({ integer: true, min: 0, max: 1_000_000 })],
+  },
+  updateStoreDescription: {
+    input: [required("Input is required")],
+    "input.restaurantId": id("Restaurant"),
+    "input.description": [required("Description is required"), string({ max: 5000 })],
+  },
+  setRestaurantAnnouncement: {
+    input: [required("Input is required")],
+    "input.restaurantId": id("Restaurant"),
+    // Empty clears the announcement
+    "input.message": [string({ max: 200 })],
+    "input.expiresAt": [isoDateTime()],
+  },
+  setRestaurantPaused: {
+    restaurantId: id("Restaurant"),
+    paused: [required("Paused is required")],
+    until: [isoDateTime()],
+  },
+  setKitchenCapacity: {
+    restaurantId: id("Restaurant"),
+    // null or 0 removes the cap
+    maxActiveOrders: [number({ integer: true, min: 0, max: 1000 })],
+  },
+  setDailyBanner: {
+    input: [required("Input is required")],
+    "input.date": [required("Date is required"), string({ max: 10 })],
+    // Empty clears the day's banner
+    "input.text": [string({ max: 200 })],
+    "input.imageUrl": [url()],
+    "input.linkUrl": [url(["https:", "tg:"])],
+  },
+  setOpeningHoursOverride: {
+    input: [required("Input is required")],
+    "input.restaurantId": id("Restaurant"),
+    "input.date": [required("Date is required"), string({ max: 10 })],
+    "input.hours": [array({ max: 10 })],
+  },
+};
@@ -0,0 +1,103 @@
+// Notification Failure Tests
+// Tests for notificationFailures.ts - recording, retrying and suppressing bot messages
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { deliverTelegramMessage } from "./notifications";
+import {
+  isNotificationSuppressed,
+  listNotificationFailures,
+  markNotificationsPermanentlyFailed,
+  retryNotificationFailures,
+} from "./notificationFailures";
+import { deleteKey, listKeys } from "./storage";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+const fetchMock = vi.fn();
+
+function respond(status: number) {
+  fetchMock.mockResolvedValueOnce(new Response(JSON.stringify({ ok: status === 200 }), { status }));
+}
+
+beforeEach(async () => {
+  (globalThis as any).TELEGRAM_BOT_TOKEN = "test-token";
+  vi.stubGlobal("fetch", fetchMock);
+  fetchMock.mockReset();
+  for (const prefix of ["notification-failure:", "notification-blocks:"]) {
+    for (const key of await listKeys(prefix)) {
+      await deleteKey(key);
+    }
+  }
+});
+
+afterEach(() => {
+  vi.unstubAllGlobals();
+  delete (globalThis as any).TELEGRAM_BOT_TOKEN;
+  delete (globalThis as any).NOTIFICATION_BLOCK_LIMIT;
+});
+
+describe("notification failures", () => {
+  it("should record refused messages and deliver them on retry", async () => {
+    respond(500);
+    await deliverTelegramMessage("user-1", "Your order #1 is ready");
+    const [failure] = await listNotificationFailures();
+    expect(failure).toMatchObject({ userId: "user-1", reason: "ERROR", httpStatus: 500 });
+
+    respond(200);
+    const [retried] = await retryNotificationFailures([failure.id, "missing"], "admin");
+    expect(retried).toMatchObject({ status: "DELIVERED", attempts: 2, resolvedBy: "admin" });
+    expect(await listNotificationFailures()).toEqual([]);
+    expect(await listNotificationFailures({ status: "DELIVERED" })).toHaveLength(1);
+  });
+
+  it("should not record broadcast messages", async () => {
+    respond(500);
+    await deliverTelegramMessage("user-1", "News", { recordFailure: false });
+    expect(await listNotificationFailures()).toEqual([]);
+  });
+
+  it("should stop messaging users who keep blocking the bot", async () => {
+    (globalThis as any).NOTIFICATION_BLOCK_LIMIT = "2";
+    respond(403);
+    await deliverTelegramMessage("user-2", "First");
+    expect(await isNotificationSuppressed("user-2")).toBe(false);
+    respond(403);
+    await deliverTelegramMessage("user-2", "Second");
+    expect(await isNotificationSuppressed("user-2")).toBe(true);
+
+    const result = await deliverTelegramMessage("user-2", "Third");
+    expect(result).toMatchObject({ ok: false, suppressed: true });
+    expect(fetchMock).toHaveBeenCalledTimes(2);
+
+    const failures = await listNotificationFailures({ reason: "BLOCKED" });
+    expect(failures.every((f) => f.userSuppressed)).toBe(true);
+    const retried = await retryNotificationFailures(
+      failures.map((f) => f.id),
+      "admin",
+    );
+    expect(retried.map((f) => f.status)).toEqual(["PERMANENTLY_FAILED", "PERMANENTLY_FAILED"]);
+    expect(fetchMock).toHaveBeenCalledTimes(2);
+  });
+
+  it("should reset the block count after a delivered message", async () => {
+    (globalThis as any).NOTIFICATION_BLOCK_LIMIT = "2";
+    respond(403);
+    await deliverTelegramMessage("user-3", "First");
+    respond(200);
+    await deliverTelegramMessage("user-3", "Second");
+    respond(403);
+    await deliverTelegramMessage("user-3", "Third");
+    expect(await isNotificationSuppressed("user-3")).toBe(false);
+  });
+
+  it("should mark failures permanently failed", async () => {
+    respond(500);
+    await deliverTelegramMessage("user-4", "Hello");
+    const [failure] = await listNotificationFailures();
+    const [marked] = await markNotificationsPermanentlyFailed([failure.id], "admin");
+    expect(marked.status).toBe("PERMANENTLY_FAILED");
+    expect(await listNotificationFailures({ status: "PERMANENTLY_FAILED" })).toHaveLength(1);
+  });
+});
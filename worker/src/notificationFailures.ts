@@ -0,0 +1,253 @@
+// Failed Notification Deliveries
+// Bot messages that Telegram refused (the user blocked the bot, rate
+// limiting, other errors) are kept for a while (notification-failure:<id>)
+// so superadmins can list them, retry some or mark them permanently
+// failed. Broadcasts keep their own stats and aren't recorded here.
+// Every 403 counts against the user (notification-blocks:<userId>) and a
+// delivered message resets the count; after NOTIFICATION_BLOCK_LIMIT
+// blocks in a row nothing more is sent to them for 30 days, as Telegram
+// asks of bots.
+
+import {
+  NotificationFailure,
+  NotificationFailureFilter,
+  NotificationFailureReason,
+} from "./contracts";
+import { getNumberVar } from "./config";
+import { logger } from "./logger";
+import { TelegramSendResult, deliverTelegramMessage } from "./notifications";
+import { deleteKey, readAllJSON, readJSON, writeJSON } from "./storage";
+
+const FAILURE_PREFIX = "notification-failure:";
+const BLOCKS_PREFIX = "notification-blocks:";
+const FAILURE_TTL_SECONDS = 14 * 24 * 60 * 60;
+const BLOCKS_TTL_SECONDS = 30 * 24 * 60 * 60;
+
+export const DEFAULT_FAILURE_LIST_SIZE = 50;
+export const MAX_FAILURE_LIST_SIZE = 200;
+export const MAX_RETRY_BATCH = 100;
+
+type FailureRecord = Omit<NotificationFailure, "userSuppressed">;
+
+/**
+ * Consecutive blocks of the bot by one user
+ */
+interface BlockRecord {
+  userId: string;
+  blocks: number;
+  lastBlockedAt: string;
+  suppressedAt: string | null;
+}
+
+function getFailureKey(id: string): string {
+  return `${FAILURE_PREFIX}${id}`;
+}
+
+function getBlocksKey(userId: string): string {
+  return `${BLOCKS_PREFIX}${userId}`;
+}
+
+/**
+ * Blocks in a row after which a user gets no more messages
+ * (NOTIFICATION_BLOCK_LIMIT, default 3)
+ */
+export function getBlockLimit(): number {
+  const limit = getNumberVar("NOTIFICATION_BLOCK_LIMIT", 3);
+  return limit > 0 ? Math.floor(limit) : 3;
+}
+
+export function getFailureReason(result: TelegramSendResult): NotificationFailureReason {
+  if (result.blocked) {
+    return "BLOCKED";
+  }
+  return result.status === 429 ? "RATE_LIMITED" : "ERROR";
+}
+
+export async function isNotificationSuppressed(userId: string): Promise<boolean> {
+  const record = await readJSON<BlockRecord>(getBlocksKey(userId));
+  return Boolean(record?.suppressedAt);
+}
+
+/**
+ * Count a delivery outcome towards the user's suppression: a block adds
+ * one, a delivered message starts the count over
+ */
+export async function trackDeliveryOutcome(
+  userId: string,
+  result: TelegramSendResult,
+): Promise<void> {
+  const key = getBlocksKey(userId);
+  const record = await readJSON<BlockRecord>(key);
+  if (result.ok) {
+    if (record) {
+      await deleteKey(key);
+    }
+    return;
+  }
+  if (!result.blocked) {
+    return;
+  }
+
+  const now = new Date().toISOString();
+  const next: BlockRecord = {
+    userId,
+    blocks: (record?.blocks ?? 0) + 1,
+    lastBlockedAt: now,
+    suppressedAt: record?.suppressedAt ?? null,
+  };
+  if (!next.suppressedAt && next.blocks >= getBlockLimit()) {
+    next.suppressedAt = now;
+    logger.info("notifications_suppressed", { userId, blocks: next.blocks });
+  }
+  await writeJSON(key, next, { expirationTtl: BLOCKS_TTL_SECONDS });
+}
+
+/**
+ * Keep a message Telegram refused so it can be retried
+ */
+export async function recordNotificationFailure(
+  userId: string,
+  text: string,
+  result: TelegramSendResult,
+): Promise<NotificationFailure> {
+  const now = new Date().toISOString();
+  const record: FailureRecord = {
+    id: crypto.randomUUID(),
+    userId,
+    text,
+    reason: getFailureReason(result),
+    httpStatus: result.status,
+    status: "FAILED",
+    attempts: 1,
+    firstFailedAt: now,
+    lastFailedAt: now,
+    resolvedAt: null,
+    resolvedBy: null,
+  };
+  await writeJSON(getFailureKey(record.id), record, { expirationTtl: FAILURE_TTL_SECONDS });
+  return { ...record, userSuppressed: false };
+}
+
+async function withSuppression(records: FailureRecord[]): Promise<NotificationFailure[]> {
+  const suppressed = new Map<string, boolean>();
+  const failures: NotificationFailure[] = [];
+  for (const record of records) {
+    if (!suppressed.has(record.userId)) {
+      suppressed.set(record.userId, await isNotificationSuppressed(record.userId));
+    }
+    failures.push({ ...record, userSuppressed: suppressed.get(record.userId)! });
+  }
+  return failures;
+}
+
+/**
+ * Failed deliveries matching the filter (FAILED by default), newest first
+ */
+export async function listNotificationFailures(
+  filter: NotificationFailureFilter = {},
+  limit: number = DEFAULT_FAILURE_LIST_SIZE,
+): Promise<NotificationFailure[]> {
+  const size = Math.min(Math.max(1, Math.floor(limit)), MAX_FAILURE_LIST_SIZE);
+  const status = filter.status || "FAILED";
+  const records = await readAllJSON<FailureRecord>(FAILURE_PREFIX);
+  const matching = records
+    .filter(
+      (record) =>
+        record.status === status &&
+        (!filter.reason || record.reason === filter.reason) &&
+        (!filter.userId || record.userId === filter.userId),
+    )
+    .sort((a, b) => b.lastFailedAt.localeCompare(a.lastFailedAt))
+    .slice(0, size);
+  return withSuppression(matching);
+}
+
+async function readFailures(ids: string[]): Promise<FailureRecord[]> {
+  const records: FailureRecord[] = [];
+  for (const id of Array.from(new Set(ids)).slice(0, MAX_RETRY_BATCH)) {
+    const record = await readJSON<FailureRecord>(getFailureKey(id));
+    if (record) {
+      records.push(record);
+    }
+  }
+  return records;
+}
+
+async function saveFailure(record: FailureRecord): Promise<void> {
+  await writeJSON(getFailureKey(record.id), record, { expirationTtl: FAILURE_TTL_SECONDS });
+}
+
+/**
+ * Send failed messages again. Messages to suppressed users are marked
+ * permanently failed instead; a 429 stops the run so the rest keep
+ * their place. Unknown or expired IDs are skipped.
+ */
+export async function retryNotificationFailures(
+  ids: string[],
+  retriedBy: string,
+): Promise<NotificationFailure[]> {
+  const records = await readFailures(ids);
+  let rateLimited = false;
+  let delivered = 0;
+
+  for (const record of records) {
+    if (record.status !== "FAILED" || rateLimited) {
+      continue;
+    }
+    const now = new Date().toISOString();
+    if (await isNotificationSuppressed(record.userId)) {
+      record.status = "PERMANENTLY_FAILED";
+      record.resolvedAt = now;
+      record.resolvedBy = retriedBy;
+      await saveFailure(record);
+      continue;
+    }
+
+    const result = await deliverTelegramMessage(record.userId, record.text, {
+      recordFailure: false,
+    });
+    record.attempts++;
+    if (result.ok) {
+      record.status = "DELIVERED";
+      record.resolvedAt = now;
+      record.resolvedBy = retriedBy;
+      delivered++;
+    } else {
+      record.reason = getFailureReason(result);
+      record.httpStatus = result.status;
+      record.lastFailedAt = now;
+      rateLimited = result.status === 429;
+    }
+    await saveFailure(record);
+  }
+
+  logger.info("notification_retry", {
+    retriedBy,
+    requested: ids.length,
+    delivered,
+    rateLimited,
+  });
+  return withSuppression(records);
+}
+
+/**
+ * Stop retrying failed messages
+ */
+export async function markNotificationsPermanentlyFailed(
+  ids: string[],
+  markedBy: string,
+): Promise<NotificationFailure[]> {
+  const records = await readFailures(ids);
+  const now = new Date().toISOString();
+  for (const record of records) {
+    if (record.status !== "FAILED") {
+      continue;
+    }
+    record.status = "PERMANENTLY_FAILED";
+    record.resolvedAt = now;
+    record.resolvedBy = markedBy;
+    await saveFailure(record);
+  }
+  logger.info("notifications_marked_failed", { markedBy, count: records.length });
+  return withSuppression(records);
+}
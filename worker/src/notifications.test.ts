@@ -0,0 +1,107 @@
+// Notification Tests
+// Tests for notifications.ts - order receipt messages and Mini App links
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { OrderDetails } from "./contracts";
+import { renderMessage } from "./messageTemplates";
+import { buildOrderLink, buildOrderPlacedMessage } from "./notifications";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+const order: OrderDetails = {
+  orderId: "T3JkZXI6MQ==",
+  number: 42,
+  status: "UNFULFILLED",
+  normalizedStatus: "PLACED",
+  createdAt: "2026-03-01T12:00:00.000Z",
+  total: 24.5,
+  currency: "EUR",
+  totalMoney: { amount: 24.5, currency: "EUR", displayAmount: "€24.50" },
+  lines: [
+    { dishId: "d1", name: "Margherita", quantity: 2, unitPrice: 9.5 },
+    { dishId: "d2", name: "Cola", quantity: 1 },
+  ],
+  deliveryLocation: { address: "1 Market Square", city: "Berlin" },
+  estimatedDeliveryAt: "2026-03-01T12:40:00.000Z",
+  fulfillmentType: "DELIVERY",
+  paymentMethod: "CASH",
+  totals: {
+    currency: "EUR",
+    subtotal: 19,
+    deliveryFee: 2.5,
+    serviceFee: 0,
+    tip: 3,
+    tax: 0,
+    discount: 0,
+    grandTotal: 24.5,
+  },
+};
+
+afterEach(() => {
+  delete (globalThis as any).TELEGRAM_MINI_APP_URL;
+  delete (globalThis as any).MESSAGE_TEMPLATES;
+});
+
+describe("buildOrderLink", () => {
+  it("should make the order ID a valid start parameter", () => {
+    expect(buildOrderLink("T3JkZXI6MQ==")).toBeNull();
+    (globalThis as any).TELEGRAM_MINI_APP_URL = "https://t.me/food_bot/app";
+    expect(buildOrderLink("T3Jk+ZX/I6MQ==")).toBe(
+      "https://t.me/food_bot/app?startapp=order_T3Jk-ZX_I6MQ",
+    );
+    expect(buildOrderLink("x".repeat(80))).toBe("https://t.me/food_bot/app");
+  });
+});
+
+describe("buildOrderPlacedMessage", () => {
+  it("should summarise items, totals, delivery and the order link", () => {
+    (globalThis as any).TELEGRAM_MINI_APP_URL = "https://t.me/food_bot/app";
+    const text = buildOrderPlacedMessage(order, "Pizza Place", {
+      announcement: "Free dessert today",
+      timeZone: "Europe/Berlin",
+    });
+    const lines = text.split("\n");
+    expect(lines[0]).toBe("Your order #42 from Pizza Place has been placed.");
+    expect(lines).toContain("2 × Margherita — €19.00");
+    expect(lines).toContain("1 × Cola");
+    expect(lines).toContain("Delivery: €2.50");
+    expect(lines).toContain("Tip: €3.00");
+    expect(lines).toContain("Total: €24.50");
+    expect(lines).toContain("Delivery to: 1 Market Square, Berlin");
+    expect(lines).toContain("Payment: Cash");
+    expect(lines).toContain("Pizza Place: Free dessert today");
+    expect(text).toMatch(/Estimated at .*1:40/);
+    expect(text).not.toContain("Service fee");
+    expect(lines[lines.length - 1]).toBe(
+      "Track your order: https://t.me/food_bot/app?startapp=order_T3JkZXI6MQ",
+    );
+  });
+
+  it("should show the pickup address for pickup orders", () => {
+    const text = buildOrderPlacedMessage(
+      { ...order, fulfillmentType: "PICKUP", pickupAddress: "Counter 3" },
+      "Pizza Place",
+    );
+    expect(text).toContain("Pickup at: Counter 3");
+    expect(text).not.toContain("Delivery to:");
+    expect(text).not.toContain("Track your order");
+  });
+});
+
+describe("renderMessage", () => {
+  it("should use configured translations with an English fallback", () => {
+    (globalThis as any).MESSAGE_TEMPLATES = JSON.stringify({
+      ru: { order_total: "Итого: {amount}" },
+    });
+    expect(renderMessage("order_total", "ru-RU", { amount: "5 €" })).toBe("Итого: 5 €");
+    expect(renderMessage("order_tip", "ru", { amount: "1 €" })).toBe("Tip: 1 €");
+    expect(renderMessage("order_total", "de")).toBe("Total: ");
+  });
+
+  it("should ignore invalid configuration", () => {
+    (globalThis as any).MESSAGE_TEMPLATES = "{not json";
+    expect(renderMessage("payment_cash", "ru")).toBe("Cash");
+  });
+});
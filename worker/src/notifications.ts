@@ -0,0 +1,353 @@
+// User Notifications
+// Sends Telegram Bot API messages to users. Telegram user IDs double as
+// private chat IDs, so no extra chat mapping is needed.
+// Disabled (logged only) when TELEGRAM_BOT_TOKEN is not configured.
+
+import { getBooleanVar, getVar } from "./config";
+import {
+  CancellationReasonCode,
+  OrderCancellation,
+  OrderDetails,
+  OrderPaymentMethod,
+} from "./contracts";
+import { formatMoney, localeFromLanguageCode } from "./currencyFormat";
+import { logger } from "./logger";
+import { MessageKey, renderMessage } from "./messageTemplates";
+import { multiplyMoney } from "./money";
+import {
+  isNotificationSuppressed,
+  recordNotificationFailure,
+  trackDeliveryOutcome,
+} from "./notificationFailures";
+
+const TELEGRAM_API_BASE = "https://api.telegram.org";
+// Telegram start parameters allow [A-Za-z0-9_-], at most 64 characters
+const START_PARAM_PATTERN = /^[A-Za-z0-9_-]{1,64}$/;
+export const ORDER_START_PARAM_PREFIX = "order_";
+
+const PAYMENT_METHOD_KEYS: Record<OrderPaymentMethod, MessageKey> = {
+  CASH: "payment_cash",
+  CARD_ON_DELIVERY: "payment_card_on_delivery",
+  ONLINE: "payment_online",
+};
+
+const CANCELLATION_REASON_KEYS: Record<CancellationReasonCode, MessageKey> = {
+  CUSTOMER_REQUEST: "cancel_reason_customer_request",
+  RESTAURANT_REJECTED: "cancel_reason_restaurant_rejected",
+  OUT_OF_STOCK: "cancel_reason_out_of_stock",
+  RESTAURANT_CLOSED: "cancel_reason_restaurant_closed",
+  KITCHEN_BUSY: "cancel_reason_kitchen_busy",
+  OUT_OF_DELIVERY_AREA: "cancel_reason_out_of_delivery_area",
+  PAYMENT_TIMEOUT: "cancel_reason_payment_timeout",
+  FRAUD_CHECK: "cancel_reason_fraud_check",
+};
+
+/**
+ * Outcome of a Bot API sendMessage call
+ */
+export interface TelegramSendResult {
+  ok: boolean;
+  status: number; // HTTP status, 0 when not sent
+  retryAfter?: number; // seconds, set on 429 Too Many Requests
+  blocked?: boolean; // user blocked the bot or never started it (403)
+  suppressed?: boolean; // not sent: the user blocked the bot repeatedly
+}
+
+export interface DeliveryOptions {
+  // Keep refused messages for retrying (notificationFailures.ts); callers
+  // with their own retry or stats, like broadcasts, turn it off
+  recordFailure?: boolean;
+}
+
+async function sendToTelegram(
+  token: string,
+  userId: string,
+  text: string,
+): Promise<TelegramSendResult> {
+  try {
+    const response = await fetch(`${TELEGRAM_API_BASE}/bot${token}/sendMessage`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ chat_id: userId, text }),
+    });
+
+    if (!response.ok) {
+      let retryAfter: number | undefined;
+      if (response.status === 429) {
+        const body: any = await response.json().catch(() => null);
+        retryAfter = Number(body?.parameters?.retry_after) || 1;
+      }
+      logger.warn("notification_failed", { userId, status: response.status });
+      return {
+        ok: false,
+        status: response.status,
+        retryAfter,
+        blocked: response.status === 403,
+      };
+    }
+    return { ok: true, status: response.status };
+  } catch (error) {
+    logger.error("notification_failed", {
+      userId,
+      error: error instanceof Error ? error.message : "Unknown error",
+    });
+    return { ok: false, status: 0 };
+  }
+}
+
+/**
+ * Send a plain-text message and report the Bot API outcome
+ * Users who keep blocking the bot are skipped (reported as blocked)
+ */
+export async function deliverTelegramMessage(
+  userId: string,
+  text: string,
+  options: DeliveryOptions = {},
+): Promise<TelegramSendResult> {
+  const token = getVar("TELEGRAM_BOT_TOKEN");
+  if (!token) {
+    logger.debug("notification_skipped", { userId, reason: "no_bot_token" });
+    return { ok: false, status: 0 };
+  }
+  if (await isNotificationSuppressed(userId)) {
+    logger.debug("notification_skipped", { userId, reason: "suppressed" });
+    return { ok: false, status: 0, blocked: true, suppressed: true };
+  }
+
+  const result = await sendToTelegram(token, userId, text);
+  await trackDeliveryOutcome(userId, result);
+  if (!result.ok && options.recordFailure !== false) {
+    await recordNotificationFailure(userId, text, result);
+  }
+  return result;
+}
+
+/**
+ * Send a plain-text message to a Telegram user
+ * Returns false when the bot is not configured or delivery failed
+ */
+export async function sendTelegramMessage(
+  userId: string,
+  text: string,
+): Promise<boolean> {
+  const result = await deliverTelegramMessage(userId, text);
+  return result.ok;
+}
+
+/**
+ * Tell a user their unpaid order was cancelled
+ */
+export async function notifyOrderExpired(
+  userId: string,
+  orderId: string,
+  orderNumber?: number,
+): Promise<boolean> {
+  return notifyOrderCancelled(userId, orderId, orderNumber, {
+    reason: "PAYMENT_TIMEOUT",
+    cancelledBy: "SYSTEM",
+    note: null,
+    cancelledAt: null,
+  });
+}
+
+/**
+ * Cancellation extras: the user's language and whether a payment hold
+ * was released
+ */
+export interface CancellationMessageOptions {
+  languageCode?: string;
+  paymentReleased?: boolean;
+}
+
+/**
+ * Text telling a customer why their order was cancelled
+ */
+export function buildOrderCancelledMessage(
+  orderLabel: string,
+  cancellation: OrderCancellation,
+  options: CancellationMessageOptions = {},
+): string {
+  const { languageCode } = options;
+  const lines = [
+    renderMessage("order_cancelled", languageCode, {
+      order: orderLabel,
+      reason: renderMessage(CANCELLATION_REASON_KEYS[cancellation.reason], languageCode),
+    }),
+  ];
+  if (cancellation.note) {
+    lines.push(renderMessage("order_cancelled_note", languageCode, { note: cancellation.note }));
+  }
+  if (options.paymentReleased) {
+    lines.push(renderMessage("order_payment_released", languageCode));
+  }
+  return lines.join("\n");
+}
+
+/**
+ * Tell a user their order was cancelled, with the reason and note
+ */
+export async function notifyOrderCancelled(
+  userId: string,
+  orderId: string,
+  orderNumber: number | undefined,
+  cancellation: OrderCancellation,
+  options: CancellationMessageOptions = {},
+): Promise<boolean> {
+  const label = orderNumber ? `#${orderNumber}` : orderId;
+  return sendTelegramMessage(userId, buildOrderCancelledMessage(label, cancellation, options));
+}
+
+/**
+ * Mini App direct link with a start parameter, or null without
+ * TELEGRAM_MINI_APP_URL; an invalid parameter links to the app itself
+ */
+export function buildMiniAppLink(startParam?: string): string | null {
+  const base = getVar("TELEGRAM_MINI_APP_URL");
+  if (!base) {
+    return null;
+  }
+  if (!startParam || !START_PARAM_PATTERN.test(startParam)) {
+    return base;
+  }
+  const separator = base.includes("?") ? "&" : "?";
+  return `${base}${separator}startapp=${startParam}`;
+}
+
+/**
+ * Deep link to an order's screen: startapp=order_<id>, with the base64
+ * order ID made URL-safe (+ to -, / to _, padding dropped)
+ */
+export function buildOrderLink(orderId: string): string | null {
+  const param = orderId.replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "");
+  return buildMiniAppLink(`${ORDER_START_PARAM_PREFIX}${param}`);
+}
+
+/**
+ * Receipt extras: the restaurant's announcement, the user's language and
+ * the restaurant's timezone for times
+ */
+export interface OrderMessageOptions {
+  announcement?: string | null;
+  languageCode?: string;
+  timeZone?: string;
+}
+
+function formatTime(iso: string, locale: string, timeZone?: string): string {
+  const date = new Date(iso);
+  if (isNaN(date.getTime())) {
+    return iso;
+  }
+  try {
+    return date.toLocaleString(locale, {
+      timeZone: timeZone || "UTC",
+      dateStyle: "medium",
+      timeStyle: "short",
+    });
+  } catch {
+    return date.toISOString();
+  }
+}
+
+/**
+ * Receipt text for a placed order: items, totals, delivery or pickup,
+ * payment and a link back to the order
+ */
+export function buildOrderPlacedMessage(
+  order: OrderDetails,
+  restaurantName: string,
+  options: OrderMessageOptions = {},
+): string {
+  const { languageCode, timeZone } = options;
+  const locale = localeFromLanguageCode(languageCode);
+  const money = (amount: number) => formatMoney(amount, order.currency, locale);
+  const text = (key: MessageKey, values: Record<string, string | number> = {}) =>
+    renderMessage(key, languageCode, values);
+
+  const lines = [
+    text("order_placed", {
+      order: order.number ? `#${order.number}` : order.orderId,
+      restaurant: restaurantName,
+    }),
+    "",
+  ];
+  for (const line of order.lines) {
+    lines.push(
+      line.unitPrice !== undefined
+        ? text("order_line_amount", {
+            quantity: line.quantity,
+            name: line.name,
+            amount: money(multiplyMoney(line.unitPrice, line.quantity, order.currency)),
+          })
+        : text("order_line", { quantity: line.quantity, name: line.name }),
+    );
+  }
+
+  const totals = order.totals;
+  lines.push("", text("order_subtotal", { amount: money(totals.subtotal) }));
+  if (totals.deliveryFee > 0) {
+    lines.push(text("order_delivery_fee", { amount: money(totals.deliveryFee) }));
+  }
+  if (totals.serviceFee > 0) {
+    lines.push(text("order_service_fee", { amount: money(totals.serviceFee) }));
+  }
+  if (totals.tip > 0) {
+    lines.push(text("order_tip", { amount: money(totals.tip) }));
+  }
+  if (totals.discount > 0) {
+    lines.push(text("order_discount", { amount: money(totals.discount) }));
+  }
+  lines.push(text("order_total", { amount: money(totals.grandTotal) }), "");
+
+  if (order.fulfillmentType === "PICKUP") {
+    if (order.pickupAddress) {
+      lines.push(text("order_pickup_at", { address: order.pickupAddress }));
+    }
+  } else {
+    const address = [
+      order.deliveryLocation?.address,
+      order.deliveryLocation?.city,
+      order.deliveryLocation?.country,
+    ]
+      .filter(Boolean)
+      .join(", ");
+    if (address) {
+      lines.push(text("order_delivery_to", { address }));
+    }
+  }
+  if (order.scheduledFor) {
+    lines.push(
+      text("order_scheduled_for", { time: formatTime(order.scheduledFor, locale, timeZone) }),
+    );
+  } else if (order.estimatedDeliveryAt) {
+    lines.push(
+      text("order_estimated_at", { time: formatTime(order.estimatedDeliveryAt, locale, timeZone) }),
+    );
+  }
+  lines.push(text("order_payment", { method: text(PAYMENT_METHOD_KEYS[order.paymentMethod]) }));
+
+  if (options.announcement) {
+    lines.push("", `${restaurantName}: ${options.announcement}`);
+  }
+  const link = buildOrderLink(order.orderId);
+  if (link) {
+    lines.push("", text("order_link", { link }));
+  }
+  return lines.join("\n");
+}
+
+/**
+ * Send the customer a receipt for a placed order, with the restaurant's
+ * announcement if one is set (ORDER_CONFIRMATION_MESSAGES=false turns
+ * them off)
+ */
+export async function notifyOrderPlaced(
+  userId: string,
+  order: OrderDetails,
+  restaurantName: string,
+  options: OrderMessageOptions = {},
+): Promise<boolean> {
+  if (!getBooleanVar("ORDER_CONFIRMATION_MESSAGES", true)) {
+    return false;
+  }
+  return sendTelegramMessage(userId, buildOrderPlacedMessage(order, restaurantName, options));
+}
@@ -0,0 +1,187 @@
+// Opening Hours Tests
+// Tests for openingHours.ts - tma_hours parsing and open/closed checks
+
+import { describe, it, expect, afterEach } from "vitest";
+import {
+  parseTimeRange,
+  parseOpeningHours,
+  getLocalTime,
+  isOpenAt,
+  isOpenNow,
+  shiftDateKey,
+  formatTimeRange,
+  getOpeningTransitions,
+  localTimeToInstant,
+} from "./openingHours";
+import { validateAsapOrder } from "./scheduledOrders";
+
+describe("parseTimeRange", () => {
+  it("should parse HH:MM-HH:MM into minutes", () => {
+    expect(parseTimeRange("09:00-14:30")).toEqual({ open: 540, close: 870 });
+    expect(parseTimeRange("18:00-02:00")).toEqual({ open: 1080, close: 120 });
+  });
+
+  it("should reject malformed ranges", () => {
+    expect(parseTimeRange("9-14")).toBeNull();
+    expect(parseTimeRange("25:00-26:00")).toBeNull();
+    expect(parseTimeRange("10:00-10:00")).toBeNull();
+  });
+});
+
+describe("parseOpeningHours", () => {
+  it("should return null without a schedule", () => {
+    expect(parseOpeningHours(undefined)).toBeNull();
+    expect(parseOpeningHours({ tma_hours: "not json" })).toBeNull();
+  });
+
+  it("should fall back to UTC for unknown timezones", () => {
+    const hours = parseOpeningHours({
+      tma_hours: JSON.stringify({ mon: ["09:00-17:00"] }),
+      tma_timezone: "Mars/Olympus",
+    });
+    expect(hours?.timezone).toBe("UTC");
+    expect(hours?.weekly.mon).toEqual([{ open: 540, close: 1020 }]);
+  });
+});
+
+describe("isOpenAt", () => {
+  const hours = parseOpeningHours({
+    tma_hours: JSON.stringify({
+      mon: ["09:00-14:00", "17:00-23:00"],
+      sat: ["18:00-02:00"],
+    }),
+    tma_timezone: "Europe/Berlin",
+  })!;
+
+  it("should use the restaurant timezone", () => {
+    // Monday 2026-01-05 08:30 UTC = 09:30 in Berlin
+    expect(isOpenAt(hours, new Date("2026-01-05T08:30:00Z"))).toBe(true);
+    // 07:30 UTC = 08:30 in Berlin
+    expect(isOpenAt(hours, new Date("2026-01-05T07:30:00Z"))).toBe(false);
+  });
+
+  it("should be closed between ranges and on missing days", () => {
+    expect(isOpenAt(hours, new Date("2026-01-05T14:00:00Z"))).toBe(false);
+    expect(isOpenAt(hours, new Date("2026-01-06T10:00:00Z"))).toBe(false);
+  });
+
+  it("should keep overnight ranges open past midnight", () => {
+    // Sunday 2026-01-11 00:30 UTC = 01:30 in Berlin, from Saturday's range
+    expect(isOpenAt(hours, new Date("2026-01-11T00:30:00Z"))).toBe(true);
+    expect(isOpenAt(hours, new Date("2026-01-11T02:30:00Z"))).toBe(false);
+  });
+});
+
+describe("date overrides", () => {
+  const metadata = {
+    tma_hours: JSON.stringify({ mon: ["09:00-17:00"], tue: ["09:00-17:00"] }),
+    tma_timezone: "UTC",
+    tma_hours_overrides: JSON.stringify({
+      "2026-01-05": [],
+      "2026-01-06": ["12:00-14:00"],
+      "not-a-date": ["00:00-23:00"],
+    }),
+  };
+  const hours = parseOpeningHours(metadata)!;
+
+  it("should close the restaurant on a holiday", () => {
+    expect(isOpenAt(hours, new Date("2026-01-05T10:00:00Z"))).toBe(false);
+    // The following Monday uses the weekly hours again
+    expect(isOpenAt(hours, new Date("2026-01-12T10:00:00Z"))).toBe(true);
+  });
+
+  it("should apply special hours instead of the weekly ones", () => {
+    expect(isOpenAt(hours, new Date("2026-01-06T10:00:00Z"))).toBe(false);
+    expect(isOpenAt(hours, new Date("2026-01-06T13:00:00Z"))).toBe(true);
+  });
+
+  it("should ignore invalid date keys", () => {
+    expect(Object.keys(hours.overrides)).toEqual(["2026-01-05", "2026-01-06"]);
+  });
+
+  it("should stay open all week when only overrides are set", () => {
+    const overridesOnly = {
+      tma_hours_overrides: JSON.stringify({ "2026-12-25": [] }),
+    };
+    expect(isOpenNow(overridesOnly, new Date("2026-12-24T10:00:00Z"))).toBe(true);
+    expect(isOpenNow(overridesOnly, new Date("2026-12-25T10:00:00Z"))).toBe(false);
+    expect(isOpenNow(undefined)).toBeNull();
+  });
+
+  it("should format ranges back to HH:MM-HH:MM", () => {
+    expect(formatTimeRange({ open: 540, close: 90 })).toBe("09:00-01:30");
+  });
+});
+
+describe("getLocalTime", () => {
+  it("should resolve weekday and date in the timezone", () => {
+    const local = getLocalTime(new Date("2026-01-04T23:30:00Z"), "Asia/Tokyo");
+    expect(local.weekday).toBe("mon");
+    expect(local.minutes).toBe(8 * 60 + 30);
+    expect(local.dateKey).toBe("2026-01-05");
+  });
+
+  it("should shift date keys across month boundaries", () => {
+    expect(shiftDateKey("2026-03-01", -1)).toBe("2026-02-28");
+  });
+});
+
+describe("getOpeningTransitions", () => {
+  const hours = parseOpeningHours({
+    tma_hours: JSON.stringify({
+      mon: ["09:00-14:00", "17:00-23:00"],
+      fri: ["22:00-24:00"],
+      sat: ["00:00-02:00"],
+    }),
+    tma_timezone: "Europe/Berlin",
+  })!;
+
+  it("should give the next opening while closed", () => {
+    // Monday 2026-01-05 07:30 UTC = 08:30 in Berlin
+    expect(getOpeningTransitions(hours, new Date("2026-01-05T07:30:00Z"))).toEqual({
+      opensAt: "2026-01-05T08:00:00.000Z",
+      closesAt: null,
+    });
+  });
+
+  it("should give the next closing while open", () => {
+    expect(getOpeningTransitions(hours, new Date("2026-01-05T16:30:00Z"))).toEqual({
+      opensAt: null,
+      closesAt: "2026-01-05T22:00:00.000Z",
+    });
+  });
+
+  it("should treat back to back ranges as one", () => {
+    // Friday 2026-01-09 22:30 in Berlin, open until Saturday 02:00
+    expect(getOpeningTransitions(hours, new Date("2026-01-09T21:30:00Z")).closesAt).toBe(
+      "2026-01-10T01:00:00.000Z",
+    );
+  });
+
+  it("should resolve local times across a DST change", () => {
+    expect(localTimeToInstant("2026-03-28", 180, "Europe/Berlin").toISOString()).toBe(
+      "2026-03-28T02:00:00.000Z",
+    );
+    expect(localTimeToInstant("2026-03-29", 180, "Europe/Berlin").toISOString()).toBe(
+      "2026-03-29T01:00:00.000Z",
+    );
+  });
+});
+
+describe("validateAsapOrder", () => {
+  const closedOnTuesday = { tma_hours: JSON.stringify({ mon: "09:00-17:00" }) };
+  const tuesday = new Date("2026-01-06T10:00:00Z");
+
+  afterEach(() => {
+    delete (globalThis as any).ACCEPT_ORDERS_WHEN_CLOSED;
+  });
+
+  it("should reject orders while closed", () => {
+    expect(() => validateAsapOrder(closedOnTuesday, tuesday)).toThrow(/closed now/);
+  });
+
+  it("should accept them with ACCEPT_ORDERS_WHEN_CLOSED", () => {
+    (globalThis as any).ACCEPT_ORDERS_WHEN_CLOSED = "true";
+    expect(() => validateAsapOrder(closedOnTuesday, tuesday)).not.toThrow();
+  });
+});
@@ -0,0 +1,325 @@
+// Restaurant Opening Hours
+// Parses the tma_hours channel metadata schedule and answers
+// "is the restaurant open at time X" in the restaurant's timezone.
+//
+// tma_hours format (JSON), times are local to tma_timezone (default UTC):
+//   { "mon": ["09:00-14:00", "17:00-23:00"], "sat": ["18:00-02:00"], "sun": [] }
+// Days that are missing or empty are closed; a range ending before it
+// starts runs past midnight into the next day.
+//
+// tma_hours_overrides (JSON) replaces the weekly hours on specific local
+// dates, e.g. holidays and special hours:
+//   { "2026-12-25": [], "2026-12-31": ["10:00-16:00"] }
+// Without tma_hours, days without an override are open all day.
+
+import { parseJSONValue } from "./metadata";
+
+export const HOURS_METADATA_KEY = "tma_hours";
+export const TIMEZONE_METADATA_KEY = "tma_timezone";
+export const HOURS_OVERRIDES_METADATA_KEY = "tma_hours_overrides";
+
+export type Weekday = "sun" | "mon" | "tue" | "wed" | "thu" | "fri" | "sat";
+
+// Ordered to match Date.getDay()
+export const WEEKDAYS: Weekday[] = ["sun", "mon", "tue", "wed", "thu", "fri", "sat"];
+
+/**
+ * Time range in minutes since local midnight
+ * close <= open means the range ends the following day
+ */
+export interface TimeRange {
+  open: number;
+  close: number;
+}
+
+export interface OpeningHours {
+  timezone: string;
+  weekly: Partial<Record<Weekday, TimeRange[]>>;
+  overrides: Record<string, TimeRange[]>; // YYYY-MM-DD -> ranges, [] = closed
+}
+
+const ALL_DAY: TimeRange[] = [{ open: 0, close: 24 * 60 }];
+const DATE_KEY_PATTERN = /^\d{4}-\d{2}-\d{2}$/;
+
+/**
+ * Local calendar position of an instant in a timezone
+ */
+export interface LocalTime {
+  weekday: Weekday;
+  minutes: number; // minutes since local midnight
+  dateKey: string; // YYYY-MM-DD in local time
+}
+
+function parseClock(value: string): number | null {
+  const match = value.trim().match(/^(\d{1,2}):(\d{2})$/);
+  if (!match) {
+    return null;
+  }
+  const hours = parseInt(match[1], 10);
+  const minutes = parseInt(match[2], 10);
+  if (hours > 24 || minutes > 59 || (hours === 24 && minutes > 0)) {
+    return null;
+  }
+  return hours * 60 + minutes;
+}
+
+/**
+ * Parse "HH:MM-HH:MM" into a TimeRange
+ */
+export function parseTimeRange(value: string): TimeRange | null {
+  const [openStr, closeStr] = value.split("-");
+  if (!openStr || !closeStr) {
+    return null;
+  }
+  const open = parseClock(openStr);
+  const close = parseClock(closeStr);
+  if (open === null || close === null || open === close) {
+    return null;
+  }
+  return { open, close };
+}
+
+function parseRanges(value: unknown): TimeRange[] {
+  const entries = Array.isArray(value) ? value : [value];
+  return entries
+    .filter((entry): entry is string => typeof entry === "string")
+    .map(parseTimeRange)
+    .filter((range): range is TimeRange => range !== null);
+}
+
+/**
+ * Check that an IANA timezone is usable by Intl
+ */
+export function isValidTimezone(timezone: string): boolean {
+  try {
+    new Intl.DateTimeFormat("en-US", { timeZone: timezone });
+    return true;
+  } catch {
+    return false;
+  }
+}
+
+function isRecord(value: unknown): value is Record<string, unknown> {
+  return !!value && typeof value === "object" && !Array.isArray(value);
+}
+
+/**
+ * Check a YYYY-MM-DD date key
+ */
+export function isValidDateKey(dateKey: string): boolean {
+  return (
+    DATE_KEY_PATTERN.test(dateKey) &&
+    !isNaN(new Date(`${dateKey}T00:00:00Z`).getTime()) &&
+    new Date(`${dateKey}T00:00:00Z`).toISOString().startsWith(dateKey)
+  );
+}
+
+/**
+ * Parse date-specific overrides from tma_hours_overrides
+ */
+export function parseHoursOverrides(
+  value: string | undefined,
+): Record<string, TimeRange[]> {
+  const raw = parseJSONValue<unknown>(value);
+  const overrides: Record<string, TimeRange[]> = {};
+  if (!isRecord(raw)) {
+    return overrides;
+  }
+  for (const [dateKey, ranges] of Object.entries(raw)) {
+    if (isValidDateKey(dateKey)) {
+      overrides[dateKey] = parseRanges(ranges);
+    }
+  }
+  return overrides;
+}
+
+/**
+ * Parse opening hours from restaurant metadata
+ * Returns null when no schedule is configured (treated as always open)
+ */
+export function parseOpeningHours(
+  metadata: Record<string, string> | undefined,
+): OpeningHours | null {
+  const raw = parseJSONValue<Record<string, unknown>>(
+    metadata?.[HOURS_METADATA_KEY],
+  );
+  const overrides = parseHoursOverrides(
+    metadata?.[HOURS_OVERRIDES_METADATA_KEY],
+  );
+  if (!isRecord(raw) && Object.keys(overrides).length === 0) {
+    return null;
+  }
+
+  const timezone = metadata?.[TIMEZONE_METADATA_KEY] || "UTC";
+  const weekly: Partial<Record<Weekday, TimeRange[]>> = {};
+  for (const day of WEEKDAYS) {
+    if (!isRecord(raw)) {
+      weekly[day] = ALL_DAY;
+    } else if (raw[day] !== undefined) {
+      weekly[day] = parseRanges(raw[day]);
+    }
+  }
+
+  return {
+    timezone: isValidTimezone(timezone) ? timezone : "UTC",
+    weekly,
+    overrides,
+  };
+}
+
+/**
+ * Resolve an instant to weekday/minutes/date in a timezone
+ */
+export function getLocalTime(date: Date, timezone: string): LocalTime {
+  const parts = new Intl.DateTimeFormat("en-US", {
+    timeZone: timezone,
+    weekday: "short",
+    year: "numeric",
+    month: "2-digit",
+    day: "2-digit",
+    hour: "2-digit",
+    minute: "2-digit",
+    hourCycle: "h23",
+  }).formatToParts(date);
+
+  const get = (type: string) => parts.find((p) => p.type === type)?.value || "";
+  const weekday = get("weekday").toLowerCase().substring(0, 3) as Weekday;
+  const hour = parseInt(get("hour"), 10) % 24;
+  const minute = parseInt(get("minute"), 10);
+
+  return {
+    weekday: WEEKDAYS.includes(weekday) ? weekday : WEEKDAYS[date.getUTCDay()],
+    minutes: hour * 60 + minute,
+    dateKey: `${get("year")}-${get("month")}-${get("day")}`,
+  };
+}
+
+function previousWeekday(day: Weekday): Weekday {
+  return WEEKDAYS[(WEEKDAYS.indexOf(day) + 6) % 7];
+}
+
+/**
+ * Ranges that apply on a given local day (date overrides win)
+ */
+export function getRangesForDay(
+  hours: OpeningHours,
+  local: LocalTime,
+): TimeRange[] {
+  return hours.overrides[local.dateKey] ?? hours.weekly[local.weekday] ?? [];
+}
+
+/**
+ * Format a TimeRange back to "HH:MM-HH:MM"
+ */
+export function formatTimeRange(range: TimeRange): string {
+  return `${formatClock(range.open)}-${formatClock(range.close)}`;
+}
+
+function formatClock(minutes: number): string {
+  const hours = String(Math.floor(minutes / 60)).padStart(2, "0");
+  return `${hours}:${String(minutes % 60).padStart(2, "0")}`;
+}
+
+/**
+ * Check whether the restaurant is open at an instant
+ */
+export function isOpenAt(hours: OpeningHours, date: Date): boolean {
+  const local = getLocalTime(date, hours.timezone);
+
+  for (const range of getRangesForDay(hours, local)) {
+    if (range.close > range.open) {
+      if (local.minutes >= range.open && local.minutes < range.close) {
+        return true;
+      }
+    } else if (local.minutes >= range.open) {
+      return true;
+    }
+  }
+
+  // Overnight ranges from the previous day spill into today
+  const yesterday: LocalTime = {
+    ...local,
+    weekday: previousWeekday(local.weekday),
+    dateKey: shiftDateKey(local.dateKey, -1),
+  };
+  for (const range of getRangesForDay(hours, yesterday)) {
+    if (range.close <= range.open && local.minutes < range.close) {
+      return true;
+    }
+  }
+
+  return false;
+}
+
+/**
+ * Whether a restaurant is open now; null when it has no opening hours
+ */
+export function isOpenNow(
+  metadata: Record<string, string> | undefined,
+  now: Date = new Date(),
+): boolean | null {
+  const hours = parseOpeningHours(metadata);
+  return hours ? isOpenAt(hours, now) : null;
+}
+
+/**
+ * Instant of a local wall-clock time (minutes may run past midnight)
+ * Resolved twice so a DST change between the guess and the answer is caught
+ */
+export function localTimeToInstant(dateKey: string, minutes: number, timezone: string): Date {
+  const target = Date.parse(`${dateKey}T00:00:00Z`) + minutes * 60 * 1000;
+  let instant = target;
+  for (let i = 0; i < 2; i++) {
+    const local = getLocalTime(new Date(instant), timezone);
+    const shown = Date.parse(`${local.dateKey}T00:00:00Z`) + local.minutes * 60 * 1000;
+    instant += target - shown;
+  }
+  return new Date(instant);
+}
+
+/**
+ * When a restaurant next opens (while closed) or closes (while open)
+ * Looks a week ahead; both are null when nothing changes in that time
+ */
+export function getOpeningTransitions(
+  hours: OpeningHours,
+  now: Date,
+): { opensAt: string | null; closesAt: string | null } {
+  const open = isOpenAt(hours, now);
+  const today = getLocalTime(now, hours.timezone);
+  const candidates: number[] = [];
+  // From yesterday, whose overnight range may close this morning
+  for (let day = -1; day <= 7; day++) {
+    const dateKey = shiftDateKey(today.dateKey, day);
+    const local: LocalTime = {
+      weekday: WEEKDAYS[new Date(`${dateKey}T00:00:00Z`).getUTCDay()],
+      minutes: 0,
+      dateKey,
+    };
+    for (const range of getRangesForDay(hours, local)) {
+      const boundary = open
+        ? range.close > range.open
+          ? range.close
+          : range.close + 24 * 60
+        : range.open;
+      candidates.push(localTimeToInstant(dateKey, boundary, hours.timezone).getTime());
+    }
+  }
+
+  // Back to back ranges (22:00-24:00, then 00:00-02:00) aren't a change
+  const next = candidates
+    .filter((instant) => instant > now.getTime())
+    .sort((a, b) => a - b)
+    .find((instant) => isOpenAt(hours, new Date(instant)) !== open);
+  const at = next !== undefined ? new Date(next).toISOString() : null;
+  return open ? { opensAt: null, closesAt: at } : { opensAt: at, closesAt: null };
+}
+
+/**
+ * Shift a YYYY-MM-DD key by a number of days
+ */
+export function shiftDateKey(dateKey: string, days: number): string {
+  const date = new Date(`${dateKey}T00:00:00Z`);
+  date.setUTCDate(date.getUTCDate() + days);
+  return date.toISOString().substring(0, 10);
+}
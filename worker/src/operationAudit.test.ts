@@ -0,0 +1,98 @@
+// Operation Audit Tests
+// Tests for operationAudit.ts - document hashing and folding recorded hits
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { listKeys } from "./storage";
+import {
+  extractOperationName,
+  foldOperationHits,
+  listAuditedOperations,
+  normalizeDocument,
+  recordOperation,
+  sha256Hex,
+} from "./operationAudit";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+afterEach(() => {
+  delete (globalThis as any).OPERATION_AUDIT_MODE;
+  delete (globalThis as any).OPERATION_ALLOWLIST;
+});
+
+describe("normalizeDocument", () => {
+  it("should drop comments and formatting", () => {
+    const formatted = `
+      # Cart lookup
+      query Cart($id: ID!) {
+        cart(id: $id) { items { dishId } }
+      }
+    `;
+    expect(normalizeDocument(formatted)).toBe("query Cart($id:ID!){cart(id:$id){items{dishId}}}");
+    expect(normalizeDocument("query Cart($id: ID!) {cart(id:$id){items{dishId}}}")).toBe(
+      normalizeDocument(formatted),
+    );
+  });
+});
+
+describe("sha256Hex", () => {
+  it("should return the hex SHA-256 digest", async () => {
+    expect(await sha256Hex("abc")).toBe(
+      "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+    );
+  });
+});
+
+describe("extractOperationName", () => {
+  it("should prefer the request's operation name over the document's", () => {
+    expect(extractOperationName("query Cart { cart { id } }")).toBe("Cart");
+    expect(extractOperationName(" mutation AddToCart($x: Int) { a }")).toBe("AddToCart");
+    expect(extractOperationName("query Cart { cart { id } }", "Other")).toBe("Other");
+    expect(extractOperationName("{ cart { id } }")).toBeNull();
+  });
+});
+
+describe("recordOperation", () => {
+  it("should count concurrent requests once folded", async () => {
+    (globalThis as any).OPERATION_AUDIT_MODE = "record";
+    const query = "query Cart { cart { id } }";
+
+    await Promise.all([
+      recordOperation(query, {}),
+      recordOperation(query, {}),
+      recordOperation(query, { id: "x" }),
+    ]);
+    expect(await listAuditedOperations()).toHaveLength(0);
+
+    expect(await foldOperationHits()).toBe(3);
+    expect(await listKeys("audit:hit:")).toHaveLength(0);
+    const counts = (await listAuditedOperations()).map((record) => record.count);
+    expect(counts).toEqual([2, 1]);
+
+    await recordOperation(query, {});
+    expect(await foldOperationHits()).toBe(1);
+    const [top] = await listAuditedOperations();
+    expect(top).toMatchObject({ operationName: "Cart", count: 3, allowlisted: false });
+  });
+
+  it("should fold a bounded number of hits per run", async () => {
+    (globalThis as any).OPERATION_AUDIT_MODE = "record";
+    const query = "query Menu { menu { id } }";
+    for (let i = 0; i < 320; i++) {
+      await recordOperation(query, { page: i });
+    }
+
+    expect(await foldOperationHits()).toBe(300);
+    expect(await listKeys("audit:hit:")).toHaveLength(20);
+    expect(await foldOperationHits()).toBe(20);
+    const menu = (await listAuditedOperations()).find((r) => r.operationName === "Menu");
+    expect(menu?.count).toBe(320);
+  });
+
+  it("should record nothing while audit mode is off", async () => {
+    await recordOperation("query Menu { menu { id } }", {});
+    expect(await listKeys("audit:hit:")).toHaveLength(0);
+  });
+});
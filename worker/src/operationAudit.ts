@@ -0,0 +1,267 @@
+// Operation Audit Mode
+// Records every distinct GraphQL document + variables shape (hashed) so
+// admins can build the persisted-query allowlist and spot unexpected clients.
+// Audit mode never blocks a request - allowlist misses are only logged.
+// Requests only write their own hit record (audit:hit:...) in the
+// background; the cron job folds hits into the records, so concurrent
+// requests can't lose each other's count. Records are kept per document
+// (one entry per variables shape) so a fold writes each document once, and
+// a fold stays well within the Workers limit of 1000 KV operations.
+
+import { OperationAuditRecord } from "./contracts";
+import { getListVar, getStringVar } from "./config";
+import { logger } from "./logger";
+import { deleteKey, listKeys, readJSON, writeJSON, readAllJSON } from "./storage";
+
+const AUDIT_KEY_PREFIX = "audit:op:";
+const HIT_KEY_PREFIX = "audit:hit:";
+// Hits and documents folded per cron run (a read and a delete per hit, a
+// read and a write per document); the rest wait for the next one
+const MAX_HITS_PER_FOLD = 300;
+const MAX_DOCUMENTS_PER_FOLD = 50;
+
+/**
+ * One request's sighting of an operation, waiting to be folded
+ */
+interface OperationHitRecord {
+  documentHash: string;
+  variablesShapeHash: string;
+  operationName: string | null;
+  document: string;
+  variablesShape: string;
+  seenAt: string; // ISO timestamp
+}
+
+// Hits of one variables shape and their keys
+interface HitGroup {
+  hits: OperationHitRecord[];
+  keys: string[];
+}
+
+// Records expire after 30 days without traffic
+const AUDIT_TTL_SECONDS = 30 * 24 * 60 * 60;
+
+// Keep stored documents bounded
+const MAX_DOCUMENT_LENGTH = 4000;
+
+/**
+ * Audit is enabled with OPERATION_AUDIT_MODE=record (default: off)
+ */
+export function isOperationAuditEnabled(): boolean {
+  return getStringVar("OPERATION_AUDIT_MODE", "off").toLowerCase() === "record";
+}
+
+/**
+ * Document hashes considered known (OPERATION_ALLOWLIST, comma separated)
+ */
+export function getOperationAllowlist(): string[] {
+  return getListVar("OPERATION_ALLOWLIST");
+}
+
+/**
+ * Strip comments and collapse whitespace so formatting changes
+ * don't produce new document hashes
+ */
+export function normalizeDocument(query: string): string {
+  return query
+    .replace(/#[^\n\r]*/g, "")
+    .replace(/\s+/g, " ")
+    .replace(/\s*([{}():,!$=@\[\]])\s*/g, "$1")
+    .trim();
+}
+
+/**
+ * Describe the shape of GraphQL variables without their values
+ * e.g. { input: { dishId: "x", quantity: 2 } } -> { input: { dishId: "string", quantity: "number" } }
+ */
+export function describeVariablesShape(value: unknown): unknown {
+  if (value === null || value === undefined) {
+    return "null";
+  }
+  if (Array.isArray(value)) {
+    return value.length > 0 ? [describeVariablesShape(value[0])] : [];
+  }
+  if (typeof value === "object") {
+    const shape: Record<string, unknown> = {};
+    for (const key of Object.keys(value as Record<string, unknown>).sort()) {
+      shape[key] = describeVariablesShape(
+        (value as Record<string, unknown>)[key],
+      );
+    }
+    return shape;
+  }
+  return typeof value;
+}
+
+/**
+ * Extract the operation name from the request or document
+ */
+export function extractOperationName(
+  query: string,
+  operationName?: string | null,
+): string | null {
+  if (operationName) {
+    return operationName;
+  }
+  const match = query.match(/^\s*(?:query|mutation|subscription)\s+([A-Za-z_]\w*)/);
+  return match ? match[1] : null;
+}
+
+/**
+ * SHA-256 hex digest
+ */
+export async function sha256Hex(input: string): Promise<string> {
+  const digest = await crypto.subtle.digest(
+    "SHA-256",
+    new TextEncoder().encode(input),
+  );
+  return Array.from(new Uint8Array(digest))
+    .map((b) => b.toString(16).padStart(2, "0"))
+    .join("");
+}
+
+/**
+ * Record a GraphQL operation seen by the worker
+ * Errors are swallowed - auditing must never fail a request
+ */
+export async function recordOperation(
+  query: string,
+  variables: unknown,
+  operationName?: string | null,
+): Promise<void> {
+  if (!isOperationAuditEnabled() || !query) {
+    return;
+  }
+
+  try {
+    const document = normalizeDocument(query);
+    const variablesShape = JSON.stringify(describeVariablesShape(variables ?? {}));
+    const documentHash = await sha256Hex(document);
+    const variablesShapeHash = await sha256Hex(variablesShape);
+    const hit: OperationHitRecord = {
+      documentHash,
+      variablesShapeHash,
+      operationName: extractOperationName(query, operationName),
+      document: document.substring(0, MAX_DOCUMENT_LENGTH),
+      variablesShape,
+      seenAt: new Date().toISOString(),
+    };
+    await writeJSON(
+      `${HIT_KEY_PREFIX}${documentHash}:${variablesShapeHash}:${crypto.randomUUID()}`,
+      hit,
+      { expirationTtl: AUDIT_TTL_SECONDS },
+    );
+
+    const allowlist = getOperationAllowlist();
+    if (allowlist.length > 0 && !allowlist.includes(documentHash)) {
+      logger.warn("operation_not_allowlisted", {
+        documentHash,
+        operationName: hit.operationName,
+      });
+    }
+  } catch (error) {
+    logger.error("operation_audit_failed", {
+      error: error instanceof Error ? error.message : "Unknown error",
+    });
+  }
+}
+
+/**
+ * Scheduled job: fold recorded hits into the per-document records
+ * Returns the number of hits folded
+ */
+export async function foldOperationHits(): Promise<number> {
+  const keys = await listKeys(HIT_KEY_PREFIX, MAX_HITS_PER_FOLD);
+  // Hits by document hash, then by variables shape hash
+  const documents = new Map<string, Map<string, HitGroup>>();
+  for (const key of keys) {
+    const hit = await readJSON<OperationHitRecord>(key);
+    if (!hit) {
+      continue;
+    }
+    let shapes = documents.get(hit.documentHash);
+    if (!shapes) {
+      if (documents.size >= MAX_DOCUMENTS_PER_FOLD) {
+        continue;
+      }
+      shapes = new Map();
+      documents.set(hit.documentHash, shapes);
+    }
+    const group = shapes.get(hit.variablesShapeHash) || { hits: [], keys: [] };
+    group.hits.push(hit);
+    group.keys.push(key);
+    shapes.set(hit.variablesShapeHash, group);
+  }
+
+  const allowlist = getOperationAllowlist();
+  let folded = 0;
+  for (const [documentHash, shapes] of documents) {
+    const recordKey = `${AUDIT_KEY_PREFIX}${documentHash}`;
+    const records = (await readJSON<OperationAuditRecord[]>(recordKey)) || [];
+    const hitKeys: string[] = [];
+    for (const [variablesShapeHash, { hits, keys: groupKeys }] of shapes) {
+      const [first] = hits;
+      const seen = hits.map((hit) => hit.seenAt).sort();
+      const index = records.findIndex((r) => r.variablesShapeHash === variablesShapeHash);
+      const existing = index >= 0 ? records[index] : null;
+      const record: OperationAuditRecord = existing
+        ? {
+            ...existing,
+            count: existing.count + hits.length,
+            lastSeenAt:
+              existing.lastSeenAt > seen[seen.length - 1]
+                ? existing.lastSeenAt
+                : seen[seen.length - 1],
+          }
+        : {
+            documentHash,
+            variablesShapeHash,
+            operationName: first.operationName,
+            document: first.document,
+            variablesShape: first.variablesShape,
+            count: hits.length,
+            firstSeenAt: seen[0],
+            lastSeenAt: seen[seen.length - 1],
+            allowlisted: false,
+          };
+      record.allowlisted = allowlist.includes(documentHash);
+      if (existing) {
+        records[index] = record;
+      } else {
+        records.push(record);
+        logger.info("operation_audit_new_document", {
+          documentHash,
+          variablesShapeHash,
+          operationName: record.operationName,
+        });
+      }
+      hitKeys.push(...groupKeys);
+    }
+
+    await writeJSON(recordKey, records, { expirationTtl: AUDIT_TTL_SECONDS });
+    for (const key of hitKeys) {
+      await deleteKey(key);
+    }
+    folded += hitKeys.length;
+  }
+  return folded;
+}
+
+/**
+ * List recorded operations, most frequent first
+ */
+export async function listAuditedOperations(
+  onlyUnlisted = false,
+): Promise<OperationAuditRecord[]> {
+  const documents = await readAllJSON<OperationAuditRecord[]>(AUDIT_KEY_PREFIX);
+  const allowlist = getOperationAllowlist();
+
+  return documents
+    .flat()
+    .map((record) => ({
+      ...record,
+      allowlisted: allowlist.includes(record.documentHash),
+    }))
+    .filter((record) => !onlyUnlisted || !record.allowlisted)
+    .sort((a, b) => b.count - a.count);
+}
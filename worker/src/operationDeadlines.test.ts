@@ -0,0 +1,64 @@
+// Operation Deadlines Tests
+// Tests for operationDeadlines.ts - budgets and the deadline middleware
+
+import { describe, it, expect, afterEach } from "vitest";
+import {
+  getOperationBudgetMs,
+  getRemainingBudgetMs,
+  withOperationDeadlines,
+} from "./operationDeadlines";
+import { GraphQLContext } from "./contracts";
+
+afterEach(() => {
+  delete (globalThis as any).OPERATION_TIMEOUTS;
+  delete (globalThis as any).OPERATION_TIMEOUT_MS;
+});
+
+function makeContext(): GraphQLContext {
+  return { auth: { userId: "u1", valid: true } };
+}
+
+const sleep = (ms: number) => new Promise((resolve) => setTimeout(resolve, ms));
+
+describe("getOperationBudgetMs", () => {
+  it("should use built-in budgets and type defaults", () => {
+    expect(getOperationBudgetMs("Query", "categoryDishes")).toBe(2000);
+    expect(getOperationBudgetMs("Mutation", "placeOrder")).toBe(10000);
+    expect(getOperationBudgetMs("Query", "orderHistory")).toBe(5000);
+    expect(getOperationBudgetMs("Mutation", "submitReview")).toBe(8000);
+  });
+
+  it("should apply OPERATION_TIMEOUTS overrides", () => {
+    (globalThis as any).OPERATION_TIMEOUTS = "placeOrder=12000, cart=0, bogus";
+    (globalThis as any).OPERATION_TIMEOUT_MS = "3000";
+    expect(getOperationBudgetMs("Mutation", "placeOrder")).toBe(12000);
+    expect(getOperationBudgetMs("Query", "cart")).toBe(0);
+    expect(getOperationBudgetMs("Query", "orderHistory")).toBe(3000);
+  });
+});
+
+describe("withOperationDeadlines", () => {
+  it("should fail slow resolvers with TIMEOUT", async () => {
+    (globalThis as any).OPERATION_TIMEOUTS = "slow=20";
+    const resolvers = withOperationDeadlines("Query", {
+      slow: async () => {
+        await sleep(200);
+        return "late";
+      },
+    });
+    await expect(resolvers.slow(null, {}, makeContext())).rejects.toMatchObject({
+      code: "TIMEOUT",
+    });
+  });
+
+  it("should expose the deadline on the context", async () => {
+    const context = makeContext();
+    const resolvers = withOperationDeadlines("Query", {
+      fast: async (_: any, __: any, ctx: GraphQLContext) => getRemainingBudgetMs(ctx),
+    });
+    const remaining = await resolvers.fast(null, {}, context);
+    expect(remaining).toBeGreaterThan(4000);
+    expect(remaining).toBeLessThanOrEqual(5000);
+    expect(getRemainingBudgetMs(undefined)).toBe(Infinity);
+  });
+});
@@ -0,0 +1,102 @@
+// Per-Operation Deadlines
+// Each Query/Mutation field gets a time budget (menu reads 2s, placeOrder
+// 10s, others 5s/8s by default; OPERATION_TIMEOUTS overrides). The deadline
+// is set on the GraphQL context so resolvers can skip optional work, and a
+// resolver still running when it passes fails with TIMEOUT instead of
+// holding the connection until Saleor answers. Work already started is not
+// cancelled, so budgets for writes are generous.
+
+import { GraphQLContext } from "./contracts";
+import { getListVar, getNumberVar } from "./config";
+import { timeoutError } from "./errors";
+import { logger } from "./logger";
+
+const DEFAULT_QUERY_BUDGET_MS = 5000;
+const DEFAULT_MUTATION_BUDGET_MS = 8000;
+
+const OPERATION_BUDGETS_MS: Record<string, number> = {
+  restaurants: 2000,
+  restaurantCategories: 2000,
+  categoryDishes: 2000,
+  restaurantsConnection: 2000,
+  restaurantCategoriesConnection: 2000,
+  categoryDishesConnection: 2000,
+  dishesByIds: 2000,
+  featuredDishes: 2000,
+  dish: 2000,
+  cart: 2000,
+  placeOrder: 10000,
+};
+
+/**
+ * Budget for an operation in ms; 0 disables the deadline
+ * OPERATION_TIMEOUTS entries ("placeOrder=12000,categoryDishes=3000")
+ * override the defaults, OPERATION_TIMEOUT_MS the fallback for the rest
+ */
+export function getOperationBudgetMs(type: string, field: string): number {
+  for (const entry of getListVar("OPERATION_TIMEOUTS")) {
+    const [name, value] = entry.split("=").map((part) => part.trim());
+    const ms = Number(value);
+    if (name === field && Number.isFinite(ms) && ms >= 0) {
+      return ms;
+    }
+  }
+  if (OPERATION_BUDGETS_MS[field] !== undefined) {
+    return OPERATION_BUDGETS_MS[field];
+  }
+  return Math.max(
+    0,
+    getNumberVar(
+      "OPERATION_TIMEOUT_MS",
+      type === "Mutation" ? DEFAULT_MUTATION_BUDGET_MS : DEFAULT_QUERY_BUDGET_MS,
+    ),
+  );
+}
+
+/**
+ * Milliseconds left before the operation's deadline, Infinity without one
+ */
+export function getRemainingBudgetMs(
+  context: GraphQLContext | undefined,
+  now: number = Date.now(),
+): number {
+  return context?.deadline ? Math.max(0, context.deadline - now) : Infinity;
+}
+
+/**
+ * Wrap resolvers so each call runs against its operation's deadline
+ */
+export function withOperationDeadlines<T extends Record<string, (...args: any[]) => any>>(
+  type: string,
+  resolvers: T,
+): T {
+  const wrapped: Record<string, (...args: any[]) => any> = { ...resolvers };
+  for (const [name, resolver] of Object.entries(resolvers)) {
+    wrapped[name] = async (...args: any[]) => {
+      const budgetMs = getOperationBudgetMs(type, name);
+      const context = args[2] as GraphQLContext | undefined;
+      if (budgetMs <= 0 || !context) {
+        return resolver(...args);
+      }
+      context.deadline = Date.now() + budgetMs;
+
+      let timer: ReturnType<typeof setTimeout> | undefined;
+      const expired = new Promise<never>((_, reject) => {
+        timer = setTimeout(() => {
+          logger.warn("operation_deadline_exceeded", {
+            operation: `${type}.${name}`,
+            budgetMs,
+            userId: context.auth?.userId,
+          });
+          reject(timeoutError());
+        }, budgetMs);
+      });
+      try {
+        return await Promise.race([resolver(...args), expired]);
+      } finally {
+        clearTimeout(timer);
+      }
+    };
+  }
+  return wrapped as T;
+}
@@ -0,0 +1,75 @@
+// Order Fulfillment Tests
+// Tests for orderFulfillment.ts - Saleor fulfillments for delivered orders
+
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import { buildFulfillmentLines, markOrderDelivered } from "./orderFulfillment";
+import { clearOrders, createSaleorOrder, getOrder } from "./saleorOrder";
+import { buildPlaceOrderInput } from "./testHelpers";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+const orderInput = buildPlaceOrderInput();
+
+describe("buildFulfillmentLines", () => {
+  it("should take allocated stock first and skip fulfilled lines", () => {
+    const lines = buildFulfillmentLines([
+      {
+        id: "line-1",
+        quantityToFulfill: 3,
+        allocations: [
+          { quantity: 1, warehouse: { id: "wh-1" } },
+          { quantity: 1, warehouse: { id: "wh-2" } },
+        ],
+        variant: null,
+      },
+      {
+        id: "line-2",
+        quantityToFulfill: 2,
+        allocations: [],
+        variant: { stocks: [{ warehouse: { id: "wh-3" } }] },
+      },
+      { id: "line-3", quantityToFulfill: 0, allocations: [], variant: null },
+    ]);
+    expect(lines).toEqual([
+      {
+        orderLineId: "line-1",
+        stocks: [
+          { quantity: 2, warehouse: "wh-1" },
+          { quantity: 1, warehouse: "wh-2" },
+        ],
+      },
+      { orderLineId: "line-2", stocks: [{ quantity: 2, warehouse: "wh-3" }] },
+    ]);
+  });
+
+  it("should give up when a line has no warehouse", () => {
+    const lines = [{ id: "line-1", quantityToFulfill: 1, allocations: null, variant: null }];
+    expect(buildFulfillmentLines(lines)).toBeNull();
+  });
+});
+
+describe("markOrderDelivered", () => {
+  beforeEach(() => {
+    clearOrders();
+  });
+
+  it("should record the delivery once and fulfill the order", async () => {
+    const order = (await createSaleorOrder(orderInput, "user-1")).order!;
+    const now = new Date("2026-05-01T12:00:00.000Z");
+
+    const result = await markOrderDelivered(order.id, "courier-1", now);
+    expect(result).toMatchObject({ deliveredAt: now.toISOString(), fulfilled: true });
+    expect(getOrder(order.id)?.status).toBe("FULFILLED");
+
+    const again = await markOrderDelivered(order.id, "courier-2", new Date());
+    expect(again.deliveredAt).toBe(now.toISOString());
+  });
+
+  it("should refuse cancelled orders", async () => {
+    const order = (await createSaleorOrder(orderInput, "user-1")).order!;
+    getOrder(order.id)!.status = "CANCELLED";
+    await expect(markOrderDelivered(order.id, "courier-1")).rejects.toThrow("cancelled");
+  });
+});
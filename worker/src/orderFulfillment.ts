@@ -0,0 +1,193 @@
+// Order Fulfillment
+// When the courier marks an order delivered, the worker records
+// tma.deliveredAt and creates the matching Saleor fulfillment (orderFulfill)
+// for every line still to fulfill, so Saleor's order status and stock
+// accounting follow the app. Stock comes from the warehouses Saleor
+// allocated it in, the rest from the first warehouse stocking the variant.
+// A fulfillment that fails is retried when the order is marked delivered
+// again; the delivery itself stays recorded.
+
+import { OrderDelivery } from "./contracts";
+import { badUserInputError, notFoundError } from "./errors";
+import { logger } from "./logger";
+import {
+  ORDER_FULFILL_MUTATION,
+  ORDER_FULFILLMENT_LINES_QUERY,
+  getSaleorClient,
+  isSaleorConfigured,
+} from "./saleorClient";
+import {
+  ORDER_METADATA_KEYS,
+  addOrderNote,
+  fetchOrderById,
+  getNormalizedStatus,
+  isAwaitingAbuseReview,
+  updateOrderMetadata,
+  updateOrderMetadataWith,
+} from "./saleorOrder";
+import {
+  OrderFulfillMutation,
+  OrderFulfillMutationVariables,
+  OrderFulfillmentLinesQuery,
+} from "./saleorOperations";
+
+/**
+ * Order line as returned by ORDER_FULFILLMENT_LINES_QUERY
+ */
+export type FulfillmentLineNode = NonNullable<OrderFulfillmentLinesQuery["order"]>["lines"][number];
+
+/**
+ * OrderFulfillLineInput
+ */
+export type FulfillLineInput = OrderFulfillMutationVariables["input"]["lines"][number] & {
+  orderLineId: string;
+};
+
+// Saleor statuses with nothing left to fulfill
+const FULFILLED_STATUSES = ["FULFILLED", "DELIVERED"];
+
+/**
+ * Stock to take for each line still to fulfill: allocations first, the
+ * rest from the first allocated (else stocked) warehouse. Null when a line
+ * has no warehouse to take it from.
+ */
+export function buildFulfillmentLines(lines: FulfillmentLineNode[]): FulfillLineInput[] | null {
+  const result: FulfillLineInput[] = [];
+  for (const line of lines) {
+    let remaining = line.quantityToFulfill;
+    if (remaining <= 0) {
+      continue;
+    }
+    const quantities = new Map<string, number>();
+    for (const allocation of line.allocations || []) {
+      const quantity = Math.min(allocation.quantity, remaining);
+      if (quantity > 0) {
+        const warehouse = allocation.warehouse.id;
+        quantities.set(warehouse, (quantities.get(warehouse) || 0) + quantity);
+        remaining -= quantity;
+      }
+    }
+    if (remaining > 0) {
+      const warehouse =
+        line.allocations?.[0]?.warehouse.id ?? line.variant?.stocks?.[0]?.warehouse.id;
+      if (!warehouse) {
+        return null;
+      }
+      quantities.set(warehouse, (quantities.get(warehouse) || 0) + remaining);
+    }
+    result.push({
+      orderLineId: line.id,
+      stocks: Array.from(quantities, ([warehouse, quantity]) => ({ quantity, warehouse })),
+    });
+  }
+  return result;
+}
+
+/**
+ * Create the Saleor fulfillment for everything still to fulfill
+ * Returns the fulfillment ID ("" when there was nothing left), or null
+ * when it failed. Mock orders are marked FULFILLED.
+ */
+export async function fulfillSaleorOrder(orderId: string): Promise<string | null> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    const order = await fetchOrderById(orderId);
+    if (!order) {
+      return null;
+    }
+    order.status = "FULFILLED";
+    return "";
+  }
+
+  const response = await client.execute<OrderFulfillmentLinesQuery>(ORDER_FULFILLMENT_LINES_QUERY, {
+    id: orderId,
+  });
+  const order = response.data?.order;
+  if (!order) {
+    logger.error("saleor_order_fulfill_error", {
+      orderId,
+      error: response.errors?.map((e) => e.message).join(", ") || "Order not found",
+    });
+    return null;
+  }
+  if (FULFILLED_STATUSES.includes(order.status)) {
+    return "";
+  }
+
+  const lines = buildFulfillmentLines(order.lines);
+  if (!lines) {
+    logger.error("saleor_order_fulfill_error", { orderId, error: "No warehouse for a line" });
+    return null;
+  }
+  if (lines.length === 0) {
+    return "";
+  }
+
+  // The app has already told the customer
+  const variables: OrderFulfillMutationVariables = {
+    order: orderId,
+    input: { lines, notifyCustomer: false },
+  };
+  const { data, error } = await client.mutate<OrderFulfillMutation>(
+    ORDER_FULFILL_MUTATION,
+    variables,
+    "OrderFulfill",
+    "orderFulfill",
+  );
+  if (error) {
+    logger.error("saleor_order_fulfill_error", { orderId, code: error.code ?? null });
+    return null;
+  }
+  return data?.orderFulfill?.fulfillments?.[0]?.id ?? "";
+}
+
+/**
+ * Mark an order delivered and fulfill it in Saleor
+ * The first call records the delivery; calls after a failed fulfillment
+ * retry it
+ */
+export async function markOrderDelivered(
+  orderId: string,
+  deliveredBy: string,
+  now: Date = new Date(),
+): Promise<OrderDelivery> {
+  const order = await fetchOrderById(orderId);
+  if (!order) {
+    throw notFoundError("Order not found");
+  }
+  const status = getNormalizedStatus(order);
+  if (status === "CANCELLED" || status === "EXPIRED") {
+    throw badUserInputError("This order was cancelled", "orderId");
+  }
+  if (isAwaitingAbuseReview(order)) {
+    throw badUserInputError("Order is awaiting review", "orderId");
+  }
+
+  let deliveredAt = order.metadata?.[ORDER_METADATA_KEYS.deliveredAt];
+  if (!deliveredAt) {
+    const recorded = await updateOrderMetadataWith(orderId, (metadata) =>
+      metadata[ORDER_METADATA_KEYS.deliveredAt]
+        ? null
+        : { [ORDER_METADATA_KEYS.deliveredAt]: now.toISOString() },
+    );
+    if (recorded) {
+      await addOrderNote(orderId, "Delivered to the customer");
+      logger.info("order_delivered", { orderId, deliveredBy });
+    }
+    deliveredAt =
+      recorded?.[ORDER_METADATA_KEYS.deliveredAt] ??
+      (await fetchOrderById(orderId))?.metadata?.[ORDER_METADATA_KEYS.deliveredAt] ??
+      now.toISOString();
+  }
+
+  let fulfilled = FULFILLED_STATUSES.includes(order.status);
+  if (!fulfilled) {
+    const fulfillmentId = await fulfillSaleorOrder(orderId);
+    fulfilled = fulfillmentId !== null;
+    if (fulfillmentId) {
+      await updateOrderMetadata(orderId, { [ORDER_METADATA_KEYS.fulfillmentId]: fulfillmentId });
+    }
+  }
+
+  return { orderId, number: order.number ?? null, deliveredAt, fulfilled };
+}
@@ -0,0 +1,87 @@
+// Order Handoff
+// Staff check the customer's handoff code (handoffCodes.ts) before handing
+// over a pickup or dine-in order, so the wrong bag doesn't leave the
+// counter. The first successful check records tma.handedOffAt and a note
+// in the order's history. Staff views of orders never include the code,
+// so it can't be read off the kitchen screen.
+
+import { HandoffVerification, OrderDetails } from "./contracts";
+import { badUserInputError, notFoundError } from "./errors";
+import { buildHandoffQrPayload } from "./handoffCodes";
+import { logger } from "./logger";
+import {
+  ORDER_METADATA_KEYS,
+  SaleorOrder,
+  addOrderNote,
+  fetchOrderById,
+  isAwaitingAbuseReview,
+  updateOrderMetadataWith,
+} from "./saleorOrder";
+
+/**
+ * Handoff code and QR payload for the customer's view of an order
+ * Orders placed before handoff codes existed have neither
+ */
+export function withHandoffCode<T extends { orderId: string }>(
+  details: T,
+  order: SaleorOrder,
+): T & Pick<OrderDetails, "handoffCode" | "handoffQrPayload"> {
+  const code = order.metadata?.[ORDER_METADATA_KEYS.handoffCode];
+  if (!code) {
+    return details;
+  }
+  return {
+    ...details,
+    handoffCode: code,
+    handoffQrPayload: buildHandoffQrPayload(order.id, code),
+  };
+}
+
+/**
+ * Check a customer's code against the order being handed over
+ * The first match records the handoff; later matches report when it was
+ */
+export async function verifyHandoffCode(
+  orderId: string,
+  code: string,
+  verifiedBy: string,
+  now: Date = new Date(),
+): Promise<HandoffVerification> {
+  const order = await fetchOrderById(orderId);
+  if (!order) {
+    throw notFoundError("Order not found");
+  }
+  if (isAwaitingAbuseReview(order)) {
+    throw badUserInputError("Order is awaiting review", "orderId");
+  }
+  const expected = order.metadata?.[ORDER_METADATA_KEYS.handoffCode];
+  const handedOffAt = order.metadata?.[ORDER_METADATA_KEYS.handedOffAt] || null;
+
+  if (!expected || code !== expected) {
+    logger.warn("handoff_code_mismatch", { orderId, verifiedBy });
+    return { orderId, number: order.number ?? null, valid: false, handedOffAt };
+  }
+  if (handedOffAt) {
+    return { orderId, number: order.number ?? null, valid: true, handedOffAt };
+  }
+
+  const recorded = await updateOrderMetadataWith(orderId, (metadata) =>
+    metadata[ORDER_METADATA_KEYS.handedOffAt]
+      ? null
+      : { [ORDER_METADATA_KEYS.handedOffAt]: now.toISOString() },
+  );
+  if (recorded) {
+    await addOrderNote(orderId, "Handed over to the customer (handoff code checked)");
+    logger.info("order_handed_off", { orderId, verifiedBy });
+  }
+  return {
+    orderId,
+    number: order.number ?? null,
+    valid: true,
+    // Someone else may have recorded the handoff in the meantime
+    handedOffAt:
+      recorded?.[ORDER_METADATA_KEYS.handedOffAt] ??
+      (await fetchOrderById(orderId))?.metadata?.[ORDER_METADATA_KEYS.handedOffAt] ??
+      now.toISOString(),
+  };
+}
@@ -0,0 +1,113 @@
+// Order Issue Tests
+// Tests for orderIssues.ts - reporting issues and closing them
+
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import { clearOrders, createSaleorOrder } from "./saleorOrder";
+import { sendTelegramMessage } from "./notifications";
+import { getOrderIssue, reportOrderIssue, resolveOrderIssue } from "./orderIssues";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+vi.mock("./notifications", async (importOriginal) => ({
+  ...(await importOriginal<typeof import("./notifications")>()),
+  sendTelegramMessage: vi.fn(async () => true),
+}));
+
+async function createOrder(userId: string) {
+  const result = await createSaleorOrder(
+    {
+      restaurantId: "restA",
+      deliveryLocation: { address: "1 Test Street" },
+      items: [{ dishId: "dish1", quantity: 1 }],
+    },
+    userId,
+  );
+  return result.order!;
+}
+
+beforeEach(() => {
+  clearOrders();
+  vi.mocked(sendTelegramMessage).mockClear();
+});
+
+describe("reportOrderIssue", () => {
+  it("should open an issue with its SLA deadline", async () => {
+    const order = await createOrder("user-1");
+    const issue = await reportOrderIssue(
+      { orderId: order.id, category: "LATE", details: " Still waiting " },
+      "user-1",
+    );
+    expect(issue).toMatchObject({
+      status: "OPEN",
+      details: "Still waiting",
+      slaBreached: false,
+    });
+    expect(Date.parse(issue.slaDueAt) - Date.parse(issue.createdAt)).toBe(15 * 60 * 1000);
+  });
+
+  it("should refuse categories that are only prototype keys", async () => {
+    const order = await createOrder("user-1");
+    for (const category of ["constructor", "toString", "__proto__"]) {
+      await expect(
+        reportOrderIssue({ orderId: order.id, category: category as any }, "user-1"),
+      ).rejects.toThrow("Unknown issue category");
+    }
+  });
+
+  it("should only accept reports on the user's own orders", async () => {
+    const order = await createOrder("user-1");
+    await expect(
+      reportOrderIssue({ orderId: order.id, category: "COLD_FOOD" }, "user-2"),
+    ).rejects.toMatchObject({ code: "NOT_FOUND" });
+  });
+});
+
+describe("resolveOrderIssue", () => {
+  it("should leave refunds to staff without claiming one was issued", async () => {
+    const order = await createOrder("user-1");
+    const issue = await reportOrderIssue(
+      { orderId: order.id, category: "MISSING_ITEM" },
+      "user-1",
+    );
+
+    const resolved = await resolveOrderIssue(
+      { issueId: issue.id, resolution: "REFUND", refundAmount: 4.5 },
+      "admin",
+    );
+    expect(resolved).toMatchObject({
+      status: "RESOLVED",
+      resolution: "REFUND",
+      refundAmount: 4.5,
+    });
+    const [, message] = vi.mocked(sendTelegramMessage).mock.calls[0];
+    expect(message).toContain("We'll refund it");
+    expect(message).not.toContain("has been issued");
+
+    await expect(
+      resolveOrderIssue({ issueId: issue.id, resolution: "NO_ACTION" }, "admin"),
+    ).rejects.toThrow("already resolved");
+  });
+
+  it("should refuse resolutions that are only prototype keys", async () => {
+    const order = await createOrder("user-1");
+    const issue = await reportOrderIssue({ orderId: order.id, category: "OTHER" }, "user-1");
+    await expect(
+      resolveOrderIssue({ issueId: issue.id, resolution: "constructor" as any }, "admin"),
+    ).rejects.toThrow("Unknown resolution");
+  });
+
+  it("should keep the issue open when the voucher can't be issued", async () => {
+    const order = await createOrder("user-1");
+    const issue = await reportOrderIssue({ orderId: order.id, category: "QUALITY" }, "user-1");
+
+    // Mock orders have no Saleor to create the voucher in
+    await expect(
+      resolveOrderIssue({ issueId: issue.id, resolution: "VOUCHER" }, "admin"),
+    ).rejects.toMatchObject({ code: "BAD_USER_INPUT" });
+    expect((await getOrderIssue(issue.id))?.status).toBe("OPEN");
+    expect(sendTelegramMessage).not.toHaveBeenCalled();
+  });
+});
@@ -0,0 +1,257 @@
+// Order Issue Reports
+// Structured complaints (missing item, cold food, late, ...) replace
+// free-form support chats for common cases. Reports land in an admin
+// triage queue ordered by SLA deadline and are closed with a canned
+// resolution (refund, voucher, no action); the customer is notified.
+// Reports and resolutions are also noted in the order's Saleor history.
+// Refunds aren't issued from here: a REFUND resolution records the amount
+// and tells the customer one is on its way, and staff refund it by hand.
+
+import {
+  OrderIssue,
+  OrderIssueCategory,
+  OrderIssueStatus,
+  ReportOrderIssueInput,
+  ResolveOrderIssueInput,
+} from "./contracts";
+import { badUserInputError, notFoundError } from "./errors";
+import { logger } from "./logger";
+import { sendTelegramMessage } from "./notifications";
+import { addOrderNote, fetchUserOrder } from "./saleorOrder";
+import { readJSON, writeJSON, readAllJSON } from "./storage";
+import { issueCompensationVoucher } from "./vouchers";
+
+const ISSUE_PREFIX = "issue:";
+const MAX_DETAILS_LENGTH = 2000;
+const MAX_PHOTOS = 5;
+
+/**
+ * Minutes until an issue of each category breaches its SLA
+ */
+export const ISSUE_SLA_MINUTES: Record<OrderIssueCategory, number> = {
+  LATE: 15,
+  MISSING_ITEM: 30,
+  WRONG_ITEM: 30,
+  COLD_FOOD: 60,
+  QUALITY: 120,
+  OTHER: 240,
+};
+
+/**
+ * Customer-facing text for each canned resolution
+ */
+const RESOLUTION_MESSAGES: Record<ResolveOrderIssueInput["resolution"], string> = {
+  REFUND: "We're sorry about your order. We'll refund it and follow up with you.",
+  VOUCHER: "We're sorry about your order. Use this voucher on your next order:",
+  NO_ACTION: "Thanks for letting us know. We've reviewed your report.",
+};
+
+function getKey(issueId: string): string {
+  return `${ISSUE_PREFIX}${issueId}`;
+}
+
+// Input enums are checked against own keys, so "constructor" doesn't pass
+function isOwnKey(record: object, key: string): boolean {
+  return Object.prototype.hasOwnProperty.call(record, key);
+}
+
+function isValidPhotoUrl(url: string): boolean {
+  try {
+    return new URL(url).protocol === "https:";
+  } catch {
+    return false;
+  }
+}
+
+/**
+ * Add computed SLA fields to a stored issue
+ */
+function withSla(issue: OrderIssue, now: Date = new Date()): OrderIssue {
+  return {
+    ...issue,
+    slaBreached:
+      issue.status === "OPEN"
+        ? now.toISOString() > issue.slaDueAt
+        : (issue.resolvedAt || "") > issue.slaDueAt,
+  };
+}
+
+export async function getOrderIssue(issueId: string): Promise<OrderIssue | null> {
+  const issue = await readJSON<OrderIssue>(getKey(issueId));
+  return issue ? withSla(issue) : null;
+}
+
+/**
+ * Report a problem with one of the user's orders
+ */
+export async function reportOrderIssue(
+  input: ReportOrderIssueInput,
+  reporterId: string,
+): Promise<OrderIssue> {
+  if (!isOwnKey(ISSUE_SLA_MINUTES, input.category)) {
+    throw badUserInputError("Unknown issue category", "category");
+  }
+  const details = (input.details || "").trim();
+  if (details.length > MAX_DETAILS_LENGTH) {
+    throw badUserInputError(
+      `Details exceed ${MAX_DETAILS_LENGTH} characters`,
+      "details",
+    );
+  }
+  const photos = input.photos || [];
+  if (photos.length > MAX_PHOTOS) {
+    throw badUserInputError(`At most ${MAX_PHOTOS} photos`, "photos");
+  }
+  if (!photos.every(isValidPhotoUrl)) {
+    throw badUserInputError("Photos must be https URLs", "photos");
+  }
+
+  const order = await fetchUserOrder(input.orderId, reporterId);
+  if (!order) {
+    throw notFoundError("Order not found");
+  }
+
+  const now = new Date();
+  const issue: OrderIssue = {
+    id: crypto.randomUUID(),
+    orderId: order.id,
+    orderNumber: order.number,
+    restaurantId: order.channelId || "",
+    reporterId,
+    category: input.category,
+    details,
+    photos,
+    status: "OPEN",
+    createdAt: now.toISOString(),
+    slaDueAt: new Date(
+      now.getTime() + ISSUE_SLA_MINUTES[input.category] * 60 * 1000,
+    ).toISOString(),
+    slaBreached: false,
+    resolution: null,
+    resolutionNote: null,
+    refundAmount: null,
+    resolvedAt: null,
+    resolvedBy: null,
+    voucherCode: null,
+  };
+
+  await writeJSON(getKey(issue.id), issue);
+  await addOrderNote(
+    order.id,
+    `Customer reported an issue (${issue.category})${details ? `: ${details}` : ""}`,
+  );
+  logger.info("order_issue_reported", {
+    issueId: issue.id,
+    orderId: order.id,
+    category: issue.category,
+  });
+  return issue;
+}
+
+/**
+ * Triage queue: issues ordered by SLA deadline (most urgent first)
+ * Omitting restaurantId lists every restaurant (callers enforce superadmin)
+ */
+export async function listOrderIssues(
+  restaurantId?: string,
+  status?: OrderIssueStatus,
+): Promise<OrderIssue[]> {
+  const now = new Date();
+  const issues = await readAllJSON<OrderIssue>(ISSUE_PREFIX);
+  return issues
+    .filter((i) => !restaurantId || i.restaurantId === restaurantId)
+    .filter((i) => !status || i.status === status)
+    .map((i) => withSla(i, now))
+    .sort((a, b) => a.slaDueAt.localeCompare(b.slaDueAt));
+}
+
+/**
+ * Look up the restaurant an issue belongs to (for permission checks)
+ */
+export async function getOrderIssueRestaurantId(issueId: string): Promise<string> {
+  const issue = issueId ? await getOrderIssue(issueId) : null;
+  if (!issue) {
+    throw notFoundError("Issue not found");
+  }
+  return issue.restaurantId;
+}
+
+/**
+ * Close an issue with a canned resolution and notify the customer
+ */
+export async function resolveOrderIssue(
+  input: ResolveOrderIssueInput,
+  resolverId: string,
+): Promise<OrderIssue> {
+  if (!isOwnKey(RESOLUTION_MESSAGES, input.resolution)) {
+    throw badUserInputError("Unknown resolution", "resolution");
+  }
+  if (
+    input.refundAmount !== undefined &&
+    input.refundAmount !== null &&
+    !(input.refundAmount > 0)
+  ) {
+    throw badUserInputError("Refund amount must be positive", "refundAmount");
+  }
+
+  const issue = await readJSON<OrderIssue>(getKey(input.issueId));
+  if (!issue) {
+    throw notFoundError("Issue not found");
+  }
+  if (issue.status !== "OPEN") {
+    throw badUserInputError("Issue is already resolved", "issueId");
+  }
+
+  // Issue the voucher first so a Saleor failure leaves the issue open
+  if (input.resolution === "VOUCHER") {
+    const voucher = await issueCompensationVoucher(issue.orderId, {
+      value: input.voucherValue,
+      reason: `issue:${issue.category}`,
+      issuedBy: resolverId,
+      notify: false,
+    });
+    issue.voucherCode = voucher.code;
+  }
+
+  issue.status = "RESOLVED";
+  issue.resolution = input.resolution;
+  issue.resolutionNote = input.note?.trim() || null;
+  issue.refundAmount =
+    input.resolution === "REFUND" ? (input.refundAmount ?? null) : null;
+  issue.resolvedAt = new Date().toISOString();
+  issue.resolvedBy = resolverId;
+  await writeJSON(getKey(issue.id), issue);
+  await addOrderNote(
+    issue.orderId,
+    `Issue (${issue.category}) resolved: ${issue.resolution}` +
+      (issue.resolution === "REFUND"
+        ? ` (to be refunded manually${
+            issue.refundAmount !== null ? `: ${issue.refundAmount}` : ""
+          })`
+        : "") +
+      (issue.voucherCode ? ` ${issue.voucherCode}` : "") +
+      (issue.resolutionNote ? ` - ${issue.resolutionNote}` : ""),
+  );
+
+  const label = issue.orderNumber ? `#${issue.orderNumber}` : issue.orderId;
+  const code = issue.voucherCode ? ` ${issue.voucherCode}` : "";
+  const note = issue.resolutionNote ? `\n\n${issue.resolutionNote}` : "";
+  await sendTelegramMessage(
+    issue.reporterId,
+    `Order ${label}: ${RESOLUTION_MESSAGES[input.resolution]}${code}${note}`,
+  );
+
+  logger.info("order_issue_resolved", {
+    issueId: issue.id,
+    resolution: issue.resolution,
+    resolverId,
+  });
+  if (issue.resolution === "REFUND") {
+    logger.warn("order_issue_refund_pending", {
+      issueId: issue.id,
+      orderId: issue.orderId,
+      refundAmount: issue.refundAmount,
+    });
+  }
+  return withSla(issue);
+}
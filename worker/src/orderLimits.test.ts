@@ -0,0 +1,69 @@
+// Order Limit Tests
+// Tests for orderLimits.ts - active orders per user and per address
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { PlaceOrderInput } from "./contracts";
+import { assertOrderLimits, normalizeAddress } from "./orderLimits";
+import { cancelSaleorOrder, clearOrders, createSaleorOrder } from "./saleorOrder";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+const orderInput: PlaceOrderInput = {
+  restaurantId: "restA",
+  deliveryLocation: { address: "1 Test Street", city: "Berlin" },
+  items: [{ dishId: "dish1", quantity: 1 }],
+};
+
+beforeEach(() => {
+  clearOrders();
+});
+
+afterEach(() => {
+  delete (globalThis as any).MAX_ACTIVE_ORDERS_PER_USER;
+  delete (globalThis as any).MAX_ACTIVE_ORDERS_PER_ADDRESS;
+});
+
+describe("normalizeAddress", () => {
+  it("should ignore case, spacing and punctuation", () => {
+    expect(normalizeAddress({ address: "1, Test  Street", city: "BERLIN" })).toBe(
+      normalizeAddress({ address: "1 test street.", city: "Berlin" }),
+    );
+    expect(normalizeAddress(undefined)).toBe("");
+  });
+});
+
+describe("assertOrderLimits", () => {
+  it("should allow any number of orders when unset", async () => {
+    await createSaleorOrder(orderInput, "user-1");
+    await createSaleorOrder(orderInput, "user-1");
+    await expect(assertOrderLimits("user-1", orderInput.deliveryLocation)).resolves.toBeUndefined();
+  });
+
+  it("should refuse a user at the limit until an order finishes", async () => {
+    (globalThis as any).MAX_ACTIVE_ORDERS_PER_USER = "1";
+    const placed = await createSaleorOrder(orderInput, "user-1");
+    await expect(assertOrderLimits("user-1", undefined)).rejects.toMatchObject({
+      code: "TOO_MANY_ACTIVE_ORDERS",
+    });
+    await expect(assertOrderLimits("user-2", undefined)).resolves.toBeUndefined();
+
+    await cancelSaleorOrder(placed.order!.id);
+    await expect(assertOrderLimits("user-1", undefined)).resolves.toBeUndefined();
+  });
+
+  it("should refuse an address at the limit across users", async () => {
+    (globalThis as any).MAX_ACTIVE_ORDERS_PER_ADDRESS = "2";
+    await createSaleorOrder(orderInput, "user-1");
+    await createSaleorOrder(orderInput, "user-2");
+    await expect(
+      assertOrderLimits("user-3", { address: "1 test street", city: "berlin" }),
+    ).rejects.toMatchObject({ code: "TOO_MANY_ACTIVE_ORDERS", field: "deliveryLocation" });
+    await expect(
+      assertOrderLimits("user-3", { address: "2 Test Street", city: "Berlin" }),
+    ).resolves.toBeUndefined();
+    // Pickup orders have no address to count
+    await expect(assertOrderLimits("user-3", undefined)).resolves.toBeUndefined();
+  });
+});
@@ -0,0 +1,89 @@
+// Active Order Limits
+// Caps how many orders a Telegram user can have in progress at once
+// (MAX_ACTIVE_ORDERS_PER_USER) and how many can be on their way to one
+// delivery address (MAX_ACTIVE_ORDERS_PER_ADDRESS), so prank orders and
+// double submissions that get past idempotency keys are turned away before
+// anything is created. In progress means not yet in a terminal status;
+// 0 turns a limit off. Addresses match ignoring case, spacing and
+// punctuation, across restaurants, over the last day of orders.
+
+import { DeliveryLocation } from "./contracts";
+import { getNumberVar } from "./config";
+import { tooManyActiveOrdersError } from "./errors";
+import { logger } from "./logger";
+import { isTerminalOrderStatus } from "./orderStatus";
+import { SaleorOrder, fetchOrders, fetchUserOrders, getNormalizedStatus } from "./saleorOrder";
+
+// Orders older than this no longer count towards the address limit
+const ADDRESS_WINDOW_HOURS = 24;
+
+function getLimit(name: string): number {
+  const limit = getNumberVar(name, 0);
+  return Number.isInteger(limit) && limit > 0 ? limit : 0;
+}
+
+/**
+ * Comparable form of a delivery address ("" without one)
+ */
+export function normalizeAddress(
+  location: Pick<DeliveryLocation, "address" | "city" | "country"> | undefined,
+): string {
+  return [location?.address, location?.city, location?.country]
+    .filter(Boolean)
+    .join(" ")
+    .toLowerCase()
+    .replace(/[^\p{L}\p{N}]+/gu, " ")
+    .trim();
+}
+
+function isActive(order: SaleorOrder): boolean {
+  return !isTerminalOrderStatus(getNormalizedStatus(order));
+}
+
+/**
+ * Refuse an order when the user or the delivery address is at its limit
+ * Pickup orders pass no delivery location and skip the address limit
+ */
+export async function assertOrderLimits(
+  userId: string,
+  deliveryLocation: DeliveryLocation | undefined,
+  now: Date = new Date(),
+): Promise<void> {
+  const userLimit = getLimit("MAX_ACTIVE_ORDERS_PER_USER");
+  if (userLimit > 0) {
+    const active = (await fetchUserOrders(userId)).filter(isActive).length;
+    if (active >= userLimit) {
+      logger.warn("order_limit_reached", { userId, scope: "user", active, limit: userLimit });
+      throw tooManyActiveOrdersError(
+        userLimit === 1
+          ? "You already have an order in progress. Please wait until it is completed."
+          : `You already have ${active} orders in progress. Please wait until one is completed.`,
+      );
+    }
+  }
+
+  const addressLimit = getLimit("MAX_ACTIVE_ORDERS_PER_ADDRESS");
+  const address = normalizeAddress(deliveryLocation);
+  if (addressLimit > 0 && address) {
+    const since = new Date(now.getTime() - ADDRESS_WINDOW_HOURS * 60 * 60 * 1000);
+    const orders = await fetchOrders({ createdFrom: since.toISOString().slice(0, 10) });
+    const active = orders.filter(
+      (order) =>
+        new Date(order.createdAt).getTime() >= since.getTime() &&
+        isActive(order) &&
+        normalizeAddress(order.deliveryAddress) === address,
+    ).length;
+    if (active >= addressLimit) {
+      logger.warn("order_limit_reached", {
+        userId,
+        scope: "address",
+        active,
+        limit: addressLimit,
+      });
+      throw tooManyActiveOrdersError(
+        "This address already has orders on the way. Please wait until they are delivered.",
+        "deliveryLocation",
+      );
+    }
+  }
+}
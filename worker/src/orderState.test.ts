@@ -0,0 +1,166 @@
+// Order State Tests
+// Tests for orderState.ts - payment deadlines and expiration of unpaid orders
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import {
+  addOrderNote,
+  clearOrders,
+  createSaleorOrder,
+  getOrder,
+  updateOrderMetadataWith,
+  SaleorOrder,
+} from "./saleorOrder";
+import {
+  startPaymentDeadline,
+  markOrderPaid,
+  expireUnpaidOrders,
+} from "./orderState";
+import { reserveSlot, countSlotReservations } from "./slots";
+import { PlaceOrderInput } from "./contracts";
+import { SaleorFetch, initializeSaleorClient } from "./saleorClient";
+import { runWithSaleorTarget } from "./saleorTargets";
+import { readJSON } from "./storage";
+
+// Mock the logger to avoid console output during tests
+vi.mock("./logger", () => ({
+  logger: {
+    info: vi.fn(),
+    error: vi.fn(),
+    warn: vi.fn(),
+    debug: vi.fn(),
+  },
+}));
+
+const orderInput: PlaceOrderInput = {
+  restaurantId: "restA",
+  deliveryLocation: { address: "1 Test Street" },
+  items: [{ dishId: "dish1", quantity: 1 }],
+};
+
+async function createPendingOrder(userId: string, slotStart?: string) {
+  const result = await createSaleorOrder(orderInput, userId);
+  const order = result.order!;
+  await startPaymentDeadline(order, userId, "restA", slotStart);
+  return order;
+}
+
+describe("payment deadlines", () => {
+  beforeEach(() => {
+    clearOrders();
+  });
+
+  it("should mark new orders as PENDING_PAYMENT", async () => {
+    const order = await createPendingOrder("user-1");
+    expect(getOrder(order.id)?.metadata?.["tma.state"]).toBe("PENDING_PAYMENT");
+    expect(order.metadata?.["tma.paymentDeadline"]).toBeDefined();
+  });
+
+  it("should leave orders alone before the deadline", async () => {
+    const order = await createPendingOrder("user-2");
+    expect(await expireUnpaidOrders(new Date())).toBe(0);
+    expect(getOrder(order.id)?.status).toBe("CREATED");
+    await markOrderPaid(order.id);
+  });
+
+  it("should cancel unpaid orders and release their slot", async () => {
+    const scheduledFor = new Date(Date.now() + 2 * 60 * 60 * 1000).toISOString();
+    const result = await createSaleorOrder(orderInput, "user-3");
+    const order = result.order!;
+    const slotStart = await reserveSlot("restA", scheduledFor, order.id);
+    await startPaymentDeadline(order, "user-3", "restA", slotStart);
+    expect(await countSlotReservations("restA", slotStart)).toBe(1);
+
+    const later = new Date(Date.now() + 60 * 60 * 1000);
+    expect(await expireUnpaidOrders(later)).toBe(1);
+
+    expect(getOrder(order.id)?.status).toBe("CANCELLED");
+    expect(getOrder(order.id)?.metadata?.["tma.state"]).toBe("EXPIRED");
+    expect(await countSlotReservations("restA", slotStart)).toBe(0);
+  });
+
+  it("should not expire paid orders", async () => {
+    const order = await createPendingOrder("user-4");
+    expect(await markOrderPaid(order.id)).toBe(true);
+
+    const later = new Date(Date.now() + 60 * 60 * 1000);
+    expect(await expireUnpaidOrders(later)).toBe(0);
+    expect(getOrder(order.id)?.metadata?.["tma.state"]).toBe("PAID");
+  });
+});
+
+describe("payment deadlines on the secondary Saleor", () => {
+  afterEach(() => {
+    initializeSaleorClient({});
+    delete (globalThis as any).SALEOR_SECONDARY_API_URL;
+    delete (globalThis as any).SALEOR_SECONDARY_TOKEN;
+    delete (globalThis as any).SALEOR_TRANSPORT;
+  });
+
+  it("should expire orders on the Saleor they were placed on", async () => {
+    const send = vi.fn<SaleorFetch>(async () =>
+      Response.json({ data: { updateMetadata: { errors: [] } } }),
+    );
+    (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+    initializeSaleorClient({ SALEOR_API_URL: "https://saleor.test/graphql/", SALEOR_TOKEN: "t" });
+    (globalThis as any).SALEOR_SECONDARY_API_URL = "https://sandbox.example.com/graphql/";
+    (globalThis as any).SALEOR_SECONDARY_TOKEN = "sandbox-token";
+
+    const order = { id: "primary-order", number: 1 } as SaleorOrder;
+    await startPaymentDeadline(order, "user-5", "restA");
+    await runWithSaleorTarget("secondary", () =>
+      startPaymentDeadline({ ...order, id: "sandbox-order" }, "user-6", "restA"),
+    );
+    expect(await readJSON("payment-deadline:sandbox-order")).toMatchObject({
+      saleorTarget: "secondary",
+    });
+
+    send.mockClear();
+    await expireUnpaidOrders(new Date(Date.now() + 60 * 60 * 1000));
+
+    const urlsByOrder = new Map<string, Set<string>>();
+    for (const [url, init] of send.mock.calls) {
+      const id = JSON.parse(String(init?.body)).variables?.id;
+      urlsByOrder.set(id, new Set([...(urlsByOrder.get(id) || []), String(url)]));
+    }
+    expect([...urlsByOrder.get("primary-order")!]).toEqual(["https://saleor.test/graphql/"]);
+    expect([...urlsByOrder.get("sandbox-order")!]).toEqual([
+      "https://sandbox.example.com/graphql/",
+    ]);
+  });
+});
+
+describe("conditional order metadata updates", () => {
+  beforeEach(() => {
+    clearOrders();
+  });
+
+  it("should write the keys returned for the current metadata", async () => {
+    const order = await createPendingOrder("user-5");
+    const written = await updateOrderMetadataWith(order.id, (metadata) =>
+      metadata["tma.state"] === "PENDING_PAYMENT" ? { "tma.state": "EXPIRED" } : null,
+    );
+    expect(written).toEqual({ "tma.state": "EXPIRED" });
+    expect(getOrder(order.id)?.metadata?.["tma.state"]).toBe("EXPIRED");
+  });
+
+  it("should leave the order alone when the update declines", async () => {
+    const order = await createPendingOrder("user-6");
+    await markOrderPaid(order.id);
+    const written = await updateOrderMetadataWith(order.id, (metadata) =>
+      metadata["tma.state"] === "PENDING_PAYMENT" ? { "tma.state": "EXPIRED" } : null,
+    );
+    expect(written).toBeNull();
+    expect(getOrder(order.id)?.metadata?.["tma.state"]).toBe("PAID");
+  });
+
+  it("should return null for unknown orders", async () => {
+    expect(await updateOrderMetadataWith("missing", () => ({ "tma.state": "EXPIRED" }))).toBeNull();
+    expect(await addOrderNote("missing", "Note")).toBe(false);
+  });
+
+  it("should add notes to existing orders", async () => {
+    const order = await createPendingOrder("user-7");
+    expect(await addOrderNote(order.id, "Called the customer")).toBe(true);
+    expect(await addOrderNote(order.id, "   ")).toBe(false);
+  });
+});
@@ -0,0 +1,210 @@
+// Order App State & Payment Deadlines
+// For pay-before-completion flows orders start in PENDING_PAYMENT with a
+// deadline; the scheduled job cancels orders still unpaid after it,
+// releases their slot reservation and notifies the user.
+// State lives in order metadata (tma.state, tma.paymentDeadline); a KV
+// index of pending orders lets the job avoid scanning all orders. A payment
+// held until the restaurant accepts (AUTHORIZED) stops the deadline too.
+// Expiry claims tma.state with a conditional update, so a payment that
+// lands while the job runs wins over the cancellation. Records keep the
+// Saleor target the order was placed on, and the job expires it there.
+
+import { buildCancellationMetadata } from "./cancellationReasons";
+import { getBooleanVar, getNumberVar } from "./config";
+import { logger } from "./logger";
+import { restoreRedeemedPoints } from "./loyalty";
+import { readJSON, writeJSON, deleteKey, readAllJSON } from "./storage";
+import {
+  SaleorOrder,
+  ORDER_METADATA_KEYS,
+  addOrderNote,
+  updateOrderMetadata,
+  updateOrderMetadataWith,
+  cancelSaleorOrder,
+} from "./saleorOrder";
+import { SaleorTarget, getSaleorTarget, runWithSaleorTarget } from "./saleorTargets";
+import { releaseSlot } from "./slots";
+import { notifyOrderExpired } from "./notifications";
+
+/**
+ * Worker-side order state, tracked alongside Saleor's order status
+ */
+export type OrderAppState = "PENDING_PAYMENT" | "AUTHORIZED" | "PAID" | "EXPIRED";
+
+/**
+ * Pending payment index entry
+ */
+export interface PaymentDeadlineRecord {
+  orderId: string;
+  orderNumber?: number;
+  userId: string;
+  restaurantId: string;
+  deadline: string; // ISO timestamp
+  slotStart?: string; // reserved slot for scheduled orders
+  saleorTarget?: SaleorTarget; // unset on records from before targets
+}
+
+const PENDING_PREFIX = "payment-deadline:";
+
+function getKey(orderId: string): string {
+  return `${PENDING_PREFIX}${orderId}`;
+}
+
+/**
+ * Whether orders must be paid before completion (REQUIRE_PAYMENT_BEFORE_COMPLETION)
+ */
+export function isPaymentRequired(): boolean {
+  return getBooleanVar("REQUIRE_PAYMENT_BEFORE_COMPLETION");
+}
+
+/**
+ * Minutes an order may stay unpaid (PAYMENT_DEADLINE_MINUTES, default 15)
+ */
+export function getPaymentDeadlineMinutes(): number {
+  const minutes = getNumberVar("PAYMENT_DEADLINE_MINUTES", 15);
+  return minutes > 0 ? minutes : 15;
+}
+
+/**
+ * Put a new order into PENDING_PAYMENT and register its deadline
+ */
+export async function startPaymentDeadline(
+  order: SaleorOrder,
+  userId: string,
+  restaurantId: string,
+  slotStart?: string,
+): Promise<PaymentDeadlineRecord> {
+  const deadline = new Date(
+    Date.now() + getPaymentDeadlineMinutes() * 60 * 1000,
+  ).toISOString();
+
+  const record: PaymentDeadlineRecord = {
+    orderId: order.id,
+    orderNumber: order.number,
+    userId,
+    restaurantId,
+    deadline,
+    slotStart,
+    saleorTarget: getSaleorTarget(),
+  };
+  await writeJSON(getKey(order.id), record);
+
+  const metadata = {
+    [ORDER_METADATA_KEYS.state]: "PENDING_PAYMENT",
+    [ORDER_METADATA_KEYS.paymentDeadline]: deadline,
+  };
+  await updateOrderMetadata(order.id, metadata);
+  order.metadata = { ...(order.metadata || {}), ...metadata };
+
+  logger.info("payment_deadline_started", { orderId: order.id, deadline });
+  return record;
+}
+
+/**
+ * Mark an order as paid and stop its deadline if one is running
+ * Returns false when the order had no pending deadline
+ */
+export async function markOrderPaid(orderId: string): Promise<boolean> {
+  const record = await readJSON<PaymentDeadlineRecord>(getKey(orderId));
+  if (record) {
+    await deleteKey(getKey(orderId));
+  }
+  await updateOrderMetadata(orderId, { [ORDER_METADATA_KEYS.state]: "PAID" });
+  logger.info("order_paid", { orderId });
+  return record !== null;
+}
+
+/**
+ * Mark an order's payment as held (authorized, not yet captured) and stop
+ * its deadline; the restaurant's accept or reject settles it
+ */
+export async function markOrderAuthorized(orderId: string): Promise<void> {
+  await deleteKey(getKey(orderId));
+  await updateOrderMetadata(orderId, { [ORDER_METADATA_KEYS.state]: "AUTHORIZED" });
+  logger.info("order_payment_authorized", { orderId });
+}
+
+/**
+ * Drop an order's pending deadline (e.g. the restaurant rejected it)
+ * Returns the removed record, or null when none was running
+ */
+export async function clearPaymentDeadline(
+  orderId: string,
+): Promise<PaymentDeadlineRecord | null> {
+  const record = await readJSON<PaymentDeadlineRecord>(getKey(orderId));
+  if (record) {
+    await deleteKey(getKey(orderId));
+  }
+  return record;
+}
+
+/**
+ * Expire one overdue order
+ * Returns false when it was paid meanwhile or must be retried
+ */
+async function expireOrder(record: PaymentDeadlineRecord): Promise<boolean> {
+  // EXPIRED is claimable again when an earlier run couldn't cancel
+  let claimed: Record<string, string> | null;
+  try {
+    claimed = await updateOrderMetadataWith(record.orderId, (metadata) => {
+      const state = metadata[ORDER_METADATA_KEYS.state];
+      return state === "PENDING_PAYMENT" || state === "EXPIRED"
+        ? {
+            [ORDER_METADATA_KEYS.state]: "EXPIRED",
+            ...buildCancellationMetadata("PAYMENT_TIMEOUT"),
+          }
+        : null;
+    });
+  } catch {
+    // Leave the record in place so the next run retries
+    logger.warn("payment_deadline_claim_failed", { orderId: record.orderId });
+    return false;
+  }
+  if (!claimed) {
+    // Paid or held meanwhile (or gone): nothing to expire
+    await deleteKey(getKey(record.orderId));
+    return false;
+  }
+
+  const cancelled = await cancelSaleorOrder(record.orderId);
+  if (!cancelled) {
+    // Leave the record in place so the next run retries
+    logger.warn("payment_deadline_cancel_failed", { orderId: record.orderId });
+    return false;
+  }
+
+  await deleteKey(getKey(record.orderId));
+  await addOrderNote(record.orderId, "Cancelled: not paid before the payment deadline");
+  await restoreRedeemedPoints(record.orderId);
+  if (record.slotStart) {
+    await releaseSlot(record.restaurantId, record.slotStart, record.orderId);
+  }
+  await notifyOrderExpired(record.userId, record.orderId, record.orderNumber);
+
+  logger.info("order_payment_expired", {
+    orderId: record.orderId,
+    deadline: record.deadline,
+  });
+  return true;
+}
+
+/**
+ * Cancel orders whose payment deadline has passed
+ * Returns the number of orders expired
+ */
+export async function expireUnpaidOrders(now: Date = new Date()): Promise<number> {
+  const pending = await readAllJSON<PaymentDeadlineRecord>(PENDING_PREFIX);
+  let expired = 0;
+
+  for (const record of pending) {
+    if (new Date(record.deadline).getTime() > now.getTime()) {
+      continue;
+    }
+    const target = record.saleorTarget ?? "primary";
+    if (await runWithSaleorTarget(target, () => expireOrder(record))) {
+      expired++;
+    }
+  }
+
+  return expired;
+}
@@ -0,0 +1,30 @@
+// Order Status Tests
+// Tests for orderStatus.ts - normalized status mapping
+
+import { describe, it, expect } from "vitest";
+import { normalizeOrderStatus, isTerminalOrderStatus } from "./orderStatus";
+
+describe("normalizeOrderStatus", () => {
+  it("should map Saleor and legacy statuses", () => {
+    expect(normalizeOrderStatus("UNCONFIRMED")).toBe("PLACED");
+    expect(normalizeOrderStatus("CREATED")).toBe("PLACED");
+    expect(normalizeOrderStatus("UNFULFILLED")).toBe("PREPARING");
+    expect(normalizeOrderStatus("PARTIALLY_FULFILLED")).toBe("OUT_FOR_DELIVERY");
+    expect(normalizeOrderStatus("FULFILLED")).toBe("COMPLETED");
+    expect(normalizeOrderStatus("CANCELED")).toBe("CANCELLED");
+  });
+
+  it("should apply the app state", () => {
+    expect(normalizeOrderStatus("UNCONFIRMED", "PENDING_PAYMENT")).toBe(
+      "PENDING_PAYMENT",
+    );
+    expect(normalizeOrderStatus("CANCELED", "EXPIRED")).toBe("EXPIRED");
+    expect(normalizeOrderStatus("UNFULFILLED", "PAID")).toBe("PREPARING");
+  });
+
+  it("should flag terminal statuses", () => {
+    expect(isTerminalOrderStatus("COMPLETED")).toBe(true);
+    expect(isTerminalOrderStatus("EXPIRED")).toBe(true);
+    expect(isTerminalOrderStatus("PREPARING")).toBe(false);
+  });
+});
@@ -0,0 +1,61 @@
+// Normalized Order Status
+// Collapses Saleor order statuses, legacy mock statuses and the worker's
+// app state (tma.state) into one customer-facing status.
+
+export type NormalizedOrderStatus =
+  | "PENDING_PAYMENT"
+  | "PLACED"
+  | "PREPARING"
+  | "OUT_FOR_DELIVERY"
+  | "COMPLETED"
+  | "CANCELLED"
+  | "EXPIRED";
+
+const STATUS_MAP: Record<string, NormalizedOrderStatus> = {
+  DRAFT: "PLACED",
+  UNCONFIRMED: "PLACED",
+  CREATED: "PLACED",
+  CONFIRMED: "PREPARING",
+  UNFULFILLED: "PREPARING",
+  PROCESSING: "PREPARING",
+  PARTIALLY_FULFILLED: "OUT_FOR_DELIVERY",
+  SHIPPED: "OUT_FOR_DELIVERY",
+  FULFILLED: "COMPLETED",
+  DELIVERED: "COMPLETED",
+  CANCELED: "CANCELLED",
+  CANCELLED: "CANCELLED",
+};
+
+/**
+ * Statuses after which an order no longer changes
+ */
+export const TERMINAL_ORDER_STATUSES: NormalizedOrderStatus[] = [
+  "COMPLETED",
+  "CANCELLED",
+  "EXPIRED",
+];
+
+/**
+ * Map a Saleor status plus worker app state to a normalized status
+ */
+export function normalizeOrderStatus(
+  status: string,
+  appState?: string,
+): NormalizedOrderStatus {
+  const normalized = STATUS_MAP[status] || "PLACED";
+  // Saleor's own terminal statuses win over the app state
+  if (normalized === "CANCELLED" || normalized === "COMPLETED") {
+    return appState === "EXPIRED" ? "EXPIRED" : normalized;
+  }
+  if (appState === "EXPIRED") {
+    return "EXPIRED";
+  }
+  if (appState === "PENDING_PAYMENT") {
+    return "PENDING_PAYMENT";
+  }
+  return normalized;
+}
+
+export function isTerminalOrderStatus(status: NormalizedOrderStatus): boolean {
+  return TERMINAL_ORDER_STATUSES.includes(status);
+}
@@ -0,0 +1,74 @@
+// Order Totals Tests
+// Tests for orderTotals.ts - service fee config and totals breakdown
+
+import { describe, it, expect, afterEach } from "vitest";
+import { getOrderTotals, getServiceFee } from "./orderTotals";
+import { SaleorOrder } from "./saleorOrder";
+
+afterEach(() => {
+  delete (globalThis as any).SERVICE_FEE_VARIANT_ID;
+  delete (globalThis as any).TIP_VARIANT_ID;
+});
+
+function order(overrides: Partial<SaleorOrder>): SaleorOrder {
+  return {
+    id: "order-1",
+    status: "CREATED",
+    total: { gross: { amount: 0, currency: "USD" } },
+    deliveryAddress: { address: "1 Main St" },
+    lines: [],
+    createdAt: "2026-01-01T00:00:00Z",
+    ...overrides,
+  };
+}
+
+describe("getServiceFee", () => {
+  it("should only charge a fee when the variant is configured", () => {
+    expect(getServiceFee({ tma_service_fee: "1.5" }, "USD")).toBe(0);
+    (globalThis as any).SERVICE_FEE_VARIANT_ID = "fee-variant";
+    expect(getServiceFee({ tma_service_fee: "1.499" }, "USD")).toBe(1.5);
+    expect(getServiceFee({}, "USD")).toBe(0);
+  });
+});
+
+describe("getOrderTotals", () => {
+  it("should split fee and tip lines from dishes", () => {
+    (globalThis as any).SERVICE_FEE_VARIANT_ID = "fee-variant";
+    (globalThis as any).TIP_VARIANT_ID = "tip-variant";
+    const totals = getOrderTotals(
+      order({
+        total: { gross: { amount: 27.5, currency: "USD" } },
+        totalTax: 2.5,
+        undiscountedTotal: 30.5,
+        undiscountedShippingPrice: 4,
+        lines: [
+          { variantId: "dish-1", quantity: 2, productName: "Pizza", unitPrice: 9, undiscountedUnitPrice: 10 },
+          { variantId: "fee-variant", quantity: 1, productName: "Service fee", unitPrice: 1.5 },
+          { variantId: "tip-variant", quantity: 1, productName: "Tip", unitPrice: 5 },
+        ],
+      }),
+    );
+    expect(totals).toEqual({
+      currency: "USD",
+      subtotal: 20,
+      deliveryFee: 4,
+      serviceFee: 1.5,
+      tip: 5,
+      tax: 2.5,
+      discount: 3,
+      grandTotal: 27.5,
+    });
+  });
+
+  it("should derive the discount when Saleor pricing is missing", () => {
+    const totals = getOrderTotals(
+      order({
+        total: { gross: { amount: 18, currency: "USD" } },
+        lines: [{ variantId: "dish-1", quantity: 2, productName: "Pizza", unitPrice: 10 }],
+      }),
+    );
+    expect(totals.subtotal).toBe(20);
+    expect(totals.discount).toBe(2);
+    expect(totals.tax).toBe(0);
+  });
+});
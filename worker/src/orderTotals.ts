@@ -0,0 +1,94 @@
+// Order Totals Breakdown
+// Splits a Saleor order's gross total into subtotal, delivery fee, service
+// fee, tip, tax and discount for placeOrder and order details. Tip and
+// service fee are order lines (TIP_VARIANT_ID / SERVICE_FEE_VARIANT_ID with
+// an overridden price); the service fee amount comes from the restaurant's
+// tma_service_fee channel metadata. Amounts are gross, so tax is included
+// and subtotal + fees + tip - discount = grandTotal.
+
+import { OrderTotals } from "./contracts";
+import { getVar } from "./config";
+import { parseNumberValue } from "./metadata";
+import { multiplyMoney, roundMoney, subtractMoney, sumMoney } from "./money";
+import { SaleorOrder } from "./saleorOrder";
+
+// Channel metadata key for a fixed per-order service fee
+export const SERVICE_FEE_METADATA_KEY = "tma_service_fee";
+
+/**
+ * Service fee a restaurant charges per order, 0 when not configured
+ * The fee is only charged when SERVICE_FEE_VARIANT_ID is set
+ */
+export function getServiceFee(
+  metadata: Record<string, string> | undefined,
+  currency: string,
+): number {
+  if (!getVar("SERVICE_FEE_VARIANT_ID")) {
+    return 0;
+  }
+  const fee = parseNumberValue(metadata?.[SERVICE_FEE_METADATA_KEY]);
+  return fee && fee > 0 ? roundMoney(fee, currency) : 0;
+}
+
+/**
+ * Saleor order/checkout line carrying the service fee, or null when none
+ */
+export function buildServiceFeeLine(
+  serviceFee: number | undefined,
+): { variantId: string; quantity: number; price: number } | null {
+  const variantId = getVar("SERVICE_FEE_VARIANT_ID");
+  if (!variantId || !serviceFee || serviceFee <= 0) {
+    return null;
+  }
+  return { variantId, quantity: 1, price: serviceFee };
+}
+
+/**
+ * Totals breakdown of an order
+ */
+export function getOrderTotals(order: SaleorOrder): OrderTotals {
+  const currency = order.total.gross.currency;
+  const tipVariantId = getVar("TIP_VARIANT_ID");
+  const serviceFeeVariantId = getVar("SERVICE_FEE_VARIANT_ID");
+
+  const dishes: number[] = [];
+  const serviceFees: number[] = [];
+  const tips: number[] = [];
+  for (const line of order.lines) {
+    const amount = multiplyMoney(
+      line.undiscountedUnitPrice ?? line.unitPrice ?? 0,
+      line.quantity,
+      currency,
+    );
+    if (serviceFeeVariantId && line.variantId === serviceFeeVariantId) {
+      serviceFees.push(amount);
+    } else if (tipVariantId && line.variantId === tipVariantId) {
+      tips.push(amount);
+    } else {
+      dishes.push(amount);
+    }
+  }
+
+  const subtotal = sumMoney(dishes, currency);
+  const serviceFee = sumMoney(serviceFees, currency);
+  const tip = sumMoney(tips, currency);
+  const deliveryFee = roundMoney(order.undiscountedShippingPrice ?? 0, currency);
+  const grandTotal = order.total.gross.amount;
+
+  // Saleor reports the undiscounted total; without it (mock orders) the
+  // discount is whatever the lines and fees don't account for
+  const undiscountedTotal =
+    order.undiscountedTotal ??
+    sumMoney([subtotal, serviceFee, tip, deliveryFee], currency);
+
+  return {
+    currency,
+    subtotal,
+    deliveryFee,
+    serviceFee,
+    tip,
+    tax: roundMoney(order.totalTax ?? 0, currency),
+    discount: Math.max(0, subtractMoney(undiscountedTotal, grandTotal, currency)),
+    grandTotal,
+  };
+}
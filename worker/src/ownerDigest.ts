@@ -0,0 +1,131 @@
+// Daily Owner Digest
+// Once a day, after DAILY_DIGEST_HOUR in the restaurant's timezone
+// (tma_timezone, default UTC), each restaurant's channel admin gets a
+// Telegram summary of the previous local day from restaurantStats.ts.
+// Runs from the cron job list; a KV marker per restaurant and date makes
+// later ticks skip restaurants already sent. Owners can opt out
+// (setDailyDigestEnabled).
+
+import { formatMoney } from "./currencyFormat";
+import { getChannelAdmin } from "./channelAdmin";
+import { getNumberVar } from "./config";
+import { Channel } from "./contracts";
+import { logger } from "./logger";
+import { sendTelegramMessage } from "./notifications";
+import {
+  TIMEZONE_METADATA_KEY,
+  getLocalTime,
+  isValidTimezone,
+  shiftDateKey,
+} from "./openingHours";
+import { buildDailyStats, DailyStats } from "./restaurantStats";
+import { fetchChannels } from "./saleorService";
+import { deleteKey, readJSON, writeJSON } from "./storage";
+
+const OPT_OUT_PREFIX = "digest-optout:";
+const SENT_PREFIX = "digest-sent:";
+const SENT_TTL_SECONDS = 3 * 24 * 60 * 60;
+
+/**
+ * Local hour (0-23) after which the digest is sent (DAILY_DIGEST_HOUR, default 9)
+ */
+export function getDigestHour(): number {
+  const hour = getNumberVar("DAILY_DIGEST_HOUR", 9);
+  return Number.isInteger(hour) && hour >= 0 && hour <= 23 ? hour : 9;
+}
+
+export async function isDigestEnabled(userId: string): Promise<boolean> {
+  return (await readJSON<{ optedOutAt: string }>(`${OPT_OUT_PREFIX}${userId}`)) === null;
+}
+
+/**
+ * Opt an owner in or out of the daily digest
+ */
+export async function setDigestEnabled(userId: string, enabled: boolean): Promise<boolean> {
+  const key = `${OPT_OUT_PREFIX}${userId}`;
+  if (enabled) {
+    await deleteKey(key);
+  } else {
+    await writeJSON(key, { optedOutAt: new Date().toISOString() });
+  }
+  logger.info("daily_digest_preference", { userId, enabled });
+  return enabled;
+}
+
+/**
+ * Digest message for one restaurant's day
+ */
+export function renderDailyDigest(restaurantName: string, stats: DailyStats): string {
+  const lines = [`${restaurantName} — daily summary for ${stats.date}`, ""];
+  if (stats.orderCount === 0) {
+    lines.push("No orders.");
+    return lines.join("\n");
+  }
+  lines.push(`Orders: ${stats.orderCount}`);
+  lines.push(`Revenue: ${formatMoney(stats.revenue, stats.currency)}`);
+  if (stats.cancelledCount > 0) {
+    lines.push(`Cancelled: ${stats.cancelledCount}`);
+  }
+  if (stats.topDishes.length > 0) {
+    lines.push("", "Top dishes:");
+    stats.topDishes.forEach((dish, i) => {
+      lines.push(`${i + 1}. ${dish.name} × ${dish.quantity}`);
+    });
+  }
+  return lines.join("\n");
+}
+
+function getChannelTimezone(channel: Channel): string {
+  const timezone = channel.metadata?.[TIMEZONE_METADATA_KEY];
+  return timezone && isValidTimezone(timezone) ? timezone : "UTC";
+}
+
+/**
+ * Send digests that are due; returns the number sent
+ */
+export async function sendDailyDigests(now: Date = new Date()): Promise<number> {
+  const digestMinutes = getDigestHour() * 60;
+  let sent = 0;
+
+  for (const channel of await fetchChannels()) {
+    if (!channel.isActive) {
+      continue;
+    }
+    const local = getLocalTime(now, getChannelTimezone(channel));
+    if (local.minutes < digestMinutes) {
+      continue;
+    }
+    const date = shiftDateKey(local.dateKey, -1);
+    const sentKey = `${SENT_PREFIX}${channel.id}:${date}`;
+    if (await readJSON(sentKey)) {
+      continue;
+    }
+
+    const admin = await getChannelAdmin(channel.id);
+    if (!admin || !(await isDigestEnabled(admin.telegramUserId))) {
+      continue;
+    }
+
+    const stats = await buildDailyStats(
+      channel.id,
+      date,
+      getChannelTimezone(channel),
+      channel.currencyCode,
+    );
+    const delivered = await sendTelegramMessage(
+      admin.telegramUserId,
+      renderDailyDigest(channel.name, stats),
+    );
+    // Marked either way: a blocked bot shouldn't be retried every tick
+    await writeJSON(sentKey, { sentAt: now.toISOString(), delivered }, {
+      expirationTtl: SENT_TTL_SECONDS,
+    });
+    if (delivered) {
+      sent++;
+    } else {
+      logger.warn("daily_digest_not_delivered", { restaurantId: channel.id, date });
+    }
+  }
+
+  return sent;
+}
@@ -0,0 +1,60 @@
+// Pagination Tests
+// Tests for pagination.ts - offset cursors and page sizes
+
+import { describe, it, expect } from "vitest";
+import { decodeOffsetCursor, getPageSize, mapConnection, paginateList } from "./pagination";
+import { fetchDishesPage } from "./saleorService";
+
+describe("paginateList", () => {
+  const items = ["a", "b", "c", "d", "e"];
+
+  it("should walk a list page by page", () => {
+    const first = paginateList(items, 2, null);
+    expect(first.edges.map((e) => e.node)).toEqual(["a", "b"]);
+    expect(first.pageInfo.hasNextPage).toBe(true);
+
+    const second = paginateList(items, 2, first.pageInfo.endCursor);
+    expect(second.edges.map((e) => e.node)).toEqual(["c", "d"]);
+
+    const last = paginateList(items, 2, second.pageInfo.endCursor);
+    expect(last.edges.map((e) => e.node)).toEqual(["e"]);
+    expect(last.pageInfo.hasNextPage).toBe(false);
+  });
+
+  it("should return an empty page past the end", () => {
+    const page = paginateList(items, 10, paginateList(items, 5, null).pageInfo.endCursor);
+    expect(page.edges).toEqual([]);
+    expect(page.pageInfo).toEqual({ hasNextPage: false, endCursor: null });
+  });
+
+  it("should map nodes and keep cursors", () => {
+    const page = mapConnection(paginateList(items, 2, null), (nodes) =>
+      nodes.map((n) => n.toUpperCase()),
+    );
+    expect(page.edges.map((e) => e.node)).toEqual(["A", "B"]);
+    expect(page.edges[0].cursor).toBe(paginateList(items, 2, null).edges[0].cursor);
+  });
+});
+
+describe("page arguments", () => {
+  it("should default and cap the page size", () => {
+    expect(getPageSize(undefined)).toBe(20);
+    expect(getPageSize(500)).toBe(100);
+    expect(() => getPageSize(0)).toThrow("first must be a positive integer");
+  });
+
+  it("should reject cursors it didn't issue", () => {
+    expect(decodeOffsetCursor(null)).toBe(0);
+    expect(() => decodeOffsetCursor("not-a-cursor")).toThrow("Invalid cursor");
+    expect(() => decodeOffsetCursor(btoa("offset:-1"))).toThrow("Invalid cursor");
+  });
+});
+
+describe("fetchDishesPage", () => {
+  it("should page mock dishes without Saleor", async () => {
+    const page = await fetchDishesPage("catA", "restA", 1, null);
+    expect(page.edges).toHaveLength(1);
+    expect(page.edges[0].node.categoryId).toBe("catA");
+    expect(page.pageInfo.hasNextPage).toBe(true);
+  });
+});
@@ -0,0 +1,96 @@
+// Cursor Pagination
+// Relay-style connections (edges { cursor node }, pageInfo) for menu lists.
+// Categories and dishes pass Saleor's own cursors through; channels are not
+// paginated by Saleor, so restaurants use opaque offset cursors over the
+// fetched list. Saleor caps a page at 100, and so do we.
+
+import { Connection, PageInfo } from "./contracts";
+import { badUserInputError } from "./errors";
+
+export const DEFAULT_PAGE_SIZE = 20;
+export const MAX_PAGE_SIZE = 100;
+// Whole-catalog reads (non-paginated queries) stop after this many pages
+export const MAX_CATALOG_PAGES = 20;
+
+const OFFSET_CURSOR_PREFIX = "offset:";
+
+/**
+ * Saleor pageInfo and edge cursors as returned by connection queries
+ */
+export interface SaleorPageInfo {
+  hasNextPage: boolean;
+  endCursor: string | null;
+}
+
+/**
+ * Page size for a first argument (default 20, at most 100)
+ */
+export function getPageSize(first: number | null | undefined): number {
+  if (first === null || first === undefined) {
+    return DEFAULT_PAGE_SIZE;
+  }
+  if (!Number.isInteger(first) || first < 1) {
+    throw badUserInputError("first must be a positive integer", "first");
+  }
+  return Math.min(first, MAX_PAGE_SIZE);
+}
+
+export function encodeOffsetCursor(offset: number): string {
+  return btoa(`${OFFSET_CURSOR_PREFIX}${offset}`);
+}
+
+/**
+ * Index of the first item after a cursor (0 without one)
+ */
+export function decodeOffsetCursor(cursor: string | null | undefined): number {
+  if (!cursor) {
+    return 0;
+  }
+  let decoded = "";
+  try {
+    decoded = atob(cursor);
+  } catch {
+    // fall through to the error below
+  }
+  const offset = Number(decoded.substring(OFFSET_CURSOR_PREFIX.length));
+  if (!decoded.startsWith(OFFSET_CURSOR_PREFIX) || !Number.isInteger(offset) || offset < 0) {
+    throw badUserInputError("Invalid cursor", "after");
+  }
+  return offset + 1;
+}
+
+/**
+ * Page of an in-memory list with offset cursors
+ */
+export function paginateList<T>(
+  items: T[],
+  first: number | null | undefined,
+  after: string | null | undefined,
+): Connection<T> {
+  const size = getPageSize(first);
+  const start = decodeOffsetCursor(after);
+  const page = items.slice(start, start + size);
+  const edges = page.map((node, i) => ({
+    cursor: encodeOffsetCursor(start + i),
+    node,
+  }));
+  const pageInfo: PageInfo = {
+    hasNextPage: start + size < items.length,
+    endCursor: edges.length > 0 ? edges[edges.length - 1].cursor : null,
+  };
+  return { edges, pageInfo };
+}
+
+/**
+ * Apply a mapping to every node of a connection
+ */
+export function mapConnection<T, U>(
+  connection: Connection<T>,
+  fn: (nodes: T[]) => U[],
+): Connection<U> {
+  const nodes = fn(connection.edges.map((edge) => edge.node));
+  return {
+    edges: connection.edges.map((edge, i) => ({ cursor: edge.cursor, node: nodes[i] })),
+    pageInfo: connection.pageInfo,
+  };
+}
@@ -0,0 +1,154 @@
+// Payment Gateway Tests
+// Tests for paymentGateways.ts - redirect URLs and starting gateway payments
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { SaleorFetch, initializeSaleorClient } from "./saleorClient";
+import { SaleorOrder, fetchUserOrder } from "./saleorOrder";
+import { writeJSON } from "./storage";
+import { extractRedirectUrl, initPayment } from "./paymentGateways";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+vi.mock("./saleorOrder", async (importOriginal) => ({
+  ...(await importOriginal<typeof import("./saleorOrder")>()),
+  fetchUserOrder: vi.fn(),
+}));
+
+vi.mock("./saleorService", async (importOriginal) => ({
+  ...(await importOriginal<typeof import("./saleorService")>()),
+  fetchChannelById: vi.fn(async (id: string) => ({
+    id,
+    slug: "restaurant-a",
+    name: "Restaurant A",
+    isActive: true,
+    currencyCode: "USD",
+    categories: [],
+  })),
+}));
+
+const ORDER: SaleorOrder = {
+  id: "T3JkZXI6MQ==",
+  number: 1,
+  status: "UNCONFIRMED",
+  channelId: "channelA",
+  total: { gross: { amount: 24.5, currency: "USD" } },
+  deliveryAddress: { address: "1 Test Street" },
+  lines: [],
+  createdAt: "2026-10-18T10:00:00.000Z",
+  metadata: { "tma.telegramUserId": "user-1" },
+};
+
+describe("extractRedirectUrl", () => {
+  it("should find a URL at the top level or one object down", () => {
+    expect(extractRedirectUrl({ redirectUrl: "https://pay.test/a" })).toBe("https://pay.test/a");
+    expect(extractRedirectUrl({ session: { url: "tg://resolve?domain=bot" } })).toBe(
+      "tg://resolve?domain=bot",
+    );
+  });
+
+  it("should ignore non-URLs and anything nested deeper", () => {
+    expect(extractRedirectUrl({ url: "javascript:alert(1)" })).toBeNull();
+    expect(extractRedirectUrl({ a: { b: { url: "https://pay.test/deep" } } })).toBeNull();
+    expect(extractRedirectUrl("https://pay.test/a")).toBeNull();
+    expect(extractRedirectUrl(null)).toBeNull();
+  });
+});
+
+// Saleor answers with one gateway and a transaction needing a redirect
+const send = vi.fn<SaleorFetch>(async (_, init) => {
+  const { operationName } = JSON.parse(String(init?.body));
+  if (operationName === "AvailablePaymentGateways") {
+    return Response.json({
+      data: {
+        shop: {
+          availablePaymentGateways: [{ id: "app.stripe", name: "Stripe", currencies: ["USD"] }],
+        },
+      },
+    });
+  }
+  return Response.json({
+    data: {
+      transactionInitialize: {
+        transaction: { id: "transaction-1" },
+        transactionEvent: { type: "CHARGE_ACTION_REQUIRED", message: null },
+        data: { session: { url: "https://pay.test/session" } },
+        errors: [],
+      },
+    },
+  });
+});
+
+function initializeCalls(): any[] {
+  return send.mock.calls
+    .map(([, init]) => JSON.parse(String(init?.body)))
+    .filter((body) => body.operationName === "TransactionInitialize");
+}
+
+describe("initPayment", () => {
+  beforeEach(() => {
+    vi.mocked(fetchUserOrder).mockResolvedValue(ORDER);
+    send.mockClear();
+    (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+    initializeSaleorClient({ SALEOR_API_URL: "https://saleor.test/graphql/", SALEOR_TOKEN: "t" });
+  });
+
+  afterEach(() => {
+    initializeSaleorClient({});
+    delete (globalThis as any).SALEOR_TRANSPORT;
+  });
+
+  it("should start a gateway transaction and return its redirect", async () => {
+    const payment = await initPayment(ORDER.id, "app.stripe", "user-1");
+
+    expect(payment).toEqual({
+      orderId: ORDER.id,
+      method: "app.stripe",
+      redirectUrl: "https://pay.test/session",
+      transactionId: "transaction-1",
+    });
+    expect(initializeCalls()[0].variables).toMatchObject({
+      id: ORDER.id,
+      paymentGateway: { id: "app.stripe" },
+      amount: 24.5,
+      action: null,
+    });
+  });
+
+  it("should reuse the idempotency key until a payment fails", async () => {
+    await initPayment(ORDER.id, "app.stripe", "user-1");
+    await initPayment(ORDER.id, "app.stripe", "user-1");
+    await writeJSON(`payment-event:${ORDER.id}`, {
+      orderId: ORDER.id,
+      event: "transaction_charge_requested",
+      failed: true,
+      receivedAt: "2026-10-18T10:05:00.000Z",
+    });
+    await initPayment(ORDER.id, "app.stripe", "user-1");
+
+    const keys = initializeCalls().map((body) => body.variables.idempotencyKey);
+    expect(keys[0]).toBeTruthy();
+    expect(keys[1]).toBe(keys[0]);
+    expect(keys[2]).not.toBe(keys[0]);
+  });
+
+  it("should refuse methods the restaurant doesn't accept", async () => {
+    await expect(initPayment(ORDER.id, "app.other", "user-1")).rejects.toMatchObject({
+      code: "BAD_USER_INPUT",
+      field: "method",
+    });
+    expect(initializeCalls()).toHaveLength(0);
+  });
+
+  it("should refuse orders that are already paid", async () => {
+    vi.mocked(fetchUserOrder).mockResolvedValue({
+      ...ORDER,
+      metadata: { ...ORDER.metadata, "tma.state": "PAID" },
+    });
+    await expect(initPayment(ORDER.id, "app.stripe", "user-1")).rejects.toThrow(
+      "already paid",
+    );
+  });
+});
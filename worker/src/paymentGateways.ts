@@ -0,0 +1,273 @@
+// External Payment Gateways
+// Lists the payment methods a restaurant accepts (Saleor payment apps such
+// as Stripe, optionally narrowed by the channel's tma_payment_gateways
+// metadata, plus Telegram Payments when configured) and starts a payment:
+// gateways go through Saleor transactionInitialize and return the redirect
+// or deep-link URL from the app's response; Telegram returns an invoice link.
+// With payment holds on, gateways only authorize the total (paymentHolds.ts).
+// Each attempt carries an idempotency key, so repeated taps get Saleor's
+// existing transaction; a failed payment starts a fresh one.
+
+import { InitPaymentPayload, PaymentMethod } from "./contracts";
+import { getVar } from "./config";
+import { badUserInputError, notFoundError } from "./errors";
+import { logger } from "./logger";
+import { parseListValue } from "./metadata";
+import { isPaymentHoldEnabled, recordPaymentHold } from "./paymentHolds";
+import { isTerminalOrderStatus } from "./orderStatus";
+import { getPaymentEvent, resolvePaymentStatus } from "./paymentStatus";
+import {
+  getSaleorClient,
+  isSaleorConfigured,
+  AVAILABLE_PAYMENT_GATEWAYS_QUERY,
+  TRANSACTION_INITIALIZE_MUTATION,
+} from "./saleorClient";
+import {
+  AvailablePaymentGatewaysQuery,
+  AvailablePaymentGatewaysQueryVariables,
+  TransactionInitializeMutation,
+  TransactionInitializeMutationVariables,
+} from "./saleorOperations";
+import {
+  SaleorOrder,
+  fetchUserOrder,
+  getNormalizedStatus,
+  isAwaitingAbuseReview,
+} from "./saleorOrder";
+import { fetchChannelById } from "./saleorService";
+import { createInvoice, isTelegramPaymentsEnabled } from "./telegramPayments";
+
+// Channel metadata key listing allowed Saleor gateway IDs (comma-separated)
+export const PAYMENT_GATEWAYS_METADATA_KEY = "tma_payment_gateways";
+export const TELEGRAM_PAYMENT_METHOD_ID = "telegram";
+
+// Keys gateway apps commonly use for the URL the customer must open
+const REDIRECT_URL_KEYS = [
+  "redirectUrl",
+  "redirect_url",
+  "checkoutUrl",
+  "checkout_url",
+  "paymentUrl",
+  "payment_url",
+  "url",
+];
+// Nested objects searched below the top level of the gateway data
+const MAX_REDIRECT_URL_DEPTH = 1;
+
+/**
+ * Find a redirect/deep-link URL in a gateway's transactionInitialize data
+ */
+export function extractRedirectUrl(data: unknown, depth = 0): string | null {
+  if (!data || typeof data !== "object") {
+    return null;
+  }
+  const record = data as Record<string, unknown>;
+  for (const key of REDIRECT_URL_KEYS) {
+    const value = record[key];
+    if (typeof value === "string" && /^https?:\/\/|^tg:\/\//.test(value)) {
+      return value;
+    }
+  }
+  // One level down, e.g. { session: { url } } or { paymentIntent: { ... } }
+  if (depth >= MAX_REDIRECT_URL_DEPTH) {
+    return null;
+  }
+  for (const value of Object.values(record)) {
+    if (value && typeof value === "object" && !Array.isArray(value)) {
+      const nested = extractRedirectUrl(value, depth + 1);
+      if (nested) {
+        return nested;
+      }
+    }
+  }
+  return null;
+}
+
+async function fetchChannelGateways(
+  channelSlug: string,
+): Promise<PaymentMethod[]> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    return [];
+  }
+  const variables: AvailablePaymentGatewaysQueryVariables = { channel: channelSlug };
+  const response = await client.execute<AvailablePaymentGatewaysQuery>(
+    AVAILABLE_PAYMENT_GATEWAYS_QUERY,
+    variables,
+  );
+  if (response.errors && response.errors.length > 0) {
+    logger.error("saleor_payment_gateways_error", {
+      error: response.errors.map((e) => e.message).join(", "),
+      channel: channelSlug,
+    });
+    return [];
+  }
+  const gateways = response.data?.shop?.availablePaymentGateways || [];
+  return gateways.map(
+    (gateway): PaymentMethod => ({
+      id: gateway.id,
+      name: gateway.name,
+      kind: "GATEWAY",
+      currencies: gateway.currencies || [],
+    }),
+  );
+}
+
+/**
+ * Payment methods a restaurant accepts
+ */
+export async function listPaymentMethods(
+  restaurantId: string,
+): Promise<PaymentMethod[]> {
+  const channel = await fetchChannelById(restaurantId);
+  if (!channel) {
+    throw notFoundError("Restaurant not found");
+  }
+
+  let gateways = await fetchChannelGateways(channel.slug);
+  const allowed = parseListValue(channel.metadata?.[PAYMENT_GATEWAYS_METADATA_KEY]);
+  if (allowed.length > 0) {
+    gateways = gateways.filter((gateway) => allowed.includes(gateway.id));
+  }
+
+  const methods: PaymentMethod[] = [...gateways];
+  if (isTelegramPaymentsEnabled()) {
+    methods.push({
+      id: TELEGRAM_PAYMENT_METHOD_ID,
+      name: "Telegram",
+      kind: "TELEGRAM",
+      currencies: [channel.currencyCode],
+    });
+  }
+  return methods;
+}
+
+/**
+ * Idempotency key for a gateway payment attempt
+ * Same order, method, amount and flow reuse it until a payment fails
+ */
+export function buildPaymentIdempotencyKey(
+  order: Pick<SaleorOrder, "id" | "total">,
+  method: string,
+  hold: boolean,
+  failedAt?: string,
+): string {
+  return [
+    "tma",
+    order.id,
+    method,
+    order.total.gross.amount,
+    hold ? "authorize" : "charge",
+    failedAt || "initial",
+  ].join(":");
+}
+
+/**
+ * Start paying one of the user's orders with the chosen method
+ */
+export async function initPayment(
+  orderId: string,
+  method: string,
+  userId: string,
+): Promise<InitPaymentPayload> {
+  const order = await fetchUserOrder(orderId, userId);
+  if (!order) {
+    throw notFoundError("Order not found");
+  }
+  const paymentStatus = resolvePaymentStatus(order);
+  if (paymentStatus === "PAID" || paymentStatus === "AUTHORIZED") {
+    throw badUserInputError("Order is already paid", "orderId");
+  }
+  if (isTerminalOrderStatus(getNormalizedStatus(order))) {
+    throw badUserInputError("Order can no longer be paid", "orderId");
+  }
+  if (isAwaitingAbuseReview(order)) {
+    throw badUserInputError("Order is awaiting review", "orderId");
+  }
+
+  const methods = await listPaymentMethods(order.channelId || "");
+  if (!methods.some((m) => m.id === method)) {
+    throw badUserInputError("Payment method is not available", "method");
+  }
+
+  if (method === TELEGRAM_PAYMENT_METHOD_ID) {
+    const invoice = await createInvoice(orderId, userId);
+    return {
+      orderId,
+      method,
+      redirectUrl: invoice.invoiceUrl,
+      transactionId: null,
+    };
+  }
+
+  const client = getSaleorClient();
+  if (!client) {
+    throw badUserInputError("Payments are not configured");
+  }
+  const hold = isPaymentHoldEnabled();
+  const lastEvent = await getPaymentEvent(orderId);
+  const variables: TransactionInitializeMutationVariables = {
+    id: orderId,
+    paymentGateway: {
+      id: method,
+      data: {
+        // Where the gateway sends the customer back (e.g. the Mini App link)
+        returnUrl: getVar("PAYMENT_RETURN_URL") || null,
+      },
+    },
+    amount: order.total.gross.amount,
+    // Authorize only; acceptOrder captures, rejectOrder voids
+    action: hold ? "AUTHORIZATION" : null,
+    idempotencyKey: buildPaymentIdempotencyKey(
+      order,
+      method,
+      hold,
+      lastEvent?.failed ? lastEvent.receivedAt : undefined,
+    ),
+  };
+  const response = await client.execute<TransactionInitializeMutation>(
+    TRANSACTION_INITIALIZE_MUTATION,
+    variables,
+  );
+
+  const result = response.data?.transactionInitialize;
+  const errors = [
+    ...(response.errors || []).map((e) => e.message),
+    ...(result?.errors || []).map((e) => e.message),
+  ];
+  if (errors.length > 0 || !result) {
+    logger.error("payment_init_failed", {
+      orderId,
+      method,
+      error: errors.join(", ") || "No transactionInitialize result",
+    });
+    throw badUserInputError("Could not start the payment, please try again");
+  }
+
+  if (hold && result.transaction) {
+    await recordPaymentHold(
+      orderId,
+      result.transaction.id,
+      method,
+      order.total.gross.amount,
+      order.total.gross.currency,
+    );
+  }
+
+  const redirectUrl = extractRedirectUrl(result.data);
+  logger.info("payment_initialized", {
+    orderId,
+    method,
+    transactionId: result.transaction?.id,
+    event: result.transactionEvent?.type,
+    hasRedirect: Boolean(redirectUrl),
+    hold,
+  });
+
+  return {
+    orderId,
+    method,
+    redirectUrl,
+    transactionId: result.transaction?.id ?? null,
+  };
+}
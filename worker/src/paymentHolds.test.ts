@@ -0,0 +1,125 @@
+// Payment Hold Tests
+// Tests for paymentHolds.ts - accepting and rejecting held orders
+
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import { createSaleorOrder, getOrder, clearOrders, updateOrderMetadata } from "./saleorOrder";
+import {
+  acceptOrder,
+  confirmPaymentHold,
+  getPaymentHold,
+  recordPaymentHold,
+  rejectOrder,
+} from "./paymentHolds";
+import { extractAuthorizedTransaction } from "./saleorWebhooks";
+import { resolvePaymentStatus } from "./paymentStatus";
+import { reserveSlot, countSlotReservations } from "./slots";
+import { PlaceOrderInput } from "./contracts";
+
+// Mock the logger to avoid console output during tests
+vi.mock("./logger", () => ({
+  logger: {
+    info: vi.fn(),
+    error: vi.fn(),
+    warn: vi.fn(),
+    debug: vi.fn(),
+  },
+}));
+
+const orderInput: PlaceOrderInput = {
+  restaurantId: "restA",
+  deliveryLocation: { address: "1 Test Street" },
+  items: [{ dishId: "dish1", quantity: 1 }],
+};
+
+async function createOrder(userId: string) {
+  const result = await createSaleorOrder(orderInput, userId);
+  return result.order!;
+}
+
+describe("payment holds", () => {
+  beforeEach(() => {
+    clearOrders();
+  });
+
+  it("should report held payments as AUTHORIZED", () => {
+    expect(resolvePaymentStatus({ status: "UNCONFIRMED", authorizeStatus: "FULL" })).toBe(
+      "AUTHORIZED",
+    );
+    expect(
+      resolvePaymentStatus({ status: "UNFULFILLED", authorizeStatus: "FULL", chargeStatus: "FULL" }),
+    ).toBe("PAID");
+  });
+
+  it("should accept orders without a hold once", async () => {
+    const order = await createOrder("user-1");
+    expect(await acceptOrder(order.id, "admin")).toEqual({
+      orderId: order.id,
+      accepted: true,
+      paymentHold: "NONE",
+    });
+    expect(getOrder(order.id)?.metadata?.["tma.acceptedAt"]).toBeDefined();
+    expect((await acceptOrder(order.id, "admin")).accepted).toBe(true);
+    await expect(rejectOrder(order.id, "Closed", "admin")).rejects.toThrow(
+      "Order was already accepted",
+    );
+  });
+
+  it("should cancel rejected orders and free their slot", async () => {
+    const order = await createOrder("user-2");
+    const scheduledFor = new Date(Date.now() + 2 * 60 * 60 * 1000).toISOString();
+    const slotStart = await reserveSlot("restA", scheduledFor, order.id);
+    await updateOrderMetadata(order.id, { "tma.scheduledFor": scheduledFor });
+
+    const result = await rejectOrder(order.id, "Out of dough", "admin");
+    expect(result).toEqual({ orderId: order.id, accepted: false, paymentHold: "NONE" });
+    expect(getOrder(order.id)?.status).toBe("CANCELLED");
+    expect(getOrder(order.id)?.metadata?.["tma.rejectionReason"]).toBe("Out of dough");
+    expect(await countSlotReservations("restA", slotStart)).toBe(0);
+  });
+
+  it("should hold a payment only once the customer authorizes it", async () => {
+    const order = await createOrder("user-4");
+    await recordPaymentHold(order.id, "txn-1", "app.stripe", 12, "EUR");
+    expect((await getPaymentHold(order.id))?.status).toBe("PENDING");
+    await expect(acceptOrder(order.id, "admin")).rejects.toThrow(
+      "Order is waiting for the customer's payment",
+    );
+
+    const payload = {
+      transaction: {
+        id: "txn-1",
+        events: [{ type: "AUTHORIZATION_REQUEST" }, { type: "AUTHORIZATION_SUCCESS" }],
+      },
+    };
+    expect(extractAuthorizedTransaction(payload)).toBe("txn-1");
+    expect(await confirmPaymentHold(order.id, "txn-2")).toBe(false);
+    expect(await confirmPaymentHold(order.id, "txn-1")).toBe(true);
+    expect((await getPaymentHold(order.id))?.status).toBe("HELD");
+  });
+
+  it("should void a pending payment when the order is rejected", async () => {
+    const order = await createOrder("user-5");
+    await recordPaymentHold(order.id, "txn-1", "app.stripe", 12, "EUR");
+    const result = await rejectOrder(order.id, "Closed", "admin");
+    expect(result.paymentHold).toBe("VOIDED");
+    expect(getOrder(order.id)?.status).toBe("CANCELLED");
+  });
+
+  it("should refuse to reject orders already charged", async () => {
+    const order = await createOrder("user-6");
+    await updateOrderMetadata(order.id, { "tma.state": "PAID" });
+    await expect(rejectOrder(order.id, "Closed", "admin")).rejects.toThrow("already paid");
+    expect(getOrder(order.id)?.status).toBe("CREATED");
+  });
+
+  it("should keep the order when a held payment can't be voided", async () => {
+    const order = await createOrder("user-3");
+    await recordPaymentHold(order.id, "txn-1", "app.stripe", 12, "EUR");
+    await confirmPaymentHold(order.id, "txn-1");
+    await expect(rejectOrder(order.id, "Closed", "admin")).rejects.toThrow(
+      "Could not release the payment",
+    );
+    expect(getOrder(order.id)?.status).toBe("CREATED");
+    expect((await getPaymentHold(order.id))?.status).toBe("HELD");
+  });
+});
@@ -0,0 +1,401 @@
+// Payment Holds (Order-Ahead Pre-Authorization)
+// With PAYMENT_HOLD_UNTIL_ACCEPTED, gateway payments only authorize the
+// order total at order time. The restaurant's acceptOrder captures the hold;
+// rejectOrder voids it and cancels the order, as does the customer's
+// cancelOrder before then. Holds go through Saleor transactions
+// (transactionInitialize with action AUTHORIZATION, then
+// transactionRequestAction CHARGE / CANCEL), so the payment app talks to the
+// provider. Telegram Payments can't hold and are charged at once.
+// Each hold's transaction is kept in KV (payment-hold:<orderId>): PENDING
+// when initPayment starts it, HELD once the gateway reports
+// AUTHORIZATION_SUCCESS (Saleor transaction webhook). Orders already
+// charged can't be refunded from here, so they can't be cancelled either.
+
+import { buildCancellationMetadata, getCancelledBy } from "./cancellationReasons";
+import { CancellationReasonCode, OrderDecisionPayload, PaymentHoldStatus } from "./contracts";
+import { getBooleanVar } from "./config";
+import { badUserInputError, internalError, notFoundError } from "./errors";
+import { logger } from "./logger";
+import { restoreRedeemedPoints } from "./loyalty";
+import { notifyOrderCancelled } from "./notifications";
+import { clearPaymentDeadline } from "./orderState";
+import { isTerminalOrderStatus } from "./orderStatus";
+import { resolvePaymentStatus } from "./paymentStatus";
+import {
+  getSaleorClient,
+  isSaleorConfigured,
+  TRANSACTION_REQUEST_ACTION_MUTATION,
+} from "./saleorClient";
+import {
+  TransactionRequestActionMutation,
+  TransactionRequestActionMutationVariables,
+} from "./saleorOperations";
+import {
+  ORDER_METADATA_KEYS,
+  SaleorOrder,
+  addOrderNote,
+  cancelSaleorOrder,
+  fetchOrderById,
+  fetchUserOrder,
+  getNormalizedStatus,
+  isAwaitingAbuseReview,
+  updateOrderMetadata,
+} from "./saleorOrder";
+import { getSlotStart, releaseSlot } from "./slots";
+import { readJSON, writeJSON } from "./storage";
+
+/**
+ * Payment held for an order
+ */
+export interface PaymentHoldRecord {
+  orderId: string;
+  transactionId: string;
+  method: string;
+  amount: number;
+  currency: string;
+  status: Exclude<PaymentHoldStatus, "NONE">;
+  createdAt: string;
+  authorizedAt?: string;
+  settledAt?: string;
+}
+
+const HOLD_PREFIX = "payment-hold:";
+// Card authorizations lapse after about a week; keep the record a while longer
+const HOLD_TTL_SECONDS = 30 * 24 * 60 * 60;
+
+function getKey(orderId: string): string {
+  return `${HOLD_PREFIX}${orderId}`;
+}
+
+/**
+ * Whether gateway payments are held until the restaurant accepts
+ * (PAYMENT_HOLD_UNTIL_ACCEPTED)
+ */
+export function isPaymentHoldEnabled(): boolean {
+  return getBooleanVar("PAYMENT_HOLD_UNTIL_ACCEPTED");
+}
+
+export async function getPaymentHold(orderId: string): Promise<PaymentHoldRecord | null> {
+  return readJSON<PaymentHoldRecord>(getKey(orderId));
+}
+
+/**
+ * Remember the transaction started for an order's payment, PENDING until
+ * the customer authorizes it; a previous transaction still pending is
+ * voided rather than left behind
+ */
+export async function recordPaymentHold(
+  orderId: string,
+  transactionId: string,
+  method: string,
+  amount: number,
+  currency: string,
+): Promise<PaymentHoldRecord> {
+  const previous = await getPaymentHold(orderId);
+  if (previous?.status === "PENDING" && previous.transactionId !== transactionId) {
+    await voidPendingHold(previous);
+  }
+  const record: PaymentHoldRecord = {
+    orderId,
+    transactionId,
+    method,
+    amount,
+    currency,
+    status: "PENDING",
+    createdAt: new Date().toISOString(),
+  };
+  await writeJSON(getKey(orderId), record, { expirationTtl: HOLD_TTL_SECONDS });
+  logger.info("payment_hold_recorded", { orderId, transactionId, method });
+  return record;
+}
+
+/**
+ * Mark a pending hold HELD once its transaction reports
+ * AUTHORIZATION_SUCCESS; false when no hold of that transaction is pending
+ */
+export async function confirmPaymentHold(
+  orderId: string,
+  transactionId: string,
+): Promise<boolean> {
+  const hold = await getPaymentHold(orderId);
+  if (hold?.status !== "PENDING") {
+    return false;
+  }
+  if (hold.transactionId !== transactionId) {
+    logger.warn("payment_hold_unknown_transaction", {
+      orderId,
+      transactionId,
+      expected: hold.transactionId,
+    });
+    return false;
+  }
+  await writeJSON(
+    getKey(orderId),
+    { ...hold, status: "HELD", authorizedAt: new Date().toISOString() },
+    { expirationTtl: HOLD_TTL_SECONDS },
+  );
+  logger.info("payment_hold_authorized", { orderId, transactionId });
+  return true;
+}
+
+/**
+ * Ask Saleor's payment app to capture or void a held transaction
+ */
+async function requestTransactionAction(
+  hold: PaymentHoldRecord,
+  actionType: "CHARGE" | "CANCEL",
+): Promise<boolean> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    return false;
+  }
+  try {
+    const variables: TransactionRequestActionMutationVariables = {
+      id: hold.transactionId,
+      actionType,
+      amount: actionType === "CHARGE" ? hold.amount : null,
+    };
+    const response = await client.execute<TransactionRequestActionMutation>(
+      TRANSACTION_REQUEST_ACTION_MUTATION,
+      variables,
+    );
+    const errors = [
+      ...(response.errors || []).map((e) => e.message),
+      ...(response.data?.transactionRequestAction?.errors || []).map((e) => e.message),
+    ];
+    if (errors.length > 0) {
+      logger.error("payment_hold_action_failed", {
+        orderId: hold.orderId,
+        transactionId: hold.transactionId,
+        actionType,
+        error: errors.join(", "),
+      });
+      return false;
+    }
+    return true;
+  } catch (error) {
+    logger.error("payment_hold_action_failed", {
+      orderId: hold.orderId,
+      transactionId: hold.transactionId,
+      actionType,
+      error: error instanceof Error ? error.message : "Unknown error",
+    });
+    return false;
+  }
+}
+
+/**
+ * Restaurant (channel) an order belongs to, for admin checks
+ */
+export async function getOrderRestaurantId(orderId: string): Promise<string> {
+  const order = orderId ? await fetchOrderById(orderId) : null;
+  if (!order) {
+    throw notFoundError("Order not found");
+  }
+  return order.channelId || "";
+}
+
+async function settleHold(
+  hold: PaymentHoldRecord,
+  status: "CAPTURED" | "VOIDED",
+): Promise<void> {
+  await writeJSON(
+    getKey(hold.orderId),
+    { ...hold, status, settledAt: new Date().toISOString() },
+    { expirationTtl: HOLD_TTL_SECONDS },
+  );
+}
+
+/**
+ * Void a transaction the customer never authorized; an abandoned one may
+ * have nothing to void, so a failure is only logged
+ */
+async function voidPendingHold(hold: PaymentHoldRecord): Promise<void> {
+  if (!(await requestTransactionAction(hold, "CANCEL"))) {
+    logger.warn("payment_hold_void_skipped", {
+      orderId: hold.orderId,
+      transactionId: hold.transactionId,
+    });
+  }
+  await settleHold(hold, "VOIDED");
+}
+
+/**
+ * Accept an order, capturing its held payment
+ * Accepting twice is a no-op
+ */
+export async function acceptOrder(
+  orderId: string,
+  acceptedBy: string,
+): Promise<OrderDecisionPayload> {
+  const order = await fetchOrderById(orderId);
+  if (!order) {
+    throw notFoundError("Order not found");
+  }
+  const hold = await getPaymentHold(orderId);
+  if (order.metadata?.[ORDER_METADATA_KEYS.acceptedAt]) {
+    return { orderId, accepted: true, paymentHold: hold?.status ?? "NONE" };
+  }
+  if (isTerminalOrderStatus(getNormalizedStatus(order))) {
+    throw badUserInputError("Order can no longer be accepted", "orderId");
+  }
+  // Orders flagged by abuseDetection.ts wait for an admin's approval
+  if (isAwaitingAbuseReview(order)) {
+    throw badUserInputError("Order is awaiting review", "orderId");
+  }
+  // Nothing to capture until the customer authorizes the payment
+  if (hold?.status === "PENDING" && resolvePaymentStatus(order) !== "PAID") {
+    throw badUserInputError("Order is waiting for the customer's payment", "orderId");
+  }
+
+  if (hold?.status === "HELD") {
+    if (!(await requestTransactionAction(hold, "CHARGE"))) {
+      throw internalError(
+        "payment_capture_failed",
+        "Could not capture the payment, please try again",
+      );
+    }
+    await settleHold(hold, "CAPTURED");
+  }
+
+  await updateOrderMetadata(orderId, {
+    [ORDER_METADATA_KEYS.acceptedAt]: new Date().toISOString(),
+  });
+  logger.info("order_accepted", { orderId, acceptedBy, captured: hold?.status === "HELD" });
+  return {
+    orderId,
+    accepted: true,
+    paymentHold: hold?.status === "HELD" ? "CAPTURED" : hold?.status ?? "NONE",
+  };
+}
+
+/**
+ * Cancel an order the restaurant hasn't accepted yet: void its held
+ * payment, cancel it in Saleor with the reason, give back redeemed points,
+ * free its delivery slot and tell the customer why
+ */
+async function cancelUnacceptedOrder(
+  order: SaleorOrder,
+  reason: CancellationReasonCode,
+  note: string | null,
+  cancelledBy: string,
+): Promise<OrderDecisionPayload> {
+  const orderId = order.id;
+  const hold = await getPaymentHold(orderId);
+  // Charged payments (Telegram Payments, or gateways without holds) can't
+  // be refunded from here; those orders are cancelled in the dashboard
+  if (hold?.status !== "HELD" && resolvePaymentStatus(order) === "PAID") {
+    throw badUserInputError(
+      "This order is already paid and can only be cancelled with a refund",
+      "orderId",
+    );
+  }
+  if (hold?.status === "HELD") {
+    if (!(await requestTransactionAction(hold, "CANCEL"))) {
+      throw internalError(
+        "payment_void_failed",
+        "Could not release the payment, please try again",
+      );
+    }
+    await settleHold(hold, "VOIDED");
+  } else if (hold?.status === "PENDING") {
+    await voidPendingHold(hold);
+  }
+  const voided = hold?.status === "HELD" || hold?.status === "PENDING";
+
+  if (!(await cancelSaleorOrder(orderId))) {
+    throw internalError("order_cancel_failed", "Could not cancel the order, please try again");
+  }
+  const metadata = buildCancellationMetadata(reason, note);
+  if (note && getCancelledBy(reason) === "RESTAURANT") {
+    // Kept for readers of the pre-reason-code key
+    metadata[ORDER_METADATA_KEYS.rejectionReason] = note;
+  }
+  await updateOrderMetadata(orderId, metadata);
+  await addOrderNote(
+    orderId,
+    `Cancelled (${reason}) by ${cancelledBy}${note ? `: ${note}` : ""}`,
+  );
+  await clearPaymentDeadline(orderId);
+  await restoreRedeemedPoints(orderId);
+
+  const scheduledFor = order.metadata?.[ORDER_METADATA_KEYS.scheduledFor];
+  if (scheduledFor && order.channelId) {
+    await releaseSlot(order.channelId, getSlotStart(new Date(scheduledFor)), orderId);
+  }
+
+  const userId = order.metadata?.[ORDER_METADATA_KEYS.telegramUserId];
+  if (userId) {
+    await notifyOrderCancelled(
+      userId,
+      orderId,
+      order.number,
+      {
+        reason,
+        cancelledBy: getCancelledBy(reason),
+        note: metadata[ORDER_METADATA_KEYS.cancellationNote] || null,
+        cancelledAt: metadata[ORDER_METADATA_KEYS.cancelledAt],
+      },
+      {
+        languageCode: order.metadata?.[ORDER_METADATA_KEYS.language],
+        paymentReleased: hold?.status === "HELD",
+      },
+    );
+  }
+
+  logger.info("order_cancelled", {
+    orderId,
+    reason,
+    cancelledBy,
+    voided,
+  });
+  return {
+    orderId,
+    accepted: false,
+    paymentHold: voided ? "VOIDED" : hold?.status ?? "NONE",
+  };
+}
+
+/**
+ * Reject an order with a reason code (RESTAURANT_REJECTED by default) and
+ * a note for the customer
+ */
+export async function rejectOrder(
+  orderId: string,
+  note: string | null,
+  rejectedBy: string,
+  reason: CancellationReasonCode = "RESTAURANT_REJECTED",
+): Promise<OrderDecisionPayload> {
+  const order = await fetchOrderById(orderId);
+  if (!order) {
+    throw notFoundError("Order not found");
+  }
+  if (order.metadata?.[ORDER_METADATA_KEYS.acceptedAt]) {
+    throw badUserInputError("Order was already accepted", "orderId");
+  }
+  if (isTerminalOrderStatus(getNormalizedStatus(order))) {
+    throw badUserInputError("Order can no longer be rejected", "orderId");
+  }
+  return cancelUnacceptedOrder(order, reason, note, rejectedBy);
+}
+
+/**
+ * Cancel a customer's own order, allowed until the restaurant accepts it
+ */
+export async function cancelUserOrder(
+  orderId: string,
+  userId: string,
+  note: string | null,
+): Promise<OrderDecisionPayload> {
+  const order = await fetchUserOrder(orderId, userId);
+  if (!order) {
+    throw notFoundError("Order not found");
+  }
+  if (order.metadata?.[ORDER_METADATA_KEYS.acceptedAt]) {
+    throw badUserInputError("The restaurant already accepted this order", "orderId");
+  }
+  if (isTerminalOrderStatus(getNormalizedStatus(order))) {
+    throw badUserInputError("Order can no longer be cancelled", "orderId");
+  }
+  return cancelUnacceptedOrder(order, "CUSTOMER_REQUEST", note, userId);
+}
@@ -0,0 +1,47 @@
+// Payment Status Tests
+// Tests for paymentStatus.ts and saleorWebhooks.ts - status mapping and payloads
+
+import { describe, it, expect } from "vitest";
+import { resolvePaymentStatus } from "./paymentStatus";
+import { extractOrderId, isFailedPayment, isPaymentEvent } from "./saleorWebhooks";
+
+describe("resolvePaymentStatus", () => {
+  it("should map Saleor charge and payment statuses", () => {
+    expect(resolvePaymentStatus({ status: "UNFULFILLED", chargeStatus: "FULL" })).toBe("PAID");
+    expect(resolvePaymentStatus({ status: "UNCONFIRMED", chargeStatus: "NONE" })).toBe("PENDING");
+    expect(
+      resolvePaymentStatus({ status: "UNFULFILLED", paymentStatus: "FULLY_REFUNDED" }),
+    ).toBe("REFUNDED");
+    expect(resolvePaymentStatus({ status: "CANCELED" })).toBe("FAILED");
+  });
+
+  it("should apply the app state and failed webhooks", () => {
+    expect(
+      resolvePaymentStatus({ status: "UNCONFIRMED", metadata: { "tma.state": "PAID" } }),
+    ).toBe("PAID");
+    expect(resolvePaymentStatus({ status: "UNCONFIRMED" }, { failed: true })).toBe("FAILED");
+  });
+});
+
+describe("Saleor webhook payloads", () => {
+  it("should recognise payment events", () => {
+    expect(isPaymentEvent("order_fully_paid")).toBe(true);
+    expect(isPaymentEvent("transaction_item_metadata_updated")).toBe(true);
+    expect(isPaymentEvent("product_updated")).toBe(false);
+  });
+
+  it("should find the order ID in subscription and legacy payloads", () => {
+    expect(extractOrderId({ order: { id: "T3JkZXI6MQ==" } })).toBe("T3JkZXI6MQ==");
+    expect(extractOrderId({ transaction: { order: { id: "o-2" } } })).toBe("o-2");
+    expect(extractOrderId([{ type: "Order", id: "o-3" }])).toBe("o-3");
+    expect(extractOrderId({ product: { id: "p-1" } })).toBeNull();
+  });
+
+  it("should detect failed charges", () => {
+    const payload = {
+      transaction: { events: [{ type: "CHARGE_REQUEST" }, { type: "CHARGE_FAILURE" }] },
+    };
+    expect(isFailedPayment("transaction_item_metadata_updated", payload)).toBe(true);
+    expect(isFailedPayment("order_fully_paid", { order: { id: "o-1" } })).toBe(false);
+  });
+});
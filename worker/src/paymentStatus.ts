@@ -0,0 +1,148 @@
+// Order Payment Status
+// Maps Saleor's charge/payment status (plus the app's tma.state and the
+// latest payment webhook) to a simple PAID / AUTHORIZED / PENDING / FAILED /
+// REFUNDED status, so the Mini App can show payment progress without polling Saleor.
+
+import { OrderPaymentStatus, PaymentStatus } from "./contracts";
+import { notFoundError } from "./errors";
+import { logger } from "./logger";
+import { markOrderAuthorized, markOrderPaid } from "./orderState";
+import {
+  SaleorOrder,
+  ORDER_METADATA_KEYS,
+  fetchOrderById,
+  fetchUserOrder,
+} from "./saleorOrder";
+import { readJSON, writeJSON } from "./storage";
+
+/**
+ * Latest payment webhook seen for an order
+ */
+export interface PaymentEventRecord {
+  orderId: string;
+  event: string; // Saleor event type, lower case (e.g. order_fully_paid)
+  failed: boolean;
+  receivedAt: string;
+}
+
+const EVENT_PREFIX = "payment-event:";
+const EVENT_TTL_SECONDS = 30 * 24 * 60 * 60;
+
+const PAID_CHARGE_STATUSES = ["FULL", "OVERCHARGED"];
+const REFUNDED_PAYMENT_STATUSES = ["FULLY_REFUNDED", "PARTIALLY_REFUNDED"];
+const FAILED_PAYMENT_STATUSES = ["REFUSED", "CANCELLED"];
+
+function getKey(orderId: string): string {
+  return `${EVENT_PREFIX}${orderId}`;
+}
+
+/**
+ * Derive the payment status of an order
+ */
+export function resolvePaymentStatus(
+  order: Pick<
+    SaleorOrder,
+    "status" | "chargeStatus" | "paymentStatus" | "authorizeStatus" | "metadata"
+  >,
+  lastEvent?: Pick<PaymentEventRecord, "failed"> | null,
+): PaymentStatus {
+  const state = order.metadata?.[ORDER_METADATA_KEYS.state];
+  if (REFUNDED_PAYMENT_STATUSES.includes(order.paymentStatus || "")) {
+    return "REFUNDED";
+  }
+  if (
+    state === "PAID" ||
+    PAID_CHARGE_STATUSES.includes(order.chargeStatus || "") ||
+    order.paymentStatus === "FULLY_CHARGED"
+  ) {
+    return "PAID";
+  }
+  if (
+    state === "EXPIRED" ||
+    order.status === "CANCELED" ||
+    FAILED_PAYMENT_STATUSES.includes(order.paymentStatus || "") ||
+    lastEvent?.failed
+  ) {
+    return "FAILED";
+  }
+  // Held until the restaurant accepts (payment holds)
+  if (state === "AUTHORIZED" || order.authorizeStatus === "FULL") {
+    return "AUTHORIZED";
+  }
+  return "PENDING";
+}
+
+/**
+ * Latest payment webhook recorded for an order, if any
+ */
+export async function getPaymentEvent(orderId: string): Promise<PaymentEventRecord | null> {
+  return readJSON<PaymentEventRecord>(getKey(orderId));
+}
+
+function toOrderPaymentStatus(
+  order: SaleorOrder,
+  lastEvent: PaymentEventRecord | null,
+): OrderPaymentStatus {
+  return {
+    orderId: order.id,
+    status: resolvePaymentStatus(order, lastEvent),
+    chargeStatus: order.chargeStatus ?? null,
+    amountCharged: order.totalCharged ?? null,
+    total: order.total.gross.amount,
+    currency: order.total.gross.currency,
+    lastEvent: lastEvent?.event ?? null,
+    updatedAt: lastEvent?.receivedAt ?? null,
+  };
+}
+
+/**
+ * Payment status of one of the user's orders
+ */
+export async function getPaymentStatus(
+  orderId: string,
+  userId: string,
+): Promise<OrderPaymentStatus> {
+  const order = await fetchUserOrder(orderId, userId);
+  if (!order) {
+    throw notFoundError("Order not found");
+  }
+  return toOrderPaymentStatus(order, await getPaymentEvent(orderId));
+}
+
+/**
+ * Record a payment/transaction webhook and sync the app state
+ * Returns the resulting payment status, or null for unknown orders
+ */
+export async function recordPaymentEvent(
+  orderId: string,
+  event: string,
+  failed: boolean,
+): Promise<PaymentStatus | null> {
+  const order = await fetchOrderById(orderId);
+  if (!order) {
+    logger.warn("payment_event_unknown_order", { orderId, event });
+    return null;
+  }
+
+  const record: PaymentEventRecord = {
+    orderId,
+    event,
+    failed,
+    receivedAt: new Date().toISOString(),
+  };
+  await writeJSON(getKey(orderId), record, { expirationTtl: EVENT_TTL_SECONDS });
+
+  const status = resolvePaymentStatus(order, record);
+  if (status === "PAID" && order.metadata?.[ORDER_METADATA_KEYS.state] !== "PAID") {
+    // Stops the unpaid-order expiry job from cancelling it
+    await markOrderPaid(orderId);
+  } else if (
+    status === "AUTHORIZED" &&
+    order.metadata?.[ORDER_METADATA_KEYS.state] === "PENDING_PAYMENT"
+  ) {
+    await markOrderAuthorized(orderId);
+  }
+
+  logger.info("payment_event_recorded", { orderId, event, status });
+  return status;
+}
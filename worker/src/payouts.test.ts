@@ -0,0 +1,107 @@
+// Payout Report Tests
+// Tests for payouts.ts - completed-order reports over a period
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { SaleorFetch, initializeSaleorClient } from "./saleorClient";
+import { toSaleorStatusFilter } from "./saleorOrder";
+import { buildPayoutReports, payoutReportsToCsv } from "./payouts";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const API_URL = "https://saleor.test/graphql/";
+const PERIOD = ["2026-10-01T00:00:00.000Z", "2026-10-31T23:59:59.999Z"] as const;
+
+function orderNode(id: string, amount: number) {
+  return {
+    id,
+    number: "1",
+    status: "FULFILLED",
+    channel: { id: "ch-1" },
+    total: { gross: { amount, currency: "EUR" } },
+    lines: [],
+    metadata: [],
+    created: "2026-10-10T12:00:00.000Z",
+  };
+}
+
+function useSaleor(send: SaleorFetch) {
+  (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+  initializeSaleorClient({ SALEOR_API_URL: API_URL, SALEOR_TOKEN: "t" });
+}
+
+function ordersPage(nodes: unknown[], hasNextPage = false) {
+  return Response.json({
+    data: {
+      orders: {
+        edges: nodes.map((node) => ({ node })),
+        pageInfo: { hasNextPage, endCursor: hasNextPage ? "next" : null },
+      },
+    },
+  });
+}
+
+afterEach(() => {
+  initializeSaleorClient({});
+  delete (globalThis as any).SALEOR_TRANSPORT;
+});
+
+describe("toSaleorStatusFilter", () => {
+  it("should only send Saleor's own status filter values", () => {
+    expect(toSaleorStatusFilter(["FULFILLED", "DELIVERED"])).toEqual(["FULFILLED"]);
+    expect(toSaleorStatusFilter(["CREATED", "CANCELLED", "DRAFT"])).toEqual([
+      "UNCONFIRMED",
+      "CANCELED",
+    ]);
+  });
+});
+
+describe("buildPayoutReports", () => {
+  it("should report completed orders filtered in Saleor", async () => {
+    const send = vi.fn<SaleorFetch>(async () =>
+      ordersPage([orderNode("o1", 20), orderNode("o2", 10)]),
+    );
+    useSaleor(send);
+
+    const [report] = await buildPayoutReports("ch-1", ...PERIOD);
+    expect(report).toMatchObject({ currency: "EUR", orderCount: 2, gross: 30 });
+    const { variables } = JSON.parse(String(send.mock.calls[0][1]?.body));
+    expect(variables.filter.status).toEqual(["FULFILLED"]);
+  });
+
+  it("should fail when Saleor refuses the orders query", async () => {
+    const errors = [{ message: "Bad filter" }];
+    useSaleor(vi.fn<SaleorFetch>(async () => Response.json({ errors })));
+    await expect(buildPayoutReports("ch-1", ...PERIOD)).rejects.toMatchObject({
+      code: "INTERNAL_ERROR",
+    });
+  });
+
+  it("should refuse to report on a truncated order list", async () => {
+    useSaleor(vi.fn<SaleorFetch>(async () => ordersPage([orderNode("o1", 20)], true)));
+    await expect(buildPayoutReports("ch-1", ...PERIOD)).rejects.toMatchObject({
+      code: "BAD_USER_INPUT",
+    });
+  });
+});
+
+describe("payoutReportsToCsv", () => {
+  it("should write amounts with the report currency's minor units", () => {
+    const csv = payoutReportsToCsv([
+      {
+        restaurantId: "ch-1",
+        periodStart: PERIOD[0],
+        periodEnd: PERIOD[1],
+        currency: "JPY",
+        orderCount: 2,
+        gross: 3000,
+        commissionRate: 0.1,
+        commission: 300,
+        net: 2700,
+      },
+    ]);
+    expect(csv.split("\n")[1]).toBe(`ch-1,${PERIOD[0]},${PERIOD[1]},JPY,2,3000,0.1,300,2700`);
+  });
+});
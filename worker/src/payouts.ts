@@ -0,0 +1,182 @@
+// Restaurant Commission & Payout Reporting
+// Tracks a commission rate per restaurant and computes per-period payout
+// reports (gross, commission, net) from completed orders.
+
+import { CommissionRate, PayoutReport } from "./contracts";
+import { getNumberVar } from "./config";
+import { logger } from "./logger";
+import { badUserInputError } from "./errors";
+import { readJSON, writeJSON } from "./storage";
+import { formatAmount, sumMoney, multiplyMoney, subtractMoney } from "./money";
+import {
+  fetchOrderList,
+  COMPLETED_ORDER_STATUSES,
+  SaleorOrder,
+} from "./saleorOrder";
+
+function getKey(restaurantId: string): string {
+  return `commission:${restaurantId}`;
+}
+
+/**
+ * Commission applied when a restaurant has no explicit rate
+ * (DEFAULT_COMMISSION_RATE, fraction between 0 and 1)
+ */
+export function getDefaultCommissionRate(): number {
+  const rate = getNumberVar("DEFAULT_COMMISSION_RATE", 0);
+  return rate >= 0 && rate <= 1 ? rate : 0;
+}
+
+export async function getCommissionRate(
+  restaurantId: string,
+): Promise<CommissionRate> {
+  const stored = await readJSON<CommissionRate>(getKey(restaurantId));
+  if (stored) {
+    return stored;
+  }
+  return {
+    restaurantId,
+    rate: getDefaultCommissionRate(),
+    updatedAt: null,
+    updatedBy: null,
+  };
+}
+
+export async function setCommissionRate(
+  restaurantId: string,
+  rate: number,
+  updatedBy: string,
+): Promise<CommissionRate> {
+  const commission: CommissionRate = {
+    restaurantId,
+    rate,
+    updatedAt: new Date().toISOString(),
+    updatedBy,
+  };
+  await writeJSON(getKey(restaurantId), commission);
+  logger.info("commission_rate_updated", { restaurantId, rate, updatedBy });
+  return commission;
+}
+
+/**
+ * Validate a reporting period and expand date-only bounds to full days
+ */
+export function normalizePeriod(
+  from: string,
+  to: string,
+): { periodStart: string; periodEnd: string } {
+  const start = new Date(from);
+  const end = new Date(to);
+  if (isNaN(start.getTime())) {
+    throw badUserInputError("Invalid period start date", "from");
+  }
+  if (isNaN(end.getTime())) {
+    throw badUserInputError("Invalid period end date", "to");
+  }
+  // "2024-01-31" means the whole day
+  if (/^\d{4}-\d{2}-\d{2}$/.test(to.trim())) {
+    end.setUTCHours(23, 59, 59, 999);
+  }
+  if (start > end) {
+    throw badUserInputError("Period start must be before period end", "from");
+  }
+  return { periodStart: start.toISOString(), periodEnd: end.toISOString() };
+}
+
+/**
+ * Build payout reports for a restaurant over [periodStart, periodEnd]
+ * One report per currency found in the period's completed orders; throws
+ * rather than report on part of a period with more orders than can be listed
+ */
+export async function buildPayoutReports(
+  restaurantId: string,
+  periodStart: string,
+  periodEnd: string,
+): Promise<PayoutReport[]> {
+  const [commission, { orders, truncated }] = await Promise.all([
+    getCommissionRate(restaurantId),
+    fetchOrderList({
+      channelId: restaurantId,
+      createdFrom: periodStart,
+      createdTo: periodEnd,
+      statuses: COMPLETED_ORDER_STATUSES,
+    }),
+  ]);
+  if (truncated) {
+    logger.warn("payout_report_truncated", { restaurantId, periodStart, periodEnd });
+    throw badUserInputError("The period has too many orders; choose a shorter period", "from");
+  }
+
+  const byCurrency = new Map<string, SaleorOrder[]>();
+  for (const order of orders) {
+    const currency = order.total.gross.currency;
+    byCurrency.set(currency, [...(byCurrency.get(currency) || []), order]);
+  }
+
+  const reports: PayoutReport[] = [];
+  for (const [currency, currencyOrders] of byCurrency.entries()) {
+    const gross = sumMoney(
+      currencyOrders.map((order) => order.total.gross.amount),
+      currency,
+    );
+    const commissionAmount = multiplyMoney(gross, commission.rate, currency);
+    reports.push({
+      restaurantId,
+      periodStart,
+      periodEnd,
+      currency,
+      orderCount: currencyOrders.length,
+      gross,
+      commissionRate: commission.rate,
+      commission: commissionAmount,
+      net: subtractMoney(gross, commissionAmount, currency),
+    });
+  }
+
+  logger.info("payout_report_built", {
+    restaurantId,
+    periodStart,
+    periodEnd,
+    orderCount: orders.length,
+  });
+
+  return reports;
+}
+
+function csvField(value: string | number): string {
+  const str = String(value);
+  return /[",\n]/.test(str) ? `"${str.replace(/"/g, '""')}"` : str;
+}
+
+/**
+ * Render payout reports as CSV for marketplace accounting
+ */
+export function payoutReportsToCsv(reports: PayoutReport[]): string {
+  const header = [
+    "restaurantId",
+    "periodStart",
+    "periodEnd",
+    "currency",
+    "orderCount",
+    "gross",
+    "commissionRate",
+    "commission",
+    "net",
+  ];
+  const rows = reports.map((r) =>
+    [
+      r.restaurantId,
+      r.periodStart,
+      r.periodEnd,
+      r.currency,
+      r.orderCount,
+      formatAmount(r.gross, r.currency),
+      r.commissionRate,
+      formatAmount(r.commission, r.currency),
+      formatAmount(r.net, r.currency),
+    ]
+      .map(csvField)
+      .join(","),
+  );
+  return [header.join(","), ...rows].join("\n");
+}
@@ -0,0 +1,56 @@
+// Pickup Fulfillment
+// Resolves where a pickup order is collected from. Restaurants opt in to
+// pickup by setting tma_pickup_address on their channel metadata.
+
+import { Channel, DeliveryLocation, FulfillmentType } from "./contracts";
+import { badUserInputError } from "./errors";
+import { fetchChannelById } from "./saleorService";
+
+export const PICKUP_ADDRESS_METADATA_KEY = "tma_pickup_address";
+
+/**
+ * Normalize the requested fulfillment type (DELIVERY when omitted)
+ */
+export function normalizeFulfillmentType(value: unknown): FulfillmentType {
+  if (value === undefined || value === null || value === "") {
+    return "DELIVERY";
+  }
+  if (value === "DELIVERY" || value === "PICKUP") {
+    return value;
+  }
+  throw badUserInputError(
+    "fulfillmentType must be DELIVERY or PICKUP",
+    "fulfillmentType",
+  );
+}
+
+/**
+ * Pickup location from channel metadata, or null if pickup is not offered
+ */
+export function getPickupLocation(channel: Channel | null): DeliveryLocation | null {
+  const address = channel?.metadata?.[PICKUP_ADDRESS_METADATA_KEY]?.trim();
+  if (!channel || !address) {
+    return null;
+  }
+  return {
+    id: `pickup:${channel.id}`,
+    address,
+    country: channel.defaultCountry?.code,
+  };
+}
+
+/**
+ * Look up a restaurant's pickup location, failing if it doesn't offer pickup
+ */
+export async function requirePickupLocation(
+  restaurantId: string,
+): Promise<DeliveryLocation> {
+  const location = getPickupLocation(await fetchChannelById(restaurantId));
+  if (!location) {
+    throw badUserInputError(
+      "This restaurant does not offer pickup",
+      "fulfillmentType",
+    );
+  }
+  return location;
+}
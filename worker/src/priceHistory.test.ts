@@ -0,0 +1,85 @@
+// Price History Tests
+// Tests for priceHistory.ts - recording price changes and the snapshot job
+
+import { describe, it, expect, vi } from "vitest";
+import { Dish } from "./contracts";
+import { fetchChannels, fetchListedDishes } from "./saleorService";
+import {
+  getPriceHistory,
+  MAX_PRICE_SNAPSHOTS,
+  recordPriceSnapshot,
+  snapshotDishPrices,
+  snapshotRestaurantPrices,
+} from "./priceHistory";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+vi.mock("./saleorService", () => ({
+  fetchChannels: vi.fn(async () => []),
+  fetchListedDishes: vi.fn(async () => []),
+}));
+
+function dish(id: string, price: number): Dish {
+  return {
+    id,
+    name: id,
+    description: "",
+    descriptionHtml: "",
+    price,
+    currency: "USD",
+    categoryId: "cat1",
+    imageUrl: "",
+    available: true,
+    soldOut: false,
+    dietaryTags: [],
+    allergens: [],
+    images: [],
+  };
+}
+
+describe("snapshotRestaurantPrices", () => {
+  it("should record only price changes, newest first", async () => {
+    const listed = vi.mocked(fetchListedDishes);
+    listed.mockResolvedValueOnce([dish("dish1", 10), dish("dish2", 5)]);
+    expect(await snapshotRestaurantPrices("restA", new Date("2026-03-01T10:00:00Z"))).toBe(2);
+
+    listed.mockResolvedValueOnce([dish("dish1", 10), dish("dish2", 5)]);
+    expect(await snapshotRestaurantPrices("restA", new Date("2026-03-01T11:00:00Z"))).toBe(0);
+
+    listed.mockResolvedValueOnce([dish("dish1", 12), dish("dish2", 5)]);
+    expect(await snapshotRestaurantPrices("restA", new Date("2026-03-01T12:00:00Z"))).toBe(1);
+
+    const history = await getPriceHistory("restA", "dish1");
+    expect(history.map((s) => s.price)).toEqual([12, 10]);
+    expect(history[0].recordedAt).toBe("2026-03-01T12:00:00.000Z");
+    expect(await getPriceHistory("restA", "dish2")).toHaveLength(1);
+    expect(await getPriceHistory("restB", "dish1")).toEqual([]);
+  });
+});
+
+describe("recordPriceSnapshot", () => {
+  it("should keep a capped history", async () => {
+    for (let i = 0; i < MAX_PRICE_SNAPSHOTS + 5; i++) {
+      await recordPriceSnapshot({
+        dishId: "dish9",
+        restaurantId: "restC",
+        price: i,
+        currency: "USD",
+        taxIncluded: true,
+        recordedAt: new Date(i * 1000).toISOString(),
+      });
+    }
+    const history = await getPriceHistory("restC", "dish9");
+    expect(history).toHaveLength(MAX_PRICE_SNAPSHOTS);
+    expect(history[0].price).toBe(MAX_PRICE_SNAPSHOTS + 4);
+  });
+});
+
+describe("snapshotDishPrices", () => {
+  it("should skip without Saleor so mock menus aren't recorded", async () => {
+    expect(await snapshotDishPrices()).toBe(0);
+    expect(fetchChannels).not.toHaveBeenCalled();
+  });
+});
@@ -0,0 +1,123 @@
+// Dish Price History
+// A cron job snapshots every listed dish's menu price per active channel,
+// at most once per PRICE_SNAPSHOT_INTERVAL_MINUTES. Only changes are kept:
+// price-latest:<channelId> holds the last recorded price of each dish, and
+// a snapshot is appended to price-history:<channelId>:<dishId> when the
+// price or currency differs. Admins read it with priceHistory to check
+// "I was charged more than shown" complaints against what the menu said.
+
+import { getNumberVar } from "./config";
+import { PriceSnapshot } from "./contracts";
+import { logger } from "./logger";
+import { fetchChannels, fetchListedDishes, getSaleorFanoutLimit } from "./saleorService";
+import { isSaleorConfigured } from "./saleorClient";
+import { readJSON, writeJSON } from "./storage";
+import { resolveMenuPriceDisplay } from "./taxes";
+import { mapConcurrent } from "./utils";
+
+const LATEST_PREFIX = "price-latest:";
+const HISTORY_PREFIX = "price-history:";
+const LAST_RUN_KEY = "price-snapshot:last-run";
+
+// Snapshots kept per dish; older ones are dropped
+export const MAX_PRICE_SNAPSHOTS = 100;
+
+type LatestPrices = Record<string, { price: number; currency: string }>;
+
+/**
+ * Minutes between snapshot runs (PRICE_SNAPSHOT_INTERVAL_MINUTES, default 60)
+ */
+export function getSnapshotIntervalMinutes(): number {
+  const minutes = getNumberVar("PRICE_SNAPSHOT_INTERVAL_MINUTES", 60);
+  return minutes > 0 ? minutes : 60;
+}
+
+function historyKey(restaurantId: string, dishId: string): string {
+  return `${HISTORY_PREFIX}${restaurantId}:${dishId}`;
+}
+
+/**
+ * Append a snapshot to a dish's history, newest first, capped
+ */
+export async function recordPriceSnapshot(snapshot: PriceSnapshot): Promise<void> {
+  const key = historyKey(snapshot.restaurantId, snapshot.dishId);
+  const history = (await readJSON<PriceSnapshot[]>(key)) || [];
+  await writeJSON(key, [snapshot, ...history].slice(0, MAX_PRICE_SNAPSHOTS));
+}
+
+/**
+ * Recorded prices of a dish in a restaurant, newest first
+ */
+export async function getPriceHistory(
+  restaurantId: string,
+  dishId: string,
+): Promise<PriceSnapshot[]> {
+  return (await readJSON<PriceSnapshot[]>(historyKey(restaurantId, dishId))) || [];
+}
+
+/**
+ * Snapshot one restaurant's menu; returns the number of price changes recorded
+ */
+export async function snapshotRestaurantPrices(
+  restaurantId: string,
+  now: Date = new Date(),
+): Promise<number> {
+  const priceDisplay = await resolveMenuPriceDisplay(restaurantId);
+  const dishes = await fetchListedDishes(restaurantId, priceDisplay);
+  const latestKey = `${LATEST_PREFIX}${restaurantId}`;
+  const latest = (await readJSON<LatestPrices>(latestKey)) || {};
+  let changed = 0;
+
+  for (const dish of dishes) {
+    const previous = latest[dish.id];
+    if (previous && previous.price === dish.price && previous.currency === dish.currency) {
+      continue;
+    }
+    await recordPriceSnapshot({
+      dishId: dish.id,
+      restaurantId,
+      price: dish.price,
+      currency: dish.currency,
+      taxIncluded: priceDisplay === "GROSS",
+      recordedAt: now.toISOString(),
+    });
+    latest[dish.id] = { price: dish.price, currency: dish.currency };
+    changed++;
+  }
+
+  if (changed > 0) {
+    await writeJSON(latestKey, latest);
+  }
+  return changed;
+}
+
+/**
+ * Snapshot every active restaurant when the interval has passed
+ * Skipped without Saleor, so mock menus never enter the history
+ */
+export async function snapshotDishPrices(now: Date = new Date()): Promise<number> {
+  if (!isSaleorConfigured()) {
+    return 0;
+  }
+  const lastRun = await readJSON<{ ranAt: string }>(LAST_RUN_KEY);
+  const intervalMs = getSnapshotIntervalMinutes() * 60 * 1000;
+  if (lastRun && now.getTime() - Date.parse(lastRun.ranAt) < intervalMs) {
+    return 0;
+  }
+  await writeJSON(LAST_RUN_KEY, { ranAt: now.toISOString() });
+
+  const channels = (await fetchChannels()).filter((channel) => channel.isActive);
+  const counts = await mapConcurrent(channels, getSaleorFanoutLimit(), async (channel) => {
+    try {
+      return await snapshotRestaurantPrices(channel.id, now);
+    } catch (error) {
+      // One restaurant's failure shouldn't stop the rest
+      logger.error("price_snapshot_failed", {
+        restaurantId: channel.id,
+        error: error instanceof Error ? error.message : "Unknown error",
+      });
+      return 0;
+    }
+  });
+  return counts.reduce((sum, count) => sum + count, 0);
+}
@@ -0,0 +1,70 @@
+// Product Media Tests
+// Tests for productMedia.ts - dish image galleries
+
+import { describe, it, expect, afterEach } from "vitest";
+import { getGallerySize, toImages } from "./productMedia";
+
+afterEach(() => {
+  delete (globalThis as any).GALLERY_IMAGE_SIZE;
+});
+
+describe("toImages", () => {
+  it("should keep images in order and skip videos", () => {
+    const images = toImages([
+      {
+        id: "m1",
+        alt: "Margherita",
+        type: "IMAGE",
+        url: "https://cdn.example.com/m1-1024.webp",
+        thumbnailUrl: "https://cdn.example.com/m1-256.webp",
+        originalUrl: "https://cdn.example.com/m1.jpg",
+      },
+      {
+        id: "m2",
+        alt: null,
+        type: "VIDEO",
+        url: "https://youtu.be/x",
+        thumbnailUrl: "https://youtu.be/x",
+        originalUrl: "https://youtu.be/x",
+      },
+      {
+        id: "m3",
+        alt: null,
+        type: "IMAGE",
+        url: "https://cdn.example.com/m3.jpg",
+        thumbnailUrl: "",
+        originalUrl: "",
+      },
+    ]);
+
+    expect(images.map((image) => image.id)).toEqual(["m1", "m3"]);
+    expect(images[0]).toEqual({
+      id: "m1",
+      alt: "Margherita",
+      url: "https://cdn.example.com/m1-1024.webp",
+      thumbnailUrl: "https://cdn.example.com/m1-256.webp",
+      originalUrl: "https://cdn.example.com/m1.jpg",
+    });
+    // Missing sizes fall back to the gallery URL
+    expect(images[1]).toMatchObject({
+      alt: "",
+      thumbnailUrl: "https://cdn.example.com/m3.jpg",
+      originalUrl: "https://cdn.example.com/m3.jpg",
+    });
+  });
+
+  it("should handle products without media", () => {
+    expect(toImages(null)).toEqual([]);
+    expect(toImages(undefined)).toEqual([]);
+  });
+});
+
+describe("getGallerySize", () => {
+  it("should default to 1024 pixels", () => {
+    expect(getGallerySize()).toBe(1024);
+    (globalThis as any).GALLERY_IMAGE_SIZE = "2048";
+    expect(getGallerySize()).toBe(2048);
+    (globalThis as any).GALLERY_IMAGE_SIZE = "-1";
+    expect(getGallerySize()).toBe(1024);
+  });
+});
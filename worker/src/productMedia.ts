@@ -0,0 +1,46 @@
+// Product Media Gallery
+// Dish cards show a swipeable gallery of the product's Saleor media. Each
+// image comes in three sizes: the menu thumbnail (THUMBNAIL_SIZE), a gallery
+// size for the full-width card (GALLERY_IMAGE_SIZE) and the original upload.
+// Sized variants use the negotiated image format; videos are skipped.
+
+import { Image } from "./contracts";
+import { getNumberVar } from "./config";
+
+/**
+ * Saleor ProductMedia with the size aliases requested by the product queries
+ */
+export interface SaleorProductMedia {
+  id: string;
+  alt: string | null;
+  type: "IMAGE" | "VIDEO";
+  url: string;
+  thumbnailUrl: string;
+  originalUrl: string;
+}
+
+/**
+ * Gallery image edge length in pixels (GALLERY_IMAGE_SIZE, default 1024)
+ */
+export function getGallerySize(): number {
+  const size = getNumberVar("GALLERY_IMAGE_SIZE", 1024);
+  return size > 0 ? Math.floor(size) : 1024;
+}
+
+/**
+ * Gallery images of a product, in the order arranged in Saleor
+ */
+export function toImages(media: SaleorProductMedia[] | null | undefined): Image[] {
+  if (!Array.isArray(media)) {
+    return [];
+  }
+  return media
+    .filter((item) => item?.type === "IMAGE" && typeof item.url === "string")
+    .map((item) => ({
+      id: item.id,
+      alt: item.alt || "",
+      url: item.url,
+      thumbnailUrl: item.thumbnailUrl || item.url,
+      originalUrl: item.originalUrl || item.url,
+    }));
+}
@@ -0,0 +1,56 @@
+// Promo Code Tests
+// Tests for promoCodes.ts - voucher evaluation per channel and date
+
+import { describe, it, expect } from "vitest";
+import { evaluateVoucher, normalizePromoCode } from "./promoCodes";
+
+const voucher = {
+  id: "voucher-1",
+  code: "SPRING10",
+  type: "ENTIRE_ORDER" as const,
+  discountValueType: "PERCENTAGE" as const,
+  startDate: "2026-03-01T00:00:00Z",
+  endDate: "2026-06-01T00:00:00Z",
+  usageLimit: 100,
+  used: 3,
+  channelListings: [
+    {
+      channel: { id: "channel-1" },
+      discountValue: 10,
+      currency: "EUR",
+      minSpent: { amount: 20 },
+    },
+  ],
+};
+
+describe("evaluateVoucher", () => {
+  const now = new Date("2026-04-01T12:00:00Z");
+
+  it("should return the channel discount for a valid code", () => {
+    const result = evaluateVoucher("SPRING10", voucher, "channel-1", now);
+    expect(result.valid).toBe(true);
+    expect(result.discountType).toBe("PERCENTAGE");
+    expect(result.discountValue).toBe(10);
+    expect(result.minSpent).toBe(20);
+  });
+
+  it("should reject codes outside their dates or channel", () => {
+    expect(evaluateVoucher("SPRING10", voucher, "channel-1", new Date("2026-07-01")).reason).toBe(
+      "Promo code has expired",
+    );
+    expect(evaluateVoucher("SPRING10", voucher, "channel-2", now).reason).toBe(
+      "Promo code is not valid at this restaurant",
+    );
+  });
+
+  it("should reject fully redeemed codes", () => {
+    const used = { ...voucher, used: 100 };
+    expect(evaluateVoucher("SPRING10", used, "channel-1", now).valid).toBe(false);
+  });
+});
+
+describe("normalizePromoCode", () => {
+  it("should trim and upper-case codes", () => {
+    expect(normalizePromoCode(" spring10 ")).toBe("SPRING10");
+  });
+});
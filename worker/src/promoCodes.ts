@@ -0,0 +1,186 @@
+// Promo Codes
+// Customer-entered Saleor voucher codes: validatePromoCode previews the
+// discount for the UI, placeOrder re-checks the code and applies it with
+// draftOrderUpdate (draft pipeline) or checkoutAddPromoCode (checkout
+// pipeline). Saleor remains the authority on the final discount.
+
+import {
+  PromoCodeValidation,
+  PromoDiscountType,
+  PromoDiscountScope,
+} from "./contracts";
+import { badUserInputError, notFoundError } from "./errors";
+import { logger } from "./logger";
+import {
+  getSaleorClient,
+  isSaleorConfigured,
+  VOUCHER_BY_CODE_QUERY,
+  DRAFT_ORDER_VOUCHER_UPDATE_MUTATION,
+} from "./saleorClient";
+import {
+  DraftOrderVoucherUpdateMutation,
+  DraftOrderVoucherUpdateMutationVariables,
+  VoucherByCodeQuery,
+  VoucherByCodeQueryVariables,
+} from "./saleorOperations";
+import { SaleorOrder } from "./saleorOrder";
+import { fetchChannelById } from "./saleorService";
+
+interface SaleorVoucherNode {
+  id: string;
+  code: string | null;
+  type: PromoDiscountScope;
+  discountValueType: PromoDiscountType;
+  startDate: string | null;
+  endDate: string | null;
+  usageLimit: number | null;
+  used: number | null;
+  channelListings: Array<{
+    channel: { id: string };
+    discountValue: number;
+    currency: string;
+    minSpent: { amount: number } | null;
+  }> | null;
+}
+
+/**
+ * Promo codes are matched case-insensitively and stored upper-case
+ */
+export function normalizePromoCode(code: string): string {
+  return code.trim().toUpperCase();
+}
+
+function invalid(code: string, reason: string): PromoCodeValidation {
+  return {
+    code,
+    valid: false,
+    reason,
+    discountType: null,
+    appliesTo: null,
+    discountValue: null,
+    currency: null,
+    minSpent: null,
+  };
+}
+
+/**
+ * Check a voucher against a channel at a point in time
+ */
+export function evaluateVoucher(
+  code: string,
+  voucher: SaleorVoucherNode,
+  channelId: string,
+  now: Date = new Date(),
+): PromoCodeValidation {
+  if (voucher.startDate && new Date(voucher.startDate) > now) {
+    return invalid(code, "Promo code is not active yet");
+  }
+  if (voucher.endDate && new Date(voucher.endDate) < now) {
+    return invalid(code, "Promo code has expired");
+  }
+  if (voucher.usageLimit !== null && (voucher.used || 0) >= voucher.usageLimit) {
+    return invalid(code, "Promo code has been fully redeemed");
+  }
+  const listing = (voucher.channelListings || []).find(
+    (l) => l.channel.id === channelId,
+  );
+  if (!listing) {
+    return invalid(code, "Promo code is not valid at this restaurant");
+  }
+  return {
+    code,
+    valid: true,
+    reason: null,
+    discountType: voucher.discountValueType,
+    appliesTo: voucher.type,
+    discountValue: listing.discountValue,
+    currency: listing.currency,
+    minSpent: listing.minSpent?.amount ?? null,
+  };
+}
+
+/**
+ * Look up a promo code for a restaurant
+ */
+export async function validatePromoCode(
+  rawCode: string,
+  restaurantId: string,
+): Promise<PromoCodeValidation> {
+  const code = normalizePromoCode(rawCode);
+  const channel = await fetchChannelById(restaurantId);
+  if (!channel) {
+    throw notFoundError("Restaurant not found");
+  }
+
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    return invalid(code, "Promo codes are unavailable");
+  }
+
+  const variables: VoucherByCodeQueryVariables = { code, channel: channel.slug };
+  const response = await client.execute<VoucherByCodeQuery>(VOUCHER_BY_CODE_QUERY, variables);
+  if (response.errors && response.errors.length > 0) {
+    logger.error("saleor_voucher_lookup_error", {
+      error: response.errors.map((e) => e.message).join(", "),
+      restaurantId,
+    });
+    return invalid(code, "Promo codes are unavailable");
+  }
+
+  // search is a substring match; only the exact code counts
+  const voucher = (response.data?.vouchers?.edges || [])
+    .map((edge) => edge.node)
+    .find((node) => node.code && normalizePromoCode(node.code) === code);
+  if (!voucher) {
+    return invalid(code, "Promo code not found");
+  }
+  return evaluateVoucher(code, voucher, channel.id);
+}
+
+/**
+ * Validate a promo code for placeOrder, throwing on invalid codes
+ */
+export async function requireValidPromoCode(
+  code: string,
+  restaurantId: string,
+): Promise<string> {
+  const result = await validatePromoCode(code, restaurantId);
+  if (!result.valid) {
+    throw badUserInputError(result.reason || "Invalid promo code", "voucherCode");
+  }
+  return result.code;
+}
+
+/**
+ * Apply a voucher code to a created order, updating its total
+ */
+export async function applyVoucherToOrder(
+  order: SaleorOrder,
+  voucherCode: string,
+): Promise<boolean> {
+  const client = getSaleorClient();
+  if (!client) {
+    return false;
+  }
+  const variables: DraftOrderVoucherUpdateMutationVariables = { id: order.id, voucherCode };
+  const response = await client.execute<DraftOrderVoucherUpdateMutation>(
+    DRAFT_ORDER_VOUCHER_UPDATE_MUTATION,
+    variables,
+  );
+
+  const errors = [
+    ...(response.errors || []).map((e) => e.message),
+    ...(response.data?.draftOrderUpdate?.errors || []).map((e) => e.message),
+  ];
+  const updated = response.data?.draftOrderUpdate?.order;
+  if (errors.length > 0 || !updated) {
+    logger.error("order_voucher_apply_failed", {
+      orderId: order.id,
+      error: errors.join(", ") || "No order returned",
+    });
+    return false;
+  }
+  order.total = { gross: updated.total.gross };
+  order.totalTax = updated.total.tax.amount;
+  return true;
+}
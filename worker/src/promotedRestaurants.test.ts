@@ -0,0 +1,49 @@
+// Promoted Restaurant Tests
+// Tests for promotedRestaurants.ts - promotion expiry and placement
+
+import { describe, it, expect } from "vitest";
+import { Restaurant } from "./contracts";
+import { isPromotedNow, placePromotedFirst } from "./promotedRestaurants";
+
+function restaurant(id: string, name: string, isPromoted = false): Restaurant {
+  return { id, name, categories: [], isPromoted };
+}
+
+describe("isPromotedNow", () => {
+  const now = new Date("2026-06-01T12:00:00Z");
+
+  it("should be promoted until tma_promoted_until passes", () => {
+    const metadata = { tma_promoted_until: "2026-06-30T00:00:00Z" };
+    expect(isPromotedNow(metadata, now)).toBe(true);
+    expect(isPromotedNow(metadata, new Date("2026-07-01T00:00:00Z"))).toBe(false);
+  });
+
+  it("should ignore missing or invalid dates", () => {
+    expect(isPromotedNow({ tma_promoted_until: "soon" }, now)).toBe(false);
+    expect(isPromotedNow(undefined, now)).toBe(false);
+  });
+});
+
+describe("placePromotedFirst", () => {
+  it("should lead with promoted restaurants in a stable order", () => {
+    const restaurants = [
+      restaurant("c1", "Curry House"),
+      restaurant("p2", "Taco Truck", true),
+      restaurant("a1", "Arepa Stand"),
+      restaurant("p1", "Taco Truck", true),
+      restaurant("p3", "Noodle Bar", true),
+    ];
+    const placed = placePromotedFirst(restaurants).map((r) => r.id);
+    expect(placed).toEqual(["p3", "p1", "p2", "c1", "a1"]);
+    expect(placePromotedFirst([...restaurants].reverse()).map((r) => r.id).slice(0, 3)).toEqual([
+      "p3",
+      "p1",
+      "p2",
+    ]);
+  });
+
+  it("should keep the list as is without promotions", () => {
+    const restaurants = [restaurant("b", "B"), restaurant("a", "A")];
+    expect(placePromotedFirst(restaurants)).toBe(restaurants);
+  });
+});
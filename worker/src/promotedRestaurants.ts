@@ -0,0 +1,34 @@
+// Promoted Restaurants
+// Sponsored placement: a restaurant whose tma_promoted_until channel
+// metadata is in the future is promoted. The restaurant lists put promoted
+// restaurants first, flagged isPromoted for a "Sponsored" badge, ordered by
+// name then ID so the feed doesn't reshuffle between refreshes; the rest
+// keep their order. Search ranks by relevance and ignores promotion.
+
+import { Restaurant } from "./contracts";
+
+export const PROMOTED_UNTIL_METADATA_KEY = "tma_promoted_until";
+
+/**
+ * Whether tma_promoted_until is a date-time still in the future
+ */
+export function isPromotedNow(
+  metadata: Record<string, string> | undefined,
+  now: Date = new Date(),
+): boolean {
+  const until = Date.parse(metadata?.[PROMOTED_UNTIL_METADATA_KEY]?.trim() || "");
+  return Number.isFinite(until) && until > now.getTime();
+}
+
+/**
+ * Promoted restaurants first (by name, then ID), the others in their order
+ */
+export function placePromotedFirst(restaurants: Restaurant[]): Restaurant[] {
+  const promoted = restaurants
+    .filter((restaurant) => restaurant.isPromoted)
+    .sort((a, b) => a.name.localeCompare(b.name) || a.id.localeCompare(b.id));
+  if (promoted.length === 0) {
+    return restaurants;
+  }
+  return [...promoted, ...restaurants.filter((restaurant) => !restaurant.isPromoted)];
+}
@@ -0,0 +1,69 @@
+// Query Cost Tests
+// Tests for queryCost.ts - page size preflight and splitting costly pages
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import {
+  createCostBudget,
+  executePageWithinCost,
+  getCostSafePageSize,
+  isQueryCostError,
+} from "./queryCost";
+import { SaleorClient } from "./saleorClient";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+afterEach(() => {
+  delete (globalThis as any).SALEOR_MAX_QUERY_COST;
+});
+
+describe("getCostSafePageSize", () => {
+  it("should keep full pages under the default limit", () => {
+    expect(getCostSafePageSize(30)).toBe(100);
+  });
+
+  it("should shrink pages to fit a lower limit", () => {
+    (globalThis as any).SALEOR_MAX_QUERY_COST = "1000";
+    expect(getCostSafePageSize(30)).toBe(26);
+    expect(getCostSafePageSize(5000)).toBe(1);
+  });
+});
+
+describe("isQueryCostError", () => {
+  it("should recognise Saleor's cost limit errors only", () => {
+    expect(
+      isQueryCostError({
+        errors: [{ message: "The query exceeds the maximum cost of 50000. Actual cost is 61200" }],
+      }),
+    ).toBe(true);
+    const coded = {
+      message: "Query too costly",
+      extensions: { exception: { code: "QueryCostError" } },
+    };
+    expect(isQueryCostError({ errors: [coded] })).toBe(true);
+    expect(isQueryCostError({ errors: [{ message: "Network error" }] })).toBe(false);
+    expect(isQueryCostError({ data: {} })).toBe(false);
+  });
+});
+
+describe("executePageWithinCost", () => {
+  it("should halve the page until Saleor accepts it and keep the smaller size", async () => {
+    const execute = vi.fn(async (_query: string, variables: Record<string, any>) =>
+      variables.first > 30
+        ? { errors: [{ message: "The query exceeds the maximum cost of 50000" }] }
+        : { data: { first: variables.first } },
+    );
+    const client = { execute } as unknown as SaleorClient;
+    const budget = createCostBudget(30);
+
+    const response = await executePageWithinCost(client, "query", { after: "c1" }, budget);
+    expect(response.data).toEqual({ first: 25 });
+    expect(execute.mock.calls.map(([, variables]) => variables)).toEqual([
+      { after: "c1", first: 100 },
+      { after: "c1", first: 50 },
+      { after: "c1", first: 25 },
+    ]);
+    expect(budget.pageSize).toBe(25);
+  });
+});
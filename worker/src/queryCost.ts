@@ -0,0 +1,101 @@
+// Saleor Query Cost
+// Saleor rejects queries whose cost (each field weighted, connections
+// multiplied by their page size) exceeds GRAPHQL_QUERY_MAX_COMPLEXITY. Whole
+// catalog and order reads page through Saleor, so before the first page the
+// page size is estimated down to fit SALEOR_MAX_QUERY_COST (set it to match
+// the instance), and if Saleor still refuses a page as too costly it is
+// split in half and retried from the same cursor instead of failing midway.
+// Node costs are rough upper bounds per query, not Saleor's exact figures.
+
+import { getNumberVar } from "./config";
+import { logger } from "./logger";
+import { MAX_PAGE_SIZE } from "./pagination";
+import { SaleorClient, SaleorResponse } from "./saleorClient";
+
+// Saleor's default GRAPHQL_QUERY_MAX_COMPLEXITY
+export const DEFAULT_MAX_QUERY_COST = 50000;
+// Share of the limit a page may use, since estimates are approximate
+const COST_HEADROOM = 0.8;
+
+// Estimated cost of one node, including its nested lists
+export const QUERY_NODE_COSTS = {
+  // Variants with pricing and listings, media, thumbnail, metadata
+  product: 30,
+  // Lines, payments, fulfillments, addresses, metadata
+  order: 25,
+  // Listings and product type (catalog health)
+  catalogProduct: 5,
+  // Metadata (metadata migration)
+  productMetadata: 3,
+};
+
+/**
+ * Page size for the next request; lowered when Saleor refuses a page
+ */
+export interface CostBudget {
+  pageSize: number;
+  nodeCost: number;
+}
+
+export function getMaxQueryCost(): number {
+  const limit = getNumberVar("SALEOR_MAX_QUERY_COST", DEFAULT_MAX_QUERY_COST);
+  return limit > 0 ? limit : DEFAULT_MAX_QUERY_COST;
+}
+
+/**
+ * Estimated cost of a page of nodes
+ */
+export function estimateQueryCost(pageSize: number, nodeCost: number): number {
+  // The connection itself and its pageInfo cost a little on top
+  return pageSize * nodeCost + 2;
+}
+
+/**
+ * Largest page size up to requested whose estimated cost fits the limit
+ */
+export function getCostSafePageSize(nodeCost: number, requested: number = MAX_PAGE_SIZE): number {
+  const budget = getMaxQueryCost() * COST_HEADROOM;
+  const fitting = Math.floor((budget - estimateQueryCost(0, nodeCost)) / Math.max(1, nodeCost));
+  return Math.max(1, Math.min(requested, fitting));
+}
+
+export function createCostBudget(nodeCost: number, requested: number = MAX_PAGE_SIZE): CostBudget {
+  return { pageSize: getCostSafePageSize(nodeCost, requested), nodeCost };
+}
+
+/**
+ * Whether Saleor refused a query for exceeding its cost limit
+ */
+export function isQueryCostError(response: SaleorResponse<unknown>): boolean {
+  return (response.errors || []).some(
+    (error) =>
+      error.extensions?.exception?.code === "QueryCostError" ||
+      /maximum (query )?cost|query cost/i.test(error.message),
+  );
+}
+
+/**
+ * Run one page with $first set from the budget, halving the page and
+ * retrying while Saleor reports the query as too costly
+ */
+export async function executePageWithinCost<T>(
+  client: SaleorClient,
+  query: string,
+  variables: Record<string, unknown>,
+  budget: CostBudget,
+): Promise<SaleorResponse<T>> {
+  for (;;) {
+    const response = await client.execute<T>(query, { ...variables, first: budget.pageSize });
+    if (!isQueryCostError(response) || budget.pageSize <= 1) {
+      return response;
+    }
+    const pageSize = Math.max(1, Math.floor(budget.pageSize / 2));
+    logger.warn("saleor_query_cost_split", {
+      from: budget.pageSize,
+      to: pageSize,
+      estimatedCost: estimateQueryCost(budget.pageSize, budget.nodeCost),
+      maxCost: getMaxQueryCost(),
+    });
+    budget.pageSize = pageSize;
+  }
+}
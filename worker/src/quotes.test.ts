@@ -0,0 +1,98 @@
+// Order Quote Tests
+// Tests for quotes.ts - pricing orders the way placeOrder creates them
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { PlaceOrderInput } from "./contracts";
+import { SaleorFetch, initializeSaleorClient } from "./saleorClient";
+import { clearOrders, createSaleorOrder } from "./saleorOrder";
+import { updateChannelMetadata } from "./saleorService";
+import { getOrderTotals, getServiceFee } from "./orderTotals";
+import { validateTipAmount } from "./tips";
+import { quoteOrder } from "./quotes";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const INPUT: PlaceOrderInput = {
+  restaurantId: "channelA",
+  deliveryLocation: { address: "1 Test Street" },
+  items: [
+    { dishId: "dishA1", quantity: 2 },
+    { dishId: "dishA2", quantity: 1 },
+  ],
+  tipAmount: 2.5,
+};
+
+afterEach(() => {
+  clearOrders();
+  initializeSaleorClient({});
+  delete (globalThis as any).SALEOR_TRANSPORT;
+  delete (globalThis as any).TIP_VARIANT_ID;
+  delete (globalThis as any).SERVICE_FEE_VARIANT_ID;
+});
+
+describe("quoteOrder", () => {
+  it("should quote the total of the order placeOrder creates", async () => {
+    (globalThis as any).TIP_VARIANT_ID = "tip-variant";
+    (globalThis as any).SERVICE_FEE_VARIANT_ID = "fee-variant";
+    await updateChannelMetadata("channelA", { tma_service_fee: "1.25" });
+
+    const quote = await quoteOrder(INPUT);
+    const placed = await createSaleorOrder(
+      {
+        ...INPUT,
+        tipAmount: validateTipAmount(INPUT.tipAmount, "USD"),
+        serviceFee: getServiceFee({ tma_service_fee: "1.25" }, "USD"),
+      },
+      "user-1",
+    );
+    const totals = getOrderTotals(placed.order!);
+
+    expect(quote).toMatchObject({ tipAmount: 2.5, serviceFee: 1.25, deliveryFee: 0 });
+    expect(quote.subtotal).toBe(totals.subtotal);
+    expect(quote.total).toBe(placed.order!.total.gross.amount);
+  });
+
+  it("should price lines in the channel with promotions and tax", async () => {
+    const variant = (id: string, price: number, undiscounted: number, tax: number) => ({
+      id,
+      name: "Default",
+      product: { name: `Dish ${id}` },
+      pricing: {
+        onSale: price < undiscounted,
+        price: { gross: { amount: price, currency: "USD" }, tax: { amount: tax } },
+        priceUndiscounted: { gross: { amount: undiscounted, currency: "USD" } },
+      },
+    });
+    const send = vi.fn<SaleorFetch>(async (_, init) => {
+      const { operationName } = JSON.parse(String(init?.body));
+      if (operationName !== "VariantPricing") {
+        return Response.json({ errors: [{ message: "Unavailable" }] });
+      }
+      return Response.json({
+        data: {
+          productVariants: {
+            edges: [
+              { node: variant("dishA1", 8, 10, 0.8) },
+              { node: variant("dishA2", 5, 5, 0.5) },
+            ],
+          },
+        },
+      });
+    });
+    (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+    initializeSaleorClient({ SALEOR_API_URL: "https://saleor.test/graphql/", SALEOR_TOKEN: "t" });
+
+    const quote = await quoteOrder({ ...INPUT, tipAmount: undefined });
+    expect(quote).toMatchObject({ subtotal: 21, discount: 4, tax: 2.1, total: 21 });
+    expect(quote.lines[0]).toMatchObject({ dishId: "dishA1", discount: 4, lineTotal: 16 });
+  });
+
+  it("should refuse unknown restaurants", async () => {
+    await expect(
+      quoteOrder({ ...INPUT, tipAmount: undefined, restaurantId: "missing" }),
+    ).rejects.toMatchObject({ code: "NOT_FOUND" });
+  });
+});
@@ -0,0 +1,150 @@
+// Order Quotes
+// Prices an order without creating anything in Saleor, from the same line
+// builders placeOrder uses: dish lines are priced by one batch variant
+// pricing query in the restaurant's channel (promotion discounts and tax
+// included), tip and service fee only when placeOrder would charge them as
+// lines. The draft pipeline charges no delivery fee; the checkout pipeline
+// prices shipping in Saleor when the order is placed, so it isn't quoted.
+// Falls back to mock order prices when Saleor is not configured.
+
+import { OrderQuote, OrderQuoteLine, PlaceOrderInput } from "./contracts";
+import { badUserInputError, notFoundError } from "./errors";
+import { logger } from "./logger";
+import { multiplyMoney, roundForCash, roundMoney, subtractMoney, sumMoney } from "./money";
+import {
+  getSaleorClient,
+  isSaleorConfigured,
+  VARIANT_PRICING_QUERY,
+} from "./saleorClient";
+import { VariantPricingQuery, VariantPricingQueryVariables } from "./saleorOperations";
+import { fetchChannelById } from "./saleorService";
+import { buildOrderLines, getMockDishPricing } from "./saleorOrder";
+import { buildServiceFeeLine, getServiceFee } from "./orderTotals";
+import { buildTipLine, validateTipAmount } from "./tips";
+
+// Channel metadata keys for the delivery pricing shown on restaurant details
+export const DELIVERY_FEE_METADATA_KEY = "tma_delivery_fee";
+export const FREE_DELIVERY_THRESHOLD_METADATA_KEY = "tma_free_delivery_threshold";
+
+interface VariantPrice {
+  name: string;
+  unitPrice: number;
+  undiscountedUnitPrice: number;
+  unitTax: number; // included in unitPrice
+}
+
+async function fetchVariantPrices(
+  variantIds: string[],
+  channelSlug: string,
+): Promise<Map<string, VariantPrice>> {
+  const prices = new Map<string, VariantPrice>();
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+
+  if (!client) {
+    // Priced like the mock order placeOrder creates
+    for (const variantId of variantIds) {
+      const pricing = getMockDishPricing(variantId);
+      prices.set(variantId, {
+        name: pricing.name,
+        unitPrice: pricing.price,
+        undiscountedUnitPrice: pricing.price,
+        unitTax: 0,
+      });
+    }
+    return prices;
+  }
+
+  const variables: VariantPricingQueryVariables = { ids: variantIds, channel: channelSlug };
+  const response = await client.execute<VariantPricingQuery>(VARIANT_PRICING_QUERY, variables);
+
+  if (response.errors && response.errors.length > 0) {
+    logger.error("saleor_variant_pricing_error", {
+      error: response.errors.map((e) => e.message).join(", "),
+      channel: channelSlug,
+    });
+    throw badUserInputError("Prices are unavailable, please try again");
+  }
+
+  for (const edge of response.data?.productVariants?.edges || []) {
+    const node = edge.node;
+    const price = node.pricing?.price?.gross?.amount;
+    if (price === undefined || price === null) {
+      continue; // not available in this channel
+    }
+    prices.set(node.id, {
+      name: node.product?.name || node.name,
+      unitPrice: Number(price),
+      undiscountedUnitPrice: Number(
+        node.pricing?.priceUndiscounted?.gross?.amount ?? price,
+      ),
+      unitTax: Number(node.pricing?.price?.tax?.amount ?? 0),
+    });
+  }
+  return prices;
+}
+
+/**
+ * Price an order the way placeOrder would create it
+ */
+export async function quoteOrder(input: PlaceOrderInput): Promise<OrderQuote> {
+  const channel = await fetchChannelById(input.channelId || input.restaurantId);
+  if (!channel) {
+    throw notFoundError("Restaurant not found");
+  }
+  const currency = channel.currencyCode;
+
+  const orderLines = buildOrderLines(input.items);
+  const prices = await fetchVariantPrices(
+    Array.from(new Set(orderLines.map((line) => line.variantId))),
+    channel.slug,
+  );
+
+  const taxes: number[] = [];
+  const lines: OrderQuoteLine[] = orderLines.map((line) => {
+    const price = prices.get(line.variantId);
+    if (!price) {
+      throw badUserInputError(`Dish ${line.variantId} is not available`, "items");
+    }
+    const lineTotal = multiplyMoney(price.unitPrice, line.quantity, currency);
+    const undiscountedTotal = multiplyMoney(
+      price.undiscountedUnitPrice,
+      line.quantity,
+      currency,
+    );
+    taxes.push(multiplyMoney(price.unitTax, line.quantity, currency));
+    return {
+      dishId: line.variantId,
+      name: price.name,
+      quantity: line.quantity,
+      unitPrice: price.unitPrice,
+      undiscountedUnitPrice: price.undiscountedUnitPrice,
+      discount: Math.max(0, subtractMoney(undiscountedTotal, lineTotal, currency)),
+      lineTotal,
+    };
+  });
+
+  // Tip and service fee count only when they become order lines
+  const tipLine = buildTipLine(validateTipAmount(input.tipAmount, currency));
+  const serviceFeeLine = buildServiceFeeLine(getServiceFee(channel.metadata, currency));
+
+  const subtotal = sumMoney(lines.map((line) => line.lineTotal), currency);
+  const discount = sumMoney(lines.map((line) => line.discount), currency);
+  const deliveryFee = 0;
+  const serviceFee = roundMoney(serviceFeeLine?.price ?? 0, currency);
+  const tipAmount = roundMoney(tipLine?.price ?? 0, currency);
+  const total = sumMoney([subtotal, deliveryFee, serviceFee, tipAmount], currency);
+
+  return {
+    restaurantId: channel.id,
+    currency,
+    lines,
+    subtotal,
+    discount,
+    deliveryFee,
+    serviceFee,
+    tipAmount,
+    tax: sumMoney(taxes, currency),
+    // Cash orders are collected in the currency's cash increment
+    total: input.paymentMethod === "CASH" ? roundForCash(total, currency) : total,
+  };
+}
@@ -0,0 +1,163 @@
+// Order Receipts
+// Serves GET /orders/{id}/receipt as a self-contained HTML page that can be
+// opened inside the Telegram WebView (and printed to PDF from there).
+// Only the order's owner can view it, and only once the order is completed.
+
+import { badUserInputError, notFoundError } from "./errors";
+import { GraphQLContext, OrderDetails, OrderPaymentMethod } from "./contracts";
+import { logger } from "./logger";
+import { formatMoney, localeFromLanguageCode } from "./currencyFormat";
+import { multiplyMoney } from "./money";
+import {
+  COMPLETED_ORDER_STATUSES,
+  OrderStatus,
+  fetchUserOrder,
+  toOrderDetails,
+} from "./saleorOrder";
+import { fetchChannelById } from "./saleorService";
+
+const RECEIPT_PATH = /^\/orders\/([^/]+)\/receipt\/?$/;
+
+const PAYMENT_METHOD_LABELS: Record<OrderPaymentMethod, string> = {
+  CASH: "Cash",
+  CARD_ON_DELIVERY: "Card on delivery",
+  ONLINE: "Online",
+};
+
+/**
+ * Extract the order ID from a receipt URL path, or null if it doesn't match
+ */
+export function matchReceiptPath(pathname: string): string | null {
+  const match = pathname.match(RECEIPT_PATH);
+  if (!match) {
+    return null;
+  }
+  try {
+    return decodeURIComponent(match[1]);
+  } catch {
+    return null;
+  }
+}
+
+function escapeHtml(value: string): string {
+  return value
+    .replace(/&/g, "&amp;")
+    .replace(/</g, "&lt;")
+    .replace(/>/g, "&gt;")
+    .replace(/"/g, "&quot;")
+    .replace(/'/g, "&#39;");
+}
+
+function formatDate(iso: string, locale: string): string {
+  const date = new Date(iso);
+  if (isNaN(date.getTime())) {
+    return iso;
+  }
+  try {
+    return `${date.toLocaleString(locale, { timeZone: "UTC" })} UTC`;
+  } catch {
+    return date.toISOString();
+  }
+}
+
+/**
+ * Render a receipt as a standalone HTML document
+ */
+export function renderReceiptHtml(
+  order: OrderDetails,
+  restaurantName: string,
+  locale: string = "en",
+): string {
+  const money = (amount: number) =>
+    escapeHtml(formatMoney(amount, order.currency, locale));
+  const title = order.number ? `Order #${order.number}` : "Order receipt";
+  const date = formatDate(order.createdAt, locale);
+
+  const rows = order.lines
+    .map((line) => {
+      const lineTotal =
+        line.unitPrice !== undefined
+          ? money(multiplyMoney(line.unitPrice, line.quantity, order.currency))
+          : "";
+      return `<tr><td>${escapeHtml(line.name)}</td><td class="num">${line.quantity}</td><td class="num">${lineTotal}</td></tr>`;
+    })
+    .join("");
+
+  const address = [
+    order.deliveryLocation.address,
+    order.deliveryLocation.city,
+    order.deliveryLocation.country,
+  ]
+    .filter(Boolean)
+    .map((part) => escapeHtml(String(part)))
+    .join(", ");
+
+  return `<!DOCTYPE html>
+<html lang="${escapeHtml(locale)}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>${escapeHtml(title)}</title>
+<style>
+body{font-family:-apple-system,system-ui,sans-serif;margin:0;padding:16px;color:var(--tg-theme-text-color,#111);background:var(--tg-theme-bg-color,#fff)}
+h1{font-size:20px;margin:0 0 4px}
+.muted{color:var(--tg-theme-hint-color,#777);font-size:14px}
+table{width:100%;border-collapse:collapse;margin:16px 0}
+td,th{padding:6px 0;border-bottom:1px solid #ddd;text-align:left}
+.num{text-align:right}
+.total td{font-weight:bold;border-bottom:none}
+@media print{body{padding:0}}
+</style>
+</head>
+<body>
+<h1>${escapeHtml(restaurantName)}</h1>
+<div class="muted">${escapeHtml(title)} &middot; ${escapeHtml(date)}</div>
+<table>
+<thead><tr><th>Item</th><th class="num">Qty</th><th class="num">Amount</th></tr></thead>
+<tbody>${rows}</tbody>
+<tfoot><tr class="total"><td>Total</td><td></td><td class="num">${money(order.total)}</td></tr></tfoot>
+</table>
+<div class="muted">Payment: ${escapeHtml(PAYMENT_METHOD_LABELS[order.paymentMethod])}</div>
+${address ? `<div class="muted">Delivered to: ${address}</div>` : ""}
+</body>
+</html>`;
+}
+
+/**
+ * Build the receipt response for the authenticated user
+ * Throws AppError (NOT_FOUND / BAD_USER_INPUT) for the caller to format
+ */
+export async function handleReceiptRequest(
+  orderId: string,
+  context: GraphQLContext,
+): Promise<Response> {
+  const order = await fetchUserOrder(orderId, context.auth.userId);
+  if (!order) {
+    throw notFoundError("Order not found");
+  }
+  if (!COMPLETED_ORDER_STATUSES.includes(order.status as OrderStatus)) {
+    throw badUserInputError(
+      "Receipts are available once the order is completed",
+      "orderId",
+    );
+  }
+
+  const channel = order.channelId
+    ? await fetchChannelById(order.channelId)
+    : null;
+  const html = renderReceiptHtml(
+    toOrderDetails(order),
+    channel?.name || "Receipt",
+    localeFromLanguageCode(context.auth.language),
+  );
+
+  logger.info("receipt_rendered", { orderId, userId: context.auth.userId });
+
+  return new Response(html, {
+    status: 200,
+    headers: {
+      "Content-Type": "text/html; charset=utf-8",
+      "Cache-Control": "private, no-store",
+    },
+  });
+}
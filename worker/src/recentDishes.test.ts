@@ -0,0 +1,99 @@
+// Recent Dishes Tests
+// Tests for recentDishes.ts - ranking a user's order history for reordering
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { PlaceOrderInput } from "./contracts";
+import { fetchRecentDishes, invalidateRecentDishes, rankRecentDishes } from "./recentDishes";
+import { SaleorOrder, clearOrders, createSaleorOrder } from "./saleorOrder";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+const now = new Date("2026-03-31T12:00:00.000Z");
+
+function order(
+  createdAt: string,
+  lines: Array<[string, number]>,
+  status: SaleorOrder["status"] = "FULFILLED",
+): SaleorOrder {
+  return {
+    id: `order-${createdAt}`,
+    status,
+    channelId: "restA",
+    total: { gross: { amount: 10, currency: "EUR" } },
+    deliveryAddress: { address: "1 Test Street" },
+    lines: lines.map(([variantId, quantity]) => ({ variantId, quantity, productName: variantId })),
+    createdAt,
+  } as SaleorOrder;
+}
+
+afterEach(() => {
+  delete (globalThis as any).TIP_VARIANT_ID;
+});
+
+describe("rankRecentDishes", () => {
+  it("should favour dishes ordered often, weighted by recency", () => {
+    const ranks = rankRecentDishes(
+      [
+        order("2026-03-30T12:00:00.000Z", [["pizza", 1]]),
+        order("2026-03-01T12:00:00.000Z", [["soup", 1]]),
+        order("2026-02-20T12:00:00.000Z", [["soup", 2], ["soup", 1]]),
+        order("2026-02-10T12:00:00.000Z", [["soup", 1]]),
+      ],
+      now,
+    );
+    expect(ranks.map((r) => r.dishId)).toEqual(["soup", "pizza"]);
+    expect(ranks[0]).toMatchObject({
+      orderCount: 3,
+      quantity: 5,
+      lastOrderedAt: "2026-03-01T12:00:00.000Z",
+    });
+  });
+
+  it("should skip cancelled orders and tip lines", () => {
+    (globalThis as any).TIP_VARIANT_ID = "tip";
+    const ranks = rankRecentDishes(
+      [
+        order("2026-03-30T12:00:00.000Z", [["pizza", 1], ["tip", 1]]),
+        order("2026-03-30T13:00:00.000Z", [["soup", 1]], "CANCELLED"),
+      ],
+      now,
+    );
+    expect(ranks.map((r) => r.dishId)).toEqual(["pizza"]);
+  });
+});
+
+describe("fetchRecentDishes", () => {
+  const orderInput: PlaceOrderInput = {
+    restaurantId: "channelA",
+    deliveryLocation: { address: "1 Test Street" },
+    items: [
+      { dishId: "dishA1", quantity: 2 },
+      { dishId: "retired-dish", quantity: 1 },
+    ],
+  };
+
+  beforeEach(async () => {
+    clearOrders();
+    await invalidateRecentDishes("recent-user");
+  });
+
+  it("should price dishes from the current menu and leave out unlisted ones", async () => {
+    await createSaleorOrder(orderInput, "recent-user");
+    const recent = await fetchRecentDishes("recent-user");
+    expect(recent.map((r) => [r.dish.id, r.restaurantId, r.quantity])).toEqual([
+      ["dishA1", "channelA", 2],
+    ]);
+    expect(recent[0].dish.price).toBe(9.5);
+  });
+
+  it("should serve the cached ranking until invalidated", async () => {
+    expect(await fetchRecentDishes("recent-user")).toEqual([]);
+    await createSaleorOrder(orderInput, "recent-user");
+    expect(await fetchRecentDishes("recent-user")).toEqual([]);
+
+    await invalidateRecentDishes("recent-user");
+    expect(await fetchRecentDishes("recent-user")).toHaveLength(1);
+  });
+});
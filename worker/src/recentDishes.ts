@@ -0,0 +1,154 @@
+// Recently Ordered Dishes
+// One-tap reordering: the dishes (variants) a user orders most, from their
+// order history. Each order a dish was in counts for it, halving in weight
+// every RECENCY_HALF_LIFE_DAYS, so a weekly staple outranks a one-off
+// while last night's order still shows near the top. Cancelled and expired
+// orders, tips and service fees don't count. The ranking is cached per
+// user (recent-dishes:<userId>) and dropped when they place an order;
+// dishes are priced fresh from their restaurant's menu and left out once
+// no longer listed.
+
+import { RecentDish } from "./contracts";
+import { getVar } from "./config";
+import { logger } from "./logger";
+import { SaleorOrder, fetchUserOrders, getNormalizedStatus } from "./saleorOrder";
+import { fetchDishes } from "./saleorService";
+import { deleteKey, readJSON, writeJSON } from "./storage";
+import { resolveMenuPriceDisplay } from "./taxes";
+
+export const DEFAULT_RECENT_DISHES = 10;
+export const MAX_RECENT_DISHES = 50;
+
+const CACHE_PREFIX = "recent-dishes:";
+const CACHE_TTL_SECONDS = 600;
+const RECENCY_HALF_LIFE_DAYS = 30;
+
+/**
+ * A dish's standing in a user's history, before current menu data is added
+ */
+export interface RecentDishRank {
+  dishId: string;
+  restaurantId: string;
+  orderCount: number;
+  quantity: number;
+  lastOrderedAt: string;
+  score: number;
+}
+
+function getKey(userId: string): string {
+  return `${CACHE_PREFIX}${userId}`;
+}
+
+/**
+ * Rank the dishes in a user's orders, highest score first
+ */
+export function rankRecentDishes(orders: SaleorOrder[], now: Date = new Date()): RecentDishRank[] {
+  const skipped = new Set([getVar("TIP_VARIANT_ID"), getVar("SERVICE_FEE_VARIANT_ID")]);
+  const ranks = new Map<string, RecentDishRank>();
+
+  for (const order of orders) {
+    const status = getNormalizedStatus(order);
+    if (status === "CANCELLED" || status === "EXPIRED" || !order.channelId) {
+      continue;
+    }
+    const ageDays = Math.max(0, now.getTime() - new Date(order.createdAt).getTime()) / 86400000;
+    const weight = Math.pow(0.5, ageDays / RECENCY_HALF_LIFE_DAYS);
+    const seen = new Set<string>();
+    for (const line of order.lines) {
+      if (!line.variantId || skipped.has(line.variantId)) {
+        continue;
+      }
+      const key = `${order.channelId}:${line.variantId}`;
+      const rank = ranks.get(key) ?? {
+        dishId: line.variantId,
+        restaurantId: order.channelId,
+        orderCount: 0,
+        quantity: 0,
+        lastOrderedAt: order.createdAt,
+        score: 0,
+      };
+      rank.quantity += line.quantity;
+      if (!seen.has(key)) {
+        seen.add(key);
+        rank.orderCount += 1;
+        rank.score += weight;
+      }
+      if (order.createdAt > rank.lastOrderedAt) {
+        rank.lastOrderedAt = order.createdAt;
+      }
+      ranks.set(key, rank);
+    }
+  }
+
+  return Array.from(ranks.values()).sort(
+    (a, b) => b.score - a.score || b.lastOrderedAt.localeCompare(a.lastOrderedAt),
+  );
+}
+
+/**
+ * A user's ranking, from cache or their order history
+ */
+async function getRanking(userId: string): Promise<RecentDishRank[]> {
+  const cached = await readJSON<RecentDishRank[]>(getKey(userId));
+  if (cached) {
+    return cached;
+  }
+  const ranking = rankRecentDishes(await fetchUserOrders(userId)).slice(0, MAX_RECENT_DISHES);
+  await writeJSON(getKey(userId), ranking, { expirationTtl: CACHE_TTL_SECONDS });
+  return ranking;
+}
+
+/**
+ * Forget a user's cached ranking (after they order)
+ */
+export async function invalidateRecentDishes(userId: string): Promise<void> {
+  await deleteKey(getKey(userId));
+}
+
+/**
+ * The dishes a user orders most, with current menu data
+ */
+export async function fetchRecentDishes(
+  userId: string,
+  limit: number = DEFAULT_RECENT_DISHES,
+): Promise<RecentDish[]> {
+  const size = Math.min(Math.max(1, Math.floor(limit)), MAX_RECENT_DISHES);
+  const ranking = await getRanking(userId);
+
+  const recent: RecentDish[] = [];
+  const menus = new Map<string, Map<string, RecentDish["dish"]>>();
+  for (const rank of ranking) {
+    if (recent.length >= size) {
+      break;
+    }
+    let menu = menus.get(rank.restaurantId);
+    if (!menu) {
+      menu = new Map();
+      try {
+        const priceDisplay = await resolveMenuPriceDisplay(rank.restaurantId);
+        const dishes = await fetchDishes(undefined, rank.restaurantId, undefined, priceDisplay);
+        for (const dish of dishes) {
+          menu.set(dish.id, dish);
+        }
+      } catch (error) {
+        // One restaurant's menu failing leaves its dishes out
+        logger.warn("recent_dishes_menu_failed", {
+          restaurantId: rank.restaurantId,
+          error: error instanceof Error ? error.message : "Unknown error",
+        });
+      }
+      menus.set(rank.restaurantId, menu);
+    }
+    const dish = menu.get(rank.dishId);
+    if (dish) {
+      recent.push({
+        dish,
+        restaurantId: rank.restaurantId,
+        orderCount: rank.orderCount,
+        quantity: rank.quantity,
+        lastOrderedAt: rank.lastOrderedAt,
+      });
+    }
+  }
+  return recent;
+}
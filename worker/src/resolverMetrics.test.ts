@@ -0,0 +1,103 @@
+// Resolver Metrics Tests
+// Tests for resolverMetrics.ts - per-field timing, errors and cardinality
+
+import { describe, it, expect, beforeEach, afterEach } from "vitest";
+import { notFoundError } from "./errors";
+import {
+  getResolverRestaurantId,
+  recordResolverCall,
+  renderPrometheusMetrics,
+  resetResolverMetrics,
+  restaurantLabel,
+  withResolverMetrics,
+} from "./resolverMetrics";
+import { getLogContext } from "./logContext";
+
+describe("resolver metrics", () => {
+  beforeEach(() => {
+    resetResolverMetrics();
+  });
+
+  afterEach(() => {
+    delete (globalThis as any).RESOLVER_METRICS_MAX_FIELDS;
+    delete (globalThis as any).METRICS_RESTAURANT_ALLOWLIST;
+    delete (globalThis as any).METRICS_RESTAURANT_BUCKETS;
+  });
+
+  it("should count calls and errors by code", async () => {
+    const wrapped = withResolverMetrics("Query", {
+      ok: async () => "ok",
+      missing: async () => {
+        throw notFoundError("Order not found");
+      },
+    });
+
+    await wrapped.ok();
+    await expect(wrapped.missing()).rejects.toThrow("Order not found");
+
+    const output = renderPrometheusMetrics();
+    expect(output).toContain(
+      'graphql_resolver_duration_seconds_count{type="Query",field="ok"} 1',
+    );
+    expect(output).toContain(
+      'graphql_resolver_errors_total{type="Query",field="missing",code="NOT_FOUND"} 1',
+    );
+  });
+
+  it("should label unknown errors as internal", () => {
+    recordResolverCall("Mutation", "placeOrder", 20, new Error("boom"));
+    expect(renderPrometheusMetrics()).toContain(
+      'graphql_resolver_errors_total{type="Mutation",field="placeOrder",code="INTERNAL_ERROR"} 1',
+    );
+  });
+
+  it("should fold fields past the cap into __other__", () => {
+    (globalThis as any).RESOLVER_METRICS_MAX_FIELDS = "1";
+    recordResolverCall("Query", "restaurants", 5);
+    recordResolverCall("Query", "cart", 5);
+    const output = renderPrometheusMetrics();
+    expect(output).toContain('field="restaurants"');
+    expect(output).toContain('field="__other__"');
+    expect(output).not.toContain('field="cart"');
+  });
+
+  it("should find the restaurant in args or input", () => {
+    expect(getResolverRestaurantId({ restaurantId: "restA" })).toBe("restA");
+    expect(getResolverRestaurantId({ input: { restaurantId: "restB" } })).toBe("restB");
+    expect(getResolverRestaurantId({ orderId: "o1" })).toBeNull();
+    expect(getResolverRestaurantId(undefined)).toBeNull();
+  });
+
+  it("should bound restaurant labels with an allowlist and hash buckets", () => {
+    (globalThis as any).METRICS_RESTAURANT_ALLOWLIST = "restA";
+    (globalThis as any).METRICS_RESTAURANT_BUCKETS = "4";
+    expect(restaurantLabel("restA")).toBe("restA");
+    expect(restaurantLabel("restB")).toMatch(/^bucket-[0-3]$/);
+    expect(restaurantLabel("restB")).toBe(restaurantLabel("restB"));
+
+    (globalThis as any).METRICS_RESTAURANT_BUCKETS = "0";
+    expect(restaurantLabel("restB")).toBe("__other__");
+  });
+
+  it("should count per restaurant and tag logs with it", async () => {
+    (globalThis as any).METRICS_RESTAURANT_ALLOWLIST = "restA";
+    let logContext: Record<string, string> = {};
+    const wrapped = withResolverMetrics("Query", {
+      restaurantCategories: async (_: unknown, __: { restaurantId: string }) => {
+        logContext = getLogContext();
+        throw notFoundError("Restaurant not found");
+      },
+    });
+
+    await expect(wrapped.restaurantCategories(null, { restaurantId: "restA" })).rejects.toThrow();
+
+    expect(logContext).toEqual({ tenant: "primary", restaurantId: "restA" });
+    const output = renderPrometheusMetrics();
+    expect(output).toContain(
+      'graphql_restaurant_resolver_calls_total{tenant="primary",restaurant="restA",type="Query",field="restaurantCategories"} 1',
+    );
+    expect(output).toContain(
+      'graphql_restaurant_resolver_errors_total{tenant="primary",restaurant="restA",type="Query",field="restaurantCategories",code="NOT_FOUND"} 1',
+    );
+  });
+});
@@ -0,0 +1,315 @@
+// Per-Resolver Operation Metrics
+// Records resolve time and error counts for every Query/Mutation field in
+// this isolate and exposes them in Prometheus text format at GET /metrics
+// (bearer METRICS_TOKEN). Labels are bounded: field names come from the
+// resolver maps (capped by RESOLVER_METRICS_MAX_FIELDS, extra fields fold
+// into "__other__") and error labels are ErrorCode values only.
+// Calls for one restaurant (a restaurantId argument or input field) are
+// also counted per tenant (Saleor target) and restaurant. Restaurant
+// labels stay bounded: IDs in METRICS_RESTAURANT_ALLOWLIST are used as is,
+// the rest are hashed into METRICS_RESTAURANT_BUCKETS buckets. Log entries
+// written during the call carry the tenant and the raw restaurantId.
+
+import { getBooleanVar, getListVar, getNumberVar, getVar } from "./config";
+import { AppError, ErrorCode } from "./errors";
+import { logger } from "./logger";
+import { runWithLogContext } from "./logContext";
+import { fnv1a, getSaleorTarget } from "./saleorTargets";
+
+export const METRICS_PATH = "/metrics";
+
+// Histogram buckets in seconds
+const DURATION_BUCKETS = [0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10];
+
+const OTHER_FIELD = "__other__";
+
+interface FieldStats {
+  type: string;
+  field: string;
+  count: number;
+  durationSum: number;
+  bucketCounts: number[];
+  errors: Map<string, number>;
+}
+
+interface RestaurantStats {
+  tenant: string;
+  restaurant: string;
+  type: string;
+  field: string;
+  count: number;
+  durationSum: number;
+  errors: Map<string, number>;
+}
+
+// Series beyond this fold into restaurant "__other__"
+const MAX_RESTAURANT_SERIES = 2000;
+
+let stats = new Map<string, FieldStats>();
+let restaurantStats = new Map<string, RestaurantStats>();
+
+/**
+ * Metrics are on unless RESOLVER_METRICS_ENABLED=false
+ */
+export function isResolverMetricsEnabled(): boolean {
+  return getBooleanVar("RESOLVER_METRICS_ENABLED", true);
+}
+
+function getMaxFields(): number {
+  const max = getNumberVar("RESOLVER_METRICS_MAX_FIELDS", 200);
+  return max > 0 ? max : 200;
+}
+
+function getFieldStats(type: string, field: string): FieldStats {
+  let key = `${type}.${field}`;
+  if (!stats.has(key) && stats.size >= getMaxFields()) {
+    field = OTHER_FIELD;
+    key = `${type}.${field}`;
+  }
+  let entry = stats.get(key);
+  if (!entry) {
+    entry = {
+      type,
+      field,
+      count: 0,
+      durationSum: 0,
+      bucketCounts: DURATION_BUCKETS.map(() => 0),
+      errors: new Map(),
+    };
+    stats.set(key, entry);
+  }
+  return entry;
+}
+
+/**
+ * Bounded metrics label for a restaurant ID
+ */
+export function restaurantLabel(restaurantId: string): string {
+  if (getListVar("METRICS_RESTAURANT_ALLOWLIST").includes(restaurantId)) {
+    return restaurantId;
+  }
+  const buckets = Math.floor(getNumberVar("METRICS_RESTAURANT_BUCKETS", 16));
+  return buckets > 0 ? `bucket-${fnv1a(restaurantId) % buckets}` : OTHER_FIELD;
+}
+
+/**
+ * Restaurant a resolver call is for, from args.restaurantId or args.input.restaurantId
+ */
+export function getResolverRestaurantId(args: unknown): string | null {
+  const value = (args as any)?.restaurantId ?? (args as any)?.input?.restaurantId;
+  return typeof value === "string" && value ? value : null;
+}
+
+function getRestaurantStats(
+  tenant: string,
+  restaurantId: string,
+  type: string,
+  field: string,
+): RestaurantStats {
+  let restaurant = restaurantLabel(restaurantId);
+  let key = `${tenant}|${restaurant}|${type}.${field}`;
+  if (!restaurantStats.has(key) && restaurantStats.size >= MAX_RESTAURANT_SERIES) {
+    restaurant = OTHER_FIELD;
+    key = `${tenant}|${restaurant}|${type}.${field}`;
+  }
+  let entry = restaurantStats.get(key);
+  if (!entry) {
+    entry = { tenant, restaurant, type, field, count: 0, durationSum: 0, errors: new Map() };
+    restaurantStats.set(key, entry);
+  }
+  return entry;
+}
+
+/**
+ * Error label for a thrown value; anything but an AppError is internal
+ */
+export function errorCodeLabel(error: unknown): string {
+  if (error instanceof AppError && Object.values(ErrorCode).includes(error.code)) {
+    return error.code;
+  }
+  return ErrorCode.INTERNAL_ERROR;
+}
+
+/**
+ * Record one resolver call
+ */
+export function recordResolverCall(
+  type: string,
+  field: string,
+  durationMs: number,
+  error?: unknown,
+  restaurantId: string | null = null,
+): void {
+  if (restaurantId) {
+    const perRestaurant = getRestaurantStats(getSaleorTarget(), restaurantId, type, field);
+    perRestaurant.count += 1;
+    perRestaurant.durationSum += durationMs / 1000;
+    if (error !== undefined) {
+      const code = errorCodeLabel(error);
+      perRestaurant.errors.set(code, (perRestaurant.errors.get(code) || 0) + 1);
+    }
+  }
+
+  const entry = getFieldStats(type, field);
+  const seconds = durationMs / 1000;
+  entry.count += 1;
+  entry.durationSum += seconds;
+  DURATION_BUCKETS.forEach((bucket, index) => {
+    if (seconds <= bucket) {
+      entry.bucketCounts[index] += 1;
+    }
+  });
+  if (error !== undefined) {
+    const code = errorCodeLabel(error);
+    entry.errors.set(code, (entry.errors.get(code) || 0) + 1);
+  }
+}
+
+/**
+ * Wrap resolvers so each call's duration and outcome are recorded
+ */
+export function withResolverMetrics<T extends Record<string, (...args: any[]) => any>>(
+  type: string,
+  resolvers: T,
+): T {
+  const wrapped: Record<string, (...args: any[]) => any> = { ...resolvers };
+  for (const [name, resolver] of Object.entries(resolvers)) {
+    wrapped[name] = async (...args: unknown[]) => {
+      const restaurantId = getResolverRestaurantId(args[1]);
+      const context = {
+        tenant: getSaleorTarget(),
+        ...(restaurantId ? { restaurantId } : {}),
+      };
+      return runWithLogContext(context, async () => {
+        if (!isResolverMetricsEnabled()) {
+          return resolver(...args);
+        }
+        const startedAt = Date.now();
+        try {
+          const result = await resolver(...args);
+          recordResolverCall(type, name, Date.now() - startedAt, undefined, restaurantId);
+          return result;
+        } catch (error) {
+          recordResolverCall(type, name, Date.now() - startedAt, error ?? null, restaurantId);
+          throw error;
+        }
+      });
+    };
+  }
+  return wrapped as T;
+}
+
+function labels(values: Record<string, string>): string {
+  const parts = Object.entries(values).map(
+    ([key, value]) => `${key}="${value.replace(/\\/g, "\\\\").replace(/"/g, '\\"')}"`,
+  );
+  return `{${parts.join(",")}}`;
+}
+
+function restaurantLabels(entry: RestaurantStats): Record<string, string> {
+  return {
+    tenant: entry.tenant,
+    restaurant: entry.restaurant,
+    type: entry.type,
+    field: entry.field,
+  };
+}
+
+/**
+ * Prometheus text exposition of the recorded metrics
+ */
+export function renderPrometheusMetrics(): string {
+  const entries = Array.from(stats.values()).sort((a, b) =>
+    `${a.type}.${a.field}`.localeCompare(`${b.type}.${b.field}`),
+  );
+  const lines: string[] = [
+    "# HELP graphql_resolver_duration_seconds Resolve time per GraphQL field",
+    "# TYPE graphql_resolver_duration_seconds histogram",
+  ];
+  for (const entry of entries) {
+    const base = { type: entry.type, field: entry.field };
+    DURATION_BUCKETS.forEach((bucket, index) => {
+      lines.push(
+        `graphql_resolver_duration_seconds_bucket${labels({ ...base, le: String(bucket) })} ${entry.bucketCounts[index]}`,
+      );
+    });
+    lines.push(
+      `graphql_resolver_duration_seconds_bucket${labels({ ...base, le: "+Inf" })} ${entry.count}`,
+      `graphql_resolver_duration_seconds_sum${labels(base)} ${entry.durationSum}`,
+      `graphql_resolver_duration_seconds_count${labels(base)} ${entry.count}`,
+    );
+  }
+
+  lines.push(
+    "# HELP graphql_resolver_errors_total Failed GraphQL field resolutions by error code",
+    "# TYPE graphql_resolver_errors_total counter",
+  );
+  for (const entry of entries) {
+    for (const [code, count] of entry.errors) {
+      lines.push(
+        `graphql_resolver_errors_total${labels({ type: entry.type, field: entry.field, code })} ${count}`,
+      );
+    }
+  }
+
+  const restaurants = Array.from(restaurantStats.values()).sort((a, b) =>
+    `${a.tenant}|${a.restaurant}|${a.type}.${a.field}`.localeCompare(
+      `${b.tenant}|${b.restaurant}|${b.type}.${b.field}`,
+    ),
+  );
+  lines.push(
+    "# HELP graphql_restaurant_resolver_calls_total GraphQL field calls per restaurant",
+    "# TYPE graphql_restaurant_resolver_calls_total counter",
+  );
+  for (const entry of restaurants) {
+    const base = restaurantLabels(entry);
+    lines.push(`graphql_restaurant_resolver_calls_total${labels(base)} ${entry.count}`);
+  }
+  lines.push(
+    "# HELP graphql_restaurant_resolver_duration_seconds_total Resolve time per restaurant",
+    "# TYPE graphql_restaurant_resolver_duration_seconds_total counter",
+  );
+  for (const entry of restaurants) {
+    const base = restaurantLabels(entry);
+    lines.push(
+      `graphql_restaurant_resolver_duration_seconds_total${labels(base)} ${entry.durationSum}`,
+    );
+  }
+  lines.push(
+    "# HELP graphql_restaurant_resolver_errors_total Failed field resolutions per restaurant",
+    "# TYPE graphql_restaurant_resolver_errors_total counter",
+  );
+  for (const entry of restaurants) {
+    for (const [code, count] of entry.errors) {
+      const values = { ...restaurantLabels(entry), code };
+      lines.push(`graphql_restaurant_resolver_errors_total${labels(values)} ${count}`);
+    }
+  }
+  return `${lines.join("\n")}\n`;
+}
+
+/**
+ * GET /metrics - scrape endpoint, disabled unless METRICS_TOKEN is set
+ */
+export function handleMetricsRequest(request: Request): Response {
+  const token = getVar("METRICS_TOKEN");
+  if (!token) {
+    return new Response("Not Found", { status: 404 });
+  }
+  if (request.headers.get("Authorization") !== `Bearer ${token}`) {
+    logger.authFailure("invalid_metrics_token");
+    return new Response("Forbidden", { status: 403 });
+  }
+  return new Response(renderPrometheusMetrics(), {
+    status: 200,
+    headers: { "Content-Type": "text/plain; version=0.0.4" },
+  });
+}
+
+/**
+ * Reset recorded metrics (tests)
+ */
+export function resetResolverMetrics(): void {
+  stats = new Map();
+  restaurantStats = new Map();
+}
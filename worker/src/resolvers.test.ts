@@ -0,0 +1,167 @@
+// Resolver Tests
+// Tests for resolvers.ts - restaurant order lists for restaurant admins
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { GraphQLContext } from "./contracts";
+import { SUPERADMIN_TELEGRAM_ID } from "./auth";
+import { SaleorFetch, initializeSaleorClient } from "./saleorClient";
+import { cancelSaleorOrder, clearOrders, createSaleorOrder } from "./saleorOrder";
+import { queryResolvers } from "./resolvers";
+
+vi.mock("./logger", () => ({
+  logger: {
+    info: vi.fn(),
+    warn: vi.fn(),
+    error: vi.fn(),
+    debug: vi.fn(),
+    authFailure: vi.fn(),
+  },
+  isDebugModeEnabled: () => false,
+}));
+
+function makeContext(userId: string = SUPERADMIN_TELEGRAM_ID): GraphQLContext {
+  return { auth: { userId, valid: true } };
+}
+
+async function createOrders(restaurantId: string, count: number): Promise<string[]> {
+  const ids: string[] = [];
+  for (let i = 0; i < count; i++) {
+    const result = await createSaleorOrder(
+      {
+        restaurantId,
+        deliveryLocation: { address: "1 Test Street" },
+        items: [{ dishId: "dish1", quantity: 1 }],
+      },
+      `${restaurantId}-user-${i}`,
+    );
+    ids.push(result.order!.id);
+  }
+  return ids;
+}
+
+describe("restaurantOrders", () => {
+  beforeEach(() => {
+    clearOrders();
+  });
+
+  it("should list the restaurant's orders and leave out finished ones", async () => {
+    const [cancelled, ...active] = await createOrders("restA", 3);
+    await createOrders("restB", 1);
+    await cancelSaleorOrder(cancelled);
+
+    const all = await queryResolvers.restaurantOrders(
+      null,
+      { restaurantId: "restA" },
+      makeContext(),
+    );
+    expect(all).toHaveLength(3);
+
+    const activeOnly = await queryResolvers.restaurantOrders(
+      null,
+      { restaurantId: "restA", activeOnly: true },
+      makeContext(),
+    );
+    expect(activeOnly.map((order) => order.orderId).sort()).toEqual([...active].sort());
+  });
+
+  it("should refuse callers who don't manage the restaurant", async () => {
+    await expect(
+      queryResolvers.restaurantOrders(null, { restaurantId: "restA" }, makeContext("user-1")),
+    ).rejects.toMatchObject({ code: "FORBIDDEN" });
+  });
+});
+
+describe("restaurantOrdersConnection", () => {
+  beforeEach(() => {
+    clearOrders();
+  });
+
+  it("should page through the restaurant's orders", async () => {
+    const ids = await createOrders("restA", 3);
+
+    const first = await queryResolvers.restaurantOrdersConnection(
+      null,
+      { restaurantId: "restA", first: 2 },
+      makeContext(),
+    );
+    expect(first.edges).toHaveLength(2);
+    expect(first.pageInfo.hasNextPage).toBe(true);
+
+    const second = await queryResolvers.restaurantOrdersConnection(
+      null,
+      { restaurantId: "restA", first: 2, after: first.pageInfo.endCursor },
+      makeContext(),
+    );
+    expect(second.edges).toHaveLength(1);
+    expect(second.pageInfo).toEqual({ hasNextPage: false, endCursor: null });
+    expect([...first.edges, ...second.edges].map((edge) => edge.node.orderId)).toEqual(ids);
+  });
+
+  it("should resume after an edge's cursor", async () => {
+    const ids = await createOrders("restA", 3);
+    const page = await queryResolvers.restaurantOrdersConnection(
+      null,
+      { restaurantId: "restA" },
+      makeContext(),
+    );
+
+    const rest = await queryResolvers.restaurantOrdersConnection(
+      null,
+      { restaurantId: "restA", after: page.edges[0].cursor },
+      makeContext(),
+    );
+    expect(rest.edges.map((edge) => edge.node.orderId)).toEqual(ids.slice(1));
+  });
+});
+
+describe("restaurantOrders with Saleor", () => {
+  const send = vi.fn<SaleorFetch>(async () =>
+    Response.json({
+      data: { orders: { edges: [], pageInfo: { hasNextPage: false, endCursor: null } } },
+    }),
+  );
+
+  beforeEach(() => {
+    send.mockClear();
+    (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+    initializeSaleorClient({ SALEOR_API_URL: "https://saleor.test/graphql/", SALEOR_TOKEN: "t" });
+  });
+
+  afterEach(() => {
+    initializeSaleorClient({});
+    delete (globalThis as any).SALEOR_TRANSPORT;
+  });
+
+  function ordersVariables(): any[] {
+    return send.mock.calls
+      .map(([, init]) => JSON.parse(String(init?.body)))
+      .filter((body) => body.operationName === "Orders")
+      .map((body) => body.variables);
+  }
+
+  it("should let Saleor filter out finished orders", async () => {
+    await queryResolvers.restaurantOrders(
+      null,
+      { restaurantId: "restA", activeOnly: true },
+      makeContext(),
+    );
+    await queryResolvers.restaurantOrdersConnection(
+      null,
+      { restaurantId: "restA", activeOnly: true, first: 5 },
+      makeContext(),
+    );
+
+    const [list, page] = ordersVariables();
+    const filter = {
+      channels: ["restA"],
+      status: ["UNCONFIRMED", "UNFULFILLED", "PARTIALLY_FULFILLED"],
+    };
+    expect(list.filter).toEqual(filter);
+    expect(page).toMatchObject({ filter, first: 5, after: null });
+  });
+
+  it("should not filter on status without activeOnly", async () => {
+    await queryResolvers.restaurantOrders(null, { restaurantId: "restA" }, makeContext());
+    expect(ordersVariables()[0].filter).toEqual({ channels: ["restA"] });
+  });
+});
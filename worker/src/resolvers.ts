@@ -5,15 +5,25 @@
 
 import {
   Restaurant,
+  RestaurantReview,
+  TagLabel,
+  HandoffVerification,
+  OrderDelivery,
+  PriceSnapshot,
+  GeoPoint,
+  VerifyHandoffCodeInput,
   Category,
   Dish,
+  Connection,
   PlaceOrderInput,
   PlaceOrderPayload,
+  OrderDetails,
   GraphQLContext,
   AddToCartInput,
   UpdateCartItemInput,
   CartState,
   DeliveryLocation,
+  CancellationReasonCode,
 } from "./contracts";
 import { logger } from "./logger";
 import {
@@ -27,17 +37,47 @@ import {
 } from "./cart";
 import {
   createSaleorOrder,
+  CreateOrderResult,
   toPlaceOrderPayload,
+  toOrderDetails,
+  fetchUserOrder,
+  fetchUserOrders,
+  fetchActiveUserOrder,
+  fetchOrders,
+  fetchOrdersPage,
+  getNormalizedStatus,
+  OrderQueryFilter,
   OrderStatus,
+  SaleorOrder,
+  ACTIVE_ORDER_STATUSES,
 } from "./saleorOrder";
-import { forbiddenError, badUserInputError, internalError } from "./errors";
+import { isTerminalOrderStatus } from "./orderStatus";
+import { validateAsapOrder, validateScheduledFor } from "./scheduledOrders";
+import { assertSlotAvailable, reserveSlot } from "./slots";
+import { normalizeFulfillmentType, requirePickupLocation } from "./pickup";
+import { estimateDeliveryAt } from "./eta";
+import { isPaymentRequired, startPaymentDeadline } from "./orderState";
+import {
+  forbiddenError,
+  badUserInputError,
+  internalError,
+  notFoundError,
+  mixedRestaurantsError,
+} from "./errors";
 import { requireRead, requireWrite, requireSuperadmin, isSuperadmin as checkIsSuperadmin } from "./auth";
 import {
   fetchRestaurants,
   fetchCategories,
+  fetchCategoryDishCounts,
   fetchDishes,
   fetchChannels,
+  fetchRestaurantsPage,
+  fetchCategoriesPage,
+  fetchDishesPage,
+  fetchFeaturedDishes,
 } from "./saleorService";
+import { getPageSize, mapConnection } from "./pagination";
+import { QUERY_NODE_COSTS, createCostBudget } from "./queryCost";
 import {
   getChannelAdmin,
   setChannelAdmin,
@@ -48,7 +88,155 @@ import {
 import {
   LinkChannelInput,
   UnlinkChannelInput,
+  OperationAuditRecord,
+  OrderPipelineShadowReport,
+  MetadataMigrationReport,
+  CatalogHealthReport,
+  ImageFormat,
+  DishSortBy,
+  RestaurantSortBy,
+  OrderQuote,
+  PromoCodeValidation,
+  GiftCardBalance,
+  LoyaltyBalance,
+  RestaurantAnnouncement,
+  RestaurantPause,
+  SetRestaurantAnnouncementInput,
+  KitchenCapacity,
+  ClientConfig,
+  DailyBanner,
+  SetDailyBannerInput,
+  CommissionRate,
+  SetCommissionRateInput,
+  PayoutReport,
+  TaxConfiguration,
+  Broadcast,
+  StartBroadcastInput,
+  NotificationFailure,
+  NotificationFailureFilter,
+  Review,
+  ReviewStatus,
+  FavoriteEntry,
+  FavoriteInput,
+  RecentDish,
+  RestaurantSearchResult,
+  FavoriteKind,
+  SubmitReviewInput,
+  ModerateReviewInput,
+  ReplyToReviewInput,
+  OrderIssue,
+  OrderIssueStatus,
+  AbuseFlag,
+  AbuseFlagStatus,
+  ReviewAbuseFlagInput,
+  ReportOrderIssueInput,
+  ResolveOrderIssueInput,
+  CreateInvoicePayload,
+  CompensationVoucher,
+  IssueCompensationVoucherInput,
+  OrderPaymentStatus,
+  PaymentMethod,
+  InitPaymentPayload,
+  OrderDecisionPayload,
+  CreateStaffInviteInput,
+  RevokeStaffRoleInput,
+  StaffInvite,
+  StaffMember,
+  DeliverySlot,
+  DishDetail,
+  Money,
+  OpeningHoursOverride,
+  SetOpeningHoursOverrideInput,
 } from "./contracts";
+import { fetchTaxConfiguration, resolveMenuPriceDisplay } from "./taxes";
+import { listAuditedOperations } from "./operationAudit";
+import { getShadowReport } from "./shadowPipeline";
+import { migrateCatalogMetadata } from "./metadataMigration";
+import { buildCatalogHealthReport } from "./catalogHealth";
+import { negotiateImageFormat } from "./imageFormat";
+import { quoteOrder } from "./quotes";
+import { requireValidPromoCode, validatePromoCode } from "./promoCodes";
+import { fetchGiftCardBalance, lookupGiftCard, requireUsableGiftCard } from "./giftCards";
+import { getServiceFee } from "./orderTotals";
+import {
+  getLoyaltyBalance,
+  getLoyaltyPointsRedeemed,
+  releaseLoyaltyPoints,
+  requireRedeemablePoints,
+  reserveLoyaltyPoints,
+} from "./loyalty";
+import { getActiveAnnouncement, setRestaurantAnnouncement } from "./announcements";
+import { assertAcceptingOrders, setRestaurantPaused } from "./restaurantPause";
+import { buildClientConfig } from "./clientConfig";
+import { setDailyBanner } from "./dailyBanner";
+import { assertKitchenCapacity, setKitchenCapacity, withBusyFlags } from "./kitchenCapacity";
+import { assertOrderLimits } from "./orderLimits";
+import { fetchRecentDishes, invalidateRecentDishes } from "./recentDishes";
+import { getDeliverySlots, setOpeningHoursOverride } from "./workingCalendar";
+import { notifyOrderPlaced } from "./notifications";
+import { isValidTimezone, TIMEZONE_METADATA_KEY } from "./openingHours";
+import { assertDishesAvailable } from "./availability";
+import { createInvoice } from "./telegramPayments";
+import { getPaymentStatus } from "./paymentStatus";
+import { initPayment, listPaymentMethods } from "./paymentGateways";
+import {
+  issueCompensationVoucher,
+  getOrderVoucherRestaurantId,
+} from "./vouchers";
+import {
+  reportOrderIssue,
+  listOrderIssues,
+  resolveOrderIssue,
+  getOrderIssueRestaurantId,
+} from "./orderIssues";
+import { getPriceHistory } from "./priceHistory";
+import { fetchRestaurantDetails } from "./restaurantDetails";
+import { fetchRestaurantsNear } from "./geo";
+import { applyZoneVisibility } from "./deliveryZones";
+import {
+  DEFAULT_SEARCH_RESULTS,
+  MAX_QUERY_LENGTH as MAX_SEARCH_QUERY_LENGTH,
+  searchRestaurants,
+} from "./restaurantSearch";
+import { DISH_SORT_OPTIONS } from "./dishPopularity";
+import { RESTAURANT_SORT_OPTIONS, sortRestaurants } from "./restaurantSort";
+import { placePromotedFirst } from "./promotedRestaurants";
+import { isDemoMode, placeDemoOrder } from "./demoMode";
+import { matchesDietaryFilter } from "./dietaryTags";
+import {
+  detectAbuseSignals,
+  flagOrderForReview,
+  getAbuseFlagRestaurantId,
+  listAbuseFlags,
+  reviewAbuseFlag,
+} from "./abuseDetection";
+import {
+  submitReview,
+  listReviews,
+  listRestaurantReviews,
+  withRatings,
+  moderateReview,
+  replyToReview,
+  getReviewRestaurantId,
+} from "./reviews";
+import {
+  startBroadcast,
+  abortBroadcast,
+  getBroadcast,
+  listBroadcasts,
+} from "./broadcasts";
+import {
+  listNotificationFailures,
+  markNotificationsPermanentlyFailed,
+  retryNotificationFailures,
+} from "./notificationFailures";
+import {
+  getCommissionRate,
+  setCommissionRate,
+  buildPayoutReports,
+  payoutReportsToCsv,
+  normalizePeriod,
+} from "./payouts";
 import {
   createDish,
   updateDish,
@@ -57,6 +245,157 @@ import {
   isChannelAdmin,
 } from "./products";
 import { badUserInputError } from "./errors";
+import { assertValidArgs, withInputValidation } from "./validation";
+import { withResolverMetrics } from "./resolverMetrics";
+import { getRemainingBudgetMs, withOperationDeadlines } from "./operationDeadlines";
+import { validateTipAmount } from "./tips";
+import { fetchChannelById } from "./saleorService";
+import { MUTATION_INPUT_RULES } from "./mutationRules";
+import { toMoney } from "./currencyFormat";
+import { fetchDishDetail } from "./dishDetails";
+import {
+  acceptOrder,
+  cancelUserOrder,
+  getOrderRestaurantId,
+  rejectOrder,
+} from "./paymentHolds";
+import { RESTAURANT_CANCELLATION_REASONS } from "./cancellationReasons";
+import { mapSaleorError } from "./saleorErrors";
+import { acceptStaffInvite, createStaffInvite } from "./staffInvites";
+import { setDigestEnabled } from "./ownerDigest";
+import {
+  hasStaffRole,
+  listRestaurantStaff,
+  listUserStaffRoles,
+  revokeStaffRole,
+} from "./staffRoles";
+import {
+  collectRestaurantTags,
+  filterRestaurantsByTags,
+  listTagLabels,
+  toTagLabels,
+} from "./tagLabels";
+import { buildCategoryTree, getChildCategories } from "./categoryTree";
+import { addFavorite, listFavorites, removeFavorite, resolveFavorites } from "./favorites";
+import { resolveHandoffInput } from "./handoffCodes";
+import { verifyHandoffCode, withHandoffCode } from "./orderHandoff";
+import { markOrderDelivered } from "./orderFulfillment";
+import { allInOrder } from "./utils";
+
+/**
+ * Allow the superadmin or the restaurant's channel admin
+ */
+async function requireRestaurantAdmin(
+  context: GraphQLContext,
+  restaurantId: string,
+): Promise<void> {
+  const auth = requireRead(context.auth);
+  if (!auth.valid) {
+    logger.authFailure("permission_denied", context.auth.userId);
+    throw forbiddenError();
+  }
+  if (checkIsSuperadmin(auth.userId)) {
+    return;
+  }
+  const isAdmin = await isChannelAdmin(auth.userId, restaurantId);
+  if (!isAdmin) {
+    logger.authFailure("channel_admin_required", context.auth.userId);
+    throw forbiddenError();
+  }
+}
+
+/**
+ * Allow the superadmin, the restaurant's channel admin and its staff or
+ * couriers
+ */
+async function requireRestaurantStaff(
+  context: GraphQLContext,
+  restaurantId: string,
+): Promise<void> {
+  const auth = requireRead(context.auth);
+  if (!auth.valid) {
+    logger.authFailure("permission_denied", context.auth.userId);
+    throw forbiddenError();
+  }
+  if (checkIsSuperadmin(auth.userId) || (await hasStaffRole(restaurantId, auth.userId))) {
+    return;
+  }
+  await requireRestaurantAdmin(context, restaurantId);
+}
+
+/**
+ * Check the caller's role at an order's restaurant
+ * The order is looked up only for authenticated callers, so anonymous ones
+ * can't tell unknown orders (NOT_FOUND) from existing ones (FORBIDDEN)
+ */
+async function requireOrderRestaurantRole(
+  context: GraphQLContext,
+  orderId: string,
+  requireRole: (context: GraphQLContext, restaurantId: string) => Promise<void>,
+): Promise<void> {
+  const auth = requireRead(context.auth);
+  if (!auth.valid) {
+    logger.authFailure("permission_denied", context.auth.userId);
+    throw forbiddenError();
+  }
+  await requireRole(context, await getOrderRestaurantId(orderId));
+}
+
+/**
+ * Saleor filter for restaurantOrders; with activeOnly, orders in a terminal
+ * Saleor status are left out by Saleor itself
+ */
+function toRestaurantOrderFilter(args: {
+  restaurantId: string;
+  activeOnly?: boolean;
+}): OrderQueryFilter {
+  return {
+    channelId: args.restaurantId,
+    statuses: args.activeOnly ? ACTIVE_ORDER_STATUSES : undefined,
+  };
+}
+
+// Expired orders keep an active Saleor status, so they're dropped here
+function isActiveOrder(order: SaleorOrder): boolean {
+  return !isTerminalOrderStatus(getNormalizedStatus(order));
+}
+
+/**
+ * Add the display price and tag labels for the user's language to each dish
+ */
+function localizeDishes(dishes: Dish[], languageCode?: string): Dish[] {
+  return dishes.map((dish) => ({
+    ...dish,
+    priceMoney: toMoney(dish.price, dish.currency, languageCode),
+    dietaryTagLabels: toTagLabels(dish.dietaryTags, languageCode),
+    allergenLabels: toTagLabels(dish.allergens, languageCode),
+  }));
+}
+
+/**
+ * Add each category's dish count in the restaurant (null when unknown)
+ */
+async function withDishCounts(
+  categories: Category[],
+  restaurantId: string,
+): Promise<Category[]> {
+  const counts = await fetchCategoryDishCounts(categories, restaurantId);
+  return categories.map((category) => ({
+    ...category,
+    dishCount: counts.get(category.id) ?? null,
+  }));
+}
+
+/**
+ * Add cuisine tag labels for the user's language to each restaurant
+ */
+function localizeRestaurants(restaurants: Restaurant[], languageCode?: string): Restaurant[] {
+  return restaurants.map((restaurant) => ({
+    ...restaurant,
+    tags: restaurant.tags || [],
+    tagLabels: toTagLabels(restaurant.tags, languageCode),
+  }));
+}
 
 /**
  * Query resolvers with auth context
@@ -68,7 +407,11 @@ const queryResolvers = {
    */
   restaurants: async (
     _: any,
-    __: any,
+    args: {
+      location?: GeoPoint | null;
+      tags?: string[] | null;
+      sortBy?: RestaurantSortBy | null;
+    },
     context: GraphQLContext,
   ): Promise<Restaurant[]> => {
     // Enforce read permissions
@@ -77,9 +420,88 @@ const queryResolvers = {
       logger.authFailure("permission_denied", context.auth.userId);
       throw forbiddenError();
     }
+    if (args?.sortBy && !RESTAURANT_SORT_OPTIONS.includes(args.sortBy)) {
+      throw badUserInputError("Unknown sort order", "sortBy");
+    }
+    if (args?.sortBy === "DISTANCE" && !args.location) {
+      throw badUserInputError("Sorting by distance needs a location", "sortBy");
+    }
     // Log authenticated user (avoid logging sensitive data)
     console.log(`[Resolver] restaurants query for user ${context.auth.userId}`);
-    return await fetchRestaurants();
+    // With a location, restaurants that don't deliver there are left out
+    const nearby = args?.location
+      ? await fetchRestaurantsNear(args.location)
+      : await fetchRestaurants();
+    // Restaurants limited to other zones (cities) than the user's are hidden
+    const restaurants = await applyZoneVisibility(nearby, auth.userId, args?.location);
+    // With tags, restaurants sharing any of them
+    const tagged = filterRestaurantsByTags(restaurants, args?.tags);
+    const rated = await withRatings(await withBusyFlags(tagged));
+    // Promoted restaurants lead whatever the sort order
+    return localizeRestaurants(
+      placePromotedFirst(sortRestaurants(rated, args?.sortBy)),
+      context.auth.language,
+    );
+  },
+
+  /**
+   * Restaurants matching a free-text query, best first, with highlights
+   */
+  searchRestaurants: async (
+    _: any,
+    args: { query: string; location?: GeoPoint | null; first?: number | null },
+    context: GraphQLContext,
+  ): Promise<RestaurantSearchResult[]> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    const query = (args.query || "").trim();
+    if (!query) {
+      throw badUserInputError("Search query is required", "query");
+    }
+    if (query.length > MAX_SEARCH_QUERY_LENGTH) {
+      throw badUserInputError(
+        `Search query must be at most ${MAX_SEARCH_QUERY_LENGTH} characters`,
+        "query",
+      );
+    }
+    const nearby = args.location
+      ? await fetchRestaurantsNear(args.location)
+      : await fetchRestaurants();
+    const restaurants = await applyZoneVisibility(nearby, auth.userId, args.location);
+    const ranked = await withRatings(await withBusyFlags(restaurants));
+    return searchRestaurants(
+      localizeRestaurants(ranked, auth.language),
+      query,
+      auth.language,
+      args.first ?? DEFAULT_SEARCH_RESULTS,
+    );
+  },
+
+  /**
+   * One restaurant with address, hours, fees and rating
+   */
+  restaurant: async (
+    _: any,
+    args: { id: string },
+    context: GraphQLContext,
+  ): Promise<Restaurant> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (!args.id) {
+      throw badUserInputError("Restaurant is required", "id");
+    }
+    const restaurant = await fetchRestaurantDetails(args.id, auth.language);
+    if (!restaurant) {
+      throw notFoundError("Restaurant not found");
+    }
+    const [withBusy] = await withBusyFlags([restaurant]);
+    return localizeRestaurants([withBusy], auth.language)[0];
   },
 
   /**
@@ -87,7 +509,7 @@ const queryResolvers = {
    */
   restaurantCategories: async (
     _: any,
-    args: { restaurantId: string },
+    args: { restaurantId: string; parentCategoryId?: string | null },
     context: GraphQLContext,
   ): Promise<Category[]> => {
     const auth = requireRead(context.auth);
@@ -99,7 +521,29 @@ const queryResolvers = {
     console.log(
       `[Resolver] restaurantCategories for ${restaurantId}, user ${context.auth.userId}`,
     );
-    return await fetchCategories(restaurantId);
+    const categories = await withDishCounts(await fetchCategories(restaurantId), restaurantId);
+    // Without a parent every category is listed, as before nesting existed
+    return args.parentCategoryId
+      ? getChildCategories(categories, args.parentCategoryId)
+      : categories;
+  },
+
+  /**
+   * A restaurant's categories as a tree: top level sections with their
+   * subcategories nested in children
+   */
+  categoryTree: async (
+    _: any,
+    args: { restaurantId: string },
+    context: GraphQLContext,
+  ): Promise<Category[]> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    const categories = await fetchCategories(args.restaurantId);
+    return buildCategoryTree(await withDishCounts(categories, args.restaurantId));
   },
 
   /**
@@ -107,7 +551,13 @@ const queryResolvers = {
    */
   categoryDishes: async (
     _: any,
-    args: { categoryId: string; restaurantId: string },
+    args: {
+      categoryId: string;
+      restaurantId: string;
+      imageFormat?: ImageFormat;
+      sortBy?: DishSortBy;
+      dietaryFilter?: string[] | null;
+    },
     context: GraphQLContext,
   ): Promise<Dish[]> => {
     const auth = requireRead(context.auth);
@@ -116,10 +566,196 @@ const queryResolvers = {
       throw forbiddenError();
     }
     const { categoryId, restaurantId } = args;
+    if (args.sortBy && !DISH_SORT_OPTIONS.includes(args.sortBy)) {
+      throw badUserInputError("Unknown sort order", "sortBy");
+    }
     console.log(
       `[Resolver] categoryDishes for ${categoryId}, restaurant ${restaurantId}, user ${context.auth.userId}`,
     );
-    return await fetchDishes(categoryId, restaurantId);
+    const priceDisplay = await resolveMenuPriceDisplay(restaurantId);
+    const imageFormat = negotiateImageFormat(
+      args.imageFormat,
+      context.imageFormats,
+    );
+    const dishes = await fetchDishes(
+      categoryId,
+      restaurantId,
+      undefined,
+      priceDisplay,
+      imageFormat,
+      args.sortBy,
+    );
+    return localizeDishes(
+      dishes.filter((dish) => matchesDietaryFilter(dish, args.dietaryFilter)),
+      context.auth.language,
+    );
+  },
+
+  /**
+   * Page of restaurants
+   */
+  restaurantsConnection: async (
+    _: any,
+    args: { first?: number | null; after?: string | null },
+    context: GraphQLContext,
+  ): Promise<Connection<Restaurant>> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    const page = await fetchRestaurantsPage(args.first, args.after);
+    const restaurants = localizeRestaurants(
+      await withRatings(await withBusyFlags(page.edges.map((edge) => edge.node))),
+      context.auth.language,
+    );
+    return mapConnection(page, () => restaurants);
+  },
+
+  /**
+   * Page of a restaurant's categories
+   */
+  restaurantCategoriesConnection: async (
+    _: any,
+    args: { restaurantId: string; first?: number | null; after?: string | null },
+    context: GraphQLContext,
+  ): Promise<Connection<Category>> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    return fetchCategoriesPage(args.first, args.after);
+  },
+
+  /**
+   * Page of a category's dishes
+   */
+  categoryDishesConnection: async (
+    _: any,
+    args: {
+      categoryId: string;
+      restaurantId: string;
+      first?: number | null;
+      after?: string | null;
+      imageFormat?: ImageFormat;
+      dietaryFilter?: string[] | null;
+    },
+    context: GraphQLContext,
+  ): Promise<Connection<Dish>> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (!args.restaurantId) {
+      throw badUserInputError("Restaurant is required", "restaurantId");
+    }
+    const page = await fetchDishesPage(
+      args.categoryId,
+      args.restaurantId,
+      args.first,
+      args.after,
+      await resolveMenuPriceDisplay(args.restaurantId),
+      negotiateImageFormat(args.imageFormat, context.imageFormats),
+      args.dietaryFilter,
+    );
+    return mapConnection(page, (dishes) => localizeDishes(dishes, context.auth.language));
+  },
+
+  /**
+   * A dish with all its variants (sizes and options)
+   */
+  dish: async (
+    _: any,
+    args: { productId: string; restaurantId: string; imageFormat?: ImageFormat },
+    context: GraphQLContext,
+  ): Promise<DishDetail> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (!args.productId) {
+      throw badUserInputError("Dish is required", "productId");
+    }
+    if (!args.restaurantId) {
+      throw badUserInputError("Restaurant is required", "restaurantId");
+    }
+    const dish = await fetchDishDetail(
+      args.productId,
+      args.restaurantId,
+      await resolveMenuPriceDisplay(args.restaurantId),
+      negotiateImageFormat(args.imageFormat, context.imageFormats),
+    );
+    if (!dish) {
+      throw notFoundError("Dish not found");
+    }
+    return {
+      ...dish,
+      priceMoney: toMoney(dish.price, dish.currency, auth.language),
+      dietaryTagLabels: toTagLabels(dish.dietaryTags, auth.language),
+      allergenLabels: toTagLabels(dish.allergens, auth.language),
+      variants: dish.variants.map((variant) => ({
+        ...variant,
+        priceMoney: toMoney(variant.price, variant.currency, auth.language),
+      })),
+    };
+  },
+
+  /**
+   * Dishes by ID that are listed in the restaurant's channel
+   */
+  dishesByIds: async (
+    _: any,
+    args: { restaurantId: string; ids: string[] },
+    context: GraphQLContext,
+  ): Promise<Dish[]> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (!args.restaurantId) {
+      throw badUserInputError("Restaurant is required", "restaurantId");
+    }
+    const ids = args.ids || [];
+    const priceDisplay = await resolveMenuPriceDisplay(args.restaurantId);
+    const dishes = await fetchDishes(
+      undefined,
+      args.restaurantId,
+      undefined,
+      priceDisplay,
+      negotiateImageFormat(undefined, context.imageFormats),
+    );
+    return localizeDishes(
+      dishes.filter((dish) => ids.includes(dish.id)),
+      context.auth.language,
+    );
+  },
+
+  /**
+   * Dishes the restaurant pins to the top of its menu
+   */
+  featuredDishes: async (
+    _: any,
+    args: { restaurantId: string; imageFormat?: ImageFormat },
+    context: GraphQLContext,
+  ): Promise<Dish[]> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (!args.restaurantId) {
+      throw badUserInputError("Restaurant is required", "restaurantId");
+    }
+    const dishes = await fetchFeaturedDishes(
+      args.restaurantId,
+      await resolveMenuPriceDisplay(args.restaurantId),
+      negotiateImageFormat(args.imageFormat, context.imageFormats),
+    );
+    return localizeDishes(dishes, context.auth.language);
   },
 
   // ============================================================
@@ -140,6 +776,55 @@ const queryResolvers = {
     return checkIsSuperadmin(context.auth.userId);
   },
 
+  /**
+   * Labelled tags for filter chips, in the user's language
+   */
+  filterTagLabels: async (
+    _: any,
+    __: any,
+    context: GraphQLContext,
+  ): Promise<TagLabel[]> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    return listTagLabels(auth.language);
+  },
+
+  /**
+   * Distinct cuisine tags across restaurants, for restaurant filter chips
+   */
+  restaurantTags: async (
+    _: any,
+    __: any,
+    context: GraphQLContext,
+  ): Promise<TagLabel[]> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    return collectRestaurantTags(await fetchRestaurants(), auth.language);
+  },
+
+  /**
+   * Startup configuration for the Mini App, including supported versions
+   * and whether this client must update
+   */
+  clientConfig: async (
+    _: any,
+    __: any,
+    context: GraphQLContext,
+  ): Promise<ClientConfig> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    return buildClientConfig(context.clientVersion);
+  },
+
   /**
    * Get channel admin info for a restaurant
    */
@@ -197,354 +882,1706 @@ const queryResolvers = {
   },
 
   // ============================================================
-  // Phase 3: Cart Query Resolvers
+  // Order Detail & History Query Resolvers
   // ============================================================
+
   /**
-   * Get current user's cart
-   * Returns cart with items, total price, and item count
+   * Get one of the current user's orders
+   * Orders owned by other users are reported as not found
    */
-  cart: async (
+  order: async (
     _: any,
-    __: any,
+    args: { orderId: string },
     context: GraphQLContext,
-  ): Promise<{
-    restaurantId: string | null;
-    items: any[];
-    total: number;
-    itemCount: number;
-  }> => {
+  ): Promise<OrderDetails> => {
     const auth = requireRead(context.auth);
     if (!auth.valid) {
       logger.authFailure("permission_denied", context.auth.userId);
       throw forbiddenError();
     }
-    const userId = context.auth.userId;
-    console.log(`[Resolver] cart for user ${userId}`);
-
-    const cart = getCart(userId);
-    const total = getCartTotal(userId);
-    const itemCount = getCartItemCount(userId);
-
-    return {
-      restaurantId: cart.restaurantId || null,
-      items: cart.items,
-      total,
-      itemCount,
-    };
+    if (!args.orderId) {
+      throw badUserInputError("Order is required", "orderId");
+    }
+    const order = await fetchUserOrder(args.orderId, auth.userId);
+    if (!order) {
+      throw notFoundError("Order not found");
+    }
+    return withHandoffCode(toOrderDetails(order, auth.language), order);
   },
-};
 
-/**
- * Mutation resolvers with auth context
- */
-const mutationResolvers = {
   /**
-   * Place an order
-   * Uses cart items and authenticated user from context
-   * Creates a mock Saleor draft order
-   * Clears cart after successful order
+   * Current user's orders, newest first
    */
-  placeOrder: async (
+  orderHistory: async (
     _: any,
-    args: { input: PlaceOrderInput },
+    __: any,
     context: GraphQLContext,
-  ): Promise<PlaceOrderPayload> => {
-    // Enforce write permission for mutations
-    const auth = requireWrite(context.auth);
+  ): Promise<OrderDetails[]> => {
+    const auth = requireRead(context.auth);
     if (!auth.valid) {
-      logger.authFailure("permission_denied", auth.userId);
+      logger.authFailure("permission_denied", context.auth.userId);
       throw forbiddenError();
     }
-    const userId = auth.userId;
-    const userName = auth.name;
-    const userLanguage = auth.language;
-    console.log(
-      `[Resolver] placeOrder by user ${userId} (${userName}, lang: ${userLanguage})`,
-    );
+    const orders = await fetchUserOrders(auth.userId);
+    return orders.map((order) => withHandoffCode(toOrderDetails(order, auth.language), order));
+  },
 
-    // Validate input
-    if (!args.input.restaurantId) {
+  /**
+   * Current user's most recent non-terminal order (for "track your order")
+   */
+  activeOrder: async (
+    _: any,
+    __: any,
+    context: GraphQLContext,
+  ): Promise<OrderDetails | null> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    const order = await fetchActiveUserOrder(auth.userId);
+    return order ? withHandoffCode(toOrderDetails(order, auth.language), order) : null;
+  },
+
+  /**
+   * Dishes the current user orders most, for one-tap reordering
+   */
+  recentDishes: async (
+    _: any,
+    args: { limit?: number | null },
+    context: GraphQLContext,
+  ): Promise<RecentDish[]> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (args?.limit !== null && args?.limit !== undefined && args.limit < 1) {
+      throw badUserInputError("limit must be a positive integer", "limit");
+    }
+    const recent = await fetchRecentDishes(auth.userId, args?.limit ?? undefined);
+    return recent.map((entry) => ({
+      ...entry,
+      dish: localizeDishes([entry.dish], auth.language)[0],
+    }));
+  },
+
+  /**
+   * A restaurant's orders, newest first (superadmin or channel admin)
+   * With activeOnly, orders that reached a terminal status are left out
+   */
+  restaurantOrders: async (
+    _: any,
+    args: { restaurantId: string; activeOnly?: boolean },
+    context: GraphQLContext,
+  ): Promise<OrderDetails[]> => {
+    if (!args.restaurantId) {
       throw badUserInputError("Restaurant is required", "restaurantId");
     }
+    await requireRestaurantAdmin(context, args.restaurantId);
+    const orders = await fetchOrders(toRestaurantOrderFilter(args));
+    return orders
+      .filter((order) => !args.activeOnly || isActiveOrder(order))
+      .sort((a, b) => b.createdAt.localeCompare(a.createdAt))
+      .map((order) => toOrderDetails(order, context.auth.language));
+  },
 
-    // Get user's cart
-    const cart = getCart(userId);
+  /**
+   * Page of a restaurant's orders (superadmin or channel admin)
+   */
+  restaurantOrdersConnection: async (
+    _: any,
+    args: {
+      restaurantId: string;
+      activeOnly?: boolean;
+      first?: number | null;
+      after?: string | null;
+    },
+    context: GraphQLContext,
+  ): Promise<Connection<OrderDetails>> => {
+    if (!args.restaurantId) {
+      throw badUserInputError("Restaurant is required", "restaurantId");
+    }
+    await requireRestaurantAdmin(context, args.restaurantId);
+    const budget = createCostBudget(QUERY_NODE_COSTS.order, getPageSize(args.first));
+    const page = await fetchOrdersPage(
+      toRestaurantOrderFilter(args),
+      args.after ?? null,
+      budget,
+    );
+    const edges = page.orders
+      .map((order, i) => ({ cursor: page.cursors[i], order }))
+      .filter((edge) => !args.activeOnly || isActiveOrder(edge.order))
+      .map((edge) => ({
+        cursor: edge.cursor,
+        node: toOrderDetails(edge.order, context.auth.language),
+      }));
+    return {
+      edges,
+      pageInfo: { hasNextPage: page.endCursor !== null, endCursor: page.endCursor },
+    };
+  },
 
-    // If cart items provided in input, use those; otherwise use cart
-    let orderItems = args.input.items;
-    let orderRestaurantId = args.input.restaurantId;
+  /**
+   * Price preview for a placeOrder input; empty items quote the cart
+   */
+  quoteOrder: async (
+    _: any,
+    args: { input: PlaceOrderInput },
+    context: GraphQLContext,
+  ): Promise<OrderQuote> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    assertValidArgs(args, MUTATION_INPUT_RULES.placeOrder);
 
-    // If no items in input, use cart items
-    if (!orderItems || orderItems.length === 0) {
+    let items = args.input.items;
+    let restaurantId = args.input.restaurantId;
+    if (!items || items.length === 0) {
+      const cart = getCart(context.auth.userId);
       if (cart.items.length === 0) {
-        throw badUserInputError(
-          "Cart is empty. Add items to your cart before placing an order.",
-          "items",
-        );
+        throw badUserInputError("Cart is empty", "items");
       }
-
-      // Map cart items to order items
-      orderItems = cart.items.map((item) => ({
+      items = cart.items.map((item) => ({
         dishId: item.dishId,
         quantity: item.quantity,
-        notes: undefined,
       }));
-      orderRestaurantId = cart.restaurantId || args.input.restaurantId;
+      restaurantId = cart.restaurantId || restaurantId;
     }
 
-    // Validate delivery location
-    if (!args.input.deliveryLocation?.address) {
-      throw badUserInputError("Delivery address is required", "deliveryLocation");
+    const quote = await quoteOrder({ ...args.input, restaurantId, items });
+    return {
+      ...quote,
+      totalMoney: toMoney(quote.total, quote.currency, auth.language),
+    };
+  },
+
+  /**
+   * Check a promo code for a restaurant and preview its discount
+   */
+  validatePromoCode: async (
+    _: any,
+    args: { code: string; restaurantId: string },
+    context: GraphQLContext,
+  ): Promise<PromoCodeValidation> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
     }
+    if (!args.code?.trim()) {
+      throw badUserInputError("Promo code is required", "code");
+    }
+    if (!args.restaurantId) {
+      throw badUserInputError("Restaurant is required", "restaurantId");
+    }
+    return validatePromoCode(args.code, args.restaurantId);
+  },
 
-    // Build order input with cart items
-    const orderInput: PlaceOrderInput = {
-      restaurantId: orderRestaurantId,
-      deliveryLocation: args.input.deliveryLocation,
-      items: orderItems,
-      customerNote: args.input.customerNote,
-    };
+  /**
+   * Remaining balance of a gift card, looked up by its code
+   * Lookups that find no card count towards the user's lockout
+   */
+  giftCardBalance: async (
+    _: any,
+    args: { code: string },
+    context: GraphQLContext,
+  ): Promise<GiftCardBalance> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (!args.code?.trim()) {
+      throw badUserInputError("Gift card code is required", "code");
+    }
+    const card = await lookupGiftCard(auth.userId, args.code);
+    if (!card) {
+      logger.warn("gift_card_not_found", { userId: auth.userId });
+      throw notFoundError("Gift card not found");
+    }
+    return card;
+  },
+
+  /**
+   * Current user's loyalty point balance
+   */
+  loyaltyBalance: async (
+    _: any,
+    __: any,
+    context: GraphQLContext,
+  ): Promise<LoyaltyBalance> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    return getLoyaltyBalance(auth.userId);
+  },
+
+  /**
+   * Open delivery slots of a restaurant on a local date
+   */
+  deliverySlots: async (
+    _: any,
+    args: { restaurantId: string; date: string },
+    context: GraphQLContext,
+  ): Promise<DeliverySlot[]> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (!args.restaurantId) {
+      throw badUserInputError("Restaurant is required", "restaurantId");
+    }
+    return getDeliverySlots(args.restaurantId, args.date || "");
+  },
+
+  /**
+   * Payment status of one of the current user's orders
+   */
+  paymentStatus: async (
+    _: any,
+    args: { orderId: string },
+    context: GraphQLContext,
+  ): Promise<OrderPaymentStatus> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (!args.orderId) {
+      throw badUserInputError("Order is required", "orderId");
+    }
+    return getPaymentStatus(args.orderId, auth.userId);
+  },
 
-    // Validate that we have a restaurantId after building orderInput
-    if (!orderInput.restaurantId) {
+  /**
+   * Payment methods a restaurant accepts
+   */
+  paymentMethods: async (
+    _: any,
+    args: { restaurantId: string },
+    context: GraphQLContext,
+  ): Promise<PaymentMethod[]> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (!args.restaurantId) {
       throw badUserInputError("Restaurant is required", "restaurantId");
     }
+    return listPaymentMethods(args.restaurantId);
+  },
 
-    // Create mock Saleor order
-    const result = await createSaleorOrder(
-      orderInput,
-      userId,
-      userName,
-      userLanguage,
-    );
+  // ============================================================
+  // Staff Query Resolvers
+  // ============================================================
 
-    if (!result.success || !result.order) {
-      const errorMsg = result.error || "Failed to create order";
-      console.error(
-        `[Resolver] placeOrder failed for user ${userId}: ${errorMsg}`,
-      );
-      throw badUserInputError(errorMsg);
+  /**
+   * Couriers and staff of a restaurant (superadmin or channel admin)
+   */
+  restaurantStaff: async (
+    _: any,
+    args: { restaurantId: string },
+    context: GraphQLContext,
+  ): Promise<StaffMember[]> => {
+    if (!args.restaurantId) {
+      throw badUserInputError("Restaurant is required", "restaurantId");
     }
+    await requireRestaurantAdmin(context, args.restaurantId);
+    return listRestaurantStaff(args.restaurantId);
+  },
 
-    // Clear cart after successful order
-    clearCart(userId);
-    console.log(
-      `[Resolver] Cart cleared for user ${userId} after order ${result.order.id}`,
-    );
+  /**
+   * Restaurants where the current user is courier or staff
+   */
+  myStaffRoles: async (
+    _: any,
+    __: any,
+    context: GraphQLContext,
+  ): Promise<StaffMember[]> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    return listUserStaffRoles(auth.userId);
+  },
+
+  // ============================================================
+  // Operation Audit Query Resolvers
+  // ============================================================
+
+  /**
+   * List distinct GraphQL operations recorded in audit mode (superadmin only)
+   */
+  operationAudit: async (
+    _: any,
+    args: { onlyUnlisted?: boolean },
+    context: GraphQLContext,
+  ): Promise<OperationAuditRecord[]> => {
+    const auth = requireSuperadmin(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("superadmin_required", context.auth.userId);
+      throw forbiddenError();
+    }
+    return listAuditedOperations(args.onlyUnlisted === true);
+  },
+
+  /**
+   * Draft vs checkout pipeline shadow comparisons (superadmin only)
+   */
+  orderPipelineShadowReport: async (
+    _: any,
+    __: any,
+    context: GraphQLContext,
+  ): Promise<OrderPipelineShadowReport> => {
+    const auth = requireSuperadmin(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("superadmin_required", context.auth.userId);
+      throw forbiddenError();
+    }
+    return getShadowReport();
+  },
+
+  /**
+   * Tax configuration for a restaurant channel
+   */
+  taxConfiguration: async (
+    _: any,
+    args: { restaurantId: string },
+    context: GraphQLContext,
+  ): Promise<TaxConfiguration> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (!args.restaurantId) {
+      throw badUserInputError("Restaurant is required", "restaurantId");
+    }
+    return fetchTaxConfiguration(args.restaurantId);
+  },
+
+  // ============================================================
+  // Order Issue Query Resolvers
+  // ============================================================
+
+  /**
+   * Issue triage queue, most urgent SLA first (superadmin or channel admin)
+   * Omitting restaurantId lists all restaurants and requires superadmin
+   */
+  orderIssues: async (
+    _: any,
+    args: { restaurantId?: string; status?: OrderIssueStatus },
+    context: GraphQLContext,
+  ): Promise<OrderIssue[]> => {
+    if (args.restaurantId) {
+      await requireRestaurantAdmin(context, args.restaurantId);
+    } else {
+      const auth = requireSuperadmin(context.auth);
+      if (!auth.valid) {
+        logger.authFailure("superadmin_required", context.auth.userId);
+        throw forbiddenError();
+      }
+    }
+    return listOrderIssues(args.restaurantId, args.status || "OPEN");
+  },
+
+  /**
+   * Orders flagged by abuse heuristics (superadmin or channel admin)
+   * Omitting restaurantId lists all restaurants and requires superadmin
+   */
+  abuseReviewQueue: async (
+    _: any,
+    args: { restaurantId?: string; status?: AbuseFlagStatus; userId?: string },
+    context: GraphQLContext,
+  ): Promise<AbuseFlag[]> => {
+    if (args.restaurantId) {
+      await requireRestaurantAdmin(context, args.restaurantId);
+    } else {
+      const auth = requireSuperadmin(context.auth);
+      if (!auth.valid) {
+        logger.authFailure("superadmin_required", context.auth.userId);
+        throw forbiddenError();
+      }
+    }
+    return listAbuseFlags(args.restaurantId, args.status || "PENDING", args.userId);
+  },
+
+  /**
+   * Duplicate, orphan and cross-listed catalog objects (superadmin only)
+   */
+  catalogHealthReport: async (
+    _: any,
+    __: any,
+    context: GraphQLContext,
+  ): Promise<CatalogHealthReport> => {
+    const auth = requireSuperadmin(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("superadmin_required", context.auth.userId);
+      throw forbiddenError();
+    }
+    return buildCatalogHealthReport();
+  },
+
+  /**
+   * Recorded menu prices of a dish, newest first (superadmin or channel admin)
+   */
+  priceHistory: async (
+    _: any,
+    args: { restaurantId: string; dishId: string },
+    context: GraphQLContext,
+  ): Promise<PriceSnapshot[]> => {
+    await requireRestaurantAdmin(context, args.restaurantId);
+    return getPriceHistory(args.restaurantId, args.dishId);
+  },
+
+  // ============================================================
+  // Favorites Query Resolvers
+  // ============================================================
+
+  /**
+   * The current user's saved restaurants and dishes, most recent first
+   */
+  favorites: async (
+    _: any,
+    args: { kind?: FavoriteKind | null },
+    context: GraphQLContext,
+  ): Promise<FavoriteEntry[]> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    const entries = await resolveFavorites(
+      await listFavorites(auth.userId, args?.kind ?? undefined),
+    );
+    return entries.map((entry) => ({
+      ...entry,
+      restaurant: entry.restaurant
+        ? localizeRestaurants([entry.restaurant], auth.language)[0]
+        : null,
+      dish: entry.dish ? localizeDishes([entry.dish], auth.language)[0] : null,
+    }));
+  },
+
+  // ============================================================
+  // Review Moderation Query Resolvers
+  // ============================================================
+
+  /**
+   * Reviews awaiting or past moderation (superadmin or channel admin)
+   * Omitting restaurantId lists all restaurants and requires superadmin
+   */
+  reviewQueue: async (
+    _: any,
+    args: { restaurantId?: string; status?: ReviewStatus },
+    context: GraphQLContext,
+  ): Promise<Review[]> => {
+    if (args.restaurantId) {
+      await requireRestaurantAdmin(context, args.restaurantId);
+    } else {
+      const auth = requireSuperadmin(context.auth);
+      if (!auth.valid) {
+        logger.authFailure("superadmin_required", context.auth.userId);
+        throw forbiddenError();
+      }
+    }
+    return listReviews(args.restaurantId, args.status || "PENDING");
+  },
+
+  /**
+   * Approved reviews of a restaurant, newest first
+   */
+  restaurantReviews: async (
+    _: any,
+    args: { restaurantId: string; first?: number | null; after?: string | null },
+    context: GraphQLContext,
+  ): Promise<Connection<RestaurantReview>> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (!args.restaurantId) {
+      throw badUserInputError("Restaurant is required", "restaurantId");
+    }
+    return listRestaurantReviews(args.restaurantId, args.first, args.after);
+  },
+
+  // ============================================================
+  // Broadcast Query Resolvers
+  // ============================================================
+
+  /**
+   * Broadcasts with delivery stats, newest first (superadmin only)
+   */
+  broadcasts: async (
+    _: any,
+    __: any,
+    context: GraphQLContext,
+  ): Promise<Broadcast[]> => {
+    const auth = requireSuperadmin(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("superadmin_required", context.auth.userId);
+      throw forbiddenError();
+    }
+    return listBroadcasts();
+  },
+
+  /**
+   * Single broadcast by ID (superadmin only)
+   */
+  broadcast: async (
+    _: any,
+    args: { broadcastId: string },
+    context: GraphQLContext,
+  ): Promise<Broadcast | null> => {
+    const auth = requireSuperadmin(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("superadmin_required", context.auth.userId);
+      throw forbiddenError();
+    }
+    return getBroadcast(args.broadcastId);
+  },
+
+  /**
+   * Bot messages Telegram refused, newest first (superadmin only)
+   */
+  notificationFailures: async (
+    _: any,
+    args: { filter?: NotificationFailureFilter | null; first?: number | null },
+    context: GraphQLContext,
+  ): Promise<NotificationFailure[]> => {
+    const auth = requireSuperadmin(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("superadmin_required", context.auth.userId);
+      throw forbiddenError();
+    }
+    return listNotificationFailures(args.filter || {}, args.first ?? undefined);
+  },
+
+  // ============================================================
+  // Commission & Payout Query Resolvers
+  // ============================================================
+
+  /**
+   * Commission rate for a restaurant (superadmin or channel admin)
+   */
+  commissionRate: async (
+    _: any,
+    args: { restaurantId: string },
+    context: GraphQLContext,
+  ): Promise<CommissionRate> => {
+    await requireRestaurantAdmin(context, args.restaurantId);
+    return getCommissionRate(args.restaurantId);
+  },
+
+  /**
+   * Payout report for a restaurant over a period (superadmin or channel admin)
+   */
+  payoutReport: async (
+    _: any,
+    args: { restaurantId: string; from: string; to: string },
+    context: GraphQLContext,
+  ): Promise<PayoutReport[]> => {
+    await requireRestaurantAdmin(context, args.restaurantId);
+    const { periodStart, periodEnd } = normalizePeriod(args.from, args.to);
+    return buildPayoutReports(args.restaurantId, periodStart, periodEnd);
+  },
+
+  /**
+   * CSV export of payout reports
+   * Without restaurantId covers all restaurants (superadmin only)
+   */
+  payoutReportCsv: async (
+    _: any,
+    args: { restaurantId?: string; from: string; to: string },
+    context: GraphQLContext,
+  ): Promise<string> => {
+    if (args.restaurantId) {
+      await requireRestaurantAdmin(context, args.restaurantId);
+    } else {
+      const auth = requireSuperadmin(context.auth);
+      if (!auth.valid) {
+        logger.authFailure("superadmin_required", context.auth.userId);
+        throw forbiddenError();
+      }
+    }
+    const { periodStart, periodEnd } = normalizePeriod(args.from, args.to);
+    const restaurantIds = args.restaurantId
+      ? [args.restaurantId]
+      : (await fetchChannels()).map((channel) => channel.id);
+
+    const reports: PayoutReport[] = [];
+    for (const restaurantId of restaurantIds) {
+      reports.push(
+        ...(await buildPayoutReports(restaurantId, periodStart, periodEnd)),
+      );
+    }
+    return payoutReportsToCsv(reports);
+  },
+
+  // ============================================================
+  // Phase 3: Cart Query Resolvers
+  // ============================================================
+  /**
+   * Get current user's cart
+   * Returns cart with items, total price, and item count
+   */
+  cart: async (
+    _: any,
+    __: any,
+    context: GraphQLContext,
+  ): Promise<{
+    restaurantId: string | null;
+    items: any[];
+    total: number;
+    totalMoney: Money | null;
+    itemCount: number;
+  }> => {
+    const auth = requireRead(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    const userId = context.auth.userId;
+    console.log(`[Resolver] cart for user ${userId}`);
+
+    const cart = getCart(userId);
+    const total = getCartTotal(userId);
+    const itemCount = getCartItemCount(userId);
+
+    return {
+      restaurantId: cart.restaurantId || null,
+      items: cart.items,
+      total,
+      totalMoney:
+        cart.items.length > 0
+          ? toMoney(total, cart.items[0].currency || "USD", auth.language)
+          : null,
+      itemCount,
+    };
+  },
+};
+
+/**
+ * Mutation resolvers with auth context
+ */
+const mutationResolvers = {
+  /**
+   * Place an order
+   * Uses cart items and authenticated user from context
+   * Creates a mock Saleor draft order
+   * Clears cart after successful order
+   */
+  placeOrder: async (
+    _: any,
+    args: { input: PlaceOrderInput },
+    context: GraphQLContext,
+  ): Promise<PlaceOrderPayload> => {
+    // Enforce write permission for mutations
+    const auth = requireWrite(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", auth.userId);
+      throw forbiddenError();
+    }
+    const userId = auth.userId;
+    const userName = auth.name;
+    const userLanguage = auth.language;
+    console.log(
+      `[Resolver] placeOrder by user ${userId} (${userName}, lang: ${userLanguage})`,
+    );
+
+    // Validate input
+    if (!args.input.restaurantId) {
+      throw badUserInputError("Restaurant is required", "restaurantId");
+    }
+
+    // Get user's cart
+    const cart = getCart(userId);
+
+    // If cart items provided in input, use those; otherwise use cart
+    let orderItems = args.input.items;
+    let orderRestaurantId = args.input.restaurantId;
+
+    // If no items in input, use cart items
+    if (!orderItems || orderItems.length === 0) {
+      if (cart.items.length === 0) {
+        throw badUserInputError(
+          "Cart is empty. Add items to your cart before placing an order.",
+          "items",
+        );
+      }
+
+      // Map cart items to order items
+      orderItems = cart.items.map((item) => ({
+        dishId: item.dishId,
+        quantity: item.quantity,
+        notes: undefined,
+      }));
+      // The cart is tied to the restaurant it was started in; ordering it
+      // from another one would silently switch restaurants
+      if (
+        cart.restaurantId &&
+        args.input.restaurantId &&
+        cart.restaurantId !== args.input.restaurantId
+      ) {
+        throw mixedRestaurantsError(
+          orderItems.map((item) => ({
+            dishId: item.dishId,
+            reason: "OTHER_RESTAURANT",
+            restaurantId: cart.restaurantId!,
+          })),
+        );
+      }
+      orderRestaurantId = cart.restaurantId || args.input.restaurantId;
+    }
+
+    // Pickup orders don't need a delivery location; deliveries do
+    const fulfillmentType = normalizeFulfillmentType(args.input.fulfillmentType);
+    if (fulfillmentType === "DELIVERY" && !args.input.deliveryLocation?.address) {
+      throw badUserInputError("Delivery address is required", "deliveryLocation");
+    }
+
+    // Validate that we have a restaurantId before building orderInput
+    if (!orderRestaurantId) {
+      throw badUserInputError("Restaurant is required", "restaurantId");
+    }
+
+    // Paused restaurants take no orders until the owner resumes them
+    const orderChannel = await fetchChannelById(orderRestaurantId);
+    assertAcceptingOrders(orderChannel);
+
+    // Every dish must belong to the restaurant (MIXED_RESTAURANTS lists the
+    // ones that don't) and still be published, purchasable and in stock in
+    // the channel; ITEMS_UNAVAILABLE lists the ones that aren't
+    const quantities: Record<string, number> = {};
+    for (const item of orderItems) {
+      quantities[item.dishId] = (quantities[item.dishId] || 0) + item.quantity;
+    }
+    // Promo codes are checked up front so invalid codes fail before Saleor.
+    // These checks run together (one Saleor request with SALEOR_BATCHING)
+    // and report the first failure in this order.
+    const [, pickupLocation, voucherCode] = await allInOrder([
+      assertDishesAvailable(
+        orderItems.map((item) => item.dishId),
+        orderRestaurantId,
+        "items",
+        quantities,
+      ),
+      fulfillmentType === "PICKUP" ? requirePickupLocation(orderRestaurantId) : undefined,
+      args.input.voucherCode
+        ? requireValidPromoCode(args.input.voucherCode, orderRestaurantId)
+        : undefined,
+    ]);
+
+    // Build order input with cart items
+    const orderInput: PlaceOrderInput = {
+      restaurantId: orderRestaurantId,
+      fulfillmentType,
+      deliveryLocation: pickupLocation ?? args.input.deliveryLocation,
+      items: orderItems,
+      customerNote: args.input.customerNote,
+      paymentMethod: args.input.paymentMethod || "ONLINE",
+    };
+    if (voucherCode) {
+      orderInput.voucherCode = voucherCode;
+    }
+
+    // Tips are capped by MAX_TIP_AMOUNT and rounded in the channel currency
+    if (args.input.tipAmount !== undefined && args.input.tipAmount !== null) {
+      orderInput.tipAmount = validateTipAmount(
+        args.input.tipAmount,
+        orderChannel?.currencyCode || "USD",
+      );
+    }
+
+    // The restaurant's service fee is charged as an order line
+    orderInput.serviceFee = getServiceFee(
+      orderChannel?.metadata,
+      orderChannel?.currencyCode || "USD",
+    );
+
+    // Gift cards must be usable and in the restaurant's currency
+    if (args.input.giftCardCode) {
+      orderInput.giftCardCode = await requireUsableGiftCard(
+        args.input.giftCardCode,
+        orderChannel?.currencyCode || "USD",
+        userId,
+      );
+    }
+
+    // Points become a draft order discount; gift cards need a checkout
+    if (args.input.redeemPoints) {
+      if (orderInput.giftCardCode) {
+        throw badUserInputError(
+          "Loyalty points can't be combined with a gift card",
+          "redeemPoints",
+        );
+      }
+      orderInput.loyaltyPoints = await requireRedeemablePoints(userId);
+    }
+
+    // Scheduled orders must fall within lead-time limits and opening hours;
+    // ASAP orders need the restaurant to be open now
+    if (args.input.scheduledFor) {
+      orderInput.scheduledFor = await validateScheduledFor(
+        args.input.scheduledFor,
+        orderInput.restaurantId,
+      );
+    } else {
+      validateAsapOrder(orderChannel?.metadata);
+    }
+
+    // Users and addresses with too many orders in progress are turned away
+    await assertOrderLimits(
+      userId,
+      fulfillmentType === "DELIVERY" ? orderInput.deliveryLocation : undefined,
+    );
+
+    // Kitchens at their active order cap turn new orders away for a while
+    await assertKitchenCapacity(orderChannel);
+
+    // Scheduled orders need room in their slot when the restaurant caps it
+    if (orderInput.scheduledFor) {
+      await assertSlotAvailable(orderChannel, orderInput.scheduledFor);
+    }
+
+    // ETA from restaurant/dish prep time + delivery buffer, stored in order metadata
+    orderInput.estimatedDeliveryAt = await estimateDeliveryAt(
+      orderInput.restaurantId,
+      fulfillmentType,
+      orderInput.scheduledFor,
+      undefined,
+      orderItems.map((item) => item.dishId),
+    );
+
+    // Demo deployments stop here with a simulated order; nothing is created
+    if (isDemoMode()) {
+      clearCart(userId);
+      return placeDemoOrder(orderInput, userId, userLanguage);
+    }
+
+    // Abuse heuristics run on the history before this order exists
+    const abuseSignals = await detectAbuseSignals(
+      userId,
+      fulfillmentType === "DELIVERY" ? orderInput.deliveryLocation : undefined,
+      orderChannel?.metadata,
+    );
+
+    // Points are debited before the order exists, so concurrent orders
+    // can't spend them twice; what the order doesn't use goes back
+    const reservedPoints = orderInput.loyaltyPoints
+      ? await reserveLoyaltyPoints(userId)
+      : 0;
+    orderInput.loyaltyPoints = reservedPoints || undefined;
+
+    // Create mock Saleor order
+    let result: CreateOrderResult;
+    try {
+      result = await createSaleorOrder(
+        orderInput,
+        userId,
+        userName,
+        userLanguage,
+      );
+    } catch (error) {
+      await releaseLoyaltyPoints(userId, reservedPoints, "order not placed");
+      throw error;
+    }
+    const usedPoints = (result.order && getLoyaltyPointsRedeemed(result.order)) || 0;
+    await releaseLoyaltyPoints(
+      userId,
+      reservedPoints - usedPoints,
+      result.order ? `unused on order ${result.order.id}` : "order not placed",
+    );
+
+    if (!result.success || !result.order) {
+      const errorMsg = result.error || "Failed to create order";
+      console.error(
+        `[Resolver] placeOrder failed for user ${userId}: ${errorMsg}`,
+      );
+      // Stock, missing objects and permissions get their own codes
+      const mapped = result.saleorError ? mapSaleorError(result.saleorError) : null;
+      throw mapped ?? badUserInputError(errorMsg);
+    }
+
+    // Hold the delivery slot for scheduled orders
+    let slotStart: string | undefined;
+    if (orderInput.scheduledFor) {
+      slotStart = await reserveSlot(
+        orderInput.restaurantId,
+        orderInput.scheduledFor,
+        result.order.id,
+      );
+    }
+
+    // Flagged orders go to the admin review queue (and wait for approval
+    // with ABUSE_REQUIRE_APPROVAL)
+    if (abuseSignals.length > 0) {
+      await flagOrderForReview(result.order, userId, orderInput.restaurantId, abuseSignals);
+    }
+
+    // Pay-before-completion: unpaid orders are cancelled after the deadline
+    // (cash and card-on-delivery orders are paid at handover)
+    if (isPaymentRequired() && orderInput.paymentMethod === "ONLINE") {
+      await startPaymentDeadline(
+        result.order,
+        userId,
+        orderInput.restaurantId,
+        slotStart,
+      );
+    }
+
+    // Receipt message carries the restaurant's current announcement
+    const channel = await fetchChannelById(orderInput.restaurantId);
+    const timeZone = channel?.metadata?.[TIMEZONE_METADATA_KEY]?.trim();
+    await notifyOrderPlaced(
+      userId,
+      toOrderDetails(result.order, userLanguage),
+      channel?.name || "the restaurant",
+      {
+        announcement: channel ? getActiveAnnouncement(channel) : null,
+        languageCode: userLanguage,
+        timeZone: timeZone && isValidTimezone(timeZone) ? timeZone : undefined,
+      },
+    );
+
+    // Clear cart after successful order
+    clearCart(userId);
+    console.log(
+      `[Resolver] Cart cleared for user ${userId} after order ${result.order.id}`,
+    );
+    await invalidateRecentDishes(userId);
+
+    // Return GraphQL payload
+    const payload = toPlaceOrderPayload(result.order);
+    // The remaining balance is a nicety; skip it when the deadline is near
+    if (orderInput.giftCardCode && getRemainingBudgetMs(context) > 1000) {
+      const card = await fetchGiftCardBalance(orderInput.giftCardCode);
+      payload.giftCardBalance = card?.balance;
+    }
+    return payload;
+  },
+
+  // ============================================================
+  // Phase 3: Cart Mutation Resolvers
+  // ============================================================
+
+  /**
+   * Add item to cart
+   * If restaurant changes, clears existing cart items
+   */
+  addToCart: async (
+    _: any,
+    args: { input: AddToCartInput },
+    context: GraphQLContext,
+  ): Promise<{
+    restaurantId: string | null;
+    items: any[];
+    total: number;
+    itemCount: number;
+  }> => {
+    const auth = requireWrite(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    const userId = auth.userId;
+    const userName = auth.name;
+    console.log(
+      `[Resolver] addToCart for user ${userId} (${userName}), dish ${args.input.dishId}, quantity ${args.input.quantity}`,
+    );
+
+    const channelId = args.input.channelId || args.input.restaurantId;
+    if (channelId) {
+      await assertDishesAvailable([args.input.dishId], channelId, "dishId", {
+        [args.input.dishId]: args.input.quantity,
+      });
+    }
+
+    const cart = addToCart(userId, args.input);
+    const total = getCartTotal(userId);
+    const itemCount = getCartItemCount(userId);
+
+    return {
+      restaurantId: cart.restaurantId || null,
+      items: cart.items,
+      total,
+      itemCount,
+    };
+  },
+
+  /**
+   * Update quantity of cart item
+   * Set quantity to 0 to remove item
+   */
+  updateCartItem: async (
+    _: any,
+    args: { input: UpdateCartItemInput },
+    context: GraphQLContext,
+  ): Promise<{
+    restaurantId: string | null;
+    items: any[];
+    total: number;
+    itemCount: number;
+  }> => {
+    const auth = requireWrite(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    const userId = auth.userId;
+    const userName = auth.name;
+    console.log(
+      `[Resolver] updateCartItem for user ${userId} (${userName}), dish ${args.input.dishId}, quantity ${args.input.quantity}`,
+    );
+
+    const currentCart = getCart(userId);
+    if (args.input.quantity > 0 && currentCart.restaurantId) {
+      await assertDishesAvailable(
+        [args.input.dishId],
+        currentCart.restaurantId,
+        "dishId",
+        { [args.input.dishId]: args.input.quantity },
+      );
+    }
+
+    const cart = updateCartItem(userId, args.input);
+    const total = getCartTotal(userId);
+    const itemCount = getCartItemCount(userId);
+
+    return {
+      restaurantId: cart.restaurantId || null,
+      items: cart.items,
+      total,
+      itemCount,
+    };
+  },
+
+  /**
+   * Remove item from cart
+   */
+  removeCartItem: async (
+    _: any,
+    args: { dishId: string },
+    context: GraphQLContext,
+  ): Promise<{
+    restaurantId: string | null;
+    items: any[];
+    total: number;
+    itemCount: number;
+  }> => {
+    const auth = requireWrite(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", auth.userId);
+      throw forbiddenError();
+    }
+    const userId = auth.userId;
+    const userName = auth.name;
+    console.log(
+      `[Resolver] removeCartItem for user ${userId} (${userName}), dish ${args.dishId}`,
+    );
+
+    const cart = removeFromCart(userId, args.dishId);
+    const total = getCartTotal(userId);
+    const itemCount = getCartItemCount(userId);
+
+    return {
+      restaurantId: cart.restaurantId || null,
+      items: cart.items,
+      total,
+      itemCount,
+    };
+  },
+
+  /**
+   * Clear entire cart
+   */
+  clearCart: async (
+    _: any,
+    __: any,
+    context: GraphQLContext,
+  ): Promise<{
+    restaurantId: string | null;
+    items: any[];
+    total: number;
+    itemCount: number;
+  }> => {
+    const auth = requireWrite(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", auth.userId);
+      throw forbiddenError();
+    }
+    const userId = auth.userId;
+    const userName = auth.name;
+    console.log(`[Resolver] clearCart for user ${userId} (${userName})`);
+
+    clearCart(userId);
+
+    return {
+      restaurantId: null,
+      items: [],
+      total: 0,
+      itemCount: 0,
+    };
+  },
+
+  // ============================================================
+  // Phase 10: Superadmin & Channel Admin Mutation Resolvers
+  // ============================================================
+
+  /**
+   * Link channel to telegram user as admin (superadmin only)
+   */
+  linkChannelToTelegram: async (
+    _: any,
+    args: { input: LinkChannelInput },
+    context: GraphQLContext,
+  ): Promise<{
+    success: boolean;
+    channelAdmin: {
+      restaurantId: string;
+      telegramUserId: string;
+      assignedAt: string;
+      assignedBy: string;
+    } | null;
+  }> => {
+    const auth = requireSuperadmin(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("superadmin_required", context.auth.userId);
+      throw forbiddenError();
+    }
+    const { restaurantId, telegramUserId } = args.input;
+    console.log(
+      `[Resolver] linkChannelToTelegram: ${restaurantId} -> ${telegramUserId} by superadmin ${context.auth.userId}`,
+    );
+
+    const admin = await setChannelAdmin(
+      restaurantId,
+      telegramUserId,
+      context.auth.userId,
+    );
+
+    return {
+      success: true,
+      channelAdmin: toChannelAdminInfo(admin),
+    };
+  },
+
+  /**
+   * Unlink channel from telegram admin (superadmin only)
+   */
+  unlinkChannel: async (
+    _: any,
+    args: { input: UnlinkChannelInput },
+    context: GraphQLContext,
+  ): Promise<{ success: boolean }> => {
+    const auth = requireSuperadmin(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("superadmin_required", context.auth.userId);
+      throw forbiddenError();
+    }
+    const { restaurantId } = args.input;
+    console.log(
+      `[Resolver] unlinkChannel: ${restaurantId} by superadmin ${context.auth.userId}`,
+    );
+
+    await removeChannelAdmin(restaurantId);
+
+    return { success: true };
+  },
+
+  // ============================================================
+  // Telegram Payments Mutation Resolvers
+  // ============================================================
+
+  /**
+   * Create a Telegram invoice link for one of the current user's orders
+   */
+  createInvoice: async (
+    _: any,
+    args: { orderId: string },
+    context: GraphQLContext,
+  ): Promise<CreateInvoicePayload> => {
+    const auth = requireWrite(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (!args.orderId) {
+      throw badUserInputError("Order is required", "orderId");
+    }
+    return createInvoice(args.orderId, auth.userId);
+  },
+
+  /**
+   * Start paying one of the current user's orders with a payment method
+   */
+  initPayment: async (
+    _: any,
+    args: { orderId: string; method: string },
+    context: GraphQLContext,
+  ): Promise<InitPaymentPayload> => {
+    const auth = requireWrite(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    return initPayment(args.orderId, args.method, auth.userId);
+  },
+
+  /**
+   * Accept an order, capturing a held payment (superadmin or channel admin)
+   */
+  acceptOrder: async (
+    _: any,
+    args: { orderId: string },
+    context: GraphQLContext,
+  ): Promise<OrderDecisionPayload> => {
+    await requireOrderRestaurantRole(context, args.orderId, requireRestaurantAdmin);
+    return acceptOrder(args.orderId, context.auth.userId);
+  },
+
+  /**
+   * Reject an order, voiding a held payment (superadmin or channel admin)
+   */
+  rejectOrder: async (
+    _: any,
+    args: { orderId: string; reason: string; reasonCode?: CancellationReasonCode | null },
+    context: GraphQLContext,
+  ): Promise<OrderDecisionPayload> => {
+    await requireOrderRestaurantRole(context, args.orderId, requireRestaurantAdmin);
+    if (!args.reason?.trim()) {
+      throw badUserInputError("Reason is required", "reason");
+    }
+    const reasonCode = args.reasonCode ?? "RESTAURANT_REJECTED";
+    if (!RESTAURANT_CANCELLATION_REASONS.includes(reasonCode)) {
+      throw badUserInputError(
+        `reasonCode must be one of ${RESTAURANT_CANCELLATION_REASONS.join(", ")}`,
+        "reasonCode",
+      );
+    }
+    return rejectOrder(args.orderId, args.reason.trim(), context.auth.userId, reasonCode);
+  },
+
+  /**
+   * Cancel the user's own order before the restaurant accepts it
+   */
+  cancelOrder: async (
+    _: any,
+    args: { orderId: string; note?: string | null },
+    context: GraphQLContext,
+  ): Promise<OrderDecisionPayload> => {
+    const auth = requireWrite(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    return cancelUserOrder(args.orderId, auth.userId, args.note?.trim() || null);
+  },
+
+  /**
+   * Check the customer's handoff code before handing an order over
+   * (superadmin, channel admin or the restaurant's staff and couriers)
+   */
+  verifyHandoffCode: async (
+    _: any,
+    args: { input: VerifyHandoffCodeInput },
+    context: GraphQLContext,
+  ): Promise<HandoffVerification> => {
+    const { orderId, code } = resolveHandoffInput(args.input || {});
+    await requireOrderRestaurantRole(context, orderId, requireRestaurantStaff);
+    return verifyHandoffCode(orderId, code, context.auth.userId);
+  },
+
+  /**
+   * Mark an order delivered, creating its Saleor fulfillment
+   * (superadmin, channel admin or the restaurant's staff and couriers)
+   */
+  markOrderDelivered: async (
+    _: any,
+    args: { orderId: string },
+    context: GraphQLContext,
+  ): Promise<OrderDelivery> => {
+    await requireOrderRestaurantRole(context, args.orderId, requireRestaurantStaff);
+    return markOrderDelivered(args.orderId, context.auth.userId);
+  },
+
+  // ============================================================
+  // Staff Invitation Mutation Resolvers
+  // ============================================================
+
+  /**
+   * Create a one-time courier/staff invite (superadmin or channel admin)
+   */
+  createStaffInvite: async (
+    _: any,
+    args: { input: CreateStaffInviteInput },
+    context: GraphQLContext,
+  ): Promise<StaffInvite> => {
+    await requireRestaurantAdmin(context, args.input.restaurantId);
+    return createStaffInvite(args.input.restaurantId, args.input.role, context.auth.userId);
+  },
+
+  /**
+   * Redeem an invite, granting its role to the current user
+   */
+  acceptStaffInvite: async (
+    _: any,
+    args: { token: string },
+    context: GraphQLContext,
+  ): Promise<StaffMember> => {
+    const auth = requireWrite(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    return acceptStaffInvite(args.token, auth.userId);
+  },
+
+  /**
+   * Remove a courier or staff member (superadmin or channel admin)
+   */
+  revokeStaffRole: async (
+    _: any,
+    args: { input: RevokeStaffRoleInput },
+    context: GraphQLContext,
+  ): Promise<boolean> => {
+    await requireRestaurantAdmin(context, args.input.restaurantId);
+    const revoked = await revokeStaffRole(
+      args.input.restaurantId,
+      args.input.telegramUserId,
+      context.auth.userId,
+    );
+    if (!revoked) {
+      throw notFoundError("Staff member not found");
+    }
+    return true;
+  },
+
+  /**
+   * Opt the current user in or out of the daily owner digest
+   */
+  setDailyDigestEnabled: async (
+    _: any,
+    args: { enabled: boolean },
+    context: GraphQLContext,
+  ): Promise<boolean> => {
+    const auth = requireWrite(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    return setDigestEnabled(auth.userId, args.enabled);
+  },
+
+  // ============================================================
+  // Order Issue Mutation Resolvers
+  // ============================================================
+
+  /**
+   * Report a problem with one of the current user's orders
+   */
+  reportOrderIssue: async (
+    _: any,
+    args: { input: ReportOrderIssueInput },
+    context: GraphQLContext,
+  ): Promise<OrderIssue> => {
+    const auth = requireWrite(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("permission_denied", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (!args.input?.orderId) {
+      throw badUserInputError("Order is required", "orderId");
+    }
+    return reportOrderIssue(args.input, auth.userId);
+  },
+
+  /**
+   * Close an issue with a canned resolution (superadmin or channel admin)
+   */
+  resolveOrderIssue: async (
+    _: any,
+    args: { input: ResolveOrderIssueInput },
+    context: GraphQLContext,
+  ): Promise<OrderIssue> => {
+    const restaurantId = await getOrderIssueRestaurantId(args.input?.issueId);
+    await requireRestaurantAdmin(context, restaurantId);
+    return resolveOrderIssue(args.input, context.auth.userId);
+  },
+
+  /**
+   * Approve or reject an order flagged for abuse (superadmin or channel admin)
+   */
+  reviewAbuseFlag: async (
+    _: any,
+    args: { input: ReviewAbuseFlagInput },
+    context: GraphQLContext,
+  ): Promise<AbuseFlag> => {
+    const restaurantId = await getAbuseFlagRestaurantId(args.input?.flagId);
+    await requireRestaurantAdmin(context, restaurantId);
+    return reviewAbuseFlag(args.input, context.auth.userId);
+  },
 
-    // Return GraphQL payload
-    return toPlaceOrderPayload(result.order);
+  /**
+   * Issue a compensation voucher for an order (superadmin or channel admin)
+   */
+  issueCompensationVoucher: async (
+    _: any,
+    args: { input: IssueCompensationVoucherInput },
+    context: GraphQLContext,
+  ): Promise<CompensationVoucher> => {
+    const restaurantId = await getOrderVoucherRestaurantId(args.input?.orderId);
+    await requireRestaurantAdmin(context, restaurantId);
+    if (!args.input.reason?.trim()) {
+      throw badUserInputError("Reason is required", "reason");
+    }
+    return issueCompensationVoucher(args.input.orderId, {
+      value: args.input.value ?? undefined,
+      reason: args.input.reason.trim(),
+      issuedBy: context.auth.userId,
+    });
   },
 
   // ============================================================
-  // Phase 3: Cart Mutation Resolvers
+  // Review Mutation Resolvers
   // ============================================================
 
   /**
-   * Add item to cart
-   * If restaurant changes, clears existing cart items
+   * Review one of the current user's completed orders
    */
-  addToCart: async (
+  submitReview: async (
     _: any,
-    args: { input: AddToCartInput },
+    args: { input: SubmitReviewInput },
     context: GraphQLContext,
-  ): Promise<{
-    restaurantId: string | null;
-    items: any[];
-    total: number;
-    itemCount: number;
-  }> => {
+  ): Promise<Review> => {
     const auth = requireWrite(context.auth);
     if (!auth.valid) {
       logger.authFailure("permission_denied", context.auth.userId);
       throw forbiddenError();
     }
-    const userId = auth.userId;
-    const userName = auth.name;
-    console.log(
-      `[Resolver] addToCart for user ${userId} (${userName}), dish ${args.input.dishId}, quantity ${args.input.quantity}`,
-    );
-
-    const cart = addToCart(userId, args.input);
-    const total = getCartTotal(userId);
-    const itemCount = getCartItemCount(userId);
-
-    return {
-      restaurantId: cart.restaurantId || null,
-      items: cart.items,
-      total,
-      itemCount,
-    };
+    if (!args.input?.orderId) {
+      throw badUserInputError("Order is required", "orderId");
+    }
+    return submitReview(args.input, auth.userId, auth.name);
   },
 
   /**
-   * Update quantity of cart item
-   * Set quantity to 0 to remove item
+   * Rate one of the current user's completed orders with 1-5 stars
+   * Deprecated in favour of submitReview
    */
-  updateCartItem: async (
+  rateOrder: async (
     _: any,
-    args: { input: UpdateCartItemInput },
+    args: { orderId: string; stars: number; comment?: string | null },
     context: GraphQLContext,
-  ): Promise<{
-    restaurantId: string | null;
-    items: any[];
-    total: number;
-    itemCount: number;
-  }> => {
+  ): Promise<Review> => {
     const auth = requireWrite(context.auth);
     if (!auth.valid) {
       logger.authFailure("permission_denied", context.auth.userId);
       throw forbiddenError();
     }
-    const userId = auth.userId;
-    const userName = auth.name;
-    console.log(
-      `[Resolver] updateCartItem for user ${userId} (${userName}), dish ${args.input.dishId}, quantity ${args.input.quantity}`,
+    return submitReview(
+      { orderId: args.orderId, rating: args.stars, comment: args.comment ?? undefined },
+      auth.userId,
+      auth.name,
     );
+  },
 
-    const cart = updateCartItem(userId, args.input);
-    const total = getCartTotal(userId);
-    const itemCount = getCartItemCount(userId);
+  /**
+   * Approve or hide a review (superadmin or channel admin)
+   */
+  moderateReview: async (
+    _: any,
+    args: { input: ModerateReviewInput },
+    context: GraphQLContext,
+  ): Promise<Review> => {
+    const restaurantId = await getReviewRestaurantId(args.input?.reviewId);
+    await requireRestaurantAdmin(context, restaurantId);
+    return moderateReview(args.input, context.auth.userId);
+  },
 
-    return {
-      restaurantId: cart.restaurantId || null,
-      items: cart.items,
-      total,
-      itemCount,
-    };
+  /**
+   * Reply to a review and notify its author (superadmin or channel admin)
+   */
+  replyToReview: async (
+    _: any,
+    args: { input: ReplyToReviewInput },
+    context: GraphQLContext,
+  ): Promise<Review> => {
+    const restaurantId = await getReviewRestaurantId(args.input?.reviewId);
+    await requireRestaurantAdmin(context, restaurantId);
+    return replyToReview(args.input, context.auth.userId);
   },
 
+  // ============================================================
+  // Favorites Mutation Resolvers
+  // ============================================================
+
   /**
-   * Remove item from cart
+   * Save a restaurant or dish to the current user's favorites
    */
-  removeCartItem: async (
+  addFavorite: async (
     _: any,
-    args: { dishId: string },
+    args: { input: FavoriteInput },
     context: GraphQLContext,
-  ): Promise<{
-    restaurantId: string | null;
-    items: any[];
-    total: number;
-    itemCount: number;
-  }> => {
+  ): Promise<FavoriteEntry> => {
     const auth = requireWrite(context.auth);
     if (!auth.valid) {
-      logger.authFailure("permission_denied", auth.userId);
+      logger.authFailure("permission_denied", context.auth.userId);
       throw forbiddenError();
     }
-    const userId = auth.userId;
-    const userName = auth.name;
-    console.log(
-      `[Resolver] removeCartItem for user ${userId} (${userName}), dish ${args.dishId}`,
-    );
-
-    const cart = removeFromCart(userId, args.dishId);
-    const total = getCartTotal(userId);
-    const itemCount = getCartItemCount(userId);
-
-    return {
-      restaurantId: cart.restaurantId || null,
-      items: cart.items,
-      total,
-      itemCount,
-    };
+    const favorite = await addFavorite(auth.userId, args.input);
+    const [entry] = await resolveFavorites([favorite]);
+    return entry ?? { ...favorite, restaurant: null, dish: null };
   },
 
   /**
-   * Clear entire cart
+   * Remove a restaurant or dish from the current user's favorites
    */
-  clearCart: async (
+  removeFavorite: async (
     _: any,
-    __: any,
+    args: { kind: FavoriteKind; targetId: string },
     context: GraphQLContext,
-  ): Promise<{
-    restaurantId: string | null;
-    items: any[];
-    total: number;
-    itemCount: number;
-  }> => {
+  ): Promise<boolean> => {
     const auth = requireWrite(context.auth);
     if (!auth.valid) {
-      logger.authFailure("permission_denied", auth.userId);
+      logger.authFailure("permission_denied", context.auth.userId);
       throw forbiddenError();
     }
-    const userId = auth.userId;
-    const userName = auth.name;
-    console.log(`[Resolver] clearCart for user ${userId} (${userName})`);
-
-    clearCart(userId);
-
-    return {
-      restaurantId: null,
-      items: [],
-      total: 0,
-      itemCount: 0,
-    };
+    return removeFavorite(auth.userId, args.kind, args.targetId);
   },
 
   // ============================================================
-  // Phase 10: Superadmin & Channel Admin Mutation Resolvers
+  // Broadcast Mutation Resolvers
   // ============================================================
 
   /**
-   * Link channel to telegram user as admin (superadmin only)
+   * Queue a broadcast to past customers (superadmin only)
+   * Delivery happens in batches from the cron job scheduler
    */
-  linkChannelToTelegram: async (
+  startBroadcast: async (
     _: any,
-    args: { input: LinkChannelInput },
+    args: { input: StartBroadcastInput },
     context: GraphQLContext,
-  ): Promise<{
-    success: boolean;
-    channelAdmin: {
-      restaurantId: string;
-      telegramUserId: string;
-      assignedAt: string;
-      assignedBy: string;
-    } | null;
-  }> => {
+  ): Promise<Broadcast> => {
     const auth = requireSuperadmin(context.auth);
     if (!auth.valid) {
       logger.authFailure("superadmin_required", context.auth.userId);
       throw forbiddenError();
     }
-    const { restaurantId, telegramUserId } = args.input;
-    console.log(
-      `[Resolver] linkChannelToTelegram: ${restaurantId} -> ${telegramUserId} by superadmin ${context.auth.userId}`,
-    );
+    return startBroadcast(args.input, auth.userId);
+  },
 
-    const admin = await setChannelAdmin(
-      restaurantId,
-      telegramUserId,
-      context.auth.userId,
-    );
+  /**
+   * Stop a pending or running broadcast (superadmin only)
+   */
+  abortBroadcast: async (
+    _: any,
+    args: { broadcastId: string },
+    context: GraphQLContext,
+  ): Promise<Broadcast> => {
+    const auth = requireSuperadmin(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("superadmin_required", context.auth.userId);
+      throw forbiddenError();
+    }
+    if (!args.broadcastId) {
+      throw badUserInputError("Broadcast is required", "broadcastId");
+    }
+    return abortBroadcast(args.broadcastId, auth.userId);
+  },
 
-    return {
-      success: true,
-      channelAdmin: toChannelAdminInfo(admin),
-    };
+  /**
+   * Send failed bot messages again (superadmin only)
+   */
+  retryNotifications: async (
+    _: any,
+    args: { ids: string[] },
+    context: GraphQLContext,
+  ): Promise<NotificationFailure[]> => {
+    const auth = requireSuperadmin(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("superadmin_required", context.auth.userId);
+      throw forbiddenError();
+    }
+    return retryNotificationFailures(args.ids || [], auth.userId);
   },
 
   /**
-   * Unlink channel from telegram admin (superadmin only)
+   * Stop retrying failed bot messages (superadmin only)
    */
-  unlinkChannel: async (
+  markNotificationsFailed: async (
     _: any,
-    args: { input: UnlinkChannelInput },
+    args: { ids: string[] },
     context: GraphQLContext,
-  ): Promise<{ success: boolean }> => {
+  ): Promise<NotificationFailure[]> => {
     const auth = requireSuperadmin(context.auth);
     if (!auth.valid) {
       logger.authFailure("superadmin_required", context.auth.userId);
       throw forbiddenError();
     }
-    const { restaurantId } = args.input;
-    console.log(
-      `[Resolver] unlinkChannel: ${restaurantId} by superadmin ${context.auth.userId}`,
+    return markNotificationsPermanentlyFailed(args.ids || [], auth.userId);
+  },
+
+  // ============================================================
+  // Metadata Migration Mutation Resolvers
+  // ============================================================
+
+  /**
+   * Move legacy catalog metadata keys to the tma_* contract (superadmin only)
+   * Only reports planned changes unless dryRun is explicitly false
+   */
+  migrateCatalogMetadata: async (
+    _: any,
+    args: { dryRun?: boolean | null; after?: string | null },
+    context: GraphQLContext,
+  ): Promise<MetadataMigrationReport> => {
+    const auth = requireSuperadmin(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("superadmin_required", context.auth.userId);
+      throw forbiddenError();
+    }
+    return migrateCatalogMetadata(
+      args.dryRun !== false,
+      auth.userId,
+      args.after || null,
     );
+  },
 
-    await removeChannelAdmin(restaurantId);
+  // ============================================================
+  // Commission Mutation Resolvers
+  // ============================================================
 
-    return { success: true };
+  /**
+   * Set a restaurant's commission rate (superadmin only)
+   */
+  setCommissionRate: async (
+    _: any,
+    args: { input: SetCommissionRateInput },
+    context: GraphQLContext,
+  ): Promise<CommissionRate> => {
+    const auth = requireSuperadmin(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("superadmin_required", context.auth.userId);
+      throw forbiddenError();
+    }
+    const { restaurantId, rate } = args.input;
+    if (!restaurantId) {
+      throw badUserInputError("Restaurant is required", "restaurantId");
+    }
+    if (typeof rate !== "number" || !(rate >= 0 && rate <= 1)) {
+      throw badUserInputError("Commission rate must be between 0 and 1", "rate");
+    }
+    return setCommissionRate(restaurantId, rate, context.auth.userId);
   },
 
   // ============================================================
@@ -728,15 +2765,109 @@ const mutationResolvers = {
       description,
     };
   },
+
+  /**
+   * Set or clear a restaurant's announcement (superadmin or channel admin)
+   */
+  setRestaurantAnnouncement: async (
+    _: any,
+    args: { input: SetRestaurantAnnouncementInput },
+    context: GraphQLContext,
+  ): Promise<RestaurantAnnouncement> => {
+    const { restaurantId, message, expiresAt } = args.input;
+    await requireRestaurantAdmin(context, restaurantId);
+    return setRestaurantAnnouncement(
+      restaurantId,
+      message || "",
+      expiresAt,
+      context.auth.userId,
+    );
+  },
+
+  /**
+   * Pause or resume taking orders (superadmin or channel admin)
+   */
+  setRestaurantPaused: async (
+    _: any,
+    args: { restaurantId: string; paused: boolean; until?: string | null },
+    context: GraphQLContext,
+  ): Promise<RestaurantPause> => {
+    await requireRestaurantAdmin(context, args.restaurantId);
+    return setRestaurantPaused(
+      args.restaurantId,
+      Boolean(args.paused),
+      args.until,
+      context.auth.userId,
+    );
+  },
+
+  /**
+   * Cap a restaurant's concurrent active orders (superadmin or channel admin)
+   */
+  setKitchenCapacity: async (
+    _: any,
+    args: { restaurantId: string; maxActiveOrders?: number | null },
+    context: GraphQLContext,
+  ): Promise<KitchenCapacity> => {
+    await requireRestaurantAdmin(context, args.restaurantId);
+    return setKitchenCapacity(args.restaurantId, args.maxActiveOrders, context.auth.userId);
+  },
+
+  /**
+   * Schedule or clear the app-wide banner for a day (superadmin only)
+   */
+  setDailyBanner: async (
+    _: any,
+    args: { input: SetDailyBannerInput },
+    context: GraphQLContext,
+  ): Promise<DailyBanner | null> => {
+    const auth = requireSuperadmin(context.auth);
+    if (!auth.valid) {
+      logger.authFailure("superadmin_required", context.auth.userId);
+      throw forbiddenError();
+    }
+    return setDailyBanner(args.input, auth.userId);
+  },
+
+  /**
+   * Close a date or set its special hours (superadmin or channel admin)
+   */
+  setOpeningHoursOverride: async (
+    _: any,
+    args: { input: SetOpeningHoursOverrideInput },
+    context: GraphQLContext,
+  ): Promise<OpeningHoursOverride> => {
+    const { restaurantId, date, hours, clear } = args.input;
+    await requireRestaurantAdmin(context, restaurantId);
+    return setOpeningHoursOverride(
+      restaurantId,
+      date,
+      clear ? null : hours || [],
+      context.auth.userId,
+    );
+  },
 };
 
-// Combined resolvers object
+// Inputs are checked against MUTATION_INPUT_RULES before each mutation runs
+const validatedMutationResolvers = withInputValidation(
+  mutationResolvers,
+  MUTATION_INPUT_RULES,
+);
+
+// Combined resolvers object; each field runs against its deadline, and
+// resolve time and errors (including timeouts) are recorded per field
 export const resolvers = {
-  Query: queryResolvers,
-  Mutation: mutationResolvers,
+  Query: withResolverMetrics(
+    "Query",
+    withOperationDeadlines("Query", queryResolvers),
+  ),
+  Mutation: withResolverMetrics(
+    "Mutation",
+    withOperationDeadlines("Mutation", validatedMutationResolvers),
+  ),
 };
 
 export default resolvers;
 
 // Export individual resolvers for direct use
-export { queryResolvers, mutationResolvers };
\ No newline at end of file
+export { queryResolvers, validatedMutationResolvers as mutationResolvers };
\ No newline at end of file
@@ -0,0 +1,115 @@
+// Restaurant Details Tests
+// Tests for restaurantDetails.ts - typed fields from channel metadata
+
+import { describe, it, expect, vi } from "vitest";
+import { Channel, Review } from "./contracts";
+import {
+  buildRestaurantDetails,
+  fetchRestaurantDetails,
+  getAverageRating,
+  getWeeklyHours,
+} from "./restaurantDetails";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+const channel: Channel = {
+  id: "restA",
+  slug: "rest-a",
+  name: "Restaurant A",
+  isActive: true,
+  currencyCode: "EUR",
+  metadata: {
+    tma_address: " 1 Market Square ",
+    tma_phone: "+49 30 123456",
+    tma_timezone: "Europe/Berlin",
+    tma_hours: JSON.stringify({ mon: "09:00-22:00", sun: [] }),
+    tma_min_order: "15",
+    tma_delivery_fee: "2.5",
+    tma_free_delivery_threshold: "40",
+    tma_prep_minutes: "25",
+  },
+  categories: [],
+  deliveryLocations: [],
+};
+
+function review(rating: number): Review {
+  return {
+    id: `r${rating}`,
+    orderId: "o1",
+    restaurantId: "restA",
+    authorId: "user-1",
+    rating,
+    comment: "",
+    status: "APPROVED",
+    createdAt: "2026-03-01T00:00:00.000Z",
+    moderatedAt: null,
+    moderatedBy: null,
+    reply: null,
+    repliedAt: null,
+    repliedBy: null,
+  };
+}
+
+describe("getWeeklyHours", () => {
+  it("should list weekdays Monday first with formatted ranges", () => {
+    const hours = getWeeklyHours(channel.metadata)!;
+    expect(hours.map((day) => day.weekday)).toEqual([
+      "mon",
+      "tue",
+      "wed",
+      "thu",
+      "fri",
+      "sat",
+      "sun",
+    ]);
+    expect(hours[0].hours).toEqual(["09:00-22:00"]);
+    expect(hours[6].hours).toEqual([]);
+  });
+
+  it("should be null without a schedule", () => {
+    expect(getWeeklyHours({})).toBeNull();
+  });
+});
+
+describe("getAverageRating", () => {
+  it("should average to one decimal", () => {
+    expect(getAverageRating([review(5), review(4), review(4)])).toBe(4.3);
+    expect(getAverageRating([])).toBeNull();
+  });
+});
+
+describe("buildRestaurantDetails", () => {
+  it("should parse metadata into typed fields", () => {
+    const details = buildRestaurantDetails(channel, [review(5), review(3)], "en");
+    expect(details.address).toBe("1 Market Square");
+    expect(details.phone).toBe("+49 30 123456");
+    expect(details.timezone).toBe("Europe/Berlin");
+    expect(details.minOrder?.amount).toBe(15);
+    expect(details.deliveryFee).toMatchObject({ amount: 2.5, currency: "EUR" });
+    expect(details.freeDeliveryThreshold?.amount).toBe(40);
+    expect(details.prepMinutes).toBe(25);
+    expect(details.rating).toBe(4);
+    expect(details.reviewCount).toBe(2);
+  });
+
+  it("should fall back to the pickup address and leave unset fields null", () => {
+    const details = buildRestaurantDetails(
+      { ...channel, metadata: { tma_pickup_address: "2 Side Street" } },
+      [],
+    );
+    expect(details.address).toBe("2 Side Street");
+    expect(details.phone).toBeNull();
+    expect(details.timezone).toBeNull();
+    expect(details.minOrder).toBeNull();
+    expect(details.openingHours).toBeNull();
+    expect(details.rating).toBeNull();
+  });
+});
+
+describe("fetchRestaurantDetails", () => {
+  it("should be null for an unknown restaurant", async () => {
+    expect(await fetchRestaurantDetails("no-such-restaurant")).toBeNull();
+  });
+});
@@ -0,0 +1,111 @@
+// Restaurant Details
+// The restaurant query returns a restaurant's listing fields plus typed
+// details parsed from channel metadata, so clients don't read tma_* keys
+// themselves: tma_address (falling back to tma_pickup_address), tma_phone,
+// tma_hours/tma_timezone, tma_min_order, tma_delivery_fee,
+// tma_free_delivery_threshold and tma_prep_minutes. The rating is the
+// average of approved reviews (reviews.ts).
+
+import { Channel, Money, OpeningHoursDay, Restaurant, Review } from "./contracts";
+import { toMoney } from "./currencyFormat";
+import { parseNumberValue } from "./metadata";
+import {
+  formatTimeRange,
+  isValidTimezone,
+  parseOpeningHours,
+  TIMEZONE_METADATA_KEY,
+  Weekday,
+} from "./openingHours";
+import { PICKUP_ADDRESS_METADATA_KEY } from "./pickup";
+import { DELIVERY_FEE_METADATA_KEY, FREE_DELIVERY_THRESHOLD_METADATA_KEY } from "./quotes";
+import { getApprovedReviews, summarizeRatings } from "./reviews";
+import { fetchChannelById, toRestaurant } from "./saleorService";
+
+export const ADDRESS_METADATA_KEY = "tma_address";
+export const PHONE_METADATA_KEY = "tma_phone";
+export const MIN_ORDER_METADATA_KEY = "tma_min_order";
+
+// Display order, Monday first
+const DISPLAY_WEEKDAYS: Weekday[] = ["mon", "tue", "wed", "thu", "fri", "sat", "sun"];
+
+/**
+ * Weekly opening hours for display, null when no schedule is set
+ */
+export function getWeeklyHours(
+  metadata: Record<string, string> | undefined,
+): OpeningHoursDay[] | null {
+  const hours = parseOpeningHours(metadata);
+  if (!hours) {
+    return null;
+  }
+  return DISPLAY_WEEKDAYS.map((weekday) => ({
+    weekday,
+    hours: (hours.weekly[weekday] || []).map(formatTimeRange),
+  }));
+}
+
+/**
+ * Average rating to one decimal, null without reviews
+ */
+export function getAverageRating(reviews: Review[]): number | null {
+  return summarizeRatings(reviews).average;
+}
+
+function toAmount(
+  metadata: Record<string, string> | undefined,
+  key: string,
+  currency: string,
+  languageCode?: string,
+): Money | null {
+  const amount = parseNumberValue(metadata?.[key]);
+  return amount !== null && amount > 0 ? toMoney(amount, currency, languageCode) : null;
+}
+
+/**
+ * Restaurant with its detail fields from channel metadata and reviews
+ */
+export function buildRestaurantDetails(
+  channel: Channel,
+  reviews: Review[],
+  languageCode?: string,
+  now: Date = new Date(),
+): Restaurant {
+  const metadata = channel.metadata;
+  const currency = channel.currencyCode;
+  const timezone = metadata?.[TIMEZONE_METADATA_KEY]?.trim();
+  return {
+    ...toRestaurant(channel, now),
+    address:
+      metadata?.[ADDRESS_METADATA_KEY]?.trim() ||
+      metadata?.[PICKUP_ADDRESS_METADATA_KEY]?.trim() ||
+      null,
+    phone: metadata?.[PHONE_METADATA_KEY]?.trim() || null,
+    timezone: timezone && isValidTimezone(timezone) ? timezone : null,
+    openingHours: getWeeklyHours(metadata),
+    minOrder: toAmount(metadata, MIN_ORDER_METADATA_KEY, currency, languageCode),
+    deliveryFee: toAmount(metadata, DELIVERY_FEE_METADATA_KEY, currency, languageCode),
+    freeDeliveryThreshold: toAmount(
+      metadata,
+      FREE_DELIVERY_THRESHOLD_METADATA_KEY,
+      currency,
+      languageCode,
+    ),
+    rating: getAverageRating(reviews),
+    reviewCount: reviews.length,
+    ratingSummary: summarizeRatings(reviews),
+  };
+}
+
+/**
+ * A restaurant with details, or null when the channel doesn't exist
+ */
+export async function fetchRestaurantDetails(
+  restaurantId: string,
+  languageCode?: string,
+): Promise<Restaurant | null> {
+  const channel = await fetchChannelById(restaurantId);
+  if (!channel) {
+    return null;
+  }
+  return buildRestaurantDetails(channel, await getApprovedReviews(restaurantId), languageCode);
+}
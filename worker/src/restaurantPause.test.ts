@@ -0,0 +1,52 @@
+// Restaurant Pause Tests
+// Tests for restaurantPause.ts - tma_paused flag, expiry and order blocking
+
+import { describe, it, expect, vi } from "vitest";
+import { assertAcceptingOrders, isRestaurantPaused, setRestaurantPaused } from "./restaurantPause";
+import { fetchChannelById, toRestaurant } from "./saleorService";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+const now = new Date("2026-05-01T12:00:00Z");
+
+describe("isRestaurantPaused", () => {
+  it("should stay paused until tma_paused_until passes", () => {
+    const metadata = { tma_paused: "true", tma_paused_until: "2026-05-01T13:00:00Z" };
+    expect(isRestaurantPaused(metadata, now)).toBe(true);
+    expect(isRestaurantPaused(metadata, new Date("2026-05-01T13:00:01Z"))).toBe(false);
+    expect(isRestaurantPaused({ tma_paused: "yes" }, now)).toBe(true);
+  });
+
+  it("should treat an unset or cleared flag as accepting orders", () => {
+    expect(isRestaurantPaused({ tma_paused: "" }, now)).toBe(false);
+    expect(isRestaurantPaused(undefined, now)).toBe(false);
+  });
+});
+
+describe("assertAcceptingOrders", () => {
+  it("should reject orders for a paused restaurant", () => {
+    expect(() =>
+      assertAcceptingOrders({ id: "channelA", metadata: { tma_paused: "true" } }, now),
+    ).toThrow(expect.objectContaining({ code: "RESTAURANT_PAUSED", field: "restaurantId" }));
+    expect(() => assertAcceptingOrders({ id: "channelA", metadata: {} }, now)).not.toThrow();
+  });
+});
+
+describe("setRestaurantPaused", () => {
+  it("should pause and resume a restaurant's listing", async () => {
+    const paused = await setRestaurantPaused("channelA", true, null, "owner");
+    expect(paused).toEqual({ restaurantId: "channelA", paused: true, pausedUntil: null });
+    expect(toRestaurant((await fetchChannelById("channelA"))!).acceptingOrders).toBe(false);
+
+    await setRestaurantPaused("channelA", false, null, "owner");
+    expect(toRestaurant((await fetchChannelById("channelA"))!).acceptingOrders).toBe(true);
+  });
+
+  it("should refuse a pause ending in the past", async () => {
+    await expect(
+      setRestaurantPaused("channelA", true, "2020-01-01T00:00:00Z", "owner"),
+    ).rejects.toMatchObject({ code: "BAD_USER_INPUT", field: "until" });
+  });
+});
@@ -0,0 +1,73 @@
+// Restaurant Pause
+// Owners can pause a restaurant (tma_paused channel metadata, optionally
+// until tma_paused_until) when the kitchen can't take orders for a while:
+// a rush, a missing courier, a broken oven. Listings keep showing it with
+// acceptingOrders false and placeOrder fails with RESTAURANT_PAUSED until it
+// is resumed or the pause runs out. Unlike opening hours this doesn't allow
+// scheduling for later; unlike kitchen capacity it is switched by hand.
+
+import { Channel, RestaurantPause } from "./contracts";
+import { badUserInputError, notFoundError, restaurantPausedError } from "./errors";
+import { logger } from "./logger";
+import { parseBooleanValue } from "./metadata";
+import { fetchChannelById, updateChannelMetadata } from "./saleorService";
+
+export const PAUSED_METADATA_KEY = "tma_paused";
+export const PAUSED_UNTIL_METADATA_KEY = "tma_paused_until";
+
+/**
+ * Whether a channel is paused now (a pause past tma_paused_until has ended)
+ */
+export function isRestaurantPaused(
+  metadata: Record<string, string> | undefined,
+  now: Date = new Date(),
+): boolean {
+  if (!parseBooleanValue(metadata?.[PAUSED_METADATA_KEY])) {
+    return false;
+  }
+  const until = metadata?.[PAUSED_UNTIL_METADATA_KEY];
+  return !until || Number.isNaN(Date.parse(until)) || new Date(until) > now;
+}
+
+/**
+ * Throw RESTAURANT_PAUSED when the restaurant isn't accepting orders
+ */
+export function assertAcceptingOrders(
+  channel: Pick<Channel, "id" | "metadata"> | null,
+  now: Date = new Date(),
+): void {
+  if (channel && isRestaurantPaused(channel.metadata, now)) {
+    logger.info("order_rejected_paused", { restaurantId: channel.id });
+    throw restaurantPausedError();
+  }
+}
+
+/**
+ * Pause (optionally until a time) or resume a restaurant
+ */
+export async function setRestaurantPaused(
+  restaurantId: string,
+  paused: boolean,
+  until: string | null | undefined,
+  updatedBy: string,
+): Promise<RestaurantPause> {
+  const channel = await fetchChannelById(restaurantId);
+  if (!channel) {
+    throw notFoundError("Restaurant not found");
+  }
+  if (paused && until && new Date(until) <= new Date()) {
+    throw badUserInputError("Pause end must be in the future", "until");
+  }
+
+  const pausedUntil = paused && until ? new Date(until).toISOString() : null;
+  const saved = await updateChannelMetadata(restaurantId, {
+    [PAUSED_METADATA_KEY]: paused ? "true" : "",
+    [PAUSED_UNTIL_METADATA_KEY]: pausedUntil || "",
+  });
+  if (!saved) {
+    throw badUserInputError("Could not save the pause, please try again");
+  }
+
+  logger.info("restaurant_pause_updated", { restaurantId, updatedBy, paused, pausedUntil });
+  return { restaurantId, paused, pausedUntil };
+}
@@ -0,0 +1,78 @@
+// Restaurant Search Tests
+// Tests for restaurantSearch.ts - matching, scoring and highlights
+
+import { describe, it, expect } from "vitest";
+import { Restaurant } from "./contracts";
+import { findMatchRanges, searchRestaurants, tokenizeQuery } from "./restaurantSearch";
+
+function restaurant(id: string, fields: Partial<Restaurant> = {}): Restaurant {
+  return { id, name: id, categories: [], ...fields };
+}
+
+const restaurants: Restaurant[] = [
+  restaurant("Pizza Place", { tags: ["italian"], description: "Wood-fired ovens" }),
+  restaurant("Trattoria Roma", { tags: ["pizza"] }),
+  restaurant("Noodle Bar", { description: "Ramen, udon and the odd pizza on Fridays" }),
+  restaurant("Sushi Go", { tags: ["japanese"] }),
+];
+
+describe("tokenizeQuery", () => {
+  it("should lowercase and de-duplicate words", () => {
+    expect(tokenizeQuery("  Pizza, pizza ROMA! ")).toEqual(["pizza", "roma"]);
+    expect(tokenizeQuery("?!")).toEqual([]);
+  });
+});
+
+describe("findMatchRanges", () => {
+  it("should find every match and merge overlaps", () => {
+    expect(findMatchRanges("Pizza pizzeria", ["pizz", "izza"])).toEqual([
+      { start: 0, length: 5 },
+      { start: 6, length: 4 },
+    ]);
+  });
+});
+
+describe("searchRestaurants", () => {
+  it("should rank name over tag over description matches", () => {
+    const results = searchRestaurants(restaurants, "pizza");
+    expect(results.map((r) => r.restaurant.id)).toEqual([
+      "Pizza Place",
+      "Trattoria Roma",
+      "Noodle Bar",
+    ]);
+    expect(results[0].highlights).toEqual([
+      { field: "NAME", text: "Pizza Place", ranges: [{ start: 0, length: 5 }] },
+    ]);
+    expect(results[1].highlights[0]).toMatchObject({ field: "TAG", text: "Pizza" });
+  });
+
+  it("should require every word to match", () => {
+    expect(searchRestaurants(restaurants, "pizza roma").map((r) => r.restaurant.id)).toEqual([
+      "Trattoria Roma",
+    ]);
+    expect(searchRestaurants(restaurants, "pizza sushi")).toEqual([]);
+  });
+
+  it("should boost well-rated, much-reviewed restaurants among equal matches", () => {
+    const rated = [
+      restaurant("Pizza A", { ratingSummary: { average: 3.1, count: 2 } }),
+      restaurant("Pizza B", { ratingSummary: { average: 4.8, count: 120 } }),
+    ];
+    const results = searchRestaurants(rated, "pizza");
+    expect(results.map((r) => r.restaurant.id)).toEqual(["Pizza B", "Pizza A"]);
+    expect(results[0].score).toBeGreaterThan(results[1].score);
+  });
+
+  it("should cut long descriptions to a snippet around the match", () => {
+    const description = `${"Lorem ipsum dolor sit amet. ".repeat(6)}Famous for ramen.${
+      " Consectetur adipiscing elit.".repeat(6)
+    }`;
+    const [result] = searchRestaurants([restaurant("Bar", { description })], "ramen");
+    const [highlight] = result.highlights;
+    expect(highlight.field).toBe("DESCRIPTION");
+    expect(highlight.text.startsWith("…")).toBe(true);
+    expect(highlight.text.endsWith("…")).toBe(true);
+    const [range] = highlight.ranges;
+    expect(highlight.text.substr(range.start, range.length)).toBe("ramen");
+  });
+});
@@ -0,0 +1,208 @@
+// Restaurant Search
+// Free-text search over restaurants with highlights and a relevance score.
+// Every query word must match the name, a cuisine tag (key or label in the
+// user's language) or the description; each word scores by the best field
+// it hits (name > tag > description, more at the start of a word). The sum
+// is boosted by the approved rating and, as the popularity signal, how many
+// reviews it has, so among equal matches well-rated, much-reviewed
+// restaurants come first. Highlights carry the ranges to mark; descriptions
+// are cut to a snippet around the first match.
+
+import { Restaurant, RestaurantSearchResult, SearchField, SearchHighlight } from "./contracts";
+import { toTagLabels } from "./tagLabels";
+
+export const DEFAULT_SEARCH_RESULTS = 20;
+export const MAX_SEARCH_RESULTS = 50;
+export const MAX_QUERY_LENGTH = 100;
+
+const MAX_QUERY_TERMS = 8;
+const SNIPPET_RADIUS = 60;
+
+const FIELD_WEIGHTS: Record<SearchField, number> = {
+  NAME: 10,
+  TAG: 5,
+  DESCRIPTION: 2,
+};
+// Extra share of the field weight when a word starts with the term
+const WORD_START_BONUS = 0.25;
+
+type Range = SearchHighlight["ranges"][number];
+
+/**
+ * Lowercase, de-duplicated words of a search query
+ */
+export function tokenizeQuery(query: string): string[] {
+  const words = query
+    .toLowerCase()
+    .split(/[^\p{L}\p{N}]+/u)
+    .filter(Boolean);
+  return Array.from(new Set(words)).slice(0, MAX_QUERY_TERMS);
+}
+
+function isWordStart(text: string, index: number): boolean {
+  return index === 0 || !/[\p{L}\p{N}]/u.test(text[index - 1]);
+}
+
+/**
+ * Case-insensitive ranges of every term in text, merged where they overlap
+ */
+export function findMatchRanges(text: string, terms: string[]): Range[] {
+  const lower = text.toLowerCase();
+  // Lowercasing can change length (e.g. "İ"), which would shift ranges
+  if (lower.length !== text.length) {
+    return [];
+  }
+  const ranges: Range[] = [];
+  for (const term of terms) {
+    let index = lower.indexOf(term);
+    while (index !== -1) {
+      ranges.push({ start: index, length: term.length });
+      index = lower.indexOf(term, index + term.length);
+    }
+  }
+  ranges.sort((a, b) => a.start - b.start);
+
+  const merged: Range[] = [];
+  for (const range of ranges) {
+    const last = merged[merged.length - 1];
+    if (last && range.start <= last.start + last.length) {
+      const end = Math.max(last.start + last.length, range.start + range.length);
+      last.length = end - last.start;
+    } else {
+      merged.push({ ...range });
+    }
+  }
+  return merged;
+}
+
+/**
+ * A window of text around the first range, with "…" where it was cut
+ */
+function toSnippet(text: string, ranges: Range[]): { text: string; ranges: Range[] } {
+  if (text.length <= SNIPPET_RADIUS * 2) {
+    return { text, ranges };
+  }
+  let start = Math.max(0, ranges[0].start - SNIPPET_RADIUS);
+  let end = Math.min(text.length, ranges[0].start + ranges[0].length + SNIPPET_RADIUS);
+  // Don't cut words in half
+  while (start > 0 && !/\s/.test(text[start - 1])) {
+    start--;
+  }
+  while (end < text.length && !/\s/.test(text[end])) {
+    end++;
+  }
+  const prefix = start > 0 ? "…" : "";
+  const suffix = end < text.length ? "…" : "";
+  return {
+    text: `${prefix}${text.slice(start, end)}${suffix}`,
+    ranges: ranges
+      .filter((r) => r.start >= start && r.start + r.length <= end)
+      .map((r) => ({ start: r.start - start + prefix.length, length: r.length })),
+  };
+}
+
+/**
+ * Weight of a term in one field's text (0 when it isn't there)
+ */
+function termWeight(text: string, term: string, field: SearchField): number {
+  const lower = text.toLowerCase();
+  let index = lower.indexOf(term);
+  if (index === -1) {
+    return 0;
+  }
+  const weight = FIELD_WEIGHTS[field];
+  while (index !== -1) {
+    if (isWordStart(lower, index)) {
+      return weight * (1 + WORD_START_BONUS);
+    }
+    index = lower.indexOf(term, index + 1);
+  }
+  return weight;
+}
+
+/**
+ * Multiplier from the restaurant's rating and review count
+ */
+export function getRankingBoost(restaurant: Restaurant): number {
+  const summary = restaurant.ratingSummary;
+  if (!summary || summary.count === 0 || summary.average === null) {
+    return 1;
+  }
+  const rating = (summary.average - 3) / 10; // -0.2 .. +0.2
+  const popularity = Math.min(Math.log10(1 + summary.count) / 10, 0.3);
+  return 1 + rating + popularity;
+}
+
+/**
+ * Score and highlight one restaurant, or null unless every term matches
+ */
+export function matchRestaurant(
+  restaurant: Restaurant,
+  terms: string[],
+  languageCode?: string,
+): RestaurantSearchResult | null {
+  if (terms.length === 0) {
+    return null;
+  }
+  const tags = toTagLabels(restaurant.tags, languageCode);
+  const fields: Array<{ field: SearchField; texts: string[] }> = [
+    { field: "NAME", texts: [restaurant.name] },
+    { field: "TAG", texts: tags.map((tag) => tag.label) },
+    { field: "DESCRIPTION", texts: restaurant.description ? [restaurant.description] : [] },
+  ];
+  // Tag keys match too ("vegan" finds "Веган"), but only labels are shown
+  const scored = [...fields, { field: "TAG" as SearchField, texts: tags.map((tag) => tag.key) }];
+
+  let score = 0;
+  for (const term of terms) {
+    let best = 0;
+    for (const { field, texts } of scored) {
+      for (const text of texts) {
+        best = Math.max(best, termWeight(text, term, field));
+      }
+    }
+    if (best === 0) {
+      return null;
+    }
+    score += best;
+  }
+
+  const highlights: SearchHighlight[] = [];
+  for (const { field, texts } of fields) {
+    for (const text of texts) {
+      const ranges = findMatchRanges(text, terms);
+      if (ranges.length === 0) {
+        continue;
+      }
+      highlights.push(
+        field === "DESCRIPTION" ? { field, ...toSnippet(text, ranges) } : { field, text, ranges },
+      );
+    }
+  }
+
+  return {
+    restaurant,
+    score: Math.round(score * getRankingBoost(restaurant) * 100) / 100,
+    highlights,
+  };
+}
+
+/**
+ * Restaurants matching a query, best first (ties by name)
+ */
+export function searchRestaurants(
+  restaurants: Restaurant[],
+  query: string,
+  languageCode?: string,
+  limit: number = DEFAULT_SEARCH_RESULTS,
+): RestaurantSearchResult[] {
+  const terms = tokenizeQuery(query);
+  const size = Math.min(Math.max(1, Math.floor(limit)), MAX_SEARCH_RESULTS);
+  return restaurants
+    .map((restaurant) => matchRestaurant(restaurant, terms, languageCode))
+    .filter((result): result is RestaurantSearchResult => result !== null)
+    .sort(
+      (a, b) => b.score - a.score || a.restaurant.name.localeCompare(b.restaurant.name),
+    )
+    .slice(0, size);
+}
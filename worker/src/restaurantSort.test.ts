@@ -0,0 +1,68 @@
+// Restaurant Sorting Tests
+// Tests for restaurantSort.ts - in-memory restaurant ordering
+
+import { describe, it, expect } from "vitest";
+import { Restaurant } from "./contracts";
+import { sortRestaurants } from "./restaurantSort";
+
+function restaurant(name: string, fields: Partial<Restaurant> = {}): Restaurant {
+  return { id: name, name, categories: [], ...fields };
+}
+
+const restaurants: Restaurant[] = [
+  restaurant("Curry House", {
+    ratingSummary: { average: 4.5, count: 10 },
+    distanceKm: 3.2,
+    prepMinutes: 25,
+  }),
+  restaurant("Burger Bar", { ratingSummary: { average: null, count: 0 }, prepMinutes: 10 }),
+  restaurant("Dumpling Den", {
+    ratingSummary: { average: 4.5, count: 40 },
+    distanceKm: 0.8,
+    prepMinutes: 25,
+  }),
+  restaurant("Arepa Stand", { ratingSummary: { average: 3.9, count: 5 }, distanceKm: 1.5 }),
+];
+
+function names(sorted: Restaurant[]): string[] {
+  return sorted.map((r) => r.name);
+}
+
+describe("sortRestaurants", () => {
+  it("should put the best rated first, more reviews breaking ties", () => {
+    expect(names(sortRestaurants(restaurants, "RATING"))).toEqual([
+      "Dumpling Den",
+      "Curry House",
+      "Arepa Stand",
+      "Burger Bar",
+    ]);
+  });
+
+  it("should put the nearest first and unlocated restaurants last", () => {
+    expect(names(sortRestaurants(restaurants, "DISTANCE"))).toEqual([
+      "Dumpling Den",
+      "Arepa Stand",
+      "Curry House",
+      "Burger Bar",
+    ]);
+  });
+
+  it("should put the quickest kitchens first, ties by name", () => {
+    expect(names(sortRestaurants(restaurants, "PREP_TIME"))).toEqual([
+      "Burger Bar",
+      "Curry House",
+      "Dumpling Den",
+      "Arepa Stand",
+    ]);
+  });
+
+  it("should sort by name or keep the fetched order", () => {
+    expect(names(sortRestaurants(restaurants, "ALPHABETICAL"))).toEqual([
+      "Arepa Stand",
+      "Burger Bar",
+      "Curry House",
+      "Dumpling Den",
+    ]);
+    expect(sortRestaurants(restaurants, undefined)).toBe(restaurants);
+  });
+});
@@ -0,0 +1,63 @@
+// Restaurant Sorting
+// Saleor returns channels in its own order and can't sort them by metadata,
+// so the restaurants query sorts in memory by fields derived from it:
+// RATING (approved review average, then review count), DISTANCE (distanceKm
+// from the user's location), PREP_TIME (tma_prep_minutes) or ALPHABETICAL.
+// Restaurants without the value (no reviews, no tma_location) go last; ties
+// are broken by name.
+
+import { Restaurant, RestaurantSortBy } from "./contracts";
+
+export const RESTAURANT_SORT_OPTIONS: RestaurantSortBy[] = [
+  "RATING",
+  "DISTANCE",
+  "PREP_TIME",
+  "ALPHABETICAL",
+];
+
+/**
+ * Compare optional numbers, missing values last
+ */
+function compareKnown(
+  a: number | null | undefined,
+  b: number | null | undefined,
+  direction: 1 | -1,
+): number {
+  const aKnown = a !== null && a !== undefined;
+  const bKnown = b !== null && b !== undefined;
+  if (!aKnown || !bKnown) {
+    return aKnown === bKnown ? 0 : aKnown ? -1 : 1;
+  }
+  return (a - b) * direction;
+}
+
+function byName(a: Restaurant, b: Restaurant): number {
+  return a.name.localeCompare(b.name);
+}
+
+/**
+ * Sort restaurants in memory; without sortBy they keep their fetched order
+ */
+export function sortRestaurants(
+  restaurants: Restaurant[],
+  sortBy: RestaurantSortBy | null | undefined,
+): Restaurant[] {
+  const sorted = [...restaurants];
+  switch (sortBy) {
+    case "RATING":
+      return sorted.sort(
+        (a, b) =>
+          compareKnown(a.ratingSummary?.average, b.ratingSummary?.average, -1) ||
+          (b.ratingSummary?.count ?? 0) - (a.ratingSummary?.count ?? 0) ||
+          byName(a, b),
+      );
+    case "DISTANCE":
+      return sorted.sort((a, b) => compareKnown(a.distanceKm, b.distanceKm, 1) || byName(a, b));
+    case "PREP_TIME":
+      return sorted.sort((a, b) => compareKnown(a.prepMinutes, b.prepMinutes, 1) || byName(a, b));
+    case "ALPHABETICAL":
+      return sorted.sort(byName);
+    default:
+      return restaurants;
+  }
+}
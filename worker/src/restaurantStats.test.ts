@@ -0,0 +1,92 @@
+// Restaurant Stats Tests
+// Tests for restaurantStats.ts and the digest rendered from it
+
+import { describe, it, expect, vi } from "vitest";
+import { SaleorOrder } from "./saleorOrder";
+import { computeDailyStats } from "./restaurantStats";
+import { renderDailyDigest } from "./ownerDigest";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn() },
+}));
+
+function order(
+  id: string,
+  createdAt: string,
+  amount: number,
+  lines: Array<[string, number]>,
+  status: SaleorOrder["status"] = "UNFULFILLED",
+): SaleorOrder {
+  return {
+    id,
+    status,
+    total: { gross: { amount, currency: "EUR" } },
+    deliveryAddress: { address: "1 Main St" },
+    lines: lines.map(([productName, quantity]) => ({
+      variantId: productName,
+      productName,
+      quantity,
+    })),
+    createdAt,
+  };
+}
+
+const orders = [
+  order("o1", "2026-10-17T08:00:00Z", 20.1, [["Pizza", 2], ["Cola", 1]]),
+  order("o2", "2026-10-17T21:30:00Z", 10.2, [["Cola", 3]]),
+  order("o3", "2026-10-17T12:00:00Z", 15, [["Soup", 5]], "CANCELLED"),
+  // 2026-10-18 01:00 in Europe/Berlin
+  order("o4", "2026-10-17T23:00:00Z", 9, [["Soup", 1]]),
+];
+
+describe("computeDailyStats", () => {
+  it("should count orders, cancellations, revenue and top dishes", () => {
+    const stats = computeDailyStats("ch-1", orders, "2026-10-17", "UTC");
+
+    expect(stats.orderCount).toBe(4);
+    expect(stats.cancelledCount).toBe(1);
+    expect(stats.revenue).toBe(39.3);
+    expect(stats.currency).toBe("EUR");
+    expect(stats.topDishes).toEqual([
+      { name: "Cola", quantity: 4 },
+      { name: "Pizza", quantity: 2 },
+      { name: "Soup", quantity: 1 },
+    ]);
+  });
+
+  it("should use the restaurant's local date", () => {
+    const stats = computeDailyStats("ch-1", orders, "2026-10-17", "Europe/Berlin");
+
+    expect(stats.orderCount).toBe(3);
+    expect(stats.revenue).toBe(30.3);
+  });
+
+  it("should report an empty day", () => {
+    const stats = computeDailyStats("ch-1", orders, "2026-10-10", "UTC", "USD");
+
+    expect(stats).toMatchObject({ orderCount: 0, revenue: 0, currency: "USD", topDishes: [] });
+  });
+});
+
+describe("renderDailyDigest", () => {
+  it("should list totals and top dishes", () => {
+    const text = renderDailyDigest(
+      "Pizza Place",
+      computeDailyStats("ch-1", orders, "2026-10-17", "UTC"),
+    );
+
+    expect(text).toContain("Pizza Place — daily summary for 2026-10-17");
+    expect(text).toContain("Orders: 4");
+    expect(text).toContain("Cancelled: 1");
+    expect(text).toContain("1. Cola × 4");
+  });
+
+  it("should say when there were no orders", () => {
+    const text = renderDailyDigest(
+      "Pizza Place",
+      computeDailyStats("ch-1", [], "2026-10-17", "UTC"),
+    );
+
+    expect(text).toContain("No orders.");
+  });
+});
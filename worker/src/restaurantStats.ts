@@ -0,0 +1,89 @@
+// Restaurant Stats
+// Per-day order statistics for a restaurant in its own timezone: orders
+// placed, revenue, top dishes and cancellations (CANCELLED or EXPIRED).
+// Revenue counts every order that wasn't cancelled, so it reflects the day's
+// takings before fulfilment; payout reports use completed orders only.
+
+import { getNormalizedStatus, fetchOrders, SaleorOrder } from "./saleorOrder";
+import { getLocalTime, shiftDateKey } from "./openingHours";
+import { sumMoney } from "./money";
+
+export interface TopDish {
+  name: string;
+  quantity: number;
+}
+
+export interface DailyStats {
+  restaurantId: string;
+  date: string; // YYYY-MM-DD in the restaurant's timezone
+  orderCount: number;
+  cancelledCount: number;
+  revenue: number; // gross, cancelled orders excluded
+  currency: string;
+  topDishes: TopDish[];
+}
+
+const TOP_DISH_COUNT = 3;
+
+/**
+ * Stats for the orders created on a local date
+ */
+export function computeDailyStats(
+  restaurantId: string,
+  orders: SaleorOrder[],
+  date: string,
+  timezone: string,
+  currency: string = "USD",
+): DailyStats {
+  const dayOrders = orders.filter(
+    (order) => getLocalTime(new Date(order.createdAt), timezone).dateKey === date,
+  );
+  const cancelled = dayOrders.filter((order) => {
+    const status = getNormalizedStatus(order);
+    return status === "CANCELLED" || status === "EXPIRED";
+  });
+  const kept = dayOrders.filter((order) => !cancelled.includes(order));
+  const orderCurrency = kept[0]?.total.gross.currency || currency;
+
+  const quantities = new Map<string, number>();
+  for (const order of kept) {
+    for (const line of order.lines) {
+      quantities.set(line.productName, (quantities.get(line.productName) || 0) + line.quantity);
+    }
+  }
+  const topDishes = Array.from(quantities.entries())
+    .map(([name, quantity]) => ({ name, quantity }))
+    .sort((a, b) => b.quantity - a.quantity || a.name.localeCompare(b.name))
+    .slice(0, TOP_DISH_COUNT);
+
+  return {
+    restaurantId,
+    date,
+    orderCount: dayOrders.length,
+    cancelledCount: cancelled.length,
+    revenue: sumMoney(
+      kept.map((order) => order.total.gross.amount),
+      orderCurrency,
+    ),
+    currency: orderCurrency,
+    topDishes,
+  };
+}
+
+/**
+ * Fetch a restaurant's orders for a local date and compute its stats
+ * Saleor filters by UTC date, so the neighbouring days are fetched too
+ */
+export async function buildDailyStats(
+  restaurantId: string,
+  date: string,
+  timezone: string,
+  currency?: string,
+): Promise<DailyStats> {
+  const orders = await fetchOrders({
+    channelId: restaurantId,
+    createdFrom: shiftDateKey(date, -1),
+    createdTo: shiftDateKey(date, 1),
+  });
+  return computeDailyStats(restaurantId, orders, date, timezone, currency);
+}
@@ -0,0 +1,96 @@
+// Review Tests
+// Tests for reviews.ts - star ratings, rating summaries and public listings
+
+import { describe, it, expect, vi } from "vitest";
+import { Restaurant, Review } from "./contracts";
+import {
+  backfillRatingSummaries,
+  listRestaurantReviews,
+  moderateReview,
+  summarizeRatings,
+  withRatings,
+} from "./reviews";
+import { writeJSON } from "./storage";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+}));
+
+function review(id: string, restaurantId: string, rating: number, status = "APPROVED"): Review {
+  return {
+    id,
+    orderId: `order-${id}`,
+    restaurantId,
+    authorId: "user-1",
+    authorName: "Ann",
+    rating,
+    comment: `Comment ${id}`,
+    status: status as Review["status"],
+    createdAt: `2026-03-0${id.slice(-1)}T00:00:00.000Z`,
+    moderatedAt: null,
+    moderatedBy: null,
+    reply: null,
+    repliedAt: null,
+    repliedBy: null,
+  };
+}
+
+async function seed(reviews: Review[]): Promise<void> {
+  for (const item of reviews) {
+    await writeJSON(`review:${item.id}`, item);
+  }
+}
+
+describe("summarizeRatings", () => {
+  it("should average to one decimal", () => {
+    expect(summarizeRatings([review("r1", "restA", 5), review("r2", "restA", 4)])).toEqual({
+      average: 4.5,
+      count: 2,
+    });
+    expect(summarizeRatings([])).toEqual({ average: null, count: 0 });
+  });
+});
+
+describe("restaurant ratings", () => {
+  it("should summarise and list approved reviews only", async () => {
+    await seed([
+      review("s1", "restS", 5),
+      review("s2", "restS", 2),
+      review("s3", "restS", 1, "PENDING"),
+      review("s4", "restT", 4, "HIDDEN"),
+    ]);
+
+    expect(await backfillRatingSummaries()).toBe(1);
+    expect(await backfillRatingSummaries()).toBe(0);
+    const restaurants = await withRatings([
+      { id: "restS", name: "S" },
+      { id: "restT", name: "T" },
+    ] as Restaurant[]);
+    expect(restaurants.map((r) => r.ratingSummary)).toEqual([
+      { average: 3.5, count: 2 },
+      { average: null, count: 0 },
+    ]);
+
+    const page = await listRestaurantReviews("restS", 1);
+    expect(page.edges.map((edge) => edge.node.id)).toEqual(["s2"]);
+    expect(page.edges[0].node).not.toHaveProperty("authorId");
+    expect(page.pageInfo.hasNextPage).toBe(true);
+
+    const next = await listRestaurantReviews("restS", 1, page.pageInfo.endCursor);
+    expect(next.edges.map((edge) => edge.node.id)).toEqual(["s1"]);
+    expect(next.pageInfo.hasNextPage).toBe(false);
+  });
+
+  it("should count reviews towards the rating as they are approved or hidden", async () => {
+    await seed([review("u1", "restU", 4, "PENDING")]);
+    const rating = async () =>
+      (await withRatings([{ id: "restU", name: "U" } as Restaurant]))[0].ratingSummary;
+
+    await moderateReview({ reviewId: "u1", action: "APPROVE" }, "admin");
+    expect(await rating()).toEqual({ average: 4, count: 1 });
+    await moderateReview({ reviewId: "u1", action: "APPROVE" }, "admin");
+    expect(await rating()).toEqual({ average: 4, count: 1 });
+    await moderateReview({ reviewId: "u1", action: "HIDE" }, "admin");
+    expect(await rating()).toEqual({ average: null, count: 0 });
+  });
+});
@@ -0,0 +1,303 @@
+// Restaurant Review Moderation
+// Customers review their completed orders; reviews wait in a moderation
+// queue (PENDING) until a restaurant admin or superadmin approves or hides
+// them. Only APPROVED reviews count towards public ratings and are listed
+// to customers. Admin replies are sent to the review author through the
+// Bot API.
+//
+// Ratings shown in restaurant lists come from a per-restaurant aggregate
+// (rating-summary:<restaurantId>, count and sum of approved ratings) kept
+// up to date by moderation, so listing restaurants reads one key per
+// restaurant on the page. backfillRatingSummaries builds the aggregates
+// once from the reviews stored before them.
+
+import {
+  Connection,
+  RatingSummary,
+  Restaurant,
+  RestaurantReview,
+  Review,
+  ReviewStatus,
+  SubmitReviewInput,
+  ModerateReviewInput,
+  ReplyToReviewInput,
+} from "./contracts";
+import { badUserInputError, notFoundError } from "./errors";
+import { logger } from "./logger";
+import { sendTelegramMessage } from "./notifications";
+import { paginateList } from "./pagination";
+import { getNormalizedStatus, fetchUserOrder } from "./saleorOrder";
+import { fetchChannelById } from "./saleorService";
+import { readJSON, writeJSON, readAllJSON } from "./storage";
+
+const REVIEW_PREFIX = "review:";
+const RATING_PREFIX = "rating-summary:";
+const RATING_BACKFILL_KEY = "rating-summary-backfilled";
+const MAX_COMMENT_LENGTH = 2000;
+
+/**
+ * Approved ratings of a restaurant
+ */
+interface RatingTotals {
+  count: number;
+  sum: number;
+}
+
+function getKey(reviewId: string): string {
+  return `${REVIEW_PREFIX}${reviewId}`;
+}
+
+function getOrderIndexKey(orderId: string): string {
+  return `review-order:${orderId}`;
+}
+
+function getRatingKey(restaurantId: string): string {
+  return `${RATING_PREFIX}${restaurantId}`;
+}
+
+async function getRatingTotals(restaurantId: string): Promise<RatingTotals> {
+  return (await readJSON<RatingTotals>(getRatingKey(restaurantId))) || { count: 0, sum: 0 };
+}
+
+async function adjustRatingTotals(restaurantId: string, rating: number, delta: 1 | -1) {
+  const totals = await getRatingTotals(restaurantId);
+  await writeJSON(getRatingKey(restaurantId), {
+    count: Math.max(0, totals.count + delta),
+    sum: Math.max(0, totals.sum + delta * rating),
+  });
+}
+
+export async function getReview(reviewId: string): Promise<Review | null> {
+  return readJSON<Review>(getKey(reviewId));
+}
+
+async function requireReview(reviewId: string): Promise<Review> {
+  const review = reviewId ? await getReview(reviewId) : null;
+  if (!review) {
+    throw notFoundError("Review not found");
+  }
+  return review;
+}
+
+/**
+ * Submit a review for one of the user's completed orders
+ */
+export async function submitReview(
+  input: SubmitReviewInput,
+  authorId: string,
+  authorName?: string,
+): Promise<Review> {
+  if (!Number.isInteger(input.rating) || input.rating < 1 || input.rating > 5) {
+    throw badUserInputError("Rating must be between 1 and 5", "rating");
+  }
+  const comment = (input.comment || "").trim();
+  if (comment.length > MAX_COMMENT_LENGTH) {
+    throw badUserInputError(
+      `Comment exceeds ${MAX_COMMENT_LENGTH} characters`,
+      "comment",
+    );
+  }
+
+  const order = await fetchUserOrder(input.orderId, authorId);
+  if (!order) {
+    throw notFoundError("Order not found");
+  }
+  if (getNormalizedStatus(order) !== "COMPLETED") {
+    throw badUserInputError("Only completed orders can be reviewed", "orderId");
+  }
+  if (await readJSON<string>(getOrderIndexKey(order.id))) {
+    throw badUserInputError("This order has already been reviewed", "orderId");
+  }
+
+  const review: Review = {
+    id: crypto.randomUUID(),
+    orderId: order.id,
+    restaurantId: order.channelId || "",
+    authorId,
+    authorName,
+    rating: input.rating,
+    comment,
+    status: "PENDING",
+    createdAt: new Date().toISOString(),
+    moderatedAt: null,
+    moderatedBy: null,
+    reply: null,
+    repliedAt: null,
+    repliedBy: null,
+  };
+
+  await writeJSON(getKey(review.id), review);
+  await writeJSON(getOrderIndexKey(order.id), review.id);
+  logger.info("review_submitted", {
+    reviewId: review.id,
+    restaurantId: review.restaurantId,
+  });
+  return review;
+}
+
+/**
+ * Reviews for moderation, newest first
+ * Omitting restaurantId lists every restaurant (callers enforce superadmin)
+ */
+export async function listReviews(
+  restaurantId?: string,
+  status?: ReviewStatus,
+): Promise<Review[]> {
+  const reviews = await readAllJSON<Review>(REVIEW_PREFIX);
+  return reviews
+    .filter((r) => !restaurantId || r.restaurantId === restaurantId)
+    .filter((r) => !status || r.status === status)
+    .sort((a, b) => b.createdAt.localeCompare(a.createdAt));
+}
+
+/**
+ * Approved reviews, the only ones that count towards public ratings
+ */
+export async function getApprovedReviews(restaurantId: string): Promise<Review[]> {
+  return listReviews(restaurantId, "APPROVED");
+}
+
+function toRatingSummary(totals: RatingTotals): RatingSummary {
+  if (totals.count <= 0) {
+    return { average: null, count: 0 };
+  }
+  return { average: Math.round((totals.sum / totals.count) * 10) / 10, count: totals.count };
+}
+
+/**
+ * Average to one decimal and count of reviews
+ */
+export function summarizeRatings(reviews: Review[]): RatingSummary {
+  const sum = reviews.reduce((total, review) => total + review.rating, 0);
+  return toRatingSummary({ count: reviews.length, sum });
+}
+
+/**
+ * Restaurants with the rating summary of their approved reviews
+ */
+export async function withRatings(restaurants: Restaurant[]): Promise<Restaurant[]> {
+  const totals = await Promise.all(
+    restaurants.map((restaurant) => getRatingTotals(restaurant.id)),
+  );
+  return restaurants.map((restaurant, index) => ({
+    ...restaurant,
+    ratingSummary: toRatingSummary(totals[index]),
+  }));
+}
+
+/**
+ * Build the rating aggregates from the stored reviews, once (cron)
+ * Returns the restaurants written, 0 after the first run
+ */
+export async function backfillRatingSummaries(): Promise<number> {
+  if (await readJSON<string>(RATING_BACKFILL_KEY)) {
+    return 0;
+  }
+  const byRestaurant = new Map<string, RatingTotals>();
+  for (const review of await listReviews(undefined, "APPROVED")) {
+    const totals = byRestaurant.get(review.restaurantId) || { count: 0, sum: 0 };
+    byRestaurant.set(review.restaurantId, {
+      count: totals.count + 1,
+      sum: totals.sum + review.rating,
+    });
+  }
+  for (const [restaurantId, totals] of byRestaurant.entries()) {
+    await writeJSON(getRatingKey(restaurantId), totals);
+  }
+  await writeJSON(RATING_BACKFILL_KEY, new Date().toISOString());
+  logger.info("rating_summaries_backfilled", { restaurants: byRestaurant.size });
+  return byRestaurant.size;
+}
+
+function toRestaurantReview(review: Review): RestaurantReview {
+  return {
+    id: review.id,
+    authorName: review.authorName,
+    rating: review.rating,
+    comment: review.comment,
+    createdAt: review.createdAt,
+    reply: review.reply,
+    repliedAt: review.repliedAt,
+  };
+}
+
+/**
+ * Page of a restaurant's approved reviews, newest first
+ */
+export async function listRestaurantReviews(
+  restaurantId: string,
+  first?: number | null,
+  after?: string | null,
+): Promise<Connection<RestaurantReview>> {
+  const reviews = await getApprovedReviews(restaurantId);
+  return paginateList(reviews.map(toRestaurantReview), first, after);
+}
+
+/**
+ * Look up the restaurant a review belongs to (for permission checks)
+ */
+export async function getReviewRestaurantId(reviewId: string): Promise<string> {
+  const review = await requireReview(reviewId);
+  return review.restaurantId;
+}
+
+/**
+ * Approve or hide a review
+ */
+export async function moderateReview(
+  input: ModerateReviewInput,
+  moderatorId: string,
+): Promise<Review> {
+  if (input.action !== "APPROVE" && input.action !== "HIDE") {
+    throw badUserInputError("Action must be APPROVE or HIDE", "action");
+  }
+  const review = await requireReview(input.reviewId);
+  const wasApproved = review.status === "APPROVED";
+  review.status = input.action === "APPROVE" ? "APPROVED" : "HIDDEN";
+  review.moderatedAt = new Date().toISOString();
+  review.moderatedBy = moderatorId;
+  await writeJSON(getKey(review.id), review);
+  if (wasApproved !== (review.status === "APPROVED")) {
+    await adjustRatingTotals(review.restaurantId, review.rating, wasApproved ? -1 : 1);
+  }
+  logger.info("review_moderated", {
+    reviewId: review.id,
+    status: review.status,
+    moderatorId,
+  });
+  return review;
+}
+
+/**
+ * Reply publicly to a review and notify its author
+ */
+export async function replyToReview(
+  input: ReplyToReviewInput,
+  replierId: string,
+): Promise<Review> {
+  const reply = (input.reply || "").trim();
+  if (!reply) {
+    throw badUserInputError("Reply is required", "reply");
+  }
+  if (reply.length > MAX_COMMENT_LENGTH) {
+    throw badUserInputError(
+      `Reply exceeds ${MAX_COMMENT_LENGTH} characters`,
+      "reply",
+    );
+  }
+
+  const review = await requireReview(input.reviewId);
+  review.reply = reply;
+  review.repliedAt = new Date().toISOString();
+  review.repliedBy = replierId;
+  await writeJSON(getKey(review.id), review);
+
+  const channel = await fetchChannelById(review.restaurantId);
+  await sendTelegramMessage(
+    review.authorId,
+    `${channel?.name || "The restaurant"} replied to your review:\n\n${reply}`,
+  );
+
+  logger.info("review_replied", { reviewId: review.id, replierId });
+  return review;
+}
@@ -0,0 +1,70 @@
+// Rich Text Tests
+// Tests for richText.ts - EditorJS descriptions as plain text and Telegram HTML
+
+import { describe, it, expect } from "vitest";
+import { parseEditorJs, renderDescription } from "./richText";
+
+function editorJs(...blocks: Array<{ type: string; data: Record<string, unknown> }>): string {
+  return JSON.stringify({ time: 1700000000000, blocks, version: "2.28.0" });
+}
+
+describe("parseEditorJs", () => {
+  it("should only accept EditorJS JSON", () => {
+    expect(parseEditorJs(editorJs({ type: "paragraph", data: { text: "Hi" } }))).toHaveLength(1);
+    expect(parseEditorJs("Plain text")).toBeNull();
+    expect(parseEditorJs('{"not":"editorjs"}')).toBeNull();
+    expect(parseEditorJs(null)).toBeNull();
+  });
+});
+
+describe("renderDescription", () => {
+  it("should render blocks as paragraphs", () => {
+    const result = renderDescription(
+      editorJs(
+        { type: "header", data: { text: "Chef's special", level: 2 } },
+        { type: "paragraph", data: { text: "Slow cooked &amp; <b>spicy</b><br>Serves two" } },
+        { type: "list", data: { style: "unordered", items: ["Rice", "Salad"] } },
+      ),
+    );
+
+    expect(result.description).toBe(
+      "Chef's special\n\nSlow cooked & spicy\nServes two\n\n• Rice\n• Salad",
+    );
+    expect(result.descriptionHtml).toBe(
+      "<b>Chef's special</b>\n\nSlow cooked &amp; <b>spicy</b>\nServes two\n\n• Rice\n• Salad",
+    );
+  });
+
+  it("should keep only tags Telegram supports", () => {
+    const result = renderDescription(
+      editorJs({
+        type: "paragraph",
+        data: {
+          text: '<mark class="cdx-marker">New</mark> <strong>hot <em>deal</em>',
+        },
+      }),
+    );
+    expect(result.descriptionHtml).toBe("New <b>hot <i>deal</i></b>");
+  });
+
+  it("should drop unsafe links but keep their text", () => {
+    const result = renderDescription(
+      editorJs({
+        type: "paragraph",
+        data: {
+          text: '<a href="https://example.com/?a=1&amp;b=2">Menu</a> <a href="javascript:alert(1)">x</a>',
+        },
+      }),
+    );
+    expect(result.descriptionHtml).toBe('<a href="https://example.com/?a=1&amp;b=2">Menu</a> x');
+    expect(result.description).toBe("Menu x");
+  });
+
+  it("should treat other descriptions as plain text", () => {
+    expect(renderDescription("Fish <3 & chips")).toEqual({
+      description: "Fish <3 & chips",
+      descriptionHtml: "Fish &lt;3 &amp; chips",
+    });
+    expect(renderDescription(null)).toEqual({ description: "", descriptionHtml: "" });
+  });
+});
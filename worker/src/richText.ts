@@ -0,0 +1,250 @@
+// Rich Text Descriptions
+// Saleor stores descriptions as EditorJS JSON ({"blocks": [...]}), whose
+// block text carries inline HTML (<b>, <i>, <a href>, <br>, &nbsp;). Menus
+// get two renderings: plain text (description) and HTML limited to what
+// Telegram's parse_mode=HTML accepts (descriptionHtml). Telegram has no
+// paragraphs or lists, so blocks are separated by blank lines, headers are
+// bold and list items get bullet or number prefixes. Descriptions that
+// aren't EditorJS JSON are treated as plain text.
+
+// Product types (categories) have no description field in Saleor
+export const DESCRIPTION_METADATA_KEY = "tma_description";
+
+interface EditorJsBlock {
+  type?: string;
+  data?: Record<string, any>;
+}
+
+interface Rendered {
+  text: string;
+  html: string;
+}
+
+// EditorJS inline tags mapped to the Telegram tag that renders them
+const INLINE_TAGS: Record<string, string> = {
+  b: "b",
+  strong: "b",
+  i: "i",
+  em: "i",
+  u: "u",
+  s: "s",
+  strike: "s",
+  del: "s",
+  code: "code",
+  a: "a",
+};
+
+const INLINE_TOKEN = /<(\/?)([a-zA-Z][a-zA-Z0-9-]*)([^>]*)>|([^<]+)|</g;
+const HREF_ATTRIBUTE = /href\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))/i;
+const SAFE_HREF = /^(https?:|tg:|mailto:)/i;
+
+const NAMED_ENTITIES: Record<string, string> = {
+  amp: "&",
+  lt: "<",
+  gt: ">",
+  quot: '"',
+  apos: "'",
+  nbsp: " ",
+};
+
+function decodeEntities(value: string): string {
+  return value.replace(/&(#x[0-9a-f]+|#\d+|[a-z]+);/gi, (entity, name: string) => {
+    if (name[0] === "#") {
+      const code =
+        name[1] === "x" || name[1] === "X"
+          ? parseInt(name.slice(2), 16)
+          : parseInt(name.slice(1), 10);
+      return code > 0 && code <= 0x10ffff ? String.fromCodePoint(code) : entity;
+    }
+    return NAMED_ENTITIES[name.toLowerCase()] ?? entity;
+  });
+}
+
+/**
+ * Escape text for Telegram HTML (the only entities it needs)
+ */
+export function escapeTelegramHtml(value: string): string {
+  return value
+    .replace(/&/g, "&amp;")
+    .replace(/</g, "&lt;")
+    .replace(/>/g, "&gt;")
+    .replace(/"/g, "&quot;");
+}
+
+function getSafeHref(attributes: string): string | null {
+  const match = attributes.match(HREF_ATTRIBUTE);
+  const href = decodeEntities(match?.[1] ?? match?.[2] ?? match?.[3] ?? "").trim();
+  return SAFE_HREF.test(href) ? href : null;
+}
+
+/**
+ * Inline EditorJS HTML as plain text
+ */
+function inlineToText(value: unknown): string {
+  if (typeof value !== "string") {
+    return "";
+  }
+  return decodeEntities(
+    value.replace(/<br\s*\/?>/gi, "\n").replace(/<\/?[a-zA-Z][^>]*>/g, ""),
+  ).trim();
+}
+
+/**
+ * Inline EditorJS HTML as Telegram HTML: supported tags are normalised,
+ * everything else is dropped, and tags are always balanced
+ */
+function inlineToHtml(value: unknown): string {
+  if (typeof value !== "string") {
+    return "";
+  }
+  const open: string[] = [];
+  let html = "";
+
+  for (const [, closing, name, attributes, content] of value.matchAll(INLINE_TOKEN)) {
+    if (content !== undefined) {
+      html += escapeTelegramHtml(decodeEntities(content));
+      continue;
+    }
+    if (!name) {
+      html += "&lt;"; // a stray "<"
+      continue;
+    }
+    const tag = name.toLowerCase();
+    if (tag === "br") {
+      html += "\n";
+      continue;
+    }
+    const mapped = INLINE_TAGS[tag];
+    if (!mapped) {
+      continue;
+    }
+
+    if (closing) {
+      const index = open.lastIndexOf(mapped);
+      // Closing an outer tag closes the ones opened inside it
+      while (index !== -1 && open.length > index) {
+        html += `</${open.pop()}>`;
+      }
+      continue;
+    }
+    // Telegram doesn't format inside code, and links can't nest
+    if (open.includes("code") || (mapped === "a" && open.includes("a"))) {
+      continue;
+    }
+    if (mapped === "a") {
+      const href = getSafeHref(attributes);
+      if (!href) {
+        continue;
+      }
+      html += `<a href="${escapeTelegramHtml(href)}">`;
+    } else {
+      html += `<${mapped}>`;
+    }
+    open.push(mapped);
+  }
+
+  while (open.length > 0) {
+    html += `</${open.pop()}>`;
+  }
+  return html.trim();
+}
+
+function renderListItems(items: unknown, ordered: boolean, depth: number): Rendered[] {
+  if (!Array.isArray(items)) {
+    return [];
+  }
+  const indent = "  ".repeat(depth);
+  const lines: Rendered[] = [];
+  items.forEach((item, index) => {
+    // Nested lists store { content, items }, flat lists plain strings
+    const content = typeof item === "string" ? item : item?.content ?? item?.text;
+    const prefix = `${indent}${ordered ? `${index + 1}.` : "•"} `;
+    lines.push({
+      text: prefix + inlineToText(content),
+      html: prefix + inlineToHtml(content),
+    });
+    if (item && typeof item === "object") {
+      lines.push(...renderListItems(item.items, ordered, depth + 1));
+    }
+  });
+  return lines;
+}
+
+function renderBlock(block: EditorJsBlock): Rendered | null {
+  const data = block?.data || {};
+  switch (block?.type) {
+    case "header":
+      return { text: inlineToText(data.text), html: `<b>${inlineToHtml(data.text)}</b>` };
+    case "list": {
+      const lines = renderListItems(data.items, data.style === "ordered", 0);
+      return {
+        text: lines.map((line) => line.text).join("\n"),
+        html: lines.map((line) => line.html).join("\n"),
+      };
+    }
+    case "checklist": {
+      const items: any[] = Array.isArray(data.items) ? data.items : [];
+      const mark = (item: any) => (item?.checked ? "☑" : "☐");
+      return {
+        text: items.map((item) => `${mark(item)} ${inlineToText(item?.text)}`).join("\n"),
+        html: items.map((item) => `${mark(item)} ${inlineToHtml(item?.text)}`).join("\n"),
+      };
+    }
+    case "quote": {
+      const caption = inlineToText(data.caption);
+      return {
+        text: inlineToText(data.text) + (caption ? `\n— ${caption}` : ""),
+        html:
+          `<blockquote>${inlineToHtml(data.text)}</blockquote>` +
+          (caption ? `\n— ${inlineToHtml(data.caption)}` : ""),
+      };
+    }
+    case "code": {
+      const code = typeof data.code === "string" ? data.code : "";
+      return { text: code, html: `<pre>${escapeTelegramHtml(code)}</pre>` };
+    }
+    case "delimiter":
+      return { text: "* * *", html: "* * *" };
+    default:
+      // paragraph, and unknown blocks that carry text
+      return typeof data.text === "string"
+        ? { text: inlineToText(data.text), html: inlineToHtml(data.text) }
+        : null;
+  }
+}
+
+/**
+ * EditorJS blocks of a description, or null when it isn't EditorJS JSON
+ */
+export function parseEditorJs(raw: string | null | undefined): EditorJsBlock[] | null {
+  if (!raw || !raw.trim().startsWith("{")) {
+    return null;
+  }
+  try {
+    const parsed = JSON.parse(raw);
+    return Array.isArray(parsed?.blocks) ? parsed.blocks : null;
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Plain text and Telegram HTML renderings of a Saleor description
+ */
+export function renderDescription(raw: string | null | undefined): {
+  description: string;
+  descriptionHtml: string;
+} {
+  const blocks = parseEditorJs(raw);
+  if (!blocks) {
+    const text = (raw || "").trim();
+    return { description: text, descriptionHtml: escapeTelegramHtml(text) };
+  }
+  const rendered = blocks
+    .map(renderBlock)
+    .filter((block): block is Rendered => !!block && block.text.trim() !== "");
+  return {
+    description: rendered.map((block) => block.text).join("\n\n"),
+    descriptionHtml: rendered.map((block) => block.html).join("\n\n"),
+  };
+}
@@ -0,0 +1,60 @@
+// Saleor Allowlist Tests
+// Tests for saleorAllowlist.ts - operation names and allowlisted operations
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { SaleorClient, SaleorFetch } from "./saleorClient";
+import { isAllowlisted } from "./saleorAllowlist";
+import { normalizeDocument, sha256Hex } from "./operationAudit";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const API_URL = "https://saleor.test/graphql/";
+const SHOP_QUERY = "query ShopName { shop { name } }";
+
+afterEach(() => {
+  delete (globalThis as any).SALEOR_OPERATION_ALLOWLIST;
+  delete (globalThis as any).SALEOR_OPERATION_ALLOWLIST_MODE;
+});
+
+function client(send: SaleorFetch) {
+  return new SaleorClient({ apiUrl: API_URL, token: "t", fetch: send });
+}
+
+describe("isAllowlisted", () => {
+  it("should match names, pinned names and hashes", () => {
+    expect(isAllowlisted(["ShopName"], "ShopName", "abc")).toBe(true);
+    expect(isAllowlisted(["ShopName@abc"], "ShopName", "abc")).toBe(true);
+    expect(isAllowlisted(["ShopName@def"], "ShopName", "abc")).toBe(false);
+    expect(isAllowlisted(["abc"], undefined, "abc")).toBe(true);
+  });
+});
+
+describe("Saleor operation names", () => {
+  it("should send the document's operation name", async () => {
+    const send = vi.fn<SaleorFetch>(async () => Response.json({ data: {} }));
+    await client(send).execute(SHOP_QUERY);
+    expect(JSON.parse(String(send.mock.calls[0][1]?.body)).operationName).toBe("ShopName");
+  });
+
+  it("should refuse operations missing from the allowlist in enforce mode", async () => {
+    const send = vi.fn<SaleorFetch>(async () => Response.json({ data: {} }));
+    const hash = await sha256Hex(normalizeDocument(SHOP_QUERY));
+    (globalThis as any).SALEOR_OPERATION_ALLOWLIST_MODE = "enforce";
+    (globalThis as any).SALEOR_OPERATION_ALLOWLIST = `OrderCancel,ShopName@${hash}`;
+
+    expect((await client(send).execute(SHOP_QUERY)).errors).toBeUndefined();
+    const blocked = await client(send).execute("query ShopName { shop { name description } }");
+    expect(blocked.errors?.[0].message).toBe("Saleor operation ShopName is not allowlisted");
+    expect(send).toHaveBeenCalledTimes(1);
+  });
+
+  it("should only report misses in log mode", async () => {
+    const send = vi.fn<SaleorFetch>(async () => Response.json({ data: {} }));
+    (globalThis as any).SALEOR_OPERATION_ALLOWLIST_MODE = "log";
+    expect((await client(send).execute(SHOP_QUERY)).errors).toBeUndefined();
+    expect(send).toHaveBeenCalledTimes(1);
+  });
+});
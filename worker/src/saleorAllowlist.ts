@@ -0,0 +1,83 @@
+// Saleor Operation Allowlist
+// Every call to Saleor carries its operation name (taken from the document
+// when the caller passes none), so Saleor's logs and the call hooks show
+// which operation ran. SALEOR_OPERATION_ALLOWLIST lists the operations the
+// worker may send: a name ("OrderCancel"), a name pinned to a document hash
+// ("OrderCancel@3f2a..."), or a bare hash. Hashes are SHA-256 of the
+// normalized document, as for inbound operations (operationAudit.ts).
+//
+// SALEOR_OPERATION_ALLOWLIST_MODE=log reports operations missing from the
+// list once per isolate (saleor_operation_not_allowlisted, with the hash to
+// add); enforce refuses to send them. Run in log mode first to collect the
+// list.
+
+import { getListVar, getStringVar } from "./config";
+import { logger } from "./logger";
+import { extractOperationName, normalizeDocument, sha256Hex } from "./operationAudit";
+
+export type SaleorOperationMode = "off" | "log" | "enforce";
+
+const reported = new Set<string>();
+
+/**
+ * SALEOR_OPERATION_ALLOWLIST_MODE (default off)
+ */
+export function getSaleorOperationMode(): SaleorOperationMode {
+  const mode = getStringVar("SALEOR_OPERATION_ALLOWLIST_MODE", "off").toLowerCase();
+  return mode === "log" || mode === "enforce" ? mode : "off";
+}
+
+/**
+ * Operation name sent with a document: the caller's, else the document's
+ */
+export function getSaleorOperationName(query: string, operationName?: string): string | undefined {
+  return extractOperationName(query, operationName) ?? undefined;
+}
+
+/**
+ * Whether an allowlist entry admits the operation
+ */
+export function isAllowlisted(
+  allowlist: string[],
+  operationName: string | undefined,
+  documentHash: string,
+): boolean {
+  return allowlist.some((entry) => {
+    const [name, hash] = entry.split("@");
+    if (hash !== undefined) {
+      return name === operationName && hash === documentHash;
+    }
+    return entry === operationName || entry === documentHash;
+  });
+}
+
+/**
+ * Check an operation against the allowlist; returns an error message when
+ * it may not be sent (enforce mode only)
+ */
+export async function checkSaleorOperation(
+  query: string,
+  operationName: string | undefined,
+): Promise<string | null> {
+  const mode = getSaleorOperationMode();
+  if (mode === "off") {
+    return null;
+  }
+  const documentHash = await sha256Hex(normalizeDocument(query));
+  if (isAllowlisted(getListVar("SALEOR_OPERATION_ALLOWLIST"), operationName, documentHash)) {
+    return null;
+  }
+
+  if (mode === "enforce") {
+    logger.warn("saleor_operation_blocked", { operationName: operationName ?? null, documentHash });
+    return `Saleor operation ${operationName ?? "(anonymous)"} is not allowlisted`;
+  }
+  if (!reported.has(documentHash)) {
+    reported.add(documentHash);
+    logger.warn("saleor_operation_not_allowlisted", {
+      operationName: operationName ?? null,
+      documentHash,
+    });
+  }
+  return null;
+}
@@ -0,0 +1,96 @@
+// Saleor Auth Tests
+// Tests for saleorAuth.ts - staff JWTs, their renewal and the 401 retry
+
+import { describe, it, expect, vi } from "vitest";
+import { JwtTokenProvider, StaticTokenProvider, decodeJwtExpiry } from "./saleorAuth";
+import { SaleorClient, SaleorFetch } from "./saleorClient";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const API_URL = "https://saleor.test/graphql/";
+
+function jwt(exp: number): string {
+  return `header.${btoa(JSON.stringify({ exp }))}.signature`;
+}
+
+// Saleor answering token mutations with the given JWTs, in order
+function tokenServer(tokens: string[]) {
+  return vi.fn<SaleorFetch>(async (_input, init) => {
+    const { query } = JSON.parse(String(init?.body));
+    const field = query.includes("tokenRefresh") ? "tokenRefresh" : "tokenCreate";
+    const token = tokens.shift();
+    return Response.json({ data: { [field]: { token, refreshToken: "refresh", errors: [] } } });
+  });
+}
+
+describe("decodeJwtExpiry", () => {
+  it("should read exp in ms", () => {
+    expect(decodeJwtExpiry(jwt(1_800_000_000))).toBe(1_800_000_000_000);
+    expect(decodeJwtExpiry("app-token")).toBeNull();
+  });
+});
+
+describe("JwtTokenProvider", () => {
+  it("should sign in once, then refresh before expiry", async () => {
+    const now = Date.UTC(2026, 4, 1);
+    const exp = now / 1000 + 300;
+    const send = tokenServer([jwt(exp), jwt(exp + 300)]);
+    const provider = new JwtTokenProvider(API_URL, "bot@example.com", "secret", send);
+
+    expect(await provider.getToken(now)).toBe(jwt(exp));
+    expect(await provider.getToken(now + 60_000)).toBe(jwt(exp));
+    // Within a minute of expiry
+    expect(await provider.getToken(now + 250_000)).toBe(jwt(exp + 300));
+    const fields = send.mock.calls.map(([, init]) => JSON.parse(String(init?.body)).query);
+    expect(fields[0]).toContain("tokenCreate");
+    expect(fields[1]).toContain("tokenRefresh");
+  });
+
+  it("should fail when Saleor refuses the sign-in", async () => {
+    const send = vi.fn<SaleorFetch>(async () =>
+      Response.json({
+        data: { tokenCreate: { token: null, errors: [{ code: "INVALID_CREDENTIALS" }] } },
+      }),
+    );
+    const provider = new JwtTokenProvider(API_URL, "bot@example.com", "wrong", send);
+    await expect(provider.getToken()).rejects.toThrow("sign-in failed");
+  });
+});
+
+describe("SaleorClient token handling", () => {
+  it("should get a new token and retry once on 401", async () => {
+    const tokens = ["first", "second"];
+    const provider = {
+      getToken: vi.fn(async () => tokens[0]),
+      invalidate: vi.fn(() => {
+        tokens.shift();
+        return true;
+      }),
+    };
+    const send = vi.fn<SaleorFetch>(async (_input, init) => {
+      const auth = (init?.headers as Record<string, string>).Authorization;
+      return auth === "Bearer first"
+        ? new Response("Signature has expired", { status: 401 })
+        : Response.json({ data: { shop: { name: "x" } } });
+    });
+    const client = new SaleorClient({ apiUrl: API_URL, tokenProvider: provider, fetch: send });
+
+    expect(await client.execute("{ shop { name } }")).toEqual({ data: { shop: { name: "x" } } });
+    expect(send).toHaveBeenCalledTimes(2);
+  });
+
+  it("should not retry static tokens", async () => {
+    const send = vi.fn<SaleorFetch>(async () => new Response("", { status: 401 }));
+    const client = new SaleorClient({
+      apiUrl: API_URL,
+      tokenProvider: new StaticTokenProvider("app-token"),
+      fetch: send,
+    });
+
+    expect((await client.execute("{ shop { name } }")).errors).toHaveLength(1);
+    expect(send).toHaveBeenCalledTimes(1);
+  });
+});
@@ -0,0 +1,169 @@
+// Saleor Authentication
+// The Saleor client asks a token provider for the bearer token of each
+// request. App tokens (SALEOR_TOKEN) don't expire and are sent as they
+// are. Without one, the worker signs in as a staff user (SALEOR_EMAIL,
+// SALEOR_PASSWORD): tokenCreate returns a short-lived JWT and a refresh
+// token; the JWT is cached per isolate and renewed with tokenRefresh a
+// minute before it expires, or by signing in again once the refresh token
+// is refused. A request Saleor answers with 401 invalidates the cached JWT
+// and is retried once (saleorClient.ts).
+
+import { getVar } from "./config";
+import { logger } from "./logger";
+import { SaleorFetch } from "./saleorClient";
+import {
+  TOKEN_CREATE_MUTATION,
+  TOKEN_REFRESH_MUTATION,
+  TokenCreateMutation,
+  TokenRefreshMutation,
+} from "./saleorOperations";
+
+/**
+ * Source of the bearer token sent to Saleor
+ */
+export interface SaleorTokenProvider {
+  getToken(): Promise<string>;
+  /** Drop a token Saleor refused; false when a new one can't be had */
+  invalidate(): boolean;
+}
+
+// Renew JWTs this long before they expire
+const REFRESH_MARGIN_MS = 60 * 1000;
+// Saleor's default access token lifetime, for tokens without exp
+const DEFAULT_TOKEN_LIFETIME_MS = 5 * 60 * 1000;
+
+/**
+ * A fixed token, e.g. a Saleor App token
+ */
+export class StaticTokenProvider implements SaleorTokenProvider {
+  constructor(private readonly token: string) {}
+
+  async getToken(): Promise<string> {
+    return this.token;
+  }
+
+  invalidate(): boolean {
+    return false;
+  }
+}
+
+/**
+ * Expiry of a JWT in ms since the epoch, or null when it has none
+ */
+export function decodeJwtExpiry(token: string): number | null {
+  const payload = token.split(".")[1];
+  if (!payload) {
+    return null;
+  }
+  try {
+    const json = atob(payload.replace(/-/g, "+").replace(/_/g, "/"));
+    const exp = JSON.parse(json).exp;
+    return typeof exp === "number" ? exp * 1000 : null;
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Staff user JWTs from tokenCreate, renewed with tokenRefresh
+ */
+export class JwtTokenProvider implements SaleorTokenProvider {
+  private token: string | null = null;
+  private refreshToken: string | null = null;
+  private expiresAt = 0;
+  private pending: Promise<string> | null = null;
+
+  constructor(
+    private readonly apiUrl: string,
+    private readonly email: string,
+    private readonly password: string,
+    private readonly fetchImpl?: SaleorFetch,
+  ) {}
+
+  async getToken(now: number = Date.now()): Promise<string> {
+    if (this.token && now < this.expiresAt - REFRESH_MARGIN_MS) {
+      return this.token;
+    }
+    // Concurrent requests share one renewal
+    if (!this.pending) {
+      this.pending = this.renew(now).finally(() => {
+        this.pending = null;
+      });
+    }
+    return this.pending;
+  }
+
+  invalidate(): boolean {
+    this.token = null;
+    this.expiresAt = 0;
+    return true;
+  }
+
+  private async renew(now: number): Promise<string> {
+    if (this.refreshToken) {
+      const data = await this.call<TokenRefreshMutation>(
+        "tokenRefresh",
+        TOKEN_REFRESH_MUTATION,
+        { refreshToken: this.refreshToken },
+      );
+      if (data?.token) {
+        return this.store(data.token, now);
+      }
+      this.refreshToken = null;
+    }
+    const data = await this.call<TokenCreateMutation>("tokenCreate", TOKEN_CREATE_MUTATION, {
+      email: this.email,
+      password: this.password,
+    });
+    if (!data?.token) {
+      throw new Error("Saleor sign-in failed");
+    }
+    this.refreshToken = data.refreshToken ?? null;
+    return this.store(data.token, now);
+  }
+
+  private store(token: string, now: number): string {
+    this.token = token;
+    this.expiresAt = decodeJwtExpiry(token) ?? now + DEFAULT_TOKEN_LIFETIME_MS;
+    logger.info("saleor_token_renewed", { expiresAt: new Date(this.expiresAt).toISOString() });
+    return token;
+  }
+
+  private async call<T>(
+    field: keyof T & string,
+    mutation: string,
+    variables: Record<string, string>,
+  ): Promise<T[keyof T] | null> {
+    const send = this.fetchImpl ?? fetch;
+    const response = await send(this.apiUrl, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ query: mutation, variables }),
+    });
+    if (!response.ok) {
+      logger.error("saleor_token_error", { field, status: response.status });
+      return null;
+    }
+    const json: any = await response.json();
+    const errors = [...(json.errors || []), ...(json.data?.[field]?.errors || [])];
+    if (errors.length > 0) {
+      // Messages may echo the email; codes are enough
+      logger.error("saleor_token_error", {
+        field,
+        codes: errors.map((e: { code?: string }) => e.code ?? "UNKNOWN"),
+      });
+      return null;
+    }
+    return json.data?.[field] ?? null;
+  }
+}
+
+/**
+ * Whether credentials for Saleor are set: an app token, or a staff
+ * user's email and password
+ */
+export function hasSaleorCredentials(): boolean {
+  return Boolean(
+    getVar("SALEOR_TOKEN") || (getVar("SALEOR_EMAIL") && getVar("SALEOR_PASSWORD")),
+  );
+}
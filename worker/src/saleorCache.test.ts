@@ -0,0 +1,74 @@
+// Saleor Cache Tests
+// Tests for saleorCache.ts - cached catalog answers and their invalidation
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { SaleorClient, SaleorFetch } from "./saleorClient";
+import { MemoryCacheStore, invalidateSaleorCache, setSaleorCacheStore } from "./saleorCache";
+import { getStaleCacheTags } from "./saleorWebhooks";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const API_URL = "https://saleor.test/graphql/";
+const QUERY = "query Channels { channels { id } }";
+
+afterEach(() => {
+  setSaleorCacheStore(null);
+  delete (globalThis as any).SALEOR_CACHE_TTL_SECONDS;
+});
+
+describe("MemoryCacheStore", () => {
+  it("should drop the least recently used entry", async () => {
+    const store = new MemoryCacheStore(2);
+    await store.set("a", "1", 60);
+    await store.set("b", "2", 60);
+    await store.get("a");
+    await store.set("c", "3", 60);
+
+    expect(await store.get("b")).toBeNull();
+    expect(await store.get("a")).toBe("1");
+    expect(store.size).toBe(2);
+  });
+});
+
+describe("Saleor response cache", () => {
+  function cachingClient(send: SaleorFetch) {
+    (globalThis as any).SALEOR_CACHE_TTL_SECONDS = "60";
+    setSaleorCacheStore(new MemoryCacheStore());
+    return new SaleorClient({ apiUrl: API_URL, token: "t", fetch: send });
+  }
+
+  it("should answer tagged queries from the cache until invalidated", async () => {
+    const send = vi.fn<SaleorFetch>(async () => Response.json({ data: { channels: [] } }));
+    const client = cachingClient(send);
+
+    await client.execute(QUERY, undefined, undefined, { cache: "channels" });
+    const cached = await client.execute(QUERY, undefined, undefined, { cache: "channels" });
+    await client.execute(QUERY);
+    expect(cached).toEqual({ data: { channels: [] } });
+    expect(send).toHaveBeenCalledTimes(2);
+
+    await invalidateSaleorCache(...getStaleCacheTags("channel_updated"));
+    await client.execute(QUERY, undefined, undefined, { cache: "channels" });
+    expect(send).toHaveBeenCalledTimes(3);
+  });
+
+  it("should not keep answers with errors", async () => {
+    const send = vi.fn<SaleorFetch>(async () => Response.json({ errors: [{ message: "x" }] }));
+    const client = cachingClient(send);
+
+    await client.execute(QUERY, undefined, undefined, { cache: "channels" });
+    await client.execute(QUERY, undefined, undefined, { cache: "channels" });
+    expect(send).toHaveBeenCalledTimes(2);
+  });
+});
+
+describe("getStaleCacheTags", () => {
+  it("should map catalog events to tags", () => {
+    expect(getStaleCacheTags("product_variant_updated")).toEqual(["products"]);
+    expect(getStaleCacheTags("product_type_deleted")).toEqual(["categories", "products"]);
+    expect(getStaleCacheTags("order_paid")).toEqual([]);
+  });
+});
@@ -0,0 +1,172 @@
+// Saleor Response Cache
+// Opt-in cache for read-only catalog queries (channels, product types,
+// products), keyed by API URL, query and variables. Only answers without
+// errors are kept, for SALEOR_CACHE_TTL_SECONDS (0, the default, turns the
+// cache off). Entries live in an LRU map in the isolate, or in the CARTS KV
+// namespace with SALEOR_CACHE_STORE=kv so isolates share them.
+//
+// Each entry belongs to a tag; invalidating a tag bumps its generation, which
+// is part of every key, so old entries are never read again and age out.
+// Saleor webhooks for products, product types and channels invalidate their
+// tag (saleorWebhooks.ts), as do the app's own channel metadata updates. KV
+// is eventually consistent, so other locations may serve old entries for up
+// to a minute after an invalidation.
+
+import { getNumberVar, getVar } from "./config";
+import { readJSON, writeJSON } from "./storage";
+import { SaleorResponse } from "./saleorClient";
+
+export type SaleorCacheTag = "channels" | "categories" | "products";
+
+export interface SaleorCacheStore {
+  get(key: string): Promise<string | null>;
+  set(key: string, value: string, ttlSeconds: number): Promise<void>;
+  getGeneration(tag: SaleorCacheTag): Promise<number>;
+  bumpGeneration(tag: SaleorCacheTag): Promise<void>;
+}
+
+const DEFAULT_MAX_ENTRIES = 500;
+const KV_PREFIX = "saleor_cache:";
+// KV rejects shorter expirations
+const KV_MIN_TTL_SECONDS = 60;
+
+/**
+ * Least recently used entries are dropped beyond maxEntries
+ */
+export class MemoryCacheStore implements SaleorCacheStore {
+  private entries = new Map<string, { value: string; expiresAt: number }>();
+  private generations = new Map<SaleorCacheTag, number>();
+
+  constructor(private maxEntries: number = DEFAULT_MAX_ENTRIES) {}
+
+  async get(key: string): Promise<string | null> {
+    const entry = this.entries.get(key);
+    if (!entry) {
+      return null;
+    }
+    this.entries.delete(key);
+    if (entry.expiresAt <= Date.now()) {
+      return null;
+    }
+    // Map order is insertion order: re-inserting marks it most recent
+    this.entries.set(key, entry);
+    return entry.value;
+  }
+
+  async set(key: string, value: string, ttlSeconds: number): Promise<void> {
+    this.entries.delete(key);
+    this.entries.set(key, { value, expiresAt: Date.now() + ttlSeconds * 1000 });
+    while (this.entries.size > this.maxEntries) {
+      this.entries.delete(this.entries.keys().next().value!);
+    }
+  }
+
+  async getGeneration(tag: SaleorCacheTag): Promise<number> {
+    return this.generations.get(tag) ?? 0;
+  }
+
+  async bumpGeneration(tag: SaleorCacheTag): Promise<void> {
+    this.generations.set(tag, (this.generations.get(tag) ?? 0) + 1);
+  }
+
+  get size(): number {
+    return this.entries.size;
+  }
+}
+
+/**
+ * Entries in the CARTS KV namespace (storage.ts), shared by isolates
+ */
+export class KVCacheStore implements SaleorCacheStore {
+  async get(key: string): Promise<string | null> {
+    return readJSON<string>(`${KV_PREFIX}${key}`);
+  }
+
+  async set(key: string, value: string, ttlSeconds: number): Promise<void> {
+    await writeJSON(`${KV_PREFIX}${key}`, value, {
+      expirationTtl: Math.max(ttlSeconds, KV_MIN_TTL_SECONDS),
+    });
+  }
+
+  async getGeneration(tag: SaleorCacheTag): Promise<number> {
+    return (await readJSON<number>(`${KV_PREFIX}generation:${tag}`)) ?? 0;
+  }
+
+  async bumpGeneration(tag: SaleorCacheTag): Promise<void> {
+    // A timestamp stays unique without reading the current generation
+    await writeJSON(`${KV_PREFIX}generation:${tag}`, Date.now());
+  }
+}
+
+let store: SaleorCacheStore | null = null;
+
+/**
+ * Seconds cached answers are kept (SALEOR_CACHE_TTL_SECONDS, default 0: off)
+ */
+export function getSaleorCacheTtlSeconds(): number {
+  return Math.max(getNumberVar("SALEOR_CACHE_TTL_SECONDS", 0), 0);
+}
+
+/**
+ * Store chosen by SALEOR_CACHE_STORE ("memory", the default, or "kv")
+ */
+export function getSaleorCacheStore(): SaleorCacheStore {
+  if (!store) {
+    store =
+      getVar("SALEOR_CACHE_STORE") === "kv"
+        ? new KVCacheStore()
+        : new MemoryCacheStore(getNumberVar("SALEOR_CACHE_MAX_ENTRIES", DEFAULT_MAX_ENTRIES));
+  }
+  return store;
+}
+
+/**
+ * Replace the store (tests, or a custom backend)
+ */
+export function setSaleorCacheStore(custom: SaleorCacheStore | null): void {
+  store = custom;
+}
+
+async function sha256(text: string): Promise<string> {
+  const digest = await crypto.subtle.digest("SHA-256", new TextEncoder().encode(text));
+  return Array.from(new Uint8Array(digest), (b) => b.toString(16).padStart(2, "0")).join("");
+}
+
+/**
+ * Cache key for a call, or null while the cache is off
+ */
+export async function getSaleorCacheKey(
+  tag: SaleorCacheTag,
+  apiUrl: string,
+  payload: { query: string; variables?: Record<string, any> },
+): Promise<string | null> {
+  if (getSaleorCacheTtlSeconds() <= 0) {
+    return null;
+  }
+  const generation = await getSaleorCacheStore().getGeneration(tag);
+  const hash = await sha256(JSON.stringify([apiUrl, payload.query, payload.variables ?? null]));
+  return `${tag}:${generation}:${hash}`;
+}
+
+export async function readSaleorCache<T>(key: string): Promise<SaleorResponse<T> | null> {
+  const cached = await getSaleorCacheStore().get(key);
+  return cached ? (JSON.parse(cached) as SaleorResponse<T>) : null;
+}
+
+/**
+ * Keep an answer; ones with errors are not cached
+ */
+export async function writeSaleorCache(key: string, response: SaleorResponse): Promise<void> {
+  if (response.errors && response.errors.length > 0) {
+    return;
+  }
+  await getSaleorCacheStore().set(key, JSON.stringify(response), getSaleorCacheTtlSeconds());
+}
+
+/**
+ * Drop the cached answers of tags (their next reads go to Saleor)
+ */
+export async function invalidateSaleorCache(...tags: SaleorCacheTag[]): Promise<void> {
+  const current = getSaleorCacheStore();
+  await Promise.all(tags.map((tag) => current.bumpGeneration(tag)));
+}
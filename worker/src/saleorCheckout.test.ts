@@ -0,0 +1,173 @@
+// Saleor Checkout Tests
+// Tests for saleorCheckout.ts - delivery method selection and the checkout steps
+
+import { describe, it, expect, vi, beforeEach } from "vitest";
+import { PlaceOrderInput } from "./contracts";
+import { logger } from "./logger";
+import { SaleorClient, SaleorFetch } from "./saleorClient";
+import { fetchOrderById } from "./saleorOrder";
+import { createCheckoutOrder, selectDeliveryMethod } from "./saleorCheckout";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+vi.mock("./saleorOrder", async (importOriginal) => ({
+  ...(await importOriginal<typeof import("./saleorOrder")>()),
+  fetchOrderById: vi.fn(),
+}));
+
+vi.mock("./saleorService", async (importOriginal) => ({
+  ...(await importOriginal<typeof import("./saleorService")>()),
+  fetchChannelById: vi.fn(async (id: string) => ({
+    id,
+    slug: "restaurant-a",
+    name: "Restaurant A",
+    isActive: true,
+    currencyCode: "USD",
+    defaultCountry: { code: "US" },
+    categories: [],
+  })),
+}));
+
+const options = {
+  shippingMethods: [
+    { id: "express", name: "Express", active: true, price: { amount: 9 } },
+    { id: "standard", name: "Standard", active: true, price: { amount: 4 } },
+    { id: "free", name: "Free", active: false, price: { amount: 0 } },
+  ],
+  availableCollectionPoints: [{ id: "warehouse-1", name: "Counter" }],
+};
+
+describe("selectDeliveryMethod", () => {
+  it("should pick the cheapest active shipping method", () => {
+    expect(selectDeliveryMethod(options, "DELIVERY")).toBe("standard");
+  });
+
+  it("should prefer the channel's pinned method when active", () => {
+    expect(selectDeliveryMethod(options, "DELIVERY", "express")).toBe("express");
+    expect(selectDeliveryMethod(options, "DELIVERY", "free")).toBe("standard");
+  });
+
+  it("should use a collection point for pickup", () => {
+    expect(selectDeliveryMethod(options, "PICKUP")).toBe("warehouse-1");
+    expect(
+      selectDeliveryMethod(
+        { shippingMethods: [], availableCollectionPoints: [] },
+        "PICKUP",
+      ),
+    ).toBeNull();
+  });
+});
+
+const INPUT: PlaceOrderInput = {
+  restaurantId: "channelA",
+  deliveryLocation: { address: "1 Test Street", city: "Springfield" },
+  items: [{ dishId: "variant-1", quantity: 2 }],
+};
+
+// Successful payload of each checkout step, by operation name
+const STEP_RESULTS: Record<string, [string, unknown]> = {
+  CheckoutCreate: ["checkoutCreate", { checkout: { id: "checkout-1" } }],
+  CheckoutLinesAdd: ["checkoutLinesAdd", { checkout: { id: "checkout-1", ...options } }],
+  CheckoutDeliveryMethodUpdate: ["checkoutDeliveryMethodUpdate", { checkout: { id: "c" } }],
+  CheckoutComplete: [
+    "checkoutComplete",
+    { order: { id: "order-1" }, confirmationNeeded: false },
+  ],
+};
+
+function checkoutClient(
+  failingStep?: string,
+  results = STEP_RESULTS,
+): { client: SaleorClient; steps: string[] } {
+  const steps: string[] = [];
+  const send = vi.fn<SaleorFetch>(async (_, init) => {
+    const { operationName } = JSON.parse(String(init?.body));
+    steps.push(operationName);
+    const [field, result] = results[operationName];
+    return Response.json({
+      data: {
+        [field]:
+          operationName === failingStep
+            ? { errors: [{ field: null, message: `${field} failed`, code: "INVALID" }] }
+            : { ...(result as object), errors: [] },
+      },
+    });
+  });
+  const client = new SaleorClient({
+    apiUrl: "https://saleor.test/graphql/",
+    token: "t",
+    fetch: send,
+    batching: false,
+    rateLimiter: null,
+  });
+  return { client, steps };
+}
+
+describe("createCheckoutOrder", () => {
+  beforeEach(() => {
+    vi.mocked(logger.error).mockClear();
+    vi.mocked(fetchOrderById).mockResolvedValue({
+      id: "order-1",
+      status: "UNFULFILLED",
+      total: { gross: { amount: 20, currency: "USD" } },
+      deliveryAddress: { address: "1 Test Street" },
+      lines: [],
+      createdAt: "2026-10-18T10:00:00.000Z",
+      metadata: {},
+    });
+  });
+
+  it("should run the steps in order and return the completed order", async () => {
+    const { client, steps } = checkoutClient();
+    const result = await createCheckoutOrder(client, INPUT, "channelA", "user-1");
+
+    expect(result.success).toBe(true);
+    expect(result.order?.metadata?.["tma.telegramUserId"]).toBe("user-1");
+    expect(steps).toEqual([
+      "CheckoutCreate",
+      "CheckoutLinesAdd",
+      "CheckoutDeliveryMethodUpdate",
+      "CheckoutComplete",
+    ]);
+  });
+
+  const failures: Array<[string, string, number]> = [
+    ["CheckoutCreate", "CHECKOUT_CREATE_FAILED", 1],
+    ["CheckoutLinesAdd", "CHECKOUT_LINES_FAILED", 2],
+    ["CheckoutDeliveryMethodUpdate", "CHECKOUT_DELIVERY_FAILED", 3],
+    ["CheckoutComplete", "CHECKOUT_COMPLETE_FAILED", 4],
+  ];
+  for (const [step, errorCode, stepsRun] of failures) {
+    it(`should stop at a failed ${step} and log the checkout`, async () => {
+      const { client, steps } = checkoutClient(step);
+      const result = await createCheckoutOrder(client, INPUT, "channelA", "user-1");
+
+      expect(result).toMatchObject({ success: false, errorCode });
+      expect(steps).toHaveLength(stepsRun);
+      expect(logger.error).toHaveBeenCalledWith(
+        "saleor_checkout_error",
+        expect.objectContaining({
+          errorCode,
+          checkoutId: step === "CheckoutCreate" ? null : "checkout-1",
+        }),
+      );
+    });
+  }
+
+  it("should fail without a delivery method for the address", async () => {
+    const { client, steps } = checkoutClient(undefined, {
+      ...STEP_RESULTS,
+      CheckoutLinesAdd: [
+        "checkoutLinesAdd",
+        { checkout: { id: "checkout-1", shippingMethods: [], availableCollectionPoints: [] } },
+      ],
+    });
+    const result = await createCheckoutOrder(client, INPUT, "channelA", "user-1");
+
+    expect(result).toMatchObject({ success: false, errorCode: "NO_DELIVERY_METHOD" });
+    expect(steps).toEqual(["CheckoutCreate", "CheckoutLinesAdd"]);
+  });
+});
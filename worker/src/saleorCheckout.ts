@@ -0,0 +1,343 @@
+// Saleor Checkout Order Pipeline
+// Alternative to draft orders (ORDER_PIPELINE=checkout): builds the order
+// through checkoutCreate → checkoutLinesAdd → checkoutDeliveryMethodUpdate
+// → checkoutComplete, so promotions, taxes and shipping prices computed by
+// Saleor apply automatically. Channels must allow unpaid orders to complete
+// ("Allow unpaid orders" in the channel's checkout settings). A failed step
+// leaves the checkout behind; its ID is logged and Saleor deletes unused
+// checkouts after the channel's checkout TTL.
+
+import { PlaceOrderInput } from "./contracts";
+import { getStringVar } from "./config";
+import { resolveCustomerEmail } from "./customerEmail";
+import { buildTipLine } from "./tips";
+import { buildServiceFeeLine } from "./orderTotals";
+import { logger } from "./logger";
+import { recordToMetadataInput } from "./metadata";
+import {
+  SaleorClient,
+  CHECKOUT_CREATE_MUTATION,
+  CHECKOUT_LINES_ADD_MUTATION,
+  CHECKOUT_DELIVERY_METHOD_UPDATE_MUTATION,
+  CHECKOUT_CUSTOMER_NOTE_UPDATE_MUTATION,
+  CHECKOUT_ADD_PROMO_CODE_MUTATION,
+  CHECKOUT_COMPLETE_MUTATION,
+} from "./saleorClient";
+import {
+  CreateOrderResult,
+  buildOrderMetadata,
+  fetchOrderById,
+} from "./saleorOrder";
+import {
+  CheckoutAddPromoCodeMutation,
+  CheckoutAddPromoCodeMutationVariables,
+  CheckoutCompleteMutation,
+  CheckoutCompleteMutationVariables,
+  CheckoutCreateMutation,
+  CheckoutCreateMutationVariables,
+  CheckoutCustomerNoteUpdateMutation,
+  CheckoutCustomerNoteUpdateMutationVariables,
+  CheckoutDeliveryMethodUpdateMutation,
+  CheckoutDeliveryMethodUpdateMutationVariables,
+  CheckoutLinesAddMutation,
+  CheckoutLinesAddMutationVariables,
+  SaleorAddressInput,
+  SaleorCountryCode,
+  SaleorLanguageCodeEnum,
+} from "./saleorOperations";
+import { fetchChannelById } from "./saleorService";
+import { SaleorError } from "./saleorErrors";
+
+export type OrderPipeline = "draft" | "checkout";
+
+// Channel metadata key pinning the shipping method used for deliveries
+export const SHIPPING_METHOD_METADATA_KEY = "tma_shipping_method_id";
+
+interface CheckoutDeliveryOptions {
+  shippingMethods: Array<{
+    id: string;
+    name: string;
+    active: boolean;
+    price: { amount: number } | null;
+  }>;
+  availableCollectionPoints: Array<{ id: string; name: string }>;
+}
+
+/**
+ * Failure of one checkout step, mapped to a CreateOrderResult error code
+ */
+class CheckoutStepError extends Error {
+  constructor(
+    message: string,
+    public readonly errorCode: string,
+    public readonly saleorError?: SaleorError,
+  ) {
+    super(message);
+    this.name = "CheckoutStepError";
+  }
+}
+
+/**
+ * Order pipeline in use (ORDER_PIPELINE, default "draft")
+ */
+export function getOrderPipeline(): OrderPipeline {
+  return getStringVar("ORDER_PIPELINE", "draft").toLowerCase() === "checkout"
+    ? "checkout"
+    : "draft";
+}
+
+/**
+ * Run one checkout mutation, throwing on transport or validation errors
+ */
+async function runStep<T>(
+  client: SaleorClient,
+  mutation: string,
+  field: keyof T & string,
+  variables: Record<string, any>,
+  errorCode: string,
+): Promise<NonNullable<T[keyof T]>> {
+  const response = await client.execute<T>(mutation, variables);
+  const saleorError = SaleorError.fromResponse(response, field);
+  if (saleorError) {
+    throw new CheckoutStepError(saleorError.message, errorCode, saleorError);
+  }
+  const result = response.data?.[field];
+  if (!result) {
+    throw new CheckoutStepError(`${field} returned no data`, errorCode);
+  }
+  return result as NonNullable<T[keyof T]>;
+}
+
+/**
+ * Pick the delivery method: a collection point for pickup, otherwise the
+ * channel's pinned shipping method or the cheapest active one
+ */
+export function selectDeliveryMethod(
+  options: CheckoutDeliveryOptions,
+  fulfillmentType: string,
+  pinnedShippingMethodId?: string,
+): string | null {
+  if (fulfillmentType === "PICKUP") {
+    return options.availableCollectionPoints[0]?.id ?? null;
+  }
+  const active = options.shippingMethods.filter((method) => method.active);
+  if (pinnedShippingMethodId) {
+    const pinned = active.find((method) => method.id === pinnedShippingMethodId);
+    if (pinned) {
+      return pinned.id;
+    }
+  }
+  const cheapest = [...active].sort(
+    (a, b) => (a.price?.amount ?? 0) - (b.price?.amount ?? 0),
+  )[0];
+  return cheapest?.id ?? null;
+}
+
+/**
+ * Create an order through the Saleor checkout API
+ * Input is validated by createSaleorOrder before it delegates here
+ */
+export async function createCheckoutOrder(
+  client: SaleorClient,
+  input: PlaceOrderInput,
+  channelId: string,
+  userId: string,
+  userName?: string,
+  userLanguage?: string,
+): Promise<CreateOrderResult> {
+  const channel = await fetchChannelById(channelId);
+  if (!channel) {
+    return {
+      success: false,
+      error: "Restaurant not found",
+      errorCode: "MISSING_CHANNEL",
+    };
+  }
+
+  const [firstName, ...rest] = (userName || "Telegram user").split(" ");
+  const address: SaleorAddressInput = {
+    firstName,
+    lastName: rest.join(" "),
+    streetAddress1: input.deliveryLocation.address,
+    city: input.deliveryLocation.city || "",
+    country: (input.deliveryLocation.country ||
+      channel.defaultCountry?.code ||
+      "") as SaleorCountryCode,
+  };
+  const fulfillmentType = input.fulfillmentType || "DELIVERY";
+  let checkoutId: string | undefined;
+
+  try {
+    const email = await resolveCustomerEmail(userId);
+    const checkout: CheckoutCreateMutationVariables = {
+      input: {
+        channel: channel.slug,
+        email,
+        lines: [],
+        billingAddress: address,
+        ...(fulfillmentType === "DELIVERY" ? { shippingAddress: address } : {}),
+        languageCode: userLanguage
+          ? (userLanguage.toUpperCase().split("-")[0] as SaleorLanguageCodeEnum)
+          : undefined,
+      },
+    };
+    const created = await runStep<CheckoutCreateMutation>(
+      client,
+      CHECKOUT_CREATE_MUTATION,
+      "checkoutCreate",
+      checkout,
+      "CHECKOUT_CREATE_FAILED",
+    );
+    checkoutId = created.checkout?.id;
+    if (!checkoutId) {
+      throw new CheckoutStepError(
+        "checkoutCreate returned no checkout",
+        "CHECKOUT_CREATE_FAILED",
+      );
+    }
+
+    // Custom line prices need the app's HANDLE_CHECKOUTS permission
+    const tipLine = buildTipLine(input.tipAmount);
+    const serviceFeeLine = buildServiceFeeLine(input.serviceFee);
+    const lines: CheckoutLinesAddMutationVariables = {
+      id: checkoutId,
+      lines: [
+        ...input.items.map((item) => ({
+          variantId: item.dishId,
+          quantity: item.quantity,
+        })),
+        ...(tipLine ? [{ ...tipLine, forceNewLine: true }] : []),
+        ...(serviceFeeLine ? [{ ...serviceFeeLine, forceNewLine: true }] : []),
+      ],
+    };
+    const withLines = await runStep<CheckoutLinesAddMutation>(
+      client,
+      CHECKOUT_LINES_ADD_MUTATION,
+      "checkoutLinesAdd",
+      lines,
+      "CHECKOUT_LINES_FAILED",
+    );
+
+    if (input.voucherCode) {
+      const voucher: CheckoutAddPromoCodeMutationVariables = {
+        id: checkoutId,
+        promoCode: input.voucherCode,
+      };
+      await runStep<CheckoutAddPromoCodeMutation>(
+        client,
+        CHECKOUT_ADD_PROMO_CODE_MUTATION,
+        "checkoutAddPromoCode",
+        voucher,
+        "VOUCHER_APPLY_FAILED",
+      );
+    }
+
+    // Gift cards share the promo code mutation; Saleor charges them at completion
+    if (input.giftCardCode) {
+      const giftCard: CheckoutAddPromoCodeMutationVariables = {
+        id: checkoutId,
+        promoCode: input.giftCardCode,
+      };
+      await runStep<CheckoutAddPromoCodeMutation>(
+        client,
+        CHECKOUT_ADD_PROMO_CODE_MUTATION,
+        "checkoutAddPromoCode",
+        giftCard,
+        "GIFT_CARD_APPLY_FAILED",
+      );
+    }
+
+    const deliveryMethodId = selectDeliveryMethod(
+      withLines.checkout || { shippingMethods: [], availableCollectionPoints: [] },
+      fulfillmentType,
+      channel.metadata?.[SHIPPING_METHOD_METADATA_KEY],
+    );
+    if (!deliveryMethodId) {
+      throw new CheckoutStepError(
+        fulfillmentType === "PICKUP"
+          ? "No pickup point is available for this restaurant"
+          : "No shipping method is available for this address",
+        "NO_DELIVERY_METHOD",
+      );
+    }
+    const delivery: CheckoutDeliveryMethodUpdateMutationVariables = {
+      id: checkoutId,
+      deliveryMethodId,
+    };
+    await runStep<CheckoutDeliveryMethodUpdateMutation>(
+      client,
+      CHECKOUT_DELIVERY_METHOD_UPDATE_MUTATION,
+      "checkoutDeliveryMethodUpdate",
+      delivery,
+      "CHECKOUT_DELIVERY_FAILED",
+    );
+
+    if (input.customerNote) {
+      const note: CheckoutCustomerNoteUpdateMutationVariables = {
+        id: checkoutId,
+        customerNote: input.customerNote,
+      };
+      await runStep<CheckoutCustomerNoteUpdateMutation>(
+        client,
+        CHECKOUT_CUSTOMER_NOTE_UPDATE_MUTATION,
+        "checkoutCustomerNoteUpdate",
+        note,
+        "CHECKOUT_NOTE_FAILED",
+      );
+    }
+
+    // Ownership and scheduling metadata is copied onto the order
+    const metadata = buildOrderMetadata(input, userId, userLanguage);
+    const complete: CheckoutCompleteMutationVariables = {
+      id: checkoutId,
+      metadata: recordToMetadataInput(metadata),
+    };
+    const completed = await runStep<CheckoutCompleteMutation>(
+      client,
+      CHECKOUT_COMPLETE_MUTATION,
+      "checkoutComplete",
+      complete,
+      "CHECKOUT_COMPLETE_FAILED",
+    );
+    if (!completed.order) {
+      throw new CheckoutStepError(
+        completed.confirmationNeeded
+          ? "Payment confirmation is required to complete the checkout"
+          : "checkoutComplete returned no order",
+        "CHECKOUT_COMPLETE_FAILED",
+      );
+    }
+
+    const order = await fetchOrderById(completed.order.id);
+    if (!order) {
+      throw new CheckoutStepError(
+        "Completed order could not be loaded",
+        "ORDER_CREATE_FAILED",
+      );
+    }
+    order.metadata = { ...metadata, ...order.metadata };
+
+    logger.info("order_created", {
+      orderId: order.id,
+      userId,
+      restaurantId: input.restaurantId,
+      itemCount: input.items.length,
+      pipeline: "checkout",
+    });
+    return { success: true, order };
+  } catch (error) {
+    const message = error instanceof Error ? error.message : "Unknown error";
+    const errorCode =
+      error instanceof CheckoutStepError ? error.errorCode : "ORDER_CREATE_FAILED";
+    const saleorError = error instanceof CheckoutStepError ? error.saleorError : undefined;
+    logger.error("saleor_checkout_error", {
+      error: message,
+      errorCode,
+      saleorCode: saleorError?.code,
+      userId,
+      // Abandoned checkout, if one was created
+      checkoutId: checkoutId ?? null,
+    });
+    return { success: false, error: message, errorCode, saleorError };
+  }
+}
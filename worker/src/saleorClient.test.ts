@@ -0,0 +1,88 @@
+// Saleor Client Tests
+// Tests for saleorClient.ts - timeouts and custom transports
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { SaleorClient, SaleorFetch } from "./saleorClient";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+// Answers only when the request is aborted
+const hangingFetch: SaleorFetch = (_input, init) =>
+  new Promise((_resolve, reject) => {
+    init?.signal?.addEventListener("abort", () => reject(new Error("aborted")));
+  });
+
+const API_URL = "https://saleor.test/graphql/";
+
+afterEach(() => {
+  delete (globalThis as any).SALEOR_TIMEOUT_MS;
+  delete (globalThis as any).SALEOR_TRANSPORT;
+});
+
+describe("SaleorClient", () => {
+  it("should send requests through an injected fetch", async () => {
+    const send = vi.fn<SaleorFetch>(async () =>
+      Response.json({ data: { shop: { name: "x" } } }),
+    );
+    const client = new SaleorClient({ apiUrl: API_URL, token: "t", fetch: send });
+
+    expect(await client.execute("{ shop { name } }")).toEqual({ data: { shop: { name: "x" } } });
+    const [url, init] = send.mock.calls[0];
+    expect(url).toBe(API_URL);
+    expect((init?.headers as Record<string, string>).Authorization).toBe("Bearer t");
+  });
+
+  it("should use the SALEOR_TRANSPORT binding by default", async () => {
+    const send = vi.fn<SaleorFetch>(async () => Response.json({ data: {} }));
+    (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+    const client = new SaleorClient({ apiUrl: API_URL, token: "t" });
+
+    await client.execute("{ shop { name } }");
+    expect(send).toHaveBeenCalledTimes(1);
+  });
+
+  it("should abort calls that take longer than the timeout", async () => {
+    (globalThis as any).SALEOR_TIMEOUT_MS = "10";
+    const client = new SaleorClient({ apiUrl: API_URL, token: "t", fetch: hangingFetch });
+
+    const response = await client.execute("{ shop { name } }");
+    expect(response.errors?.[0].message).toBe("Saleor did not answer within 10ms");
+
+    // Per-call timeouts win over the client's
+    const slow = new SaleorClient({
+      apiUrl: API_URL,
+      token: "t",
+      timeoutMs: 60000,
+      fetch: hangingFetch,
+    });
+    const fast = await slow.execute("{ shop { name } }", undefined, undefined, { timeoutMs: 5 });
+    expect(fast.errors?.[0].message).toBe("Saleor did not answer within 5ms");
+  });
+
+  it("should send calls made together as one batch", async () => {
+    const send = vi.fn<SaleorFetch>(async (_input, init) => {
+      const batch = JSON.parse(String(init?.body));
+      return Response.json(
+        batch.map((op: { operationName: string }) => ({ data: { name: op.operationName } })),
+      );
+    });
+    const client = new SaleorClient({ apiUrl: API_URL, fetch: send, batching: true });
+
+    const [a, b] = await Promise.all([
+      client.execute("query A { shop { name } }", undefined, "A"),
+      client.execute("query B { shop { name } }", undefined, "B"),
+    ]);
+    expect(a.data).toEqual({ name: "A" });
+    expect(b.data).toEqual({ name: "B" });
+    expect(send).toHaveBeenCalledTimes(1);
+
+    // A call on its own is sent as usual
+    send.mockResolvedValueOnce(Response.json({ data: { name: "C" } }));
+    expect((await client.execute("query C { shop { name } }", undefined, "C")).data).toEqual({
+      name: "C",
+    });
+  });
+});
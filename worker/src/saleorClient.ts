@@ -2,14 +2,174 @@
 // Provides GraphQL client for Saleor API communication
 // See: task/phase-9-improve-code.md
 
-import { logger, isDebugModeEnabled } from "./logger";
+import { logger } from "./logger";
+import { recordSaleorFailure, recordSaleorSuccess } from "./health";
+import { getBooleanVar, getNumberVar, getVar } from "./config";
+import { getSaleorTarget, isSecondaryTargetConfigured } from "./saleorTargets";
+import { isSaleorMockMode } from "./saleorFixtures";
+import { SaleorError } from "./saleorErrors";
+import { SaleorHook, notifySaleorHooks, redactVariables } from "./saleorHooks";
+import {
+  SaleorCacheTag,
+  getSaleorCacheKey,
+  readSaleorCache,
+  writeSaleorCache,
+} from "./saleorCache";
+import { TokenBucket, getSaleorRateLimiter } from "./saleorRateLimit";
+import { checkSaleorOperation, getSaleorOperationName } from "./saleorAllowlist";
+import {
+  CHANNEL_PING_QUERY,
+  ChannelPingQuery,
+  ChannelPingQueryVariables,
+  SHOP_PING_QUERY,
+  ShopPingQuery,
+} from "./saleorOperations";
+import {
+  JwtTokenProvider,
+  SaleorTokenProvider,
+  StaticTokenProvider,
+  hasSaleorCredentials,
+} from "./saleorAuth";
+
+// Workers' fetch has no proxy, CA bundle or connection pool settings:
+// requests to Saleor go through a custom transport instead, either one
+// passed in SaleorConfig.fetch or a SALEOR_TRANSPORT binding (a service
+// binding to a proxy Worker, or an mTLS certificate binding)
+const DEFAULT_TIMEOUT_MS = 20000;
+const DEFAULT_MAX_RESPONSE_BYTES = 10 * 1024 * 1024;
+
+/**
+ * Sends a request to Saleor, same signature as the global fetch
+ */
+export type SaleorFetch = (input: RequestInfo, init?: RequestInit) => Promise<Response>;
 
 /**
  * Saleor client configuration
  */
 export interface SaleorConfig {
   apiUrl: string;
-  token: string;
+  token?: string; // static token, e.g. an app token
+  tokenProvider?: SaleorTokenProvider; // used instead of token
+  timeoutMs?: number; // default SALEOR_TIMEOUT_MS; 0 disables the timeout
+  fetch?: SaleorFetch; // default SALEOR_TRANSPORT, else the global fetch
+  hooks?: SaleorHook[]; // called after the globally registered hooks
+  batching?: boolean; // default SALEOR_BATCHING
+  rateLimiter?: TokenBucket | null; // default from SALEOR_RATE_LIMIT_RPS; null turns it off
+  maxResponseBytes?: number; // default SALEOR_MAX_RESPONSE_BYTES; 0 turns the limit off
+}
+
+/**
+ * Result of SaleorClient.ping
+ */
+export interface SaleorPing {
+  ok: boolean;
+  shopName: string | null;
+  channelSlug: string | null; // the checked channel, when one was given
+  durationMs: number;
+  error: string | null;
+}
+
+/**
+ * Per-call options for execute
+ */
+export interface ExecuteOptions {
+  timeoutMs?: number; // overrides the client's timeout for this call
+  batch?: boolean; // false sends the call on its own even with batching on
+  cache?: SaleorCacheTag; // read-only query whose answer may be cached (saleorCache.ts)
+}
+
+interface SaleorPayload {
+  query: string;
+  variables?: Record<string, any>;
+  operationName?: string;
+}
+
+interface SendResult {
+  result: SaleorResponse<any>;
+  status: number | null;
+}
+
+interface QueuedCall {
+  payload: SaleorPayload;
+  timeoutMs?: number;
+  resolve: (result: SendResult) => void;
+}
+
+// Saleor's GraphQL view runs a JSON array of operations as one request and
+// answers with an array of results in the same order
+const MAX_BATCH_SIZE = 10;
+
+/**
+ * Whether calls made in the same tick are batched (SALEOR_BATCHING)
+ */
+export function isSaleorBatchingEnabled(): boolean {
+  return getBooleanVar("SALEOR_BATCHING");
+}
+
+/**
+ * Timeout in ms for Saleor calls without their own; 0 disables it
+ */
+export function getSaleorTimeoutMs(): number {
+  return Math.max(0, getNumberVar("SALEOR_TIMEOUT_MS", DEFAULT_TIMEOUT_MS));
+}
+
+/**
+ * Largest response body read from Saleor (SALEOR_MAX_RESPONSE_BYTES,
+ * default 10 MB); 0 disables the limit
+ */
+export function getSaleorMaxResponseBytes(): number {
+  return Math.max(0, getNumberVar("SALEOR_MAX_RESPONSE_BYTES", DEFAULT_MAX_RESPONSE_BYTES));
+}
+
+// Error code of answers dropped for exceeding the size limit
+export const RESPONSE_TOO_LARGE = "RESPONSE_TOO_LARGE";
+
+export class SaleorResponseTooLargeError extends Error {
+  constructor(readonly limitBytes: number) {
+    super(`Saleor response exceeded ${limitBytes} bytes`);
+    this.name = "SaleorResponseTooLargeError";
+  }
+}
+
+/**
+ * Parse a JSON body, reading at most maxBytes of it: a misbehaving
+ * upstream can't exhaust the isolate's memory. The body is decoded as it
+ * streams in and the read is cancelled once the limit is passed.
+ */
+export async function readJsonWithin(response: Response, maxBytes: number): Promise<any> {
+  if (maxBytes <= 0 || !response.body) {
+    return response.json();
+  }
+  if (Number(response.headers.get("Content-Length")) > maxBytes) {
+    await response.body.cancel();
+    throw new SaleorResponseTooLargeError(maxBytes);
+  }
+
+  const reader = response.body.getReader();
+  const decoder = new TextDecoder();
+  let text = "";
+  let size = 0;
+  for (;;) {
+    const { done, value } = await reader.read();
+    if (done) {
+      break;
+    }
+    size += value.byteLength;
+    if (size > maxBytes) {
+      await reader.cancel();
+      throw new SaleorResponseTooLargeError(maxBytes);
+    }
+    text += decoder.decode(value, { stream: true });
+  }
+  return JSON.parse(text + decoder.decode());
+}
+
+/**
+ * Fetch of the SALEOR_TRANSPORT binding, if one is bound
+ */
+export function getSaleorTransport(): SaleorFetch | undefined {
+  const binding = (globalThis as any).SALEOR_TRANSPORT;
+  return typeof binding?.fetch === "function" ? binding.fetch.bind(binding) : undefined;
 }
 
 /**
@@ -21,160 +181,382 @@ export interface SaleorResponse<T = any> {
     message: string;
     locations?: Array<{ line: number; column: number }>;
     path?: string[];
+    extensions?: Record<string, any>;
   }>;
 }
 
+/**
+ * One call's result from a batch response; an error for the whole request
+ * goes to every call
+ */
+function pickBatchResult(result: any, index: number, size: number): SaleorResponse {
+  if (Array.isArray(result)) {
+    return result.length === size
+      ? result[index]
+      : { errors: [{ message: "Saleor answered the batch with the wrong number of results" }] };
+  }
+  return result?.errors ? result : { errors: [{ message: "Saleor did not answer the batch" }] };
+}
+
 /**
  * Saleor GraphQL client for executing queries and mutations
  */
 export class SaleorClient {
-  private apiUrl: string;
-  private token: string;
+  readonly apiUrl: string;
+  private tokens: SaleorTokenProvider;
+  private timeoutMs?: number;
+  private fetchImpl?: SaleorFetch;
+  private hooks: SaleorHook[];
+  private batching?: boolean;
+  private rateLimiter?: TokenBucket | null;
+  private maxResponseBytes?: number;
+  private queue: QueuedCall[] = [];
 
   constructor(config: SaleorConfig) {
     this.apiUrl = config.apiUrl;
-    this.token = config.token;
+    this.tokens = config.tokenProvider ?? new StaticTokenProvider(config.token ?? "");
+    this.timeoutMs = config.timeoutMs;
+    this.fetchImpl = config.fetch;
+    this.hooks = config.hooks ?? [];
+    this.batching = config.batching;
+    this.rateLimiter = config.rateLimiter;
+    this.maxResponseBytes = config.maxResponseBytes;
   }
 
   /**
    * Execute a GraphQL query/mutation against Saleor API
+   * A call still waiting after the timeout is aborted and returns an error.
+   * Each call is reported to the Saleor hooks (saleorHooks.ts).
+   * With batching on, calls made in the same tick share one request.
+   * Calls with a cache tag are answered from the cache while it's on.
+   * The operation name defaults to the document's; operations missing from
+   * SALEOR_OPERATION_ALLOWLIST aren't sent in enforce mode.
    */
   async execute<T = any>(
     query: string,
     variables?: Record<string, any>,
     operationName?: string,
+    options: ExecuteOptions = {},
   ): Promise<SaleorResponse<T>> {
-    const headers: Record<string, string> = {
-      "Content-Type": "application/json",
-    };
+    const name = getSaleorOperationName(query, operationName);
+    const blocked = await checkSaleorOperation(query, name);
+    if (blocked) {
+      return { errors: [{ message: blocked }] };
+    }
+
+    const payload = { query, variables, operationName: name };
+    const cacheKey = options.cache
+      ? await getSaleorCacheKey(options.cache, this.apiUrl, payload)
+      : null;
+    const cached = cacheKey ? await readSaleorCache<T>(cacheKey) : null;
+    if (cached) {
+      return cached;
+    }
+
+    const startedAt = Date.now();
+    const { result, status } =
+      options.batch !== false && (this.batching ?? isSaleorBatchingEnabled())
+        ? await this.enqueue(payload, options)
+        : await this.send(payload, options);
+    notifySaleorHooks(
+      {
+        apiUrl: this.apiUrl,
+        operationName: name ?? null,
+        query,
+        variables: variables ? redactVariables(variables) : null,
+        durationMs: Date.now() - startedAt,
+        status,
+        error: result.errors?.[0]?.message ?? null,
+      },
+      this.hooks,
+    );
+    if (cacheKey) {
+      await writeSaleorCache(cacheKey, result);
+    }
+    return result;
+  }
+
+  /**
+   * Queue a call for the next batch; the batch goes out once the current
+   * tick's calls are in, or as soon as it is full
+   */
+  private enqueue(payload: SaleorPayload, options: ExecuteOptions): Promise<SendResult> {
+    return new Promise((resolve) => {
+      this.queue.push({ payload, timeoutMs: options.timeoutMs, resolve });
+      if (this.queue.length >= MAX_BATCH_SIZE) {
+        this.flush();
+      } else if (this.queue.length === 1) {
+        setTimeout(() => this.flush(), 0);
+      }
+    });
+  }
 
-    if (this.token) {
-      headers["Authorization"] = `Bearer ${this.token}`;
+  private flush(): void {
+    const calls = this.queue.splice(0, MAX_BATCH_SIZE);
+    if (calls.length === 0) {
+      return;
     }
+    if (calls.length === 1) {
+      const [call] = calls;
+      this.send(call.payload, { timeoutMs: call.timeoutMs }).then(call.resolve);
+      return;
+    }
+    // The longest timeout of the batch applies to all of it
+    const timeouts = calls.map((call) => call.timeoutMs ?? this.getTimeoutMs());
+    const timeoutMs = timeouts.includes(0) ? 0 : Math.max(...timeouts);
+    this.send(
+      calls.map((call) => call.payload),
+      { timeoutMs },
+    ).then(({ result, status }) => {
+      calls.forEach((call, index) =>
+        call.resolve({ result: pickBatchResult(result, index, calls.length), status }),
+      );
+    });
+  }
+
+  private getTimeoutMs(options: ExecuteOptions = {}): number {
+    return options.timeoutMs ?? this.timeoutMs ?? getSaleorTimeoutMs();
+  }
 
-    if (isDebugModeEnabled()) {
-      console.log("[SALEOR] Calling API:", this.apiUrl);
-      console.log("[SALEOR] Query:", query.substring(0, 200));
+  private async send(
+    payload: SaleorPayload | SaleorPayload[],
+    options: ExecuteOptions,
+  ): Promise<SendResult> {
+    const timeoutMs = this.getTimeoutMs(options);
+    const operationName = Array.isArray(payload)
+      ? payload.map((p) => p.operationName).join(",")
+      : payload.operationName;
+
+    // Time spent waiting for the rate limiter counts against the timeout
+    const limiter =
+      this.rateLimiter === undefined ? getSaleorRateLimiter(this.apiUrl) : this.rateLimiter;
+    const waitedMs = limiter ? await limiter.take(timeoutMs) : 0;
+    if (waitedMs === null) {
+      logger.warn("saleor_rate_limited", { operationName, timeoutMs });
+      return {
+        result: { errors: [{ message: `Saleor rate limit left no slot within ${timeoutMs}ms` }] },
+        status: null,
+      };
     }
+    const remainingMs = timeoutMs > 0 ? Math.max(1, timeoutMs - waitedMs) : 0;
+
+    const controller = new AbortController();
+    const timer =
+      remainingMs > 0 ? setTimeout(() => controller.abort(), remainingMs) : undefined;
+    const send = this.fetchImpl ?? getSaleorTransport() ?? fetch;
+    const body = JSON.stringify(payload);
 
     try {
-      const response = await fetch(this.apiUrl, {
-        method: "POST",
-        headers,
-        body: JSON.stringify({
-          query,
-          variables,
-          operationName,
-        }),
-      });
+      let response = await this.post(send, body, controller.signal);
+      // Expired or revoked JWT: get a new one and retry once
+      if (response.status === 401 && this.tokens.invalidate()) {
+        logger.warn("saleor_token_rejected", { operationName });
+        response = await this.post(send, body, controller.signal);
+      }
 
       if (!response.ok) {
+        recordSaleorFailure();
         logger.error("saleor_api_error", {
           status: response.status,
           statusText: response.statusText,
         });
         return {
-          errors: [
-            {
-              message: `Saleor API error: ${response.status} ${response.statusText}`,
-            },
-          ],
+          result: {
+            errors: [
+              {
+                message: `Saleor API error: ${response.status} ${response.statusText}`,
+              },
+            ],
+          },
+          status: response.status,
         };
       }
 
-      const json = await response.json();
-      
-      if (isDebugModeEnabled()) {
-        console.log("[SALEOR] Response:", JSON.stringify(json).substring(0, 500));
-      }
-      
-      return json;
+      const json = await readJsonWithin(
+        response,
+        this.maxResponseBytes ?? getSaleorMaxResponseBytes(),
+      );
+      recordSaleorSuccess();
+      return { result: json, status: response.status };
     } catch (error) {
+      recordSaleorFailure();
+      if (error instanceof SaleorResponseTooLargeError) {
+        logger.error("saleor_response_too_large", { operationName, limitBytes: error.limitBytes });
+        return {
+          result: {
+            errors: [{ message: error.message, extensions: { code: RESPONSE_TOO_LARGE } }],
+          },
+          status: null,
+        };
+      }
+      if (controller.signal.aborted) {
+        logger.error("saleor_timeout", { operationName, timeoutMs });
+        return {
+          result: { errors: [{ message: `Saleor did not answer within ${timeoutMs}ms` }] },
+          status: null,
+        };
+      }
       logger.error("saleor_network_error", {
         error: error instanceof Error ? error.message : "Unknown error",
       });
       return {
-        errors: [
-          {
-            message:
-              error instanceof Error
-                ? error.message
-                : "Network error connecting to Saleor",
-          },
-        ],
+        result: {
+          errors: [
+            {
+              message:
+                error instanceof Error
+                  ? error.message
+                  : "Network error connecting to Saleor",
+            },
+          ],
+        },
+        status: null,
       };
+    } finally {
+      clearTimeout(timer);
     }
   }
 
+  private async post(send: SaleorFetch, body: string, signal: AbortSignal): Promise<Response> {
+    const headers: Record<string, string> = {
+      "Content-Type": "application/json",
+    };
+    const token = await this.tokens.getToken();
+    if (token) {
+      headers["Authorization"] = `Bearer ${token}`;
+    }
+    return send(this.apiUrl, { method: "POST", headers, body, signal });
+  }
+
   /**
-   * Execute a mutation with error handling
+   * Execute a mutation, collecting its errors into a SaleorError
    */
   async mutate<T = any>(
     mutation: string,
     variables?: Record<string, any>,
     operationName?: string,
-  ): Promise<{ data?: T; error?: string }> {
-    const response = await this.execute<T>(mutation, variables, operationName);
+    payloadField?: string,
+    options?: ExecuteOptions,
+  ): Promise<{ data?: T; error?: SaleorError }> {
+    const response = await this.execute<T>(mutation, variables, operationName, options);
 
-    if (response.errors && response.errors.length > 0) {
-      const errorMessage = response.errors.map((e) => e.message).join(", ");
-      logger.error("saleor_mutation_error", { error: errorMessage });
-      return { error: errorMessage };
+    // Top-level errors, plus the payload's errors when payloadField is given
+    const error = SaleorError.fromResponse(response, payloadField);
+    if (error) {
+      logger.error("saleor_mutation_error", {
+        error: error.message,
+        code: error.code,
+        field: error.field,
+      });
+      return { data: response.data, error };
     }
 
     return { data: response.data };
   }
-}
 
-// ============================================================
-// Saleor GraphQL Fragments and Mutations
-// ============================================================
+  /**
+   * Check that Saleor answers, accepts the token and, when a channel ID is
+   * given, has that channel. Sent on its own so a batch can't hide a failure.
+   */
+  async ping(channelId?: string): Promise<SaleorPing> {
+    const startedAt = Date.now();
+    let response: SaleorResponse<ChannelPingQuery | ShopPingQuery>;
+    if (channelId) {
+      const variables: ChannelPingQueryVariables = { channel: channelId };
+      response = await this.execute<ChannelPingQuery>(
+        CHANNEL_PING_QUERY,
+        variables,
+        "ChannelPing",
+        { batch: false },
+      );
+    } else {
+      response = await this.execute<ShopPingQuery>(SHOP_PING_QUERY, undefined, "ShopPing", {
+        batch: false,
+      });
+    }
+    const durationMs = Date.now() - startedAt;
+    const shopName = response.data?.shop?.name ?? null;
+    const channel = response.data && "channel" in response.data ? response.data.channel : null;
 
-/**
- * OrderCreate mutation for creating orders in Saleor
- */
-export const ORDER_CREATE_MUTATION = `
-  mutation OrderCreate($input: OrderCreateInput!) {
-    orderCreate(input: $input) {
-      order {
-        id
-        number
-        status
-        total {
-          gross {
-            amount
-            currency
-          }
-        }
-        shippingAddress {
-          streetAddress1
-          city
-          country {
-            code
-          }
-        }
-        lines {
-          id
-          productName
-          quantity
-        }
-        createdAt
-      }
-      errors {
-        field
-        message
-        code
-      }
+    let error = response.errors?.[0]?.message ?? (shopName ? null : "Saleor sent no shop");
+    if (!error && channelId && !channel) {
+      error = `Channel ${channelId} not found`;
     }
+    return { ok: !error, shopName, channelSlug: channel?.slug ?? null, durationMs, error };
   }
-`;
+}
 
+// ============================================================
+// Saleor GraphQL Operations
+// ============================================================
 
+// Operations generated from saleor/operations (npm run saleor:codegen), typed
+// and checked against saleor/schema.graphql; import their result types from
+// ./saleorOperations
+export {
+  AVAILABLE_PAYMENT_GATEWAYS_QUERY,
+  CATALOG_HEALTH_QUERY,
+  CATALOG_METADATA_QUERY,
+  CHECKOUT_ADD_PROMO_CODE_MUTATION,
+  CHECKOUT_COMPLETE_MUTATION,
+  CHECKOUT_CREATE_MUTATION,
+  CHECKOUT_CUSTOMER_NOTE_UPDATE_MUTATION,
+  CHECKOUT_DELIVERY_METHOD_UPDATE_MUTATION,
+  CHECKOUT_LINES_ADD_MUTATION,
+  CUSTOMER_CREATE_MUTATION,
+  DELETE_METADATA_MUTATION,
+  DISH_AVAILABILITY_QUERY,
+  DISH_DETAIL_QUERY,
+  DISH_PREP_MINUTES_QUERY,
+  DRAFT_ORDER_COMPLETE_MUTATION,
+  DRAFT_ORDER_CREATE_MUTATION,
+  DRAFT_ORDER_DELETE_MUTATION,
+  DRAFT_ORDER_VOUCHER_UPDATE_MUTATION,
+  GIFT_CARD_BY_CODE_QUERY,
+  LINKED_CUSTOMER_QUERY,
+  LOYALTY_CUSTOMER_QUERY,
+  ORDER_ADD_NOTE_MUTATION,
+  ORDER_CANCEL_MUTATION,
+  ORDER_DISCOUNT_ADD_MUTATION,
+  ORDER_FULFILL_MUTATION,
+  ORDER_FULFILLMENT_LINES_QUERY,
+  ORDER_MARK_AS_PAID_MUTATION,
+  ORDER_METADATA_QUERY,
+  PRODUCTS_METADATA_QUERY,
+  TRANSACTION_INITIALIZE_MUTATION,
+  TRANSACTION_REQUEST_ACTION_MUTATION,
+  UPDATE_METADATA_MUTATION,
+  UPDATE_PRIVATE_METADATA_MUTATION,
+  VARIANT_PRICING_QUERY,
+  VOUCHER_BY_CODE_QUERY,
+  VOUCHER_CHANNEL_LISTING_UPDATE_MUTATION,
+  VOUCHER_CREATE_MUTATION,
+} from "./saleorOperations";
 
 // Module-level variables for client state
 let saleorClientInstance: SaleorClient | null = null;
 let configuredUrl: string | null = null;
 
+/**
+ * Primary client: the app token (SALEOR_TOKEN) when set, else a staff
+ * user's JWTs (SALEOR_EMAIL, SALEOR_PASSWORD; see saleorAuth.ts)
+ */
+function createPrimaryClient(apiUrl: string): SaleorClient {
+  const token = getVar("SALEOR_TOKEN");
+  if (token) {
+    return new SaleorClient({ apiUrl, token });
+  }
+  return new SaleorClient({
+    apiUrl,
+    tokenProvider: new JwtTokenProvider(
+      apiUrl,
+      getVar("SALEOR_EMAIL")!,
+      getVar("SALEOR_PASSWORD")!,
+      getSaleorTransport(),
+    ),
+  });
+}
+
 /**
  * Initialize Saleor client with environment configuration
  * Also stores in globalThis for access throughout the worker lifecycle
@@ -191,11 +573,8 @@ export function initializeSaleorClient(env: {
   (globalThis as any).SALEOR_API_URL = env.SALEOR_API_URL;
   (globalThis as any).SALEOR_TOKEN = env.SALEOR_TOKEN;
   
-  if (env.SALEOR_API_URL && env.SALEOR_TOKEN) {
-    saleorClientInstance = new SaleorClient({
-      apiUrl: env.SALEOR_API_URL,
-      token: env.SALEOR_TOKEN,
-    });
+  if (env.SALEOR_API_URL && hasSaleorCredentials()) {
+    saleorClientInstance = createPrimaryClient(env.SALEOR_API_URL);
     configuredUrl = env.SALEOR_API_URL;
     console.log("  >>> SaleorClient CREATED, url:", configuredUrl);
   } else {
@@ -209,15 +588,17 @@ export function initializeSaleorClient(env: {
  * Check if Saleor is configured - checks both module instance and globalThis
  */
 export function isSaleorConfigured(): boolean {
+  // Mock and demo modes never reach Saleor (saleorFixtures.ts)
+  if (isSaleorMockMode()) return false;
+
   // Check module instance first
   if (saleorClientInstance) return true;
   
   // Fallback: lazy init from globalThis
   const url = (globalThis as any).SALEOR_API_URL;
-  const token = (globalThis as any).SALEOR_TOKEN;
   
-  if (url && token) {
-    saleorClientInstance = new SaleorClient({ apiUrl: url, token });
+  if (url && hasSaleorCredentials()) {
+    saleorClientInstance = createPrimaryClient(url);
     configuredUrl = url;
     return true;
   }
@@ -225,16 +606,44 @@ export function isSaleorConfigured(): boolean {
   return false;
 }
 
+// Secondary (sandbox/staging) client, created on first use
+let secondaryClientInstance: SaleorClient | null = null;
+
+function getSecondaryClient(): SaleorClient | null {
+  if (!isSecondaryTargetConfigured()) {
+    return null;
+  }
+  const apiUrl = getVar("SALEOR_SECONDARY_API_URL")!;
+  if (!secondaryClientInstance || secondaryClientInstance.apiUrl !== apiUrl) {
+    secondaryClientInstance = new SaleorClient({
+      apiUrl,
+      token: getVar("SALEOR_SECONDARY_TOKEN")!,
+    });
+  }
+  return secondaryClientInstance;
+}
+
 /**
  * Get Saleor client instance
+ * Requests routed to the secondary target get the secondary client
  */
 export function getSaleorClient(): SaleorClient | null {
+  if (isSaleorMockMode()) {
+    return null;
+  }
+
+  if (getSaleorTarget() === "secondary") {
+    const secondary = getSecondaryClient();
+    if (secondary) {
+      return secondary;
+    }
+  }
+
   // Lazy init from globalThis if module instance is null
   if (!saleorClientInstance) {
     const url = (globalThis as any).SALEOR_API_URL;
-    const token = (globalThis as any).SALEOR_TOKEN;
-    if (url && token) {
-      saleorClientInstance = new SaleorClient({ apiUrl: url, token });
+    if (url && hasSaleorCredentials()) {
+      saleorClientInstance = createPrimaryClient(url);
       configuredUrl = url;
     }
   }
@@ -258,6 +667,9 @@ export function getConfiguredUrl(): string | null {
 // Configuration:
 // - SALEOR_API_URL: GraphQL endpoint (e.g., https://store.saleor.io/graphql/)
 // - SALEOR_TOKEN: API token for authentication
+// - SALEOR_EMAIL / SALEOR_PASSWORD: staff sign-in used without SALEOR_TOKEN
+// - SALEOR_TIMEOUT_MS: per-call timeout (default 20s)
+// - SALEOR_TRANSPORT: optional binding requests are sent through
 //
 // Error handling:
 // - Network errors are caught and logged
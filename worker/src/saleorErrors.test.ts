@@ -0,0 +1,82 @@
+// Saleor Error Tests
+// Tests for saleorErrors.ts - collecting Saleor errors and mapping their codes
+
+import { describe, it, expect } from "vitest";
+import { ErrorCode } from "./errors";
+import { SaleorError, collectSaleorErrors, mapSaleorError } from "./saleorErrors";
+
+describe("collectSaleorErrors", () => {
+  it("should keep top-level and payload errors with their codes", () => {
+    const errors = collectSaleorErrors(
+      {
+        errors: [
+          {
+            message: "You need one of the following permissions: MANAGE_ORDERS",
+            path: ["orderCreate"],
+            extensions: { exception: { code: "PermissionDenied" } },
+          },
+        ],
+        data: {
+          orderCreate: {
+            errors: [
+              { field: "lines", message: "Insufficient stock", code: "INSUFFICIENT_STOCK" },
+            ],
+          },
+        },
+      },
+      "orderCreate",
+    );
+    expect(errors).toEqual([
+      {
+        message: "You need one of the following permissions: MANAGE_ORDERS",
+        code: "PERMISSION_DENIED",
+        path: ["orderCreate"],
+      },
+      {
+        message: "Insufficient stock",
+        code: "INSUFFICIENT_STOCK",
+        field: "lines",
+        path: ["orderCreate"],
+      },
+    ]);
+  });
+
+  it("should return null from fromResponse when nothing failed", () => {
+    const response = { data: { orderCreate: { errors: [] } } };
+    expect(SaleorError.fromResponse(response, "orderCreate")).toBeNull();
+  });
+});
+
+describe("mapSaleorError", () => {
+  it("should report insufficient stock with the variants", () => {
+    const error = new SaleorError([
+      {
+        message: "Insufficient stock",
+        code: "INSUFFICIENT_STOCK",
+        field: "quantity",
+        variants: ["v1", "v1", "v2"],
+      },
+    ]);
+    const mapped = mapSaleorError(error)!;
+    expect(mapped.code).toBe(ErrorCode.INSUFFICIENT_STOCK);
+    expect(mapped.toGraphQL()).toMatchObject({
+      unavailableItems: [
+        { dishId: "v1", reason: "OUT_OF_STOCK" },
+        { dishId: "v2", reason: "OUT_OF_STOCK" },
+      ],
+      saleorErrors: [{ code: "INSUFFICIENT_STOCK", field: "quantity", path: null }],
+    });
+    // Saleor's wording isn't passed on
+    expect(JSON.stringify(mapped.toGraphQL())).not.toContain("Insufficient stock");
+  });
+
+  it("should map not found and permission errors", () => {
+    expect(mapSaleorError(new SaleorError([{ message: "x", code: "NOT_FOUND" }]))?.code).toBe(
+      ErrorCode.NOT_FOUND,
+    );
+    expect(
+      mapSaleorError(new SaleorError([{ message: "x", code: "PERMISSION_DENIED" }]))?.code,
+    ).toBe(ErrorCode.FORBIDDEN);
+    expect(mapSaleorError(new SaleorError([{ message: "x", code: "INVALID" }]))).toBeNull();
+  });
+});
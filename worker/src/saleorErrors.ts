@@ -0,0 +1,177 @@
+// Saleor Errors
+// Saleor reports failures two ways: top-level GraphQL errors (permission
+// and query errors, with the code in extensions.exception.code) and the
+// errors list of a mutation payload ({field, message, code}). SaleorError
+// keeps all of them with their code, field and path instead of one joined
+// message, and mapSaleorError turns the codes the Mini App can act on into
+// AppErrors; Saleor's messages themselves stay in the logs.
+
+import {
+  AppError,
+  ErrorCode,
+  UpstreamError,
+  forbiddenError,
+  notFoundError,
+} from "./errors";
+import { SaleorResponse } from "./saleorClient";
+
+/**
+ * One error Saleor returned
+ */
+export interface SaleorErrorDetail {
+  message: string;
+  code?: string;
+  field?: string | null;
+  path?: string[];
+  variants?: string[]; // variant IDs, e.g. for INSUFFICIENT_STOCK
+}
+
+/**
+ * Mutation payload error as selected in our mutations
+ */
+interface PayloadError {
+  message?: string | null;
+  code?: string | null;
+  field?: string | null;
+  variants?: string[] | null;
+}
+
+// Top-level exception codes and payload codes meaning the same thing
+const CODE_ALIASES: Record<string, string> = {
+  PermissionDenied: "PERMISSION_DENIED",
+  NotFound: "NOT_FOUND",
+  QueryCostError: "QUERY_COST",
+};
+
+export class SaleorError extends Error {
+  constructor(
+    public readonly errors: SaleorErrorDetail[],
+    public readonly operation?: string,
+  ) {
+    super(errors.map((e) => e.message).join(", ") || `${operation ?? "Saleor"} failed`);
+    this.name = "SaleorError";
+  }
+
+  /** Code of the first error that has one */
+  get code(): string | undefined {
+    return this.errors.find((e) => e.code)?.code;
+  }
+
+  get field(): string | undefined {
+    return this.errors.find((e) => e.field)?.field ?? undefined;
+  }
+
+  get path(): string[] | undefined {
+    return this.errors.find((e) => e.path)?.path;
+  }
+
+  hasCode(code: string): boolean {
+    return this.errors.some((e) => e.code === code);
+  }
+
+  /**
+   * Top-level errors and the errors of the operation's payload, or null
+   * when there are none
+   */
+  static fromResponse(
+    response: SaleorResponse<any>,
+    operation?: string,
+  ): SaleorError | null {
+    const errors = collectSaleorErrors(response, operation);
+    return errors.length > 0 ? new SaleorError(errors, operation) : null;
+  }
+}
+
+function normalizeCode(code: unknown): string | undefined {
+  if (typeof code !== "string" || !code) {
+    return undefined;
+  }
+  return CODE_ALIASES[code] ?? code;
+}
+
+/**
+ * Every error in a response; operation names the mutation whose payload
+ * errors are included
+ */
+export function collectSaleorErrors(
+  response: SaleorResponse<any>,
+  operation?: string,
+): SaleorErrorDetail[] {
+  const errors: SaleorErrorDetail[] = (response.errors || []).map((error) => ({
+    message: error.message,
+    code: normalizeCode(error.extensions?.exception?.code ?? error.extensions?.code),
+    path: error.path,
+  }));
+  if (!operation) {
+    return errors;
+  }
+  const payloadErrors: PayloadError[] = response.data?.[operation]?.errors || [];
+  for (const error of payloadErrors) {
+    errors.push({
+      message: error.message || error.code || "Unknown error",
+      code: normalizeCode(error.code),
+      field: error.field ?? null,
+      path: [operation],
+      ...(error.variants?.length ? { variants: error.variants } : {}),
+    });
+  }
+  return errors;
+}
+
+/**
+ * A copy of an AppError carrying the Saleor errors behind it
+ */
+function withUpstream(base: AppError, upstream: UpstreamError[]): AppError {
+  return new AppError(
+    base.message,
+    base.code,
+    base.statusCode,
+    base.field,
+    base.internalId,
+    base.fieldErrors,
+    base.unavailableItems,
+    base.retryAfterMinutes,
+    upstream,
+  );
+}
+
+function toUpstreamErrors(error: SaleorError): UpstreamError[] {
+  return error.errors.map((e) => ({
+    code: e.code ?? null,
+    field: e.field ?? null,
+    path: e.path ?? null,
+  }));
+}
+
+/**
+ * AppError for the Saleor codes clients can act on: NOT_FOUND,
+ * INSUFFICIENT_STOCK (with the variants as unavailable items) and
+ * PERMISSION_DENIED. Null for anything else, which callers report their
+ * own way.
+ */
+export function mapSaleorError(error: SaleorError): AppError | null {
+  const upstream = toUpstreamErrors(error);
+  if (error.hasCode("INSUFFICIENT_STOCK")) {
+    const variants = error.errors
+      .filter((e) => e.code === "INSUFFICIENT_STOCK")
+      .flatMap((e) => e.variants || []);
+    return new AppError(
+      "Some dishes are out of stock in the requested quantity.",
+      ErrorCode.INSUFFICIENT_STOCK,
+      409,
+      "items",
+      undefined,
+      undefined,
+      Array.from(new Set(variants)).map((dishId) => ({ dishId, reason: "OUT_OF_STOCK" })),
+      undefined,
+      upstream,
+    );
+  }
+  if (error.hasCode("NOT_FOUND")) {
+    return withUpstream(notFoundError(), upstream);
+  }
+  if (error.hasCode("PERMISSION_DENIED")) {
+    return withUpstream(forbiddenError("The store can't do this right now."), upstream);
+  }
+  return null;
+}
@@ -0,0 +1,71 @@
+// Mock Saleor Mode Tests
+// Tests for saleorFixtures.ts - fixture catalog and orders without Saleor
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { getSaleorFixtures, getSaleorMode } from "./saleorFixtures";
+import { initializeSaleorClient, isSaleorConfigured } from "./saleorClient";
+import { fetchChannels, fetchDishes } from "./saleorService";
+import { buildMockOrder } from "./saleorOrder";
+import { buildPlaceOrderInput } from "./testHelpers";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const FIXTURES = {
+  restaurants: [{ id: "r1", name: "Napoli", currencyCode: "EUR" }, { name: "No ID" }],
+  categories: [{ id: "c1", name: "Pizzas" }],
+  dishes: [
+    { id: "d1", name: "Margherita", price: 8.5, categoryId: "c1", restaurantId: "r1" },
+    { id: "d2", name: "Water", price: 2, categoryId: "c2" },
+    { id: "d3", name: "Elsewhere", price: 5, categoryId: "c1", restaurantId: "r2" },
+  ],
+};
+
+afterEach(() => {
+  initializeSaleorClient({});
+  delete (globalThis as any).SALEOR_MODE;
+  delete (globalThis as any).SALEOR_FIXTURES;
+});
+
+describe("mock Saleor mode", () => {
+  it("should switch Saleor off even when it is configured", () => {
+    initializeSaleorClient({ SALEOR_API_URL: "https://saleor.test/graphql/", SALEOR_TOKEN: "t" });
+    expect(isSaleorConfigured()).toBe(true);
+    (globalThis as any).SALEOR_MODE = "mock";
+    expect(isSaleorConfigured()).toBe(false);
+  });
+
+  it("should fold demo mode into the same switched-off Saleor", () => {
+    initializeSaleorClient({ SALEOR_API_URL: "https://saleor.test/graphql/", SALEOR_TOKEN: "t" });
+    (globalThis as any).SALEOR_MODE = "demo";
+    expect(getSaleorMode()).toBe("demo");
+    expect(isSaleorConfigured()).toBe(false);
+    (globalThis as any).SALEOR_MODE = "live";
+    expect(getSaleorMode()).toBe("live");
+  });
+
+  it("should skip malformed fixtures", () => {
+    (globalThis as any).SALEOR_FIXTURES = JSON.stringify(FIXTURES);
+    expect(getSaleorFixtures()?.restaurants).toEqual([FIXTURES.restaurants[0]]);
+  });
+
+  it("should serve the fixture catalog and price orders from it", async () => {
+    (globalThis as any).SALEOR_MODE = "mock";
+    (globalThis as any).SALEOR_FIXTURES = JSON.stringify(FIXTURES);
+
+    expect((await fetchChannels()).map((channel) => channel.name)).toEqual(["Napoli"]);
+    const dishes = await fetchDishes(undefined, "r1");
+    expect(dishes.map((dish) => dish.id)).toEqual(["d1", "d2"]);
+    expect(dishes[0]).toMatchObject({ price: 8.5, currency: "EUR", restaurantId: "r1" });
+
+    const input = buildPlaceOrderInput({
+      restaurantId: "r1",
+      items: [{ dishId: "d1", quantity: 2 }],
+    });
+    const order = buildMockOrder(input, "user-1", "order-1", 1);
+    expect(order.lines[0]).toMatchObject({ productName: "Margherita", quantity: 2 });
+    expect(order.total.gross).toEqual({ amount: 17, currency: "EUR" });
+  });
+});
@@ -0,0 +1,126 @@
+// Mock Saleor Mode
+// SALEOR_MODE=mock runs the backend without a Saleor instance, even when
+// SALEOR_API_URL is set (a shared .dev.vars): the Saleor client is switched
+// off, restaurants, categories and dishes come from fixtures, and orders go
+// through the in-memory order flow (saleorOrder.ts) like any other mock
+// order, so checkout, status changes and order history work end to end.
+// SALEOR_MODE=demo serves the same catalog but only simulates orders
+// (demoMode.ts).
+//
+// Fixtures are JSON in SALEOR_FIXTURES, which scripts/dev.mjs fills from a
+// fixture directory (SALEOR_FIXTURES_DIR, default worker/fixtures):
+// restaurants.json, categories.json and dishes.json, each an array. Without
+// fixtures the built-in test catalog is served. Malformed entries are
+// skipped with a warning.
+
+import { getVar } from "./config";
+import { logger } from "./logger";
+
+export interface RestaurantFixture {
+  id: string;
+  name: string;
+  slug?: string;
+  currencyCode?: string;
+  metadata?: Record<string, string>;
+}
+
+export interface CategoryFixture {
+  id: string;
+  name: string;
+  imageUrl?: string;
+}
+
+export interface DishFixture {
+  id: string;
+  name: string;
+  price: number;
+  categoryId: string;
+  restaurantId?: string; // served by every restaurant when unset
+  description?: string;
+  imageUrl?: string;
+}
+
+export interface SaleorFixtures {
+  restaurants: RestaurantFixture[];
+  categories: CategoryFixture[];
+  dishes: DishFixture[];
+}
+
+let cachedSource: string | undefined;
+let cachedFixtures: SaleorFixtures | null = null;
+
+export type SaleorMode = "live" | "mock" | "demo";
+
+/**
+ * SALEOR_MODE: mock, demo, or live (any other value: the configured Saleor)
+ */
+export function getSaleorMode(): SaleorMode {
+  const mode = getVar("SALEOR_MODE")?.toLowerCase();
+  return mode === "mock" || mode === "demo" ? mode : "live";
+}
+
+/**
+ * Whether Saleor is switched off (SALEOR_MODE=mock or demo)
+ */
+export function isSaleorMockMode(): boolean {
+  return getSaleorMode() !== "live";
+}
+
+function pick<T>(
+  kind: string,
+  entries: unknown,
+  isValid: (entry: any) => boolean,
+): T[] {
+  if (entries === undefined) {
+    return [];
+  }
+  if (!Array.isArray(entries)) {
+    logger.warn("saleor_fixture_invalid", { kind, error: "Not an array" });
+    return [];
+  }
+  return entries.filter((entry) => {
+    const valid = Boolean(entry) && isValid(entry);
+    if (!valid) {
+      logger.warn("saleor_fixture_invalid", { kind, entry });
+    }
+    return valid;
+  });
+}
+
+/**
+ * Fixtures from SALEOR_FIXTURES, or null when none are set
+ */
+export function getSaleorFixtures(): SaleorFixtures | null {
+  const source = getVar("SALEOR_FIXTURES");
+  if (source === cachedSource) {
+    return cachedFixtures;
+  }
+  cachedSource = source;
+  cachedFixtures = null;
+  if (!source) {
+    return null;
+  }
+
+  let parsed: any;
+  try {
+    parsed = JSON.parse(source);
+  } catch (error) {
+    logger.warn("saleor_fixture_invalid", {
+      kind: "all",
+      error: error instanceof Error ? error.message : "Unknown error",
+    });
+    return null;
+  }
+  const isNamed = (entry: any) => typeof entry.id === "string" && typeof entry.name === "string";
+  cachedFixtures = {
+    restaurants: pick<RestaurantFixture>("restaurants", parsed?.restaurants, isNamed),
+    categories: pick<CategoryFixture>("categories", parsed?.categories, isNamed),
+    dishes: pick<DishFixture>(
+      "dishes",
+      parsed?.dishes,
+      (entry) =>
+        isNamed(entry) && typeof entry.price === "number" && typeof entry.categoryId === "string",
+    ),
+  };
+  return cachedFixtures;
+}
@@ -0,0 +1,59 @@
+// Saleor Hook Tests
+// Tests for saleorHooks.ts - observing Saleor calls with secrets redacted
+
+import { describe, it, expect, vi } from "vitest";
+import { SaleorClient, SaleorFetch } from "./saleorClient";
+import { SaleorCallEvent, addSaleorHook, redactVariables } from "./saleorHooks";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const API_URL = "https://saleor.test/graphql/";
+
+describe("redactVariables", () => {
+  it("should hide secrets at any depth", () => {
+    expect(
+      redactVariables({
+        email: "bot@example.com",
+        password: "hunter2",
+        input: { lines: [{ quantity: 1 }], refreshToken: "r", giftCardCode: null },
+      }),
+    ).toEqual({
+      email: "bot@example.com",
+      password: "[redacted]",
+      input: { lines: [{ quantity: 1 }], refreshToken: "[redacted]", giftCardCode: null },
+    });
+  });
+});
+
+describe("Saleor hooks", () => {
+  it("should report every call to global and client hooks", async () => {
+    const events: SaleorCallEvent[] = [];
+    const remove = addSaleorHook((event) => events.push(event));
+    const clientHook = vi.fn(() => {
+      throw new Error("broken hook");
+    });
+    const send = vi.fn<SaleorFetch>(async () =>
+      Response.json({ errors: [{ message: "Unknown field" }] }),
+    );
+    const client = new SaleorClient({ apiUrl: API_URL, fetch: send, hooks: [clientHook] });
+
+    const result = await client.execute("mutation X { x }", { token: "t", id: "1" }, "X");
+    remove();
+    await client.execute("{ shop { name } }");
+
+    expect(result.errors).toHaveLength(1);
+    expect(events).toHaveLength(1);
+    expect(events[0]).toMatchObject({
+      apiUrl: API_URL,
+      operationName: "X",
+      variables: { token: "[redacted]", id: "1" },
+      status: 200,
+      error: "Unknown field",
+    });
+    expect(events[0].durationMs).toBeGreaterThanOrEqual(0);
+    expect(clientHook).toHaveBeenCalledTimes(2);
+  });
+});
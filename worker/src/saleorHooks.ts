@@ -0,0 +1,90 @@
+// Saleor Call Hooks
+// Every SaleorClient.execute call is reported to the registered hooks once
+// it has finished: operation, variables with secrets redacted, duration,
+// HTTP status and the first error. Hooks only observe; one that throws is
+// logged and skipped. Debug logging of Saleor calls is such a hook, so the
+// client and the services calling it don't log requests themselves.
+
+import { isDebugModeEnabled, logger } from "./logger";
+
+/**
+ * A finished Saleor call
+ */
+export interface SaleorCallEvent {
+  apiUrl: string;
+  operationName: string | null;
+  query: string;
+  variables: Record<string, unknown> | null; // secrets redacted
+  durationMs: number;
+  status: number | null; // HTTP status, null when no response arrived
+  error: string | null; // first error Saleor or the network reported
+}
+
+export type SaleorHook = (event: SaleorCallEvent) => void;
+
+// Variable names whose values never leave the client
+const SECRET_KEY_PATTERN = /password|token|secret|authorization|card|cvc|iban/i;
+export const REDACTED = "[redacted]";
+
+/**
+ * Copy of the variables with secret values replaced, at any depth
+ */
+export function redactVariables(value: unknown, key = ""): any {
+  if (key && SECRET_KEY_PATTERN.test(key)) {
+    return value === undefined || value === null ? value : REDACTED;
+  }
+  if (Array.isArray(value)) {
+    return value.map((item) => redactVariables(item));
+  }
+  if (value && typeof value === "object") {
+    return Object.fromEntries(
+      Object.entries(value).map(([name, item]) => [name, redactVariables(item, name)]),
+    );
+  }
+  return value;
+}
+
+/**
+ * Logs Saleor calls while DEBUG is on
+ */
+export function debugLoggingHook(event: SaleorCallEvent): void {
+  if (!isDebugModeEnabled()) {
+    return;
+  }
+  logger.debug("saleor_call", {
+    apiUrl: event.apiUrl,
+    operationName: event.operationName,
+    query: event.query.substring(0, 200),
+    variables: event.variables,
+    durationMs: event.durationMs,
+    status: event.status,
+    error: event.error,
+  });
+}
+
+let hooks: SaleorHook[] = [debugLoggingHook];
+
+/**
+ * Register a hook for every client; returns a function removing it
+ */
+export function addSaleorHook(hook: SaleorHook): () => void {
+  hooks = [...hooks, hook];
+  return () => {
+    hooks = hooks.filter((registered) => registered !== hook);
+  };
+}
+
+/**
+ * Report a call to the registered hooks and the client's own
+ */
+export function notifySaleorHooks(event: SaleorCallEvent, clientHooks: SaleorHook[] = []): void {
+  for (const hook of [...hooks, ...clientHooks]) {
+    try {
+      hook(event);
+    } catch (error) {
+      logger.warn("saleor_hook_failed", {
+        error: error instanceof Error ? error.message : "Unknown error",
+      });
+    }
+  }
+}
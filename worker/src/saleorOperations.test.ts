@@ -0,0 +1,21 @@
+// Saleor Operation Tests
+// Tests for saleorOperations.ts - generated code matches saleor/operations
+
+import { describe, it, expect } from "vitest";
+import { execFileSync } from "node:child_process";
+import { fileURLToPath } from "node:url";
+import { ORDER_CANCEL_MUTATION as clientCancel } from "./saleorClient";
+import { ORDER_CANCEL_MUTATION } from "./saleorOperations";
+
+const CODEGEN = fileURLToPath(new URL("../scripts/saleor-codegen.mjs", import.meta.url));
+
+describe("saleorOperations", () => {
+  it("should be up to date with the .graphql files", () => {
+    expect(() => execFileSync("node", [CODEGEN, "--check"], { stdio: "pipe" })).not.toThrow();
+  });
+
+  it("should be what the client exports", () => {
+    expect(clientCancel).toBe(ORDER_CANCEL_MUTATION);
+    expect(ORDER_CANCEL_MUTATION).toContain("mutation OrderCancel($id: ID!)");
+  });
+});
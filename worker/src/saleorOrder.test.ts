@@ -0,0 +1,114 @@
+// Saleor Order Tests
+// Tests for saleorOrder.ts - creating orders through the draft pipeline
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { PlaceOrderInput } from "./contracts";
+import { SaleorFetch, initializeSaleorClient } from "./saleorClient";
+import {
+  ORDER_METADATA_KEYS,
+  SaleorOrder,
+  createSaleorOrder,
+  getPayableTotal,
+} from "./saleorOrder";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const orderInput: PlaceOrderInput = {
+  restaurantId: "channelA",
+  deliveryLocation: { address: "1 Test Street", city: "Berlin", country: "DE" },
+  items: [{ dishId: "variant-1", quantity: 2 }],
+};
+
+const CREATED_ORDER = {
+  id: "T3JkZXI6MQ==",
+  number: "1",
+  status: "DRAFT",
+  total: { gross: { amount: 20, currency: "EUR" }, tax: { amount: 0 } },
+  undiscountedTotal: { gross: { amount: 20 } },
+  undiscountedShippingPrice: { amount: 0 },
+  shippingAddress: { streetAddress1: "1 Test Street", city: "Berlin", country: { code: "DE" } },
+  lines: [],
+  created: "2026-10-18T10:00:00.000Z",
+};
+
+let metadataFails = false;
+const send = vi.fn<SaleorFetch>(async (_, init) => {
+  const { operationName } = JSON.parse(String(init?.body));
+  if (operationName === "DraftOrderCreate") {
+    return Response.json({ data: { draftOrderCreate: { order: CREATED_ORDER, errors: [] } } });
+  }
+  if (operationName === "DraftOrderComplete") {
+    const order = { ...CREATED_ORDER, status: "UNCONFIRMED" };
+    return Response.json({ data: { draftOrderComplete: { order, errors: [] } } });
+  }
+  if (operationName === "UpdateMetadata") {
+    return Response.json({
+      data: {
+        updateMetadata: {
+          errors: metadataFails ? [{ field: "id", message: "Saleor is down", code: "ERROR" }] : [],
+        },
+      },
+    });
+  }
+  return Response.json({
+    data: { draftOrderDelete: { order: { id: CREATED_ORDER.id }, errors: [] } },
+  });
+});
+
+function operations(): string[] {
+  return send.mock.calls.map(([, init]) => JSON.parse(String(init?.body)).operationName);
+}
+
+describe("createSaleorOrder", () => {
+  beforeEach(() => {
+    metadataFails = false;
+    send.mockClear();
+    (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+    initializeSaleorClient({ SALEOR_API_URL: "https://saleor.test/graphql/", SALEOR_TOKEN: "t" });
+  });
+
+  afterEach(() => {
+    initializeSaleorClient({});
+    delete (globalThis as any).SALEOR_TRANSPORT;
+  });
+
+  it("should record the owner in metadata and complete the draft", async () => {
+    const result = await createSaleorOrder(orderInput, "user-1");
+
+    expect(result.success).toBe(true);
+    expect(result.order).toMatchObject({
+      status: "UNCONFIRMED",
+      metadata: { "tma.telegramUserId": "user-1" },
+    });
+    expect(operations()).toEqual(["DraftOrderCreate", "UpdateMetadata", "DraftOrderComplete"]);
+  });
+
+  it("should delete the draft when its metadata can't be written", async () => {
+    metadataFails = true;
+    const result = await createSaleorOrder(orderInput, "user-1");
+
+    expect(result).toMatchObject({ success: false, errorCode: "ORDER_METADATA_FAILED" });
+    expect(operations()).toContain("DraftOrderDelete");
+    expect(operations()).not.toContain("DraftOrderComplete");
+  });
+});
+
+describe("getPayableTotal", () => {
+  const order = (paymentMethod: string): SaleorOrder => ({
+    id: "o1",
+    status: "UNCONFIRMED",
+    total: { gross: { amount: 12.32, currency: "CHF" } },
+    deliveryAddress: { address: "1 Test Street" },
+    lines: [],
+    metadata: { [ORDER_METADATA_KEYS.paymentMethod]: paymentMethod },
+    createdAt: "2026-10-18T10:00:00.000Z",
+  });
+
+  it("should round cash totals to the currency's cash increment", () => {
+    expect(getPayableTotal(order("CASH"))).toBe(12.3);
+    expect(getPayableTotal(order("ONLINE"))).toBe(12.32);
+  });
+});
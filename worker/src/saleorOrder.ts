@@ -8,15 +8,112 @@ import {
   PlaceOrderPayload,
   DeliveryLocation,
   OrderItemInput,
+  OrderDetails,
+  FulfillmentType,
+  OrderPaymentMethod,
   Channel,
 } from "./contracts";
 import {
   SaleorClient,
-  ORDER_CREATE_MUTATION,
+  SaleorResponse,
+  DRAFT_ORDER_COMPLETE_MUTATION,
+  DRAFT_ORDER_CREATE_MUTATION,
+  DRAFT_ORDER_DELETE_MUTATION,
+  ORDER_CANCEL_MUTATION,
+  ORDER_MARK_AS_PAID_MUTATION,
+  ORDER_ADD_NOTE_MUTATION,
+  ORDER_METADATA_QUERY,
+  UPDATE_METADATA_MUTATION,
   getSaleorClient,
   isSaleorConfigured,
 } from "./saleorClient";
 import { logger } from "./logger";
+import { resolveCustomerEmail } from "./customerEmail";
+import { buildTipLine } from "./tips";
+import { multiplyMoney, roundForCash, subtractMoney, sumMoney } from "./money";
+import { toMoney } from "./currencyFormat";
+import { buildServiceFeeLine, getOrderTotals } from "./orderTotals";
+import { applyVoucherToOrder } from "./promoCodes";
+import {
+  applyLoyaltyDiscount,
+  computeLoyaltyRedemption,
+  getLoyaltyPointsRedeemed,
+} from "./loyalty";
+import { createCheckoutOrder, getOrderPipeline } from "./saleorCheckout";
+import { isShadowModeEnabled, runWithShadow } from "./shadowPipeline";
+import { metadataToRecord, recordToMetadataInput } from "./metadata";
+import { internalError } from "./errors";
+import { SaleorError } from "./saleorErrors";
+import { getSaleorFixtures } from "./saleorFixtures";
+import { getSaleorTarget } from "./saleorTargets";
+import {
+  ORDERS_QUERY,
+  ORDER_QUERY,
+  DraftOrderCompleteMutation,
+  DraftOrderCompleteMutationVariables,
+  DraftOrderCreateMutation,
+  DraftOrderCreateMutationVariables,
+  DraftOrderDeleteMutation,
+  DraftOrderDeleteMutationVariables,
+  OrderAddNoteMutation,
+  OrderAddNoteMutationVariables,
+  OrderCancelMutation,
+  OrderCancelMutationVariables,
+  OrderMarkAsPaidMutation,
+  OrderMarkAsPaidMutationVariables,
+  OrderMetadataQuery,
+  OrderMetadataQueryVariables,
+  OrderQuery,
+  OrderQueryVariables,
+  OrdersQuery,
+  SaleorCountryCode,
+  UpdateMetadataMutation,
+  UpdateMetadataMutationVariables,
+} from "./saleorOperations";
+import { QUERY_NODE_COSTS, createCostBudget, executePageWithinCost } from "./queryCost";
+import { buildHandoffQrPayload, generateHandoffCode } from "./handoffCodes";
+import { getOrderCancellation } from "./cancellationReasons";
+import {
+  normalizeOrderStatus,
+  isTerminalOrderStatus,
+  NormalizedOrderStatus,
+} from "./orderStatus";
+
+/**
+ * Order metadata keys written by the worker
+ */
+export const ORDER_METADATA_KEYS = {
+  telegramUserId: "tma.telegramUserId",
+  scheduledFor: "tma.scheduledFor",
+  state: "tma.state",
+  paymentDeadline: "tma.paymentDeadline",
+  fulfillmentType: "tma.fulfillmentType",
+  pickupAddress: "tma.pickupAddress",
+  language: "tma.language",
+  estimatedDeliveryAt: "tma.estimatedDeliveryAt",
+  paymentReference: "tma.paymentReference",
+  compensationVoucher: "tma.compensationVoucher",
+  paymentMethod: "tma.paymentMethod",
+  tipAmount: "tma.tipAmount",
+  voucherCode: "tma.voucherCode",
+  giftCardLast4: "tma.giftCardLast4",
+  loyaltyRedeemed: "tma.loyaltyRedeemed",
+  loyaltyAwarded: "tma.loyaltyAwarded",
+  loyaltyRestored: "tma.loyaltyRestored",
+  acceptedAt: "tma.acceptedAt",
+  rejectionReason: "tma.rejectionReason",
+  cancellationReason: "tma.cancellationReason",
+  cancellationNote: "tma.cancellationNote",
+  cancelledBy: "tma.cancelledBy",
+  cancelledAt: "tma.cancelledAt",
+  abuseReview: "tma.abuseReview",
+  popularityCounted: "tma.popularityCounted",
+  handoffCode: "tma.handoffCode",
+  handedOffAt: "tma.handedOffAt",
+  deliveredAt: "tma.deliveredAt",
+  fulfillmentId: "tma.fulfillmentId",
+  saleorTarget: "tma.saleorTarget",
+} as const;
 
 /**
  * Order status enum for type safety
@@ -27,14 +124,74 @@ export type OrderStatus =
   | "PROCESSING"
   | "SHIPPED"
   | "DELIVERED"
-  | "CANCELLED";
+  | "CANCELLED"
+  // Saleor OrderStatus values returned by order queries
+  | "DRAFT"
+  | "UNCONFIRMED"
+  | "UNFULFILLED"
+  | "PARTIALLY_FULFILLED"
+  | "FULFILLED"
+  | "CANCELED"
+  // Simulated order in SALEOR_MODE=demo (demoMode.ts), never stored
+  | "DEMO";
+
+/**
+ * Statuses counted as completed for reporting
+ */
+export const COMPLETED_ORDER_STATUSES: OrderStatus[] = ["FULFILLED", "DELIVERED"];
+
+/**
+ * Statuses of orders still in progress (expired orders keep theirs; the
+ * app state marks them)
+ */
+export const ACTIVE_ORDER_STATUSES: OrderStatus[] = [
+  "CREATED",
+  "CONFIRMED",
+  "PROCESSING",
+  "SHIPPED",
+  "UNCONFIRMED",
+  "UNFULFILLED",
+  "PARTIALLY_FULFILLED",
+];
+
+/**
+ * Saleor OrderStatusFilter value of each status. The worker's own statuses
+ * (mock store) have none in Saleor: a delivered order is FULFILLED there,
+ * with tma.deliveredAt set. Drafts and demo orders can't be filtered on.
+ */
+const SALEOR_STATUS_FILTERS: Partial<Record<OrderStatus, string>> = {
+  CREATED: "UNCONFIRMED",
+  UNCONFIRMED: "UNCONFIRMED",
+  CONFIRMED: "UNFULFILLED",
+  PROCESSING: "UNFULFILLED",
+  UNFULFILLED: "UNFULFILLED",
+  SHIPPED: "PARTIALLY_FULFILLED",
+  PARTIALLY_FULFILLED: "PARTIALLY_FULFILLED",
+  DELIVERED: "FULFILLED",
+  FULFILLED: "FULFILLED",
+  CANCELLED: "CANCELED",
+  CANCELED: "CANCELED",
+};
+
+/**
+ * Saleor OrderStatusFilter values matching the given statuses
+ */
+export function toSaleorStatusFilter(statuses: OrderStatus[]): string[] {
+  const values = statuses
+    .map((status) => SALEOR_STATUS_FILTERS[status])
+    .filter((value): value is string => Boolean(value));
+  return Array.from(new Set(values));
+}
 
 /**
  * Internal order representation (matches Saleor order structure)
  */
 export interface SaleorOrder {
   id: string;
+  number?: number;
   status: OrderStatus;
+  channelId?: string;
+  userEmail?: string;
   total: {
     gross: {
       amount: number;
@@ -48,13 +205,62 @@ export interface SaleorOrder {
   };
   lines: Array<{
     variantId: string;
+    productId?: string; // set on orders read back from Saleor
     quantity: number;
     productName: string;
+    unitPrice?: number; // gross, after line discounts
+    undiscountedUnitPrice?: number;
   }>;
+  totalTax?: number; // included in the gross total
+  undiscountedTotal?: number; // gross, before vouchers and promotions
+  undiscountedShippingPrice?: number;
   customerNote?: string;
+  chargeStatus?: string; // Saleor OrderChargeStatusEnum (NONE, PARTIAL, FULL, OVERCHARGED)
+  paymentStatus?: string; // Saleor PaymentChargeStatusEnum (legacy payments)
+  authorizeStatus?: string; // Saleor OrderAuthorizeStatusEnum (NONE, PARTIAL, FULL)
+  totalCharged?: number;
+  metadata?: Record<string, string>;
   createdAt: string;
 }
 
+/**
+ * Filter for listing orders
+ */
+export interface OrderQueryFilter {
+  channelId?: string;
+  createdFrom?: string; // ISO date
+  createdTo?: string; // ISO date
+  statuses?: OrderStatus[];
+  metadata?: Record<string, string>; // exact key/value matches
+}
+
+/**
+ * Saleor order node as returned by ORDER_QUERY and ORDERS_QUERY
+ */
+type SaleorOrderNode = NonNullable<OrderQuery["order"]>;
+
+// Upper bound on pages fetched by fetchOrderList (up to 100 orders per page)
+const MAX_ORDER_PAGES = 20;
+
+/**
+ * One page of orders; cursors[i] resumes after orders[i], endCursor is
+ * null on the last page
+ */
+export interface OrderPage {
+  orders: SaleorOrder[];
+  cursors: string[];
+  endCursor: string | null;
+}
+
+/**
+ * Orders matching a filter; truncated when MAX_ORDER_PAGES ran out before
+ * the last page
+ */
+export interface OrderList {
+  orders: SaleorOrder[];
+  truncated: boolean;
+}
+
 /**
  * Result from Saleor order creation
  */
@@ -63,6 +269,7 @@ export interface CreateOrderResult {
   order?: SaleorOrder;
   error?: string;
   errorCode?: string;
+  saleorError?: SaleorError; // what Saleor reported, when it refused the order
 }
 
 /**
@@ -74,7 +281,7 @@ const mockOrders: Map<string, SaleorOrder> = new Map();
 /**
  * Build order lines from input items for Saleor mutation
  */
-function buildOrderLines(
+export function buildOrderLines(
   items: OrderItemInput[],
 ): Array<{ variantId: string; quantity: number }> {
   return items.map((item) => ({
@@ -84,10 +291,308 @@ function buildOrderLines(
 }
 
 /**
- * Create a Saleor draft order from cart data
+ * Build the tma.* metadata attached to a new order
+ */
+export function buildOrderMetadata(
+  input: PlaceOrderInput,
+  userId: string,
+  userLanguage?: string,
+): Record<string, string> {
+  const fulfillmentType = input.fulfillmentType || "DELIVERY";
+  const metadata: Record<string, string> = {
+    [ORDER_METADATA_KEYS.telegramUserId]: userId,
+    [ORDER_METADATA_KEYS.fulfillmentType]: fulfillmentType,
+    [ORDER_METADATA_KEYS.paymentMethod]: input.paymentMethod || "ONLINE",
+    [ORDER_METADATA_KEYS.handoffCode]: generateHandoffCode(),
+    [ORDER_METADATA_KEYS.saleorTarget]: getSaleorTarget(),
+  };
+  if (userLanguage) {
+    metadata[ORDER_METADATA_KEYS.language] = userLanguage;
+  }
+  if (fulfillmentType === "PICKUP") {
+    metadata[ORDER_METADATA_KEYS.pickupAddress] = input.deliveryLocation.address;
+  }
+  if (input.scheduledFor) {
+    metadata[ORDER_METADATA_KEYS.scheduledFor] = input.scheduledFor;
+  }
+  if (input.estimatedDeliveryAt) {
+    metadata[ORDER_METADATA_KEYS.estimatedDeliveryAt] = input.estimatedDeliveryAt;
+  }
+  if (input.tipAmount) {
+    metadata[ORDER_METADATA_KEYS.tipAmount] = String(input.tipAmount);
+  }
+  if (input.voucherCode) {
+    metadata[ORDER_METADATA_KEYS.voucherCode] = input.voucherCode;
+  }
+  if (input.giftCardCode) {
+    metadata[ORDER_METADATA_KEYS.giftCardLast4] = input.giftCardCode.slice(-4);
+  }
+  return metadata;
+}
+
+/**
+ * Set metadata keys on an order
+ * Mock orders are updated in memory when Saleor is not configured
+ */
+export async function updateOrderMetadata(
+  orderId: string,
+  entries: Record<string, string>,
+): Promise<boolean> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    const order = mockOrders.get(orderId);
+    if (!order) {
+      return false;
+    }
+    order.metadata = { ...(order.metadata || {}), ...entries };
+    return true;
+  }
+
+  try {
+    const variables: UpdateMetadataMutationVariables = {
+      id: orderId,
+      input: recordToMetadataInput(entries),
+    };
+    const response = await client.execute<UpdateMetadataMutation>(
+      UPDATE_METADATA_MUTATION,
+      variables,
+    );
+
+    const errors = [
+      ...(response.errors || []).map((e) => e.message),
+      ...(response.data?.updateMetadata?.errors || []).map((e) => e.message),
+    ];
+    if (errors.length > 0) {
+      logger.error("saleor_order_metadata_error", {
+        error: errors.join(", "),
+        orderId,
+      });
+      return false;
+    }
+    return true;
+  } catch (error) {
+    logger.error("saleor_order_metadata_error", {
+      error: error instanceof Error ? error.message : "Unknown error",
+      orderId,
+    });
+    return false;
+  }
+}
+
+// Attempts before a contended metadata update gives up
+const METADATA_UPDATE_ATTEMPTS = 3;
+
+/**
+ * Current public metadata of an order, null when it can't be read
+ */
+async function readOrderMetadata(
+  client: SaleorClient,
+  orderId: string,
+): Promise<Record<string, string> | null> {
+  const variables: OrderMetadataQueryVariables = { id: orderId };
+  const response = await client.execute<OrderMetadataQuery>(ORDER_METADATA_QUERY, variables);
+  if ((response.errors && response.errors.length > 0) || !response.data?.order) {
+    logger.error("saleor_order_metadata_error", {
+      error: (response.errors || []).map((e) => e.message).join(", ") || "Order not found",
+      orderId,
+    });
+    return null;
+  }
+  return metadataToRecord(response.data.order.metadata);
+}
+
+/**
+ * Change an order's metadata based on its current values
+ * `update` gets the latest metadata and returns the keys to set, or null
+ * when the change no longer applies (another writer got there first).
+ * Saleor metadata has no versions, so concurrency is optimistic: after
+ * writing, the keys are read back and, if a concurrent write replaced any
+ * of them, `update` runs again on the new values.
+ * Returns the keys written, or null when `update` declined or the order
+ * doesn't exist; throws when the keys keep changing underneath
+ */
+export async function updateOrderMetadataWith(
+  orderId: string,
+  update: (metadata: Record<string, string>) => Record<string, string> | null,
+): Promise<Record<string, string> | null> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+
+  for (let attempt = 1; attempt <= METADATA_UPDATE_ATTEMPTS; attempt++) {
+    const mockOrder = client ? undefined : mockOrders.get(orderId);
+    const current = client
+      ? await readOrderMetadata(client, orderId)
+      : mockOrder
+        ? { ...(mockOrder.metadata || {}) }
+        : null;
+    if (!current) {
+      return null;
+    }
+    const entries = update(current);
+    if (!entries || Object.keys(entries).length === 0) {
+      return null;
+    }
+    if (!(await updateOrderMetadata(orderId, entries))) {
+      throw internalError(
+        "order_metadata_update_failed",
+        "Could not update the order, please try again",
+      );
+    }
+    if (!client) {
+      return entries;
+    }
+
+    const written = await readOrderMetadata(client, orderId);
+    if (written && Object.entries(entries).every(([key, value]) => written[key] === value)) {
+      return entries;
+    }
+    logger.warn("order_metadata_conflict", {
+      orderId,
+      keys: Object.keys(entries).join(","),
+      attempt,
+    });
+  }
+  throw internalError(
+    "order_metadata_conflict",
+    "The order changed while it was being updated, please try again",
+  );
+}
+
+/**
+ * Add a note to an order's history in the Saleor dashboard
+ * Notes are best effort: failures are logged and reported as false
+ */
+export async function addOrderNote(orderId: string, message: string): Promise<boolean> {
+  const text = message.trim();
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!text) {
+    return false;
+  }
+  if (!client) {
+    logger.info("mock_order_note_added", { orderId, message: text });
+    return mockOrders.has(orderId);
+  }
+
+  try {
+    const variables: OrderAddNoteMutationVariables = { order: orderId, input: { message: text } };
+    const response = await client.execute<OrderAddNoteMutation>(ORDER_ADD_NOTE_MUTATION, variables);
+    const errors = [
+      ...(response.errors || []).map((e) => e.message),
+      ...(response.data?.orderAddNote?.errors || []).map((e) => e.message),
+    ];
+    if (errors.length > 0) {
+      logger.error("saleor_order_note_error", { error: errors.join(", "), orderId });
+      return false;
+    }
+    return true;
+  } catch (error) {
+    logger.error("saleor_order_note_error", {
+      error: error instanceof Error ? error.message : "Unknown error",
+      orderId,
+    });
+    return false;
+  }
+}
+
+/**
+ * Cancel an order in Saleor (mock orders are marked CANCELLED)
+ */
+export async function cancelSaleorOrder(orderId: string): Promise<boolean> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    const order = mockOrders.get(orderId);
+    if (!order) {
+      return false;
+    }
+    order.status = "CANCELLED";
+    return true;
+  }
+
+  try {
+    const variables: OrderCancelMutationVariables = { id: orderId };
+    const response = await client.execute<OrderCancelMutation>(ORDER_CANCEL_MUTATION, variables);
+
+    const errors = [
+      ...(response.errors || []).map((e) => e.message),
+      ...(response.data?.orderCancel?.errors || []).map((e) => e.message),
+    ];
+    if (errors.length > 0) {
+      logger.error("saleor_order_cancel_error", {
+        error: errors.join(", "),
+        orderId,
+      });
+      return false;
+    }
+    return true;
+  } catch (error) {
+    logger.error("saleor_order_cancel_error", {
+      error: error instanceof Error ? error.message : "Unknown error",
+      orderId,
+    });
+    return false;
+  }
+}
+
+/**
+ * Mark an order as paid in Saleor with an external transaction reference
+ * Mock orders only record the reference
+ */
+export async function markSaleorOrderPaid(
+  orderId: string,
+  transactionReference: string,
+): Promise<boolean> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    const order = mockOrders.get(orderId);
+    if (!order) {
+      return false;
+    }
+    order.metadata = {
+      ...(order.metadata || {}),
+      [ORDER_METADATA_KEYS.paymentReference]: transactionReference,
+    };
+    return true;
+  }
+
+  try {
+    const variables: OrderMarkAsPaidMutationVariables = { id: orderId, transactionReference };
+    const response = await client.execute<OrderMarkAsPaidMutation>(
+      ORDER_MARK_AS_PAID_MUTATION,
+      variables,
+    );
+
+    const errors = [
+      ...(response.errors || []).map((e) => e.message),
+      ...(response.data?.orderMarkAsPaid?.errors || []).map((e) => e.message),
+    ];
+    if (errors.length > 0) {
+      logger.error("saleor_order_mark_paid_error", {
+        error: errors.join(", "),
+        orderId,
+      });
+      return false;
+    }
+    await updateOrderMetadata(orderId, {
+      [ORDER_METADATA_KEYS.paymentReference]: transactionReference,
+    });
+    return true;
+  } catch (error) {
+    logger.error("saleor_order_mark_paid_error", {
+      error: error instanceof Error ? error.message : "Unknown error",
+      orderId,
+    });
+    return false;
+  }
+}
+
+/**
+ * Create a Saleor order from cart data
+ *
+ * This creates a draft order with the Saleor draftOrderCreate mutation and
+ * completes it with draftOrderComplete:
+ * https://docs.saleor.io/docs/3.0/api-reference/mutations/draft-order-create
  *
- * This calls the Saleor orderCreate mutation:
- * https://docs.saleor.io/docs/3.0/api-reference/mutations/orderCreate
+ * With ORDER_PIPELINE=checkout the order goes through the checkout API
+ * instead (see saleorCheckout.ts).
  *
  * When Saleor is not configured, falls back to mock implementation.
  *
@@ -131,88 +636,144 @@ export async function createSaleorOrder(
 
   if (!isSaleorConfigured()) {
     logger.warn("saleor_not_configured", { userId });
-    return createMockOrder(input, userId, channelId);
+    return createMockOrder(input, userId, channelId, userLanguage);
+  }
+
+  // Saleor only redeems gift cards on checkouts and only accepts manual
+  // (loyalty) discounts on draft orders
+  if (
+    (getOrderPipeline() === "checkout" && !input.loyaltyPoints) ||
+    input.giftCardCode
+  ) {
+    const client = getSaleorClient();
+    if (client) {
+      return createCheckoutOrder(
+        client,
+        input,
+        channelId,
+        userId,
+        userName,
+        userLanguage,
+      );
+    }
   }
 
+  // Checkout pipeline canary: replayed against a sandbox channel
+  if (isShadowModeEnabled() && !input.loyaltyPoints) {
+    return runWithShadow(
+      () => createDraftOrder(input, userId, channelId, userLanguage),
+      input,
+      userId,
+      userName,
+      userLanguage,
+    );
+  }
+
+  return createDraftOrder(input, userId, channelId, userLanguage);
+}
+
+/**
+ * Delete a draft order that won't be completed
+ * Drafts can't be cancelled; a failed delete is logged and leaves the
+ * draft in the dashboard, where no one sees it as an order
+ */
+async function deleteDraftOrder(client: SaleorClient, orderId: string): Promise<boolean> {
+  try {
+    const variables: DraftOrderDeleteMutationVariables = { id: orderId };
+    const response = await client.execute<DraftOrderDeleteMutation>(
+      DRAFT_ORDER_DELETE_MUTATION,
+      variables,
+    );
+    const errors = [
+      ...(response.errors || []).map((e) => e.message),
+      ...(response.data?.draftOrderDelete?.errors || []).map((e) => e.message),
+    ];
+    if (errors.length > 0) {
+      logger.error("saleor_draft_order_delete_error", { error: errors.join(", "), orderId });
+      return false;
+    }
+    return true;
+  } catch (error) {
+    logger.error("saleor_draft_order_delete_error", {
+      error: error instanceof Error ? error.message : "Unknown error",
+      orderId,
+    });
+    return false;
+  }
+}
+
+/**
+ * Create the order through draftOrderCreate and draftOrderComplete (the
+ * default "draft" pipeline)
+ * Vouchers, loyalty discounts and metadata are applied to the draft, so an
+ * order that can't get them is deleted before anyone sees it
+ */
+async function createDraftOrder(
+  input: PlaceOrderInput,
+  userId: string,
+  channelId: string,
+  userLanguage?: string,
+): Promise<CreateOrderResult> {
   try {
     const client = getSaleorClient();
     if (!client) {
-      return createMockOrder(input, userId);
+      return createMockOrder(input, userId, channelId, userLanguage);
     }
 
     // Build Saleor mutation variables
     const lines = buildOrderLines(input.items);
+    const tipLine = buildTipLine(input.tipAmount);
+    const serviceFeeLine = buildServiceFeeLine(input.serviceFee);
 
-    // Note: In a real implementation, you'd need to:
-    // 1. Check if a cart/checkout exists in Saleor
-    // 2. Use the appropriate channel/warehouse
-    // 3. Map user data to Saleor customer fields
-
-    const variables = {
+    const variables: DraftOrderCreateMutationVariables = {
       input: {
-        lines: lines.map((line) => ({
-          variantId: line.variantId,
-          quantity: line.quantity,
-        })),
+        channelId,
+        userEmail: await resolveCustomerEmail(userId),
+        lines: [
+          ...lines.map((line) => ({
+            variantId: line.variantId,
+            quantity: line.quantity,
+          })),
+          ...(tipLine ? [tipLine] : []),
+          ...(serviceFeeLine ? [serviceFeeLine] : []),
+        ],
         shippingAddress: {
           streetAddress1: input.deliveryLocation.address,
           city: input.deliveryLocation.city || "",
-          country: input.deliveryLocation.country || "",
+          country: (input.deliveryLocation.country || "") as SaleorCountryCode,
         },
-        note: input.customerNote,
-        // Additional fields that might be needed:
-        // channel: "default-channel",
-        // userId: userId,
+        customerNote: input.customerNote,
       },
     };
 
-    const response = await client.execute<{
-      orderCreate: {
-        order: {
-          id: string;
-          number: number;
-          status: string;
-          total: { gross: { amount: number; currency: string } };
-          shippingAddress: {
-            streetAddress1: string;
-            city: string;
-            country: { code: string };
-          };
-          lines: Array<{ id: string; productName: string; quantity: number }>;
-          createdAt: string;
-        };
-        errors: Array<{ field: string; message: string; code: string }>;
-      };
-    }>(ORDER_CREATE_MUTATION, variables);
-
-    if (response.errors && response.errors.length > 0) {
-      const errorMessage = response.errors.map((e) => e.message).join(", ");
-      logger.error("saleor_order_create_error", {
-        error: errorMessage,
-        userId,
-      });
-      return {
-        success: false,
-        error: errorMessage,
-        errorCode: "ORDER_CREATE_FAILED",
-      };
-    }
+    const response = await client.execute<DraftOrderCreateMutation>(
+      DRAFT_ORDER_CREATE_MUTATION,
+      variables,
+    );
 
-    const orderData = response.data?.orderCreate;
-
-    if (orderData?.errors && orderData.errors.length > 0) {
-      const errorMessage = orderData.errors.map((e) => e.message).join(", ");
-      logger.error("saleor_order_validation_error", {
-        error: errorMessage,
-        userId,
-      });
-      return {
-        success: false,
-        error: errorMessage,
-        errorCode: "ORDER_VALIDATION_FAILED",
-      };
+    const saleorError = SaleorError.fromResponse(response, "draftOrderCreate");
+    if (saleorError) {
+      // Top-level errors mean the request failed, payload errors that
+      // Saleor rejected the order itself
+      const errorCode =
+        response.errors && response.errors.length > 0
+          ? "ORDER_CREATE_FAILED"
+          : "ORDER_VALIDATION_FAILED";
+      logger.error(
+        errorCode === "ORDER_CREATE_FAILED"
+          ? "saleor_order_create_error"
+          : "saleor_order_validation_error",
+        {
+          error: saleorError.message,
+          code: saleorError.code,
+          field: saleorError.field,
+          userId,
+        },
+      );
+      return { success: false, error: saleorError.message, errorCode, saleorError };
     }
 
+    const orderData = response.data?.draftOrderCreate;
     const saleorOrder = orderData?.order;
 
     if (!saleorOrder) {
@@ -227,21 +788,95 @@ export async function createSaleorOrder(
     const order: SaleorOrder = {
       id: saleorOrder.id,
       status: saleorOrder.status as OrderStatus,
-      total: saleorOrder.total,
+      total: { gross: saleorOrder.total.gross },
       deliveryAddress: {
         address: saleorOrder.shippingAddress?.streetAddress1 || "",
         city: saleorOrder.shippingAddress?.city,
         country: saleorOrder.shippingAddress?.country?.code,
       },
       lines: saleorOrder.lines.map((line) => ({
-        variantId: line.id,
+        variantId: line.variant?.id || line.id,
         quantity: line.quantity,
         productName: line.productName,
+        unitPrice: line.unitPrice?.gross?.amount,
+        undiscountedUnitPrice: line.undiscountedUnitPrice?.gross?.amount,
       })),
+      totalTax: saleorOrder.total.tax.amount,
+      undiscountedTotal: saleorOrder.undiscountedTotal.gross.amount,
+      undiscountedShippingPrice: saleorOrder.undiscountedShippingPrice.amount,
       customerNote: input.customerNote,
-      createdAt: saleorOrder.createdAt,
+      createdAt: saleorOrder.created,
     };
 
+    // A promo code that can't be applied must not silently drop the discount
+    if (input.voucherCode && !(await applyVoucherToOrder(order, input.voucherCode))) {
+      await deleteDraftOrder(client, order.id);
+      return {
+        success: false,
+        error: "Promo code could not be applied",
+        errorCode: "VOUCHER_APPLY_FAILED",
+      };
+    }
+
+    // Points are debited by placeOrder once the discounted order exists
+    const loyaltyPointsRedeemed = input.loyaltyPoints
+      ? await applyLoyaltyDiscount(order, input.loyaltyPoints)
+      : 0;
+    if (loyaltyPointsRedeemed === null) {
+      await deleteDraftOrder(client, order.id);
+      return {
+        success: false,
+        error: "Loyalty points could not be applied",
+        errorCode: "LOYALTY_APPLY_FAILED",
+      };
+    }
+
+    // Ownership and scheduling live in order metadata
+    const metadata = buildOrderMetadata(input, userId, userLanguage);
+    if (loyaltyPointsRedeemed > 0) {
+      metadata[ORDER_METADATA_KEYS.loyaltyRedeemed] = String(loyaltyPointsRedeemed);
+    }
+    // Without it the order is invisible to the user and their order limits
+    if (!(await updateOrderMetadata(order.id, metadata))) {
+      await deleteDraftOrder(client, order.id);
+      return {
+        success: false,
+        error: "Order details could not be saved",
+        errorCode: "ORDER_METADATA_FAILED",
+      };
+    }
+    order.metadata = metadata;
+
+    // Completing turns the draft into an order the restaurant sees
+    const complete: DraftOrderCompleteMutationVariables = { id: order.id };
+    const completed = await client.execute<DraftOrderCompleteMutation>(
+      DRAFT_ORDER_COMPLETE_MUTATION,
+      complete,
+    );
+    const completeError = SaleorError.fromResponse(completed, "draftOrderComplete");
+    const completedOrder = completed.data?.draftOrderComplete?.order;
+    if (completeError || !completedOrder) {
+      logger.error("saleor_order_complete_error", {
+        error: completeError?.message || "No order returned",
+        code: completeError?.code,
+        orderId: order.id,
+        userId,
+      });
+      await deleteDraftOrder(client, order.id);
+      // Payload errors (e.g. out of stock) mean Saleor refused the order
+      const payloadRefused =
+        completeError && !(completed.errors && completed.errors.length > 0);
+      return {
+        success: false,
+        error: completeError?.message || "Failed to create order in Saleor",
+        errorCode: payloadRefused ? "ORDER_VALIDATION_FAILED" : "ORDER_CREATE_FAILED",
+        ...(completeError ? { saleorError: completeError } : {}),
+      };
+    }
+    order.status = completedOrder.status as OrderStatus;
+    order.total = { gross: completedOrder.total.gross };
+    order.totalTax = completedOrder.total.tax.amount;
+
     logger.info("order_created", {
       orderId: order.id,
       userId,
@@ -259,45 +894,126 @@ export async function createSaleorOrder(
       userId,
     });
     // Fall back to mock on error
-    return createMockOrder(input, userId);
+    return createMockOrder(input, userId, channelId, userLanguage);
+  }
+}
+
+/**
+ * Name and price of a dish in a mock order: 10 unless a fixture prices it
+ * (SALEOR_MODE=mock or demo)
+ */
+export function getMockDishPricing(dishId: string): { name: string; price: number } {
+  const fixture = (getSaleorFixtures()?.dishes ?? []).find((dish) => dish.id === dishId);
+  return { name: fixture?.name ?? `Dish ${dishId}`, price: fixture?.price ?? 10 };
+}
+
+/**
+ * Currency of a mock order: the fixture restaurant's, USD otherwise
+ */
+function getMockCurrency(channelId: string): string {
+  const restaurant = getSaleorFixtures()?.restaurants.find((r) => r.id === channelId);
+  return restaurant?.currencyCode || "USD";
+}
+
+/**
+ * In-memory order as the mock store keeps it, in the restaurant's currency
+ */
+export function buildMockOrder(
+  input: PlaceOrderInput,
+  userId: string,
+  orderId: string,
+  orderNumber: number,
+  status: OrderStatus = "CREATED",
+  userLanguage?: string,
+): SaleorOrder {
+  // Tip and service fee lines mirror the Saleor order
+  const currency = getMockCurrency(input.channelId || input.restaurantId);
+  const tipLine = buildTipLine(input.tipAmount);
+  const serviceFeeLine = buildServiceFeeLine(input.serviceFee);
+  const lines = [
+    ...buildOrderLines(input.items).map((line) => {
+      const pricing = getMockDishPricing(line.variantId);
+      return { ...line, productName: pricing.name, unitPrice: pricing.price };
+    }),
+    ...(tipLine
+      ? [
+          {
+            variantId: tipLine.variantId,
+            quantity: 1,
+            productName: "Tip",
+            unitPrice: tipLine.price,
+          },
+        ]
+      : []),
+    ...(serviceFeeLine
+      ? [
+          {
+            variantId: serviceFeeLine.variantId,
+            quantity: 1,
+            productName: "Service fee",
+            unitPrice: serviceFeeLine.price,
+          },
+        ]
+      : []),
+  ];
+
+  const subtotalAmount = sumMoney(
+    lines.map((line) => multiplyMoney(line.unitPrice, line.quantity, currency)),
+    currency,
+  );
+  const loyalty = computeLoyaltyRedemption(
+    input.loyaltyPoints || 0,
+    subtotalAmount,
+    currency,
+  );
+  const totalAmount = subtractMoney(subtotalAmount, loyalty.discount, currency);
+  const metadata = buildOrderMetadata(input, userId, userLanguage);
+  if (loyalty.points > 0) {
+    metadata[ORDER_METADATA_KEYS.loyaltyRedeemed] = String(loyalty.points);
   }
+
+  return {
+    id: orderId,
+    number: orderNumber,
+    status,
+    channelId: input.channelId || input.restaurantId,
+    total: {
+      gross: {
+        amount: totalAmount,
+        currency,
+      },
+    },
+    deliveryAddress: {
+      address: input.deliveryLocation.address,
+      city: input.deliveryLocation.city,
+      country: input.deliveryLocation.country,
+    },
+    lines,
+    customerNote: input.customerNote,
+    metadata,
+    createdAt: new Date().toISOString(),
+  };
 }
 
 function createMockOrder(
   input: PlaceOrderInput,
   userId: string,
   channelId?: string,
+  userLanguage?: string,
 ): CreateOrderResult {
   try {
     const orderId = `order:${Date.now()}:${userId}`;
     const orderNumber = mockOrders.size + 1;
-
-    const lines = input.items.map((item: OrderItemInput) => ({
-      variantId: item.dishId,
-      quantity: item.quantity,
-      productName: `Dish ${item.dishId}`,
-    }));
-
-    const totalAmount = lines.reduce((sum, line) => sum + line.quantity * 10, 0);
-
-    const order: SaleorOrder = {
-      id: orderId,
-      status: "CREATED",
-      total: {
-        gross: {
-          amount: totalAmount,
-          currency: "USD",
-        },
-      },
-      deliveryAddress: {
-        address: input.deliveryLocation.address,
-        city: input.deliveryLocation.city,
-        country: input.deliveryLocation.country,
-      },
-      lines,
-      customerNote: input.customerNote,
-      createdAt: new Date().toISOString(),
-    };
+    const order = buildMockOrder(
+      { ...input, channelId: channelId || input.channelId },
+      userId,
+      orderId,
+      orderNumber,
+      "CREATED",
+      userLanguage,
+    );
+    const lines = order.lines;
+    const totalAmount = order.total.gross.amount;
 
     mockOrders.set(orderId, order);
 
@@ -332,17 +1048,391 @@ function createMockOrder(
  * Convert Saleor order to GraphQL payload
  */
 export function toPlaceOrderPayload(order: SaleorOrder): PlaceOrderPayload {
-  // Calculate estimated delivery (mock: 30-45 minutes from now)
+  const scheduledFor = order.metadata?.[ORDER_METADATA_KEYS.scheduledFor];
+  const handoffCode = order.metadata?.[ORDER_METADATA_KEYS.handoffCode];
+  const estimatedDeliveryAt =
+    order.metadata?.[ORDER_METADATA_KEYS.estimatedDeliveryAt];
+
+  // Fallback when no ETA was recorded (mock: 30-45 minutes from now)
   const estimatedMinutes = 30 + Math.floor(Math.random() * 15);
   const estimatedDate = new Date(Date.now() + estimatedMinutes * 60 * 1000);
 
   return {
     orderId: order.id,
     status: order.status,
-    estimatedDelivery: estimatedDate.toISOString(),
+    estimatedDelivery:
+      estimatedDeliveryAt || scheduledFor || estimatedDate.toISOString(),
+    estimatedDeliveryAt,
+    scheduledFor,
+    state: order.metadata?.[ORDER_METADATA_KEYS.state],
+    paymentDeadline: order.metadata?.[ORDER_METADATA_KEYS.paymentDeadline],
+    fulfillmentType: getFulfillmentType(order),
+    pickupAddress: order.metadata?.[ORDER_METADATA_KEYS.pickupAddress],
+    paymentMethod: getPaymentMethod(order),
+    tipAmount: getTipAmount(order),
+    voucherCode: order.metadata?.[ORDER_METADATA_KEYS.voucherCode],
+    giftCardLast4: order.metadata?.[ORDER_METADATA_KEYS.giftCardLast4],
+    loyaltyPointsRedeemed: getLoyaltyPointsRedeemed(order),
+    totals: getOrderTotals(order),
+    // placeOrder answers only the customer, so the handoff code can go here
+    ...(handoffCode
+      ? { handoffCode, handoffQrPayload: buildHandoffQrPayload(order.id, handoffCode) }
+      : {}),
   };
 }
 
+/**
+ * Tip recorded on an order, if any
+ */
+export function getTipAmount(order: SaleorOrder): number | undefined {
+  const tip = Number(order.metadata?.[ORDER_METADATA_KEYS.tipAmount]);
+  return Number.isFinite(tip) && tip > 0 ? tip : undefined;
+}
+
+/**
+ * Payment method recorded on an order (orders predating it paid online)
+ */
+export function getPaymentMethod(order: SaleorOrder): OrderPaymentMethod {
+  const method = order.metadata?.[ORDER_METADATA_KEYS.paymentMethod];
+  return method === "CASH" || method === "CARD_ON_DELIVERY" ? method : "ONLINE";
+}
+
+/**
+ * Fulfillment type recorded on an order (orders predating it are deliveries)
+ */
+export function getFulfillmentType(order: SaleorOrder): FulfillmentType {
+  return order.metadata?.[ORDER_METADATA_KEYS.fulfillmentType] === "PICKUP"
+    ? "PICKUP"
+    : "DELIVERY";
+}
+
+/**
+ * Normalized customer-facing status of an order
+ */
+export function getNormalizedStatus(order: SaleorOrder): NormalizedOrderStatus {
+  return normalizeOrderStatus(
+    order.status,
+    order.metadata?.[ORDER_METADATA_KEYS.state],
+  );
+}
+
+/**
+ * Whether an order is held for an admin's abuse review (abuseDetection.ts
+ * with ABUSE_REQUIRE_APPROVAL); held orders can't be paid, accepted,
+ * delivered or handed over
+ */
+export function isAwaitingAbuseReview(order: SaleorOrder): boolean {
+  return order.metadata?.[ORDER_METADATA_KEYS.abuseReview] === "PENDING";
+}
+
+/**
+ * Total the customer pays; cash is rounded to the currency's cash increment
+ */
+export function getPayableTotal(order: SaleorOrder): number {
+  const { amount, currency } = order.total.gross;
+  return getPaymentMethod(order) === "CASH" ? roundForCash(amount, currency) : amount;
+}
+
+/**
+ * Convert Saleor order to the order detail/history shape
+ * languageCode (Telegram language_code) localizes display amounts
+ */
+export function toOrderDetails(
+  order: SaleorOrder,
+  languageCode?: string,
+): OrderDetails {
+  return {
+    orderId: order.id,
+    number: order.number,
+    status: order.status,
+    normalizedStatus: getNormalizedStatus(order),
+    restaurantId: order.channelId,
+    createdAt: order.createdAt,
+    total: getPayableTotal(order),
+    currency: order.total.gross.currency,
+    totalMoney: toMoney(getPayableTotal(order), order.total.gross.currency, languageCode),
+    lines: order.lines.map((line) => ({
+      dishId: line.variantId,
+      name: line.productName,
+      quantity: line.quantity,
+      unitPrice: line.unitPrice,
+    })),
+    deliveryLocation: { id: order.id, ...order.deliveryAddress },
+    customerNote: order.customerNote,
+    scheduledFor: order.metadata?.[ORDER_METADATA_KEYS.scheduledFor],
+    estimatedDeliveryAt:
+      order.metadata?.[ORDER_METADATA_KEYS.estimatedDeliveryAt],
+    state: order.metadata?.[ORDER_METADATA_KEYS.state],
+    paymentDeadline: order.metadata?.[ORDER_METADATA_KEYS.paymentDeadline],
+    fulfillmentType: getFulfillmentType(order),
+    pickupAddress: order.metadata?.[ORDER_METADATA_KEYS.pickupAddress],
+    paymentMethod: getPaymentMethod(order),
+    tipAmount: getTipAmount(order),
+    voucherCode: order.metadata?.[ORDER_METADATA_KEYS.voucherCode],
+    totals: getOrderTotals(order),
+    handedOffAt: order.metadata?.[ORDER_METADATA_KEYS.handedOffAt],
+    deliveredAt: order.metadata?.[ORDER_METADATA_KEYS.deliveredAt],
+    cancellation: getOrderCancellation(order),
+  };
+}
+
+/**
+ * Map a Saleor order node to our order format
+ */
+function mapSaleorOrderNode(node: SaleorOrderNode): SaleorOrder {
+  return {
+    id: node.id,
+    number: parseInt(node.number, 10) || undefined,
+    status: node.status as OrderStatus,
+    channelId: node.channel?.id,
+    userEmail: node.userEmail || undefined,
+    total: { gross: node.total.gross },
+    deliveryAddress: {
+      address: node.shippingAddress?.streetAddress1 || "",
+      city: node.shippingAddress?.city,
+      country: node.shippingAddress?.country?.code,
+    },
+    lines: (node.lines || []).map((line) => ({
+      variantId: line.variant?.id || line.id,
+      productId: line.variant?.product?.id,
+      quantity: line.quantity,
+      productName: line.productName,
+      unitPrice: line.unitPrice?.gross?.amount,
+      undiscountedUnitPrice: line.undiscountedUnitPrice?.gross?.amount,
+    })),
+    totalTax: node.total.tax?.amount,
+    undiscountedTotal: node.undiscountedTotal?.gross?.amount,
+    undiscountedShippingPrice: node.undiscountedShippingPrice?.amount,
+    customerNote: node.customerNote || undefined,
+    chargeStatus: node.chargeStatus || undefined,
+    paymentStatus: node.paymentStatus || undefined,
+    authorizeStatus: node.authorizeStatus || undefined,
+    totalCharged: node.totalCharged?.amount,
+    metadata: metadataToRecord(node.metadata),
+    createdAt: node.created,
+  };
+}
+
+function matchesFilter(order: SaleorOrder, filter: OrderQueryFilter): boolean {
+  if (filter.channelId && order.channelId !== filter.channelId) {
+    return false;
+  }
+  if (
+    filter.statuses &&
+    !filter.statuses.some(
+      (status) => status === order.status || SALEOR_STATUS_FILTERS[status] === order.status,
+    )
+  ) {
+    return false;
+  }
+  if (filter.createdFrom && order.createdAt < filter.createdFrom) {
+    return false;
+  }
+  if (filter.createdTo && order.createdAt > filter.createdTo) {
+    return false;
+  }
+  for (const [key, value] of Object.entries(filter.metadata || {})) {
+    if (order.metadata?.[key] !== value) {
+      return false;
+    }
+  }
+  return true;
+}
+
+/**
+ * Saleor OrderFilterInput for a filter, or null when no Saleor order can
+ * match it (only statuses Saleor can't filter on)
+ */
+function toSaleorOrderFilter(filter: OrderQueryFilter): Record<string, unknown> | null {
+  const saleorFilter: Record<string, unknown> = {};
+  if (filter.channelId) {
+    saleorFilter.channels = [filter.channelId];
+  }
+  if (filter.statuses) {
+    const statuses = toSaleorStatusFilter(filter.statuses);
+    if (statuses.length === 0) {
+      return null;
+    }
+    saleorFilter.status = statuses;
+  }
+  if (filter.createdFrom || filter.createdTo) {
+    saleorFilter.created = {
+      gte: filter.createdFrom?.substring(0, 10),
+      lte: filter.createdTo?.substring(0, 10),
+    };
+  }
+  if (filter.metadata) {
+    saleorFilter.metadata = recordToMetadataInput(filter.metadata);
+  }
+  return saleorFilter;
+}
+
+/**
+ * Fetch one page of orders matching a filter, starting after a cursor
+ * Uses Saleor orders query when configured, mock store otherwise; throws
+ * when Saleor refuses the query
+ */
+export async function fetchOrdersPage(
+  filter: OrderQueryFilter,
+  after: string | null = null,
+  // Pages shrink to stay within Saleor's query cost limit
+  budget = createCostBudget(QUERY_NODE_COSTS.order),
+): Promise<OrderPage> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    const matching = getAllOrders().filter((order) => matchesFilter(order, filter));
+    const start = after ? Number(after) : 0;
+    const orders = matching.slice(start, start + budget.pageSize);
+    const end = start + orders.length;
+    return {
+      orders,
+      cursors: orders.map((_, i) => String(start + i + 1)),
+      endCursor: end < matching.length ? String(end) : null,
+    };
+  }
+
+  const saleorFilter = toSaleorOrderFilter(filter);
+  if (!saleorFilter) {
+    return { orders: [], cursors: [], endCursor: null };
+  }
+  const response: SaleorResponse<OrdersQuery> = await executePageWithinCost(
+    client,
+    ORDERS_QUERY,
+    { filter: saleorFilter, after },
+    budget,
+  );
+
+  if (response.errors && response.errors.length > 0) {
+    logger.error("saleor_orders_query_error", {
+      error: response.errors.map((e) => e.message).join(", "),
+    });
+    throw internalError("saleor_orders_query_error");
+  }
+
+  const connection = response.data?.orders;
+  if (!connection || !Array.isArray(connection.edges)) {
+    return { orders: [], cursors: [], endCursor: null };
+  }
+  const edges = connection.edges
+    .filter((edge) => edge?.node?.id)
+    .map((edge) => ({ cursor: edge.cursor, order: mapSaleorOrderNode(edge.node) }))
+    // Date filters are day-granular in Saleor; apply exact bounds locally
+    .filter((edge) => matchesFilter(edge.order, filter));
+  const { hasNextPage, endCursor } = connection.pageInfo || {};
+  return {
+    orders: edges.map((edge) => edge.order),
+    cursors: edges.map((edge) => edge.cursor),
+    endCursor: hasNextPage && endCursor ? endCursor : null,
+  };
+}
+
+/**
+ * List orders matching a filter, up to maxPages pages
+ */
+export async function fetchOrderList(
+  filter: OrderQueryFilter,
+  maxPages: number = MAX_ORDER_PAGES,
+): Promise<OrderList> {
+  const orders: SaleorOrder[] = [];
+  const budget = createCostBudget(QUERY_NODE_COSTS.order);
+  let after: string | null = null;
+  for (let page = 0; page < maxPages; page++) {
+    const result: OrderPage = await fetchOrdersPage(filter, after, budget);
+    orders.push(...result.orders);
+    after = result.endCursor;
+    if (!after) {
+      return { orders, truncated: false };
+    }
+  }
+  return { orders, truncated: true };
+}
+
+/**
+ * List orders matching a filter
+ * Stops at MAX_ORDER_PAGES pages (logged); callers that can't work on a
+ * partial list use fetchOrderList
+ */
+export async function fetchOrders(
+  filter: OrderQueryFilter,
+): Promise<SaleorOrder[]> {
+  const { orders, truncated } = await fetchOrderList(filter);
+  if (truncated) {
+    logger.warn("saleor_orders_truncated", {
+      channelId: filter.channelId ?? null,
+      count: orders.length,
+    });
+  }
+  return orders;
+}
+
+/**
+ * Fetch a single order by ID
+ */
+export async function fetchOrderById(
+  orderId: string,
+): Promise<SaleorOrder | null> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    return mockOrders.get(orderId) ?? null;
+  }
+
+  try {
+    const variables: OrderQueryVariables = { id: orderId };
+    const response = await client.execute<OrderQuery>(ORDER_QUERY, variables);
+    if (response.errors && response.errors.length > 0) {
+      logger.error("saleor_order_query_error", {
+        error: response.errors.map((e) => e.message).join(", "),
+        orderId,
+      });
+      return null;
+    }
+    return response.data?.order ? mapSaleorOrderNode(response.data.order) : null;
+  } catch (error) {
+    logger.error("saleor_order_query_error", {
+      error: error instanceof Error ? error.message : "Unknown error",
+      orderId,
+    });
+    return null;
+  }
+}
+
+/**
+ * Fetch an order only if it belongs to the given Telegram user
+ */
+export async function fetchUserOrder(
+  orderId: string,
+  userId: string,
+): Promise<SaleorOrder | null> {
+  const order = await fetchOrderById(orderId);
+  if (!order || order.metadata?.[ORDER_METADATA_KEYS.telegramUserId] !== userId) {
+    return null;
+  }
+  return order;
+}
+
+/**
+ * List a Telegram user's orders, newest first
+ */
+export async function fetchUserOrders(userId: string): Promise<SaleorOrder[]> {
+  const orders = await fetchOrders({
+    metadata: { [ORDER_METADATA_KEYS.telegramUserId]: userId },
+  });
+  return orders.sort((a, b) => b.createdAt.localeCompare(a.createdAt));
+}
+
+/**
+ * Most recent order of a user that hasn't reached a terminal status
+ */
+export async function fetchActiveUserOrder(
+  userId: string,
+): Promise<SaleorOrder | null> {
+  const orders = await fetchUserOrders(userId);
+  return (
+    orders.find((order) => !isTerminalOrderStatus(getNormalizedStatus(order))) ??
+    null
+  );
+}
+
 /**
  * Get order by ID (for debugging/testing)
  */
@@ -378,7 +1468,7 @@ export function clearOrders(): void {
 // Order creation flow:
 // 1. Validate input (restaurantId, deliveryLocation, items)
 // 2. Check if Saleor is configured
-// 3. If configured: call orderCreate mutation
+// 3. If configured: call draftOrderCreate, then draftOrderComplete
 // 4. If not configured: use mock implementation
 // 5. Return order with status and estimated delivery
 //
@@ -0,0 +1,93 @@
+// Saleor Ping Tests
+// Tests for saleorPing.ts - startup and readiness checks of the Saleor API
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { SaleorClient, SaleorFetch, initializeSaleorClient } from "./saleorClient";
+import {
+  getLastSaleorPing,
+  pingSaleor,
+  refreshSaleorPing,
+  resetSaleorPing,
+} from "./saleorPing";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const API_URL = "https://saleor.test/graphql/";
+
+function answering(data: unknown) {
+  return vi.fn<SaleorFetch>(async () => Response.json({ data }));
+}
+
+afterEach(() => {
+  initializeSaleorClient({});
+  resetSaleorPing();
+  delete (globalThis as any).SALEOR_TRANSPORT;
+  delete (globalThis as any).SALEOR_PING_FAIL_FAST;
+  delete (globalThis as any).SHADOW_CHANNEL_ID;
+});
+
+describe("SaleorClient.ping", () => {
+  it("should check the shop and the channel", async () => {
+    const send = answering({ shop: { name: "Eats" }, channel: { id: "ch-1", slug: "pizza" } });
+    const client = new SaleorClient({ apiUrl: API_URL, token: "t", fetch: send });
+
+    expect(await client.ping("ch-1")).toMatchObject({
+      ok: true,
+      shopName: "Eats",
+      channelSlug: "pizza",
+      error: null,
+    });
+    expect(JSON.parse(send.mock.calls[0][1]!.body as string).variables).toEqual({
+      channel: "ch-1",
+    });
+  });
+
+  it("should fail when the channel is missing", async () => {
+    const send = answering({ shop: { name: "Eats" }, channel: null });
+    const client = new SaleorClient({ apiUrl: API_URL, token: "t", fetch: send });
+    expect(await client.ping("ch-9")).toMatchObject({ ok: false, error: "Channel ch-9 not found" });
+  });
+});
+
+describe("pingSaleor", () => {
+  it("should remember a failed ping", async () => {
+    (globalThis as any).SALEOR_TRANSPORT = { fetch: answering({ shop: null }) };
+    initializeSaleorClient({ SALEOR_API_URL: API_URL, SALEOR_TOKEN: "t" });
+
+    await pingSaleor();
+    expect(getLastSaleorPing()).toMatchObject({ ok: false, error: "Saleor sent no shop" });
+  });
+
+  it("should share a ping between concurrent callers", async () => {
+    const send = answering({ shop: { name: "Eats" } });
+    (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+    initializeSaleorClient({ SALEOR_API_URL: API_URL, SALEOR_TOKEN: "t" });
+
+    await Promise.all([pingSaleor(), pingSaleor()]);
+    expect(send).toHaveBeenCalledTimes(1);
+  });
+
+  it("should skip the check without Saleor", async () => {
+    expect(await pingSaleor()).toBeNull();
+    expect(getLastSaleorPing()).toBeNull();
+  });
+});
+
+describe("refreshSaleorPing", () => {
+  it("should ping again right away after a failure", async () => {
+    const send = answering({ shop: null });
+    (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+    initializeSaleorClient({ SALEOR_API_URL: API_URL, SALEOR_TOKEN: "t" });
+    await pingSaleor();
+
+    send.mockImplementation(async () => Response.json({ data: { shop: { name: "Eats" } } }));
+    await refreshSaleorPing();
+    expect(getLastSaleorPing()).toMatchObject({ ok: true });
+
+    await refreshSaleorPing();
+    expect(send).toHaveBeenCalledTimes(2);
+  });
+});
@@ -0,0 +1,74 @@
+// Saleor Startup Check
+// The first event of an isolate pings Saleor (shop { name }, and the
+// SHADOW_CHANNEL_ID channel when one is configured) in the background so a
+// wrong URL, token or channel shows up before the first order without
+// holding that event back. A failed ping is logged and the saleor component
+// reports DEGRADED; with SALEOR_PING_FAIL_FAST it reports DOWN instead and
+// /readyz answers 503. /readyz pings again once the last result is older
+// than the health signal window, or right away after a failed ping.
+
+import { getBooleanVar, getVar } from "./config";
+import { getSignalWindowMs } from "./health";
+import { logger } from "./logger";
+import { SaleorPing, getSaleorClient, isSaleorConfigured } from "./saleorClient";
+
+let lastPing: SaleorPing | null = null;
+let lastPingAt = 0;
+// Concurrent callers share one ping
+let pending: Promise<SaleorPing | null> | null = null;
+
+/**
+ * Whether a failed ping takes the saleor component down
+ * (SALEOR_PING_FAIL_FAST, default false: warn and keep serving)
+ */
+export function isSaleorPingFailFast(): boolean {
+  return getBooleanVar("SALEOR_PING_FAIL_FAST");
+}
+
+/**
+ * Ping Saleor and remember the result; null when Saleor isn't configured
+ */
+export function pingSaleor(now: number = Date.now()): Promise<SaleorPing | null> {
+  if (!pending) {
+    pending = sendPing(now).finally(() => {
+      pending = null;
+    });
+  }
+  return pending;
+}
+
+async function sendPing(now: number): Promise<SaleorPing | null> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    return null;
+  }
+  const ping = await client.ping(getVar("SHADOW_CHANNEL_ID") || undefined);
+  if (!ping.ok) {
+    logger.warn("saleor_ping_failed", { apiUrl: client.apiUrl, error: ping.error });
+  }
+  lastPing = ping;
+  lastPingAt = now;
+  return ping;
+}
+
+/**
+ * Ping again when the last result failed or is older than the health
+ * signal window
+ */
+export async function refreshSaleorPing(now: number = Date.now()): Promise<void> {
+  if ((lastPing && !lastPing.ok) || now - lastPingAt > getSignalWindowMs()) {
+    await pingSaleor(now);
+  }
+}
+
+export function getLastSaleorPing(): SaleorPing | null {
+  return lastPing;
+}
+
+/**
+ * Forget the last ping (tests)
+ */
+export function resetSaleorPing(): void {
+  lastPing = null;
+  lastPingAt = 0;
+}
@@ -0,0 +1,64 @@
+// Saleor Rate Limit Tests
+// Tests for saleorRateLimit.ts - token bucket in front of Saleor requests
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { SaleorClient, SaleorFetch } from "./saleorClient";
+import { TokenBucket, getSaleorRateLimiter, resetSaleorRateLimiters } from "./saleorRateLimit";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const API_URL = "https://saleor.test/graphql/";
+
+afterEach(() => {
+  resetSaleorRateLimiters();
+  delete (globalThis as any).SALEOR_RATE_LIMIT_RPS;
+  delete (globalThis as any).SALEOR_RATE_LIMIT_BURST;
+});
+
+describe("TokenBucket", () => {
+  it("should allow the burst, then queue callers at the rate", () => {
+    const bucket = new TokenBucket(10, 2, 0);
+    expect(bucket.reserve(0, 0)).toBe(0);
+    expect(bucket.reserve(0, 0)).toBe(0);
+    expect(bucket.reserve(0, 0)).toBe(100);
+    expect(bucket.reserve(0, 0)).toBe(200);
+    // Refilled after a quiet second
+    expect(bucket.reserve(0, 1000)).toBe(0);
+  });
+
+  it("should refuse waits longer than allowed without taking a token", () => {
+    const bucket = new TokenBucket(1, 1, 0);
+    bucket.reserve(0, 0);
+    expect(bucket.reserve(500, 0)).toBeNull();
+    expect(bucket.reserve(1000, 0)).toBe(1000);
+  });
+});
+
+describe("Saleor rate limiting", () => {
+  it("should share one bucket per API from SALEOR_RATE_LIMIT_RPS", () => {
+    expect(getSaleorRateLimiter(API_URL)).toBeNull();
+    (globalThis as any).SALEOR_RATE_LIMIT_RPS = "5";
+    const bucket = getSaleorRateLimiter(API_URL);
+    expect(bucket).toMatchObject({ ratePerSecond: 5, burst: 5 });
+    expect(getSaleorRateLimiter(API_URL)).toBe(bucket);
+  });
+
+  it("should fail calls that can't get a slot before their timeout", async () => {
+    const send = vi.fn<SaleorFetch>(async () => Response.json({ data: {} }));
+    const client = new SaleorClient({
+      apiUrl: API_URL,
+      token: "t",
+      fetch: send,
+      timeoutMs: 50,
+      rateLimiter: new TokenBucket(1, 1),
+    });
+
+    expect((await client.execute("{ shop { name } }")).errors).toBeUndefined();
+    const limited = await client.execute("{ shop { name } }");
+    expect(limited.errors?.[0].message).toBe("Saleor rate limit left no slot within 50ms");
+    expect(send).toHaveBeenCalledTimes(1);
+  });
+});
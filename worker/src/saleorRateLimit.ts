@@ -0,0 +1,82 @@
+// Saleor Rate Limiting
+// Saleor Cloud throttles API traffic; a burst from the Mini App (a channel
+// opening its menu at lunch) would otherwise get 429s that fail every call
+// at once. Requests to Saleor take a token from a bucket refilled at
+// SALEOR_RATE_LIMIT_RPS, holding up to SALEOR_RATE_LIMIT_BURST; without a
+// token a request queues until one is due. Waiting counts against the call's
+// timeout, and a call that would wait longer fails right away. Buckets are
+// per isolate and per API URL, so set the rate below Saleor's limit divided
+// by the isolates expected to run at once.
+
+import { getNumberVar } from "./config";
+
+export class TokenBucket {
+  private tokens: number;
+  private updatedAt: number;
+
+  constructor(
+    readonly ratePerSecond: number,
+    readonly burst: number,
+    now: number = Date.now(),
+  ) {
+    this.tokens = burst;
+    this.updatedAt = now;
+  }
+
+  /**
+   * Take a token; returns how long to wait before using it, or null (and
+   * takes nothing) when that would be more than maxWaitMs (0: no limit)
+   */
+  reserve(maxWaitMs: number = 0, now: number = Date.now()): number | null {
+    const elapsed = Math.max(0, now - this.updatedAt) / 1000;
+    this.tokens = Math.min(this.burst, this.tokens + elapsed * this.ratePerSecond);
+    this.updatedAt = now;
+
+    // Queued callers have taken tokens ahead of time, so tokens can go negative
+    const waitMs = this.tokens >= 1 ? 0 : ((1 - this.tokens) / this.ratePerSecond) * 1000;
+    if (maxWaitMs > 0 && waitMs > maxWaitMs) {
+      return null;
+    }
+    this.tokens -= 1;
+    return Math.ceil(waitMs);
+  }
+
+  /**
+   * Wait for a token; returns the time waited, or null when it would take
+   * longer than maxWaitMs
+   */
+  async take(maxWaitMs: number = 0): Promise<number | null> {
+    const waitMs = this.reserve(maxWaitMs);
+    if (waitMs) {
+      await new Promise((resolve) => setTimeout(resolve, waitMs));
+    }
+    return waitMs;
+  }
+}
+
+const buckets = new Map<string, TokenBucket>();
+
+/**
+ * The isolate's bucket for a Saleor API, or null while SALEOR_RATE_LIMIT_RPS
+ * is unset or 0
+ */
+export function getSaleorRateLimiter(apiUrl: string): TokenBucket | null {
+  const rate = getNumberVar("SALEOR_RATE_LIMIT_RPS", 0);
+  if (rate <= 0) {
+    return null;
+  }
+  const burst = Math.max(1, getNumberVar("SALEOR_RATE_LIMIT_BURST", Math.ceil(rate)));
+  let bucket = buckets.get(apiUrl);
+  if (!bucket || bucket.ratePerSecond !== rate || bucket.burst !== burst) {
+    bucket = new TokenBucket(rate, burst);
+    buckets.set(apiUrl, bucket);
+  }
+  return bucket;
+}
+
+/**
+ * Forget the buckets (tests)
+ */
+export function resetSaleorRateLimiters(): void {
+  buckets.clear();
+}
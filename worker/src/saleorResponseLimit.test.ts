@@ -0,0 +1,57 @@
+// Saleor Response Limit Tests
+// Tests for saleorClient.ts - capped response bodies
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { RESPONSE_TOO_LARGE, SaleorClient, SaleorFetch, readJsonWithin } from "./saleorClient";
+import { SaleorError } from "./saleorErrors";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const API_URL = "https://saleor.test/graphql/";
+
+function streamed(chunks: string[]): Response {
+  const encoder = new TextEncoder();
+  const body = new ReadableStream<Uint8Array>({
+    start(controller) {
+      chunks.forEach((chunk) => controller.enqueue(encoder.encode(chunk)));
+      controller.close();
+    },
+  });
+  return new Response(body);
+}
+
+afterEach(() => {
+  delete (globalThis as any).SALEOR_MAX_RESPONSE_BYTES;
+});
+
+describe("readJsonWithin", () => {
+  it("should parse bodies within the limit", async () => {
+    expect(await readJsonWithin(streamed(['{"data":', '{"ok":true}}']), 64)).toEqual({
+      data: { ok: true },
+    });
+  });
+
+  it("should stop reading once the limit is passed", async () => {
+    await expect(readJsonWithin(streamed(['{"data":', '"' + "x".repeat(100) + '"}']), 64))
+      .rejects.toThrow("Saleor response exceeded 64 bytes");
+  });
+
+  it("should refuse bodies whose Content-Length is over the limit", async () => {
+    const response = new Response("{}", { headers: { "Content-Length": "1000" } });
+    await expect(readJsonWithin(response, 64)).rejects.toThrow("exceeded 64 bytes");
+  });
+});
+
+describe("Saleor response limit", () => {
+  it("should fail oversized answers with a typed error", async () => {
+    (globalThis as any).SALEOR_MAX_RESPONSE_BYTES = "32";
+    const send = vi.fn<SaleorFetch>(async () => Response.json({ data: { name: "x".repeat(64) } }));
+    const client = new SaleorClient({ apiUrl: API_URL, token: "t", fetch: send });
+
+    const result = await client.execute("{ shop { name } }");
+    expect(SaleorError.fromResponse(result)?.code).toBe(RESPONSE_TOO_LARGE);
+  });
+});
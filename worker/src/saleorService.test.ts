@@ -421,21 +421,37 @@ describe("fetchDishes", () => {
       id: "saleor_dish_1",
       name: "Saleor Dish 1",
       description: "Description 1",
+      descriptionHtml: "Description 1",
       price: 12.99,
       currency: "USD",
       categoryId: "saleor_cat_1",
       imageUrl: "https://example.com/dish1.jpg",
       restaurantId: "restA",
+      available: true,
+      soldOut: false,
+      dietaryTags: [],
+      allergens: [],
+      images: [],
+      calories: null,
+      variantIds: ["var_1"],
     });
     expect(result[1]).toEqual({
       id: "saleor_dish_2",
       name: "Saleor Dish 2",
       description: "",
+      descriptionHtml: "",
       price: 8.5,
       currency: "EUR",
       categoryId: "saleor_cat_1",
       imageUrl: "",
       restaurantId: "restA",
+      available: true,
+      soldOut: false,
+      dietaryTags: [],
+      allergens: [],
+      images: [],
+      calories: null,
+      variantIds: ["var_2"],
     });
   });
 
@@ -499,11 +515,17 @@ describe("fetchDishes", () => {
       id: TEST_DISHES.DISH_A1.id,
       name: TEST_DISHES.DISH_A1.name,
       description: "Test description",
+      descriptionHtml: "Test description",
       price: TEST_DISHES.DISH_A1.price,
       currency: "USD",
       categoryId: TEST_DISHES.DISH_A1.categoryId,
       imageUrl: "https://example.com/image.jpg",
       restaurantId: "restA",
+      available: true,
+      soldOut: false,
+      dietaryTags: [],
+      allergens: [],
+      images: [],
     });
   });
 
@@ -8,177 +8,102 @@ import {
   getSaleorClient,
   isSaleorConfigured,
   SaleorResponse,
+  UPDATE_METADATA_MUTATION,
 } from "./saleorClient";
-import { Channel, Restaurant, Category, Dish } from "./contracts";
+import {
+  CHANNELS_QUERY,
+  FEATURED_PRODUCTS_QUERY,
+  PRODUCTS_QUERY,
+  PRODUCT_TYPES_QUERY,
+  ChannelsQuery,
+  FeaturedProductsQuery,
+  FeaturedProductsQueryVariables,
+  ProductTypesQuery,
+  ProductTypesQueryVariables,
+  ProductsQuery,
+  ProductsQueryVariables,
+  UpdateMetadataMutation,
+  UpdateMetadataMutationVariables,
+} from "./saleorOperations";
+import {
+  Channel,
+  Restaurant,
+  Category,
+  Dish,
+  PriceDisplay,
+  ImageFormat,
+  Connection,
+  DishSortBy,
+} from "./contracts";
 import { TEST_CHANNELS, TEST_DISHES, TEST_CATEGORIES } from "./testHelpers";
+import { getSaleorFixtures } from "./saleorFixtures";
+import {
+  MetadataItem,
+  metadataToRecord,
+  recordToMetadataInput,
+} from "./metadata";
+import { recordFallbackServed } from "./health";
+import { getThumbnailSize } from "./imageFormat";
+import { getActiveAnnouncement } from "./announcements";
+import { isRestaurantPaused } from "./restaurantPause";
+import { isPromotedNow, placePromotedFirst } from "./promotedRestaurants";
+import { QUERY_NODE_COSTS, createCostBudget, executePageWithinCost } from "./queryCost";
+import { getDishPrepMinutes, getPrepMinutes } from "./eta";
+import {
+  getAllergens,
+  getDietaryTags,
+  getDishCalories,
+  matchesDietaryFilter,
+} from "./dietaryTags";
+import { getRestaurantTags } from "./tagLabels";
+import { getCategoryScope, getParentCategoryId } from "./categoryTree";
+import { DESCRIPTION_METADATA_KEY, renderDescription } from "./richText";
+import { getGallerySize, toImages } from "./productMedia";
+import { MAX_FEATURED_DISHES, getFeaturedCollectionSlug } from "./featuredDishes";
+import { checkChannelListing, isSoldOut } from "./availability";
+import { getOpeningTransitions, isOpenAt, parseOpeningHours } from "./openingHours";
+import {
+  MAX_CATALOG_PAGES,
+  MAX_PAGE_SIZE,
+  getPageSize,
+  paginateList,
+} from "./pagination";
+import { internalError } from "./errors";
+import { invalidateSaleorCache } from "./saleorCache";
+import { getNumberVar } from "./config";
+import {
+  SaleorProductOrder,
+  getDishPopularity,
+  sortDishes,
+  toProductOrder,
+} from "./dishPopularity";
 
 /**
- * Saleor Product Type (maps to our Category)
+ * Saleor reads a fan-out over restaurants runs at once
+ * (SALEOR_FANOUT_CONCURRENCY, default 4)
  */
-export interface SaleorProductType {
-  id: string;
-  name: string;
-  backgroundImage?: {
-    url: string;
-  } | null;
+export function getSaleorFanoutLimit(): number {
+  return Math.max(1, getNumberVar("SALEOR_FANOUT_CONCURRENCY", 4));
 }
 
 /**
- * Saleor Product Variant (contains pricing info)
+ * Saleor Product Type (maps to our Category)
  */
-export interface SaleorProductVariant {
+export interface SaleorProductType {
   id: string;
   name: string;
-  pricing: {
-    price: {
-      amount: string;
-      currency: string;
-    };
-  };
+  metadata?: MetadataItem[];
 }
 
 /**
  * Saleor Product (maps to our Dish)
  */
-export interface SaleorProduct {
-  id: string;
-  name: string;
-  description: string | null;
-  thumbnail: {
-    url: string;
-  } | null;
-  productType: SaleorProductType;
-  variants: SaleorProductVariant[];
-}
-
-/**
- * Saleor Collection (legacy - maps to Restaurant)
- */
-export interface SaleorCollection {
-  id: string;
-  name: string;
-  backgroundImage: {
-    url: string;
-  } | null;
-}
-
-/**
- * Saleor Channel (maps to our Channel entity)
- */
-export interface SaleorChannel {
-  id: string;
-  slug: string;
-  name: string;
-  isActive: boolean;
-  currencyCode: string;
-  defaultCountry: {
-    code: string;
-    country: string;
-  } | null;
-  warehouses: Array<{
-    id: string;
-    slug: string;
-    name: string;
-  }>;
-}
-
-/**
- * GraphQL query for fetching products (dishes) with variants and pricing
- */
-export const PRODUCTS_QUERY = `
-  query Products {
-    products(first: 100) {
-      edges {
-        node {
-          id
-          name
-          description
-          thumbnail {
-            url
-          }
-          productType {
-            id
-            name
-          }
-          variants {
-            id
-            name
-            pricing {
-              price {
-                gross {
-                  amount
-                  currency
-                }
-              }
-            }
-          }
-        }
-      }
-    }
-  }
-`;
-
-/**
- * GraphQL query for fetching product types (categories)
- */
-export const PRODUCT_TYPES_QUERY = `
-  query ProductTypes {
-    productTypes(first: 100) {
-      edges {
-        node {
-          id
-          name
-          backgroundImage {
-            url
-          }
-        }
-      }
-    }
-  }
-`;
+export type SaleorProduct = NonNullable<ProductsQuery["products"]>["edges"][number]["node"];
 
 /**
- * GraphQL query for fetching collections (restaurants - legacy)
- */
-export const COLLECTIONS_QUERY = `
-  query Collections {
-    collections(first: 100) {
-      edges {
-        node {
-          id
-          name
-          backgroundImage {
-            url
-          }
-        }
-      }
-    }
-  }
-`;
-
-/**
- * GraphQL query for fetching channels (Saleor multichannel)
+ * Saleor Product Variant (contains pricing info)
  */
-export const CHANNELS_QUERY = `
-  query Channels {
-    channels {
-      id
-      slug
-      name
-      isActive
-      currencyCode
-      defaultCountry {
-        code
-        country
-      }
-      warehouses {
-        id
-        slug
-        name
-      }
-    }
-  }
-`;
+export type SaleorProductVariant = NonNullable<SaleorProduct["variants"]>[number];
 
 /**
  * Fetch channels from Saleor and map to Restaurant for GraphQL backward compatibility
@@ -206,9 +131,9 @@ export async function fetchChannels(): Promise<Channel[]> {
       return getMockChannels();
     }
 
-    const response = await client.execute<{
-      channels: SaleorChannel[];
-    }>(CHANNELS_QUERY);
+    const response = await client.execute<ChannelsQuery>(CHANNELS_QUERY, undefined, undefined, {
+      cache: "channels",
+    });
 
     if (response.errors && response.errors.length > 0) {
       logger.error("saleor_service_error", {
@@ -250,6 +175,7 @@ export async function fetchChannels(): Promise<Channel[]> {
           ? { code: ch.defaultCountry.code, country: ch.defaultCountry.country }
           : undefined,
         warehouses: ch.warehouses,
+        metadata: metadataToRecord(ch.metadata),
         categories: [],
         deliveryLocations: [],
       });
@@ -269,16 +195,113 @@ export async function fetchChannels(): Promise<Channel[]> {
   }
 }
 
+/**
+ * Set tma_* metadata keys on a channel
+ * Mock channels keep the keys in memory when Saleor is not configured
+ */
+export async function updateChannelMetadata(
+  channelId: string,
+  entries: Record<string, string>,
+): Promise<boolean> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    mockChannelMetadata.set(channelId, {
+      ...(mockChannelMetadata.get(channelId) || {}),
+      ...entries,
+    });
+    return true;
+  }
+
+  try {
+    const variables: UpdateMetadataMutationVariables = {
+      id: channelId,
+      input: recordToMetadataInput(entries),
+    };
+    const response = await client.execute<UpdateMetadataMutation>(
+      UPDATE_METADATA_MUTATION,
+      variables,
+    );
+    const errors = [
+      ...(response.errors || []).map((e) => e.message),
+      ...(response.data?.updateMetadata?.errors || []).map((e) => e.message),
+    ];
+    if (errors.length > 0) {
+      logger.error("saleor_channel_metadata_error", {
+        error: errors.join(", "),
+        channelId,
+      });
+      return false;
+    }
+    await invalidateSaleorCache("channels");
+    return true;
+  } catch (error) {
+    logger.error("saleor_channel_metadata_error", {
+      error: error instanceof Error ? error.message : "Unknown error",
+      channelId,
+    });
+    return false;
+  }
+}
+
+/**
+ * Fetch a single channel (restaurant) by ID
+ */
+export async function fetchChannelById(
+  channelId: string,
+): Promise<Channel | null> {
+  const channels = await fetchChannels();
+  return channels.find((ch) => ch.id === channelId) ?? null;
+}
+
 function mapChannelsToRestaurants(channels: Channel[]): Restaurant[] {
-  return channels.map((ch) => ({
+  const now = new Date();
+  return channels.map((ch) => toRestaurant(ch, now));
+}
+
+/**
+ * Restaurant listing fields for a channel
+ */
+export function toRestaurant(ch: Channel, now: Date = new Date()): Restaurant {
+  const announcement = getActiveAnnouncement(ch);
+  const hours = parseOpeningHours(ch.metadata);
+  return {
     id: ch.id,
     name: ch.name,
     description: ch.description,
     imageUrl: ch.imageUrl,
-    tags: ch.tags,
+    tags: ch.tags ?? getRestaurantTags(ch.metadata),
     categories: ch.categories,
     deliveryLocations: ch.deliveryLocations,
-  }));
+    ...(announcement ? { announcement } : {}),
+    ...(hours ? { isOpenNow: isOpenAt(hours, now), ...getOpeningTransitions(hours, now) } : {}),
+    acceptingOrders: !isRestaurantPaused(ch.metadata, now),
+    prepMinutes: getPrepMinutes(ch.metadata),
+    isPromoted: isPromotedNow(ch.metadata, now),
+  };
+}
+
+/**
+ * Map a Saleor product type to a Category, or null when malformed
+ */
+function toCategory(pt: SaleorProductType | null | undefined): Category | null {
+  if (!pt || typeof pt.id !== "string" || typeof pt.name !== "string") {
+    logger.warn("saleor_service_skipping_malformed_product_type", {
+      productType: pt,
+      dataType: "categories",
+    });
+    return null;
+  }
+  // Product types have no description or parent, so categories use metadata
+  const metadata = metadataToRecord(pt.metadata);
+  const description = metadata[DESCRIPTION_METADATA_KEY];
+  const parentId = getParentCategoryId(metadata);
+  return {
+    id: pt.id,
+    name: pt.name,
+    imageUrl: "",
+    ...(description ? renderDescription(description) : {}),
+    ...(parentId ? { parentId } : {}),
+  };
 }
 
 export async function fetchCategories(
@@ -306,55 +329,51 @@ export async function fetchCategories(
 
     // Saleor does not provide a direct way to filter product types by restaurant (collection).
     // We fetch all product types and return them regardless of the restaurantId parameter.
-    const response = await client.execute<{
-      productTypes: { edges: { node: SaleorProductType }[] };
-    }>(PRODUCT_TYPES_QUERY);
-
-    if (response.errors && response.errors.length > 0) {
-      logger.error("saleor_service_error", {
-        error: response.errors.map((e) => e.message).join(", "),
-        dataType: "categories",
-      });
-      return getMockCategories();
-    }
-
-    const productTypesResponse = response.data?.productTypes;
+    const categories: Category[] = [];
+    let after: string | null = null;
 
-    // Handle malformed response - if productTypes or edges is not as expected
-    if (!productTypesResponse || !Array.isArray(productTypesResponse.edges)) {
-      logger.error("saleor_service_malformed_response", {
-        error: "Invalid productTypes response structure",
-        dataType: "categories",
-        received: productTypesResponse,
-      });
-      return getMockCategories();
-    }
+    for (let page = 0; page < MAX_CATALOG_PAGES; page++) {
+      const variables: ProductTypesQueryVariables = { first: MAX_PAGE_SIZE, after };
+      const response: SaleorResponse<ProductTypesQuery> = await client.execute(
+        PRODUCT_TYPES_QUERY,
+        variables,
+        undefined,
+        { cache: "categories" },
+      );
 
-    const productTypes =
-      productTypesResponse.edges
-        .filter(
-          (edge): edge is { node: SaleorProductType } => !!edge && !!edge.node,
-        )
-        .map((edge) => edge.node) || [];
+      if (response.errors && response.errors.length > 0) {
+        logger.error("saleor_service_error", {
+          error: response.errors.map((e) => e.message).join(", "),
+          dataType: "categories",
+        });
+        return getMockCategories();
+      }
 
-    // Map Saleor product types to our Category format
-    const categories: Category[] = [];
+      const productTypesResponse = response.data?.productTypes;
 
-    for (const pt of productTypes) {
-      // Skip malformed product type data
-      if (!pt || typeof pt.id !== "string" || typeof pt.name !== "string") {
-        logger.warn("saleor_service_skipping_malformed_product_type", {
-          productType: pt,
+      // Handle malformed response - if productTypes or edges is not as expected
+      if (!productTypesResponse || !Array.isArray(productTypesResponse.edges)) {
+        logger.error("saleor_service_malformed_response", {
+          error: "Invalid productTypes response structure",
           dataType: "categories",
+          received: productTypesResponse,
         });
-        continue;
+        return getMockCategories();
       }
 
-      categories.push({
-        id: pt.id,
-        name: pt.name,
-        imageUrl: pt.backgroundImage?.url || "",
-      });
+      // Map Saleor product types to our Category format
+      for (const edge of productTypesResponse.edges) {
+        const category = edge ? toCategory(edge.node) : null;
+        if (category) {
+          categories.push(category);
+        }
+      }
+
+      const pageInfo = productTypesResponse.pageInfo;
+      if (!pageInfo?.hasNextPage || !pageInfo.endCursor) {
+        break;
+      }
+      after = pageInfo.endCursor;
     }
 
     logger.info("saleor_service_success", {
@@ -374,10 +393,157 @@ export async function fetchCategories(
   }
 }
 
+/**
+ * A category's ID and those of the categories nested under it
+ * Falls back to the category alone when product types can't be read
+ */
+async function fetchCategoryScope(client: SaleorClient, categoryId: string): Promise<string[]> {
+  const categories: Category[] = [];
+  let after: string | null = null;
+  for (let page = 0; page < MAX_CATALOG_PAGES; page++) {
+    const variables: ProductTypesQueryVariables = { first: MAX_PAGE_SIZE, after };
+    const response: SaleorResponse<ProductTypesQuery> = await client.execute(
+      PRODUCT_TYPES_QUERY,
+      variables,
+      undefined,
+      { cache: "categories" },
+    );
+    const productTypes = response.data?.productTypes;
+    if ((response.errors && response.errors.length > 0) || !Array.isArray(productTypes?.edges)) {
+      return [categoryId];
+    }
+    for (const edge of productTypes!.edges) {
+      const category = edge ? toCategory(edge.node) : null;
+      if (category) {
+        categories.push(category);
+      }
+    }
+    if (!productTypes!.pageInfo?.hasNextPage || !productTypes!.pageInfo.endCursor) {
+      break;
+    }
+    after = productTypes!.pageInfo.endCursor;
+  }
+  return getCategoryScope(categories, categoryId);
+}
+
+/**
+ * Map a Saleor product to a Dish for a restaurant's menu
+ * Returns null for malformed products, products outside the categories
+ * (categoryIds, when given) and products not listed in the restaurant's
+ * channel
+ */
+function toDish(
+  product: SaleorProduct | null | undefined,
+  categoryIds: string[] | undefined,
+  restaurantId: string | undefined,
+  channelId: string | undefined,
+  priceDisplay: PriceDisplay,
+): Dish | null {
+  // Skip malformed product data
+  if (
+    !product ||
+    typeof product.id !== "string" ||
+    typeof product.name !== "string" ||
+    !product.productType ||
+    typeof product.productType.id !== "string"
+  ) {
+    logger.warn("saleor_service_skipping_malformed_product", {
+      product: product,
+      dataType: "dishes",
+    });
+    return null;
+  }
+
+  // Filter by category (and its subcategories) if provided
+  if (categoryIds && !categoryIds.includes(product.productType.id)) {
+    return null;
+  }
+
+  // Only dishes published and visible in the restaurant's channel
+  const listingChannelId = channelId || restaurantId;
+  if (
+    listingChannelId &&
+    Array.isArray(product.channelListings) &&
+    checkChannelListing(product.channelListings, listingChannelId, "LISTING")
+  ) {
+    return null;
+  }
+
+  const firstVariant =
+    Array.isArray(product.variants) && product.variants.length > 0
+      ? product.variants[0]
+      : null;
+
+  let price = 0;
+  let currency = "USD";
+  // Menus show gross or net prices depending on tax jurisdiction
+  const variantPrice =
+    priceDisplay === "NET" && firstVariant?.pricing?.price?.net
+      ? firstVariant.pricing.price.net
+      : firstVariant?.pricing?.price?.gross;
+  if (variantPrice) {
+    price = Number(variantPrice.amount) || 0;
+    currency = variantPrice.currency || "USD";
+  }
+
+  // Sold out dishes stay on the menu, greyed out, rather than vanishing
+  const soldOut = isSoldOut(firstVariant?.quantityAvailable);
+  const metadata = metadataToRecord(product.metadata);
+  const variantListed =
+    !listingChannelId ||
+    !Array.isArray(firstVariant?.channelListings) ||
+    firstVariant!.channelListings.some((l) => l.channel?.id === listingChannelId);
+  const purchasable =
+    !listingChannelId ||
+    !Array.isArray(product.channelListings) ||
+    checkChannelListing(product.channelListings, listingChannelId, "PURCHASE") === null;
+
+  return {
+    id: product.id,
+    name: product.name,
+    ...renderDescription(product.description),
+    price: price,
+    currency: currency,
+    categoryId: product.productType.id,
+    imageUrl: product.thumbnail?.url || "",
+    restaurantId: restaurantId || "", // Use provided restaurantId or empty string
+    taxIncluded: priceDisplay === "GROSS",
+    prepMinutes: getDishPrepMinutes(metadata),
+    calories: getDishCalories(metadata),
+    available: !!firstVariant && variantListed && purchasable && !soldOut,
+    soldOut,
+    dietaryTags: getDietaryTags(metadata),
+    allergens: getAllergens(metadata),
+    images: toImages(product.media),
+    variantIds: Array.isArray(product.variants) ? product.variants.map((v) => v.id) : [],
+  };
+}
+
+function getProductsVariables(
+  categoryIds: string[] | undefined,
+  imageFormat: ImageFormat,
+  first: number,
+  after: string | null,
+  sortBy: SaleorProductOrder | null = null,
+): ProductsQueryVariables {
+  return {
+    first,
+    after,
+    filter: categoryIds ? { productTypes: categoryIds } : null,
+    sortBy,
+    thumbnailSize: getThumbnailSize(),
+    mediaSize: getGallerySize(),
+    thumbnailFormat: imageFormat,
+  };
+}
+
 export async function fetchDishes(
   categoryId?: string,
   restaurantId?: string,
   channelId?: string,
+  priceDisplay: PriceDisplay = "GROSS",
+  imageFormat: ImageFormat = "ORIGINAL",
+  sortBy?: DishSortBy,
 ): Promise<Dish[]> {
   // Check if Saleor is configured
   if (!isSaleorConfigured()) {
@@ -385,7 +551,7 @@ export async function fetchDishes(
       reason: "Saleor not configured",
       dataType: "dishes",
     });
-    return getMockDishes(categoryId, restaurantId);
+    return sortDishes(getMockDishes(categoryId, restaurantId), sortBy);
   }
 
   try {
@@ -395,86 +561,66 @@ export async function fetchDishes(
         reason: "Saleor client not available",
         dataType: "dishes",
       });
-      return getMockDishes(categoryId, restaurantId);
+      return sortDishes(getMockDishes(categoryId, restaurantId), sortBy);
     }
 
-    const response = await client.execute<{
-      products: { edges: { node: SaleorProduct }[] };
-    }>(PRODUCTS_QUERY);
+    // Price sorting is per channel, so Saleor needs the restaurant's slug;
+    // both lookups go out together (one request with SALEOR_BATCHING)
+    const [channelSlug, categoryIds] = await Promise.all([
+      sortBy?.startsWith("PRICE") && restaurantId
+        ? fetchChannelById(restaurantId).then((channel) => channel?.slug)
+        : undefined,
+      categoryId ? fetchCategoryScope(client, categoryId) : undefined,
+    ]);
+    const productOrder = toProductOrder(sortBy, channelSlug);
 
-    if (response.errors && response.errors.length > 0) {
-      logger.error("saleor_service_error", {
-        error: response.errors.map((e) => e.message).join(", "),
-        dataType: "dishes",
-      });
-      return getMockDishes(categoryId, restaurantId);
-    }
+    // Map Saleor products to our Dish format, page by page so menus over
+    // 100 products aren't truncated
+    const dishes: Dish[] = [];
+    const popularity = new Map<string, number>();
+    let after: string | null = null;
 
-    const productsResponse = response.data?.products;
+    for (let page = 0; page < MAX_CATALOG_PAGES; page++) {
+      const response: SaleorResponse<ProductsQuery> = await client.execute(
+        PRODUCTS_QUERY,
+        getProductsVariables(categoryIds, imageFormat, MAX_PAGE_SIZE, after, productOrder),
+        undefined,
+        { cache: "products" },
+      );
 
-    // Handle malformed response - if products or edges is not as expected
-    if (!productsResponse || !Array.isArray(productsResponse.edges)) {
-      logger.error("saleor_service_malformed_response", {
-        error: "Invalid products response structure",
-        dataType: "dishes",
-        received: productsResponse,
-      });
-      return getMockDishes(categoryId, restaurantId);
-    }
-
-    const products =
-      productsResponse.edges
-        .filter(
-          (edge): edge is { node: SaleorProduct } => !!edge && !!edge.node,
-        )
-        .map((edge) => edge.node) || [];
+      if (response.errors && response.errors.length > 0) {
+        logger.error("saleor_service_error", {
+          error: response.errors.map((e) => e.message).join(", "),
+          dataType: "dishes",
+        });
+        return sortDishes(getMockDishes(categoryId, restaurantId), sortBy);
+      }
 
-    // Map Saleor products to our Dish format
-    const dishes: Dish[] = [];
+      const productsResponse = response.data?.products;
 
-    for (const product of products) {
-      // Skip malformed product data
-      if (
-        !product ||
-        typeof product.id !== "string" ||
-        typeof product.name !== "string" ||
-        !product.productType ||
-        typeof product.productType.id !== "string"
-      ) {
-        logger.warn("saleor_service_skipping_malformed_product", {
-          product: product,
+      // Handle malformed response - if products or edges is not as expected
+      if (!productsResponse || !Array.isArray(productsResponse.edges)) {
+        logger.error("saleor_service_malformed_response", {
+          error: "Invalid products response structure",
           dataType: "dishes",
+          received: productsResponse,
         });
-        continue;
+        return sortDishes(getMockDishes(categoryId, restaurantId), sortBy);
       }
 
-      // Filter by categoryId if provided
-      if (categoryId && product.productType.id !== categoryId) {
-        continue;
+      for (const edge of productsResponse.edges) {
+        const dish = toDish(edge?.node, categoryIds, restaurantId, channelId, priceDisplay);
+        if (dish) {
+          dishes.push(dish);
+          popularity.set(dish.id, getDishPopularity(metadataToRecord(edge.node.metadata)));
+        }
       }
 
-      const firstVariant =
-        Array.isArray(product.variants) && product.variants.length > 0
-          ? product.variants[0]
-          : null;
-
-      let price = 0;
-      let currency = "USD";
-      if (firstVariant?.pricing?.price?.gross) {
-        price = parseFloat(firstVariant.pricing.price.gross.amount) || 0;
-        currency = firstVariant.pricing.price.gross.currency || "USD";
+      const pageInfo = productsResponse.pageInfo;
+      if (!pageInfo?.hasNextPage || !pageInfo.endCursor) {
+        break;
       }
-
-      dishes.push({
-        id: product.id,
-        name: product.name,
-        description: product.description || "",
-        price: price,
-        currency: currency,
-        categoryId: product.productType.id,
-        imageUrl: product.thumbnail?.url || "",
-        restaurantId: restaurantId || "", // Use provided restaurantId or empty string
-      });
+      after = pageInfo.endCursor;
     }
 
     logger.info("saleor_service_success", {
@@ -485,17 +631,347 @@ export async function fetchDishes(
         ? `restaurantId=${restaurantId} (set on dish objects, not filtered)`
         : "none",
     });
-    return dishes;
+    return productOrder ? dishes : sortDishes(dishes, sortBy, popularity);
   } catch (error) {
     logger.error("saleor_service_error", {
       error: error instanceof Error ? error.message : "Unknown error",
       dataType: "dishes",
     });
-    return getMockDishes(categoryId, restaurantId);
+    return sortDishes(getMockDishes(categoryId, restaurantId), sortBy);
+  }
+}
+
+/**
+ * Dishes a restaurant pins to the top of its menu: the products of the
+ * Saleor collection named in its tma_featured_collection metadata
+ * Featured dishes are optional, so a missing collection or a Saleor error
+ * yields an empty list rather than failing the menu
+ */
+export async function fetchFeaturedDishes(
+  restaurantId: string,
+  priceDisplay: PriceDisplay = "GROSS",
+  imageFormat: ImageFormat = "ORIGINAL",
+): Promise<Dish[]> {
+  // Mock menus have no collections
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    return [];
+  }
+
+  const channel = await fetchChannelById(restaurantId);
+  const slug = getFeaturedCollectionSlug(channel?.metadata);
+  if (!channel || !slug) {
+    return [];
+  }
+
+  try {
+    const variables: FeaturedProductsQueryVariables = {
+      slug,
+      channel: channel.slug,
+      first: MAX_FEATURED_DISHES,
+      thumbnailSize: getThumbnailSize(),
+      mediaSize: getGallerySize(),
+      thumbnailFormat: imageFormat,
+    };
+    const response = await client.execute<FeaturedProductsQuery>(
+      FEATURED_PRODUCTS_QUERY,
+      variables,
+    );
+    if (response.errors && response.errors.length > 0) {
+      throw new Error(response.errors.map((e) => e.message).join(", "));
+    }
+
+    const edges = response.data?.collection?.products?.edges;
+    if (!Array.isArray(edges)) {
+      logger.warn("featured_collection_not_found", { restaurantId, slug });
+      return [];
+    }
+    return edges
+      .map((edge) => toDish(edge?.node, undefined, restaurantId, undefined, priceDisplay))
+      .filter((dish): dish is Dish => dish !== null);
+  } catch (error) {
+    logger.error("saleor_service_error", {
+      error: error instanceof Error ? error.message : "Unknown error",
+      dataType: "featuredDishes",
+    });
+    return [];
   }
 }
 
+/**
+ * Every dish listed in a restaurant's channel, for background jobs
+ * Unlike fetchDishes there is no mock fallback: failures throw, and
+ * without Saleor the menu is empty
+ */
+export async function fetchListedDishes(
+  restaurantId: string,
+  priceDisplay: PriceDisplay = "GROSS",
+): Promise<Dish[]> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    return [];
+  }
+
+  const dishes: Dish[] = [];
+  let after: string | null = null;
+  // Pages shrink to stay within Saleor's query cost limit
+  const budget = createCostBudget(QUERY_NODE_COSTS.product);
+  for (let page = 0; page < MAX_CATALOG_PAGES; page++) {
+    const response: SaleorResponse<ProductsQuery> = await executePageWithinCost(
+      client,
+      PRODUCTS_QUERY,
+      getProductsVariables(undefined, "ORIGINAL", budget.pageSize, after),
+      budget,
+    );
+    const products = response.data?.products;
+    if ((response.errors && response.errors.length > 0) || !Array.isArray(products?.edges)) {
+      throw new Error(
+        (response.errors || []).map((e) => e.message).join(", ") || "Invalid products response",
+      );
+    }
+    for (const edge of products!.edges) {
+      const dish = toDish(edge?.node, undefined, restaurantId, undefined, priceDisplay);
+      if (dish) {
+        dishes.push(dish);
+      }
+    }
+    if (!products!.pageInfo?.hasNextPage || !products!.pageInfo.endCursor) {
+      break;
+    }
+    after = products!.pageInfo.endCursor;
+  }
+  return dishes;
+}
+
+// products aliases per dish count query; larger menus take several queries
+const MAX_COUNTS_PER_QUERY = 50;
+
+/**
+ * Dish count query with one products alias per category (c0, c1, ...),
+ * each filtered to the product types in $types0, $types1, ...
+ * Built per batch, so unlike the documents in saleor/operations it isn't
+ * generated or checked against the schema
+ */
+export function buildCategoryDishCountsQuery(count: number): string {
+  const indexes = Array.from({ length: count }, (_, i) => i);
+  const variables = indexes.map((i) => `$types${i}: [ID!]`).join(", ");
+  const fields = indexes
+    .map(
+      (i) => `
+    c${i}: products(
+      first: 1
+      channel: $channel
+      filter: { productTypes: $types${i}, isPublished: true, isVisibleInListing: true }
+    ) {
+      totalCount
+    }`,
+    )
+    .join("");
+  return `
+  query CategoryDishCounts($channel: String!, ${variables}) {${fields}
+  }
+`;
+}
+
+/**
+ * Dishes listed in a restaurant's channel per category, counting those of
+ * nested subcategories too
+ * Counts only help hide empty sections, so on failure they are left out
+ */
+export async function fetchCategoryDishCounts(
+  categories: Category[],
+  restaurantId: string,
+): Promise<Map<string, number>> {
+  const counts = new Map<string, number>();
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    const dishes = getMockDishes(undefined, restaurantId);
+    for (const category of categories) {
+      const scope = getCategoryScope(categories, category.id);
+      counts.set(category.id, dishes.filter((dish) => scope.includes(dish.categoryId)).length);
+    }
+    return counts;
+  }
+
+  const channel = await fetchChannelById(restaurantId);
+  if (!channel) {
+    return counts;
+  }
+  try {
+    for (let start = 0; start < categories.length; start += MAX_COUNTS_PER_QUERY) {
+      const batch = categories.slice(start, start + MAX_COUNTS_PER_QUERY);
+      const variables: Record<string, unknown> = { channel: channel.slug };
+      batch.forEach((category, i) => {
+        variables[`types${i}`] = getCategoryScope(categories, category.id);
+      });
+      const response = await client.execute<
+        Record<string, { totalCount: number | null } | null>
+      >(buildCategoryDishCountsQuery(batch.length), variables);
+      if (response.errors && response.errors.length > 0) {
+        throw new Error(response.errors.map((e) => e.message).join(", "));
+      }
+      batch.forEach((category, i) => {
+        const total = response.data?.[`c${i}`]?.totalCount;
+        if (typeof total === "number") {
+          counts.set(category.id, total);
+        }
+      });
+    }
+  } catch (error) {
+    logger.error("saleor_service_error", {
+      error: error instanceof Error ? error.message : "Unknown error",
+      dataType: "categoryDishCounts",
+    });
+  }
+  return counts;
+}
+
+/**
+ * One page of restaurants, promoted first (offset cursors; Saleor doesn't
+ * paginate channels)
+ */
+export async function fetchRestaurantsPage(
+  first?: number | null,
+  after?: string | null,
+): Promise<Connection<Restaurant>> {
+  return paginateList(placePromotedFirst(await fetchRestaurants()), first, after);
+}
+
+/**
+ * One page of categories, backed by Saleor productTypes cursors
+ */
+export async function fetchCategoriesPage(
+  first?: number | null,
+  after?: string | null,
+): Promise<Connection<Category>> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    return paginateList(getMockCategories(), first, after);
+  }
+
+  const variables: ProductTypesQueryVariables = { first: getPageSize(first), after: after || null };
+  const response = await client.execute<ProductTypesQuery>(
+    PRODUCT_TYPES_QUERY,
+    variables,
+    undefined,
+    { cache: "categories" },
+  );
+  const productTypes = response.data?.productTypes;
+  if ((response.errors && response.errors.length > 0) || !Array.isArray(productTypes?.edges)) {
+    logger.error("saleor_service_error", {
+      error: (response.errors || []).map((e) => e.message).join(", ") || "Invalid response",
+      dataType: "categories",
+    });
+    throw internalError("category_page_failed", "Could not load the menu, please try again");
+  }
+
+  const edges: Connection<Category>["edges"] = [];
+  for (const edge of productTypes!.edges) {
+    const category = edge ? toCategory(edge.node) : null;
+    if (edge?.cursor && category) {
+      edges.push({ cursor: edge.cursor, node: category });
+    }
+  }
+  return {
+    edges,
+    pageInfo: {
+      hasNextPage: !!productTypes!.pageInfo?.hasNextPage,
+      endCursor: productTypes!.pageInfo?.endCursor ?? null,
+    },
+  };
+}
+
+/**
+ * One page of a category's dishes, backed by Saleor product cursors
+ * Products hidden in the restaurant's channel or not matching the dietary
+ * filter are skipped, and further Saleor pages are read until the page is full
+ */
+export async function fetchDishesPage(
+  categoryId: string,
+  restaurantId: string,
+  first?: number | null,
+  after?: string | null,
+  priceDisplay: PriceDisplay = "GROSS",
+  imageFormat: ImageFormat = "ORIGINAL",
+  dietaryFilter?: string[] | null,
+): Promise<Connection<Dish>> {
+  const size = getPageSize(first);
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    return paginateList(
+      getMockDishes(categoryId, restaurantId).filter((dish) =>
+        matchesDietaryFilter(dish, dietaryFilter),
+      ),
+      size,
+      after,
+    );
+  }
+
+  const categoryIds = await fetchCategoryScope(client, categoryId);
+  const edges: Connection<Dish>["edges"] = [];
+  let cursor: string | null = after || null;
+  let hasNextPage = true;
+
+  for (let page = 0; page < MAX_CATALOG_PAGES && hasNextPage && edges.length < size; page++) {
+    const response: SaleorResponse<ProductsQuery> = await client.execute(
+      PRODUCTS_QUERY,
+      getProductsVariables(categoryIds, imageFormat, size - edges.length, cursor),
+      undefined,
+      { cache: "products" },
+    );
+    const products = response.data?.products;
+    if ((response.errors && response.errors.length > 0) || !Array.isArray(products?.edges)) {
+      logger.error("saleor_service_error", {
+        error: (response.errors || []).map((e) => e.message).join(", ") || "Invalid response",
+        dataType: "dishes",
+      });
+      throw internalError("dish_page_failed", "Could not load the menu, please try again");
+    }
+
+    for (const edge of products!.edges) {
+      if (!edge?.cursor) {
+        continue;
+      }
+      cursor = edge.cursor;
+      const dish = toDish(edge.node, categoryIds, restaurantId, undefined, priceDisplay);
+      if (dish && matchesDietaryFilter(dish, dietaryFilter)) {
+        edges.push({ cursor: edge.cursor, node: dish });
+      }
+    }
+    hasNextPage = !!products!.pageInfo?.hasNextPage;
+    cursor = products!.pageInfo?.endCursor ?? cursor;
+  }
+
+  return {
+    edges,
+    // endCursor also skips hidden products read after the last dish
+    pageInfo: { hasNextPage, endCursor: hasNextPage || edges.length > 0 ? cursor : null },
+  };
+}
+
+// Metadata written to mock channels (local development)
+const mockChannelMetadata = new Map<string, Record<string, string>>();
+
 function getMockChannels(): Channel[] {
+  // Mock data only stands in for live data when Saleor is configured
+  if (isSaleorConfigured()) {
+    recordFallbackServed("channels");
+  }
+  const fixtures = getSaleorFixtures()?.restaurants;
+  if (fixtures?.length) {
+    return fixtures.map((restaurant) => ({
+      id: restaurant.id,
+      slug: restaurant.slug || restaurant.id,
+      name: restaurant.name,
+      isActive: true,
+      currencyCode: restaurant.currencyCode || "USD",
+      defaultCountry: undefined,
+      warehouses: [],
+      metadata: { ...restaurant.metadata, ...mockChannelMetadata.get(restaurant.id) },
+      categories: [],
+      deliveryLocations: [],
+    }));
+  }
   return Object.keys(TEST_CHANNELS).map((key) => {
     const ch = TEST_CHANNELS[key as keyof typeof TEST_CHANNELS];
     return {
@@ -506,6 +982,7 @@ function getMockChannels(): Channel[] {
       currencyCode: "USD",
       defaultCountry: undefined,
       warehouses: [],
+      metadata: mockChannelMetadata.get(ch.id),
       categories: [],
       deliveryLocations: [],
     };
@@ -513,6 +990,17 @@ function getMockChannels(): Channel[] {
 }
 
 function getMockCategories(_restaurantId?: string): Category[] {
+  if (isSaleorConfigured()) {
+    recordFallbackServed("categories");
+  }
+  const fixtures = getSaleorFixtures()?.categories;
+  if (fixtures?.length) {
+    return fixtures.map((category) => ({
+      id: category.id,
+      name: category.name,
+      imageUrl: category.imageUrl || "",
+    }));
+  }
   return Object.keys(TEST_CATEGORIES).map((key) => {
     const cat = TEST_CATEGORIES[key as keyof typeof TEST_CATEGORIES];
     return {
@@ -525,18 +1013,50 @@ function getMockCategories(_restaurantId?: string): Category[] {
   return categories;
 }
 
-function getMockDishes(categoryId?: string, _restaurantId?: string): Dish[] {
+function getMockDishes(categoryId?: string, restaurantId?: string): Dish[] {
+  if (isSaleorConfigured()) {
+    recordFallbackServed("dishes");
+  }
+  const fixtures = getSaleorFixtures();
+  if (fixtures?.dishes.length) {
+    const restaurant = fixtures.restaurants.find((r) => r.id === restaurantId);
+    return fixtures.dishes
+      .filter((dish) => !categoryId || dish.categoryId === categoryId)
+      .filter((dish) => !restaurantId || !dish.restaurantId || dish.restaurantId === restaurantId)
+      .map((dish) => ({
+        id: dish.id,
+        name: dish.name,
+        description: dish.description || "",
+        descriptionHtml: dish.description || "",
+        price: dish.price,
+        currency: restaurant?.currencyCode || "USD",
+        categoryId: dish.categoryId,
+        imageUrl: dish.imageUrl || "",
+        restaurantId: restaurantId ?? dish.restaurantId,
+        available: true,
+        soldOut: false,
+        dietaryTags: [],
+        allergens: [],
+        images: [],
+      }));
+  }
   let dishes = Object.keys(TEST_DISHES).map((key) => {
     const dish = TEST_DISHES[key as keyof typeof TEST_DISHES];
     return {
       id: dish.id,
       name: dish.name,
       description: "Test description",
+      descriptionHtml: "Test description",
       price: dish.price,
       currency: "USD",
       categoryId: dish.categoryId,
       imageUrl: "https://example.com/image.jpg",
       restaurantId: restaurantId,
+      available: true,
+      soldOut: false,
+      dietaryTags: [],
+      allergens: [],
+      images: [],
     };
   });
 
@@ -0,0 +1,76 @@
+// Saleor Target Tests
+// Tests for saleorTargets.ts - allowlist, percentage buckets and scoping
+
+import { describe, it, expect, afterEach } from "vitest";
+import {
+  getSaleorTarget,
+  getSaleorTargetForApiUrl,
+  getTargetBucket,
+  runWithSaleorTarget,
+  selectSaleorTarget,
+} from "./saleorTargets";
+import { getSaleorClient } from "./saleorClient";
+
+afterEach(() => {
+  delete (globalThis as any).SALEOR_SECONDARY_API_URL;
+  delete (globalThis as any).SALEOR_SECONDARY_TOKEN;
+  delete (globalThis as any).SALEOR_SECONDARY_USERS;
+  delete (globalThis as any).SALEOR_SECONDARY_PERCENT;
+});
+
+function configureSecondary(): void {
+  (globalThis as any).SALEOR_SECONDARY_API_URL = "https://sandbox.example.com/graphql/";
+  (globalThis as any).SALEOR_SECONDARY_TOKEN = "sandbox-token";
+}
+
+describe("selectSaleorTarget", () => {
+  it("should use the primary without a secondary endpoint", () => {
+    (globalThis as any).SALEOR_SECONDARY_USERS = "42";
+    expect(selectSaleorTarget("42")).toBe("primary");
+  });
+
+  it("should route allowlisted users to the secondary", () => {
+    configureSecondary();
+    (globalThis as any).SALEOR_SECONDARY_USERS = "7, 42";
+    expect(selectSaleorTarget("42")).toBe("secondary");
+    expect(selectSaleorTarget("43")).toBe("primary");
+    expect(selectSaleorTarget(undefined)).toBe("primary");
+  });
+
+  it("should route a stable share of users by percentage", () => {
+    configureSecondary();
+    (globalThis as any).SALEOR_SECONDARY_PERCENT = "30";
+    const users = Array.from({ length: 1000 }, (_, i) => String(100000 + i));
+    const routed = users.filter((id) => selectSaleorTarget(id) === "secondary");
+    expect(routed.length).toBeGreaterThan(200);
+    expect(routed.length).toBeLessThan(400);
+    expect(routed.every((id) => getTargetBucket(id) < 30)).toBe(true);
+
+    (globalThis as any).SALEOR_SECONDARY_PERCENT = "100";
+    expect(selectSaleorTarget("123")).toBe("secondary");
+  });
+});
+
+describe("runWithSaleorTarget", () => {
+  it("should scope the target and client to the request", async () => {
+    configureSecondary();
+    expect(getSaleorTarget()).toBe("primary");
+    const apiUrl = await runWithSaleorTarget("secondary", async () => {
+      await Promise.resolve();
+      return getSaleorClient()?.apiUrl;
+    });
+    expect(apiUrl).toBe("https://sandbox.example.com/graphql/");
+    expect(getSaleorTarget()).toBe("primary");
+  });
+});
+
+describe("getSaleorTargetForApiUrl", () => {
+  it("should route webhooks from the secondary's API URL to it", () => {
+    expect(getSaleorTargetForApiUrl("https://sandbox.example.com/graphql/")).toBe("primary");
+
+    configureSecondary();
+    expect(getSaleorTargetForApiUrl("https://Sandbox.example.com/graphql")).toBe("secondary");
+    expect(getSaleorTargetForApiUrl("https://shop.example.com/graphql/")).toBe("primary");
+    expect(getSaleorTargetForApiUrl(null)).toBe("primary");
+  });
+});
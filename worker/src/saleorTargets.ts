@@ -0,0 +1,87 @@
+// Saleor Sandbox/Staging Target
+// Routes some users to a secondary Saleor (SALEOR_SECONDARY_API_URL /
+// SALEOR_SECONDARY_TOKEN): Telegram users in SALEOR_SECONDARY_USERS, plus
+// SALEOR_SECONDARY_PERCENT of everyone else. Assignment hashes the user ID,
+// so a user stays on one target and their carts and orders don't mix IDs
+// from two catalogs. The target is request-scoped (AsyncLocalStorage, the
+// nodejs_als compatibility flag) and picked up by getSaleorClient().
+// Webhooks and cron jobs have no user: orders record their target (order
+// metadata, payment deadline records, Telegram invoice payloads), and
+// Saleor webhooks are routed by their Saleor-Api-Url header. Catalog-wide
+// cron jobs run against the primary.
+
+import { AsyncLocalStorage } from "node:async_hooks";
+import { getListVar, getNumberVar, getVar } from "./config";
+
+export type SaleorTarget = "primary" | "secondary";
+
+const targetStorage = new AsyncLocalStorage<SaleorTarget>();
+
+/**
+ * Whether a secondary Saleor endpoint is configured
+ */
+export function isSecondaryTargetConfigured(): boolean {
+  return !!getVar("SALEOR_SECONDARY_API_URL") && !!getVar("SALEOR_SECONDARY_TOKEN");
+}
+
+/**
+ * 32-bit FNV-1a hash of a string
+ */
+export function fnv1a(value: string): number {
+  let hash = 0x811c9dc5;
+  for (let i = 0; i < value.length; i++) {
+    hash ^= value.charCodeAt(i);
+    hash = Math.imul(hash, 0x01000193) >>> 0;
+  }
+  return hash;
+}
+
+/**
+ * Stable 0-99 bucket for a user (FNV-1a)
+ */
+export function getTargetBucket(userId: string): number {
+  return fnv1a(userId) % 100;
+}
+
+/**
+ * Saleor target for a Telegram user
+ */
+export function selectSaleorTarget(userId: string | undefined): SaleorTarget {
+  if (!userId || !isSecondaryTargetConfigured()) {
+    return "primary";
+  }
+  if (getListVar("SALEOR_SECONDARY_USERS").includes(userId)) {
+    return "secondary";
+  }
+  const percent = Math.min(100, Math.max(0, getNumberVar("SALEOR_SECONDARY_PERCENT", 0)));
+  return getTargetBucket(userId) < percent ? "secondary" : "primary";
+}
+
+/**
+ * Run a request's work against a Saleor target
+ */
+export function runWithSaleorTarget<T>(target: SaleorTarget, fn: () => T): T {
+  return targetStorage.run(target, fn);
+}
+
+/**
+ * Saleor target of the current request (primary outside a request)
+ */
+export function getSaleorTarget(): SaleorTarget {
+  return targetStorage.getStore() ?? "primary";
+}
+
+function normalizeApiUrl(url: string): string {
+  return url.trim().replace(/\/+$/, "").toLowerCase();
+}
+
+/**
+ * Saleor target a webhook came from, by its Saleor-Api-Url header
+ */
+export function getSaleorTargetForApiUrl(apiUrl: string | null): SaleorTarget {
+  const secondaryUrl = getVar("SALEOR_SECONDARY_API_URL");
+  if (!apiUrl || !secondaryUrl || !isSecondaryTargetConfigured()) {
+    return "primary";
+  }
+  return normalizeApiUrl(apiUrl) === normalizeApiUrl(secondaryUrl) ? "secondary" : "primary";
+}
@@ -0,0 +1,225 @@
+// Saleor Webhook Receiver
+// POST /saleor/webhook accepts Saleor async webhooks signed with the
+// webhook's secret key (HMAC-SHA256 hex in the Saleor-Signature header,
+// keyed by SALEOR_WEBHOOK_SECRET). Payment and transaction events update
+// the order's payment status (and an AUTHORIZATION_SUCCESS transaction
+// event turns a pending payment hold into HELD), order_fulfilled credits
+// loyalty points and counts toward dish popularity, product, category and
+// channel events invalidate the cached catalog (saleorCache.ts); other
+// events are acknowledged and ignored. Deliveries whose Saleor-Api-Url is
+// the secondary Saleor are checked with SALEOR_SECONDARY_WEBHOOK_SECRET
+// (falling back to SALEOR_WEBHOOK_SECRET) and handled against it.
+
+import { getVar } from "./config";
+import { logger } from "./logger";
+import { recordOrderPopularity } from "./dishPopularity";
+import { awardOrderPoints } from "./loyalty";
+import { confirmPaymentHold } from "./paymentHolds";
+import { recordPaymentEvent } from "./paymentStatus";
+import { SaleorCacheTag, invalidateSaleorCache } from "./saleorCache";
+import { getSaleorTargetForApiUrl, runWithSaleorTarget } from "./saleorTargets";
+
+export const SALEOR_WEBHOOK_PATH = "/saleor/webhook";
+
+// Order events that change payment state (alongside payment_* / transaction_*)
+const ORDER_PAYMENT_EVENTS = [
+  "order_paid",
+  "order_fully_paid",
+  "order_refunded",
+  "order_fully_refunded",
+];
+
+// Order event that earns loyalty points and raises dish popularity
+const FULFILLED_EVENT = "order_fulfilled";
+
+/**
+ * Whether a Saleor event type concerns payments
+ */
+export function isPaymentEvent(event: string): boolean {
+  return (
+    event.startsWith("payment_") ||
+    event.startsWith("transaction_") ||
+    ORDER_PAYMENT_EVENTS.includes(event)
+  );
+}
+
+/**
+ * Cached catalog data a Saleor event makes stale
+ * Product types are the app's categories; products carry their category
+ */
+export function getStaleCacheTags(event: string): SaleorCacheTag[] {
+  if (event.startsWith("product_type_") || event.startsWith("category_")) {
+    return ["categories", "products"];
+  }
+  // product_*, including variants, stock and channel listings
+  if (event.startsWith("product_")) {
+    return ["products"];
+  }
+  // Channels are listed with their warehouses
+  if (event.startsWith("channel_") || event.startsWith("warehouse_")) {
+    return ["channels"];
+  }
+  return [];
+}
+
+/**
+ * Find the order ID in a subscription or legacy webhook payload
+ */
+export function extractOrderId(payload: any): string | null {
+  const item = Array.isArray(payload) ? payload[0] : payload;
+  const candidates = [
+    item?.order?.id,
+    item?.transaction?.order?.id,
+    item?.payment?.order?.id,
+    item?.order_id,
+    // Legacy order payloads are the serialized order itself
+    item?.type === "Order" ? item?.id : undefined,
+  ];
+  const orderId = candidates.find((id) => typeof id === "string" && id);
+  return orderId ?? null;
+}
+
+/**
+ * Whether the payload reports a failed charge or refused payment
+ */
+export function isFailedPayment(event: string, payload: any): boolean {
+  const item = Array.isArray(payload) ? payload[0] : payload;
+  if (event.includes("fail")) {
+    return true;
+  }
+  if (item?.payment?.chargeStatus === "REFUSED") {
+    return true;
+  }
+  const events: Array<{ type?: string }> = item?.transaction?.events || [];
+  const latest = events[events.length - 1];
+  return Boolean(latest?.type?.endsWith("_FAILURE"));
+}
+
+/**
+ * Transaction the payload reports as authorized (latest event
+ * AUTHORIZATION_SUCCESS), if any
+ */
+export function extractAuthorizedTransaction(payload: any): string | null {
+  const item = Array.isArray(payload) ? payload[0] : payload;
+  const events: Array<{ type?: string }> = item?.transaction?.events || [];
+  const latest = events[events.length - 1];
+  const transactionId = item?.transaction?.id;
+  return latest?.type === "AUTHORIZATION_SUCCESS" && typeof transactionId === "string"
+    ? transactionId
+    : null;
+}
+
+function toHex(buffer: ArrayBuffer): string {
+  return Array.from(new Uint8Array(buffer))
+    .map((b) => b.toString(16).padStart(2, "0"))
+    .join("");
+}
+
+/**
+ * Check the Saleor-Signature header against the raw body
+ */
+export async function verifySaleorSignature(
+  body: string,
+  signature: string | null,
+  secret: string,
+): Promise<boolean> {
+  if (!signature) {
+    return false;
+  }
+  const key = await crypto.subtle.importKey(
+    "raw",
+    new TextEncoder().encode(secret),
+    { name: "HMAC", hash: "SHA-256" },
+    false,
+    ["sign"],
+  );
+  const expected = toHex(
+    await crypto.subtle.sign("HMAC", key, new TextEncoder().encode(body)),
+  );
+  const actual = signature.trim().toLowerCase();
+  if (actual.length !== expected.length) {
+    return false;
+  }
+  // Constant-time comparison
+  let diff = 0;
+  for (let i = 0; i < expected.length; i++) {
+    diff |= expected.charCodeAt(i) ^ actual.charCodeAt(i);
+  }
+  return diff === 0;
+}
+
+/**
+ * Saleor webhook endpoint (POST /saleor/webhook)
+ * Authenticated with SALEOR_WEBHOOK_SECRET instead of initData
+ */
+export async function handleSaleorWebhook(request: Request): Promise<Response> {
+  const target = getSaleorTargetForApiUrl(request.headers.get("Saleor-Api-Url"));
+  const secret =
+    (target === "secondary" && getVar("SALEOR_SECONDARY_WEBHOOK_SECRET")) ||
+    getVar("SALEOR_WEBHOOK_SECRET");
+  const body = await request.text();
+  if (
+    !secret ||
+    !(await verifySaleorSignature(body, request.headers.get("Saleor-Signature"), secret))
+  ) {
+    logger.authFailure("invalid_saleor_signature");
+    return new Response("Forbidden", { status: 403 });
+  }
+
+  let payload: any;
+  try {
+    payload = JSON.parse(body);
+  } catch {
+    return new Response("Bad Request", { status: 400 });
+  }
+
+  const event = (request.headers.get("Saleor-Event") || "").toLowerCase();
+  return runWithSaleorTarget(target, () => handleSaleorEvent(event, payload));
+}
+
+/**
+ * Handle a verified webhook delivery against its Saleor target
+ */
+async function handleSaleorEvent(event: string, payload: any): Promise<Response> {
+  const staleTags = getStaleCacheTags(event);
+  if (staleTags.length > 0) {
+    await invalidateSaleorCache(...staleTags);
+    logger.debug("saleor_cache_invalidated", { event, tags: staleTags });
+    return new Response("OK", { status: 200 });
+  }
+
+  const isFulfilledEvent = event === FULFILLED_EVENT;
+  if (!isPaymentEvent(event) && !isFulfilledEvent) {
+    logger.debug("saleor_webhook_ignored", { event });
+    return new Response("OK", { status: 200 });
+  }
+
+  const orderId = extractOrderId(payload);
+  if (!orderId) {
+    logger.warn("saleor_webhook_no_order", { event });
+    return new Response("OK", { status: 200 });
+  }
+
+  try {
+    if (isFulfilledEvent) {
+      await awardOrderPoints(orderId);
+      await recordOrderPopularity(orderId);
+    } else {
+      const authorized = extractAuthorizedTransaction(payload);
+      if (authorized) {
+        await confirmPaymentHold(orderId, authorized);
+      }
+      await recordPaymentEvent(orderId, event, isFailedPayment(event, payload));
+    }
+  } catch (error) {
+    logger.error("saleor_webhook_error", {
+      event,
+      orderId,
+      error: error instanceof Error ? error.message : "Unknown error",
+    });
+    // 5xx lets Saleor retry the delivery
+    return new Response("Internal Server Error", { status: 500 });
+  }
+
+  return new Response("OK", { status: 200 });
+}
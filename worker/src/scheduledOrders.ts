@@ -0,0 +1,88 @@
+// Scheduled Orders ("order for later")
+// Validates a requested scheduledFor time against lead-time limits and
+// the restaurant's opening hours (tma_hours channel metadata, with date
+// overrides from tma_hours_overrides). ASAP orders need it open now.
+
+import { getBooleanVar, getNumberVar } from "./config";
+import { badUserInputError } from "./errors";
+import { parseOpeningHours, isOpenAt, isOpenNow } from "./openingHours";
+import { fetchChannelById } from "./saleorService";
+
+/**
+ * Scheduling limits from SCHEDULED_ORDER_MIN_LEAD_MINUTES / SCHEDULED_ORDER_MAX_DAYS
+ */
+export function getScheduledOrderLimits(): {
+  minLeadMinutes: number;
+  maxDaysAhead: number;
+} {
+  return {
+    minLeadMinutes: Math.max(0, getNumberVar("SCHEDULED_ORDER_MIN_LEAD_MINUTES", 30)),
+    maxDaysAhead: Math.max(0, getNumberVar("SCHEDULED_ORDER_MAX_DAYS", 7)),
+  };
+}
+
+/**
+ * Validate a scheduledFor timestamp for a restaurant
+ * Returns the normalized ISO timestamp, throws BAD_USER_INPUT otherwise
+ */
+export async function validateScheduledFor(
+  scheduledFor: string,
+  restaurantId: string,
+  now: Date = new Date(),
+): Promise<string> {
+  const date = new Date(scheduledFor);
+  if (!scheduledFor || isNaN(date.getTime())) {
+    throw badUserInputError(
+      "scheduledFor must be an ISO date-time",
+      "scheduledFor",
+    );
+  }
+
+  const { minLeadMinutes, maxDaysAhead } = getScheduledOrderLimits();
+  const earliest = now.getTime() + minLeadMinutes * 60 * 1000;
+  const latest = now.getTime() + maxDaysAhead * 24 * 60 * 60 * 1000;
+
+  if (date.getTime() < earliest) {
+    throw badUserInputError(
+      `Scheduled orders must be at least ${minLeadMinutes} minutes ahead`,
+      "scheduledFor",
+    );
+  }
+  if (date.getTime() > latest) {
+    throw badUserInputError(
+      `Scheduled orders can be placed at most ${maxDaysAhead} days ahead`,
+      "scheduledFor",
+    );
+  }
+
+  const channel = await fetchChannelById(restaurantId);
+  const hours = parseOpeningHours(channel?.metadata);
+  if (hours && !isOpenAt(hours, date)) {
+    throw badUserInputError(
+      "The restaurant is closed at the requested time",
+      "scheduledFor",
+    );
+  }
+
+  return date.toISOString();
+}
+
+/**
+ * Reject an ASAP order while the restaurant is closed
+ * Restaurants without opening hours always accept orders, and
+ * ACCEPT_ORDERS_WHEN_CLOSED turns the check off (the kitchen queues them)
+ */
+export function validateAsapOrder(
+  metadata: Record<string, string> | undefined,
+  now: Date = new Date(),
+): void {
+  if (getBooleanVar("ACCEPT_ORDERS_WHEN_CLOSED")) {
+    return;
+  }
+  if (isOpenNow(metadata, now) === false) {
+    throw badUserInputError(
+      "The restaurant is closed now, schedule the order for later",
+      "scheduledFor",
+    );
+  }
+}
@@ -0,0 +1,78 @@
+// Pipeline Shadow Mode Tests
+// Tests for shadowPipeline.ts - draft vs checkout result comparison
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { runWithWaitUntil } from "./backgroundTasks";
+import { SaleorFetch, initializeSaleorClient } from "./saleorClient";
+import { CreateOrderResult, SaleorOrder } from "./saleorOrder";
+import { findDivergences, runWithShadow } from "./shadowPipeline";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+afterEach(() => {
+  initializeSaleorClient({});
+  delete (globalThis as any).SALEOR_TRANSPORT;
+  delete (globalThis as any).SHADOW_CHANNEL_ID;
+});
+
+function orderResult(amount: number, quantity: number): CreateOrderResult {
+  const order: SaleorOrder = {
+    id: `order-${amount}`,
+    status: "CREATED",
+    total: { gross: { amount, currency: "USD" } },
+    deliveryAddress: { address: "1 Main St" },
+    lines: [{ variantId: "dish-1", quantity, productName: "Dish" }],
+    createdAt: new Date().toISOString(),
+  };
+  return { success: true, order };
+}
+
+describe("findDivergences", () => {
+  it("should report nothing for matching results", () => {
+    expect(findDivergences(orderResult(20, 2), orderResult(20.001, 2))).toEqual([]);
+  });
+
+  it("should report total and quantity differences", () => {
+    expect(findDivergences(orderResult(20, 2), orderResult(25, 3))).toEqual([
+      "total: 20 vs 25",
+      "quantity: 2 vs 3",
+    ]);
+  });
+
+  it("should report a failing shadow pipeline", () => {
+    const failed: CreateOrderResult = {
+      success: false,
+      errorCode: "NO_DELIVERY_METHOD",
+    };
+    expect(findDivergences(orderResult(20, 2), failed)).toEqual([
+      "shadow failed: NO_DELIVERY_METHOD",
+    ]);
+  });
+});
+
+describe("runWithShadow", () => {
+  it("should return the primary result without waiting for the shadow run", async () => {
+    // The shadow pipeline's Saleor calls never answer
+    const send = vi.fn<SaleorFetch>(() => new Promise<Response>(() => {}));
+    (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+    (globalThis as any).SHADOW_CHANNEL_ID = "sandbox";
+    initializeSaleorClient({ SALEOR_API_URL: "https://saleor.test/graphql/", SALEOR_TOKEN: "t" });
+
+    const background: Promise<unknown>[] = [];
+    const input = {
+      restaurantId: "restA",
+      deliveryLocation: { address: "1 Main St" },
+      items: [{ dishId: "dish-1", quantity: 2 }],
+    };
+    const result = await runWithWaitUntil(
+      (promise) => background.push(promise),
+      () => runWithShadow(async () => orderResult(20, 2), input, "user-1"),
+    );
+
+    expect(result.order?.id).toBe("order-20");
+    expect(background).toHaveLength(1);
+  });
+});
@@ -0,0 +1,206 @@
+// Checkout Pipeline Shadow Mode
+// While orders still go through the draft pipeline, ORDER_PIPELINE_SHADOW
+// replays each order through the checkout pipeline against a sandbox channel
+// (SHADOW_CHANNEL_ID) in parallel, compares outcome, total, lines and
+// latency, and records divergences. Shadow orders are cancelled right away
+// and never affect the customer's result: the shadow run, its cancellation
+// and the comparison finish in the background (waitUntil).
+
+import {
+  OrderPipelineShadowReport,
+  PlaceOrderInput,
+  ShadowOrderComparison,
+} from "./contracts";
+import { runInBackground } from "./backgroundTasks";
+import { getBooleanVar, getVar } from "./config";
+import { logger } from "./logger";
+import { toMinorUnits } from "./money";
+import { getSaleorClient } from "./saleorClient";
+import { createCheckoutOrder } from "./saleorCheckout";
+import { CreateOrderResult, cancelSaleorOrder } from "./saleorOrder";
+import { readAllJSON, writeJSON } from "./storage";
+
+const SHADOW_KEY_PREFIX = "shadow:order:";
+
+// Comparisons are kept for a week
+const SHADOW_TTL_SECONDS = 7 * 24 * 60 * 60;
+
+// Divergent comparisons listed in the report
+const REPORT_RECENT_LIMIT = 20;
+
+/**
+ * Shadow mode needs ORDER_PIPELINE_SHADOW=true and a sandbox channel
+ */
+export function isShadowModeEnabled(): boolean {
+  return getBooleanVar("ORDER_PIPELINE_SHADOW") && Boolean(getVar("SHADOW_CHANNEL_ID"));
+}
+
+async function timed(
+  run: () => Promise<CreateOrderResult>,
+): Promise<{ result: CreateOrderResult; ms: number }> {
+  const startedAt = Date.now();
+  try {
+    return { result: await run(), ms: Date.now() - startedAt };
+  } catch (error) {
+    return {
+      result: {
+        success: false,
+        error: error instanceof Error ? error.message : "Unknown error",
+        errorCode: "SHADOW_EXCEPTION",
+      },
+      ms: Date.now() - startedAt,
+    };
+  }
+}
+
+/**
+ * Differences between the primary (draft) and shadow (checkout) results
+ */
+export function findDivergences(
+  primary: CreateOrderResult,
+  shadow: CreateOrderResult,
+): string[] {
+  if (primary.success !== shadow.success) {
+    return [
+      primary.success
+        ? `shadow failed: ${shadow.errorCode || shadow.error || "unknown"}`
+        : `primary failed: ${primary.errorCode || primary.error || "unknown"}`,
+    ];
+  }
+  if (!primary.order || !shadow.order) {
+    return [];
+  }
+
+  const divergences: string[] = [];
+  const primaryTotal = primary.order.total.gross;
+  const shadowTotal = shadow.order.total.gross;
+  if (primaryTotal.currency !== shadowTotal.currency) {
+    divergences.push(
+      `currency: ${primaryTotal.currency} vs ${shadowTotal.currency}`,
+    );
+  } else if (
+    toMinorUnits(primaryTotal.amount, primaryTotal.currency) !==
+    toMinorUnits(shadowTotal.amount, shadowTotal.currency)
+  ) {
+    divergences.push(`total: ${primaryTotal.amount} vs ${shadowTotal.amount}`);
+  }
+
+  const quantity = (result: CreateOrderResult) =>
+    (result.order?.lines || []).reduce((sum, line) => sum + line.quantity, 0);
+  if (quantity(primary) !== quantity(shadow)) {
+    divergences.push(`quantity: ${quantity(primary)} vs ${quantity(shadow)}`);
+  }
+  return divergences;
+}
+
+/**
+ * Run the primary pipeline with the checkout pipeline shadowing it
+ * Returns the primary result as soon as it is ready
+ */
+export async function runWithShadow(
+  primary: () => Promise<CreateOrderResult>,
+  input: PlaceOrderInput,
+  userId: string,
+  userName?: string,
+  userLanguage?: string,
+): Promise<CreateOrderResult> {
+  const client = getSaleorClient();
+  const shadowChannelId = getVar("SHADOW_CHANNEL_ID");
+  if (!client || !shadowChannelId) {
+    return primary();
+  }
+
+  const primaryRun = timed(primary);
+
+  // Shadow orders belong to a pseudo-user so they never show up in history
+  runInBackground(async () => {
+    const shadowRun = await timed(() =>
+      createCheckoutOrder(
+        client,
+        { ...input, channelId: shadowChannelId },
+        shadowChannelId,
+        `shadow-${userId}`,
+        userName,
+        userLanguage,
+      ),
+    );
+    await recordComparison(await primaryRun, shadowRun);
+  }, "order_pipeline_shadow_error");
+
+  return (await primaryRun).result;
+}
+
+async function recordComparison(
+  primaryRun: { result: CreateOrderResult; ms: number },
+  shadowRun: { result: CreateOrderResult; ms: number },
+): Promise<void> {
+  const shadowOrderId = shadowRun.result.order?.id ?? null;
+  if (shadowOrderId) {
+    await cancelSaleorOrder(shadowOrderId);
+  }
+
+  const comparison: ShadowOrderComparison = {
+    id: crypto.randomUUID(),
+    createdAt: new Date().toISOString(),
+    primaryOrderId: primaryRun.result.order?.id ?? null,
+    shadowOrderId,
+    primarySuccess: primaryRun.result.success,
+    shadowSuccess: shadowRun.result.success,
+    primaryMs: primaryRun.ms,
+    shadowMs: shadowRun.ms,
+    primaryTotal: primaryRun.result.order?.total.gross.amount ?? null,
+    shadowTotal: shadowRun.result.order?.total.gross.amount ?? null,
+    divergences: findDivergences(primaryRun.result, shadowRun.result),
+  };
+
+  await writeJSON(`${SHADOW_KEY_PREFIX}${comparison.id}`, comparison, {
+    expirationTtl: SHADOW_TTL_SECONDS,
+  });
+
+  if (comparison.divergences.length > 0) {
+    logger.warn("order_pipeline_divergence", {
+      primaryOrderId: comparison.primaryOrderId,
+      shadowOrderId,
+      divergences: comparison.divergences.join("; "),
+      primaryMs: comparison.primaryMs,
+      shadowMs: comparison.shadowMs,
+    });
+  } else {
+    logger.info("order_pipeline_shadow_match", {
+      primaryOrderId: comparison.primaryOrderId,
+      primaryMs: comparison.primaryMs,
+      shadowMs: comparison.shadowMs,
+    });
+  }
+}
+
+function percentile(values: number[], p: number): number {
+  if (values.length === 0) {
+    return 0;
+  }
+  const sorted = [...values].sort((a, b) => a - b);
+  return sorted[Math.min(sorted.length - 1, Math.floor(p * sorted.length))];
+}
+
+/**
+ * Summary of recorded comparisons (last 7 days)
+ */
+export async function getShadowReport(): Promise<OrderPipelineShadowReport> {
+  const comparisons = await readAllJSON<ShadowOrderComparison>(SHADOW_KEY_PREFIX);
+  const divergent = comparisons
+    .filter((c) => c.divergences.length > 0)
+    .sort((a, b) => b.createdAt.localeCompare(a.createdAt));
+  const primaryMs = comparisons.map((c) => c.primaryMs);
+  const shadowMs = comparisons.map((c) => c.shadowMs);
+
+  return {
+    enabled: isShadowModeEnabled(),
+    total: comparisons.length,
+    divergent: divergent.length,
+    primaryP50Ms: percentile(primaryMs, 0.5),
+    primaryP95Ms: percentile(primaryMs, 0.95),
+    shadowP50Ms: percentile(shadowMs, 0.5),
+    shadowP95Ms: percentile(shadowMs, 0.95),
+    recentDivergences: divergent.slice(0, REPORT_RECENT_LIMIT),
+  };
+}
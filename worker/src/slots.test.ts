@@ -0,0 +1,43 @@
+// Slot Reservation Tests
+// Tests for slots.ts - per-slot capacity for scheduled orders
+
+import { describe, it, expect, vi } from "vitest";
+import { assertSlotAvailable, getSlotCapacity, releaseSlot, reserveSlot } from "./slots";
+
+vi.mock("./logger", () => ({
+  logger: {
+    info: vi.fn(),
+    error: vi.fn(),
+    warn: vi.fn(),
+    debug: vi.fn(),
+  },
+}));
+
+describe("getSlotCapacity", () => {
+  it("should read a positive whole cap", () => {
+    expect(getSlotCapacity({ tma_slot_capacity: "3" })).toBe(3);
+    expect(getSlotCapacity({ tma_slot_capacity: "0" })).toBeNull();
+    expect(getSlotCapacity({ tma_slot_capacity: "1.5" })).toBeNull();
+    expect(getSlotCapacity(undefined)).toBeNull();
+  });
+});
+
+describe("assertSlotAvailable", () => {
+  it("should refuse a slot once it holds the cap", async () => {
+    const scheduledFor = new Date(Date.now() + 2 * 60 * 60 * 1000).toISOString();
+    const channel = { id: "slot-rest", metadata: { tma_slot_capacity: "1" } };
+
+    await expect(assertSlotAvailable(channel, scheduledFor)).resolves.toBeUndefined();
+    const slotStart = await reserveSlot("slot-rest", scheduledFor, "order-1");
+    await expect(assertSlotAvailable(channel, scheduledFor)).rejects.toMatchObject({
+      code: "BAD_USER_INPUT",
+      field: "scheduledFor",
+    });
+    // Uncapped restaurants take any number of orders
+    const uncapped = { ...channel, metadata: {} };
+    await expect(assertSlotAvailable(uncapped, scheduledFor)).resolves.toBeUndefined();
+
+    await releaseSlot("slot-rest", slotStart, "order-1");
+    await expect(assertSlotAvailable(channel, scheduledFor)).resolves.toBeUndefined();
+  });
+});
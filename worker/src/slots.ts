@@ -0,0 +1,122 @@
+// Scheduled Order Slot Reservations
+// Scheduled orders reserve a time slot (scheduledFor rounded down to
+// DELIVERY_SLOT_MINUTES) per restaurant; reservations are released when
+// an order is cancelled or expires unpaid. Restaurants can cap orders per
+// slot (tma_slot_capacity channel metadata; unset or 0 means no cap): full
+// slots are left out of deliverySlots and placeOrder refuses them.
+
+import { Channel } from "./contracts";
+import { getNumberVar } from "./config";
+import { badUserInputError } from "./errors";
+import { logger } from "./logger";
+import { parseNumberValue } from "./metadata";
+import { writeJSON, deleteKey, listKeys } from "./storage";
+
+export interface SlotReservation {
+  restaurantId: string;
+  slotStart: string; // ISO timestamp
+  orderId: string;
+  reservedAt: string;
+}
+
+export const SLOT_CAPACITY_METADATA_KEY = "tma_slot_capacity";
+
+// Reservations are kept a day past the slot start, then dropped by KV TTL
+const RESERVATION_GRACE_SECONDS = 24 * 60 * 60;
+
+function getPrefix(restaurantId: string, slotStart: string): string {
+  return `slot:${restaurantId}:${slotStart}:`;
+}
+
+/**
+ * Slot length in minutes from DELIVERY_SLOT_MINUTES (default 15)
+ */
+export function getSlotMinutes(): number {
+  const minutes = getNumberVar("DELIVERY_SLOT_MINUTES", 15);
+  return minutes > 0 ? Math.floor(minutes) : 15;
+}
+
+/**
+ * Start of the slot containing a date, as an ISO timestamp
+ */
+export function getSlotStart(date: Date): string {
+  const slotMs = getSlotMinutes() * 60 * 1000;
+  return new Date(Math.floor(date.getTime() / slotMs) * slotMs).toISOString();
+}
+
+/**
+ * Reserve the slot for a scheduled order, returning the slot start
+ */
+export async function reserveSlot(
+  restaurantId: string,
+  scheduledFor: string,
+  orderId: string,
+): Promise<string> {
+  const slotStart = getSlotStart(new Date(scheduledFor));
+  const reservation: SlotReservation = {
+    restaurantId,
+    slotStart,
+    orderId,
+    reservedAt: new Date().toISOString(),
+  };
+  const ttl = Math.max(
+    60,
+    Math.ceil((new Date(slotStart).getTime() - Date.now()) / 1000) +
+      RESERVATION_GRACE_SECONDS,
+  );
+  await writeJSON(getPrefix(restaurantId, slotStart) + orderId, reservation, {
+    expirationTtl: ttl,
+  });
+  logger.info("slot_reserved", { restaurantId, slotStart, orderId });
+  return slotStart;
+}
+
+/**
+ * Release an order's slot reservation
+ */
+export async function releaseSlot(
+  restaurantId: string,
+  slotStart: string,
+  orderId: string,
+): Promise<void> {
+  await deleteKey(getPrefix(restaurantId, slotStart) + orderId);
+  logger.info("slot_released", { restaurantId, slotStart, orderId });
+}
+
+/**
+ * Number of orders holding a slot
+ */
+export async function countSlotReservations(
+  restaurantId: string,
+  slotStart: string,
+): Promise<number> {
+  const keys = await listKeys(getPrefix(restaurantId, slotStart));
+  return keys.length;
+}
+
+/**
+ * Scheduled orders allowed per slot from channel metadata, null when uncapped
+ */
+export function getSlotCapacity(metadata: Record<string, string> | undefined): number | null {
+  const value = parseNumberValue(metadata?.[SLOT_CAPACITY_METADATA_KEY]);
+  return value !== null && Number.isInteger(value) && value > 0 ? value : null;
+}
+
+/**
+ * Refuse a scheduled order whose slot already holds the restaurant's cap
+ */
+export async function assertSlotAvailable(
+  channel: Pick<Channel, "id" | "metadata"> | null,
+  scheduledFor: string,
+): Promise<void> {
+  const capacity = channel ? getSlotCapacity(channel.metadata) : null;
+  if (!channel || capacity === null) {
+    return;
+  }
+  const slotStart = getSlotStart(new Date(scheduledFor));
+  const reserved = await countSlotReservations(channel.id, slotStart);
+  if (reserved >= capacity) {
+    logger.info("slot_full", { restaurantId: channel.id, slotStart, reserved, capacity });
+    throw badUserInputError("This delivery slot is full, please pick another", "scheduledFor");
+  }
+}
@@ -0,0 +1,95 @@
+// Staff Invitation Tests
+// Tests for staffInvites.ts and staffRoles.ts - one-time invites and roles
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import {
+  acceptStaffInvite,
+  buildInviteLink,
+  createStaffInvite,
+  parseInviteToken,
+} from "./staffInvites";
+import { hasStaffRole, listRestaurantStaff, listUserStaffRoles, revokeStaffRole } from "./staffRoles";
+import { readJSON, writeJSON } from "./storage";
+
+// Mock the logger to avoid console output during tests
+vi.mock("./logger", () => ({
+  logger: {
+    info: vi.fn(),
+    error: vi.fn(),
+    warn: vi.fn(),
+    debug: vi.fn(),
+  },
+}));
+
+afterEach(() => {
+  delete (globalThis as any).TELEGRAM_MINI_APP_URL;
+});
+
+describe("invite links", () => {
+  it("should build a startapp deep link when the Mini App URL is set", () => {
+    expect(buildInviteLink("abc")).toBeNull();
+    (globalThis as any).TELEGRAM_MINI_APP_URL = "https://t.me/food_bot/app";
+    expect(buildInviteLink("abc")).toBe("https://t.me/food_bot/app?startapp=invite_abc");
+  });
+
+  it("should accept the start parameter or the bare token", () => {
+    expect(parseInviteToken("invite_abc")).toBe("abc");
+    expect(parseInviteToken(" abc ")).toBe("abc");
+  });
+});
+
+describe("staff invites", () => {
+  it("should grant the role once and list it", async () => {
+    const invite = await createStaffInvite("rest-inv-1", "COURIER", "admin-1");
+    expect(invite.token).toMatch(/^[A-Za-z0-9_-]{22}$/);
+
+    const member = await acceptStaffInvite(`invite_${invite.token}`, "courier-1");
+    expect(member).toMatchObject({ restaurantId: "rest-inv-1", role: "COURIER", grantedBy: "admin-1" });
+    expect(await hasStaffRole("rest-inv-1", "courier-1", ["COURIER"])).toBe(true);
+    expect((await listRestaurantStaff("rest-inv-1")).map((m) => m.telegramUserId)).toEqual([
+      "courier-1",
+    ]);
+    expect((await listUserStaffRoles("courier-1")).map((m) => m.restaurantId)).toEqual([
+      "rest-inv-1",
+    ]);
+
+    await expect(acceptStaffInvite(invite.token, "someone-else")).rejects.toThrow(
+      "invalid or has expired",
+    );
+  });
+
+  it("should grant the role to the first redeemer only", async () => {
+    const invite = await createStaffInvite("rest-inv-3", "STAFF", "admin-1");
+    const record = await readJSON(`staff-invite:${invite.token}`);
+
+    await acceptStaffInvite(invite.token, "staff-1");
+    // A stale read still sees the invite after the delete
+    await writeJSON(`staff-invite:${invite.token}`, record);
+
+    await expect(acceptStaffInvite(invite.token, "staff-2")).rejects.toThrow(
+      "invalid or has expired",
+    );
+    expect(await hasStaffRole("rest-inv-3", "staff-2", ["STAFF"])).toBe(false);
+    // The first redeemer retrying is fine
+    await expect(acceptStaffInvite(invite.token, "staff-1")).resolves.toMatchObject({
+      role: "STAFF",
+    });
+  });
+
+  it("should reject expired invites", async () => {
+    const created = new Date(Date.now() - 100 * 60 * 60 * 1000);
+    const invite = await createStaffInvite("rest-inv-2", "STAFF", "admin-1", created);
+    await expect(acceptStaffInvite(invite.token, "staff-1")).rejects.toThrow(
+      "invalid or has expired",
+    );
+  });
+
+  it("should revoke roles", async () => {
+    const invite = await createStaffInvite("rest-inv-3", "STAFF", "admin-1");
+    await acceptStaffInvite(invite.token, "staff-2");
+    expect(await revokeStaffRole("rest-inv-3", "staff-2", "admin-1")).toBe(true);
+    expect(await hasStaffRole("rest-inv-3", "staff-2")).toBe(false);
+    expect(await listUserStaffRoles("staff-2")).toEqual([]);
+    expect(await revokeStaffRole("rest-inv-3", "staff-2", "admin-1")).toBe(false);
+  });
+});
@@ -0,0 +1,181 @@
+// Courier & Staff Invitations
+// A restaurant admin creates a one-time invite for the COURIER or STAFF
+// role; the worker returns a Mini App deep link
+// (TELEGRAM_MINI_APP_URL?startapp=invite_<token>). The Mini App passes the
+// start parameter to acceptStaffInvite, which grants the role to whoever
+// opened it, so admins no longer collect Telegram user IDs by hand.
+// Invites live in KV (staff-invite:<token>) until used or expired
+// (STAFF_INVITE_TTL_HOURS, default 72). KV has no compare-and-set, so the
+// first redeemer is recorded (staff-invite-redeemed:<token>) only when
+// none is, and read back before the role is granted.
+
+import { StaffInvite, StaffMember, StaffRole } from "./contracts";
+import { getNumberVar } from "./config";
+import { notFoundError } from "./errors";
+import { logger } from "./logger";
+import { buildMiniAppLink } from "./notifications";
+import { grantStaffRole } from "./staffRoles";
+import { deleteKey, readJSON, writeJSON } from "./storage";
+
+interface StaffInviteRecord {
+  token: string;
+  restaurantId: string;
+  role: StaffRole;
+  createdBy: string;
+  createdAt: string;
+  expiresAt: string;
+}
+
+/**
+ * The user an invite was redeemed by
+ */
+interface InviteRedemptionRecord {
+  token: string;
+  userId: string;
+  redeemedAt: string;
+}
+
+const INVITE_PREFIX = "staff-invite:";
+const REDEEMED_PREFIX = "staff-invite-redeemed:";
+// KV's minimum expirationTtl
+const MIN_TTL_SECONDS = 60;
+// Telegram start parameters allow [A-Za-z0-9_-]
+export const INVITE_START_PARAM_PREFIX = "invite_";
+
+function getKey(token: string): string {
+  return `${INVITE_PREFIX}${token}`;
+}
+
+function getRedeemedKey(token: string): string {
+  return `${REDEEMED_PREFIX}${token}`;
+}
+
+/**
+ * Hours an invite stays valid (STAFF_INVITE_TTL_HOURS, default 72)
+ */
+export function getInviteTtlHours(): number {
+  const hours = getNumberVar("STAFF_INVITE_TTL_HOURS", 72);
+  return hours > 0 ? hours : 72;
+}
+
+/**
+ * Random URL-safe invite token (128 bits)
+ */
+export function generateInviteToken(): string {
+  const bytes = crypto.getRandomValues(new Uint8Array(16));
+  return btoa(String.fromCharCode(...bytes))
+    .replace(/\+/g, "-")
+    .replace(/\//g, "_")
+    .replace(/=+$/, "");
+}
+
+/**
+ * Mini App deep link for an invite, or null without TELEGRAM_MINI_APP_URL
+ */
+export function buildInviteLink(token: string): string | null {
+  return buildMiniAppLink(`${INVITE_START_PARAM_PREFIX}${token}`);
+}
+
+/**
+ * Token from an invite start parameter ("invite_<token>") or a bare token
+ */
+export function parseInviteToken(value: string): string {
+  const trimmed = value.trim();
+  return trimmed.startsWith(INVITE_START_PARAM_PREFIX)
+    ? trimmed.substring(INVITE_START_PARAM_PREFIX.length)
+    : trimmed;
+}
+
+/**
+ * Create a one-time invite granting a role at a restaurant
+ */
+export async function createStaffInvite(
+  restaurantId: string,
+  role: StaffRole,
+  createdBy: string,
+  now: Date = new Date(),
+): Promise<StaffInvite> {
+  const ttlSeconds = Math.round(getInviteTtlHours() * 60 * 60);
+  const record: StaffInviteRecord = {
+    token: generateInviteToken(),
+    restaurantId,
+    role,
+    createdBy,
+    createdAt: now.toISOString(),
+    expiresAt: new Date(now.getTime() + ttlSeconds * 1000).toISOString(),
+  };
+  await writeJSON(getKey(record.token), record, { expirationTtl: ttlSeconds });
+  logger.info("staff_invite_created", { restaurantId, role, createdBy });
+
+  return {
+    token: record.token,
+    restaurantId,
+    role,
+    link: buildInviteLink(record.token),
+    expiresAt: record.expiresAt,
+  };
+}
+
+/**
+ * Record the user redeeming an invite unless another user already has
+ * Returns false when the invite belongs to someone else; the record is read
+ * back so that of two simultaneous redeemers only the last writer wins
+ */
+async function claimInvite(
+  record: StaffInviteRecord,
+  userId: string,
+  now: Date,
+): Promise<boolean> {
+  const key = getRedeemedKey(record.token);
+  const existing = await readJSON<InviteRedemptionRecord>(key);
+  if (existing) {
+    return existing.userId === userId;
+  }
+  const ttlSeconds = Math.max(
+    MIN_TTL_SECONDS,
+    Math.ceil((new Date(record.expiresAt).getTime() - now.getTime()) / 1000),
+  );
+  const redemption: InviteRedemptionRecord = {
+    token: record.token,
+    userId,
+    redeemedAt: now.toISOString(),
+  };
+  await writeJSON(key, redemption, { expirationTtl: ttlSeconds });
+  const winner = await readJSON<InviteRedemptionRecord>(key);
+  return winner?.userId === userId;
+}
+
+/**
+ * Redeem an invite for the user who opened it
+ * The first redeemer is recorded before the invite is deleted, so it can
+ * only grant its role to one user
+ */
+export async function acceptStaffInvite(
+  tokenOrStartParam: string,
+  userId: string,
+  now: Date = new Date(),
+): Promise<StaffMember> {
+  const token = parseInviteToken(tokenOrStartParam);
+  const record = token ? await readJSON<StaffInviteRecord>(getKey(token)) : null;
+  if (!record || new Date(record.expiresAt).getTime() <= now.getTime()) {
+    throw notFoundError("This invite is invalid or has expired");
+  }
+  if (!(await claimInvite(record, userId, now))) {
+    logger.warn("staff_invite_already_redeemed", { restaurantId: record.restaurantId, userId });
+    throw notFoundError("This invite is invalid or has expired");
+  }
+  await deleteKey(getKey(token));
+
+  const member = await grantStaffRole(
+    record.restaurantId,
+    userId,
+    record.role,
+    record.createdBy,
+  );
+  logger.info("staff_invite_accepted", {
+    restaurantId: record.restaurantId,
+    role: record.role,
+    userId,
+  });
+  return member;
+}
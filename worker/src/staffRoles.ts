@@ -0,0 +1,84 @@
+// Restaurant Staff Roles
+// Couriers and restaurant staff granted per restaurant (channel), kept in
+// the role store (store.ts), which lists them by restaurant or by user.
+// Roles are granted by redeeming an invite (staffInvites.ts) and revoked by
+// the restaurant's admin.
+
+import { StaffMember, StaffRole } from "./contracts";
+import { logger } from "./logger";
+import { getStore } from "./store";
+
+/**
+ * A user's role at a restaurant, or null when they have none
+ */
+export async function getStaffMember(
+  restaurantId: string,
+  userId: string,
+): Promise<StaffMember | null> {
+  return getStore().roles.get(restaurantId, userId);
+}
+
+/**
+ * Whether a user holds one of the roles at a restaurant
+ */
+export async function hasStaffRole(
+  restaurantId: string,
+  userId: string,
+  roles: StaffRole[] = ["COURIER", "STAFF"],
+): Promise<boolean> {
+  const member = await getStaffMember(restaurantId, userId);
+  return member !== null && roles.includes(member.role);
+}
+
+/**
+ * Grant (or change) a user's role at a restaurant
+ */
+export async function grantStaffRole(
+  restaurantId: string,
+  userId: string,
+  role: StaffRole,
+  grantedBy: string,
+): Promise<StaffMember> {
+  const member: StaffMember = {
+    restaurantId,
+    telegramUserId: userId,
+    role,
+    grantedAt: new Date().toISOString(),
+    grantedBy,
+  };
+  await getStore().roles.put(member);
+  logger.info("staff_role_granted", { restaurantId, userId, role, grantedBy });
+  return member;
+}
+
+/**
+ * Remove a user's role at a restaurant
+ * Returns false when they had none
+ */
+export async function revokeStaffRole(
+  restaurantId: string,
+  userId: string,
+  revokedBy: string,
+): Promise<boolean> {
+  const member = await getStaffMember(restaurantId, userId);
+  if (!member) {
+    return false;
+  }
+  await getStore().roles.delete(restaurantId, userId);
+  logger.info("staff_role_revoked", { restaurantId, userId, role: member.role, revokedBy });
+  return true;
+}
+
+/**
+ * Couriers and staff of a restaurant, oldest first
+ */
+export async function listRestaurantStaff(restaurantId: string): Promise<StaffMember[]> {
+  return getStore().roles.listByRestaurant(restaurantId);
+}
+
+/**
+ * Restaurants where a user is courier or staff
+ */
+export async function listUserStaffRoles(userId: string): Promise<StaffMember[]> {
+  return getStore().roles.listByUser(userId);
+}
@@ -0,0 +1,173 @@
+// Shared KV persistence helpers
+// JSON records in the CARTS KV namespace with an in-memory fallback,
+// following the same pattern as cart.ts and channelAdmin.ts
+
+export interface StorageKV {
+  get(key: string, type?: "text" | "json"): Promise<string | any | null>;
+  put(
+    key: string,
+    value: string | ReadableStream | ArrayBuffer,
+    options?: { expirationTtl?: number },
+  ): Promise<void>;
+  delete(key: string): Promise<void>;
+  list?(options?: {
+    prefix?: string;
+    cursor?: string;
+    limit?: number;
+  }): Promise<{
+    keys: Array<{ name: string }>;
+    list_complete: boolean;
+    cursor?: string;
+  }>;
+}
+
+interface Env {
+  CARTS?: StorageKV;
+}
+
+interface MemoryEntry {
+  value: string;
+  expiresAt: number | null;
+}
+
+// In-memory store (fallback when KV not available in tests/local dev)
+const memoryStore: Map<string, MemoryEntry> = new Map();
+
+function getKV(): StorageKV | null {
+  if (typeof globalThis !== "undefined") {
+    const env = (globalThis as any).__env__ as Env | undefined;
+    return env?.CARTS ?? null;
+  }
+  return null;
+}
+
+/**
+ * Whether the CARTS KV namespace is bound (otherwise records are per isolate)
+ */
+export function isKVAvailable(): boolean {
+  return getKV() !== null;
+}
+
+function readMemory(key: string): string | null {
+  const entry = memoryStore.get(key);
+  if (!entry) {
+    return null;
+  }
+  if (entry.expiresAt !== null && entry.expiresAt <= Date.now()) {
+    memoryStore.delete(key);
+    return null;
+  }
+  return entry.value;
+}
+
+/**
+ * Read a JSON record, preferring KV over the memory fallback
+ */
+export async function readJSON<T>(key: string): Promise<T | null> {
+  const kv = getKV();
+
+  if (kv) {
+    try {
+      const data = await kv.get(key, "json");
+      if (data) {
+        return data as T;
+      }
+    } catch (error) {
+      console.error(`[Storage] KV get error for ${key}:`, error);
+    }
+  }
+
+  const raw = readMemory(key);
+  return raw ? (JSON.parse(raw) as T) : null;
+}
+
+/**
+ * Write a JSON record to KV (when bound) and memory
+ */
+export async function writeJSON(
+  key: string,
+  value: unknown,
+  options?: { expirationTtl?: number },
+): Promise<void> {
+  const serialized = JSON.stringify(value);
+  const kv = getKV();
+
+  if (kv) {
+    try {
+      await kv.put(key, serialized, options);
+    } catch (error) {
+      console.error(`[Storage] KV put error for ${key}:`, error);
+    }
+  }
+
+  memoryStore.set(key, {
+    value: serialized,
+    expiresAt: options?.expirationTtl
+      ? Date.now() + options.expirationTtl * 1000
+      : null,
+  });
+}
+
+/**
+ * Delete a record from KV and memory
+ */
+export async function deleteKey(key: string): Promise<void> {
+  const kv = getKV();
+
+  if (kv) {
+    try {
+      await kv.delete(key);
+    } catch (error) {
+      console.error(`[Storage] KV delete error for ${key}:`, error);
+    }
+  }
+
+  memoryStore.delete(key);
+}
+
+// Most keys one KV list call returns
+const KV_LIST_PAGE_LIMIT = 1000;
+
+/**
+ * List keys with a prefix (KV list when available, otherwise memory)
+ * With a limit, stops after that many keys (one KV list page up to 1000)
+ */
+export async function listKeys(prefix: string, limit?: number): Promise<string[]> {
+  const kv = getKV();
+
+  if (kv?.list) {
+    try {
+      const names: string[] = [];
+      let cursor: string | undefined;
+      do {
+        const remaining =
+          limit === undefined ? undefined : Math.min(limit - names.length, KV_LIST_PAGE_LIMIT);
+        const page = await kv.list({ prefix, cursor, limit: remaining });
+        names.push(...page.keys.map((k) => k.name));
+        cursor = page.list_complete ? undefined : page.cursor;
+      } while (cursor && (limit === undefined || names.length < limit));
+      return names;
+    } catch (error) {
+      console.error(`[Storage] KV list error for ${prefix}:`, error);
+    }
+  }
+
+  return Array.from(memoryStore.keys())
+    .filter((key) => key.startsWith(prefix) && readMemory(key) !== null)
+    .slice(0, limit);
+}
+
+/**
+ * Read every JSON record under a prefix
+ */
+export async function readAllJSON<T>(prefix: string): Promise<T[]> {
+  const keys = await listKeys(prefix);
+  const records: T[] = [];
+  for (const key of keys) {
+    const record = await readJSON<T>(key);
+    if (record) {
+      records.push(record);
+    }
+  }
+  return records;
+}
@@ -0,0 +1,75 @@
+// Store Tests
+// Tests for store.ts - the in-memory store and swapping stores
+
+import { describe, it, expect, afterEach } from "vitest";
+import { createMemoryStore, getStore, setStore } from "./store";
+import { grantStaffRole, listUserStaffRoles } from "./staffRoles";
+
+afterEach(() => {
+  setStore(null);
+});
+
+describe("memory store", () => {
+  it("should keep carts per user and copy them", async () => {
+    const store = createMemoryStore();
+    const cart = { channelId: "restA", restaurantId: "restA", items: [] };
+    await store.carts.put("user-1", cart);
+    cart.channelId = "restB";
+
+    expect((await store.carts.get("user-1"))?.channelId).toBe("restA");
+    expect(await store.carts.get("user-2")).toBeNull();
+    await store.carts.delete("user-1");
+    expect(await store.carts.get("user-1")).toBeNull();
+  });
+
+  it("should list saved addresses oldest first", async () => {
+    const store = createMemoryStore();
+    await store.addresses.put("user-1", {
+      id: "work",
+      label: "Work",
+      location: { address: "2 Office Road" },
+      createdAt: "2026-02-01T00:00:00.000Z",
+    });
+    await store.addresses.put("user-1", {
+      id: "home",
+      label: "Home",
+      location: { address: "1 Test Street" },
+      createdAt: "2026-01-01T00:00:00.000Z",
+    });
+
+    expect((await store.addresses.list("user-1")).map((a) => a.id)).toEqual(["home", "work"]);
+    expect(await store.addresses.list("user-2")).toEqual([]);
+    expect(await store.addresses.delete("user-1", "home")).toBe(true);
+    expect(await store.addresses.delete("user-1", "home")).toBe(false);
+  });
+
+  it("should filter favorites by kind", async () => {
+    const store = createMemoryStore();
+    const createdAt = new Date().toISOString();
+    await store.favorites.add("user-1", { kind: "RESTAURANT", targetId: "restA", createdAt });
+    await store.favorites.add("user-1", { kind: "DISH", targetId: "dish1", createdAt });
+
+    expect(await store.favorites.list("user-1")).toHaveLength(2);
+    expect((await store.favorites.list("user-1", "DISH")).map((f) => f.targetId)).toEqual([
+      "dish1",
+    ]);
+    expect(await store.favorites.has("user-1", "DISH", "restA")).toBe(false);
+    expect(await store.favorites.remove("user-1", "DISH", "dish1")).toBe(true);
+    expect(await store.favorites.has("user-1", "DISH", "dish1")).toBe(false);
+  });
+});
+
+describe("swapping stores", () => {
+  it("should route staff roles through the active store", async () => {
+    const store = createMemoryStore();
+    setStore(store);
+    expect(getStore()).toBe(store);
+
+    await grantStaffRole("restA", "user-9", "COURIER", "admin");
+    expect((await store.roles.get("restA", "user-9"))?.role).toBe("COURIER");
+    expect((await listUserStaffRoles("user-9")).map((m) => m.restaurantId)).toEqual(["restA"]);
+
+    setStore(null);
+    expect(getStore()).not.toBe(store);
+  });
+});
@@ -0,0 +1,255 @@
+// Persistence Stores
+// Features persist per-user and per-restaurant records through narrow
+// stores (carts, saved addresses, favorites, staff roles)
+// rather than raw keys, so a feature doesn't depend on how its records are
+// laid out. The default store sits on the shared KV helpers (storage.ts);
+// createMemoryStore() is a complete in-memory implementation for tests,
+// and setStore() swaps in either one, or another backend later.
+
+import { CartState, Favorite, FavoriteKind, SavedAddress, StaffMember } from "./contracts";
+import { deleteKey, readAllJSON, readJSON, writeJSON } from "./storage";
+
+export interface CartStore {
+  get(userId: string): Promise<CartState | null>;
+  put(userId: string, cart: CartState, ttlSeconds?: number): Promise<void>;
+  delete(userId: string): Promise<void>;
+}
+
+export interface AddressStore {
+  list(userId: string): Promise<SavedAddress[]>;
+  get(userId: string, addressId: string): Promise<SavedAddress | null>;
+  put(userId: string, address: SavedAddress): Promise<void>;
+  /** false when the address didn't exist */
+  delete(userId: string, addressId: string): Promise<boolean>;
+}
+
+export interface FavoriteStore {
+  list(userId: string, kind?: FavoriteKind): Promise<Favorite[]>;
+  has(userId: string, kind: FavoriteKind, targetId: string): Promise<boolean>;
+  add(userId: string, favorite: Favorite): Promise<void>;
+  /** false when it wasn't a favorite */
+  remove(userId: string, kind: FavoriteKind, targetId: string): Promise<boolean>;
+}
+
+export interface RoleStore {
+  get(restaurantId: string, userId: string): Promise<StaffMember | null>;
+  put(member: StaffMember): Promise<void>;
+  /** false when the user had no role */
+  delete(restaurantId: string, userId: string): Promise<boolean>;
+  listByRestaurant(restaurantId: string): Promise<StaffMember[]>;
+  listByUser(userId: string): Promise<StaffMember[]>;
+}
+
+export interface Store {
+  carts: CartStore;
+  addresses: AddressStore;
+  favorites: FavoriteStore;
+  roles: RoleStore;
+}
+
+// Carts expire after a day without changes
+const CART_TTL_SECONDS = 86400;
+
+function byCreatedAt<T extends { createdAt: string }>(records: T[]): T[] {
+  return records.sort((a, b) => a.createdAt.localeCompare(b.createdAt));
+}
+
+function byGrantedAt(members: StaffMember[]): StaffMember[] {
+  return members.sort((a, b) => a.grantedAt.localeCompare(b.grantedAt));
+}
+
+// ============================================================
+// KV store (default)
+// ============================================================
+
+const CART_PREFIX = "cart:";
+const ADDRESS_PREFIX = "address:";
+const FAVORITE_PREFIX = "favorite:";
+// Staff keys predate the store: staff:<restaurantId>:<userId> plus a
+// per-user index staff-user:<userId>:<restaurantId>
+const STAFF_PREFIX = "staff:";
+const STAFF_USER_PREFIX = "staff-user:";
+
+function createKVStore(): Store {
+  const addressKey = (userId: string, addressId: string) =>
+    `${ADDRESS_PREFIX}${userId}:${addressId}`;
+  const favoritePrefix = (userId: string, kind?: FavoriteKind) =>
+    `${FAVORITE_PREFIX}${userId}:${kind ? `${kind}:` : ""}`;
+  const favoriteKey = (userId: string, kind: FavoriteKind, targetId: string) =>
+    `${favoritePrefix(userId, kind)}${targetId}`;
+  const staffKey = (restaurantId: string, userId: string) =>
+    `${STAFF_PREFIX}${restaurantId}:${userId}`;
+  const staffUserKey = (userId: string, restaurantId: string) =>
+    `${STAFF_USER_PREFIX}${userId}:${restaurantId}`;
+
+  return {
+    carts: {
+      get: (userId) => readJSON<CartState>(`${CART_PREFIX}${userId}`),
+      put: (userId, cart, ttlSeconds = CART_TTL_SECONDS) =>
+        writeJSON(`${CART_PREFIX}${userId}`, cart, { expirationTtl: ttlSeconds }),
+      delete: (userId) => deleteKey(`${CART_PREFIX}${userId}`),
+    },
+    addresses: {
+      list: async (userId) =>
+        byCreatedAt(await readAllJSON<SavedAddress>(`${ADDRESS_PREFIX}${userId}:`)),
+      get: (userId, addressId) => readJSON<SavedAddress>(addressKey(userId, addressId)),
+      put: (userId, address) => writeJSON(addressKey(userId, address.id), address),
+      delete: async (userId, addressId) => {
+        const key = addressKey(userId, addressId);
+        if (!(await readJSON<SavedAddress>(key))) {
+          return false;
+        }
+        await deleteKey(key);
+        return true;
+      },
+    },
+    favorites: {
+      list: async (userId, kind) =>
+        byCreatedAt(await readAllJSON<Favorite>(favoritePrefix(userId, kind))),
+      has: async (userId, kind, targetId) =>
+        (await readJSON<Favorite>(favoriteKey(userId, kind, targetId))) !== null,
+      add: (userId, favorite) =>
+        writeJSON(favoriteKey(userId, favorite.kind, favorite.targetId), favorite),
+      remove: async (userId, kind, targetId) => {
+        const key = favoriteKey(userId, kind, targetId);
+        if (!(await readJSON<Favorite>(key))) {
+          return false;
+        }
+        await deleteKey(key);
+        return true;
+      },
+    },
+    roles: {
+      get: (restaurantId, userId) => readJSON<StaffMember>(staffKey(restaurantId, userId)),
+      put: async (member) => {
+        await writeJSON(staffKey(member.restaurantId, member.telegramUserId), member);
+        await writeJSON(staffUserKey(member.telegramUserId, member.restaurantId), member);
+      },
+      delete: async (restaurantId, userId) => {
+        if (!(await readJSON<StaffMember>(staffKey(restaurantId, userId)))) {
+          return false;
+        }
+        await deleteKey(staffKey(restaurantId, userId));
+        await deleteKey(staffUserKey(userId, restaurantId));
+        return true;
+      },
+      listByRestaurant: async (restaurantId) =>
+        byGrantedAt(await readAllJSON<StaffMember>(`${STAFF_PREFIX}${restaurantId}:`)),
+      listByUser: async (userId) =>
+        byGrantedAt(await readAllJSON<StaffMember>(`${STAFF_USER_PREFIX}${userId}:`)),
+    },
+  };
+}
+
+// ============================================================
+// In-memory store (tests)
+// ============================================================
+
+/**
+ * Store holding everything in maps; records are copied in and out so
+ * callers can't mutate stored state
+ */
+export function createMemoryStore(): Store {
+  const copy = <T>(value: T): T => JSON.parse(JSON.stringify(value));
+  const carts = new Map<string, { cart: CartState; expiresAt: number }>();
+  const addresses = new Map<string, Map<string, SavedAddress>>();
+  const favorites = new Map<string, Map<string, Favorite>>();
+  const roles = new Map<string, StaffMember>();
+
+  const userMap = <T>(maps: Map<string, Map<string, T>>, userId: string) => {
+    if (!maps.has(userId)) {
+      maps.set(userId, new Map());
+    }
+    return maps.get(userId)!;
+  };
+  const favoriteId = (kind: FavoriteKind, targetId: string) => `${kind}:${targetId}`;
+  const roleId = (restaurantId: string, userId: string) => `${restaurantId}:${userId}`;
+
+  return {
+    carts: {
+      get: async (userId) => {
+        const entry = carts.get(userId);
+        if (!entry || entry.expiresAt <= Date.now()) {
+          carts.delete(userId);
+          return null;
+        }
+        return copy(entry.cart);
+      },
+      put: async (userId, cart, ttlSeconds = CART_TTL_SECONDS) => {
+        carts.set(userId, { cart: copy(cart), expiresAt: Date.now() + ttlSeconds * 1000 });
+      },
+      delete: async (userId) => {
+        carts.delete(userId);
+      },
+    },
+    addresses: {
+      list: async (userId) =>
+        byCreatedAt(Array.from(userMap(addresses, userId).values()).map(copy)),
+      get: async (userId, addressId) => {
+        const address = userMap(addresses, userId).get(addressId);
+        return address ? copy(address) : null;
+      },
+      put: async (userId, address) => {
+        userMap(addresses, userId).set(address.id, copy(address));
+      },
+      delete: async (userId, addressId) => userMap(addresses, userId).delete(addressId),
+    },
+    favorites: {
+      list: async (userId, kind) =>
+        byCreatedAt(
+          Array.from(userMap(favorites, userId).values())
+            .filter((favorite) => !kind || favorite.kind === kind)
+            .map(copy),
+        ),
+      has: async (userId, kind, targetId) =>
+        userMap(favorites, userId).has(favoriteId(kind, targetId)),
+      add: async (userId, favorite) => {
+        const id = favoriteId(favorite.kind, favorite.targetId);
+        userMap(favorites, userId).set(id, copy(favorite));
+      },
+      remove: async (userId, kind, targetId) =>
+        userMap(favorites, userId).delete(favoriteId(kind, targetId)),
+    },
+    roles: {
+      get: async (restaurantId, userId) => {
+        const member = roles.get(roleId(restaurantId, userId));
+        return member ? copy(member) : null;
+      },
+      put: async (member) => {
+        roles.set(roleId(member.restaurantId, member.telegramUserId), copy(member));
+      },
+      delete: async (restaurantId, userId) => roles.delete(roleId(restaurantId, userId)),
+      listByRestaurant: async (restaurantId) =>
+        byGrantedAt(
+          Array.from(roles.values())
+            .filter((member) => member.restaurantId === restaurantId)
+            .map(copy),
+        ),
+      listByUser: async (userId) =>
+        byGrantedAt(
+          Array.from(roles.values())
+            .filter((member) => member.telegramUserId === userId)
+            .map(copy),
+        ),
+    },
+  };
+}
+
+let activeStore: Store | null = null;
+
+/**
+ * Store used by features (the KV store unless replaced)
+ */
+export function getStore(): Store {
+  if (!activeStore) {
+    activeStore = createKVStore();
+  }
+  return activeStore;
+}
+
+/**
+ * Replace the store (tests, alternative backends); null restores the KV store
+ */
+export function setStore(store: Store | null): void {
+  activeStore = store;
+}
@@ -0,0 +1,96 @@
+// Tag Label Tests
+// Tests for tagLabels.ts - localized labels for tag and cuisine keys
+
+import { describe, it, expect, vi, afterEach } from "vitest";
+import { Restaurant } from "./contracts";
+import {
+  collectRestaurantTags,
+  filterRestaurantsByTags,
+  getRestaurantTags,
+  getTagLabel,
+  humanizeTag,
+  listTagLabels,
+  toTagLabels,
+} from "./tagLabels";
+
+// Mock the logger to avoid console output during tests
+vi.mock("./logger", () => ({
+  logger: {
+    info: vi.fn(),
+    error: vi.fn(),
+    warn: vi.fn(),
+    debug: vi.fn(),
+  },
+}));
+
+afterEach(() => {
+  delete (globalThis as any).TAG_LABELS;
+});
+
+describe("tag labels", () => {
+  it("should humanise unlabelled keys", () => {
+    expect(humanizeTag("gluten-free")).toBe("Gluten free");
+    expect(getTagLabel("vegan", "ru")).toBe("Vegan");
+  });
+
+  it("should prefer the user's language, then its base language, then English", () => {
+    (globalThis as any).TAG_LABELS = JSON.stringify({
+      Vegan: { en: "Vegan", ru: "Веган" },
+      halal: { en: "Halal", pt: "Halal (pt)", "pt-BR": "Halal (br)" },
+    });
+    expect(getTagLabel("vegan", "ru")).toBe("Веган");
+    expect(getTagLabel("halal", "pt-br")).toBe("Halal (br)");
+    expect(getTagLabel("halal", "pt-PT")).toBe("Halal (pt)");
+    expect(getTagLabel("halal", "de")).toBe("Halal");
+  });
+
+  it("should keep keys stable alongside labels", () => {
+    (globalThis as any).TAG_LABELS = JSON.stringify({ "gluten-free": { ru: "Без глютена" } });
+    expect(toTagLabels(["gluten-free", "nuts"], "ru")).toEqual([
+      { key: "gluten-free", label: "Без глютена" },
+      { key: "nuts", label: "Nuts" },
+    ]);
+    expect(listTagLabels("ru")).toEqual([{ key: "gluten-free", label: "Без глютена" }]);
+  });
+
+  it("should ignore invalid configuration", () => {
+    (globalThis as any).TAG_LABELS = "{not json";
+    expect(getTagLabel("vegan", "en")).toBe("Vegan");
+    expect(listTagLabels()).toEqual([]);
+  });
+});
+
+describe("restaurant tags", () => {
+  it("should normalise cuisine tags from channel metadata", () => {
+    expect(getRestaurantTags({ tma_tags: "Sushi, Japanese Food, sushi" })).toEqual([
+      "sushi",
+      "japanese-food",
+    ]);
+    expect(getRestaurantTags(undefined)).toEqual([]);
+  });
+});
+
+describe("restaurant tag filters", () => {
+  const restaurants = [
+    { id: "restA", name: "Pizza Place", tags: ["pizza", "italian"] },
+    { id: "restB", name: "Sushi Bar", tags: ["sushi"] },
+    { id: "restC", name: "Corner Cafe" },
+  ] as Restaurant[];
+
+  it("should keep restaurants sharing any requested tag", () => {
+    expect(filterRestaurantsByTags(restaurants, ["Italian", "sushi"]).map((r) => r.id)).toEqual([
+      "restA",
+      "restB",
+    ]);
+    expect(filterRestaurantsByTags(restaurants, [])).toHaveLength(3);
+    expect(filterRestaurantsByTags(restaurants, null)).toHaveLength(3);
+  });
+
+  it("should list distinct tags sorted by label", () => {
+    expect(collectRestaurantTags([...restaurants, restaurants[1]], "en")).toEqual([
+      { key: "italian", label: "Italian" },
+      { key: "pizza", label: "Pizza" },
+      { key: "sushi", label: "Sushi" },
+    ]);
+  });
+});
@@ -0,0 +1,138 @@
+// Tag Labels
+// Dietary tags, allergens and restaurant cuisine tags (tma_tags channel
+// metadata) are stable lowercase keys that filters match on. TAG_LABELS
+// maps keys to display labels per language,
+// {"vegan": {"en": "Vegan", "ru": "Веган"}, "gluten-free": {...}}, so
+// filter chips show in the Telegram user's language_code. Lookup tries the
+// full code ("pt-br"), its language ("pt"), then "en"; unlabelled keys are
+// shown humanised ("gluten-free" -> "Gluten free").
+
+import { Restaurant, TagLabel } from "./contracts";
+import { getVar } from "./config";
+import { normalizeTag } from "./dietaryTags";
+import { logger } from "./logger";
+import { parseListValue } from "./metadata";
+
+export const RESTAURANT_TAGS_METADATA_KEY = "tma_tags";
+
+const FALLBACK_LANGUAGE = "en";
+
+type LabelMap = Record<string, Record<string, string>>;
+
+let cachedSource: string | undefined;
+let cachedLabels: LabelMap = {};
+
+/**
+ * TAG_LABELS keyed by normalised tag and lowercase language
+ */
+function getLabelMap(): LabelMap {
+  const source = getVar("TAG_LABELS");
+  if (source === cachedSource) {
+    return cachedLabels;
+  }
+  cachedSource = source;
+  cachedLabels = {};
+  if (!source) {
+    return cachedLabels;
+  }
+  try {
+    const parsed = JSON.parse(source);
+    for (const [tag, labels] of Object.entries(parsed ?? {})) {
+      if (!labels || typeof labels !== "object") {
+        continue;
+      }
+      const byLanguage: Record<string, string> = {};
+      for (const [language, label] of Object.entries(labels as Record<string, unknown>)) {
+        if (typeof label === "string" && label.trim()) {
+          byLanguage[language.trim().toLowerCase().replace("_", "-")] = label.trim();
+        }
+      }
+      cachedLabels[normalizeTag(tag)] = byLanguage;
+    }
+  } catch {
+    logger.warn("tag_labels_invalid", { reason: "TAG_LABELS is not valid JSON" });
+  }
+  return cachedLabels;
+}
+
+/**
+ * Languages to try for a Telegram language_code, most specific first
+ */
+export function getLanguageChain(languageCode: string | undefined): string[] {
+  const code = (languageCode || "").trim().toLowerCase().replace("_", "-");
+  const chain = [code, code.split("-")[0], FALLBACK_LANGUAGE];
+  return Array.from(new Set(chain.filter(Boolean)));
+}
+
+/**
+ * "gluten-free" -> "Gluten free"
+ */
+export function humanizeTag(tag: string): string {
+  const words = tag.replace(/-+/g, " ").trim();
+  return words.charAt(0).toUpperCase() + words.slice(1);
+}
+
+/**
+ * Display label for a tag in the user's language
+ */
+export function getTagLabel(tag: string, languageCode?: string): string {
+  const key = normalizeTag(tag);
+  const labels = getLabelMap()[key] || {};
+  for (const language of getLanguageChain(languageCode)) {
+    if (labels[language]) {
+      return labels[language];
+    }
+  }
+  return humanizeTag(key);
+}
+
+/**
+ * Tags with their labels, keeping the given order
+ */
+export function toTagLabels(tags: string[] | undefined, languageCode?: string): TagLabel[] {
+  return (tags || []).map((tag) => ({ key: tag, label: getTagLabel(tag, languageCode) }));
+}
+
+/**
+ * Every tag with a configured label, sorted by label (filter chip lists)
+ */
+export function listTagLabels(languageCode?: string): TagLabel[] {
+  return toTagLabels(Object.keys(getLabelMap()), languageCode).sort((a, b) =>
+    a.label.localeCompare(b.label),
+  );
+}
+
+/**
+ * Restaurant cuisine tags from channel metadata, normalised like dietary tags
+ */
+export function getRestaurantTags(metadata: Record<string, string> | undefined): string[] {
+  const tags = parseListValue(metadata?.[RESTAURANT_TAGS_METADATA_KEY]).map(normalizeTag);
+  return Array.from(new Set(tags.filter(Boolean)));
+}
+
+/**
+ * Restaurants with at least one of the requested tags; no tags keeps all
+ */
+export function filterRestaurantsByTags(
+  restaurants: Restaurant[],
+  tags: string[] | null | undefined,
+): Restaurant[] {
+  const wanted = new Set((tags || []).map(normalizeTag).filter(Boolean));
+  if (wanted.size === 0) {
+    return restaurants;
+  }
+  return restaurants.filter((restaurant) => (restaurant.tags || []).some((tag) => wanted.has(tag)));
+}
+
+/**
+ * Distinct tags across restaurants with labels, sorted by label
+ */
+export function collectRestaurantTags(
+  restaurants: Restaurant[],
+  languageCode?: string,
+): TagLabel[] {
+  const tags = new Set(restaurants.flatMap((restaurant) => restaurant.tags || []));
+  return toTagLabels(Array.from(tags), languageCode).sort((a, b) =>
+    a.label.localeCompare(b.label),
+  );
+}
@@ -0,0 +1,133 @@
+// Tax Configuration Tests
+// Tests for taxes.ts - Saleor tax configuration, caching and menu price display
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { SaleorFetch, initializeSaleorClient } from "./saleorClient";
+import { fetchTaxConfiguration, resolveMenuPriceDisplay } from "./taxes";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+vi.mock("./saleorService", async (importOriginal) => ({
+  ...(await importOriginal<typeof import("./saleorService")>()),
+  fetchChannels: vi.fn(async () => [
+    {
+      id: "channelA",
+      slug: "restaurant-a",
+      name: "Restaurant A",
+      isActive: true,
+      currencyCode: "EUR",
+      categories: [],
+      defaultCountry: { code: "DE", country: "Germany" },
+    },
+  ]),
+}));
+
+// Channel charges taxes and shows net prices, except Germany shows gross
+let failConfiguration = false;
+const send = vi.fn<SaleorFetch>(async (_, init) => {
+  const { operationName } = JSON.parse(String(init?.body));
+  if (operationName === "TaxConfigurations") {
+    if (failConfiguration) {
+      return Response.json({ errors: [{ message: "Saleor is down" }] });
+    }
+    return Response.json({
+      data: {
+        taxConfigurations: {
+          edges: [
+            {
+              node: {
+                id: "tax-1",
+                chargeTaxes: true,
+                displayGrossPrices: false,
+                pricesEnteredWithTax: true,
+                countries: [
+                  { country: { code: "DE" }, chargeTaxes: true, displayGrossPrices: true },
+                ],
+              },
+            },
+          ],
+        },
+      },
+    });
+  }
+  return Response.json({
+    data: {
+      taxCountryConfiguration: {
+        taxClassCountryRates: [
+          { rate: 7, taxClass: { id: "food" } },
+          { rate: 19, taxClass: null },
+        ],
+      },
+    },
+  });
+});
+
+beforeEach(() => {
+  failConfiguration = false;
+  send.mockClear();
+  (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+  initializeSaleorClient({ SALEOR_API_URL: "https://saleor.test/graphql/", SALEOR_TOKEN: "t" });
+});
+
+afterEach(() => {
+  initializeSaleorClient({});
+  delete (globalThis as any).SALEOR_TRANSPORT;
+  delete (globalThis as any).MENU_PRICE_DISPLAY;
+});
+
+describe("fetchTaxConfiguration", () => {
+  it("should apply the country override and its default rate", async () => {
+    expect(await fetchTaxConfiguration("channelA")).toEqual({
+      restaurantId: "channelA",
+      chargeTaxes: true,
+      pricesEnteredWithTax: true,
+      displayGrossPrices: true,
+      countryCode: "DE",
+      taxRate: 19,
+      priceDisplay: "GROSS",
+    });
+  });
+
+  it("should serve repeat lookups from the cache", async () => {
+    await fetchTaxConfiguration("channelB");
+    const calls = send.mock.calls.length;
+    expect(calls).toBeGreaterThan(0);
+
+    await fetchTaxConfiguration("channelB");
+    expect(send.mock.calls.length).toBe(calls);
+  });
+
+  it("should fall back to defaults without caching them when Saleor fails", async () => {
+    failConfiguration = true;
+    expect(await fetchTaxConfiguration("channelC")).toMatchObject({
+      chargeTaxes: false,
+      taxRate: 0,
+      priceDisplay: "GROSS",
+    });
+
+    failConfiguration = false;
+    expect(await fetchTaxConfiguration("channelC")).toMatchObject({
+      chargeTaxes: true,
+      displayGrossPrices: false,
+      priceDisplay: "NET",
+    });
+  });
+});
+
+describe("resolveMenuPriceDisplay", () => {
+  it("should follow Saleor in auto mode", async () => {
+    expect(await resolveMenuPriceDisplay("channelA")).toBe("GROSS");
+    expect(await resolveMenuPriceDisplay("channelD")).toBe("NET");
+  });
+
+  it("should not query Saleor when the display is forced", async () => {
+    (globalThis as any).MENU_PRICE_DISPLAY = "net";
+    expect(await resolveMenuPriceDisplay("channelE")).toBe("NET");
+    (globalThis as any).MENU_PRICE_DISPLAY = "gross";
+    expect(await resolveMenuPriceDisplay("channelE")).toBe("GROSS");
+    expect(send).not.toHaveBeenCalled();
+  });
+});
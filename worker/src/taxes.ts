@@ -0,0 +1,180 @@
+// Tax Configuration Passthrough
+// Surfaces Saleor tax configuration (prices entered with/without tax,
+// default tax rate) per restaurant channel and decides whether menus
+// display gross or net prices. Saleor's answers are cached in KV per
+// restaurant (and Saleor target) so menu requests don't re-query them.
+
+import { TaxConfiguration, PriceDisplay } from "./contracts";
+import { getStringVar } from "./config";
+import { logger } from "./logger";
+import { SaleorClient, getSaleorClient, isSaleorConfigured } from "./saleorClient";
+import {
+  SaleorCountryCode,
+  TAX_CONFIGURATIONS_QUERY,
+  TAX_COUNTRY_CONFIGURATION_QUERY,
+  TaxConfigurationsQuery,
+  TaxConfigurationsQueryVariables,
+  TaxCountryConfigurationQuery,
+  TaxCountryConfigurationQueryVariables,
+} from "./saleorOperations";
+import { fetchChannels } from "./saleorService";
+import { getSaleorTarget } from "./saleorTargets";
+import { readJSON, writeJSON } from "./storage";
+
+const CACHE_PREFIX = "tax-config:";
+const CACHE_TTL_SECONDS = 10 * 60;
+
+/**
+ * Menu price display mode from MENU_PRICE_DISPLAY (auto | gross | net)
+ * "auto" follows Saleor's displayGrossPrices for the channel/country
+ */
+export function getMenuPriceDisplaySetting(): "auto" | "gross" | "net" {
+  const value = getStringVar("MENU_PRICE_DISPLAY", "auto").toLowerCase();
+  return value === "gross" || value === "net" ? value : "auto";
+}
+
+function resolvePriceDisplay(displayGrossPrices: boolean): PriceDisplay {
+  const setting = getMenuPriceDisplaySetting();
+  if (setting === "gross") {
+    return "GROSS";
+  }
+  if (setting === "net") {
+    return "NET";
+  }
+  return displayGrossPrices ? "GROSS" : "NET";
+}
+
+function getDefaultTaxConfiguration(restaurantId: string): TaxConfiguration {
+  return {
+    restaurantId,
+    chargeTaxes: false,
+    pricesEnteredWithTax: true,
+    displayGrossPrices: true,
+    countryCode: null,
+    taxRate: 0,
+    priceDisplay: resolvePriceDisplay(true),
+  };
+}
+
+/**
+ * Fetch the tax configuration for a restaurant channel
+ * Falls back to "no taxes, gross prices" when Saleor is unavailable
+ */
+export async function fetchTaxConfiguration(
+  restaurantId: string,
+): Promise<TaxConfiguration> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    return getDefaultTaxConfiguration(restaurantId);
+  }
+
+  // priceDisplay depends on MENU_PRICE_DISPLAY, so it's resolved per read
+  const cacheKey = `${CACHE_PREFIX}${getSaleorTarget()}:${restaurantId}`;
+  const cached = await readJSON<TaxConfiguration>(cacheKey);
+  if (cached) {
+    return { ...cached, priceDisplay: resolvePriceDisplay(cached.displayGrossPrices) };
+  }
+
+  const config = await loadTaxConfiguration(client, restaurantId);
+  if (!config) {
+    return getDefaultTaxConfiguration(restaurantId);
+  }
+  await writeJSON(cacheKey, config, { expirationTtl: CACHE_TTL_SECONDS });
+  return config;
+}
+
+// Queries Saleor; null when the configuration couldn't be read
+async function loadTaxConfiguration(
+  client: SaleorClient,
+  restaurantId: string,
+): Promise<TaxConfiguration | null> {
+  try {
+    const variables: TaxConfigurationsQueryVariables = { channelIds: [restaurantId] };
+    const [configResponse, channels] = await Promise.all([
+      client.execute<TaxConfigurationsQuery>(TAX_CONFIGURATIONS_QUERY, variables),
+      fetchChannels(),
+    ]);
+
+    if (configResponse.errors && configResponse.errors.length > 0) {
+      logger.error("saleor_tax_configuration_error", {
+        error: configResponse.errors.map((e) => e.message).join(", "),
+        restaurantId,
+      });
+      return null;
+    }
+
+    const node = configResponse.data?.taxConfigurations?.edges?.[0]?.node;
+    if (!node) {
+      return getDefaultTaxConfiguration(restaurantId);
+    }
+
+    const countryCode =
+      channels.find((ch) => ch.id === restaurantId)?.defaultCountry?.code ||
+      null;
+
+    // Country-level exceptions override channel defaults
+    const countryOverride = countryCode
+      ? node.countries?.find((c) => c.country?.code === countryCode)
+      : undefined;
+    const chargeTaxes = countryOverride?.chargeTaxes ?? node.chargeTaxes;
+    const displayGrossPrices =
+      countryOverride?.displayGrossPrices ?? node.displayGrossPrices;
+
+    let taxRate = 0;
+    if (chargeTaxes && countryCode) {
+      const rates: TaxCountryConfigurationQueryVariables = {
+        countryCode: countryCode as SaleorCountryCode,
+      };
+      const rateResponse = await client.execute<TaxCountryConfigurationQuery>(
+        TAX_COUNTRY_CONFIGURATION_QUERY,
+        rates,
+      );
+
+      if (rateResponse.errors && rateResponse.errors.length > 0) {
+        logger.error("saleor_tax_configuration_error", {
+          error: rateResponse.errors.map((e) => e.message).join(", "),
+          restaurantId,
+        });
+        return null;
+      }
+
+      // The default country rate is the entry without a tax class
+      const defaultRate =
+        rateResponse.data?.taxCountryConfiguration?.taxClassCountryRates?.find(
+          (r) => !r.taxClass,
+        );
+      taxRate = defaultRate?.rate ?? 0;
+    }
+
+    return {
+      restaurantId,
+      chargeTaxes,
+      pricesEnteredWithTax: node.pricesEnteredWithTax,
+      displayGrossPrices,
+      countryCode,
+      taxRate,
+      priceDisplay: resolvePriceDisplay(displayGrossPrices),
+    };
+  } catch (error) {
+    logger.error("saleor_tax_configuration_error", {
+      error: error instanceof Error ? error.message : "Unknown error",
+      restaurantId,
+    });
+    return null;
+  }
+}
+
+/**
+ * Price display mode for a restaurant's menu
+ * Only queries Saleor when MENU_PRICE_DISPLAY is "auto"
+ */
+export async function resolveMenuPriceDisplay(
+  restaurantId?: string,
+): Promise<PriceDisplay> {
+  const setting = getMenuPriceDisplaySetting();
+  if (setting !== "auto" || !restaurantId) {
+    return resolvePriceDisplay(true);
+  }
+  const config = await fetchTaxConfiguration(restaurantId);
+  return config.priceDisplay;
+}
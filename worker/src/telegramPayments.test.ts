@@ -0,0 +1,122 @@
+// Telegram Payments Tests
+// Tests for telegramPayments.ts - the bot webhook's payment updates
+
+import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
+import { clearOrders, createSaleorOrder, getOrder } from "./saleorOrder";
+import { startPaymentDeadline } from "./orderState";
+import { readJSON } from "./storage";
+import { handleTelegramWebhook } from "./telegramPayments";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn(), authFailure: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const SECRET = "webhook-secret";
+
+function post(update: unknown, secret = SECRET): Promise<Response> {
+  return handleTelegramWebhook(
+    new Request("https://worker.test/telegram/webhook", {
+      method: "POST",
+      headers: { "X-Telegram-Bot-Api-Secret-Token": secret },
+      body: JSON.stringify(update),
+    }),
+  );
+}
+
+async function createOrder(userId: string) {
+  const result = await createSaleorOrder(
+    {
+      restaurantId: "restA",
+      deliveryLocation: { address: "1 Test Street" },
+      items: [{ dishId: "dish1", quantity: 1 }],
+    },
+    userId,
+  );
+  return result.order!;
+}
+
+function paymentUpdate(orderId: string, chargeId = "charge-1") {
+  return {
+    update_id: 1,
+    message: {
+      successful_payment: {
+        invoice_payload: `order:${orderId}`,
+        telegram_payment_charge_id: chargeId,
+        currency: "USD",
+        total_amount: 1000,
+      },
+    },
+  };
+}
+
+let botApi: ReturnType<typeof vi.fn>;
+
+beforeEach(() => {
+  clearOrders();
+  (globalThis as any).TELEGRAM_WEBHOOK_SECRET = SECRET;
+  (globalThis as any).TELEGRAM_BOT_TOKEN = "bot-token";
+  (globalThis as any).TELEGRAM_PAYMENT_PROVIDER_TOKEN = "provider-token";
+  botApi = vi.fn(async () => Response.json({ ok: true, result: true }));
+  vi.stubGlobal("fetch", botApi);
+});
+
+afterEach(() => {
+  vi.unstubAllGlobals();
+  delete (globalThis as any).TELEGRAM_WEBHOOK_SECRET;
+  delete (globalThis as any).TELEGRAM_BOT_TOKEN;
+  delete (globalThis as any).TELEGRAM_PAYMENT_PROVIDER_TOKEN;
+});
+
+describe("handleTelegramWebhook", () => {
+  it("should refuse updates without the webhook secret", async () => {
+    expect((await post(paymentUpdate("o1"), "wrong")).status).toBe(403);
+  });
+
+  it("should answer pre-checkout queries for the order's owner and total", async () => {
+    const order = await createOrder("42");
+    const query = {
+      id: "q1",
+      from: { id: 42 },
+      invoice_payload: `order:${order.id}`,
+      currency: "USD",
+      total_amount: 1000,
+    };
+
+    expect((await post({ pre_checkout_query: query })).status).toBe(200);
+    expect((await post({ pre_checkout_query: { ...query, total_amount: 900 } })).status).toBe(
+      200,
+    );
+
+    const answers = botApi.mock.calls.map((call: any[]) => JSON.parse(call[1].body));
+    expect(answers[0]).toEqual({ pre_checkout_query_id: "q1", ok: true });
+    expect(answers[1]).toMatchObject({ ok: false });
+  });
+
+  it("should record the charge and mark the order paid once", async () => {
+    const order = await createOrder("42");
+    await startPaymentDeadline(order, "42", "restA");
+
+    expect((await post(paymentUpdate(order.id))).status).toBe(200);
+    expect(getOrder(order.id)?.metadata).toMatchObject({
+      "tma.state": "PAID",
+      "tma.paymentReference": "charge-1",
+    });
+    expect(await readJSON(`payment-deadline:${order.id}`)).toBeNull();
+    const charge = await readJSON<any>("telegram-charge:charge-1");
+    expect(charge).toMatchObject({ orderId: order.id, amount: 1000 });
+    expect(charge.markedPaidAt).toBeDefined();
+
+    // Redelivery is a no-op
+    expect((await post(paymentUpdate(order.id))).status).toBe(200);
+  });
+
+  it("should keep the charge and answer 500 when Saleor can't record it", async () => {
+    const response = await post(paymentUpdate("missing-order", "charge-2"));
+
+    expect(response.status).toBe(500);
+    const charge = await readJSON<any>("telegram-charge:charge-2");
+    expect(charge).toMatchObject({ orderId: "missing-order", currency: "USD" });
+    expect(charge.markedPaidAt).toBeUndefined();
+  });
+});
@@ -0,0 +1,319 @@
+// Telegram Payments
+// createInvoice issues a Bot API invoice link for an order, paid either
+// through a payment provider (TELEGRAM_PAYMENT_PROVIDER_TOKEN, order
+// currency) or in Telegram Stars (TELEGRAM_STARS_RATE stars per unit).
+// The bot webhook answers pre_checkout_query and, on successful_payment,
+// records the charge in KV and stops the payment deadline before marking
+// the Saleor order as paid via orderMarkAsPaid; when Saleor fails the
+// webhook answers 500 so Telegram redelivers the update. Payloads of
+// orders placed on the secondary Saleor name it (order:secondary:<id>),
+// so the webhook handles them against that target.
+
+import { CreateInvoicePayload } from "./contracts";
+import { getBooleanVar, getNumberVar, getVar } from "./config";
+import { badUserInputError, notFoundError } from "./errors";
+import { logger } from "./logger";
+import { toMinorUnits } from "./money";
+import { clearPaymentDeadline, markOrderPaid } from "./orderState";
+import { readJSON, writeJSON } from "./storage";
+import {
+  SaleorOrder,
+  ORDER_METADATA_KEYS,
+  fetchOrderById,
+  fetchUserOrder,
+  getNormalizedStatus,
+  isAwaitingAbuseReview,
+  markSaleorOrderPaid,
+} from "./saleorOrder";
+import { isTerminalOrderStatus } from "./orderStatus";
+import { SaleorTarget, getSaleorTarget, runWithSaleorTarget } from "./saleorTargets";
+
+const TELEGRAM_API_BASE = "https://api.telegram.org";
+export const TELEGRAM_WEBHOOK_PATH = "/telegram/webhook";
+const STARS_CURRENCY = "XTR";
+// Invoice payloads are prefixed so unrelated invoices are ignored
+const PAYLOAD_PREFIX = "order:";
+const SECONDARY_PAYLOAD_PREFIX = "order:secondary:";
+const CHARGE_PREFIX = "telegram-charge:";
+
+interface InvoicePrice {
+  currency: string;
+  amount: number; // smallest units (Stars are whole)
+}
+
+/**
+ * A successful Telegram payment, kept until (and after) Saleor records it
+ */
+interface TelegramChargeRecord {
+  orderId: string;
+  chargeId: string;
+  currency: string;
+  amount: number;
+  receivedAt: string;
+  markedPaidAt?: string;
+}
+
+/**
+ * Call a Bot API method, returning its result or throwing on failure
+ */
+async function callBotApi<T>(method: string, params: unknown): Promise<T> {
+  const token = getVar("TELEGRAM_BOT_TOKEN");
+  if (!token) {
+    throw new Error("TELEGRAM_BOT_TOKEN is not configured");
+  }
+  const response = await fetch(`${TELEGRAM_API_BASE}/bot${token}/${method}`, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(params),
+  });
+  const body: any = await response.json().catch(() => null);
+  if (!response.ok || !body?.ok) {
+    throw new Error(body?.description || `Bot API ${method} failed (${response.status})`);
+  }
+  return body.result as T;
+}
+
+/**
+ * Whether any payment method is configured (and not switched off with
+ * PAYMENTS_DISABLED)
+ */
+export function isTelegramPaymentsEnabled(): boolean {
+  if (getBooleanVar("PAYMENTS_DISABLED")) {
+    return false;
+  }
+  return Boolean(
+    getVar("TELEGRAM_PAYMENT_PROVIDER_TOKEN") ||
+      getNumberVar("TELEGRAM_STARS_RATE", 0) > 0,
+  );
+}
+
+/**
+ * Invoice amount for an order: provider payments use the order currency,
+ * Stars payments convert with TELEGRAM_STARS_RATE (rounded up)
+ */
+export function getInvoicePrice(order: SaleorOrder): InvoicePrice {
+  const { amount, currency } = order.total.gross;
+  if (getVar("TELEGRAM_PAYMENT_PROVIDER_TOKEN")) {
+    return { currency, amount: toMinorUnits(amount, currency) };
+  }
+  const rate = getNumberVar("TELEGRAM_STARS_RATE", 0);
+  // Trim float noise before rounding up (12.3 * 100 = 1230.0000000000002)
+  const stars = Math.ceil(Number((amount * rate).toFixed(6)));
+  return { currency: STARS_CURRENCY, amount: Math.max(1, stars) };
+}
+
+function isOrderPaid(order: SaleorOrder): boolean {
+  return order.metadata?.[ORDER_METADATA_KEYS.state] === "PAID";
+}
+
+interface InvoicePayload {
+  orderId: string;
+  target: SaleorTarget;
+}
+
+function buildPayload(orderId: string): string {
+  const prefix = getSaleorTarget() === "secondary" ? SECONDARY_PAYLOAD_PREFIX : PAYLOAD_PREFIX;
+  return `${prefix}${orderId}`;
+}
+
+function parsePayload(payload: string | undefined): InvoicePayload | null {
+  if (payload?.startsWith(SECONDARY_PAYLOAD_PREFIX)) {
+    return { orderId: payload.substring(SECONDARY_PAYLOAD_PREFIX.length), target: "secondary" };
+  }
+  return payload?.startsWith(PAYLOAD_PREFIX)
+    ? { orderId: payload.substring(PAYLOAD_PREFIX.length), target: "primary" }
+    : null;
+}
+
+/**
+ * Create an invoice link for one of the user's unpaid orders
+ */
+export async function createInvoice(
+  orderId: string,
+  userId: string,
+): Promise<CreateInvoicePayload> {
+  if (!isTelegramPaymentsEnabled()) {
+    throw badUserInputError("Payments are not configured");
+  }
+  const order = await fetchUserOrder(orderId, userId);
+  if (!order) {
+    throw notFoundError("Order not found");
+  }
+  if (isOrderPaid(order)) {
+    throw badUserInputError("Order is already paid", "orderId");
+  }
+  if (isTerminalOrderStatus(getNormalizedStatus(order))) {
+    throw badUserInputError("Order can no longer be paid", "orderId");
+  }
+  if (isAwaitingAbuseReview(order)) {
+    throw badUserInputError("Order is awaiting review", "orderId");
+  }
+
+  const price = getInvoicePrice(order);
+  const label = order.number ? `Order #${order.number}` : "Order";
+  const invoiceUrl = await callBotApi<string>("createInvoiceLink", {
+    title: label,
+    description: order.lines
+      .map((line) => `${line.quantity} × ${line.productName}`)
+      .join(", ")
+      .substring(0, 255) || label,
+    payload: buildPayload(order.id),
+    provider_token: getVar("TELEGRAM_PAYMENT_PROVIDER_TOKEN") || "",
+    currency: price.currency,
+    prices: [{ label, amount: price.amount }],
+  });
+
+  logger.info("invoice_created", {
+    orderId: order.id,
+    currency: price.currency,
+    amount: price.amount,
+  });
+
+  return {
+    orderId: order.id,
+    invoiceUrl,
+    currency: price.currency,
+    amount: price.amount,
+  };
+}
+
+/**
+ * Decide whether a pre-checkout query may proceed
+ * Returns an error message for the user, or null to accept
+ */
+async function checkPreCheckout(query: any): Promise<string | null> {
+  const orderId = parsePayload(query?.invoice_payload)?.orderId;
+  if (!orderId) {
+    return "Unknown invoice";
+  }
+  const order = await fetchOrderById(orderId);
+  if (
+    !order ||
+    order.metadata?.[ORDER_METADATA_KEYS.telegramUserId] !== String(query?.from?.id)
+  ) {
+    return "Order not found";
+  }
+  if (isOrderPaid(order)) {
+    return "This order is already paid";
+  }
+  if (isTerminalOrderStatus(getNormalizedStatus(order))) {
+    return "This order can no longer be paid";
+  }
+  if (isAwaitingAbuseReview(order)) {
+    return "This order is awaiting review";
+  }
+  const price = getInvoicePrice(order);
+  if (query.currency !== price.currency || query.total_amount !== price.amount) {
+    return "The order total has changed, please reopen the invoice";
+  }
+  return null;
+}
+
+async function handlePreCheckoutQuery(query: any): Promise<void> {
+  const error = await checkPreCheckout(query);
+  await callBotApi("answerPreCheckoutQuery", {
+    pre_checkout_query_id: query.id,
+    ok: error === null,
+    ...(error ? { error_message: error } : {}),
+  });
+  logger.info("pre_checkout_answered", {
+    accepted: error === null,
+    reason: error || undefined,
+  });
+}
+
+/**
+ * Record a successful payment and mark the order as paid
+ * Throws when Saleor can't record it, so the update is redelivered
+ */
+async function handleSuccessfulPayment(payment: any): Promise<void> {
+  const orderId = parsePayload(payment?.invoice_payload)?.orderId;
+  if (!orderId) {
+    return;
+  }
+  const reference = String(payment.telegram_payment_charge_id || "");
+  const key = `${CHARGE_PREFIX}${reference || orderId}`;
+  const existing = await readJSON<TelegramChargeRecord>(key);
+  if (existing?.markedPaidAt) {
+    return; // redelivered after Saleor already recorded it
+  }
+
+  // The charge is durable and the order can't expire before Saleor is asked
+  const charge: TelegramChargeRecord = existing ?? {
+    orderId,
+    chargeId: reference,
+    currency: String(payment.currency || ""),
+    amount: Number(payment.total_amount) || 0,
+    receivedAt: new Date().toISOString(),
+  };
+  await writeJSON(key, charge);
+  await clearPaymentDeadline(orderId);
+
+  const order = await fetchOrderById(orderId);
+  if (order && isTerminalOrderStatus(getNormalizedStatus(order))) {
+    // Nothing left to mark paid; the recorded charge needs a manual refund
+    logger.error("payment_for_closed_order", { orderId, reference });
+    return;
+  }
+
+  const paid = await markSaleorOrderPaid(orderId, reference);
+  if (!paid) {
+    logger.error("payment_mark_paid_failed", { orderId, reference });
+    throw new Error("Could not mark the order as paid");
+  }
+  await markOrderPaid(orderId);
+  await writeJSON(key, { ...charge, markedPaidAt: new Date().toISOString() });
+  logger.info("payment_received", {
+    orderId,
+    currency: payment.currency,
+    amount: payment.total_amount,
+  });
+}
+
+/**
+ * Bot webhook endpoint (POST /telegram/webhook)
+ * Authenticated with TELEGRAM_WEBHOOK_SECRET instead of initData
+ */
+export async function handleTelegramWebhook(request: Request): Promise<Response> {
+  const secret = getVar("TELEGRAM_WEBHOOK_SECRET");
+  if (
+    !secret ||
+    request.headers.get("X-Telegram-Bot-Api-Secret-Token") !== secret
+  ) {
+    logger.authFailure("invalid_webhook_secret");
+    return new Response("Forbidden", { status: 403 });
+  }
+
+  let update: any;
+  try {
+    update = await request.json();
+  } catch {
+    return new Response("Bad Request", { status: 400 });
+  }
+
+  const isPayment = Boolean(update?.message?.successful_payment);
+  // The invoice payload names the Saleor the order lives in
+  const invoice = update?.pre_checkout_query ?? update?.message?.successful_payment;
+  const target = parsePayload(invoice?.invoice_payload)?.target ?? "primary";
+  try {
+    await runWithSaleorTarget(target, async () => {
+      if (update?.pre_checkout_query) {
+        await handlePreCheckoutQuery(update.pre_checkout_query);
+      } else if (isPayment) {
+        await handleSuccessfulPayment(update.message.successful_payment);
+      }
+    });
+  } catch (error) {
+    logger.error("telegram_webhook_error", {
+      error: error instanceof Error ? error.message : "Unknown error",
+      updateId: update?.update_id,
+    });
+    // A payment Saleor hasn't recorded must be redelivered; a pre-checkout
+    // answer is too late to retry
+    if (isPayment) {
+      return new Response("Internal Server Error", { status: 500 });
+    }
+  }
+
+  return new Response("OK", { status: 200 });
+}
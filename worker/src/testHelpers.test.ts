@@ -0,0 +1,33 @@
+// Test Helper Tests
+// Tests for testHelpers.ts - fixtures stay valid as the types grow
+
+import { describe, it, expect } from "vitest";
+import { createHmac } from "node:crypto";
+import { validateInitData } from "./auth";
+import { getNormalizedStatus } from "./saleorOrder";
+import { buildSaleorOrder, buildSignedInitData } from "./testHelpers";
+
+describe("buildSignedInitData", () => {
+  it("should sign init data the way Telegram does", async () => {
+    const initData = await buildSignedInitData("123:bot-token", { id: "42", first_name: "Ana" });
+    const params = new URLSearchParams(initData);
+    const dataCheckString = [...params.entries()]
+      .filter(([key]) => key !== "hash")
+      .sort(([a], [b]) => a.localeCompare(b))
+      .map(([key, value]) => `${key}=${value}`)
+      .join("\n");
+    const secret = createHmac("sha256", "WebAppData").update("123:bot-token").digest();
+    const expected = createHmac("sha256", secret).update(dataCheckString).digest("hex");
+
+    expect(params.get("hash")).toBe(expected);
+    expect(validateInitData(initData)).toMatchObject({ valid: true, userId: "42", name: "Ana" });
+  });
+});
+
+describe("buildSaleorOrder", () => {
+  it("should keep the default owner when metadata is overridden", () => {
+    const order = buildSaleorOrder({ status: "CANCELED", metadata: { "tma.state": "PAID" } });
+    expect(order.metadata).toEqual({ "tma.telegramUserId": "123456789", "tma.state": "PAID" });
+    expect(getNormalizedStatus(order)).toBe("CANCELLED");
+  });
+});
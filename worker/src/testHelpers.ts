@@ -11,7 +11,9 @@ import {
   Cart,
   PlaceOrderInput,
   AuthContext,
+  SavedAddress,
 } from "./contracts";
+import { SaleorOrder } from "./saleorOrder";
 
 export const TEST_CHANNELS = {
   CH_A: {
@@ -168,6 +170,60 @@ export function buildPlaceOrderInput(
   return { ...defaultInput, ...overrides };
 }
 
+/**
+ * Build a Channel object for testing (a restaurant's Saleor channel)
+ */
+export function buildChannel(overrides: Partial<Channel> = {}): Channel {
+  return {
+    ...TEST_CHANNELS.CH_A,
+    metadata: {},
+    categories: [],
+    deliveryLocations: [],
+    ...overrides,
+  };
+}
+
+/**
+ * Build a SaleorOrder for testing: one line of DISH_A1, placed now
+ * Metadata overrides are merged with the defaults
+ */
+export function buildSaleorOrder(overrides: Partial<SaleorOrder> = {}): SaleorOrder {
+  const quantity = overrides.lines?.[0]?.quantity ?? 2;
+  const total = TEST_DISHES.DISH_A1.price * quantity;
+  return {
+    id: "order-1",
+    number: 1,
+    status: "UNFULFILLED",
+    channelId: TEST_CHANNELS.CH_A.id,
+    total: { gross: { amount: total, currency: "USD" } },
+    deliveryAddress: { address: "123 Test Street", city: "Test City", country: "US" },
+    lines: [
+      {
+        variantId: TEST_DISHES.DISH_A1.id,
+        quantity,
+        productName: TEST_DISHES.DISH_A1.name,
+        unitPrice: TEST_DISHES.DISH_A1.price,
+      },
+    ],
+    createdAt: new Date().toISOString(),
+    ...overrides,
+    metadata: { "tma.telegramUserId": "123456789", ...overrides.metadata },
+  };
+}
+
+/**
+ * Build a SavedAddress for testing
+ */
+export function buildSavedAddress(overrides: Partial<SavedAddress> = {}): SavedAddress {
+  return {
+    id: "home",
+    label: "Home",
+    location: { address: "123 Test Street", city: "Test City", country: "US" },
+    createdAt: "2026-01-01T00:00:00.000Z",
+    ...overrides,
+  };
+}
+
 // ============================================================
 // Auth Context Helpers
 // ============================================================
@@ -198,6 +254,58 @@ export const EXPIRED_INIT_DATA = [
   'user={"id":"123456789","first_name":"Test","last_name":"User"}',
 ].join("&");
 
+/**
+ * Telegram user in init data
+ */
+export interface TestTelegramUser {
+  id: string | number;
+  first_name?: string;
+  last_name?: string;
+  language_code?: string;
+}
+
+/**
+ * Build init data signed the way Telegram signs it: the hash is the
+ * HMAC-SHA256 of the sorted "key=value" lines, keyed with
+ * HMAC-SHA256("WebAppData", botToken)
+ */
+export async function buildSignedInitData(
+  botToken: string,
+  user: TestTelegramUser = { id: "123456789", first_name: "Test", language_code: "en" },
+  authDate: number = Math.floor(Date.now() / 1000),
+  extra: Record<string, string> = {},
+): Promise<string> {
+  const fields: Record<string, string> = {
+    auth_date: String(authDate),
+    user: JSON.stringify(user),
+    ...extra,
+  };
+  const dataCheckString = Object.keys(fields)
+    .sort()
+    .map((key) => `${key}=${fields[key]}`)
+    .join("\n");
+
+  const encoder = new TextEncoder();
+  const hmac = async (key: ArrayBuffer | Uint8Array, data: string) => {
+    const cryptoKey = await crypto.subtle.importKey(
+      "raw",
+      key,
+      { name: "HMAC", hash: "SHA-256" },
+      false,
+      ["sign"],
+    );
+    return crypto.subtle.sign("HMAC", cryptoKey, encoder.encode(data));
+  };
+  const secret = await hmac(encoder.encode("WebAppData"), botToken);
+  const hash = Array.from(new Uint8Array(await hmac(secret, dataCheckString)))
+    .map((byte) => byte.toString(16).padStart(2, "0"))
+    .join("");
+
+  const params = new URLSearchParams(fields);
+  params.set("hash", hash);
+  return params.toString();
+}
+
 /**
  * Build a valid AuthContext for testing
  */
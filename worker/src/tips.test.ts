@@ -0,0 +1,39 @@
+// Tip Tests
+// Tests for tips.ts - validating tips and the order line charging them
+
+import { describe, it, expect, afterEach } from "vitest";
+import { buildTipLine, validateTipAmount } from "./tips";
+
+afterEach(() => {
+  delete (globalThis as any).TIP_VARIANT_ID;
+  delete (globalThis as any).MAX_TIP_AMOUNT;
+});
+
+describe("validateTipAmount", () => {
+  it("should round tips and ignore empty ones", () => {
+    (globalThis as any).TIP_VARIANT_ID = "tip-variant";
+    expect(validateTipAmount(2.345, "EUR")).toBe(2.35);
+    expect(validateTipAmount(0, "EUR")).toBeUndefined();
+    expect(validateTipAmount(null, "EUR")).toBeUndefined();
+  });
+
+  it("should reject negative and oversized tips", () => {
+    (globalThis as any).TIP_VARIANT_ID = "tip-variant";
+    (globalThis as any).MAX_TIP_AMOUNT = "10";
+    expect(() => validateTipAmount(-1, "EUR")).toThrow("must not be negative");
+    expect(() => validateTipAmount(11, "EUR")).toThrow("at most 10");
+  });
+
+  it("should refuse tips that couldn't be charged without a tip variant", () => {
+    expect(() => validateTipAmount(2, "EUR")).toThrow("Tips are not available");
+    expect(validateTipAmount(0, "EUR")).toBeUndefined();
+  });
+});
+
+describe("buildTipLine", () => {
+  it("should charge the tip on the tip variant", () => {
+    (globalThis as any).TIP_VARIANT_ID = "tip-variant";
+    expect(buildTipLine(3)).toEqual({ variantId: "tip-variant", quantity: 1, price: 3 });
+    expect(buildTipLine(undefined)).toBeNull();
+  });
+});
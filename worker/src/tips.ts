@@ -0,0 +1,59 @@
+// Order Tips
+// Optional tipAmount on placeOrder, capped by MAX_TIP_AMOUNT. Tips need
+// TIP_VARIANT_ID, a "Tip" product variant: the tip is added to the Saleor
+// order as a line with an overridden unit price, so it is charged with the
+// total, and recorded in order metadata (tma.tipAmount). Without the
+// variant, tips are refused rather than recorded and never charged.
+
+import { getNumberVar, getVar } from "./config";
+import { badUserInputError } from "./errors";
+import { roundMoney } from "./money";
+
+/**
+ * Largest accepted tip in the order currency (MAX_TIP_AMOUNT, default 50)
+ */
+export function getMaxTipAmount(): number {
+  const max = getNumberVar("MAX_TIP_AMOUNT", 50);
+  return max >= 0 ? max : 50;
+}
+
+/**
+ * Check a requested tip, returning it rounded to the currency's minor unit
+ */
+export function validateTipAmount(
+  tipAmount: number | undefined | null,
+  currency: string,
+): number | undefined {
+  if (tipAmount === undefined || tipAmount === null) {
+    return undefined;
+  }
+  if (!Number.isFinite(tipAmount) || tipAmount < 0) {
+    throw badUserInputError("Tip must not be negative", "tipAmount");
+  }
+  const max = getMaxTipAmount();
+  if (tipAmount > max) {
+    throw badUserInputError(`Tip must be at most ${max}`, "tipAmount");
+  }
+  const rounded = roundMoney(tipAmount, currency);
+  if (rounded <= 0) {
+    return undefined;
+  }
+  if (!getVar("TIP_VARIANT_ID")) {
+    throw badUserInputError("Tips are not available", "tipAmount");
+  }
+  return rounded;
+}
+
+/**
+ * Saleor order/checkout line carrying the tip, or null without a tip (or
+ * without TIP_VARIANT_ID, where validateTipAmount refuses tips)
+ */
+export function buildTipLine(
+  tipAmount: number | undefined,
+): { variantId: string; quantity: number; price: number } | null {
+  const variantId = getVar("TIP_VARIANT_ID");
+  if (!variantId || !tipAmount || tipAmount <= 0) {
+    return null;
+  }
+  return { variantId, quantity: 1, price: tipAmount };
+}
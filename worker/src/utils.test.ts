@@ -0,0 +1,44 @@
+// Utility Tests
+// Tests for utils.ts - ordered and bounded concurrency helpers
+
+import { describe, it, expect } from "vitest";
+import { allInOrder, mapConcurrent } from "./utils";
+
+const tick = () => new Promise((resolve) => setTimeout(resolve, 0));
+
+describe("allInOrder", () => {
+  it("should rethrow the first failure in argument order", async () => {
+    const slowFailure = tick().then(() => Promise.reject(new Error("first")));
+    await expect(allInOrder([slowFailure, Promise.reject(new Error("second"))])).rejects.toThrow(
+      "first",
+    );
+  });
+});
+
+describe("mapConcurrent", () => {
+  it("should keep item order and the concurrency limit", async () => {
+    let running = 0;
+    let peak = 0;
+    const results = await mapConcurrent([3, 1, 2, 5, 4], 2, async (item) => {
+      running++;
+      peak = Math.max(peak, running);
+      for (let i = 0; i < item; i++) await tick();
+      running--;
+      return item * 10;
+    });
+    expect(results).toEqual([30, 10, 20, 50, 40]);
+    expect(peak).toBe(2);
+  });
+
+  it("should start nothing after a failure", async () => {
+    const started: number[] = [];
+    const run = mapConcurrent([1, 2, 3, 4], 2, async (item) => {
+      started.push(item);
+      await tick();
+      if (item === 1) throw new Error("menu failed");
+      return item;
+    });
+    await expect(run).rejects.toThrow("menu failed");
+    expect(started).toEqual([1, 2]);
+  });
+});
@@ -5,3 +5,53 @@ export function jsonResponse(obj: any, status = 200) {
     headers: { "Content-Type": "application/json" },
   });
 }
+
+/**
+ * Like Promise.all, but waits for every promise and rethrows the first
+ * failure in argument order, so concurrent checks report the same error
+ * they would one after another
+ */
+export async function allInOrder<T extends readonly unknown[] | []>(
+  values: T,
+): Promise<{ -readonly [P in keyof T]: Awaited<T[P]> }> {
+  const settled = await Promise.allSettled(values);
+  for (const result of settled) {
+    if (result.status === "rejected") {
+      throw result.reason;
+    }
+  }
+  return settled.map((result) => (result as PromiseFulfilledResult<unknown>).value) as any;
+}
+
+/**
+ * Map items with at most `limit` calls running at once, results in item
+ * order. After a failure no further items start; the calls already running
+ * are waited for and the first failure is rethrown.
+ */
+export async function mapConcurrent<T, R>(
+  items: readonly T[],
+  limit: number,
+  fn: (item: T, index: number) => Promise<R>,
+): Promise<R[]> {
+  const results: R[] = new Array(items.length);
+  let next = 0;
+  let failure = null as { error: unknown } | null;
+
+  const worker = async () => {
+    while (!failure && next < items.length) {
+      const index = next++;
+      try {
+        results[index] = await fn(items[index], index);
+      } catch (error) {
+        failure ??= { error };
+      }
+    }
+  };
+  const workers = Math.max(1, Math.min(limit, items.length));
+  await Promise.all(Array.from({ length: workers }, worker));
+
+  if (failure) {
+    throw failure.error;
+  }
+  return results;
+}
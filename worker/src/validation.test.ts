@@ -0,0 +1,65 @@
+// Validation Tests
+// Tests for validation.ts - rules engine and mutation input rules
+
+import { describe, it, expect } from "vitest";
+import { AppError } from "./errors";
+import { MUTATION_INPUT_RULES } from "./mutationRules";
+import { validateArgs, withInputValidation } from "./validation";
+
+describe("validateArgs", () => {
+  it("should accept a valid placeOrder input", () => {
+    const args = {
+      input: {
+        restaurantId: "rest-a",
+        items: [{ dishId: "dish-1", quantity: 2 }],
+        deliveryLocation: { address: "1 Main St", latitude: 52.5, longitude: 13.4 },
+      },
+    };
+    expect(validateArgs(args, MUTATION_INPUT_RULES.placeOrder)).toEqual([]);
+  });
+
+  it("should report every invalid field with input-relative paths", () => {
+    const args = {
+      input: {
+        items: [
+          { dishId: "dish-1", quantity: 1 },
+          { dishId: "dish-2", quantity: 0 },
+        ],
+        deliveryLocation: { address: "1 Main St", latitude: 123 },
+      },
+    };
+    expect(validateArgs(args, MUTATION_INPUT_RULES.placeOrder)).toEqual([
+      { field: "restaurantId", message: "Restaurant is required" },
+      { field: "items[1].quantity", message: "Must be at least 1" },
+      { field: "deliveryLocation.latitude", message: "Must be at most 90" },
+    ]);
+  });
+
+  it("should check URL formats", () => {
+    const args = {
+      input: { orderId: "o-1", category: "LATE", photos: ["http://example.com/a.jpg"] },
+    };
+    expect(validateArgs(args, MUTATION_INPUT_RULES.reportOrderIssue)).toEqual([
+      { field: "photos[0]", message: "Must be a https URL" },
+    ]);
+  });
+});
+
+describe("withInputValidation", () => {
+  it("should reject invalid args before the resolver runs", async () => {
+    let called = false;
+    const wrapped = withInputValidation(
+      { abortBroadcast: async () => (called = true) },
+      MUTATION_INPUT_RULES,
+    );
+
+    const error = await wrapped.abortBroadcast(null, { broadcastId: "" }).catch((e: unknown) => e);
+    expect(error).toBeInstanceOf(AppError);
+    expect((error as AppError).toGraphQL()).toMatchObject({
+      code: "BAD_USER_INPUT",
+      field: "broadcastId",
+      fieldErrors: [{ field: "broadcastId", message: "Broadcast is required" }],
+    });
+    expect(called).toBe(false);
+  });
+});
@@ -0,0 +1,207 @@
+// Input Validation Rules Engine
+// Declarative rules keyed by field path ("input.items[].quantity"), checked
+// at the resolver boundary before any mutation runs. Every failing field is
+// reported in the structured BAD_USER_INPUT fieldErrors format; field paths
+// drop the leading "input." to match the existing single-field errors.
+
+import { FieldError, validationError } from "./errors";
+
+/**
+ * A rule returns an error message, or null when the value is valid
+ * Rules other than required() skip missing (null/undefined) values
+ */
+export type Rule = (value: unknown) => string | null;
+
+/**
+ * Rules per field path, relative to the resolver args
+ */
+export type RuleSet = Record<string, Rule[]>;
+
+function isMissing(value: unknown): boolean {
+  return value === undefined || value === null;
+}
+
+function optional(check: (value: unknown) => string | null): Rule {
+  return (value) => (isMissing(value) ? null : check(value));
+}
+
+export function required(message = "This field is required"): Rule {
+  return (value) =>
+    isMissing(value) || (typeof value === "string" && value.trim() === "")
+      ? message
+      : null;
+}
+
+export function string(options: { min?: number; max?: number; pattern?: RegExp } = {}): Rule {
+  return optional((value) => {
+    if (typeof value !== "string") {
+      return "Must be a string";
+    }
+    if (options.min !== undefined && value.trim().length < options.min) {
+      return `Must be at least ${options.min} characters`;
+    }
+    if (options.max !== undefined && value.length > options.max) {
+      return `Must be at most ${options.max} characters`;
+    }
+    if (options.pattern && !options.pattern.test(value)) {
+      return "Has an invalid format";
+    }
+    return null;
+  });
+}
+
+export function number(options: { min?: number; max?: number; integer?: boolean } = {}): Rule {
+  return optional((value) => {
+    if (typeof value !== "number" || !Number.isFinite(value)) {
+      return "Must be a number";
+    }
+    if (options.integer && !Number.isInteger(value)) {
+      return "Must be a whole number";
+    }
+    if (options.min !== undefined && value < options.min) {
+      return `Must be at least ${options.min}`;
+    }
+    if (options.max !== undefined && value > options.max) {
+      return `Must be at most ${options.max}`;
+    }
+    return null;
+  });
+}
+
+export function oneOf(values: readonly string[]): Rule {
+  return optional((value) =>
+    values.includes(value as string) ? null : `Must be one of ${values.join(", ")}`,
+  );
+}
+
+export function array(options: { min?: number; max?: number } = {}): Rule {
+  return optional((value) => {
+    if (!Array.isArray(value)) {
+      return "Must be a list";
+    }
+    if (options.min !== undefined && value.length < options.min) {
+      return `Must contain at least ${options.min} items`;
+    }
+    if (options.max !== undefined && value.length > options.max) {
+      return `Must contain at most ${options.max} items`;
+    }
+    return null;
+  });
+}
+
+/**
+ * Absolute URL; empty strings count as missing (clients send "" for "no image")
+ */
+export function url(protocols: readonly string[] = ["https:"]): Rule {
+  return optional((value) => {
+    if (value === "") {
+      return null;
+    }
+    try {
+      const parsed = new URL(String(value));
+      return protocols.includes(parsed.protocol)
+        ? null
+        : `Must be a ${protocols.map((p) => p.replace(":", "")).join(" or ")} URL`;
+    } catch {
+      return "Must be a valid URL";
+    }
+  });
+}
+
+export function latitude(): Rule {
+  return number({ min: -90, max: 90 });
+}
+
+export function longitude(): Rule {
+  return number({ min: -180, max: 180 });
+}
+
+export function isoDateTime(): Rule {
+  return optional((value) =>
+    typeof value === "string" && !isNaN(Date.parse(value))
+      ? null
+      : "Must be an ISO 8601 date-time",
+  );
+}
+
+/**
+ * Resolve a path to every concrete value it addresses
+ * "items[].quantity" yields one entry per item (items[0].quantity, ...)
+ */
+function collectValues(
+  target: unknown,
+  path: string,
+): Array<{ path: string; value: unknown }> {
+  let entries: Array<{ path: string; value: unknown }> = [{ path: "", value: target }];
+  for (const segment of path.split(".")) {
+    const isList = segment.endsWith("[]");
+    const key = isList ? segment.slice(0, -2) : segment;
+    const next: Array<{ path: string; value: unknown }> = [];
+    for (const entry of entries) {
+      const value =
+        entry.value !== null && typeof entry.value === "object"
+          ? (entry.value as Record<string, unknown>)[key]
+          : undefined;
+      const childPath = entry.path ? `${entry.path}.${key}` : key;
+      if (!isList) {
+        next.push({ path: childPath, value });
+      } else if (Array.isArray(value)) {
+        value.forEach((item, index) =>
+          next.push({ path: `${childPath}[${index}]`, value: item }),
+        );
+      }
+    }
+    entries = next;
+  }
+  return entries;
+}
+
+/**
+ * Check args against a rule set, reporting the first failure per field
+ */
+export function validateArgs(args: unknown, rules: RuleSet): FieldError[] {
+  const errors: FieldError[] = [];
+  for (const [path, fieldRules] of Object.entries(rules)) {
+    for (const { path: concretePath, value } of collectValues(args, path)) {
+      for (const rule of fieldRules) {
+        const message = rule(value);
+        if (message) {
+          errors.push({ field: concretePath.replace(/^input\./, ""), message });
+          break;
+        }
+      }
+    }
+  }
+  return errors;
+}
+
+/**
+ * Throw a BAD_USER_INPUT error listing every invalid field
+ */
+export function assertValidArgs(args: unknown, rules: RuleSet): void {
+  const errors = validateArgs(args, rules);
+  if (errors.length > 0) {
+    throw validationError(errors);
+  }
+}
+
+/**
+ * Wrap resolvers so each one with registered rules validates its args first
+ */
+export function withInputValidation<T extends Record<string, (...args: any[]) => any>>(
+  resolvers: T,
+  rulesByField: Record<string, RuleSet>,
+): T {
+  const wrapped: Record<string, (...args: any[]) => any> = { ...resolvers };
+  for (const [name, rules] of Object.entries(rulesByField)) {
+    const resolver = resolvers[name];
+    if (!resolver) {
+      continue;
+    }
+    wrapped[name] = async (parent: unknown, args: unknown, ...rest: unknown[]) => {
+      assertValidArgs(args ?? {}, rules);
+      return resolver(parent, args, ...rest);
+    };
+  }
+  return wrapped as T;
+}
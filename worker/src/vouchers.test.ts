@@ -0,0 +1,95 @@
+// Compensation Voucher Tests
+// Tests for vouchers.ts - issuing single-use Saleor vouchers for orders
+
+import { describe, it, expect, vi, afterEach, beforeEach } from "vitest";
+import { SaleorFetch, initializeSaleorClient } from "./saleorClient";
+import { clearOrders, createSaleorOrder } from "./saleorOrder";
+import { generateVoucherCode, getOrderVoucher, issueCompensationVoucher } from "./vouchers";
+
+vi.mock("./logger", () => ({
+  logger: { info: vi.fn(), warn: vi.fn(), error: vi.fn(), debug: vi.fn() },
+  isDebugModeEnabled: () => false,
+}));
+
+const API_URL = "https://saleor.test/graphql/";
+const ORDER_ID = "T3JkZXI6MQ==";
+
+const ORDER = {
+  id: ORDER_ID,
+  number: "7",
+  status: "UNFULFILLED",
+  channel: { id: "ch-1" },
+  total: { gross: { amount: 20, currency: "EUR" } },
+  lines: [],
+  metadata: [{ key: "tma.telegramUserId", value: "user-1" }],
+  created: "2026-10-10T12:00:00.000Z",
+};
+
+// Answers each Saleor operation by name; voucherCreate as given
+function saleor(voucherCreate: unknown) {
+  const send = vi.fn<SaleorFetch>(async (_, init) => {
+    const { operationName } = JSON.parse(String(init?.body));
+    const data: Record<string, unknown> = {
+      Order: { order: ORDER },
+      VoucherCreate: { voucherCreate },
+      VoucherChannelListingUpdate: { voucherChannelListingUpdate: { errors: [] } },
+      UpdateMetadata: { updateMetadata: { errors: [] } },
+    };
+    return Response.json({ data: data[operationName] ?? {} });
+  });
+  (globalThis as any).SALEOR_TRANSPORT = { fetch: send };
+  initializeSaleorClient({ SALEOR_API_URL: API_URL, SALEOR_TOKEN: "t" });
+  return send;
+}
+
+const options = { reason: "late", issuedBy: "admin", notify: false };
+
+beforeEach(() => {
+  clearOrders();
+});
+
+afterEach(() => {
+  initializeSaleorClient({});
+  delete (globalThis as any).SALEOR_TRANSPORT;
+});
+
+describe("generateVoucherCode", () => {
+  it("should avoid look-alike characters", () => {
+    expect(generateVoucherCode()).toMatch(/^TMA-[A-HJ-NP-Z2-9]{8}$/);
+  });
+});
+
+describe("issueCompensationVoucher", () => {
+  it("should create the voucher in Saleor and record it once", async () => {
+    saleor({ voucher: { id: "voucher-1" }, errors: [] });
+
+    const voucher = await issueCompensationVoucher(ORDER_ID, { ...options, value: 4.999 });
+    expect(voucher).toMatchObject({ voucherId: "voucher-1", value: 5, currency: "EUR" });
+    expect(await getOrderVoucher(ORDER_ID)).toEqual(voucher);
+    await expect(issueCompensationVoucher(ORDER_ID, options)).rejects.toThrow("already issued");
+  });
+
+  it("should report Saleor failures as internal errors and record nothing", async () => {
+    saleor({ voucher: null, errors: [{ field: "code", message: "Taken", code: "UNIQUE" }] });
+    const orderId = "T3JkZXI6Mg==";
+    await expect(issueCompensationVoucher(orderId, options)).rejects.toMatchObject({
+      code: "INTERNAL_ERROR",
+    });
+    expect(await getOrderVoucher(orderId)).toBeNull();
+  });
+
+  it("should refuse without Saleor instead of sending a code that doesn't exist", async () => {
+    const placed = await createSaleorOrder(
+      {
+        restaurantId: "restA",
+        deliveryLocation: { address: "1 Test Street" },
+        items: [{ dishId: "dish1", quantity: 1 }],
+      },
+      "user-1",
+    );
+    await expect(issueCompensationVoucher(placed.order!.id, options)).rejects.toMatchObject({
+      code: "BAD_USER_INPUT",
+    });
+    expect(await getOrderVoucher(placed.order!.id)).toBeNull();
+  });
+});
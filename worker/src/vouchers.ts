@@ -0,0 +1,218 @@
+// Compensation Vouchers
+// Issues a single-use Saleor voucher (fixed amount in the order currency)
+// to a customer, e.g. for a late delivery or a resolved complaint. The
+// voucher is recorded against the order and its code sent to the user.
+
+import { CompensationVoucher } from "./contracts";
+import { getNumberVar } from "./config";
+import { badUserInputError, internalError, notFoundError } from "./errors";
+import { logger } from "./logger";
+import { roundMoney } from "./money";
+import { formatMoney } from "./currencyFormat";
+import { sendTelegramMessage } from "./notifications";
+import {
+  getSaleorClient,
+  isSaleorConfigured,
+  VOUCHER_CREATE_MUTATION,
+  VOUCHER_CHANNEL_LISTING_UPDATE_MUTATION,
+} from "./saleorClient";
+import {
+  VoucherChannelListingUpdateMutation,
+  VoucherChannelListingUpdateMutationVariables,
+  VoucherCreateMutation,
+  VoucherCreateMutationVariables,
+} from "./saleorOperations";
+import {
+  ORDER_METADATA_KEYS,
+  fetchOrderById,
+  updateOrderMetadata,
+} from "./saleorOrder";
+import { readJSON, writeJSON } from "./storage";
+
+export interface IssueVoucherOptions {
+  value?: number; // defaults to COMPENSATION_VOUCHER_VALUE
+  reason: string;
+  issuedBy: string;
+  notify?: boolean; // send the code to the customer (default true)
+}
+
+function getKey(orderId: string): string {
+  return `voucher:${orderId}`;
+}
+
+/**
+ * Default voucher value in the order currency (COMPENSATION_VOUCHER_VALUE)
+ */
+export function getCompensationVoucherValue(): number {
+  const value = getNumberVar("COMPENSATION_VOUCHER_VALUE", 5);
+  return value > 0 ? value : 5;
+}
+
+/**
+ * Random, human-typeable voucher code (no 0/O/1/I)
+ */
+export function generateVoucherCode(): string {
+  const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789";
+  const bytes = crypto.getRandomValues(new Uint8Array(8));
+  const chars = Array.from(bytes, (b) => alphabet[b % alphabet.length]);
+  return `TMA-${chars.join("")}`;
+}
+
+/**
+ * Create the voucher in Saleor and list it in the order's channel
+ * Returns the Saleor voucher ID, or null when Saleor is not configured
+ * (issueCompensationVoucher then refuses)
+ */
+async function createSaleorVoucher(
+  code: string,
+  value: number,
+  channelId: string,
+  name: string,
+): Promise<string | null> {
+  const client = isSaleorConfigured() ? getSaleorClient() : null;
+  if (!client) {
+    return null;
+  }
+
+  const voucher: VoucherCreateMutationVariables = {
+    input: {
+      name,
+      addCodes: [code],
+      type: "ENTIRE_ORDER",
+      discountValueType: "FIXED",
+      usageLimit: 1,
+      singleUse: true,
+    },
+  };
+  const created = await client.execute<VoucherCreateMutation>(VOUCHER_CREATE_MUTATION, voucher);
+  const createErrors = [
+    ...(created.errors || []).map((e) => e.message),
+    ...(created.data?.voucherCreate?.errors || []).map((e) => e.message),
+  ];
+  const voucherId = created.data?.voucherCreate?.voucher?.id;
+  if (createErrors.length > 0 || !voucherId) {
+    throw new Error(createErrors.join(", ") || "voucherCreate returned no voucher");
+  }
+
+  const listing: VoucherChannelListingUpdateMutationVariables = {
+    id: voucherId,
+    input: { addChannels: [{ channelId, discountValue: value }] },
+  };
+  const listed = await client.execute<VoucherChannelListingUpdateMutation>(
+    VOUCHER_CHANNEL_LISTING_UPDATE_MUTATION,
+    listing,
+  );
+  const listErrors = [
+    ...(listed.errors || []).map((e) => e.message),
+    ...(listed.data?.voucherChannelListingUpdate?.errors || []).map(
+      (e) => e.message,
+    ),
+  ];
+  if (listErrors.length > 0) {
+    throw new Error(listErrors.join(", "));
+  }
+
+  return voucherId;
+}
+
+export async function getOrderVoucher(
+  orderId: string,
+): Promise<CompensationVoucher | null> {
+  return readJSON<CompensationVoucher>(getKey(orderId));
+}
+
+/**
+ * Restaurant (channel) an order belongs to, for admin checks
+ */
+export async function getOrderVoucherRestaurantId(orderId: string): Promise<string> {
+  const order = orderId ? await fetchOrderById(orderId) : null;
+  if (!order) {
+    throw notFoundError("Order not found");
+  }
+  return order.channelId || "";
+}
+
+/**
+ * Issue a compensation voucher for an order (one per order)
+ */
+export async function issueCompensationVoucher(
+  orderId: string,
+  options: IssueVoucherOptions,
+): Promise<CompensationVoucher> {
+  const order = await fetchOrderById(orderId);
+  if (!order) {
+    throw notFoundError("Order not found");
+  }
+  const userId = order.metadata?.[ORDER_METADATA_KEYS.telegramUserId];
+  if (!userId) {
+    throw badUserInputError("Order has no Telegram customer", "orderId");
+  }
+  if (await getOrderVoucher(orderId)) {
+    throw badUserInputError(
+      "A voucher was already issued for this order",
+      "orderId",
+    );
+  }
+
+  const currency = order.total.gross.currency;
+  const value = roundMoney(
+    options.value ?? getCompensationVoucherValue(),
+    currency,
+  );
+  if (!(value > 0)) {
+    throw badUserInputError("Voucher value must be positive", "value");
+  }
+
+  const code = generateVoucherCode();
+  const label = order.number ? `#${order.number}` : order.id;
+  let voucherId: string | null;
+  try {
+    voucherId = await createSaleorVoucher(
+      code,
+      value,
+      order.channelId || "",
+      `Compensation for order ${label}`,
+    );
+  } catch (error) {
+    logger.error("voucher_create_failed", {
+      orderId,
+      error: error instanceof Error ? error.message : "Unknown error",
+    });
+    throw internalError("voucher_create_failed", "Could not create the voucher, please try again");
+  }
+  // Without Saleor there is no voucher to redeem; never send a dead code
+  if (!voucherId) {
+    throw badUserInputError("Vouchers can't be issued without Saleor");
+  }
+
+  const voucher: CompensationVoucher = {
+    orderId,
+    code,
+    voucherId,
+    value,
+    currency,
+    reason: options.reason,
+    issuedAt: new Date().toISOString(),
+    issuedBy: options.issuedBy,
+  };
+  await writeJSON(getKey(orderId), voucher);
+  await updateOrderMetadata(orderId, {
+    [ORDER_METADATA_KEYS.compensationVoucher]: code,
+  });
+
+  if (options.notify !== false) {
+    await sendTelegramMessage(
+      userId,
+      `Sorry about order ${label}. Here's ${formatMoney(value, currency)} off your next order: ${code}`,
+    );
+  }
+
+  logger.info("voucher_issued", {
+    orderId,
+    value,
+    currency,
+    reason: options.reason,
+    issuedBy: options.issuedBy,
+  });
+  return voucher;
+}
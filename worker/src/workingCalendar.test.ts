@@ -0,0 +1,46 @@
+// Working Calendar Tests
+// Tests for workingCalendar.ts - delivery slots within opening hours
+
+import { describe, it, expect } from "vitest";
+import { listOpenSlots } from "./workingCalendar";
+import { parseOpeningHours } from "./openingHours";
+
+describe("listOpenSlots", () => {
+  const now = new Date("2026-01-05T00:00:00Z");
+
+  it("should only list slots within opening hours", () => {
+    const hours = parseOpeningHours({
+      tma_hours: JSON.stringify({ tue: ["10:00-11:00"] }),
+      tma_timezone: "UTC",
+    });
+    const slots = listOpenSlots(hours, "2026-01-06", now, 30, 30, 7);
+    expect(slots).toEqual([
+      { start: "2026-01-06T10:00:00.000Z", end: "2026-01-06T10:30:00.000Z", remaining: null },
+      { start: "2026-01-06T10:30:00.000Z", end: "2026-01-06T11:00:00.000Z", remaining: null },
+    ]);
+  });
+
+  it("should return no slots on a closed date", () => {
+    const hours = parseOpeningHours({
+      tma_hours: JSON.stringify({ tue: ["10:00-11:00"] }),
+      tma_hours_overrides: JSON.stringify({ "2026-01-06": [] }),
+    });
+    expect(listOpenSlots(hours, "2026-01-06", now, 30, 30, 7)).toEqual([]);
+  });
+
+  it("should use the restaurant's local date", () => {
+    const hours = parseOpeningHours({
+      tma_hours: JSON.stringify({ tue: ["00:00-01:00"] }),
+      tma_timezone: "Asia/Tokyo",
+    });
+    const slots = listOpenSlots(hours, "2026-01-06", now, 60, 30, 7);
+    // Tuesday 00:00 in Tokyo is Monday 15:00 UTC
+    expect(slots.map((slot) => slot.start)).toEqual(["2026-01-05T15:00:00.000Z"]);
+  });
+
+  it("should respect the lead time and booking window", () => {
+    const slots = listOpenSlots(null, "2026-01-05", now, 60, 23 * 60, 7);
+    expect(slots.map((slot) => slot.start)).toEqual(["2026-01-05T23:00:00.000Z"]);
+    expect(listOpenSlots(null, "2026-01-20", now, 60, 0, 7)).toEqual([]);
+  });
+});
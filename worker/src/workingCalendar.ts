@@ -0,0 +1,174 @@
+// Restaurant Working Calendar
+// Date-specific opening hours on top of the weekly tma_hours schedule:
+// owners close for holidays or set special hours per local date
+// (tma_hours_overrides channel metadata). Overrides feed isOpenAt, so they
+// apply to Restaurant.isOpenNow, delivery slots and order validation.
+
+import { DeliverySlot, OpeningHoursOverride } from "./contracts";
+import { badUserInputError, notFoundError } from "./errors";
+import { logger } from "./logger";
+import {
+  HOURS_OVERRIDES_METADATA_KEY,
+  OpeningHours,
+  formatTimeRange,
+  getLocalTime,
+  isOpenAt,
+  isValidDateKey,
+  parseHoursOverrides,
+  parseOpeningHours,
+  parseTimeRange,
+  shiftDateKey,
+} from "./openingHours";
+import { fetchChannelById, updateChannelMetadata } from "./saleorService";
+import { getScheduledOrderLimits } from "./scheduledOrders";
+import { countSlotReservations, getSlotCapacity, getSlotMinutes } from "./slots";
+
+// Overrides for dates this far in the past are dropped on the next save
+const OVERRIDE_RETENTION_DAYS = 7;
+
+/**
+ * Set special hours for a local date, close it ([]), or clear the override (null)
+ */
+export async function setOpeningHoursOverride(
+  restaurantId: string,
+  date: string,
+  hours: string[] | null,
+  updatedBy: string,
+): Promise<OpeningHoursOverride> {
+  if (!isValidDateKey(date)) {
+    throw badUserInputError("Date must be YYYY-MM-DD", "date");
+  }
+  const channel = await fetchChannelById(restaurantId);
+  if (!channel) {
+    throw notFoundError("Restaurant not found");
+  }
+
+  const ranges = (hours || []).map((value) => {
+    const range = parseTimeRange(value);
+    if (!range) {
+      throw badUserInputError(`Invalid time range "${value}"`, "hours");
+    }
+    return range;
+  });
+
+  const today = getLocalTime(
+    new Date(),
+    parseOpeningHours(channel.metadata)?.timezone || "UTC",
+  ).dateKey;
+  const cutoff = shiftDateKey(today, -OVERRIDE_RETENTION_DAYS);
+  const overrides: Record<string, string[]> = {};
+  for (const [key, existing] of Object.entries(
+    parseHoursOverrides(channel.metadata?.[HOURS_OVERRIDES_METADATA_KEY]),
+  )) {
+    if (key >= cutoff) {
+      overrides[key] = existing.map(formatTimeRange);
+    }
+  }
+  if (hours === null) {
+    delete overrides[date];
+  } else {
+    overrides[date] = ranges.map(formatTimeRange);
+  }
+
+  const saved = await updateChannelMetadata(restaurantId, {
+    [HOURS_OVERRIDES_METADATA_KEY]: JSON.stringify(overrides),
+  });
+  if (!saved) {
+    throw badUserInputError("Could not save opening hours, please try again");
+  }
+
+  logger.info("opening_hours_override_updated", {
+    restaurantId,
+    date,
+    updatedBy,
+    closed: hours !== null && ranges.length === 0,
+    cleared: hours === null,
+  });
+  return {
+    restaurantId,
+    date,
+    hours: hours === null ? null : ranges.map(formatTimeRange),
+  };
+}
+
+/**
+ * Slot starts on a local date that fall within opening hours and the
+ * scheduled order lead-time window
+ */
+export function listOpenSlots(
+  hours: OpeningHours | null,
+  date: string,
+  now: Date,
+  slotMinutes: number,
+  minLeadMinutes: number,
+  maxDaysAhead: number,
+): DeliverySlot[] {
+  const slotMs = slotMinutes * 60 * 1000;
+  const timezone = hours?.timezone || "UTC";
+  const earliest = now.getTime() + minLeadMinutes * 60 * 1000;
+  const latest = now.getTime() + maxDaysAhead * 24 * 60 * 60 * 1000;
+
+  // A local date spans at most UTC-12..UTC+14 around the UTC date
+  const dayStartUtc = new Date(`${date}T00:00:00Z`).getTime();
+  const from = Math.ceil((dayStartUtc - 14 * 60 * 60 * 1000) / slotMs) * slotMs;
+  const to = dayStartUtc + 36 * 60 * 60 * 1000;
+
+  const slots: DeliverySlot[] = [];
+  for (let start = from; start < to; start += slotMs) {
+    if (start < earliest || start > latest) {
+      continue;
+    }
+    const instant = new Date(start);
+    if (getLocalTime(instant, timezone).dateKey !== date) {
+      continue;
+    }
+    if (hours && !isOpenAt(hours, instant)) {
+      continue;
+    }
+    slots.push({
+      start: instant.toISOString(),
+      end: new Date(start + slotMs).toISOString(),
+      remaining: null,
+    });
+  }
+  return slots;
+}
+
+/**
+ * Delivery slots a customer can schedule an order into on a local date
+ */
+export async function getDeliverySlots(
+  restaurantId: string,
+  date: string,
+  now: Date = new Date(),
+): Promise<DeliverySlot[]> {
+  if (!isValidDateKey(date)) {
+    throw badUserInputError("Date must be YYYY-MM-DD", "date");
+  }
+  const channel = await fetchChannelById(restaurantId);
+  if (!channel) {
+    throw notFoundError("Restaurant not found");
+  }
+  const { minLeadMinutes, maxDaysAhead } = getScheduledOrderLimits();
+  const slots = listOpenSlots(
+    parseOpeningHours(channel.metadata),
+    date,
+    now,
+    getSlotMinutes(),
+    minLeadMinutes,
+    maxDaysAhead,
+  );
+  const capacity = getSlotCapacity(channel.metadata);
+  if (capacity === null) {
+    return slots;
+  }
+
+  // Capped restaurants report what's left and hide full slots
+  const counted = await Promise.all(
+    slots.map(async (slot) => ({
+      ...slot,
+      remaining: capacity - (await countSlotReservations(restaurantId, slot.start)),
+    })),
+  );
+  return counted.filter((slot) => slot.remaining > 0);
+}